@@ -0,0 +1,209 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/soerjadi/exam/address"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/utils"
+)
+
+type addressData struct {
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2"`
+	City       string `json:"city"`
+	Region     string `json:"region"`
+	PostalCode string `json:"postal_code"`
+	Country    string `json:"country"`
+	Phone      string `json:"phone"`
+	IsDefault  bool   `json:"is_default"`
+}
+
+func (d addressData) toModel() models.Address {
+	return models.Address{
+		ID:         d.ID,
+		Name:       d.Name,
+		Line1:      d.Line1,
+		Line2:      d.Line2,
+		City:       d.City,
+		Region:     d.Region,
+		PostalCode: d.PostalCode,
+		Country:    d.Country,
+		Phone:      d.Phone,
+		IsDefault:  d.IsDefault,
+	}
+}
+
+// AddressHandler represent the http handler for address book
+type AddressHandler struct {
+	AddressUsecase address.Usecase
+}
+
+// NewAddressHandler initialize address book resource endpoint
+func NewAddressHandler(router *mux.Router, usecase address.Usecase) *mux.Router {
+	handler := &AddressHandler{
+		AddressUsecase: usecase,
+	}
+
+	p := router.PathPrefix("/v1/customer/address").Subrouter()
+	p.HandleFunc("/add", handler.AddAddress).Methods("POST")
+	p.HandleFunc("/update", handler.UpdateAddress).Methods("POST")
+	p.HandleFunc("/detail", handler.GetByID).Methods("GET")
+	p.HandleFunc("/list", handler.GetList).Methods("GET")
+	p.HandleFunc("/delete", handler.Delete).Methods("GET")
+
+	return p
+}
+
+func decode(r *http.Request, v interface{}) error {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, v)
+}
+
+// errorStatus map errors of the address usecase to http status
+func errorStatus(err error) int32 {
+	if err == models.ErrUnauthorized {
+		return http.StatusUnauthorized
+	}
+
+	return http.StatusBadRequest
+}
+
+// AddAddress endpoint to add an address to the book of the authenticated customer
+func (h *AddressHandler) AddAddress(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var data addressData
+	if err := decode(r, &data); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	addr := data.toModel()
+	addr.ID = 0
+
+	err := h.AddressUsecase.Create(ctx, &addr)
+
+	if err != nil {
+		utils.Error(w, errorStatus(err), err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, addr)
+}
+
+// UpdateAddress endpoint to change an address of the authenticated customer
+func (h *AddressHandler) UpdateAddress(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var data addressData
+	if err := decode(r, &data); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	addr := data.toModel()
+	err := h.AddressUsecase.Update(ctx, &addr)
+
+	if err != nil {
+		utils.Error(w, errorStatus(err), err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, addr)
+}
+
+// GetByID endpoint to get an address of the authenticated customer
+func (h *AddressHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	id, err := strconv.ParseInt(params.Get("id"), 0, 64)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	addr, err := h.AddressUsecase.GetByID(ctx, id)
+
+	if err != nil {
+		utils.Error(w, errorStatus(err), err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, addr)
+}
+
+// GetList endpoint to list the address book of the authenticated customer,
+// default address first
+func (h *AddressHandler) GetList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	addresses, err := h.AddressUsecase.GetList(ctx)
+
+	if err != nil {
+		utils.Error(w, errorStatus(err), err.Error())
+		return
+	}
+
+	entriesResult := &utils.EntriesResponse{
+		Data:  addresses,
+		Found: int64(len(addresses)),
+	}
+
+	utils.JSON(w, http.StatusOK, entriesResult)
+}
+
+// Delete endpoint to remove an address from the book, orders keep the
+// address they were shipped to
+func (h *AddressHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	id, err := strconv.ParseInt(params.Get("id"), 0, 64)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	err = h.AddressUsecase.Delete(ctx, id)
+
+	if err != nil {
+		utils.Error(w, errorStatus(err), err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, "success")
+}
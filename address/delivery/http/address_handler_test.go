@@ -0,0 +1,70 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	addressHttp "github.com/soerjadi/exam/address/delivery/http"
+	"github.com/soerjadi/exam/address/mocks"
+	"github.com/soerjadi/exam/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAddAddress(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("Create", mock.Anything, mock.MatchedBy(func(a *models.Address) bool {
+		return a.ID == 0 && a.City == "Jakarta" && a.IsDefault
+	})).Return(nil)
+
+	body := `{"id":9,"name":"Jane","line1":"Jl. Sudirman 1","city":"Jakarta","country":"ID","is_default":true}`
+	req, err := http.NewRequest("POST", "/v1/customer/address/add", strings.NewReader(body))
+	assert.NoError(t, err)
+
+	handler := addressHttp.AddressHandler{
+		AddressUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.AddAddress(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestGetListAnonymous(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("GetList", mock.Anything).Return(nil, models.ErrUnauthorized)
+
+	req, err := http.NewRequest("GET", "/v1/customer/address/list", nil)
+	assert.NoError(t, err)
+
+	handler := addressHttp.AddressHandler{
+		AddressUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.GetList(rec, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestDelete(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("Delete", mock.Anything, int64(5)).Return(nil)
+
+	req, err := http.NewRequest("GET", "/v1/customer/address/delete?id=5", nil)
+	assert.NoError(t, err)
+
+	handler := addressHttp.AddressHandler{
+		AddressUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.Delete(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
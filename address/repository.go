@@ -0,0 +1,16 @@
+package address
+
+import (
+	"context"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// Repository represent the address repository contract
+type Repository interface {
+	GetByID(ctx context.Context, id int64) (*models.Address, error)
+	GetByCustomer(ctx context.Context, customerID int64) ([]*models.Address, error)
+	Create(ctx context.Context, address *models.Address) error
+	Update(ctx context.Context, address *models.Address) error
+	Delete(ctx context.Context, id int64) error
+}
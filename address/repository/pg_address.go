@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/soerjadi/exam/address"
+	"github.com/soerjadi/exam/database"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/utils"
+)
+
+type pgAddressRepository struct {
+	Conn *sql.DB
+}
+
+var logger = utils.LogBuilder(true)
+
+// NewPGAddressRepository is bridge to create an object from address.Repository interface
+func NewPGAddressRepository(Conn *sql.DB) address.Repository {
+	return &pgAddressRepository{Conn}
+}
+
+func (p *pgAddressRepository) fetch(ctx context.Context, query string, args ...interface{}) ([]*models.Address, error) {
+	rows, err := p.Conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Error(err)
+		}
+	}()
+
+	result := make([]*models.Address, 0)
+	for rows.Next() {
+		t := new(models.Address)
+
+		err = rows.Scan(
+			&t.ID,
+			&t.CustomerID,
+			&t.Name,
+			&t.Line1,
+			&t.Line2,
+			&t.City,
+			&t.Region,
+			&t.PostalCode,
+			&t.Country,
+			&t.Phone,
+			&t.IsDefault,
+			&t.Created,
+			&t.Updated,
+		)
+
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+
+		result = append(result, t)
+	}
+
+	return result, nil
+}
+
+func (p *pgAddressRepository) GetByID(ctx context.Context, id int64) (*models.Address, error) {
+	query := `SELECT id, customer_id, name, line1, line2, city, region, postal_code, country, phone, is_default, created, updated
+		FROM addresses WHERE id = ?`
+
+	addresses, err := p.fetch(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(addresses) == 0 {
+		return nil, models.ErrNotFound
+	}
+
+	return addresses[0], nil
+}
+
+func (p *pgAddressRepository) GetByCustomer(ctx context.Context, customerID int64) ([]*models.Address, error) {
+	query := `SELECT id, customer_id, name, line1, line2, city, region, postal_code, country, phone, is_default, created, updated
+		FROM addresses WHERE customer_id = ? ORDER BY is_default DESC, id`
+
+	return p.fetch(ctx, query, customerID)
+}
+
+// clearDefault unset the default address of the customer other than id, a
+// customer has at most one default address
+func clearDefault(ctx context.Context, tx *sql.Tx, address *models.Address) error {
+	if !address.IsDefault {
+		return nil
+	}
+
+	_, err := tx.ExecContext(ctx, `UPDATE addresses SET is_default = false WHERE customer_id = ? AND id <> ?`, address.CustomerID, address.ID)
+	return err
+}
+
+func (p *pgAddressRepository) Create(ctx context.Context, address *models.Address) error {
+	return database.RunInTx(ctx, p.Conn, func(tx *sql.Tx) error {
+		query := `INSERT INTO addresses(customer_id, name, line1, line2, city, region, postal_code, country, phone, is_default)
+			VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?) returning id`
+
+		stmt, err := tx.PrepareContext(ctx, query)
+		if err != nil {
+			return err
+		}
+
+		result, err := stmt.ExecContext(ctx, address.CustomerID, address.Name, address.Line1, address.Line2, address.City,
+			address.Region, address.PostalCode, address.Country, address.Phone, address.IsDefault)
+		if err != nil {
+			return err
+		}
+
+		lastID, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		address.ID = lastID
+		return clearDefault(ctx, tx, address)
+	})
+}
+
+func (p *pgAddressRepository) Update(ctx context.Context, address *models.Address) error {
+	return database.RunInTx(ctx, p.Conn, func(tx *sql.Tx) error {
+		query := `UPDATE addresses SET name = ?, line1 = ?, line2 = ?, city = ?, region = ?, postal_code = ?, country = ?, phone = ?,
+			is_default = ?, updated = ? WHERE id = ?`
+
+		res, err := tx.ExecContext(ctx, query, address.Name, address.Line1, address.Line2, address.City, address.Region,
+			address.PostalCode, address.Country, address.Phone, address.IsDefault, address.Updated, address.ID)
+		if err != nil {
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if affected != 1 {
+			return models.ErrNotFound
+		}
+
+		return clearDefault(ctx, tx, address)
+	})
+}
+
+func (p *pgAddressRepository) Delete(ctx context.Context, id int64) error {
+	query := `DELETE FROM addresses WHERE id = ?`
+
+	stmt, err := p.Conn.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	res, err := stmt.ExecContext(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected != 1 {
+		return models.ErrNotFound
+	}
+
+	return nil
+}
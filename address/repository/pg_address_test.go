@@ -0,0 +1,124 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/soerjadi/exam/address/repository"
+	"github.com/soerjadi/exam/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var columns = []string{"id", "customer_id", "name", "line1", "line2", "city", "region", "postal_code", "country", "phone",
+	"is_default", "created", "updated"}
+
+func TestGetByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows(columns).
+		AddRow(2, 4, "Jane", "Jl. Sudirman 1", "", "Jakarta", "DKI Jakarta", "10220", "ID", "", true, time.Now(), nil)
+
+	mock.ExpectQuery("SELECT (.+) FROM addresses WHERE id = \\?").WithArgs(int64(2)).WillReturnRows(rows)
+
+	a := repository.NewPGAddressRepository(db)
+	addr, err := a.GetByID(context.TODO(), int64(2))
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), addr.CustomerID)
+	assert.True(t, addr.IsDefault)
+}
+
+func TestGetByCustomer(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows(columns).
+		AddRow(2, 4, "Jane", "Jl. Sudirman 1", "", "Jakarta", "DKI Jakarta", "10220", "ID", "", true, time.Now(), nil).
+		AddRow(3, 4, "Jane office", "Jl. Thamrin 9", "", "Jakarta", "DKI Jakarta", "10230", "ID", "", false, time.Now(), nil)
+
+	mock.ExpectQuery("SELECT (.+) FROM addresses WHERE customer_id = \\? ORDER BY is_default DESC").WithArgs(int64(4)).WillReturnRows(rows)
+
+	a := repository.NewPGAddressRepository(db)
+	addresses, err := a.GetByCustomer(context.TODO(), int64(4))
+
+	assert.NoError(t, err)
+	assert.Len(t, addresses, 2)
+}
+
+func TestCreate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	addr := &models.Address{CustomerID: 4, Name: "Jane", Line1: "Jl. Sudirman 1", City: "Jakarta", Country: "ID", IsDefault: true}
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare("INSERT INTO addresses\\(customer_id, name, (.+)\\) (.+) returning id").ExpectExec().
+		WithArgs(addr.CustomerID, addr.Name, addr.Line1, addr.Line2, addr.City, addr.Region, addr.PostalCode, addr.Country, addr.Phone, true).
+		WillReturnResult(sqlmock.NewResult(5, 1))
+	mock.ExpectExec("UPDATE addresses SET is_default = false WHERE customer_id = \\? AND id <> \\?").WithArgs(int64(4), int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	a := repository.NewPGAddressRepository(db)
+	err = a.Create(context.TODO(), addr)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), addr.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	addr := &models.Address{ID: 5, CustomerID: 4, Name: "Jane", Line1: "Jl. Sudirman 2", City: "Jakarta", Country: "ID"}
+	query := "UPDATE addresses SET name = \\?, (.+) WHERE id = \\?"
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(query).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		a := repository.NewPGAddressRepository(db)
+		err = a.Update(context.TODO(), addr)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(query).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		a := repository.NewPGAddressRepository(db)
+		err = a.Update(context.TODO(), addr)
+
+		assert.Equal(t, models.ErrNotFound, err)
+	})
+}
+
+func TestDelete(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectPrepare("DELETE FROM addresses WHERE id = \\?").ExpectExec().WithArgs(int64(5)).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	a := repository.NewPGAddressRepository(db)
+	err = a.Delete(context.TODO(), int64(5))
+
+	assert.NoError(t, err)
+}
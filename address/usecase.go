@@ -0,0 +1,17 @@
+package address
+
+import (
+	"context"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// Usecase represent the address usecase, addresses are the ones of the
+// customer authenticated in ctx
+type Usecase interface {
+	GetByID(ctx context.Context, id int64) (*models.Address, error)
+	GetList(ctx context.Context) ([]*models.Address, error)
+	Create(ctx context.Context, address *models.Address) error
+	Update(ctx context.Context, address *models.Address) error
+	Delete(ctx context.Context, id int64) error
+}
@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/soerjadi/exam/address"
+	"github.com/soerjadi/exam/audit"
+	"github.com/soerjadi/exam/auth"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/utils"
+	"gopkg.in/guregu/null.v3"
+)
+
+type addressUsecase struct {
+	repo           address.Repository
+	audit          audit.Usecase
+	contextTimeout time.Duration
+}
+
+var logger = utils.LogBuilder(true)
+
+// NewAddressUsecase will create object that represent of address.Usecase interface
+func NewAddressUsecase(a address.Repository, au audit.Usecase, timeout time.Duration) address.Usecase {
+	return &addressUsecase{
+		repo:           a,
+		audit:          au,
+		contextTimeout: timeout,
+	}
+}
+
+func (a *addressUsecase) record(ctx context.Context, id int64, action string, before interface{}, after interface{}) {
+	if err := a.audit.Record(ctx, models.AuditAddress, id, action, before, after); err != nil {
+		logger.Error(err)
+	}
+}
+
+// owned return the address id of the customer authenticated in ctx, the
+// address of another customer is not found
+func (a *addressUsecase) owned(ctx context.Context, id int64) (*models.Address, error) {
+	customerID, ok := auth.CustomerID(ctx)
+	if !ok {
+		return nil, models.ErrUnauthorized
+	}
+
+	addr, err := a.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if addr.CustomerID != customerID {
+		return nil, models.ErrNotFound
+	}
+
+	return addr, nil
+}
+
+func (a *addressUsecase) GetByID(ctx context.Context, id int64) (*models.Address, error) {
+	ctx, cancel := context.WithTimeout(ctx, a.contextTimeout)
+	defer cancel()
+
+	return a.owned(ctx, id)
+}
+
+func (a *addressUsecase) GetList(ctx context.Context) ([]*models.Address, error) {
+	ctx, cancel := context.WithTimeout(ctx, a.contextTimeout)
+	defer cancel()
+
+	customerID, ok := auth.CustomerID(ctx)
+	if !ok {
+		return nil, models.ErrUnauthorized
+	}
+
+	return a.repo.GetByCustomer(ctx, customerID)
+}
+
+// Create add the address to the address book, the first address of a
+// customer become the default one
+func (a *addressUsecase) Create(ctx context.Context, addr *models.Address) error {
+	ctx, cancel := context.WithTimeout(ctx, a.contextTimeout)
+	defer cancel()
+
+	customerID, ok := auth.CustomerID(ctx)
+	if !ok {
+		return models.ErrUnauthorized
+	}
+
+	if err := address.Validate(addr); err != nil {
+		return err
+	}
+
+	existing, err := a.repo.GetByCustomer(ctx, customerID)
+	if err != nil {
+		return err
+	}
+
+	addr.CustomerID = customerID
+	if len(existing) == 0 {
+		addr.IsDefault = true
+	}
+
+	if err = a.repo.Create(ctx, addr); err != nil {
+		return err
+	}
+
+	a.record(ctx, addr.ID, models.AuditCreate, nil, addr)
+	return nil
+}
+
+func (a *addressUsecase) Update(ctx context.Context, addr *models.Address) error {
+	ctx, cancel := context.WithTimeout(ctx, a.contextTimeout)
+	defer cancel()
+
+	if err := address.Validate(addr); err != nil {
+		return err
+	}
+
+	before, err := a.owned(ctx, addr.ID)
+	if err != nil {
+		return err
+	}
+
+	addr.CustomerID = before.CustomerID
+	addr.Created = before.Created
+	addr.Updated = null.NewTime(
+		time.Now(), true,
+	)
+
+	if err = a.repo.Update(ctx, addr); err != nil {
+		return err
+	}
+
+	a.record(ctx, addr.ID, models.AuditUpdate, before, addr)
+	return nil
+}
+
+func (a *addressUsecase) Delete(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, a.contextTimeout)
+	defer cancel()
+
+	exists, err := a.owned(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err = a.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	a.record(ctx, id, models.AuditDelete, exists, nil)
+	return nil
+}
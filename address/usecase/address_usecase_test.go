@@ -0,0 +1,102 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/soerjadi/exam/address/mocks"
+	"github.com/soerjadi/exam/address/usecase"
+	"github.com/soerjadi/exam/audit/audittest"
+	"github.com/soerjadi/exam/auth"
+	"github.com/soerjadi/exam/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func customerCtx(id int64) context.Context {
+	return auth.WithClaims(context.TODO(), &auth.Claims{Subject: id})
+}
+
+func TestCreate(t *testing.T) {
+	t.Run("first address become default", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		addr := models.Address{Name: "Jane", Line1: "Jl. Sudirman 1", City: "Jakarta", Country: " id"}
+
+		mockRepo.On("GetByCustomer", mock.Anything, int64(4)).Return(make([]*models.Address, 0), nil).Once()
+		mockRepo.On("Create", mock.Anything, &addr).Return(nil).Once()
+
+		mockAudit := audittest.NewUsecase()
+		a := usecase.NewAddressUsecase(mockRepo, mockAudit, time.Second*2)
+		err := a.Create(customerCtx(4), &addr)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(4), addr.CustomerID)
+		assert.Equal(t, "ID", addr.Country)
+		assert.True(t, addr.IsDefault)
+		mockRepo.AssertExpectations(t)
+		audittest.AssertRecorded(t, mockAudit, models.AuditAddress, addr.ID, models.AuditCreate, nil, &addr)
+	})
+
+	t.Run("anonymous", func(t *testing.T) {
+		a := usecase.NewAddressUsecase(new(mocks.Repository), audittest.NewUsecase(), time.Second*2)
+		err := a.Create(context.TODO(), &models.Address{Name: "Jane", Line1: "Jl. Sudirman 1", City: "Jakarta", Country: "ID"})
+
+		assert.Equal(t, models.ErrUnauthorized, err)
+	})
+
+	t.Run("incomplete", func(t *testing.T) {
+		mockAudit := audittest.NewUsecase()
+		a := usecase.NewAddressUsecase(new(mocks.Repository), mockAudit, time.Second*2)
+		err := a.Create(customerCtx(4), &models.Address{Name: "Jane", City: "Jakarta", Country: "Indonesia"})
+
+		assert.Equal(t, models.ErrBadParamInput, err)
+		audittest.AssertNothingRecorded(t, mockAudit)
+	})
+}
+
+func TestUpdate(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	before := models.Address{ID: 5, CustomerID: 4, Name: "Jane", Line1: "Jl. Sudirman 1", City: "Jakarta", Country: "ID",
+		Created: time.Now().Add(-time.Hour)}
+	addr := models.Address{ID: 5, Name: "Jane", Line1: "Jl. Sudirman 2", City: "Jakarta", Country: "ID"}
+
+	mockRepo.On("GetByID", mock.Anything, int64(5)).Return(&before, nil).Once()
+	mockRepo.On("Update", mock.Anything, &addr).Return(nil).Once()
+
+	mockAudit := audittest.NewUsecase()
+	a := usecase.NewAddressUsecase(mockRepo, mockAudit, time.Second*2)
+	err := a.Update(customerCtx(4), &addr)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), addr.CustomerID)
+	assert.Equal(t, before.Created, addr.Created)
+	assert.True(t, addr.Updated.Valid)
+	mockRepo.AssertExpectations(t)
+	audittest.AssertRecorded(t, mockAudit, models.AuditAddress, int64(5), models.AuditUpdate, &before, &addr)
+}
+
+func TestGetByIDOtherCustomer(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockRepo.On("GetByID", mock.Anything, int64(5)).Return(&models.Address{ID: 5, CustomerID: 8}, nil).Once()
+
+	a := usecase.NewAddressUsecase(mockRepo, audittest.NewUsecase(), time.Second*2)
+	_, err := a.GetByID(customerCtx(4), int64(5))
+
+	assert.Equal(t, models.ErrNotFound, err)
+}
+
+func TestDelete(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	existing := models.Address{ID: 5, CustomerID: 4}
+	mockRepo.On("GetByID", mock.Anything, int64(5)).Return(&existing, nil).Once()
+	mockRepo.On("Delete", mock.Anything, int64(5)).Return(nil).Once()
+
+	mockAudit := audittest.NewUsecase()
+	a := usecase.NewAddressUsecase(mockRepo, mockAudit, time.Second*2)
+	err := a.Delete(customerCtx(4), int64(5))
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	audittest.AssertRecorded(t, mockAudit, models.AuditAddress, int64(5), models.AuditDelete, &existing, nil)
+}
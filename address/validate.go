@@ -0,0 +1,19 @@
+package address
+
+import (
+	"strings"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// Validate check an address is complete enough to ship to, the country code
+// is upper cased
+func Validate(addr *models.Address) error {
+	addr.Country = strings.ToUpper(strings.TrimSpace(addr.Country))
+
+	if addr.Name == "" || addr.Line1 == "" || addr.City == "" || len(addr.Country) != 2 {
+		return models.ErrBadParamInput
+	}
+
+	return nil
+}
@@ -0,0 +1,255 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/soerjadi/exam/apikey"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/utils"
+)
+
+type newKeyData struct {
+	Name        string   `json:"name"`
+	Permissions []string `json:"permissions"`
+}
+
+// usageDays is the period of usage listed when none is given
+const usageDays = 30
+
+// APIKeyHandler represent the http handler for API keys
+type APIKeyHandler struct {
+	APIKeyUsecase apikey.Usecase
+}
+
+// NewAPIKeyHandler initialize API key resource endpoint
+func NewAPIKeyHandler(router *mux.Router, usecase apikey.Usecase) *mux.Router {
+	handler := &APIKeyHandler{
+		APIKeyUsecase: usecase,
+	}
+
+	p := router.PathPrefix("/v1/admin/apikey").Subrouter()
+	p.HandleFunc("/add", handler.AddKey).Methods("POST")
+	p.HandleFunc("/list", handler.GetList).Methods("GET")
+	p.HandleFunc("/detail", handler.GetByID).Methods("GET")
+	p.HandleFunc("/revoke", handler.Revoke).Methods("GET")
+	p.HandleFunc("/{id:[0-9]+}/rotate", handler.Rotate).Methods("POST")
+	p.HandleFunc("/{id:[0-9]+}/usage", handler.Usage).Methods("GET")
+
+	return p
+}
+
+// AddKey create a key, the response is the only time the key is shown
+func (h *APIKeyHandler) AddKey(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var data newKeyData
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = json.Unmarshal(body, &data)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	key := models.APIKey{
+		Name:        data.Name,
+		Permissions: data.Permissions,
+	}
+
+	err = h.APIKeyUsecase.Create(ctx, &key)
+
+	if err == models.ErrForbidden {
+		utils.Error(w, http.StatusForbidden, err.Error())
+		return
+	}
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, key)
+}
+
+// GetList list keys newest first
+func (h *APIKeyHandler) GetList(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	limit, err := strconv.ParseInt(params.Get("limit"), 0, 64)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	offset, err := strconv.ParseInt(params.Get("offset"), 0, 64)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if limit == 0 {
+		limit = 10
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	keys, found, err := h.APIKeyUsecase.GetList(ctx, offset, limit)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entriesResult := &utils.EntriesResponse{
+		Data:  keys,
+		Found: found,
+	}
+
+	utils.JSON(w, http.StatusOK, entriesResult)
+}
+
+// GetByID get detail key from given ID
+func (h *APIKeyHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	id, err := strconv.ParseInt(params.Get("id"), 0, 64)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	key, err := h.APIKeyUsecase.GetByID(ctx, id)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, key)
+}
+
+// Revoke stop key by given ID from being accepted
+func (h *APIKeyHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	id, err := strconv.ParseInt(params.Get("id"), 0, 64)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	err = h.APIKeyUsecase.Revoke(ctx, id)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, "success")
+}
+
+// Rotate replace the secret of a key, the response is the only time the new
+// key is shown
+func (h *APIKeyHandler) Rotate(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 0, 64)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	key, err := h.APIKeyUsecase.Rotate(ctx, id)
+
+	if err == models.ErrForbidden {
+		utils.Error(w, http.StatusForbidden, err.Error())
+		return
+	}
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, key)
+}
+
+// Usage list the daily requests of a key between the from and to dates
+// (YYYY-MM-DD), the last 30 days by default
+func (h *APIKeyHandler) Usage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 0, 64)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	params := r.URL.Query()
+	to := time.Now()
+	if params.Get("to") != "" {
+		to, err = time.Parse("2006-01-02", params.Get("to"))
+		if err != nil {
+			utils.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	from := to.AddDate(0, 0, -usageDays)
+	if params.Get("from") != "" {
+		from, err = time.Parse("2006-01-02", params.Get("from"))
+		if err != nil {
+			utils.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	usage, err := h.APIKeyUsecase.GetUsage(ctx, id, from, to)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entriesResult := &utils.EntriesResponse{
+		Data:  usage,
+		Found: int64(len(usage)),
+	}
+
+	utils.JSON(w, http.StatusOK, entriesResult)
+}
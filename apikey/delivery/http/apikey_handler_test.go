@@ -0,0 +1,81 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	keyHttp "github.com/soerjadi/exam/apikey/delivery/http"
+	"github.com/soerjadi/exam/apikey/mocks"
+	"github.com/soerjadi/exam/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAddKey(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("Create", mock.Anything, mock.AnythingOfType("*models.APIKey")).Return(nil).Run(func(args mock.Arguments) {
+		key := args.Get(1).(*models.APIKey)
+		key.ID = 3
+		key.Key = "ak_secret"
+	}).Once()
+
+	req, err := http.NewRequest("POST", "/v1/admin/apikey/add", strings.NewReader(`{"name":"erp","permissions":["catalog:read"]}`))
+	assert.NoError(t, err)
+
+	handler := keyHttp.APIKeyHandler{
+		APIKeyUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.AddKey(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"key":"ak_secret"`)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestAddKeyForbidden(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("Create", mock.Anything, mock.AnythingOfType("*models.APIKey")).Return(models.ErrForbidden).Once()
+
+	req, err := http.NewRequest("POST", "/v1/admin/apikey/add", strings.NewReader(`{"name":"erp","permissions":["role:manage"]}`))
+	assert.NoError(t, err)
+
+	handler := keyHttp.APIKeyHandler{
+		APIKeyUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.AddKey(rec, req)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestUsage(t *testing.T) {
+	from := time.Date(2019, 11, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2019, 11, 30, 0, 0, 0, 0, time.UTC)
+
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("GetUsage", mock.Anything, int64(3), from, to).
+		Return([]*models.APIKeyUsage{&models.APIKeyUsage{KeyID: 3, Day: from, Requests: 42}}, nil).Once()
+
+	req, err := http.NewRequest("GET", "/v1/admin/apikey/3/usage?from=2019-11-01&to=2019-11-30", strings.NewReader(""))
+	assert.NoError(t, err)
+	req = mux.SetURLVars(req, map[string]string{"id": "3"})
+
+	handler := keyHttp.APIKeyHandler{
+		APIKeyUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.Usage(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"requests":42`)
+	mockUsecase.AssertExpectations(t)
+}
@@ -0,0 +1,20 @@
+package apikey
+
+import (
+	"context"
+	"time"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// Repository represent the API key repository contract
+type Repository interface {
+	GetByID(ctx context.Context, id int64) (*models.APIKey, error)
+	GetByHash(ctx context.Context, hash string) (*models.APIKey, error)
+	GetList(ctx context.Context, offset int64, limit int64) ([]*models.APIKey, int64, error)
+	Create(ctx context.Context, key *models.APIKey) error
+	UpdateHash(ctx context.Context, key *models.APIKey) error
+	Revoke(ctx context.Context, id int64, now time.Time) error
+	Touch(ctx context.Context, id int64, now time.Time) error
+	GetUsage(ctx context.Context, id int64, from time.Time, to time.Time) ([]*models.APIKeyUsage, error)
+}
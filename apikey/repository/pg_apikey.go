@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/soerjadi/exam/apikey"
+	"github.com/soerjadi/exam/database"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/utils"
+)
+
+type pgAPIKeyRepository struct {
+	Conn *sql.DB
+}
+
+var logger = utils.LogBuilder(true)
+
+// NewPGAPIKeyRepository is bridge to create an object from apikey.Repository interface
+func NewPGAPIKeyRepository(Conn *sql.DB) apikey.Repository {
+	return &pgAPIKeyRepository{Conn}
+}
+
+// permissions are stored comma separated
+func splitPermissions(value string) []string {
+	if value == "" {
+		return []string{}
+	}
+
+	return strings.Split(value, ",")
+}
+
+func (a *pgAPIKeyRepository) fetch(ctx context.Context, query string, args ...interface{}) ([]*models.APIKey, error) {
+	rows, err := a.Conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Error(err)
+		}
+	}()
+
+	result := make([]*models.APIKey, 0)
+	for rows.Next() {
+		t := new(models.APIKey)
+		var permissions string
+
+		err = rows.Scan(
+			&t.ID,
+			&t.Name,
+			&t.Prefix,
+			&t.Hash,
+			&permissions,
+			&t.Requests,
+			&t.LastUsedAt,
+			&t.RevokedAt,
+			&t.Created,
+			&t.Updated,
+		)
+
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+
+		t.Permissions = splitPermissions(permissions)
+		result = append(result, t)
+	}
+
+	return result, nil
+}
+
+func (a *pgAPIKeyRepository) fetchRow(ctx context.Context, query string, args ...interface{}) (*sql.Row, error) {
+	stmt, err := a.Conn.PrepareContext(ctx, query)
+
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	defer func() {
+		err := stmt.Close()
+		if err != nil {
+			logger.Error(err)
+		}
+	}()
+
+	return stmt.QueryRow(args...), nil
+}
+
+func (a *pgAPIKeyRepository) getOne(ctx context.Context, query string, args ...interface{}) (*models.APIKey, error) {
+	keys, err := a.fetch(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(keys) == 0 {
+		return nil, models.ErrNotFound
+	}
+
+	return keys[0], nil
+}
+
+func (a *pgAPIKeyRepository) exec(ctx context.Context, query string, args ...interface{}) error {
+	stmt, err := a.Conn.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	res, err := stmt.ExecContext(ctx, args...)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected != 1 {
+		return models.ErrNotFound
+	}
+
+	return nil
+}
+
+func (a *pgAPIKeyRepository) GetByID(ctx context.Context, id int64) (*models.APIKey, error) {
+	query := `SELECT id, name, prefix, hash, permissions, requests, last_used_at, revoked_at, created, updated
+		FROM api_keys WHERE id = ?`
+
+	return a.getOne(ctx, query, id)
+}
+
+// GetByHash find the key matching hash, revoked keys are not found
+func (a *pgAPIKeyRepository) GetByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	query := `SELECT id, name, prefix, hash, permissions, requests, last_used_at, revoked_at, created, updated
+		FROM api_keys WHERE hash = ? AND revoked_at IS NULL`
+
+	return a.getOne(ctx, query, hash)
+}
+
+func (a *pgAPIKeyRepository) GetList(ctx context.Context, offset int64, limit int64) ([]*models.APIKey, int64, error) {
+	query := `SELECT id, name, prefix, hash, permissions, requests, last_used_at, revoked_at, created, updated
+		FROM api_keys ORDER BY id DESC LIMIT ? OFFSET ?`
+	qCount := `SELECT count(id) FROM api_keys`
+
+	result, err := a.fetch(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := a.fetchRow(ctx, qCount)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var count int64
+	err = rows.Scan(&count)
+
+	if err != nil {
+		logger.Error(err)
+		return nil, 0, err
+	}
+
+	return result, count, nil
+}
+
+func (a *pgAPIKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	query := `INSERT INTO api_keys(name, prefix, hash, permissions) VALUES(?, ?, ?, ?) returning id`
+
+	stmt, err := a.Conn.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	result, err := stmt.ExecContext(ctx, key.Name, key.Prefix, key.Hash, strings.Join(key.Permissions, ","))
+	if err != nil {
+		return err
+	}
+
+	lastID, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	key.ID = lastID
+	return nil
+}
+
+// UpdateHash replace the secret of a key which is not revoked
+func (a *pgAPIKeyRepository) UpdateHash(ctx context.Context, key *models.APIKey) error {
+	query := `UPDATE api_keys SET prefix = ?, hash = ?, updated = ? WHERE id = ? AND revoked_at IS NULL`
+
+	return a.exec(ctx, query, key.Prefix, key.Hash, key.Updated, key.ID)
+}
+
+func (a *pgAPIKeyRepository) Revoke(ctx context.Context, id int64, now time.Time) error {
+	query := `UPDATE api_keys SET revoked_at = ?, updated = ? WHERE id = ? AND revoked_at IS NULL`
+
+	return a.exec(ctx, query, now, now, id)
+}
+
+// Touch count a request made with the key, on the key and in the usage of
+// the day
+func (a *pgAPIKeyRepository) Touch(ctx context.Context, id int64, now time.Time) error {
+	return database.RunInTx(ctx, a.Conn, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `UPDATE api_keys SET requests = requests + 1, last_used_at = ? WHERE id = ?`, now, id)
+		if err != nil {
+			return err
+		}
+
+		query := `INSERT INTO api_key_usage(key_id, day, requests) VALUES(?, ?, 1)
+			ON CONFLICT (key_id, day) DO UPDATE SET requests = api_key_usage.requests + 1`
+		_, err = tx.ExecContext(ctx, query, id, now.Format("2006-01-02"))
+		return err
+	})
+}
+
+// GetUsage list the daily usage of the key between from and to, both included
+func (a *pgAPIKeyRepository) GetUsage(ctx context.Context, id int64, from time.Time, to time.Time) ([]*models.APIKeyUsage, error) {
+	query := `SELECT key_id, day, requests FROM api_key_usage WHERE key_id = ? AND day >= ? AND day <= ? ORDER BY day`
+
+	rows, err := a.Conn.QueryContext(ctx, query, id, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Error(err)
+		}
+	}()
+
+	result := make([]*models.APIKeyUsage, 0)
+	for rows.Next() {
+		t := new(models.APIKeyUsage)
+
+		if err = rows.Scan(&t.KeyID, &t.Day, &t.Requests); err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+
+		result = append(result, t)
+	}
+
+	return result, nil
+}
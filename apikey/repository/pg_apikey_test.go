@@ -0,0 +1,104 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/soerjadi/exam/apikey/repository"
+	"github.com/soerjadi/exam/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var columns = []string{"id", "name", "prefix", "hash", "permissions", "requests", "last_used_at", "revoked_at", "created", "updated"}
+
+func TestGetByHash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	query := "SELECT (.+) FROM api_keys WHERE hash = \\? AND revoked_at IS NULL"
+
+	t.Run("found", func(t *testing.T) {
+		rows := sqlmock.NewRows(columns).AddRow(3, "erp", "ak_12345678", "abc", "catalog:read,order:read", 10, nil, nil, time.Now(), nil)
+		mock.ExpectQuery(query).WithArgs("abc").WillReturnRows(rows)
+
+		a := repository.NewPGAPIKeyRepository(db)
+		key, err := a.GetByHash(context.TODO(), "abc")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"catalog:read", "order:read"}, key.Permissions)
+	})
+
+	t.Run("revoked", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs("abc").WillReturnRows(sqlmock.NewRows(columns))
+
+		a := repository.NewPGAPIKeyRepository(db)
+		_, err := a.GetByHash(context.TODO(), "abc")
+
+		assert.Equal(t, models.ErrNotFound, err)
+	})
+}
+
+func TestCreate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	key := &models.APIKey{
+		Name:        "erp",
+		Prefix:      "ak_12345678",
+		Hash:        "abc",
+		Permissions: []string{"catalog:read", "order:read"},
+	}
+
+	query := "INSERT INTO api_keys\\(name, prefix, hash, permissions\\) VALUES\\(\\?, \\?, \\?, \\?\\) returning id"
+	mock.ExpectPrepare(query).ExpectExec().WithArgs(key.Name, key.Prefix, key.Hash, "catalog:read,order:read").
+		WillReturnResult(sqlmock.NewResult(3, 1))
+
+	a := repository.NewPGAPIKeyRepository(db)
+	err = a.Create(context.TODO(), key)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), key.ID)
+}
+
+func TestRevoke(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Now()
+	query := "UPDATE api_keys SET revoked_at = \\?, updated = \\? WHERE id = \\? AND revoked_at IS NULL"
+	mock.ExpectPrepare(query).ExpectExec().WithArgs(now, now, int64(3)).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	a := repository.NewPGAPIKeyRepository(db)
+	err = a.Revoke(context.TODO(), int64(3), now)
+
+	assert.Equal(t, models.ErrNotFound, err)
+}
+
+func TestTouch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Date(2019, 11, 26, 10, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE api_keys SET requests = requests \\+ 1, last_used_at = \\? WHERE id = \\?").
+		WithArgs(now, int64(3)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO api_key_usage\\(key_id, day, requests\\) (.+) ON CONFLICT \\(key_id, day\\)").
+		WithArgs(int64(3), "2019-11-26").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	a := repository.NewPGAPIKeyRepository(db)
+	err = a.Touch(context.TODO(), int64(3), now)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
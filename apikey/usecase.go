@@ -0,0 +1,21 @@
+package apikey
+
+import (
+	"context"
+	"time"
+
+	"github.com/soerjadi/exam/auth"
+	"github.com/soerjadi/exam/models"
+)
+
+// Usecase represent the API key usecase, it also resolve the keys sent to the
+// auth middleware
+type Usecase interface {
+	GetByID(ctx context.Context, id int64) (*models.APIKey, error)
+	GetList(ctx context.Context, offset int64, limit int64) ([]*models.APIKey, int64, error)
+	Create(ctx context.Context, key *models.APIKey) error
+	Rotate(ctx context.Context, id int64) (*models.APIKey, error)
+	Revoke(ctx context.Context, id int64) error
+	Authenticate(ctx context.Context, key string) (*auth.KeyPrincipal, error)
+	GetUsage(ctx context.Context, id int64, from time.Time, to time.Time) ([]*models.APIKeyUsage, error)
+}
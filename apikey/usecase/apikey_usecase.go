@@ -0,0 +1,218 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/soerjadi/exam/apikey"
+	"github.com/soerjadi/exam/audit"
+	"github.com/soerjadi/exam/auth"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/utils"
+	"gopkg.in/guregu/null.v3"
+)
+
+type apiKeyUsecase struct {
+	repo           apikey.Repository
+	audit          audit.Usecase
+	contextTimeout time.Duration
+}
+
+// keyPrefix start every key so they are easy to spot, e.g. in a leaked file
+const keyPrefix = "ak_"
+
+var logger = utils.LogBuilder(true)
+
+// NewAPIKeyUsecase will create object that represent of apikey.Usecase interface
+func NewAPIKeyUsecase(a apikey.Repository, au audit.Usecase, timeout time.Duration) apikey.Usecase {
+	return &apiKeyUsecase{
+		repo:           a,
+		audit:          au,
+		contextTimeout: timeout,
+	}
+}
+
+// record audit a change of key, never with the key itself
+func (a *apiKeyUsecase) record(ctx context.Context, id int64, action string, before *models.APIKey, after *models.APIKey) {
+	if after != nil {
+		masked := *after
+		masked.Key = ""
+		after = &masked
+	}
+
+	if err := a.audit.Record(ctx, models.AuditAPIKey, id, action, before, after); err != nil {
+		logger.Error(err)
+	}
+}
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// generate give key a new secret, only its hash and prefix are stored
+func generate(key *models.APIKey) {
+	key.Key = keyPrefix + utils.RandString(24)
+	key.Prefix = key.Key[:len(keyPrefix)+8]
+	key.Hash = hashKey(key.Key)
+}
+
+// grantable check the principal of ctx hold every permission given, so
+// nobody can create a key allowed more than themselves
+func grantable(ctx context.Context, permissions []string) error {
+	principal, ok := auth.PrincipalFrom(ctx)
+	if !ok {
+		return models.ErrUnauthorized
+	}
+
+	for _, permission := range permissions {
+		if !principal.Allowed(auth.Permission(permission)) {
+			return models.ErrForbidden
+		}
+	}
+
+	return nil
+}
+
+func (a *apiKeyUsecase) GetByID(ctx context.Context, id int64) (*models.APIKey, error) {
+	ctx, cancel := context.WithTimeout(ctx, a.contextTimeout)
+	defer cancel()
+
+	return a.repo.GetByID(ctx, id)
+}
+
+func (a *apiKeyUsecase) GetList(ctx context.Context, offset int64, limit int64) ([]*models.APIKey, int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, a.contextTimeout)
+	defer cancel()
+
+	return a.repo.GetList(ctx, offset, limit)
+}
+
+// Create store a key scoped to its permissions, the key is set on it and
+// can not be read again afterward
+func (a *apiKeyUsecase) Create(ctx context.Context, key *models.APIKey) error {
+	ctx, cancel := context.WithTimeout(ctx, a.contextTimeout)
+	defer cancel()
+
+	if strings.TrimSpace(key.Name) == "" || len(key.Permissions) == 0 {
+		return models.ErrBadParamInput
+	}
+
+	for _, permission := range key.Permissions {
+		if !auth.ValidPermission(auth.Permission(permission)) {
+			return models.ErrBadParamInput
+		}
+	}
+
+	if err := grantable(ctx, key.Permissions); err != nil {
+		return err
+	}
+
+	generate(key)
+	if err := a.repo.Create(ctx, key); err != nil {
+		return err
+	}
+
+	a.record(ctx, key.ID, models.AuditCreate, nil, key)
+	return nil
+}
+
+// Rotate replace the secret of a key, the previous one stop working at once.
+// Only a principal holding every permission of the key can get its secret.
+func (a *apiKeyUsecase) Rotate(ctx context.Context, id int64) (*models.APIKey, error) {
+	ctx, cancel := context.WithTimeout(ctx, a.contextTimeout)
+	defer cancel()
+
+	before, err := a.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = grantable(ctx, before.Permissions); err != nil {
+		return nil, err
+	}
+
+	key := *before
+	generate(&key)
+	key.Updated = null.TimeFrom(time.Now())
+
+	if err = a.repo.UpdateHash(ctx, &key); err != nil {
+		return nil, err
+	}
+
+	a.record(ctx, id, models.AuditUpdate, before, &key)
+	return &key, nil
+}
+
+func (a *apiKeyUsecase) Revoke(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, a.contextTimeout)
+	defer cancel()
+
+	before, err := a.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if err = a.repo.Revoke(ctx, id, now); err != nil {
+		return err
+	}
+
+	after := *before
+	after.RevokedAt = null.TimeFrom(now)
+	after.Updated = null.TimeFrom(now)
+
+	a.record(ctx, id, models.AuditUpdate, before, &after)
+	return nil
+}
+
+// Authenticate resolve key into the principal it stand for and count the
+// request in its usage
+func (a *apiKeyUsecase) Authenticate(ctx context.Context, key string) (*auth.KeyPrincipal, error) {
+	ctx, cancel := context.WithTimeout(ctx, a.contextTimeout)
+	defer cancel()
+
+	if !strings.HasPrefix(key, keyPrefix) {
+		return nil, models.ErrUnauthorized
+	}
+
+	found, err := a.repo.GetByHash(ctx, hashKey(key))
+	if err == models.ErrNotFound {
+		return nil, models.ErrUnauthorized
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// usage stats are not worth refusing the request
+	if err = a.repo.Touch(ctx, found.ID, time.Now()); err != nil {
+		logger.Error(err)
+	}
+
+	principal := &auth.KeyPrincipal{ID: found.ID}
+	for _, permission := range found.Permissions {
+		principal.Permissions = append(principal.Permissions, auth.Permission(permission))
+	}
+
+	return principal, nil
+}
+
+// GetUsage list the daily usage of a key between from and to
+func (a *apiKeyUsecase) GetUsage(ctx context.Context, id int64, from time.Time, to time.Time) ([]*models.APIKeyUsage, error) {
+	ctx, cancel := context.WithTimeout(ctx, a.contextTimeout)
+	defer cancel()
+
+	if to.Before(from) {
+		return nil, models.ErrBadParamInput
+	}
+
+	if _, err := a.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return a.repo.GetUsage(ctx, id, from, to)
+}
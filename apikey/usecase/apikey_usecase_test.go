@@ -0,0 +1,169 @@
+package usecase_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/soerjadi/exam/apikey/mocks"
+	"github.com/soerjadi/exam/apikey/usecase"
+	"github.com/soerjadi/exam/audit/audittest"
+	auditMocks "github.com/soerjadi/exam/audit/mocks"
+	"github.com/soerjadi/exam/auth"
+	"github.com/soerjadi/exam/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gopkg.in/guregu/null.v3"
+)
+
+func admin() context.Context {
+	return auth.WithClaims(context.TODO(), &auth.Claims{Subject: 1, Roles: []string{auth.RoleCustomer, auth.RoleAdmin}})
+}
+
+// keyManager is an API key allowed to manage keys and read the catalog only
+func keyManager() context.Context {
+	return auth.WithPrincipal(context.TODO(), &auth.KeyPrincipal{ID: 9, Permissions: []auth.Permission{auth.KeyManage, auth.CatalogRead}})
+}
+
+func TestCreate(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockAudit := new(auditMocks.Usecase)
+		mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.APIKey")).Return(nil).Once()
+		mockAudit.On("Record", mock.Anything, models.AuditAPIKey, mock.AnythingOfType("int64"), models.AuditCreate, mock.Anything,
+			mock.MatchedBy(func(key *models.APIKey) bool { return key.Key == "" })).Return(nil).Once()
+
+		a := usecase.NewAPIKeyUsecase(mockRepo, mockAudit, time.Second*2)
+		key := models.APIKey{Name: "erp", Permissions: []string{string(auth.CatalogRead)}}
+		err := a.Create(keyManager(), &key)
+
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(key.Key, key.Prefix))
+		assert.Len(t, key.Hash, 64)
+		assert.NotContains(t, key.Hash, key.Key)
+		mockRepo.AssertExpectations(t)
+		mockAudit.AssertExpectations(t)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		mockAudit := audittest.NewUsecase()
+		a := usecase.NewAPIKeyUsecase(new(mocks.Repository), mockAudit, time.Second*2)
+
+		err := a.Create(admin(), &models.APIKey{Name: "erp", Permissions: []string{"everything"}})
+		assert.Equal(t, models.ErrBadParamInput, err)
+
+		err = a.Create(admin(), &models.APIKey{Name: "erp"})
+		assert.Equal(t, models.ErrBadParamInput, err)
+		audittest.AssertNothingRecorded(t, mockAudit)
+	})
+
+	t.Run("permission the caller does not hold", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockAudit := audittest.NewUsecase()
+		a := usecase.NewAPIKeyUsecase(mockRepo, mockAudit, time.Second*2)
+
+		key := models.APIKey{Name: "erp", Permissions: []string{string(auth.CatalogRead), string(auth.OrderWrite)}}
+		err := a.Create(keyManager(), &key)
+
+		assert.Equal(t, models.ErrForbidden, err)
+		assert.Empty(t, key.Key)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		audittest.AssertNothingRecorded(t, mockAudit)
+	})
+
+	t.Run("anonymous", func(t *testing.T) {
+		a := usecase.NewAPIKeyUsecase(new(mocks.Repository), audittest.NewUsecase(), time.Second*2)
+		err := a.Create(context.TODO(), &models.APIKey{Name: "erp", Permissions: []string{string(auth.CatalogRead)}})
+
+		assert.Equal(t, models.ErrUnauthorized, err)
+	})
+}
+
+func TestRotate(t *testing.T) {
+	before := &models.APIKey{ID: 3, Name: "erp", Prefix: "ak_12345678", Hash: "abc"}
+	mockRepo := new(mocks.Repository)
+	mockRepo.On("GetByID", mock.Anything, int64(3)).Return(before, nil).Once()
+	mockRepo.On("UpdateHash", mock.Anything, mock.MatchedBy(func(key *models.APIKey) bool {
+		return key.ID == 3 && key.Hash != "abc"
+	})).Return(nil).Once()
+
+	mockAudit := audittest.NewUsecase()
+	a := usecase.NewAPIKeyUsecase(mockRepo, mockAudit, time.Second*2)
+	key, err := a.Rotate(admin(), int64(3))
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, key.Key)
+	assert.NotEqual(t, before.Prefix, key.Prefix)
+	mockRepo.AssertExpectations(t)
+
+	// the new secret itself never reach the audit log
+	masked := *key
+	masked.Key = ""
+	audittest.AssertRecorded(t, mockAudit, models.AuditAPIKey, int64(3), models.AuditUpdate, before, &masked)
+}
+
+func TestRotateMorePermissions(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockRepo.On("GetByID", mock.Anything, int64(3)).
+		Return(&models.APIKey{ID: 3, Name: "erp", Permissions: []string{string(auth.OrderWrite)}}, nil).Once()
+
+	a := usecase.NewAPIKeyUsecase(mockRepo, audittest.NewUsecase(), time.Second*2)
+	key, err := a.Rotate(keyManager(), int64(3))
+
+	assert.Equal(t, models.ErrForbidden, err)
+	assert.Nil(t, key)
+	mockRepo.AssertNotCalled(t, "UpdateHash", mock.Anything, mock.Anything)
+}
+
+func TestAuthenticate(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockRepo.On("GetByHash", mock.Anything, mock.AnythingOfType("string")).
+			Return(&models.APIKey{ID: 3, Permissions: []string{string(auth.OrderRead)}}, nil).Once()
+		mockRepo.On("Touch", mock.Anything, int64(3), mock.AnythingOfType("time.Time")).Return(nil).Once()
+
+		a := usecase.NewAPIKeyUsecase(mockRepo, audittest.NewUsecase(), time.Second*2)
+		principal, err := a.Authenticate(context.TODO(), "ak_0123456789abcdef")
+
+		assert.NoError(t, err)
+		assert.True(t, principal.Allowed(auth.OrderRead))
+		assert.False(t, principal.Allowed(auth.OrderWrite))
+		assert.Equal(t, "apikey:3", principal.Actor())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("unknown or revoked", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockRepo.On("GetByHash", mock.Anything, mock.AnythingOfType("string")).Return(nil, models.ErrNotFound).Once()
+
+		a := usecase.NewAPIKeyUsecase(mockRepo, audittest.NewUsecase(), time.Second*2)
+		_, err := a.Authenticate(context.TODO(), "ak_0123456789abcdef")
+
+		assert.Equal(t, models.ErrUnauthorized, err)
+	})
+
+	t.Run("not a key", func(t *testing.T) {
+		a := usecase.NewAPIKeyUsecase(new(mocks.Repository), audittest.NewUsecase(), time.Second*2)
+		_, err := a.Authenticate(context.TODO(), "0123456789abcdef")
+
+		assert.Equal(t, models.ErrUnauthorized, err)
+	})
+}
+
+func TestRevoke(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockRepo.On("GetByID", mock.Anything, int64(3)).Return(&models.APIKey{ID: 3}, nil).Once()
+	mockRepo.On("Revoke", mock.Anything, int64(3), mock.AnythingOfType("time.Time")).Return(nil).Once()
+
+	mockAudit := new(auditMocks.Usecase)
+	mockAudit.On("Record", mock.Anything, models.AuditAPIKey, int64(3), models.AuditUpdate, mock.Anything,
+		mock.MatchedBy(func(key *models.APIKey) bool { return key.RevokedAt != null.Time{} })).Return(nil).Once()
+
+	a := usecase.NewAPIKeyUsecase(mockRepo, mockAudit, time.Second*2)
+	err := a.Revoke(context.TODO(), int64(3))
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockAudit.AssertExpectations(t)
+}
@@ -0,0 +1,29 @@
+// Package audittest help usecase tests check the audit entries written by the
+// usecase under test
+package audittest
+
+import (
+	"github.com/soerjadi/exam/audit/mocks"
+	"github.com/stretchr/testify/mock"
+)
+
+// NewUsecase return a mock of audit.Usecase accepting every entry, the
+// entries a test expect are checked with AssertRecorded
+func NewUsecase() *mocks.Usecase {
+	mockAudit := new(mocks.Usecase)
+	mockAudit.On("Record", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("int64"),
+		mock.AnythingOfType("string"), mock.Anything, mock.Anything).Return(nil)
+
+	return mockAudit
+}
+
+// AssertRecorded assert action on the entity id was recorded with the before
+// and after states given
+func AssertRecorded(t mock.TestingT, mockAudit *mocks.Usecase, entity string, id int64, action string, before interface{}, after interface{}) bool {
+	return mockAudit.AssertCalled(t, "Record", mock.Anything, entity, id, action, before, after)
+}
+
+// AssertNothingRecorded assert no entry was recorded, for changes which failed
+func AssertNothingRecorded(t mock.TestingT, mockAudit *mocks.Usecase) bool {
+	return mockAudit.AssertNotCalled(t, "Record", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
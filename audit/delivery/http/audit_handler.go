@@ -0,0 +1,77 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/soerjadi/exam/audit"
+	"github.com/soerjadi/exam/utils"
+)
+
+// AuditHandler represent the http handler for audit log
+type AuditHandler struct {
+	AuditUsecase audit.Usecase
+}
+
+// NewAuditHandler initialize audit log resource endpoint
+func NewAuditHandler(router *mux.Router, usecase audit.Usecase) *mux.Router {
+	handler := &AuditHandler{
+		AuditUsecase: usecase,
+	}
+
+	a := router.PathPrefix("/v1/audit").Subrouter()
+	a.HandleFunc("", handler.GetByEntity).Methods("GET")
+
+	return a
+}
+
+// GetByEntity list the change history of an entity, e.g. /v1/audit?entity=product&id=1
+func (h *AuditHandler) GetByEntity(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	entityType := params.Get("entity")
+	if entityType == "" {
+		utils.Error(w, http.StatusBadRequest, "entity is required")
+		return
+	}
+
+	id, err := strconv.ParseInt(params.Get("id"), 0, 64)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	limit, err := strconv.ParseInt(params.Get("limit"), 0, 64)
+	if err != nil {
+		limit = 0
+	}
+
+	offset, err := strconv.ParseInt(params.Get("offset"), 0, 64)
+	if err != nil {
+		offset = 0
+	}
+
+	if limit == 0 {
+		limit = 10
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	logs, found, err := h.AuditUsecase.GetByEntity(ctx, entityType, id, offset, limit)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entriesResult := &utils.EntriesResponse{
+		Data:  logs,
+		Found: found,
+	}
+
+	utils.JSON(w, http.StatusOK, entriesResult)
+}
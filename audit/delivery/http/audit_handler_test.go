@@ -0,0 +1,54 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	auditHttp "github.com/soerjadi/exam/audit/delivery/http"
+	"github.com/soerjadi/exam/audit/mocks"
+	"github.com/soerjadi/exam/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetByEntity(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockLogs := []*models.AuditLog{
+		&models.AuditLog{ID: 1, EntityType: models.AuditProduct, EntityID: 8, Action: models.AuditCreate},
+	}
+
+	mockUsecase.On("GetByEntity", mock.Anything, models.AuditProduct, int64(8), int64(0), int64(10)).
+		Return(mockLogs, int64(1), nil)
+
+	req, err := http.NewRequest("GET", "/v1/audit?entity=product&id=8", strings.NewReader(""))
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	handler := auditHttp.AuditHandler{
+		AuditUsecase: mockUsecase,
+	}
+
+	handler.GetByEntity(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestGetByEntityWithoutEntity(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+
+	req, err := http.NewRequest("GET", "/v1/audit?id=8", strings.NewReader(""))
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	handler := auditHttp.AuditHandler{
+		AuditUsecase: mockUsecase,
+	}
+
+	handler.GetByEntity(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
@@ -0,0 +1,67 @@
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// Change hold value of a single field before and after it changed
+type Change struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// Diff compare JSON representation of before and after and return only the
+// changed fields keyed by their JSON name. Either side may be nil, e.g.
+// before is nil on create and after is nil on delete.
+func Diff(before interface{}, after interface{}) (json.RawMessage, error) {
+	oldFields, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+
+	newFields, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]Change)
+	for key, oldValue := range oldFields {
+		newValue, ok := newFields[key]
+		if !ok || !reflect.DeepEqual(oldValue, newValue) {
+			changes[key] = Change{Old: oldValue, New: newValue}
+		}
+	}
+
+	for key, newValue := range newFields {
+		if _, ok := oldFields[key]; !ok {
+			changes[key] = Change{New: newValue}
+		}
+	}
+
+	return json.Marshal(changes)
+}
+
+func fields(src interface{}) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	if src == nil || reflect.ValueOf(src).Kind() == reflect.Ptr && reflect.ValueOf(src).IsNil() {
+		return result, nil
+	}
+
+	body, err := json.Marshal(src)
+	if err != nil {
+		return nil, err
+	}
+
+	// slices (e.g. every price of a product) are compared as a whole
+	if err = json.Unmarshal(body, &result); err != nil {
+		var value interface{}
+		if err = json.Unmarshal(body, &value); err != nil {
+			return nil, err
+		}
+
+		result = map[string]interface{}{"value": value}
+	}
+
+	return result, nil
+}
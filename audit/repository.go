@@ -0,0 +1,13 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// Repository represent the audit log repository contract
+type Repository interface {
+	Create(ctx context.Context, log *models.AuditLog) error
+	GetByEntity(ctx context.Context, entityType string, entityID int64, offset int64, limit int64) ([]*models.AuditLog, int64, error)
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/soerjadi/exam/audit"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/utils"
+)
+
+type pgAuditRepository struct {
+	Conn *sql.DB
+}
+
+var logger = utils.LogBuilder(true)
+
+// NewPGAuditRepository is bridge to create an object from audit.Repository interface
+func NewPGAuditRepository(Conn *sql.DB) audit.Repository {
+	return &pgAuditRepository{Conn}
+}
+
+func (a *pgAuditRepository) fetch(ctx context.Context, query string, args ...interface{}) ([]*models.AuditLog, error) {
+	rows, err := a.Conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Error(err)
+		}
+	}()
+
+	result := make([]*models.AuditLog, 0)
+	for rows.Next() {
+		t := new(models.AuditLog)
+		var diff []byte
+
+		err = rows.Scan(
+			&t.ID,
+			&t.EntityType,
+			&t.EntityID,
+			&t.Action,
+			&t.Actor,
+			&t.RequestID,
+			&diff,
+			&t.Created,
+		)
+
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+
+		t.Diff = diff
+		result = append(result, t)
+	}
+
+	return result, nil
+}
+
+func (a *pgAuditRepository) fetchRow(ctx context.Context, query string, args ...interface{}) (*sql.Row, error) {
+	stmt, err := a.Conn.PrepareContext(ctx, query)
+
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	defer func() {
+		err := stmt.Close()
+		if err != nil {
+			logger.Error(err)
+		}
+	}()
+
+	return stmt.QueryRow(args...), nil
+}
+
+func (a *pgAuditRepository) Create(ctx context.Context, log *models.AuditLog) error {
+	query := `INSERT INTO audit_log(entity_type, entity_id, action, actor, request_id, diff) VALUES(?, ?, ?, ?, ?, ?) returning id`
+	stmt, err := a.Conn.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	result, err := stmt.ExecContext(ctx, log.EntityType, log.EntityID, log.Action, log.Actor, log.RequestID, []byte(log.Diff))
+	if err != nil {
+		return err
+	}
+
+	lastID, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	log.ID = lastID
+	return nil
+}
+
+func (a *pgAuditRepository) GetByEntity(ctx context.Context, entityType string, entityID int64, offset int64, limit int64) ([]*models.AuditLog, int64, error) {
+	query := `SELECT id, entity_type, entity_id, action, actor, request_id, diff, created FROM audit_log WHERE entity_type = ? AND entity_id = ? ORDER BY created DESC, id DESC LIMIT ? OFFSET ?`
+	qCount := `SELECT count(id) FROM audit_log WHERE entity_type = ? AND entity_id = ?`
+
+	result, err := a.fetch(ctx, query, entityType, entityID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := a.fetchRow(ctx, qCount, entityType, entityID)
+	if err != nil {
+		logger.Error(err)
+		return nil, 0, err
+	}
+
+	var count int64
+	err = rows.Scan(&count)
+
+	if err != nil {
+		logger.Error(err)
+		return nil, 0, err
+	}
+
+	return result, count, nil
+}
@@ -0,0 +1,68 @@
+package repository_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/soerjadi/exam/audit/repository"
+	"github.com/soerjadi/exam/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	log := &models.AuditLog{
+		EntityType: models.AuditProduct,
+		EntityID:   8,
+		Action:     models.AuditUpdate,
+		Actor:      "admin",
+		RequestID:  "abc",
+		Diff:       json.RawMessage(`{"name":{"old":"a","new":"b"}}`),
+	}
+
+	query := "INSERT INTO audit_log\\(entity_type, entity_id, action, actor, request_id, diff\\) VALUES\\(\\?, \\?, \\?, \\?, \\?, \\?\\) returning id"
+	prep := mock.ExpectPrepare(query)
+	prep.ExpectExec().WithArgs(log.EntityType, log.EntityID, log.Action, log.Actor, log.RequestID, []byte(log.Diff)).
+		WillReturnResult(sqlmock.NewResult(3, 1))
+
+	a := repository.NewPGAuditRepository(db)
+
+	err = a.Create(context.TODO(), log)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), log.ID)
+}
+
+func TestGetByEntity(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "entity_type", "entity_id", "action", "actor", "request_id", "diff", "created"}).
+		AddRow(2, "product", 8, "update", "admin", "abc", []byte(`{"name":{"old":"a","new":"b"}}`), time.Now()).
+		AddRow(1, "product", 8, "create", "admin", "abd", []byte(`{"name":{"old":null,"new":"a"}}`), time.Now())
+
+	rowCount := sqlmock.NewRows([]string{"count"}).AddRow(2)
+
+	query := "SELECT id, entity_type, entity_id, action, actor, request_id, diff, created FROM audit_log WHERE entity_type = \\? AND entity_id = \\? ORDER BY created DESC, id DESC LIMIT \\? OFFSET \\?"
+	countQuery := "SELECT count\\(id\\) FROM audit_log WHERE entity_type = \\? AND entity_id = \\?"
+
+	mock.ExpectQuery(query).WithArgs("product", int64(8), int64(10), int64(0)).WillReturnRows(rows)
+	mock.ExpectPrepare(countQuery).ExpectQuery().WithArgs("product", int64(8)).WillReturnRows(rowCount)
+
+	a := repository.NewPGAuditRepository(db)
+	logs, found, err := a.GetByEntity(context.TODO(), "product", int64(8), int64(0), int64(10))
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), found)
+	assert.Len(t, logs, 2)
+	assert.JSONEq(t, `{"name":{"old":"a","new":"b"}}`, string(logs[0].Diff))
+}
@@ -0,0 +1,13 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// Usecase represent the audit log usecase
+type Usecase interface {
+	Record(ctx context.Context, entityType string, entityID int64, action string, before interface{}, after interface{}) error
+	GetByEntity(ctx context.Context, entityType string, entityID int64, offset int64, limit int64) ([]*models.AuditLog, int64, error)
+}
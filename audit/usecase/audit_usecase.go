@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/soerjadi/exam/audit"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/utils"
+)
+
+type auditUsecase struct {
+	repo           audit.Repository
+	contextTimeout time.Duration
+}
+
+// NewAuditUsecase will create object that represent of audit.Usecase interface
+func NewAuditUsecase(a audit.Repository, timeout time.Duration) audit.Usecase {
+	return &auditUsecase{
+		repo:           a,
+		contextTimeout: timeout,
+	}
+}
+
+func (a *auditUsecase) Record(ctx context.Context, entityType string, entityID int64, action string, before interface{}, after interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, a.contextTimeout)
+	defer cancel()
+
+	diff, err := audit.Diff(before, after)
+	if err != nil {
+		return err
+	}
+
+	log := &models.AuditLog{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		Actor:      utils.Actor(ctx),
+		RequestID:  utils.RequestID(ctx),
+		Diff:       diff,
+		Created:    time.Now(),
+	}
+
+	return a.repo.Create(ctx, log)
+}
+
+func (a *auditUsecase) GetByEntity(ctx context.Context, entityType string, entityID int64, offset int64, limit int64) ([]*models.AuditLog, int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, a.contextTimeout)
+	defer cancel()
+
+	logs, found, err := a.repo.GetByEntity(ctx, entityType, entityID, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return logs, found, nil
+}
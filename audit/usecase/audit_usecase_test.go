@@ -0,0 +1,83 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/soerjadi/exam/audit/mocks"
+	"github.com/soerjadi/exam/audit/usecase"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRecord(t *testing.T) {
+	mockAuditRepo := new(mocks.Repository)
+	before := &models.Product{ID: 8, Name: "product 8", SKU: "sku8"}
+	after := &models.Product{ID: 8, Name: "product eight", SKU: "sku8"}
+
+	ctx := utils.WithActor(utils.WithRequestID(context.TODO(), "req-1"), "admin")
+
+	t.Run("update", func(t *testing.T) {
+		var stored *models.AuditLog
+		mockAuditRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.AuditLog")).
+			Run(func(args mock.Arguments) { stored = args.Get(1).(*models.AuditLog) }).
+			Return(nil).Once()
+
+		a := usecase.NewAuditUsecase(mockAuditRepo, time.Second*2)
+		err := a.Record(ctx, models.AuditProduct, before.ID, models.AuditUpdate, before, after)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "admin", stored.Actor)
+		assert.Equal(t, "req-1", stored.RequestID)
+		assert.JSONEq(t, `{"name":{"old":"product 8","new":"product eight"}}`, string(stored.Diff))
+		mockAuditRepo.AssertExpectations(t)
+	})
+
+	t.Run("delete without identity", func(t *testing.T) {
+		var stored *models.AuditLog
+		mockAuditRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.AuditLog")).
+			Run(func(args mock.Arguments) { stored = args.Get(1).(*models.AuditLog) }).
+			Return(nil).Once()
+
+		a := usecase.NewAuditUsecase(mockAuditRepo, time.Second*2)
+		err := a.Record(context.TODO(), models.AuditProduct, before.ID, models.AuditDelete, before, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, utils.AnonymousActor, stored.Actor)
+		assert.Contains(t, string(stored.Diff), `"SKU":{"old":"sku8","new":null}`)
+		mockAuditRepo.AssertExpectations(t)
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		mockAuditRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.AuditLog")).
+			Return(errors.New("Unexpected error")).Once()
+
+		a := usecase.NewAuditUsecase(mockAuditRepo, time.Second*2)
+		err := a.Record(ctx, models.AuditProduct, after.ID, models.AuditCreate, nil, after)
+
+		assert.Error(t, err)
+		mockAuditRepo.AssertExpectations(t)
+	})
+}
+
+func TestGetByEntity(t *testing.T) {
+	mockAuditRepo := new(mocks.Repository)
+	mockLogs := []*models.AuditLog{
+		&models.AuditLog{ID: 1, EntityType: models.AuditOrder, EntityID: 2, Action: models.AuditCreate},
+	}
+
+	mockAuditRepo.On("GetByEntity", mock.Anything, models.AuditOrder, int64(2), int64(0), int64(10)).
+		Return(mockLogs, int64(1), nil).Once()
+
+	a := usecase.NewAuditUsecase(mockAuditRepo, time.Second*2)
+	logs, found, err := a.GetByEntity(context.TODO(), models.AuditOrder, int64(2), int64(0), int64(10))
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), found)
+	assert.Equal(t, mockLogs, logs)
+	mockAuditRepo.AssertExpectations(t)
+}
@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// Principal is who a request is made on behalf of, a customer signed in with
+// an access token or an integration using an API key
+type Principal interface {
+	Allowed(permission Permission) bool
+	// Actor name the principal in audit logs
+	Actor() string
+}
+
+type principalKey struct{}
+
+// WithPrincipal return copy of ctx carrying principal
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFrom return principal carried by ctx
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	return principal, ok && principal != nil
+}
+
+// WithClaims return copy of ctx carrying claims of the authenticated customer
+func WithClaims(ctx context.Context, claims *Claims) context.Context {
+	// a nil *Claims would be a non nil Principal
+	if claims == nil {
+		return WithPrincipal(ctx, nil)
+	}
+
+	return WithPrincipal(ctx, claims)
+}
+
+// ClaimsFrom return claims carried by ctx, requests made with an API key
+// carry none
+func ClaimsFrom(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(principalKey{}).(*Claims)
+	return claims, ok && claims != nil
+}
+
+// Actor name the customer in audit logs
+func (c *Claims) Actor() string {
+	return fmt.Sprintf("customer:%d", c.Subject)
+}
+
+// KeyPrincipal is an API key, allowed exactly the permissions it was scoped to
+type KeyPrincipal struct {
+	ID          int64
+	Permissions []Permission
+}
+
+// Allowed tell whether the key was scoped to permission
+func (k *KeyPrincipal) Allowed(permission Permission) bool {
+	for _, granted := range k.Permissions {
+		if granted == permission {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Actor name the key in audit logs
+func (k *KeyPrincipal) Actor() string {
+	return fmt.Sprintf("apikey:%d", k.ID)
+}
+
+// CustomerID return ID of the authenticated customer
+func CustomerID(ctx context.Context) (int64, bool) {
+	claims, ok := ClaimsFrom(ctx)
+	if !ok {
+		return 0, false
+	}
+
+	return claims.Subject, true
+}
+
+// CustomerKey return ID of the authenticated customer as it is stored with
+// carts and coupon redemptions, empty for anonymous requests
+func CustomerKey(ctx context.Context) string {
+	id, ok := CustomerID(ctx)
+	if !ok {
+		return ""
+	}
+
+	return strconv.FormatInt(id, 10)
+}
@@ -0,0 +1,53 @@
+package auth_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/soerjadi/exam/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextCustomer(t *testing.T) {
+	ctx := auth.WithClaims(context.TODO(), &auth.Claims{Subject: 4})
+
+	principal, ok := auth.PrincipalFrom(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "customer:4", principal.Actor())
+
+	claims, ok := auth.ClaimsFrom(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, int64(4), claims.Subject)
+
+	id, ok := auth.CustomerID(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, int64(4), id)
+	assert.Equal(t, "4", auth.CustomerKey(ctx))
+}
+
+func TestContextAPIKey(t *testing.T) {
+	ctx := auth.WithPrincipal(context.TODO(), &auth.KeyPrincipal{ID: 3})
+
+	principal, ok := auth.PrincipalFrom(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "apikey:3", principal.Actor())
+
+	// an API key is no customer
+	_, ok = auth.ClaimsFrom(ctx)
+	assert.False(t, ok)
+	_, ok = auth.CustomerID(ctx)
+	assert.False(t, ok)
+	assert.Empty(t, auth.CustomerKey(ctx))
+}
+
+func TestContextAnonymous(t *testing.T) {
+	_, ok := auth.PrincipalFrom(context.TODO())
+	assert.False(t, ok)
+
+	// a nil principal is anonymous too
+	_, ok = auth.PrincipalFrom(auth.WithClaims(context.TODO(), nil))
+	assert.False(t, ok)
+	_, ok = auth.ClaimsFrom(auth.WithClaims(context.TODO(), nil))
+	assert.False(t, ok)
+	assert.Empty(t, auth.CustomerKey(context.TODO()))
+}
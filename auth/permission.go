@@ -0,0 +1,77 @@
+package auth
+
+import "context"
+
+// Permission allow a group of operations, routes declare the one they need
+type Permission string
+
+// Permissions checked by the routes
+const (
+	CatalogRead   Permission = "catalog:read"
+	CatalogWrite  Permission = "catalog:write"
+	OrderRead     Permission = "order:read"
+	OrderWrite    Permission = "order:write"
+	AuditRead     Permission = "audit:read"
+	ReportRead    Permission = "report:read"
+	RoleManage    Permission = "role:manage"
+	KeyManage     Permission = "apikey:manage"
+	WebhookManage Permission = "webhook:manage"
+)
+
+// Permissions list every permission an API key can be scoped to
+var Permissions = []Permission{CatalogRead, CatalogWrite, OrderRead, OrderWrite, AuditRead, ReportRead, RoleManage, KeyManage, WebhookManage}
+
+// Roles assignable to an account
+const (
+	RoleCustomer      = "customer"
+	RoleViewer        = "viewer"
+	RoleCatalogEditor = "catalog_editor"
+	RoleOrderManager  = "order_manager"
+	RoleAdmin         = "admin"
+)
+
+// RolePermissions list what every role is allowed to do. Every account is a
+// customer, which grant no back-office permission, other roles are assigned
+// by an admin.
+var RolePermissions = map[string][]Permission{
+	RoleCustomer:      {},
+	RoleViewer:        {CatalogRead, OrderRead, AuditRead, ReportRead},
+	RoleCatalogEditor: {CatalogRead, CatalogWrite, AuditRead},
+	RoleOrderManager:  {CatalogRead, OrderRead, OrderWrite, ReportRead},
+	RoleAdmin:         Permissions,
+}
+
+// ValidRole tell whether role is one of RolePermissions
+func ValidRole(role string) bool {
+	_, ok := RolePermissions[role]
+	return ok
+}
+
+// ValidPermission tell whether permission is one of Permissions
+func ValidPermission(permission Permission) bool {
+	for _, p := range Permissions {
+		if p == permission {
+			return true
+		}
+	}
+
+	return false
+}
+
+// KeyAuthenticator resolve the API key sent by a client into its principal
+type KeyAuthenticator interface {
+	Authenticate(ctx context.Context, key string) (*KeyPrincipal, error)
+}
+
+// Allowed tell whether one of the roles of claims grant permission
+func (c *Claims) Allowed(permission Permission) bool {
+	for _, role := range c.Roles {
+		for _, granted := range RolePermissions[role] {
+			if granted == permission {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,47 @@
+package auth_test
+
+import (
+	"testing"
+
+	"github.com/soerjadi/exam/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClaimsAllowed(t *testing.T) {
+	tests := []struct {
+		name       string
+		roles      []string
+		permission auth.Permission
+		allowed    bool
+	}{
+		{"customer only", []string{auth.RoleCustomer}, auth.CatalogRead, false},
+		{"no role", nil, auth.OrderRead, false},
+		{"viewer read", []string{auth.RoleViewer}, auth.OrderRead, true},
+		{"viewer write", []string{auth.RoleViewer}, auth.OrderWrite, false},
+		{"catalog editor", []string{auth.RoleCatalogEditor}, auth.CatalogWrite, true},
+		{"catalog editor orders", []string{auth.RoleCatalogEditor}, auth.OrderWrite, false},
+		{"any role grant", []string{auth.RoleCatalogEditor, auth.RoleOrderManager}, auth.OrderWrite, true},
+		{"unknown role", []string{"root"}, auth.CatalogRead, false},
+		{"admin", []string{auth.RoleAdmin}, auth.WebhookManage, true},
+	}
+
+	for _, test := range tests {
+		claims := &auth.Claims{Subject: 4, Roles: test.roles}
+		assert.Equal(t, test.allowed, claims.Allowed(test.permission), test.name)
+	}
+}
+
+func TestKeyPrincipalAllowed(t *testing.T) {
+	key := &auth.KeyPrincipal{ID: 3, Permissions: []auth.Permission{auth.OrderRead}}
+
+	assert.True(t, key.Allowed(auth.OrderRead))
+	assert.False(t, key.Allowed(auth.OrderWrite))
+	assert.Equal(t, "apikey:3", key.Actor())
+}
+
+func TestValid(t *testing.T) {
+	assert.True(t, auth.ValidRole(auth.RoleOrderManager))
+	assert.False(t, auth.ValidRole("root"))
+	assert.True(t, auth.ValidPermission(auth.KeyManage))
+	assert.False(t, auth.ValidPermission(auth.Permission("order:delete")))
+}
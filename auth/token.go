@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/soerjadi/exam/utils"
+)
+
+// ErrInvalidToken will throw if the token is malformed, forged or expired
+var ErrInvalidToken = errors.New("Invalid token")
+
+// header of every token, only HMAC SHA-256 is ever issued or accepted
+var header = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// ScopeStream is the scope of stream tokens. Browsers cannot send headers
+// with EventSource, so they open streams with a short-lived token passed in
+// the StreamTokenParam query parameter or cookie, which is accepted nowhere
+// else.
+const ScopeStream = "stream"
+
+// StreamTokenParam is the query parameter and cookie carrying stream tokens
+const StreamTokenParam = "stream_token"
+
+// Claims carried by an access token, they are the principal of the request.
+// Roles are copied in when the token is issued and are not looked up again,
+// a role assigned or revoked only apply to the tokens issued after it. Keep
+// the access token TTL short, refreshing pick up the current roles. Access
+// tokens have no Scope.
+type Claims struct {
+	ID        string   `json:"jti"`
+	Subject   int64    `json:"sub"`
+	Email     string   `json:"email"`
+	Roles     []string `json:"roles,omitempty"`
+	Scope     string   `json:"scope,omitempty"`
+	IssuedAt  int64    `json:"iat"`
+	ExpiresAt int64    `json:"exp"`
+}
+
+// Revocations tell whether an access token was revoked before it expired,
+// e.g. because the customer logged out
+type Revocations interface {
+	IsRevoked(ctx context.Context, id string) (bool, error)
+}
+
+// Signer issue and check JWT signed with a shared secret
+type Signer struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+// NewSigner create Signer for tokens valid ttl after they are issued
+func NewSigner(secret string, ttl time.Duration) *Signer {
+	return &Signer{
+		secret: []byte(secret),
+		ttl:    ttl,
+	}
+}
+
+// TTL return how long issued tokens are valid
+func (s *Signer) TTL() time.Duration {
+	return s.ttl
+}
+
+func (s *Signer) sign(payload string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(header + "." + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Sign issue a token for claims, IssuedAt and ExpiresAt are set from now and
+// a random ID is given when claims has none
+func (s *Signer) Sign(claims Claims, now time.Time) (string, error) {
+	if claims.ID == "" {
+		claims.ID = utils.RandString(16)
+	}
+
+	claims.IssuedAt = now.Unix()
+	claims.ExpiresAt = now.Add(s.ttl).Unix()
+
+	body, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(body)
+	return header + "." + payload + "." + s.sign(payload), nil
+}
+
+// Verify return claims of token when it was signed by s and is not expired at now
+func (s *Signer) Verify(token string, now time.Time) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != header {
+		return nil, ErrInvalidToken
+	}
+
+	if !hmac.Equal([]byte(parts[2]), []byte(s.sign(parts[1]))) {
+		return nil, ErrInvalidToken
+	}
+
+	body, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	claims := new(Claims)
+	if err = json.Unmarshal(body, claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if now.Unix() >= claims.ExpiresAt {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,84 @@
+package auth_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/soerjadi/exam/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignVerify(t *testing.T) {
+	signer := auth.NewSigner("s3cret", 15*time.Minute)
+	now := time.Now()
+
+	token, err := signer.Sign(auth.Claims{Subject: 4, Email: "jane@example.com", Roles: []string{auth.RoleViewer}}, now)
+	assert.NoError(t, err)
+
+	claims, err := signer.Verify(token, now.Add(time.Minute))
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), claims.Subject)
+	assert.Equal(t, "jane@example.com", claims.Email)
+	assert.Equal(t, []string{auth.RoleViewer}, claims.Roles)
+	assert.NotEmpty(t, claims.ID)
+	assert.Equal(t, now.Unix(), claims.IssuedAt)
+	assert.Equal(t, now.Add(15*time.Minute).Unix(), claims.ExpiresAt)
+}
+
+func TestVerifyInvalid(t *testing.T) {
+	signer := auth.NewSigner("s3cret", 15*time.Minute)
+	now := time.Now()
+
+	token, err := signer.Sign(auth.Claims{Subject: 4}, now)
+	assert.NoError(t, err)
+	parts := strings.Split(token, ".")
+
+	// same claims with the admin role added, signed with another secret
+	forged, err := auth.NewSigner("guess", 15*time.Minute).Sign(auth.Claims{Subject: 4, Roles: []string{auth.RoleAdmin}}, now)
+	assert.NoError(t, err)
+	forgedParts := strings.Split(forged, ".")
+
+	tampered := []byte(parts[2])
+	if tampered[0] == 'A' {
+		tampered[0] = 'B'
+	} else {
+		tampered[0] = 'A'
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		at    time.Time
+	}{
+		{"expired", token, now.Add(15 * time.Minute)},
+		{"tampered signature", parts[0] + "." + parts[1] + "." + string(tampered), now},
+		{"tampered claims", parts[0] + "." + forgedParts[1] + "." + parts[2], now},
+		{"other secret", forged, now},
+		{"other algorithm", "eyJhbGciOiJub25lIiwidHlwIjoiSldUIn0." + parts[1] + "." + parts[2], now},
+		{"missing signature", parts[0] + "." + parts[1], now},
+		{"garbage", "not-a-token", now},
+	}
+
+	for _, test := range tests {
+		claims, err := signer.Verify(test.token, test.at)
+
+		assert.Equal(t, auth.ErrInvalidToken, err, test.name)
+		assert.Nil(t, claims, test.name)
+	}
+}
+
+func TestSignKeepID(t *testing.T) {
+	signer := auth.NewSigner("s3cret", time.Minute)
+	now := time.Now()
+
+	token, err := signer.Sign(auth.Claims{ID: "abc", Subject: 4}, now)
+	assert.NoError(t, err)
+
+	claims, err := signer.Verify(token, now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "abc", claims.ID)
+	assert.Equal(t, time.Minute, signer.TTL())
+}
@@ -0,0 +1,272 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/soerjadi/exam/cart"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/utils"
+)
+
+type itemData struct {
+	ProductID int64 `json:"product_id"`
+	Amount    int64 `json:"amount"`
+}
+
+type checkoutData struct {
+	Coupon           string          `json:"coupon"`
+	AddressID        int64           `json:"address_id"`
+	Address          *models.Address `json:"address"`
+	ShippingMethodID int64           `json:"shipping_method_id"`
+}
+
+func (d checkoutData) toModel() *models.Checkout {
+	return &models.Checkout{
+		Coupon:           d.Coupon,
+		AddressID:        d.AddressID,
+		Address:          d.Address,
+		ShippingMethodID: d.ShippingMethodID,
+	}
+}
+
+// CartHandler represent the http handler for cart
+type CartHandler struct {
+	CartUsecase cart.Usecase
+}
+
+// NewCartHandler initialize cart resource endpoint
+func NewCartHandler(router *mux.Router, usecase cart.Usecase) *mux.Router {
+	handler := &CartHandler{
+		CartUsecase: usecase,
+	}
+
+	p := router.PathPrefix("/v1/cart").Subrouter()
+	p.HandleFunc("/add", handler.CreateCart).Methods("POST")
+	p.HandleFunc("/{token:[0-9a-f]+}", handler.GetCart).Methods("GET")
+	p.HandleFunc("/{token:[0-9a-f]+}/items", handler.AddItem).Methods("POST")
+	p.HandleFunc("/{token:[0-9a-f]+}/items/update", handler.UpdateItem).Methods("POST")
+	p.HandleFunc("/{token:[0-9a-f]+}/items/remove", handler.RemoveItem).Methods("GET")
+	p.HandleFunc("/{token:[0-9a-f]+}/merge", handler.Merge).Methods("POST")
+	p.HandleFunc("/{token:[0-9a-f]+}/shipping", handler.QuoteShipping).Methods("POST")
+	p.HandleFunc("/{token:[0-9a-f]+}/checkout", handler.Checkout).Methods("POST")
+
+	return p
+}
+
+func decode(r *http.Request, v interface{}) error {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, v)
+}
+
+// CreateCart endpoint to start a cart, the token returned is needed for
+// every other cart endpoint. The cart belong to the customer when the
+// request is authenticated.
+func (h *CartHandler) CreateCart(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	crt, err := h.CartUsecase.Create(ctx)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, crt)
+}
+
+// GetCart endpoint to get a cart priced at the time of the request
+func (h *CartHandler) GetCart(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	crt, err := h.CartUsecase.Get(ctx, mux.Vars(r)["token"])
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, crt)
+}
+
+// AddItem endpoint to put more of a product in a cart
+func (h *CartHandler) AddItem(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var data itemData
+	if err := decode(r, &data); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	crt, err := h.CartUsecase.AddItem(ctx, mux.Vars(r)["token"], data.ProductID, data.Amount)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, crt)
+}
+
+// UpdateItem endpoint to change amount of a product in a cart
+func (h *CartHandler) UpdateItem(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var data itemData
+	if err := decode(r, &data); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	crt, err := h.CartUsecase.UpdateItem(ctx, mux.Vars(r)["token"], data.ProductID, data.Amount)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, crt)
+}
+
+// RemoveItem endpoint to take a product out of a cart
+func (h *CartHandler) RemoveItem(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	productID, err := strconv.ParseInt(params.Get("product_id"), 0, 64)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	crt, err := h.CartUsecase.RemoveItem(ctx, mux.Vars(r)["token"], productID)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, crt)
+}
+
+// Merge endpoint to hand an anonymous cart over to the authenticated customer
+func (h *CartHandler) Merge(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	crt, err := h.CartUsecase.Merge(ctx, mux.Vars(r)["token"])
+
+	if err == models.ErrUnauthorized {
+		utils.Error(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, crt)
+}
+
+// QuoteShipping endpoint to list the shipping methods able to deliver a
+// cart to the given address with their cost
+func (h *CartHandler) QuoteShipping(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var data checkoutData
+	if err := decode(r, &data); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	quotes, err := h.CartUsecase.QuoteShipping(ctx, mux.Vars(r)["token"], data.toModel())
+
+	if err == models.ErrUnauthorized {
+		utils.Error(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entriesResult := &utils.EntriesResponse{
+		Data:  quotes,
+		Found: int64(len(quotes)),
+	}
+
+	utils.JSON(w, http.StatusOK, entriesResult)
+}
+
+// Checkout endpoint to place an order from a cart, shipped to the address
+// given when a shipping method is chosen
+func (h *CartHandler) Checkout(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var data checkoutData
+	if err := decode(r, &data); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	order, err := h.CartUsecase.Checkout(ctx, mux.Vars(r)["token"], data.toModel())
+
+	if err == models.ErrUnauthorized {
+		utils.Error(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, order)
+}
@@ -0,0 +1,112 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	cartHttp "github.com/soerjadi/exam/cart/delivery/http"
+	"github.com/soerjadi/exam/cart/mocks"
+	"github.com/soerjadi/exam/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAddItem(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("AddItem", mock.Anything, "abc123", int64(9), int64(3)).
+		Return(&models.Cart{ID: 1, Token: "abc123", Total: 30000.0}, nil)
+
+	req, err := http.NewRequest("POST", "/v1/cart/abc123/items", strings.NewReader(`{"product_id":9,"amount":3}`))
+	assert.NoError(t, err)
+	req = mux.SetURLVars(req, map[string]string{"token": "abc123"})
+
+	handler := cartHttp.CartHandler{
+		CartUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.AddItem(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"total":30000`)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestRemoveItem(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("RemoveItem", mock.Anything, "abc123", int64(9)).Return(&models.Cart{ID: 1, Token: "abc123"}, nil)
+
+	req, err := http.NewRequest("GET", "/v1/cart/abc123/items/remove?product_id=9", strings.NewReader(""))
+	assert.NoError(t, err)
+	req = mux.SetURLVars(req, map[string]string{"token": "abc123"})
+
+	handler := cartHttp.CartHandler{
+		CartUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.RemoveItem(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestCheckout(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("Checkout", mock.Anything, "abc123", &models.Checkout{Coupon: "XMAS"}).
+		Return(&models.Order{ID: 12, Total: 27000.0}, nil)
+
+	req, err := http.NewRequest("POST", "/v1/cart/abc123/checkout", strings.NewReader(`{"coupon":"XMAS"}`))
+	assert.NoError(t, err)
+	req = mux.SetURLVars(req, map[string]string{"token": "abc123"})
+
+	handler := cartHttp.CartHandler{
+		CartUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.Checkout(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"id":12`)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestCheckoutEmpty(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("Checkout", mock.Anything, "abc123", &models.Checkout{}).Return(nil, models.ErrBadParamInput)
+
+	req, err := http.NewRequest("POST", "/v1/cart/abc123/checkout", strings.NewReader(`{}`))
+	assert.NoError(t, err)
+	req = mux.SetURLVars(req, map[string]string{"token": "abc123"})
+
+	handler := cartHttp.CartHandler{
+		CartUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.Checkout(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestMergeAnonymous(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("Merge", mock.Anything, "abc123").Return(nil, models.ErrUnauthorized)
+
+	req, err := http.NewRequest("POST", "/v1/cart/abc123/merge", strings.NewReader(""))
+	assert.NoError(t, err)
+	req = mux.SetURLVars(req, map[string]string{"token": "abc123"})
+
+	handler := cartHttp.CartHandler{
+		CartUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.Merge(rec, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
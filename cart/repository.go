@@ -0,0 +1,23 @@
+package cart
+
+import (
+	"context"
+	"time"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// Repository represent the cart repository contract, every method join the
+// transaction carried by ctx when there is one
+type Repository interface {
+	GetByToken(ctx context.Context, token string) (*models.Cart, error)
+	GetOpenByCustomer(ctx context.Context, customer string, now time.Time) (*models.Cart, error)
+	GetItems(ctx context.Context, cartID int64) ([]*models.CartItem, error)
+	Create(ctx context.Context, cart *models.Cart) error
+	Update(ctx context.Context, cart *models.Cart) error
+	SaveItem(ctx context.Context, item *models.CartItem) error
+	RemoveItem(ctx context.Context, cartID int64, productID int64) error
+	CheckOut(ctx context.Context, cartID int64, orderID int64) error
+	Delete(ctx context.Context, id int64) error
+	PurgeExpired(ctx context.Context, now time.Time) (int64, error)
+}
@@ -0,0 +1,291 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/soerjadi/exam/cart"
+	"github.com/soerjadi/exam/database"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/utils"
+)
+
+type pgCartRepository struct {
+	Conn *sql.DB
+}
+
+var logger = utils.LogBuilder(true)
+
+// NewPGCartRepository is bridge to create an object from cart.Repository interface
+func NewPGCartRepository(Conn *sql.DB) cart.Repository {
+	return &pgCartRepository{Conn}
+}
+
+func (c *pgCartRepository) fetch(ctx context.Context, query string, args ...interface{}) ([]*models.Cart, error) {
+	rows, err := database.ExecutorFrom(ctx, c.Conn).QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Error(err)
+		}
+	}()
+
+	result := make([]*models.Cart, 0)
+	for rows.Next() {
+		t := new(models.Cart)
+
+		err = rows.Scan(
+			&t.ID,
+			&t.Token,
+			&t.Customer,
+			&t.OrderID,
+			&t.ExpiresAt,
+			&t.Created,
+			&t.Updated,
+		)
+
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+
+		result = append(result, t)
+	}
+
+	return result, nil
+}
+
+func (c *pgCartRepository) getOne(ctx context.Context, query string, args ...interface{}) (*models.Cart, error) {
+	carts, err := c.fetch(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(carts) == 0 {
+		return nil, models.ErrNotFound
+	}
+
+	return carts[0], nil
+}
+
+// GetByToken return the cart of token as long as it is not checked out
+func (c *pgCartRepository) GetByToken(ctx context.Context, token string) (*models.Cart, error) {
+	query := `SELECT id, token, customer, order_id, expires_at, created, updated
+		FROM carts WHERE token = ? AND order_id IS NULL`
+
+	return c.getOne(ctx, query, token)
+}
+
+// GetOpenByCustomer return the latest cart of customer still open at now
+func (c *pgCartRepository) GetOpenByCustomer(ctx context.Context, customer string, now time.Time) (*models.Cart, error) {
+	query := `SELECT id, token, customer, order_id, expires_at, created, updated
+		FROM carts WHERE customer = ? AND order_id IS NULL AND expires_at > ? ORDER BY id DESC LIMIT 1`
+
+	return c.getOne(ctx, query, customer, now)
+}
+
+func (c *pgCartRepository) GetItems(ctx context.Context, cartID int64) ([]*models.CartItem, error) {
+	query := `SELECT id, cart_id, product_id, amount FROM cart_item WHERE cart_id = ? ORDER BY id`
+
+	rows, err := database.ExecutorFrom(ctx, c.Conn).QueryContext(ctx, query, cartID)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Error(err)
+		}
+	}()
+
+	result := make([]*models.CartItem, 0)
+	for rows.Next() {
+		t := new(models.CartItem)
+
+		err = rows.Scan(
+			&t.ID,
+			&t.CartID,
+			&t.ProductID,
+			&t.Amount,
+		)
+
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+
+		result = append(result, t)
+	}
+
+	return result, nil
+}
+
+func (c *pgCartRepository) Create(ctx context.Context, cart *models.Cart) error {
+	query := `INSERT INTO carts(token, customer, expires_at) VALUES(?, ?, ?) returning id`
+
+	stmt, err := database.ExecutorFrom(ctx, c.Conn).PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	result, err := stmt.ExecContext(ctx, cart.Token, cart.Customer, cart.ExpiresAt)
+	if err != nil {
+		return err
+	}
+
+	lastID, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	cart.ID = lastID
+	return nil
+}
+
+// Update change owner and expiry of an open cart
+func (c *pgCartRepository) Update(ctx context.Context, cart *models.Cart) error {
+	query := `UPDATE carts SET customer = ?, expires_at = ?, updated = ? WHERE id = ? AND order_id IS NULL`
+
+	stmt, err := database.ExecutorFrom(ctx, c.Conn).PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	res, err := stmt.ExecContext(ctx, cart.Customer, cart.ExpiresAt, cart.Updated, cart.ID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected != 1 {
+		return models.ErrNotFound
+	}
+
+	return nil
+}
+
+// SaveItem store amount of a product in the cart, replacing the amount
+// already there
+func (c *pgCartRepository) SaveItem(ctx context.Context, item *models.CartItem) error {
+	query := `INSERT INTO cart_item(cart_id, product_id, amount) VALUES(?, ?, ?)
+		ON CONFLICT (cart_id, product_id) DO UPDATE SET amount = EXCLUDED.amount returning id`
+
+	stmt, err := database.ExecutorFrom(ctx, c.Conn).PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	result, err := stmt.ExecContext(ctx, item.CartID, item.ProductID, item.Amount)
+	if err != nil {
+		return err
+	}
+
+	lastID, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	item.ID = lastID
+	return nil
+}
+
+func (c *pgCartRepository) RemoveItem(ctx context.Context, cartID int64, productID int64) error {
+	query := `DELETE FROM cart_item WHERE cart_id = ? AND product_id = ?`
+
+	stmt, err := database.ExecutorFrom(ctx, c.Conn).PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	res, err := stmt.ExecContext(ctx, cartID, productID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected != 1 {
+		return models.ErrNotFound
+	}
+
+	return nil
+}
+
+// CheckOut close the cart with the order made from it, a cart already
+// checked out give ErrConflict
+func (c *pgCartRepository) CheckOut(ctx context.Context, cartID int64, orderID int64) error {
+	query := `UPDATE carts SET order_id = ?, updated = ? WHERE id = ? AND order_id IS NULL`
+
+	stmt, err := database.ExecutorFrom(ctx, c.Conn).PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	res, err := stmt.ExecContext(ctx, orderID, time.Now(), cartID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected != 1 {
+		return models.ErrConflict
+	}
+
+	return nil
+}
+
+func (c *pgCartRepository) Delete(ctx context.Context, id int64) error {
+	return database.RunInTx(ctx, c.Conn, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM cart_item WHERE cart_id = ?`, id); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx, `DELETE FROM carts WHERE id = ?`, id)
+		return err
+	})
+}
+
+// PurgeExpired delete carts left open past their expiry
+func (c *pgCartRepository) PurgeExpired(ctx context.Context, now time.Time) (int64, error) {
+	var purged int64
+	err := database.RunInTx(ctx, c.Conn, func(tx *sql.Tx) error {
+		query := `DELETE FROM cart_item WHERE cart_id IN (SELECT id FROM carts WHERE order_id IS NULL AND expires_at <= ?)`
+		if _, err := tx.ExecContext(ctx, query, now); err != nil {
+			return err
+		}
+
+		res, err := tx.ExecContext(ctx, `DELETE FROM carts WHERE order_id IS NULL AND expires_at <= ?`, now)
+		if err != nil {
+			return err
+		}
+
+		purged, err = res.RowsAffected()
+		return err
+	})
+
+	if err != nil {
+		logger.Error(err)
+		return 0, err
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,115 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/soerjadi/exam/cart/repository"
+	"github.com/soerjadi/exam/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var columns = []string{"id", "token", "customer", "order_id", "expires_at", "created", "updated"}
+
+func TestGetByToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows(columns).AddRow(1, "abc123", "", nil, time.Now().Add(time.Hour), time.Now(), nil)
+
+	query := "SELECT (.+) FROM carts WHERE token = \\? AND order_id IS NULL"
+	mock.ExpectQuery(query).WithArgs("abc123").WillReturnRows(rows)
+
+	c := repository.NewPGCartRepository(db)
+	crt, err := c.GetByToken(context.TODO(), "abc123")
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), crt.ID)
+	assert.False(t, crt.OrderID.Valid)
+}
+
+func TestGetOpenByCustomerNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Now()
+	query := "SELECT (.+) FROM carts WHERE customer = \\? AND order_id IS NULL AND expires_at > \\?"
+	mock.ExpectQuery(query).WithArgs("jane@example.com", now).WillReturnRows(sqlmock.NewRows(columns))
+
+	c := repository.NewPGCartRepository(db)
+	_, err = c.GetOpenByCustomer(context.TODO(), "jane@example.com", now)
+
+	assert.Equal(t, models.ErrNotFound, err)
+}
+
+func TestSaveItem(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	item := &models.CartItem{CartID: 1, ProductID: 9, Amount: 3}
+
+	query := "INSERT INTO cart_item\\(cart_id, product_id, amount\\) VALUES\\(\\?, \\?, \\?\\)\\s+ON CONFLICT \\(cart_id, product_id\\) DO UPDATE"
+	mock.ExpectPrepare(query).ExpectExec().WithArgs(int64(1), int64(9), int64(3)).WillReturnResult(sqlmock.NewResult(4, 1))
+
+	c := repository.NewPGCartRepository(db)
+	err = c.SaveItem(context.TODO(), item)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), item.ID)
+}
+
+func TestCheckOut(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	query := "UPDATE carts SET order_id = \\?, updated = \\? WHERE id = \\? AND order_id IS NULL"
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectPrepare(query).ExpectExec().WithArgs(int64(12), sqlmock.AnyArg(), int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		c := repository.NewPGCartRepository(db)
+		err = c.CheckOut(context.TODO(), int64(1), int64(12))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("already checked out", func(t *testing.T) {
+		mock.ExpectPrepare(query).ExpectExec().WithArgs(int64(13), sqlmock.AnyArg(), int64(1)).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		c := repository.NewPGCartRepository(db)
+		err = c.CheckOut(context.TODO(), int64(1), int64(13))
+
+		assert.Equal(t, models.ErrConflict, err)
+	})
+}
+
+func TestPurgeExpired(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM cart_item WHERE cart_id IN").WithArgs(now).WillReturnResult(sqlmock.NewResult(0, 5))
+	mock.ExpectExec("DELETE FROM carts WHERE order_id IS NULL AND expires_at <= \\?").WithArgs(now).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	c := repository.NewPGCartRepository(db)
+	purged, err := c.PurgeExpired(context.TODO(), now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), purged)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
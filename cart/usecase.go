@@ -0,0 +1,21 @@
+package cart
+
+import (
+	"context"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// Usecase represent the cart usecase, carts returned are priced at the time
+// of the call. The customer is the one authenticated in ctx.
+type Usecase interface {
+	Create(ctx context.Context) (*models.Cart, error)
+	Get(ctx context.Context, token string) (*models.Cart, error)
+	AddItem(ctx context.Context, token string, productID int64, amount int64) (*models.Cart, error)
+	UpdateItem(ctx context.Context, token string, productID int64, amount int64) (*models.Cart, error)
+	RemoveItem(ctx context.Context, token string, productID int64) (*models.Cart, error)
+	Merge(ctx context.Context, token string) (*models.Cart, error)
+	QuoteShipping(ctx context.Context, token string, checkout *models.Checkout) ([]*models.ShippingQuote, error)
+	Checkout(ctx context.Context, token string, checkout *models.Checkout) (*models.Order, error)
+	PurgeExpired(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,554 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/soerjadi/exam/address"
+	"github.com/soerjadi/exam/auth"
+	"github.com/soerjadi/exam/cart"
+	"github.com/soerjadi/exam/coupon"
+	"github.com/soerjadi/exam/database"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/order"
+	"github.com/soerjadi/exam/product"
+	price "github.com/soerjadi/exam/product_price"
+	"github.com/soerjadi/exam/promotion"
+	"github.com/soerjadi/exam/shipping"
+	"github.com/soerjadi/exam/tax"
+	"github.com/soerjadi/exam/utils"
+	"gopkg.in/guregu/null.v3"
+)
+
+type cartUsecase struct {
+	repo           cart.Repository
+	products       product.Usecase
+	prices         price.Usecase
+	promotions     promotion.Usecase
+	coupons        coupon.Usecase
+	orders         order.Usecase
+	addresses      address.Usecase
+	shipping       shipping.Usecase
+	taxes          tax.Usecase
+	tx             database.Transactor
+	ttl            time.Duration
+	contextTimeout time.Duration
+}
+
+// NewCartUsecase will create object that represent of cart.Usecase interface,
+// carts are kept ttl after their last change
+func NewCartUsecase(c cart.Repository, p product.Usecase, pp price.Usecase, promo promotion.Usecase, cp coupon.Usecase,
+	o order.Usecase, a address.Usecase, s shipping.Usecase, t tax.Usecase, tx database.Transactor, ttl time.Duration, timeout time.Duration) cart.Usecase {
+	return &cartUsecase{
+		repo:           c,
+		products:       p,
+		prices:         pp,
+		promotions:     promo,
+		coupons:        cp,
+		orders:         o,
+		addresses:      a,
+		shipping:       s,
+		taxes:          t,
+		tx:             tx,
+		ttl:            ttl,
+		contextTimeout: timeout,
+	}
+}
+
+// open return the cart of token with its items, expired carts are not found
+func (c *cartUsecase) open(ctx context.Context, token string, now time.Time) (*models.Cart, error) {
+	crt, err := c.repo.GetByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if !now.Before(crt.ExpiresAt) {
+		return nil, models.ErrNotFound
+	}
+
+	crt.Items, err = c.repo.GetItems(ctx, crt.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return crt, nil
+}
+
+// touch push expiry of the cart back after a change
+func (c *cartUsecase) touch(ctx context.Context, crt *models.Cart, now time.Time) error {
+	crt.ExpiresAt = now.Add(c.ttl)
+	crt.Updated = null.TimeFrom(now)
+
+	return c.repo.Update(ctx, crt)
+}
+
+// price fill prices of the cart from the price list and promotions in effect at now
+func (c *cartUsecase) price(ctx context.Context, crt *models.Cart, now time.Time) error {
+	lines := make([]promotion.Line, 0, len(crt.Items))
+	crt.Subtotal = 0
+
+	for _, item := range crt.Items {
+		listPrice, err := c.prices.GetPriceAt(ctx, item.ProductID, item.Amount, now)
+		if err != nil {
+			return err
+		}
+
+		item.Price = listPrice.Price
+		item.Subtotal = listPrice.Price * float64(item.Amount)
+		crt.Subtotal += item.Subtotal
+
+		lines = append(lines, promotion.Line{
+			ProductID: item.ProductID,
+			Quantity:  item.Amount,
+			UnitPrice: item.Price,
+		})
+	}
+
+	crt.Promotions = make([]*models.OrderPromotion, 0)
+	crt.Discount = 0
+
+	if len(lines) > 0 {
+		applied, err := c.promotions.Evaluate(ctx, lines, now)
+		if err != nil {
+			return err
+		}
+
+		for _, promo := range applied {
+			crt.Discount += promo.Discount
+		}
+
+		crt.Promotions = applied
+	}
+
+	crt.Total = crt.Subtotal - crt.Discount
+	return nil
+}
+
+// priced load the cart of token again and price it
+func (c *cartUsecase) priced(ctx context.Context, token string, now time.Time) (*models.Cart, error) {
+	crt, err := c.open(ctx, token, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.price(ctx, crt, now); err != nil {
+		return nil, err
+	}
+
+	return crt, nil
+}
+
+func (c *cartUsecase) Create(ctx context.Context) (*models.Cart, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
+	defer cancel()
+
+	now := time.Now()
+	crt := &models.Cart{
+		Token:      utils.RandString(16),
+		Customer:   auth.CustomerKey(ctx),
+		ExpiresAt:  now.Add(c.ttl),
+		Created:    now,
+		Items:      make([]*models.CartItem, 0),
+		Promotions: make([]*models.OrderPromotion, 0),
+	}
+
+	err := c.repo.Create(ctx, crt)
+	if err != nil {
+		return nil, err
+	}
+
+	return crt, nil
+}
+
+func (c *cartUsecase) Get(ctx context.Context, token string) (*models.Cart, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
+	defer cancel()
+
+	return c.priced(ctx, token, time.Now())
+}
+
+// AddItem put amount more of a product in the cart
+func (c *cartUsecase) AddItem(ctx context.Context, token string, productID int64, amount int64) (*models.Cart, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
+	defer cancel()
+
+	if amount < 1 {
+		return nil, models.ErrBadParamInput
+	}
+
+	now := time.Now()
+	crt, err := c.open(ctx, token, now)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range crt.Items {
+		if item.ProductID == productID {
+			amount += item.Amount
+		}
+	}
+
+	return c.save(ctx, crt, productID, amount, now)
+}
+
+// UpdateItem replace the amount of a product in the cart, zero remove it
+func (c *cartUsecase) UpdateItem(ctx context.Context, token string, productID int64, amount int64) (*models.Cart, error) {
+	if amount == 0 {
+		return c.RemoveItem(ctx, token, productID)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
+	defer cancel()
+
+	if amount < 0 {
+		return nil, models.ErrBadParamInput
+	}
+
+	now := time.Now()
+	crt, err := c.open(ctx, token, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.save(ctx, crt, productID, amount, now)
+}
+
+// save store amount of a product once it is known the product can be sold
+// in that amount
+func (c *cartUsecase) save(ctx context.Context, crt *models.Cart, productID int64, amount int64, now time.Time) (*models.Cart, error) {
+	if _, err := c.products.GetPublishedByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	if _, err := c.prices.GetPriceAt(ctx, productID, amount, now); err != nil {
+		return nil, err
+	}
+
+	err := c.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		err := c.repo.SaveItem(ctx, &models.CartItem{CartID: crt.ID, ProductID: productID, Amount: amount})
+		if err != nil {
+			return err
+		}
+
+		return c.touch(ctx, crt, now)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c.priced(ctx, crt.Token, now)
+}
+
+func (c *cartUsecase) RemoveItem(ctx context.Context, token string, productID int64) (*models.Cart, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
+	defer cancel()
+
+	now := time.Now()
+	crt, err := c.open(ctx, token, now)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := c.repo.RemoveItem(ctx, crt.ID, productID); err != nil {
+			return err
+		}
+
+		return c.touch(ctx, crt, now)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c.priced(ctx, token, now)
+}
+
+// Merge hand the cart of token over to the customer. When the customer
+// already have an open cart the items are moved into it, amounts of the same
+// product are added up and the cart of token is removed.
+func (c *cartUsecase) Merge(ctx context.Context, token string) (*models.Cart, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
+	defer cancel()
+
+	customer := auth.CustomerKey(ctx)
+	if customer == "" {
+		return nil, models.ErrUnauthorized
+	}
+
+	now := time.Now()
+	anonymous, err := c.open(ctx, token, now)
+	if err != nil {
+		return nil, err
+	}
+
+	// a cart of another customer is never taken over
+	if anonymous.Customer != "" && anonymous.Customer != customer {
+		return nil, models.ErrBadParamInput
+	}
+
+	target, err := c.repo.GetOpenByCustomer(ctx, customer, now)
+	if err != nil && err != models.ErrNotFound {
+		return nil, err
+	}
+
+	if target == nil || target.ID == anonymous.ID {
+		anonymous.Customer = customer
+		if err = c.touch(ctx, anonymous, now); err != nil {
+			return nil, err
+		}
+
+		return c.priced(ctx, token, now)
+	}
+
+	target.Items, err = c.repo.GetItems(ctx, target.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	amounts := make(map[int64]int64)
+	for _, item := range target.Items {
+		amounts[item.ProductID] = item.Amount
+	}
+
+	err = c.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		for _, item := range anonymous.Items {
+			moved := &models.CartItem{
+				CartID:    target.ID,
+				ProductID: item.ProductID,
+				Amount:    amounts[item.ProductID] + item.Amount,
+			}
+
+			if err := c.repo.SaveItem(ctx, moved); err != nil {
+				return err
+			}
+		}
+
+		if err := c.repo.Delete(ctx, anonymous.ID); err != nil {
+			return err
+		}
+
+		return c.touch(ctx, target, now)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c.priced(ctx, target.Token, now)
+}
+
+// destination return where the checkout ship to, an address of the book of
+// the customer or the one given by a guest. It is nil when nothing is shipped.
+func (c *cartUsecase) destination(ctx context.Context, checkout *models.Checkout) (*models.Address, error) {
+	if checkout.AddressID != 0 {
+		return c.addresses.GetByID(ctx, checkout.AddressID)
+	}
+
+	if checkout.Address == nil {
+		return nil, nil
+	}
+
+	if err := address.Validate(checkout.Address); err != nil {
+		return nil, err
+	}
+
+	return checkout.Address, nil
+}
+
+// parcel weight the items of the cart and collect the tax class of their
+// products, products have to be published. The subtotal rate tables look up
+// is the cart total after promotions.
+func (c *cartUsecase) parcel(ctx context.Context, crt *models.Cart) (models.Parcel, map[int64]int64, error) {
+	parcel := models.Parcel{Subtotal: crt.Total}
+	classes := make(map[int64]int64, len(crt.Items))
+
+	for _, item := range crt.Items {
+		prod, err := c.products.GetPublishedByID(ctx, item.ProductID)
+		if err != nil {
+			return parcel, nil, err
+		}
+
+		parcel.Weight += shipping.BillableWeight(prod, item.Amount)
+		classes[item.ProductID] = prod.TaxClassID.Int64
+	}
+
+	return parcel, classes, nil
+}
+
+// QuoteShipping list the shipping methods able to deliver the cart to the
+// destination of checkout with their cost
+func (c *cartUsecase) QuoteShipping(ctx context.Context, token string, checkout *models.Checkout) ([]*models.ShippingQuote, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
+	defer cancel()
+
+	now := time.Now()
+
+	dest, err := c.destination(ctx, checkout)
+	if err != nil {
+		return nil, err
+	}
+
+	if dest == nil {
+		return nil, models.ErrBadParamInput
+	}
+
+	crt, err := c.priced(ctx, token, now)
+	if err != nil {
+		return nil, err
+	}
+
+	parcel, _, err := c.parcel(ctx, crt)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.shipping.Quote(ctx, dest, parcel)
+}
+
+// Checkout turn the cart into an order. The cart is priced again, the coupon
+// applied, tax charged at the destination, shipping charged when a method is
+// chosen and the order stored in the same transaction that close the cart, so
+// a cart give at most one order. Shipping is not taxed.
+func (c *cartUsecase) Checkout(ctx context.Context, token string, checkout *models.Checkout) (*models.Order, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
+	defer cancel()
+
+	now := time.Now()
+	var placed *models.Order
+
+	dest, err := c.destination(ctx, checkout)
+	if err != nil {
+		return nil, err
+	}
+
+	// shipping need both a destination and a method
+	if (dest == nil) != (checkout.ShippingMethodID == 0) {
+		return nil, models.ErrBadParamInput
+	}
+
+	err = c.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		crt, err := c.open(ctx, token, now)
+		if err != nil {
+			return err
+		}
+
+		if len(crt.Items) == 0 {
+			return models.ErrBadParamInput
+		}
+
+		// products could have been unpublished since they were added
+		parcel, classes, err := c.parcel(ctx, crt)
+		if err != nil {
+			return err
+		}
+
+		if err = c.price(ctx, crt, now); err != nil {
+			return err
+		}
+		parcel.Subtotal = crt.Total
+
+		// a cart of a customer is only checked out by that customer
+		customer := auth.CustomerKey(ctx)
+		if crt.Customer != "" && crt.Customer != customer {
+			return models.ErrUnauthorized
+		}
+
+		ord := newOrder(crt)
+		if customerID, ok := auth.CustomerID(ctx); ok {
+			ord.CustomerID = null.IntFrom(customerID)
+		}
+
+		if checkout.Coupon != "" {
+			redeemed, err := c.coupons.Apply(ctx, checkout.Coupon, customer, ord.Total, now)
+			if err != nil {
+				return err
+			}
+
+			ord.Coupon = redeemed
+			ord.Discount += redeemed.Discount
+			ord.Total -= redeemed.Discount
+		}
+
+		if err = c.taxes.Apply(ctx, ord, classes, dest); err != nil {
+			return err
+		}
+
+		if dest != nil {
+			quote, err := c.shipping.Cost(ctx, checkout.ShippingMethodID, dest, parcel)
+			if err != nil {
+				return err
+			}
+
+			ord.Shipping = newShipping(quote, dest)
+			ord.Total += quote.Cost
+		}
+
+		if err = c.orders.Create(ctx, ord); err != nil {
+			return err
+		}
+
+		placed = ord
+		return c.repo.CheckOut(ctx, crt.ID, ord.ID)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return placed, nil
+}
+
+// newShipping copy the destination into the shipping of an order
+func newShipping(quote *models.ShippingQuote, dest *models.Address) *models.OrderShipping {
+	return &models.OrderShipping{
+		MethodID:   quote.MethodID,
+		Method:     quote.Name,
+		Cost:       quote.Cost,
+		Name:       dest.Name,
+		Line1:      dest.Line1,
+		Line2:      dest.Line2,
+		City:       dest.City,
+		Region:     dest.Region,
+		PostalCode: dest.PostalCode,
+		Country:    dest.Country,
+		Phone:      dest.Phone,
+	}
+}
+
+// newOrder make a pending order out of a priced cart. The product, amount
+// and price of the order are only filled for a single item cart, the way
+// orders placed directly look like.
+func newOrder(crt *models.Cart) *models.Order {
+	ord := &models.Order{
+		Discount:   crt.Discount,
+		Total:      crt.Total,
+		Status:     models.OrderPending,
+		Items:      make([]*models.OrderItem, 0, len(crt.Items)),
+		Promotions: crt.Promotions,
+	}
+
+	for _, item := range crt.Items {
+		ord.Amount += item.Amount
+		ord.Items = append(ord.Items, &models.OrderItem{
+			ProductID: item.ProductID,
+			Amount:    item.Amount,
+			Price:     item.Price,
+		})
+	}
+
+	if len(crt.Items) == 1 {
+		ord.ProductID = crt.Items[0].ProductID
+		ord.Price = crt.Items[0].Price
+	}
+
+	return ord
+}
+
+func (c *cartUsecase) PurgeExpired(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
+	defer cancel()
+
+	return c.repo.PurgeExpired(ctx, time.Now())
+}
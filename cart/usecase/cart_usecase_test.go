@@ -0,0 +1,270 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	addrMocks "github.com/soerjadi/exam/address/mocks"
+	"github.com/soerjadi/exam/auth"
+	"github.com/soerjadi/exam/cart/mocks"
+	"github.com/soerjadi/exam/cart/usecase"
+	couponMocks "github.com/soerjadi/exam/coupon/mocks"
+	"github.com/soerjadi/exam/models"
+	orderMocks "github.com/soerjadi/exam/order/mocks"
+	pMocks "github.com/soerjadi/exam/product/mocks"
+	priceMocks "github.com/soerjadi/exam/product_price/mocks"
+	promoMocks "github.com/soerjadi/exam/promotion/mocks"
+	shipMocks "github.com/soerjadi/exam/shipping/mocks"
+	taxMocks "github.com/soerjadi/exam/tax/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gopkg.in/guregu/null.v3"
+)
+
+// inlineTx run the work straight away, the repositories are mocked
+type inlineTx struct{}
+
+func (inlineTx) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+type deps struct {
+	repo       *mocks.Repository
+	products   *pMocks.Usecase
+	prices     *priceMocks.Usecase
+	promotions *promoMocks.Usecase
+	coupons    *couponMocks.Usecase
+	orders     *orderMocks.Usecase
+	addresses  *addrMocks.Usecase
+	shipping   *shipMocks.Usecase
+	taxes      *taxMocks.Usecase
+}
+
+func newDeps() deps {
+	d := deps{
+		repo:       new(mocks.Repository),
+		products:   new(pMocks.Usecase),
+		prices:     new(priceMocks.Usecase),
+		promotions: new(promoMocks.Usecase),
+		coupons:    new(couponMocks.Usecase),
+		orders:     new(orderMocks.Usecase),
+		addresses:  new(addrMocks.Usecase),
+		shipping:   new(shipMocks.Usecase),
+		taxes:      new(taxMocks.Usecase),
+	}
+
+	d.products.On("GetPublishedByID", mock.Anything, mock.AnythingOfType("int64")).Return(&models.Product{ID: 9}, nil).Maybe()
+	d.prices.On("GetPriceAt", mock.Anything, int64(9), mock.AnythingOfType("int64"), mock.AnythingOfType("time.Time")).
+		Return(&models.ProductPrice{ProductID: 9, Amount: 1, Price: 10000.0}, nil).Maybe()
+	d.prices.On("GetPriceAt", mock.Anything, int64(7), mock.AnythingOfType("int64"), mock.AnythingOfType("time.Time")).
+		Return(&models.ProductPrice{ProductID: 7, Amount: 1, Price: 2500.0}, nil).Maybe()
+	d.promotions.On("Evaluate", mock.Anything, mock.Anything, mock.AnythingOfType("time.Time")).
+		Return(make([]*models.OrderPromotion, 0), nil).Maybe()
+	d.taxes.On("Apply", mock.Anything, mock.AnythingOfType("*models.Order"), mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	return d
+}
+
+func openCart(id int64, token string, customer string) *models.Cart {
+	return &models.Cart{ID: id, Token: token, Customer: customer, ExpiresAt: time.Now().Add(time.Hour)}
+}
+
+func TestAddItem(t *testing.T) {
+	d := newDeps()
+	crt := openCart(1, "abc123", "")
+
+	d.repo.On("GetByToken", mock.Anything, "abc123").Return(crt, nil)
+	d.repo.On("GetItems", mock.Anything, int64(1)).Return([]*models.CartItem{
+		&models.CartItem{ID: 1, CartID: 1, ProductID: 9, Amount: 2},
+	}, nil).Once()
+	d.repo.On("SaveItem", mock.Anything, mock.MatchedBy(func(i *models.CartItem) bool {
+		return i.CartID == 1 && i.ProductID == 9 && i.Amount == 5
+	})).Return(nil).Once()
+	d.repo.On("Update", mock.Anything, crt).Return(nil).Once()
+	d.repo.On("GetItems", mock.Anything, int64(1)).Return([]*models.CartItem{
+		&models.CartItem{ID: 1, CartID: 1, ProductID: 9, Amount: 5},
+	}, nil).Once()
+
+	c := usecase.NewCartUsecase(d.repo, d.products, d.prices, d.promotions, d.coupons, d.orders, d.addresses, d.shipping, d.taxes, inlineTx{}, time.Hour, time.Second*2)
+	result, err := c.AddItem(context.TODO(), "abc123", 9, 3)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 50000.0, result.Total)
+	assert.Equal(t, 10000.0, result.Items[0].Price)
+	d.repo.AssertExpectations(t)
+}
+
+func TestGetExpired(t *testing.T) {
+	d := newDeps()
+	crt := openCart(1, "abc123", "")
+	crt.ExpiresAt = time.Now().Add(-time.Minute)
+
+	d.repo.On("GetByToken", mock.Anything, "abc123").Return(crt, nil).Once()
+
+	c := usecase.NewCartUsecase(d.repo, d.products, d.prices, d.promotions, d.coupons, d.orders, d.addresses, d.shipping, d.taxes, inlineTx{}, time.Hour, time.Second*2)
+	_, err := c.Get(context.TODO(), "abc123")
+
+	assert.Equal(t, models.ErrNotFound, err)
+}
+
+func TestMerge(t *testing.T) {
+	d := newDeps()
+	anonymous := openCart(1, "abc123", "")
+	owned := openCart(2, "def456", "4")
+
+	d.repo.On("GetByToken", mock.Anything, "abc123").Return(anonymous, nil).Once()
+	d.repo.On("GetItems", mock.Anything, int64(1)).Return([]*models.CartItem{
+		&models.CartItem{ID: 1, CartID: 1, ProductID: 9, Amount: 2},
+		&models.CartItem{ID: 2, CartID: 1, ProductID: 7, Amount: 1},
+	}, nil).Once()
+	d.repo.On("GetOpenByCustomer", mock.Anything, "4", mock.AnythingOfType("time.Time")).Return(owned, nil).Once()
+	d.repo.On("GetItems", mock.Anything, int64(2)).Return([]*models.CartItem{
+		&models.CartItem{ID: 3, CartID: 2, ProductID: 9, Amount: 1},
+	}, nil).Once()
+
+	// amounts of the same product are added up
+	d.repo.On("SaveItem", mock.Anything, &models.CartItem{CartID: 2, ProductID: 9, Amount: 3}).Return(nil).Once()
+	d.repo.On("SaveItem", mock.Anything, &models.CartItem{CartID: 2, ProductID: 7, Amount: 1}).Return(nil).Once()
+	d.repo.On("Delete", mock.Anything, int64(1)).Return(nil).Once()
+	d.repo.On("Update", mock.Anything, owned).Return(nil).Once()
+
+	d.repo.On("GetByToken", mock.Anything, "def456").Return(owned, nil).Once()
+	d.repo.On("GetItems", mock.Anything, int64(2)).Return([]*models.CartItem{
+		&models.CartItem{ID: 3, CartID: 2, ProductID: 9, Amount: 3},
+		&models.CartItem{ID: 4, CartID: 2, ProductID: 7, Amount: 1},
+	}, nil).Once()
+
+	c := usecase.NewCartUsecase(d.repo, d.products, d.prices, d.promotions, d.coupons, d.orders, d.addresses, d.shipping, d.taxes, inlineTx{}, time.Hour, time.Second*2)
+	ctx := auth.WithClaims(context.TODO(), &auth.Claims{Subject: 4})
+	result, err := c.Merge(ctx, "abc123")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "def456", result.Token)
+	assert.Equal(t, 32500.0, result.Total)
+	d.repo.AssertExpectations(t)
+}
+
+func TestMergeForeignCart(t *testing.T) {
+	d := newDeps()
+
+	d.repo.On("GetByToken", mock.Anything, "abc123").Return(openCart(1, "abc123", "5"), nil).Once()
+	d.repo.On("GetItems", mock.Anything, int64(1)).Return(make([]*models.CartItem, 0), nil).Once()
+
+	c := usecase.NewCartUsecase(d.repo, d.products, d.prices, d.promotions, d.coupons, d.orders, d.addresses, d.shipping, d.taxes, inlineTx{}, time.Hour, time.Second*2)
+	ctx := auth.WithClaims(context.TODO(), &auth.Claims{Subject: 4})
+	_, err := c.Merge(ctx, "abc123")
+
+	assert.Equal(t, models.ErrBadParamInput, err)
+}
+
+func TestCheckout(t *testing.T) {
+	d := newDeps()
+	crt := openCart(1, "abc123", "4")
+
+	d.repo.On("GetByToken", mock.Anything, "abc123").Return(crt, nil).Once()
+	d.repo.On("GetItems", mock.Anything, int64(1)).Return([]*models.CartItem{
+		&models.CartItem{ID: 1, CartID: 1, ProductID: 9, Amount: 2},
+		&models.CartItem{ID: 2, CartID: 1, ProductID: 7, Amount: 4},
+	}, nil).Once()
+	d.coupons.On("Apply", mock.Anything, "XMAS", "4", 30000.0, mock.AnythingOfType("time.Time")).
+		Return(&models.OrderCoupon{CouponID: 3, Code: "XMAS", Customer: "4", Discount: 3000.0}, nil).Once()
+	d.orders.On("Create", mock.Anything, mock.MatchedBy(func(o *models.Order) bool {
+		return len(o.Items) == 2 && o.ProductID == 0 && o.Amount == 6 && o.Total == 27000.0 && o.Coupon.CouponID == 3 && o.CustomerID.Int64 == 4
+	})).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.Order).ID = 12
+	}).Once()
+	d.repo.On("CheckOut", mock.Anything, int64(1), int64(12)).Return(nil).Once()
+
+	c := usecase.NewCartUsecase(d.repo, d.products, d.prices, d.promotions, d.coupons, d.orders, d.addresses, d.shipping, d.taxes, inlineTx{}, time.Hour, time.Second*2)
+	ctx := auth.WithClaims(context.TODO(), &auth.Claims{Subject: 4})
+	order, err := c.Checkout(ctx, "abc123", &models.Checkout{Coupon: "XMAS"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), order.ID)
+	assert.Equal(t, models.OrderPending, order.Status)
+	d.repo.AssertExpectations(t)
+	d.orders.AssertExpectations(t)
+}
+
+func TestCheckoutWithShipping(t *testing.T) {
+	d := newDeps()
+	crt := openCart(1, "abc123", "4")
+	home := &models.Address{ID: 2, CustomerID: 4, Name: "Jane", Line1: "Jl. Sudirman 1", City: "Jakarta", Country: "ID"}
+
+	d.repo.On("GetByToken", mock.Anything, "abc123").Return(crt, nil).Once()
+	d.repo.On("GetItems", mock.Anything, int64(1)).Return([]*models.CartItem{
+		&models.CartItem{ID: 1, CartID: 1, ProductID: 9, Amount: 2},
+	}, nil).Once()
+	// 40x30x20 cm is 4.8 volumetric kg, charged over the 1.5 kg it weigh
+	d.products = new(pMocks.Usecase)
+	d.products.On("GetPublishedByID", mock.Anything, int64(9)).
+		Return(&models.Product{ID: 9, Weight: 1.5, Length: 40, Width: 30, Height: 20, TaxClassID: null.IntFrom(3)}, nil).Once()
+	d.addresses.On("GetByID", mock.Anything, int64(2)).Return(home, nil).Once()
+	// tax is charged on the goods before shipping is added
+	d.taxes = new(taxMocks.Usecase)
+	d.taxes.On("Apply", mock.Anything, mock.MatchedBy(func(o *models.Order) bool {
+		return o.Total == 20000.0
+	}), map[int64]int64{9: 3}, home).Return(nil).Run(func(args mock.Arguments) {
+		o := args.Get(1).(*models.Order)
+		o.Tax = 2000.0
+		o.Total += o.Tax
+	}).Once()
+	d.shipping.On("Cost", mock.Anything, int64(5), home, models.Parcel{Weight: 9.6, Subtotal: 20000.0}).
+		Return(&models.ShippingQuote{MethodID: 5, Name: "regular", Cost: 12000.0}, nil).Once()
+	d.orders.On("Create", mock.Anything, mock.MatchedBy(func(o *models.Order) bool {
+		return o.Total == 34000.0 && o.Shipping.Cost == 12000.0 && o.Shipping.City == "Jakarta"
+	})).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.Order).ID = 13
+	}).Once()
+	d.repo.On("CheckOut", mock.Anything, int64(1), int64(13)).Return(nil).Once()
+
+	c := usecase.NewCartUsecase(d.repo, d.products, d.prices, d.promotions, d.coupons, d.orders, d.addresses, d.shipping, d.taxes, inlineTx{}, time.Hour, time.Second*2)
+	ctx := auth.WithClaims(context.TODO(), &auth.Claims{Subject: 4})
+	order, err := c.Checkout(ctx, "abc123", &models.Checkout{AddressID: 2, ShippingMethodID: 5})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "regular", order.Shipping.Method)
+	d.shipping.AssertExpectations(t)
+	d.taxes.AssertExpectations(t)
+	d.orders.AssertExpectations(t)
+}
+
+func TestCheckoutMissingMethod(t *testing.T) {
+	d := newDeps()
+	guest := &models.Address{Name: "Jane", Line1: "Jl. Sudirman 1", City: "Jakarta", Country: "id"}
+
+	c := usecase.NewCartUsecase(d.repo, d.products, d.prices, d.promotions, d.coupons, d.orders, d.addresses, d.shipping, d.taxes, inlineTx{}, time.Hour, time.Second*2)
+	_, err := c.Checkout(context.TODO(), "abc123", &models.Checkout{Address: guest})
+
+	assert.Equal(t, models.ErrBadParamInput, err)
+	d.orders.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestCheckoutEmpty(t *testing.T) {
+	d := newDeps()
+
+	d.repo.On("GetByToken", mock.Anything, "abc123").Return(openCart(1, "abc123", ""), nil).Once()
+	d.repo.On("GetItems", mock.Anything, int64(1)).Return(make([]*models.CartItem, 0), nil).Once()
+
+	c := usecase.NewCartUsecase(d.repo, d.products, d.prices, d.promotions, d.coupons, d.orders, d.addresses, d.shipping, d.taxes, inlineTx{}, time.Hour, time.Second*2)
+	_, err := c.Checkout(context.TODO(), "abc123", &models.Checkout{})
+
+	assert.Equal(t, models.ErrBadParamInput, err)
+	d.orders.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestCheckoutForeignCart(t *testing.T) {
+	d := newDeps()
+
+	d.repo.On("GetByToken", mock.Anything, "abc123").Return(openCart(1, "abc123", "4"), nil).Once()
+	d.repo.On("GetItems", mock.Anything, int64(1)).Return([]*models.CartItem{
+		&models.CartItem{ID: 1, CartID: 1, ProductID: 9, Amount: 2},
+	}, nil).Once()
+
+	c := usecase.NewCartUsecase(d.repo, d.products, d.prices, d.promotions, d.coupons, d.orders, d.addresses, d.shipping, d.taxes, inlineTx{}, time.Hour, time.Second*2)
+	_, err := c.Checkout(context.TODO(), "abc123", &models.Checkout{})
+
+	assert.Equal(t, models.ErrUnauthorized, err)
+	d.orders.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
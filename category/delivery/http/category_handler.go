@@ -23,6 +23,7 @@ type updateCategoryData struct {
 	ID       int64    `json:"id"`
 	Name     string   `json:"name"`
 	ParentID null.Int `json:"parent_id"`
+	Version  int64    `json:"version"`
 }
 
 // CategoryHandler represent the http handler for category
@@ -43,6 +44,8 @@ func NewCategoryHandler(router *mux.Router, usecase category.Usecase) *mux.Route
 	c.HandleFunc("/update", handler.UpdateCategory).Methods("POST")
 	c.HandleFunc("/detail", handler.GetByID).Methods("GET")
 	c.HandleFunc("/delete", handler.Delete).Methods("GET")
+	c.HandleFunc("/trash", handler.Trash).Methods("GET")
+	c.HandleFunc("/restore", handler.Restore).Methods("GET")
 
 	return c
 }
@@ -107,19 +110,37 @@ func (h *CategoryHandler) UpdateCategory(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// If-Match take precedence over the version sent in the body
+	version, ok, err := utils.IfMatchVersion(r)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if ok {
+		updateCategory.Version = version
+	}
+
 	category := models.Category{
 		ID:       updateCategory.ID,
 		Name:     updateCategory.Name,
 		ParentID: updateCategory.ParentID,
+		Version:  updateCategory.Version,
 	}
 
 	err = h.CategoryUsecase.Update(ctx, &category)
 
+	if err == models.ErrConflict {
+		utils.Error(w, http.StatusPreconditionFailed, err.Error())
+		return
+	}
+
 	if err != nil {
 		utils.Error(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
+	w.Header().Set("ETag", utils.ETag(category.Version))
 	utils.JSON(w, http.StatusOK, category)
 }
 
@@ -145,6 +166,14 @@ func (h *CategoryHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	etag := utils.ETag(category.Version)
+	w.Header().Set("ETag", etag)
+
+	if utils.NotModified(r, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
 	utils.JSON(w, http.StatusOK, category)
 }
 
@@ -172,3 +201,67 @@ func (h *CategoryHandler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	utils.JSON(w, http.StatusOK, "success")
 }
+
+// Trash list deleted categories that still can be restored
+func (h *CategoryHandler) Trash(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	limit, err := strconv.ParseInt(params.Get("limit"), 0, 64)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	offset, err := strconv.ParseInt(params.Get("offset"), 0, 64)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if limit == 0 {
+		limit = 10
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	categories, found, err := h.CategoryUsecase.Trash(ctx, offset, limit)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entriesResult := &utils.EntriesResponse{
+		Data:  categories,
+		Found: found,
+	}
+
+	utils.JSON(w, http.StatusOK, entriesResult)
+}
+
+// Restore will bring back deleted category by given ID
+func (h *CategoryHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	id, err := strconv.ParseInt(params.Get("id"), 0, 64)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	err = h.CategoryUsecase.Restore(ctx, id)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, "success")
+}
@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/bxcodec/faker"
 	categoryHttp "github.com/soerjadi/exam/category/delivery/http"
@@ -168,3 +169,45 @@ func TestDelete(t *testing.T) {
 	assert.Equal(t, http.StatusOK, rec.Code)
 	mockUsecase.AssertExpectations(t)
 }
+
+func TestTrash(t *testing.T) {
+	mockCategory := models.Category{
+		ID:        56,
+		Name:      "category 56",
+		DeletedAt: null.NewTime(time.Now(), true),
+	}
+
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("Trash", mock.Anything, int64(0), int64(10)).Return([]*models.Category{&mockCategory}, int64(1), nil)
+
+	req, err := http.NewRequest("GET", "/v1/category/trash?offset=0&limit=10", strings.NewReader(""))
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	handler := categoryHttp.CategoryHandler{
+		CategoryUsecase: mockUsecase,
+	}
+
+	handler.Trash(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestRestore(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("Restore", mock.Anything, int64(56)).Return(models.ErrNotFound)
+
+	req, err := http.NewRequest("GET", "/v1/category/restore?id=56", strings.NewReader(""))
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	handler := categoryHttp.CategoryHandler{
+		CategoryUsecase: mockUsecase,
+	}
+
+	handler.Restore(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
@@ -3,6 +3,7 @@
 package mocks
 
 import context "context"
+import time "time"
 import mock "github.com/stretchr/testify/mock"
 import models "github.com/soerjadi/exam/models"
 
@@ -62,6 +63,71 @@ func (_m *Repository) GetByID(ctx context.Context, id int64) (*models.Category,
 	return r0, r1
 }
 
+// GetTrash provides a mock function with given fields: ctx, offset, limit
+func (_m *Repository) GetTrash(ctx context.Context, offset int64, limit int64) ([]*models.Category, int64, error) {
+	ret := _m.Called(ctx, offset, limit)
+
+	var r0 []*models.Category
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) []*models.Category); ok {
+		r0 = rf(ctx, offset, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Category)
+		}
+	}
+
+	var r1 int64
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) int64); ok {
+		r1 = rf(ctx, offset, limit)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, int64, int64) error); ok {
+		r2 = rf(ctx, offset, limit)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Purge provides a mock function with given fields: ctx, before
+func (_m *Repository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	ret := _m.Called(ctx, before)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, before)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Restore provides a mock function with given fields: ctx, id
+func (_m *Repository) Restore(ctx context.Context, id int64) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Search provides a mock function with given fields: ctx, query, offset, limit
 func (_m *Repository) Search(ctx context.Context, query *string, offset int64, limit int64) ([]*models.Category, int64, error) {
 	ret := _m.Called(ctx, query, offset, limit)
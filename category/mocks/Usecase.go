@@ -3,6 +3,7 @@
 package mocks
 
 import context "context"
+import time "time"
 import mock "github.com/stretchr/testify/mock"
 import models "github.com/soerjadi/exam/models"
 
@@ -62,6 +63,41 @@ func (_m *Usecase) GetByID(ctx context.Context, id int64) (*models.Category, err
 	return r0, r1
 }
 
+// Purge provides a mock function with given fields: ctx, retention
+func (_m *Usecase) Purge(ctx context.Context, retention time.Duration) (int64, error) {
+	ret := _m.Called(ctx, retention)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, time.Duration) int64); ok {
+		r0 = rf(ctx, retention)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Duration) error); ok {
+		r1 = rf(ctx, retention)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Restore provides a mock function with given fields: ctx, id
+func (_m *Usecase) Restore(ctx context.Context, id int64) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Search provides a mock function with given fields: ctx, query, offset, limit
 func (_m *Usecase) Search(ctx context.Context, query *string, offset int64, limit int64) ([]*models.Category, int64, error) {
 	ret := _m.Called(ctx, query, offset, limit)
@@ -92,6 +128,36 @@ func (_m *Usecase) Search(ctx context.Context, query *string, offset int64, limi
 	return r0, r1, r2
 }
 
+// Trash provides a mock function with given fields: ctx, offset, limit
+func (_m *Usecase) Trash(ctx context.Context, offset int64, limit int64) ([]*models.Category, int64, error) {
+	ret := _m.Called(ctx, offset, limit)
+
+	var r0 []*models.Category
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) []*models.Category); ok {
+		r0 = rf(ctx, offset, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Category)
+		}
+	}
+
+	var r1 int64
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) int64); ok {
+		r1 = rf(ctx, offset, limit)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, int64, int64) error); ok {
+		r2 = rf(ctx, offset, limit)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // Update provides a mock function with given fields: ctx, _a1
 func (_m *Usecase) Update(ctx context.Context, _a1 *models.Category) error {
 	ret := _m.Called(ctx, _a1)
@@ -47,6 +47,8 @@ func (p *pgCategoryRepository) fetch(ctx context.Context, query string, args ...
 			&t.ParentID,
 			&t.Created,
 			&t.Updated,
+			&t.DeletedAt,
+			&t.Version,
 		)
 
 		if err != nil {
@@ -97,7 +99,7 @@ func (p *pgCategoryRepository) fetchRow(ctx context.Context, query string, args
 }
 
 func (p *pgCategoryRepository) GetByID(ctx context.Context, id int64) (product *models.Category, err error) {
-	query := `SELECT id, name, parent_id, created, updated FROM categories WHERE id = ?`
+	query := `SELECT id, name, parent_id, created, updated, deleted_at, version FROM categories WHERE id = ? AND deleted_at IS NULL`
 
 	categories, err := p.fetch(ctx, query, id)
 	if err != nil {
@@ -131,18 +133,19 @@ func (p *pgCategoryRepository) Create(ctx context.Context, category *models.Cate
 	}
 
 	category.ID = lastID
+	category.Version = 1
 	return nil
 }
 
 func (p *pgCategoryRepository) Update(ctx context.Context, category *models.Category) error {
-	query := "UPDATE categories SET name = ?, parent_id = ?, updated = ? WHERE id = ?"
+	query := "UPDATE categories SET name = ?, parent_id = ?, updated = ?, version = version + 1 WHERE id = ? AND version = ? AND deleted_at IS NULL"
 
 	stmt, err := p.Conn.PrepareContext(ctx, query)
 	if err != nil {
-		return nil
+		return err
 	}
 
-	res, err := stmt.ExecContext(ctx, category.Name, category.ParentID, time.Now(), category.ID)
+	res, err := stmt.ExecContext(ctx, category.Name, category.ParentID, time.Now(), category.ID, category.Version)
 	if err != nil {
 		return err
 	}
@@ -152,25 +155,26 @@ func (p *pgCategoryRepository) Update(ctx context.Context, category *models.Cate
 		return err
 	}
 
+	// the row is either gone or someone else already bumped its version
 	if affected != 1 {
-		return fmt.Errorf("Total affected: %d", affected)
+		return models.ErrConflict
 	}
 
+	category.Version++
 	return nil
-
 }
 
 func (p *pgCategoryRepository) Delete(ctx context.Context, id int64) error {
-	query := "DELETE FROM categories WHERE id = ?"
+	query := "UPDATE categories SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL"
 
 	stmt, err := p.Conn.PrepareContext(ctx, query)
 	if err != nil {
 		return err
 	}
 
-	res, err := stmt.ExecContext(ctx, id)
+	res, err := stmt.ExecContext(ctx, time.Now(), id)
 	if err != nil {
-		return nil
+		return err
 	}
 
 	rowsAffected, err := res.RowsAffected()
@@ -186,15 +190,86 @@ func (p *pgCategoryRepository) Delete(ctx context.Context, id int64) error {
 	return nil
 }
 
+func (p *pgCategoryRepository) GetTrash(ctx context.Context, offset int64, limit int64) ([]*models.Category, int64, error) {
+	query := `SELECT id, name, parent_id, created, updated, deleted_at, version FROM categories WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC LIMIT ? OFFSET ?`
+	qCount := `SELECT count(id) FROM categories WHERE deleted_at IS NOT NULL`
+
+	result, err := p.fetch(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := p.fetchRow(ctx, qCount)
+	if err != nil {
+		logger.Error(err)
+		return nil, 0, err
+	}
+
+	var count int64
+	err = rows.Scan(&count)
+
+	if err != nil {
+		logger.Error(err)
+		return nil, 0, err
+	}
+
+	return result, count, nil
+}
+
+func (p *pgCategoryRepository) Restore(ctx context.Context, id int64) error {
+	query := "UPDATE categories SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL"
+
+	stmt, err := p.Conn.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	res, err := stmt.ExecContext(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected != 1 {
+		return models.ErrNotFound
+	}
+
+	return nil
+}
+
+func (p *pgCategoryRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	// a category is kept as long as a product or a child category still points to it
+	query := `DELETE FROM categories WHERE deleted_at IS NOT NULL AND deleted_at < ?
+		AND NOT EXISTS (SELECT 1 FROM product_category pc WHERE pc.category_id = categories.id)
+		AND NOT EXISTS (SELECT 1 FROM categories c WHERE c.parent_id = categories.id)`
+
+	stmt, err := p.Conn.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := stmt.ExecContext(ctx, before)
+	if err != nil {
+		logger.Error(err)
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
 func (p *pgCategoryRepository) Search(ctx context.Context, query *string, offset int64, limit int64) ([]*models.Category, int64, error) {
 	var searchQuery string
 
 	if query != nil && len(*query) > 0 {
-		searchQuery = "WHERE LOWER(name) LIKE '%?%'"
+		searchQuery = "AND LOWER(name) LIKE '%?%'"
 	}
 
-	q := fmt.Sprintf("SELECT id, name, parent_id, created, updated FROM categories %s ORDER BY created LIMIT ? OFFSET ?", searchQuery)
-	qCount := fmt.Sprintf("SELECT count(id) FROM categories %s", searchQuery)
+	q := fmt.Sprintf("SELECT id, name, parent_id, created, updated, deleted_at, version FROM categories WHERE deleted_at IS NULL %s ORDER BY created LIMIT ? OFFSET ?", searchQuery)
+	qCount := fmt.Sprintf("SELECT count(id) FROM categories WHERE deleted_at IS NULL %s", searchQuery)
 
 	result, err := p.fetch(ctx, q, strings.ToLower(*query), strings.ToLower(*query), limit, offset)
 
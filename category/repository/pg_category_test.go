@@ -35,15 +35,15 @@ func TestSearch(t *testing.T) {
 	}
 
 	found := int64(2)
-	rows := sqlmock.NewRows([]string{"id", "name", "parent_id", "created", "updated"}).
-		AddRow(mockCategory[0].ID, mockCategory[0].Name, mockCategory[0].ParentID, mockCategory[0].Created, mockCategory[0].Updated).
-		AddRow(mockCategory[1].ID, mockCategory[1].Name, mockCategory[1].ParentID, mockCategory[1].Created, mockCategory[1].Updated)
+	rows := sqlmock.NewRows([]string{"id", "name", "parent_id", "created", "updated", "deleted_at", "version"}).
+		AddRow(mockCategory[0].ID, mockCategory[0].Name, mockCategory[0].ParentID, mockCategory[0].Created, mockCategory[0].Updated, mockCategory[0].DeletedAt, mockCategory[0].Version).
+		AddRow(mockCategory[1].ID, mockCategory[1].Name, mockCategory[1].ParentID, mockCategory[1].Created, mockCategory[1].Updated, mockCategory[1].DeletedAt, mockCategory[1].Version)
 
 	rowCount := sqlmock.NewRows([]string{"count"}).
 		AddRow(found)
 
-	query := "SELECT id, name, parent_id, created, updated FROM categories WHERE LOWER\\(name\\) LIKE '\\%\\?\\%' ORDER BY created LIMIT \\? OFFSET \\?"
-	countQuery := "SELECT count\\(id\\) FROM categories WHERE LOWER\\(name\\) LIKE '\\%\\?\\%'"
+	query := "SELECT id, name, parent_id, created, updated, deleted_at, version FROM categories WHERE deleted_at IS NULL AND LOWER\\(name\\) LIKE '\\%\\?\\%' ORDER BY created LIMIT \\? OFFSET \\?"
+	countQuery := "SELECT count\\(id\\) FROM categories WHERE deleted_at IS NULL AND LOWER\\(name\\) LIKE '\\%\\?\\%'"
 	searchQuery := strings.ToLower("category")
 
 	mock.ExpectQuery(query).WithArgs(searchQuery, searchQuery, 10, 0).WillReturnRows(rows)
@@ -63,10 +63,10 @@ func TestGetByID(t *testing.T) {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
 
-	rows := sqlmock.NewRows([]string{"id", "name", "parent_id", "created", "updated"}).
-		AddRow(1, "category 1", 0, time.Now(), time.Now())
+	rows := sqlmock.NewRows([]string{"id", "name", "parent_id", "created", "updated", "deleted_at", "version"}).
+		AddRow(1, "category 1", 0, time.Now(), time.Now(), nil, 1)
 
-	query := "SELECT id, name, parent_id, created, updated FROM categories WHERE id = \\?"
+	query := "SELECT id, name, parent_id, created, updated, deleted_at, version FROM categories WHERE id = \\? AND deleted_at IS NULL"
 
 	mock.ExpectQuery(query).WillReturnRows(rows)
 	p := repository.NewPGCategoryRepository(db)
@@ -111,17 +111,19 @@ func TestUpdate(t *testing.T) {
 		Name:     "category",
 		ParentID: null.NewInt(int64(0), true),
 		Created:  time.Now(),
+		Version:  1,
 	}
 
-	query := "UPDATE categories SET name = \\?, parent_id = \\? WHERE id = \\?"
+	query := "UPDATE categories SET name = \\?, parent_id = \\?, updated = \\?, version = version \\+ 1 WHERE id = \\? AND version = \\? AND deleted_at IS NULL"
 
 	prep := mock.ExpectPrepare(query)
-	prep.ExpectExec().WithArgs(category.Name, category.ParentID, category.ID).WillReturnResult(sqlmock.NewResult(2, 1))
+	prep.ExpectExec().WithArgs(category.Name, category.ParentID, sqlmock.AnyArg(), category.ID, int64(1)).WillReturnResult(sqlmock.NewResult(2, 1))
 
 	p := repository.NewPGCategoryRepository(db)
 
 	err = p.Update(context.TODO(), category)
 	assert.NoError(t, err)
+	assert.Equal(t, int64(2), category.Version)
 }
 
 func TestDelete(t *testing.T) {
@@ -130,13 +132,73 @@ func TestDelete(t *testing.T) {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
 
-	query := "DELETE FROM categories WHERE id = \\?"
+	query := "UPDATE categories SET deleted_at = \\? WHERE id = \\? AND deleted_at IS NULL"
 
 	prep := mock.ExpectPrepare(query)
-	prep.ExpectExec().WithArgs(2).WillReturnResult(sqlmock.NewResult(2, 1))
+	prep.ExpectExec().WithArgs(sqlmock.AnyArg(), 2).WillReturnResult(sqlmock.NewResult(2, 1))
 
 	p := repository.NewPGCategoryRepository(db)
 
 	err = p.Delete(context.TODO(), int64(2))
 	assert.NoError(t, err)
 }
+
+func TestGetTrash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "name", "parent_id", "created", "updated", "deleted_at", "version"}).
+		AddRow(1, "category 1", 0, time.Now(), nil, time.Now(), 1)
+
+	rowCount := sqlmock.NewRows([]string{"count"}).
+		AddRow(1)
+
+	query := "SELECT id, name, parent_id, created, updated, deleted_at, version FROM categories WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC LIMIT \\? OFFSET \\?"
+	countQuery := "SELECT count\\(id\\) FROM categories WHERE deleted_at IS NOT NULL"
+
+	mock.ExpectQuery(query).WithArgs(10, 0).WillReturnRows(rows)
+	mock.ExpectPrepare(countQuery).ExpectQuery().WillReturnRows(rowCount)
+
+	p := repository.NewPGCategoryRepository(db)
+	result, count, err := p.GetTrash(context.TODO(), int64(0), int64(10))
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	assert.Len(t, result, 1)
+}
+
+func TestRestore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	query := "UPDATE categories SET deleted_at = NULL WHERE id = \\? AND deleted_at IS NOT NULL"
+
+	mock.ExpectPrepare(query).ExpectExec().WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	p := repository.NewPGCategoryRepository(db)
+
+	err = p.Restore(context.TODO(), int64(2))
+	assert.NoError(t, err)
+}
+
+func TestPurge(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	before := time.Now()
+	query := "DELETE FROM categories WHERE deleted_at IS NOT NULL AND deleted_at < \\?"
+
+	mock.ExpectPrepare(query).ExpectExec().WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 3))
+
+	p := repository.NewPGCategoryRepository(db)
+
+	purged, err := p.Purge(context.TODO(), before)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), purged)
+}
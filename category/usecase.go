@@ -2,6 +2,7 @@ package category
 
 import (
 	"context"
+	"time"
 
 	"github.com/soerjadi/exam/models"
 )
@@ -13,4 +14,7 @@ type Usecase interface {
 	Create(ctx context.Context, category *models.Category) error
 	Update(ctx context.Context, category *models.Category) error
 	Delete(ctx context.Context, id int64) error
+	Trash(ctx context.Context, offset int64, limit int64) ([]*models.Category, int64, error)
+	Restore(ctx context.Context, id int64) error
+	Purge(ctx context.Context, retention time.Duration) (int64, error)
 }
@@ -4,24 +4,36 @@ import (
 	"context"
 	"time"
 
+	"github.com/soerjadi/exam/audit"
 	"github.com/soerjadi/exam/category"
 	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/utils"
 	"gopkg.in/guregu/null.v3"
 )
 
 type categoryUsecase struct {
 	repo           category.Repository
+	audit          audit.Usecase
 	contextTimeout time.Duration
 }
 
+var logger = utils.LogBuilder(true)
+
 // NewCategoryUsecase will create object that represent of category.Usecase interface
-func NewCategoryUsecase(c category.Repository, timeout time.Duration) category.Usecase {
+func NewCategoryUsecase(c category.Repository, a audit.Usecase, timeout time.Duration) category.Usecase {
 	return &categoryUsecase{
 		repo:           c,
+		audit:          a,
 		contextTimeout: timeout,
 	}
 }
 
+func (c *categoryUsecase) record(ctx context.Context, id int64, action string, before interface{}, after interface{}) {
+	if err := c.audit.Record(ctx, models.AuditCategory, id, action, before, after); err != nil {
+		logger.Error(err)
+	}
+}
+
 func (c *categoryUsecase) Search(ctx context.Context, query *string, offset int64, limit int64) ([]*models.Category, int64, error) {
 	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
 	defer cancel()
@@ -56,6 +68,7 @@ func (c *categoryUsecase) Create(ctx context.Context, category *models.Category)
 		return err
 	}
 
+	c.record(ctx, category.ID, models.AuditCreate, nil, category)
 	return nil
 }
 
@@ -63,11 +76,31 @@ func (c *categoryUsecase) Update(ctx context.Context, category *models.Category)
 	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
 	defer cancel()
 
+	before, err := c.repo.GetByID(ctx, category.ID)
+	if err != nil {
+		return err
+	}
+
+	// clients that do not send a version keep the last write wins behaviour
+	if category.Version == 0 {
+		category.Version = before.Version
+	}
+
+	if category.Version != before.Version {
+		return models.ErrConflict
+	}
+
 	category.Updated = null.NewTime(
 		time.Now(), true,
 	)
 
-	return c.repo.Update(ctx, category)
+	err = c.repo.Update(ctx, category)
+	if err != nil {
+		return err
+	}
+
+	c.record(ctx, category.ID, models.AuditUpdate, before, category)
+	return nil
 }
 
 func (c *categoryUsecase) Delete(ctx context.Context, id int64) error {
@@ -83,5 +116,43 @@ func (c *categoryUsecase) Delete(ctx context.Context, id int64) error {
 		return models.ErrNotFound
 	}
 
-	return c.repo.Delete(ctx, id)
+	err = c.repo.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	c.record(ctx, id, models.AuditDelete, exists, nil)
+	return nil
+}
+
+func (c *categoryUsecase) Trash(ctx context.Context, offset int64, limit int64) ([]*models.Category, int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
+	defer cancel()
+
+	categories, found, err := c.repo.GetTrash(ctx, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return categories, found, nil
+}
+
+func (c *categoryUsecase) Restore(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
+	defer cancel()
+
+	err := c.repo.Restore(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	c.record(ctx, id, models.AuditRestore, nil, nil)
+	return nil
+}
+
+func (c *categoryUsecase) Purge(ctx context.Context, retention time.Duration) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
+	defer cancel()
+
+	return c.repo.Purge(ctx, time.Now().Add(-retention))
 }
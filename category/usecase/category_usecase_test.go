@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/soerjadi/exam/audit/audittest"
 	"github.com/soerjadi/exam/category/mocks"
 	"github.com/soerjadi/exam/category/usecase"
 	"github.com/soerjadi/exam/models"
@@ -31,7 +32,7 @@ func TestSearch(t *testing.T) {
 			mock.AnythingOfType("int64"), mock.AnythingOfType("int64")).
 			Return(mockListCategory, int64(1), nil).Once()
 
-		p := usecase.NewCategoryUsecase(mockCategoryRepo, time.Second*2)
+		p := usecase.NewCategoryUsecase(mockCategoryRepo, audittest.NewUsecase(), time.Second*2)
 		categories, found, err := p.Search(context.TODO(), &searchQuery, int64(0), int64(10))
 
 		assert.NoError(t, err)
@@ -46,7 +47,7 @@ func TestSearch(t *testing.T) {
 			mock.AnythingOfType("int64"), mock.AnythingOfType("int64")).
 			Return(nil, int64(0), errors.New("Unexpected Error")).Once()
 
-		p := usecase.NewCategoryUsecase(mockCategoryRepo, time.Second*2)
+		p := usecase.NewCategoryUsecase(mockCategoryRepo, audittest.NewUsecase(), time.Second*2)
 		categories, found, err := p.Search(context.TODO(), &searchQuery, int64(0), int64(10))
 
 		assert.Error(t, err)
@@ -71,7 +72,7 @@ func TestGetByID(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mockCategoryRepo.On("GetByID", mock.Anything, mock.AnythingOfType("int64")).Return(mockCategory, nil).Once()
 
-		p := usecase.NewCategoryUsecase(mockCategoryRepo, time.Second*2)
+		p := usecase.NewCategoryUsecase(mockCategoryRepo, audittest.NewUsecase(), time.Second*2)
 
 		product, err := p.GetByID(context.TODO(), mockCategory.ID)
 
@@ -84,7 +85,7 @@ func TestGetByID(t *testing.T) {
 	t.Run("fail", func(t *testing.T) {
 		mockCategoryRepo.On("GetByID", mock.Anything, mock.AnythingOfType("int64")).Return(nil, errors.New("Unexpected errors")).Once()
 
-		p := usecase.NewCategoryUsecase(mockCategoryRepo, time.Second*2)
+		p := usecase.NewCategoryUsecase(mockCategoryRepo, audittest.NewUsecase(), time.Second*2)
 
 		product, err := p.GetByID(context.TODO(), mockCategory.ID)
 
@@ -110,7 +111,8 @@ func TestCreate(t *testing.T) {
 		tmpMockProduct.ID = 0
 		mockCategoryRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Category")).Return(nil).Once()
 
-		p := usecase.NewCategoryUsecase(mockCategoryRepo, time.Second*2)
+		mockAudit := audittest.NewUsecase()
+		p := usecase.NewCategoryUsecase(mockCategoryRepo, mockAudit, time.Second*2)
 
 		err := p.Create(context.TODO(), &tmpMockProduct)
 
@@ -118,16 +120,19 @@ func TestCreate(t *testing.T) {
 		assert.Equal(t, mockCategory.Name, tmpMockProduct.Name)
 
 		mockCategoryRepo.AssertExpectations(t)
+		audittest.AssertRecorded(t, mockAudit, models.AuditCategory, tmpMockProduct.ID, models.AuditCreate, nil, &tmpMockProduct)
 	})
 
 	t.Run("fail", func(t *testing.T) {
 		mockCategoryRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Category")).Return(errors.New("Unexpected error")).Once()
 
-		p := usecase.NewCategoryUsecase(mockCategoryRepo, time.Second*2)
+		mockAudit := audittest.NewUsecase()
+		p := usecase.NewCategoryUsecase(mockCategoryRepo, mockAudit, time.Second*2)
 
 		err := p.Create(context.TODO(), &mockCategory)
 
 		assert.Error(t, err)
+		audittest.AssertNothingRecorded(t, mockAudit)
 
 		mockCategoryRepo.AssertExpectations(t)
 	})
@@ -145,14 +150,18 @@ func TestUpdate(t *testing.T) {
 	}
 
 	t.Run("success", func(t *testing.T) {
+		before := mockCategory
+		mockCategoryRepo.On("GetByID", mock.Anything, mockCategory.ID).Return(&before, nil).Once()
 		mockCategoryRepo.On("Update", mock.Anything, &mockCategory).Return(nil).Once()
 
-		p := usecase.NewCategoryUsecase(mockCategoryRepo, time.Second*2)
+		mockAudit := audittest.NewUsecase()
+		p := usecase.NewCategoryUsecase(mockCategoryRepo, mockAudit, time.Second*2)
 
 		err := p.Update(context.TODO(), &mockCategory)
 
 		assert.NoError(t, err)
 		mockCategoryRepo.AssertExpectations(t)
+		audittest.AssertRecorded(t, mockAudit, models.AuditCategory, mockCategory.ID, models.AuditUpdate, &before, &mockCategory)
 	})
 }
 
@@ -170,17 +179,19 @@ func TestDelete(t *testing.T) {
 		mockCategoryRepo.On("GetByID", mock.Anything, mock.AnythingOfType("int64")).Return(&mockCategory, nil).Once()
 		mockCategoryRepo.On("Delete", mock.Anything, mock.AnythingOfType("int64")).Return(nil).Once()
 
-		p := usecase.NewCategoryUsecase(mockCategoryRepo, time.Second*2)
+		mockAudit := audittest.NewUsecase()
+		p := usecase.NewCategoryUsecase(mockCategoryRepo, mockAudit, time.Second*2)
 
 		err := p.Delete(context.TODO(), mockCategory.ID)
 
 		assert.NoError(t, err)
 		mockCategoryRepo.AssertExpectations(t)
+		audittest.AssertRecorded(t, mockAudit, models.AuditCategory, mockCategory.ID, models.AuditDelete, &mockCategory, nil)
 	})
 	t.Run("item is not exist", func(t *testing.T) {
 		mockCategoryRepo.On("GetByID", mock.Anything, mock.AnythingOfType("int64")).Return(nil, nil).Once()
 
-		p := usecase.NewCategoryUsecase(mockCategoryRepo, time.Second*2)
+		p := usecase.NewCategoryUsecase(mockCategoryRepo, audittest.NewUsecase(), time.Second*2)
 
 		err := p.Delete(context.TODO(), mockCategory.ID)
 
@@ -188,3 +199,51 @@ func TestDelete(t *testing.T) {
 		mockCategoryRepo.AssertExpectations(t)
 	})
 }
+
+func TestTrash(t *testing.T) {
+	mockCategoryRepo := new(mocks.Repository)
+	mockCategory := &models.Category{
+		ID:        8,
+		Name:      "category 8",
+		Created:   time.Now(),
+		DeletedAt: null.NewTime(time.Now(), true),
+	}
+
+	mockCategoryRepo.On("GetTrash", mock.Anything, int64(0), int64(10)).
+		Return([]*models.Category{mockCategory}, int64(1), nil).Once()
+
+	c := usecase.NewCategoryUsecase(mockCategoryRepo, audittest.NewUsecase(), time.Second*2)
+	categories, found, err := c.Trash(context.TODO(), int64(0), int64(10))
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), found)
+	assert.Len(t, categories, 1)
+	mockCategoryRepo.AssertExpectations(t)
+}
+
+func TestRestore(t *testing.T) {
+	mockCategoryRepo := new(mocks.Repository)
+	mockCategoryRepo.On("Restore", mock.Anything, int64(8)).Return(nil).Once()
+
+	mockAudit := audittest.NewUsecase()
+	c := usecase.NewCategoryUsecase(mockCategoryRepo, mockAudit, time.Second*2)
+
+	err := c.Restore(context.TODO(), int64(8))
+
+	assert.NoError(t, err)
+	mockCategoryRepo.AssertExpectations(t)
+	audittest.AssertRecorded(t, mockAudit, models.AuditCategory, int64(8), models.AuditRestore, nil, nil)
+}
+
+func TestPurge(t *testing.T) {
+	mockCategoryRepo := new(mocks.Repository)
+	mockCategoryRepo.On("Purge", mock.Anything, mock.AnythingOfType("time.Time")).Return(int64(1), nil).Once()
+
+	c := usecase.NewCategoryUsecase(mockCategoryRepo, audittest.NewUsecase(), time.Second*2)
+
+	purged, err := c.Purge(context.TODO(), 24*time.Hour)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+	mockCategoryRepo.AssertExpectations(t)
+}
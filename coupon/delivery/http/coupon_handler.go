@@ -0,0 +1,188 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/soerjadi/exam/coupon"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/utils"
+	"gopkg.in/guregu/null.v3"
+)
+
+type generateData struct {
+	Count       int       `json:"count"`
+	Code        string    `json:"code"`
+	Batch       string    `json:"batch"`
+	Action      string    `json:"action"`
+	Value       float64   `json:"value"`
+	MinSpend    float64   `json:"min_spend"`
+	MaxUses     int64     `json:"max_uses"`
+	PerCustomer int64     `json:"per_customer"`
+	ExpiresAt   null.Time `json:"expires_at"`
+}
+
+// CouponHandler represent the http handler for coupon
+type CouponHandler struct {
+	CouponUsecase coupon.Usecase
+}
+
+// NewCouponHandler initialize coupon resource endpoint
+func NewCouponHandler(router *mux.Router, usecase coupon.Usecase) *mux.Router {
+	handler := &CouponHandler{
+		CouponUsecase: usecase,
+	}
+
+	p := router.PathPrefix("/v1/coupon").Subrouter()
+	p.HandleFunc("/generate", handler.Generate).Methods("POST")
+	p.HandleFunc("/detail", handler.GetByID).Methods("GET")
+	p.HandleFunc("/list", handler.GetList).Methods("GET")
+	p.HandleFunc("/deactivate", handler.Deactivate).Methods("GET")
+
+	return p
+}
+
+// Generate create a batch of coupons from given body, count default to one
+func (h *CouponHandler) Generate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var data generateData
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = json.Unmarshal(body, &data)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if data.Count == 0 {
+		data.Count = 1
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	template := models.Coupon{
+		Code:        data.Code,
+		Batch:       data.Batch,
+		Action:      data.Action,
+		Value:       data.Value,
+		MinSpend:    data.MinSpend,
+		MaxUses:     data.MaxUses,
+		PerCustomer: data.PerCustomer,
+		ExpiresAt:   data.ExpiresAt,
+	}
+
+	coupons, err := h.CouponUsecase.Generate(ctx, &template, data.Count)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entriesResult := &utils.EntriesResponse{
+		Data:  coupons,
+		Found: int64(len(coupons)),
+	}
+
+	utils.JSON(w, http.StatusOK, entriesResult)
+}
+
+// GetByID get detail coupon from given ID
+func (h *CouponHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	id, err := strconv.ParseInt(params.Get("id"), 0, 64)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	cp, err := h.CouponUsecase.GetByID(ctx, id)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, cp)
+}
+
+// GetList list coupons newest first, filtered by batch when it is given
+func (h *CouponHandler) GetList(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	limit, err := strconv.ParseInt(params.Get("limit"), 0, 64)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	offset, err := strconv.ParseInt(params.Get("offset"), 0, 64)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if limit == 0 {
+		limit = 10
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	coupons, found, err := h.CouponUsecase.GetList(ctx, params.Get("batch"), offset, limit)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entriesResult := &utils.EntriesResponse{
+		Data:  coupons,
+		Found: found,
+	}
+
+	utils.JSON(w, http.StatusOK, entriesResult)
+}
+
+// Deactivate stop coupon by given ID from being redeemed, orders keep it
+func (h *CouponHandler) Deactivate(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	id, err := strconv.ParseInt(params.Get("id"), 0, 64)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	err = h.CouponUsecase.Deactivate(ctx, id)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, "success")
+}
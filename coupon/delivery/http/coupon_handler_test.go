@@ -0,0 +1,59 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	couponHttp "github.com/soerjadi/exam/coupon/delivery/http"
+	"github.com/soerjadi/exam/coupon/mocks"
+	"github.com/soerjadi/exam/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGenerate(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("Generate", mock.Anything, mock.MatchedBy(func(c *models.Coupon) bool {
+		return c.Action == models.PromotionFixedOff && c.MaxUses == 1 && c.ExpiresAt.Valid
+	}), 2).Return([]*models.Coupon{
+		&models.Coupon{ID: 1, Code: "A1B2C3D4E5"},
+		&models.Coupon{ID: 2, Code: "F6A7B8C9D0"},
+	}, nil)
+
+	body := `{"count":2,"batch":"spring","action":"fixed_off","value":5000,"max_uses":1,"expires_at":"2019-12-31T00:00:00Z"}`
+	req, err := http.NewRequest("POST", "/v1/coupon/generate", strings.NewReader(body))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	handler := couponHttp.CouponHandler{
+		CouponUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.Generate(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"found":2`)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestGetList(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("GetList", mock.Anything, "spring", int64(0), int64(10)).
+		Return([]*models.Coupon{&models.Coupon{ID: 1, Code: "A1B2C3D4E5"}}, int64(1), nil)
+
+	req, err := http.NewRequest("GET", "/v1/coupon/list?batch=spring&offset=0&limit=10", strings.NewReader(""))
+	assert.NoError(t, err)
+
+	handler := couponHttp.CouponHandler{
+		CouponUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.GetList(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
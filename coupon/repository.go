@@ -0,0 +1,17 @@
+package coupon
+
+import (
+	"context"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// Repository represent the coupon repository contract
+type Repository interface {
+	GetByID(ctx context.Context, id int64) (*models.Coupon, error)
+	GetByCode(ctx context.Context, code string) (*models.Coupon, error)
+	GetList(ctx context.Context, batch string, offset int64, limit int64) ([]*models.Coupon, int64, error)
+	CountRedemptions(ctx context.Context, couponID int64, customer string) (int64, error)
+	CreateBulk(ctx context.Context, coupons []*models.Coupon) error
+	Deactivate(ctx context.Context, id int64) error
+}
@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/soerjadi/exam/coupon"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/utils"
+)
+
+type pgCouponRepository struct {
+	Conn *sql.DB
+}
+
+var logger = utils.LogBuilder(true)
+
+// NewPGCouponRepository is bridge to create an object from coupon.Repository interface
+func NewPGCouponRepository(Conn *sql.DB) coupon.Repository {
+	return &pgCouponRepository{Conn}
+}
+
+func (c *pgCouponRepository) fetch(ctx context.Context, query string, args ...interface{}) ([]*models.Coupon, error) {
+	rows, err := c.Conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Error(err)
+		}
+	}()
+
+	result := make([]*models.Coupon, 0)
+	for rows.Next() {
+		t := new(models.Coupon)
+
+		err = rows.Scan(
+			&t.ID,
+			&t.Code,
+			&t.Batch,
+			&t.Action,
+			&t.Value,
+			&t.MinSpend,
+			&t.MaxUses,
+			&t.PerCustomer,
+			&t.Used,
+			&t.ExpiresAt,
+			&t.Active,
+			&t.Created,
+		)
+
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+
+		result = append(result, t)
+	}
+
+	return result, nil
+}
+
+func (c *pgCouponRepository) fetchRow(ctx context.Context, query string, args ...interface{}) (*sql.Row, error) {
+	stmt, err := c.Conn.PrepareContext(ctx, query)
+
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	defer func() {
+		err := stmt.Close()
+		if err != nil {
+			logger.Error(err)
+		}
+	}()
+
+	return stmt.QueryRow(args...), nil
+}
+
+func (c *pgCouponRepository) getOne(ctx context.Context, query string, args ...interface{}) (*models.Coupon, error) {
+	coupons, err := c.fetch(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(coupons) == 0 {
+		return nil, models.ErrNotFound
+	}
+
+	return coupons[0], nil
+}
+
+func (c *pgCouponRepository) GetByID(ctx context.Context, id int64) (*models.Coupon, error) {
+	query := `SELECT id, code, batch, action, value, min_spend, max_uses, per_customer, used, expires_at, active, created
+		FROM coupons WHERE id = ?`
+
+	return c.getOne(ctx, query, id)
+}
+
+func (c *pgCouponRepository) GetByCode(ctx context.Context, code string) (*models.Coupon, error) {
+	query := `SELECT id, code, batch, action, value, min_spend, max_uses, per_customer, used, expires_at, active, created
+		FROM coupons WHERE code = ?`
+
+	return c.getOne(ctx, query, code)
+}
+
+// GetList list coupons newest first, only the ones of batch when it is given
+func (c *pgCouponRepository) GetList(ctx context.Context, batch string, offset int64, limit int64) ([]*models.Coupon, int64, error) {
+	query := `SELECT id, code, batch, action, value, min_spend, max_uses, per_customer, used, expires_at, active, created
+		FROM coupons WHERE (? = '' OR batch = ?) ORDER BY id DESC LIMIT ? OFFSET ?`
+	qCount := `SELECT count(id) FROM coupons WHERE (? = '' OR batch = ?)`
+
+	result, err := c.fetch(ctx, query, batch, batch, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := c.fetchRow(ctx, qCount, batch, batch)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var count int64
+	err = rows.Scan(&count)
+
+	if err != nil {
+		logger.Error(err)
+		return nil, 0, err
+	}
+
+	return result, count, nil
+}
+
+func (c *pgCouponRepository) CountRedemptions(ctx context.Context, couponID int64, customer string) (int64, error) {
+	query := `SELECT count(id) FROM order_coupon WHERE coupon_id = ? AND customer = ?`
+
+	rows, err := c.fetchRow(ctx, query, couponID, customer)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	err = rows.Scan(&count)
+
+	if err != nil {
+		logger.Error(err)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// CreateBulk store a whole batch of coupons, none is stored when one fail
+func (c *pgCouponRepository) CreateBulk(ctx context.Context, coupons []*models.Coupon) error {
+	tx, err := c.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO coupons(code, batch, action, value, min_spend, max_uses, per_customer, expires_at, active)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?) returning id`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	for _, cp := range coupons {
+		result, err := stmt.ExecContext(ctx, cp.Code, cp.Batch, cp.Action, cp.Value, cp.MinSpend, cp.MaxUses, cp.PerCustomer, cp.ExpiresAt, cp.Active)
+		if err != nil {
+			logger.Error(err)
+			_ = tx.Rollback()
+			return err
+		}
+
+		lastID, err := result.LastInsertId()
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+
+		cp.ID = lastID
+	}
+
+	return tx.Commit()
+}
+
+func (c *pgCouponRepository) Deactivate(ctx context.Context, id int64) error {
+	query := `UPDATE coupons SET active = false WHERE id = ?`
+
+	stmt, err := c.Conn.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	res, err := stmt.ExecContext(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected != 1 {
+		return models.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/soerjadi/exam/coupon/repository"
+	"github.com/soerjadi/exam/models"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v3"
+)
+
+var columns = []string{"id", "code", "batch", "action", "value", "min_spend", "max_uses", "per_customer", "used",
+	"expires_at", "active", "created"}
+
+func TestGetByCode(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows(columns).
+		AddRow(1, "XMAS", "xmas19", models.PromotionPercentOff, 10.0, 50000.0, 100, 1, 12, nil, true, time.Now())
+
+	query := "SELECT (.+) FROM coupons WHERE code = \\?"
+	mock.ExpectQuery(query).WithArgs("XMAS").WillReturnRows(rows)
+
+	c := repository.NewPGCouponRepository(db)
+	cp, err := c.GetByCode(context.TODO(), "XMAS")
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), cp.Used)
+	assert.False(t, cp.ExpiresAt.Valid)
+}
+
+func TestGetByCodeNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectQuery("SELECT (.+) FROM coupons WHERE code = \\?").WithArgs("NOPE").WillReturnRows(sqlmock.NewRows(columns))
+
+	c := repository.NewPGCouponRepository(db)
+	_, err = c.GetByCode(context.TODO(), "NOPE")
+
+	assert.Equal(t, models.ErrNotFound, err)
+}
+
+func TestGetList(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows(columns).
+		AddRow(2, "A1B2C3D4E5", "spring", models.PromotionFixedOff, 5000.0, 0.0, 1, 0, 0, nil, true, time.Now()).
+		AddRow(1, "F6A7B8C9D0", "spring", models.PromotionFixedOff, 5000.0, 0.0, 1, 0, 1, nil, true, time.Now())
+
+	query := "SELECT (.+) FROM coupons WHERE \\(\\? = '' OR batch = \\?\\) ORDER BY id DESC LIMIT \\? OFFSET \\?"
+	mock.ExpectQuery(query).WithArgs("spring", "spring", int64(10), int64(0)).WillReturnRows(rows)
+	mock.ExpectPrepare("SELECT count\\(id\\) FROM coupons").ExpectQuery().WithArgs("spring", "spring").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+	c := repository.NewPGCouponRepository(db)
+	coupons, found, err := c.GetList(context.TODO(), "spring", int64(0), int64(10))
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), found)
+	assert.Len(t, coupons, 2)
+}
+
+func TestCountRedemptions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectPrepare("SELECT count\\(id\\) FROM order_coupon WHERE coupon_id = \\? AND customer = \\?").ExpectQuery().
+		WithArgs(int64(1), "jane@example.com").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	c := repository.NewPGCouponRepository(db)
+	used, err := c.CountRedemptions(context.TODO(), int64(1), "jane@example.com")
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), used)
+}
+
+func TestCreateBulk(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	expires := null.TimeFrom(time.Now().Add(24 * time.Hour))
+	coupons := []*models.Coupon{
+		&models.Coupon{Code: "A1B2C3D4E5", Batch: "spring", Action: models.PromotionFixedOff, Value: 5000, MaxUses: 1, ExpiresAt: expires, Active: true},
+		&models.Coupon{Code: "F6A7B8C9D0", Batch: "spring", Action: models.PromotionFixedOff, Value: 5000, MaxUses: 1, ExpiresAt: expires, Active: true},
+	}
+
+	mock.ExpectBegin()
+	prep := mock.ExpectPrepare("INSERT INTO coupons\\(code, batch, action, value, min_spend, max_uses, per_customer, expires_at, active\\)")
+	prep.ExpectExec().WithArgs("A1B2C3D4E5", "spring", models.PromotionFixedOff, 5000.0, 0.0, int64(1), int64(0), expires, true).
+		WillReturnResult(sqlmock.NewResult(7, 1))
+	prep.ExpectExec().WithArgs("F6A7B8C9D0", "spring", models.PromotionFixedOff, 5000.0, 0.0, int64(1), int64(0), expires, true).
+		WillReturnResult(sqlmock.NewResult(8, 1))
+	mock.ExpectCommit()
+
+	c := repository.NewPGCouponRepository(db)
+	err = c.CreateBulk(context.TODO(), coupons)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), coupons[0].ID)
+	assert.Equal(t, int64(8), coupons[1].ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeactivate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectPrepare("UPDATE coupons SET active = false WHERE id = \\?").ExpectExec().WithArgs(int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	c := repository.NewPGCouponRepository(db)
+	err = c.Deactivate(context.TODO(), int64(3))
+
+	assert.NoError(t, err)
+}
@@ -0,0 +1,17 @@
+package coupon
+
+import (
+	"context"
+	"time"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// Usecase represent the coupon usecase
+type Usecase interface {
+	GetByID(ctx context.Context, id int64) (*models.Coupon, error)
+	GetList(ctx context.Context, batch string, offset int64, limit int64) ([]*models.Coupon, int64, error)
+	Generate(ctx context.Context, template *models.Coupon, count int) ([]*models.Coupon, error)
+	Deactivate(ctx context.Context, id int64) error
+	Apply(ctx context.Context, code string, customer string, subtotal float64, now time.Time) (*models.OrderCoupon, error)
+}
@@ -0,0 +1,209 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/soerjadi/exam/audit"
+	"github.com/soerjadi/exam/coupon"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/utils"
+)
+
+type couponUsecase struct {
+	repo           coupon.Repository
+	audit          audit.Usecase
+	contextTimeout time.Duration
+}
+
+// maxBatch is the most coupons generated by a single call
+const maxBatch = 10000
+
+var logger = utils.LogBuilder(true)
+
+// NewCouponUsecase will create object that represent of coupon.Usecase interface
+func NewCouponUsecase(c coupon.Repository, a audit.Usecase, timeout time.Duration) coupon.Usecase {
+	return &couponUsecase{
+		repo:           c,
+		audit:          a,
+		contextTimeout: timeout,
+	}
+}
+
+func (c *couponUsecase) record(ctx context.Context, id int64, action string, before interface{}, after interface{}) {
+	if err := c.audit.Record(ctx, models.AuditCoupon, id, action, before, after); err != nil {
+		logger.Error(err)
+	}
+}
+
+// validate reject coupons that could never be redeemed
+func validate(cp *models.Coupon) error {
+	if cp.Value <= 0 || cp.MinSpend < 0 || cp.MaxUses < 0 || cp.PerCustomer < 0 {
+		return models.ErrBadParamInput
+	}
+
+	switch cp.Action {
+	case models.PromotionPercentOff:
+		if cp.Value > 100 {
+			return models.ErrBadParamInput
+		}
+	case models.PromotionFixedOff:
+	default:
+		return models.ErrBadParamInput
+	}
+
+	return nil
+}
+
+func (c *couponUsecase) GetByID(ctx context.Context, id int64) (*models.Coupon, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
+	defer cancel()
+
+	return c.repo.GetByID(ctx, id)
+}
+
+func (c *couponUsecase) GetList(ctx context.Context, batch string, offset int64, limit int64) ([]*models.Coupon, int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
+	defer cancel()
+
+	coupons, found, err := c.repo.GetList(ctx, batch, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return coupons, found, nil
+}
+
+// Generate create count coupons sharing the rules of template. The code of
+// template is used as is when a single coupon is asked, otherwise it is the
+// prefix of random codes.
+func (c *couponUsecase) Generate(ctx context.Context, template *models.Coupon, count int) ([]*models.Coupon, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
+	defer cancel()
+
+	if count < 1 || count > maxBatch {
+		return nil, models.ErrBadParamInput
+	}
+
+	if err := validate(template); err != nil {
+		return nil, err
+	}
+
+	batch := template.Batch
+	if batch == "" {
+		batch = utils.RandString(4)
+	}
+
+	prefix := strings.ToUpper(template.Code)
+	codes := make(map[string]bool, count)
+	coupons := make([]*models.Coupon, 0, count)
+
+	for len(coupons) < count {
+		code := prefix
+		if count > 1 || code == "" {
+			code += strings.ToUpper(utils.RandString(5))
+		}
+
+		if codes[code] {
+			continue
+		}
+
+		codes[code] = true
+
+		cp := *template
+		cp.Code = code
+		cp.Batch = batch
+		cp.Used = 0
+		cp.Active = true
+		coupons = append(coupons, &cp)
+	}
+
+	err := c.repo.CreateBulk(ctx, coupons)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, cp := range coupons {
+		c.record(ctx, cp.ID, models.AuditCreate, nil, cp)
+	}
+
+	return coupons, nil
+}
+
+func (c *couponUsecase) Deactivate(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
+	defer cancel()
+
+	before, err := c.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = c.repo.Deactivate(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	after := *before
+	after.Active = false
+	c.record(ctx, id, models.AuditUpdate, before, &after)
+	return nil
+}
+
+// Apply check code can be redeemed by customer for an order of subtotal and
+// return the discount it give. Usage counters are only checked here, they
+// are enforced again when the order is stored.
+func (c *couponUsecase) Apply(ctx context.Context, code string, customer string, subtotal float64, now time.Time) (*models.OrderCoupon, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
+	defer cancel()
+
+	cp, err := c.repo.GetByCode(ctx, strings.ToUpper(code))
+	if err == models.ErrNotFound {
+		return nil, models.ErrCouponUnavailable
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !cp.Active || (cp.ExpiresAt.Valid && !now.Before(cp.ExpiresAt.Time)) {
+		return nil, models.ErrCouponUnavailable
+	}
+
+	if subtotal < cp.MinSpend || (cp.MaxUses > 0 && cp.Used >= cp.MaxUses) {
+		return nil, models.ErrCouponUnavailable
+	}
+
+	if cp.PerCustomer > 0 {
+		// a limit per customer can not be kept without knowing the customer
+		if customer == "" {
+			return nil, models.ErrCouponUnavailable
+		}
+
+		used, err := c.repo.CountRedemptions(ctx, cp.ID, customer)
+		if err != nil {
+			return nil, err
+		}
+
+		if used >= cp.PerCustomer {
+			return nil, models.ErrCouponUnavailable
+		}
+	}
+
+	var discount float64
+	switch cp.Action {
+	case models.PromotionPercentOff:
+		discount = subtotal * cp.Value / 100
+	case models.PromotionFixedOff:
+		discount = math.Min(cp.Value, subtotal)
+	}
+
+	return &models.OrderCoupon{
+		CouponID: cp.ID,
+		Code:     cp.Code,
+		Customer: customer,
+		Discount: math.Round(discount*100) / 100,
+	}, nil
+}
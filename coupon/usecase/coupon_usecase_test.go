@@ -0,0 +1,182 @@
+package usecase_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/soerjadi/exam/audit/audittest"
+	"github.com/soerjadi/exam/coupon/mocks"
+	"github.com/soerjadi/exam/coupon/usecase"
+	"github.com/soerjadi/exam/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gopkg.in/guregu/null.v3"
+)
+
+func TestGenerate(t *testing.T) {
+	mockCouponRepo := new(mocks.Repository)
+
+	t.Run("bulk", func(t *testing.T) {
+		template := models.Coupon{
+			Code:    "spr-",
+			Batch:   "spring",
+			Action:  models.PromotionFixedOff,
+			Value:   5000,
+			MaxUses: 1,
+		}
+
+		mockCouponRepo.On("CreateBulk", mock.Anything, mock.AnythingOfType("[]*models.Coupon")).Return(nil).Once()
+
+		mockAudit := audittest.NewUsecase()
+		c := usecase.NewCouponUsecase(mockCouponRepo, mockAudit, time.Second*2)
+		coupons, err := c.Generate(context.TODO(), &template, 50)
+
+		assert.NoError(t, err)
+		assert.Len(t, coupons, 50)
+		mockAudit.AssertNumberOfCalls(t, "Record", 50)
+		audittest.AssertRecorded(t, mockAudit, models.AuditCoupon, coupons[0].ID, models.AuditCreate, nil, coupons[0])
+
+		codes := make(map[string]bool)
+		for _, cp := range coupons {
+			assert.True(t, strings.HasPrefix(cp.Code, "SPR-"))
+			assert.Equal(t, "spring", cp.Batch)
+			assert.True(t, cp.Active)
+			codes[cp.Code] = true
+		}
+
+		assert.Len(t, codes, 50)
+		mockCouponRepo.AssertExpectations(t)
+	})
+
+	t.Run("single code", func(t *testing.T) {
+		template := models.Coupon{
+			Code:        "xmas",
+			Action:      models.PromotionPercentOff,
+			Value:       10,
+			MaxUses:     100,
+			PerCustomer: 1,
+		}
+
+		mockCouponRepo.On("CreateBulk", mock.Anything, mock.AnythingOfType("[]*models.Coupon")).Return(nil).Once()
+
+		c := usecase.NewCouponUsecase(mockCouponRepo, audittest.NewUsecase(), time.Second*2)
+		coupons, err := c.Generate(context.TODO(), &template, 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "XMAS", coupons[0].Code)
+		assert.NotEmpty(t, coupons[0].Batch)
+		mockCouponRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		mockAudit := audittest.NewUsecase()
+		c := usecase.NewCouponUsecase(mockCouponRepo, mockAudit, time.Second*2)
+
+		_, err := c.Generate(context.TODO(), &models.Coupon{Action: models.PromotionPercentOff, Value: 120}, 1)
+		assert.Equal(t, models.ErrBadParamInput, err)
+
+		_, err = c.Generate(context.TODO(), &models.Coupon{Action: models.PromotionFreeItem, Value: 1}, 1)
+		assert.Equal(t, models.ErrBadParamInput, err)
+
+		_, err = c.Generate(context.TODO(), &models.Coupon{Action: models.PromotionFixedOff, Value: 10}, 0)
+		assert.Equal(t, models.ErrBadParamInput, err)
+		audittest.AssertNothingRecorded(t, mockAudit)
+	})
+}
+
+func TestApply(t *testing.T) {
+	now := time.Now()
+	coupon := models.Coupon{
+		ID:          3,
+		Code:        "XMAS",
+		Action:      models.PromotionPercentOff,
+		Value:       10,
+		MinSpend:    50000,
+		MaxUses:     100,
+		PerCustomer: 1,
+		Used:        12,
+		ExpiresAt:   null.TimeFrom(now.Add(time.Hour)),
+		Active:      true,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockCouponRepo := new(mocks.Repository)
+		mockCouponRepo.On("GetByCode", mock.Anything, "XMAS").Return(&coupon, nil).Once()
+		mockCouponRepo.On("CountRedemptions", mock.Anything, coupon.ID, "jane@example.com").Return(int64(0), nil).Once()
+
+		c := usecase.NewCouponUsecase(mockCouponRepo, audittest.NewUsecase(), time.Second*2)
+		redeemed, err := c.Apply(context.TODO(), "xmas", "jane@example.com", 81000, now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 8100.0, redeemed.Discount)
+		assert.Equal(t, coupon.ID, redeemed.CouponID)
+		mockCouponRepo.AssertExpectations(t)
+	})
+
+	t.Run("unavailable", func(t *testing.T) {
+		expired := coupon
+		expired.ExpiresAt = null.TimeFrom(now.Add(-time.Hour))
+
+		usedUp := coupon
+		usedUp.Used = usedUp.MaxUses
+
+		inactive := coupon
+		inactive.Active = false
+
+		cases := map[string]struct {
+			coupon   *models.Coupon
+			customer string
+			subtotal float64
+		}{
+			"expired":        {&expired, "jane@example.com", 81000},
+			"used up":        {&usedUp, "jane@example.com", 81000},
+			"inactive":       {&inactive, "jane@example.com", 81000},
+			"below min":      {&coupon, "jane@example.com", 40000},
+			"no customer":    {&coupon, "", 81000},
+			"customer limit": {&coupon, "john@example.com", 81000},
+		}
+
+		for name, tc := range cases {
+			mockCouponRepo := new(mocks.Repository)
+			mockCouponRepo.On("GetByCode", mock.Anything, "XMAS").Return(tc.coupon, nil).Once()
+			mockCouponRepo.On("CountRedemptions", mock.Anything, coupon.ID, "john@example.com").Return(int64(1), nil).Maybe()
+			mockCouponRepo.On("CountRedemptions", mock.Anything, coupon.ID, "jane@example.com").Return(int64(0), nil).Maybe()
+
+			c := usecase.NewCouponUsecase(mockCouponRepo, audittest.NewUsecase(), time.Second*2)
+			_, err := c.Apply(context.TODO(), "XMAS", tc.customer, tc.subtotal, now)
+
+			assert.Equal(t, models.ErrCouponUnavailable, err, name)
+		}
+	})
+
+	t.Run("unknown code", func(t *testing.T) {
+		mockCouponRepo := new(mocks.Repository)
+		mockCouponRepo.On("GetByCode", mock.Anything, "NOPE").Return(nil, models.ErrNotFound).Once()
+
+		c := usecase.NewCouponUsecase(mockCouponRepo, audittest.NewUsecase(), time.Second*2)
+		_, err := c.Apply(context.TODO(), "nope", "", 81000, now)
+
+		assert.Equal(t, models.ErrCouponUnavailable, err)
+	})
+}
+
+func TestDeactivate(t *testing.T) {
+	mockCouponRepo := new(mocks.Repository)
+	coupon := models.Coupon{ID: 3, Code: "XMAS", Active: true}
+
+	mockCouponRepo.On("GetByID", mock.Anything, coupon.ID).Return(&coupon, nil).Once()
+	mockCouponRepo.On("Deactivate", mock.Anything, coupon.ID).Return(nil).Once()
+
+	mockAudit := audittest.NewUsecase()
+	c := usecase.NewCouponUsecase(mockCouponRepo, mockAudit, time.Second*2)
+	err := c.Deactivate(context.TODO(), coupon.ID)
+
+	assert.NoError(t, err)
+	mockCouponRepo.AssertExpectations(t)
+
+	after := coupon
+	after.Active = false
+	audittest.AssertRecorded(t, mockAudit, models.AuditCoupon, coupon.ID, models.AuditUpdate, &coupon, &after)
+}
@@ -0,0 +1,466 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/soerjadi/exam/auth"
+	"github.com/soerjadi/exam/customer"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/order"
+	"github.com/soerjadi/exam/utils"
+)
+
+type registerData struct {
+	Email    string `json:"email"`
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+type loginData struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type tokenData struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	Email        string `json:"email"`
+	Password     string `json:"password"`
+}
+
+type profileData struct {
+	Name string `json:"name"`
+}
+
+type roleData struct {
+	Role string `json:"role"`
+}
+
+// CustomerHandler represent the http handler for customer
+type CustomerHandler struct {
+	CustomerUsecase customer.Usecase
+	OrderUsecase    order.Usecase
+}
+
+// NewCustomerHandler initialize customer resource endpoint
+func NewCustomerHandler(router *mux.Router, usecase customer.Usecase, orderUsecase order.Usecase) *mux.Router {
+	handler := &CustomerHandler{
+		CustomerUsecase: usecase,
+		OrderUsecase:    orderUsecase,
+	}
+
+	p := router.PathPrefix("/v1/customer").Subrouter()
+	p.HandleFunc("/register", handler.Register).Methods("POST")
+	p.HandleFunc("/login", handler.Login).Methods("POST")
+	p.HandleFunc("/token/refresh", handler.Refresh).Methods("POST")
+	p.HandleFunc("/logout", handler.Logout).Methods("POST")
+	p.HandleFunc("/verify", handler.VerifyEmail).Methods("POST")
+	p.HandleFunc("/password/forgot", handler.ForgotPassword).Methods("POST")
+	p.HandleFunc("/password/reset", handler.ResetPassword).Methods("POST")
+	p.HandleFunc("/profile", handler.GetProfile).Methods("GET")
+	p.HandleFunc("/profile", handler.UpdateProfile).Methods("POST")
+	p.HandleFunc("/orders", handler.MyOrders).Methods("GET")
+
+	// role assignments are managed by admins
+	router.HandleFunc("/v1/admin/role", handler.RoleList).Methods("GET")
+	a := router.PathPrefix("/v1/admin/customer").Subrouter()
+	a.HandleFunc("/{id:[0-9]+}/roles", handler.GetRoles).Methods("GET")
+	a.HandleFunc("/{id:[0-9]+}/roles", handler.AssignRole).Methods("POST")
+	a.HandleFunc("/{id:[0-9]+}/roles/remove", handler.RevokeRole).Methods("GET")
+
+	return p
+}
+
+func decode(r *http.Request, v interface{}) error {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, v)
+}
+
+// errorStatus map errors of the customer usecase to http status
+func errorStatus(err error) int32 {
+	if err == models.ErrUnauthorized {
+		return http.StatusUnauthorized
+	}
+
+	return http.StatusBadRequest
+}
+
+// Register endpoint to sign up a customer
+func (h *CustomerHandler) Register(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var data registerData
+	if err := decode(r, &data); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	cs := models.Customer{
+		Email: data.Email,
+		Name:  data.Name,
+	}
+
+	err := h.CustomerUsecase.Register(ctx, &cs, data.Password)
+
+	if err != nil {
+		utils.Error(w, errorStatus(err), err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, cs)
+}
+
+// Login endpoint to exchange credentials for an access token
+func (h *CustomerHandler) Login(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var data loginData
+	if err := decode(r, &data); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	token, err := h.CustomerUsecase.Login(ctx, data.Email, data.Password)
+
+	if err != nil {
+		utils.Error(w, errorStatus(err), err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, token)
+}
+
+// Refresh endpoint to exchange a refresh token for a new access token
+func (h *CustomerHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var data tokenData
+	if err := decode(r, &data); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	token, err := h.CustomerUsecase.Refresh(ctx, data.RefreshToken)
+
+	if err != nil {
+		utils.Error(w, errorStatus(err), err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, token)
+}
+
+// Logout endpoint to revoke the access token of the request and the refresh
+// token sent with it
+func (h *CustomerHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var data tokenData
+	if err := decode(r, &data); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	err := h.CustomerUsecase.Logout(ctx, data.RefreshToken)
+
+	if err != nil {
+		utils.Error(w, errorStatus(err), err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, "success")
+}
+
+// VerifyEmail endpoint to confirm the email with the token sent to it
+func (h *CustomerHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var data tokenData
+	if err := decode(r, &data); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	err := h.CustomerUsecase.VerifyEmail(ctx, data.Token)
+
+	if err != nil {
+		utils.Error(w, errorStatus(err), err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, "success")
+}
+
+// ForgotPassword endpoint to ask for a password reset token, it succeed for
+// unknown emails too
+func (h *CustomerHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var data tokenData
+	if err := decode(r, &data); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	err := h.CustomerUsecase.RequestPasswordReset(ctx, data.Email)
+
+	if err != nil {
+		utils.Error(w, errorStatus(err), err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, "success")
+}
+
+// ResetPassword endpoint to choose a new password with a reset token
+func (h *CustomerHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var data tokenData
+	if err := decode(r, &data); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	err := h.CustomerUsecase.ResetPassword(ctx, data.Token, data.Password)
+
+	if err != nil {
+		utils.Error(w, errorStatus(err), err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, "success")
+}
+
+// GetProfile endpoint to get the authenticated customer
+func (h *CustomerHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	id, ok := auth.CustomerID(ctx)
+	if !ok {
+		utils.Error(w, http.StatusUnauthorized, models.ErrUnauthorized.Error())
+		return
+	}
+
+	cs, err := h.CustomerUsecase.GetByID(ctx, id)
+
+	if err != nil {
+		utils.Error(w, errorStatus(err), err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, cs)
+}
+
+// UpdateProfile endpoint to change the profile of the authenticated customer
+func (h *CustomerHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var data profileData
+	if err := decode(r, &data); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	id, ok := auth.CustomerID(ctx)
+	if !ok {
+		utils.Error(w, http.StatusUnauthorized, models.ErrUnauthorized.Error())
+		return
+	}
+
+	cs := models.Customer{
+		ID:   id,
+		Name: data.Name,
+	}
+
+	err := h.CustomerUsecase.UpdateProfile(ctx, &cs)
+
+	if err != nil {
+		utils.Error(w, errorStatus(err), err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, cs)
+}
+
+// MyOrders endpoint to list orders of the authenticated customer
+func (h *CustomerHandler) MyOrders(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	limit, err := strconv.ParseInt(params.Get("limit"), 0, 64)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	offset, err := strconv.ParseInt(params.Get("offset"), 0, 64)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if limit == 0 {
+		limit = 10
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	id, ok := auth.CustomerID(ctx)
+	if !ok {
+		utils.Error(w, http.StatusUnauthorized, models.ErrUnauthorized.Error())
+		return
+	}
+
+	orders, found, err := h.OrderUsecase.GetByCustomer(ctx, id, offset, limit)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entriesResult := &utils.EntriesResponse{
+		Data:  orders,
+		Found: found,
+	}
+
+	utils.JSON(w, http.StatusOK, entriesResult)
+}
+
+// RoleList endpoint to list the roles and the permissions they grant
+func (h *CustomerHandler) RoleList(w http.ResponseWriter, r *http.Request) {
+	utils.JSON(w, http.StatusOK, auth.RolePermissions)
+}
+
+// GetRoles endpoint to list the roles assigned to a customer
+func (h *CustomerHandler) GetRoles(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 0, 64)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	roles, err := h.CustomerUsecase.GetRoles(ctx, id)
+
+	if err != nil {
+		utils.Error(w, errorStatus(err), err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, roles)
+}
+
+// AssignRole endpoint to give a role to a customer
+func (h *CustomerHandler) AssignRole(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 0, 64)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var data roleData
+	if err = decode(r, &data); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	err = h.CustomerUsecase.AssignRole(ctx, id, data.Role)
+
+	if err != nil {
+		utils.Error(w, errorStatus(err), err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, "success")
+}
+
+// RevokeRole endpoint to take a role back from a customer
+func (h *CustomerHandler) RevokeRole(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 0, 64)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	err = h.CustomerUsecase.RevokeRole(ctx, id, r.URL.Query().Get("role"))
+
+	if err != nil {
+		utils.Error(w, errorStatus(err), err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, "success")
+}
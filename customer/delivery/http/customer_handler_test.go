@@ -0,0 +1,111 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/soerjadi/exam/auth"
+	customerHttp "github.com/soerjadi/exam/customer/delivery/http"
+	"github.com/soerjadi/exam/customer/mocks"
+	"github.com/soerjadi/exam/models"
+	orderMocks "github.com/soerjadi/exam/order/mocks"
+	"github.com/soerjadi/exam/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLogin(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockUsecase := new(mocks.Usecase)
+		mockUsecase.On("Login", mock.Anything, "jane@example.com", "correct horse").Return(&utils.AccessTokenData{Token: "signed", RefreshToken: "refresh"}, nil).Once()
+
+		req, err := http.NewRequest("POST", "/v1/customer/login", strings.NewReader(`{"email":"jane@example.com","password":"correct horse"}`))
+		assert.NoError(t, err)
+
+		handler := customerHttp.CustomerHandler{
+			CustomerUsecase: mockUsecase,
+		}
+
+		rec := httptest.NewRecorder()
+		handler.Login(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"refresh_token":"refresh"`)
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		mockUsecase := new(mocks.Usecase)
+		mockUsecase.On("Login", mock.Anything, "jane@example.com", "battery staple").Return(nil, models.ErrUnauthorized).Once()
+
+		req, err := http.NewRequest("POST", "/v1/customer/login", strings.NewReader(`{"email":"jane@example.com","password":"battery staple"}`))
+		assert.NoError(t, err)
+
+		handler := customerHttp.CustomerHandler{
+			CustomerUsecase: mockUsecase,
+		}
+
+		rec := httptest.NewRecorder()
+		handler.Login(rec, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
+
+func TestGetProfileAnonymous(t *testing.T) {
+	req, err := http.NewRequest("GET", "/v1/customer/profile", strings.NewReader(""))
+	assert.NoError(t, err)
+
+	handler := customerHttp.CustomerHandler{
+		CustomerUsecase: new(mocks.Usecase),
+	}
+
+	rec := httptest.NewRecorder()
+	handler.GetProfile(rec, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestMyOrders(t *testing.T) {
+	mockOrderUsecase := new(orderMocks.Usecase)
+	mockOrderUsecase.On("GetByCustomer", mock.Anything, int64(4), int64(0), int64(10)).
+		Return([]*models.Order{&models.Order{ID: 12}}, int64(1), nil).Once()
+
+	req, err := http.NewRequest("GET", "/v1/customer/orders?offset=0&limit=10", strings.NewReader(""))
+	assert.NoError(t, err)
+	req = req.WithContext(auth.WithClaims(context.TODO(), &auth.Claims{Subject: 4}))
+
+	handler := customerHttp.CustomerHandler{
+		CustomerUsecase: new(mocks.Usecase),
+		OrderUsecase:    mockOrderUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.MyOrders(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"found":1`)
+	mockOrderUsecase.AssertExpectations(t)
+}
+
+func TestAssignRole(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("AssignRole", mock.Anything, int64(4), "viewer").Return(nil).Once()
+
+	req, err := http.NewRequest("POST", "/v1/admin/customer/4/roles", strings.NewReader(`{"role":"viewer"}`))
+	assert.NoError(t, err)
+	req = mux.SetURLVars(req, map[string]string{"id": "4"})
+
+	handler := customerHttp.CustomerHandler{
+		CustomerUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.AssignRole(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
@@ -0,0 +1,29 @@
+package customer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/utils"
+)
+
+// Notifier deliver tokens to customers, by email once a provider is wired
+type Notifier interface {
+	Notify(ctx context.Context, customer *models.Customer, purpose string, token string) error
+}
+
+type logNotifier struct {
+	logger *utils.Logger
+}
+
+// NewLogNotifier create Notifier writing tokens to the debug log, meant for
+// development only
+func NewLogNotifier() Notifier {
+	return &logNotifier{utils.LogBuilder(true)}
+}
+
+func (l *logNotifier) Notify(ctx context.Context, customer *models.Customer, purpose string, token string) error {
+	l.logger.Debug(fmt.Sprintf("%s token for %s: %s", purpose, customer.Email, token))
+	return nil
+}
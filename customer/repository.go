@@ -0,0 +1,27 @@
+package customer
+
+import (
+	"context"
+	"time"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// Repository represent the customer repository contract
+type Repository interface {
+	GetByID(ctx context.Context, id int64) (*models.Customer, error)
+	GetByEmail(ctx context.Context, email string) (*models.Customer, error)
+	Create(ctx context.Context, customer *models.Customer) error
+	Update(ctx context.Context, customer *models.Customer) error
+	UpdatePassword(ctx context.Context, id int64, hash string) error
+	MarkVerified(ctx context.Context, id int64) error
+	CreateToken(ctx context.Context, token *models.CustomerToken) error
+	UseToken(ctx context.Context, hash string, purpose string, now time.Time) (*models.CustomerToken, error)
+	RevokeTokens(ctx context.Context, customerID int64, purpose string, now time.Time) error
+	RevokeAccessToken(ctx context.Context, id string, expiresAt time.Time) error
+	IsAccessTokenRevoked(ctx context.Context, id string) (bool, error)
+	PurgeExpiredTokens(ctx context.Context, now time.Time) (int64, error)
+	GetRoles(ctx context.Context, customerID int64) ([]string, error)
+	AddRole(ctx context.Context, customerID int64, role string) error
+	RemoveRole(ctx context.Context, customerID int64, role string) error
+}
@@ -0,0 +1,333 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/soerjadi/exam/customer"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/utils"
+)
+
+type pgCustomerRepository struct {
+	Conn *sql.DB
+}
+
+var logger = utils.LogBuilder(true)
+
+// NewPGCustomerRepository is bridge to create an object from customer.Repository interface
+func NewPGCustomerRepository(Conn *sql.DB) customer.Repository {
+	return &pgCustomerRepository{Conn}
+}
+
+func (c *pgCustomerRepository) fetch(ctx context.Context, query string, args ...interface{}) ([]*models.Customer, error) {
+	rows, err := c.Conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Error(err)
+		}
+	}()
+
+	result := make([]*models.Customer, 0)
+	for rows.Next() {
+		t := new(models.Customer)
+
+		err = rows.Scan(
+			&t.ID,
+			&t.Email,
+			&t.Name,
+			&t.Password,
+			&t.Verified,
+			&t.Created,
+			&t.Updated,
+		)
+
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+
+		result = append(result, t)
+	}
+
+	return result, nil
+}
+
+func (c *pgCustomerRepository) fetchRow(ctx context.Context, query string, args ...interface{}) (*sql.Row, error) {
+	stmt, err := c.Conn.PrepareContext(ctx, query)
+
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	defer func() {
+		err := stmt.Close()
+		if err != nil {
+			logger.Error(err)
+		}
+	}()
+
+	return stmt.QueryRow(args...), nil
+}
+
+func (c *pgCustomerRepository) getOne(ctx context.Context, query string, args ...interface{}) (*models.Customer, error) {
+	customers, err := c.fetch(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(customers) == 0 {
+		return nil, models.ErrNotFound
+	}
+
+	return customers[0], nil
+}
+
+func (c *pgCustomerRepository) exec(ctx context.Context, query string, args ...interface{}) error {
+	stmt, err := c.Conn.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	res, err := stmt.ExecContext(ctx, args...)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected != 1 {
+		return models.ErrNotFound
+	}
+
+	return nil
+}
+
+func (c *pgCustomerRepository) GetByID(ctx context.Context, id int64) (*models.Customer, error) {
+	query := `SELECT id, email, name, password, verified, created, updated FROM customers WHERE id = ?`
+
+	return c.getOne(ctx, query, id)
+}
+
+// GetByEmail find customer by email, emails are stored lower cased
+func (c *pgCustomerRepository) GetByEmail(ctx context.Context, email string) (*models.Customer, error) {
+	query := `SELECT id, email, name, password, verified, created, updated FROM customers WHERE email = ?`
+
+	return c.getOne(ctx, query, email)
+}
+
+func (c *pgCustomerRepository) Create(ctx context.Context, cs *models.Customer) error {
+	query := `INSERT INTO customers(email, name, password, verified) VALUES(?, ?, ?, ?) returning id`
+
+	stmt, err := c.Conn.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	result, err := stmt.ExecContext(ctx, cs.Email, cs.Name, cs.Password, cs.Verified)
+	if err != nil {
+		return err
+	}
+
+	lastID, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	cs.ID = lastID
+	return nil
+}
+
+// Update store the profile of a customer, email and password are changed
+// through their own flows
+func (c *pgCustomerRepository) Update(ctx context.Context, cs *models.Customer) error {
+	return c.exec(ctx, `UPDATE customers SET name = ?, updated = ? WHERE id = ?`, cs.Name, cs.Updated, cs.ID)
+}
+
+func (c *pgCustomerRepository) UpdatePassword(ctx context.Context, id int64, hash string) error {
+	return c.exec(ctx, `UPDATE customers SET password = ?, updated = ? WHERE id = ?`, hash, time.Now(), id)
+}
+
+func (c *pgCustomerRepository) MarkVerified(ctx context.Context, id int64) error {
+	return c.exec(ctx, `UPDATE customers SET verified = true, updated = ? WHERE id = ?`, time.Now(), id)
+}
+
+func (c *pgCustomerRepository) CreateToken(ctx context.Context, token *models.CustomerToken) error {
+	query := `INSERT INTO customer_tokens(customer_id, purpose, hash, expires_at) VALUES(?, ?, ?, ?) returning id`
+
+	stmt, err := c.Conn.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	result, err := stmt.ExecContext(ctx, token.CustomerID, token.Purpose, token.Hash, token.ExpiresAt)
+	if err != nil {
+		return err
+	}
+
+	lastID, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	token.ID = lastID
+	return nil
+}
+
+// UseToken mark the token matching hash used and return it, a token can only
+// be used once and before it expire
+func (c *pgCustomerRepository) UseToken(ctx context.Context, hash string, purpose string, now time.Time) (*models.CustomerToken, error) {
+	query := `UPDATE customer_tokens SET used_at = ? WHERE hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?
+		returning id, customer_id, purpose, hash, expires_at, used_at, created`
+
+	row, err := c.fetchRow(ctx, query, now, hash, purpose, now)
+	if err != nil {
+		return nil, err
+	}
+
+	t := new(models.CustomerToken)
+	err = row.Scan(&t.ID, &t.CustomerID, &t.Purpose, &t.Hash, &t.ExpiresAt, &t.UsedAt, &t.Created)
+
+	if err == sql.ErrNoRows {
+		return nil, models.ErrNotFound
+	}
+
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// RevokeTokens mark every unused token of the customer for purpose used, e.g.
+// to end all sessions once the password changed
+func (c *pgCustomerRepository) RevokeTokens(ctx context.Context, customerID int64, purpose string, now time.Time) error {
+	query := `UPDATE customer_tokens SET used_at = ? WHERE customer_id = ? AND purpose = ? AND used_at IS NULL`
+
+	stmt, err := c.Conn.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	_, err = stmt.ExecContext(ctx, now, customerID, purpose)
+	return err
+}
+
+// RevokeAccessToken deny the access token id until it expire on its own
+func (c *pgCustomerRepository) RevokeAccessToken(ctx context.Context, id string, expiresAt time.Time) error {
+	query := `INSERT INTO revoked_tokens(token_id, expires_at) VALUES(?, ?) ON CONFLICT (token_id) DO NOTHING`
+
+	stmt, err := c.Conn.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	_, err = stmt.ExecContext(ctx, id, expiresAt)
+	return err
+}
+
+func (c *pgCustomerRepository) IsAccessTokenRevoked(ctx context.Context, id string) (bool, error) {
+	query := `SELECT count(token_id) FROM revoked_tokens WHERE token_id = ?`
+
+	row, err := c.fetchRow(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+
+	var count int64
+	if err = row.Scan(&count); err != nil {
+		logger.Error(err)
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// PurgeExpiredTokens delete tokens and revocations which expired before now,
+// an expired token is refused anyway
+func (c *pgCustomerRepository) PurgeExpiredTokens(ctx context.Context, now time.Time) (int64, error) {
+	var purged int64
+	for _, query := range []string{
+		`DELETE FROM customer_tokens WHERE expires_at <= ?`,
+		`DELETE FROM revoked_tokens WHERE expires_at <= ?`,
+	} {
+		stmt, err := c.Conn.PrepareContext(ctx, query)
+		if err != nil {
+			return purged, err
+		}
+
+		res, err := stmt.ExecContext(ctx, now)
+		if err != nil {
+			return purged, err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return purged, err
+		}
+
+		purged += affected
+	}
+
+	return purged, nil
+}
+
+// GetRoles list the roles assigned to the customer
+func (c *pgCustomerRepository) GetRoles(ctx context.Context, customerID int64) ([]string, error) {
+	query := `SELECT role FROM customer_roles WHERE customer_id = ? ORDER BY role`
+
+	rows, err := c.Conn.QueryContext(ctx, query, customerID)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Error(err)
+		}
+	}()
+
+	result := make([]string, 0)
+	for rows.Next() {
+		var role string
+		if err = rows.Scan(&role); err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+
+		result = append(result, role)
+	}
+
+	return result, nil
+}
+
+// AddRole assign role to the customer, assigning it twice is a no-op
+func (c *pgCustomerRepository) AddRole(ctx context.Context, customerID int64, role string) error {
+	query := `INSERT INTO customer_roles(customer_id, role) VALUES(?, ?) ON CONFLICT (customer_id, role) DO NOTHING`
+
+	stmt, err := c.Conn.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	_, err = stmt.ExecContext(ctx, customerID, role)
+	return err
+}
+
+func (c *pgCustomerRepository) RemoveRole(ctx context.Context, customerID int64, role string) error {
+	return c.exec(ctx, `DELETE FROM customer_roles WHERE customer_id = ? AND role = ?`, customerID, role)
+}
@@ -0,0 +1,147 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/soerjadi/exam/customer/repository"
+	"github.com/soerjadi/exam/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var columns = []string{"id", "email", "name", "password", "verified", "created", "updated"}
+
+func TestGetByEmail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	query := "SELECT (.+) FROM customers WHERE email = \\?"
+
+	t.Run("found", func(t *testing.T) {
+		rows := sqlmock.NewRows(columns).AddRow(4, "jane@example.com", "Jane", "$2a$10$hash", true, time.Now(), nil)
+		mock.ExpectQuery(query).WithArgs("jane@example.com").WillReturnRows(rows)
+
+		c := repository.NewPGCustomerRepository(db)
+		cs, err := c.GetByEmail(context.TODO(), "jane@example.com")
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(4), cs.ID)
+		assert.True(t, cs.Verified)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs("john@example.com").WillReturnRows(sqlmock.NewRows(columns))
+
+		c := repository.NewPGCustomerRepository(db)
+		_, err := c.GetByEmail(context.TODO(), "john@example.com")
+
+		assert.Equal(t, models.ErrNotFound, err)
+	})
+}
+
+func TestCreate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	cs := &models.Customer{
+		Email:    "jane@example.com",
+		Name:     "Jane",
+		Password: "$2a$10$hash",
+	}
+
+	query := "INSERT INTO customers\\(email, name, password, verified\\) VALUES\\(\\?, \\?, \\?, \\?\\) returning id"
+	mock.ExpectPrepare(query).ExpectExec().WithArgs(cs.Email, cs.Name, cs.Password, false).
+		WillReturnResult(sqlmock.NewResult(4, 1))
+
+	c := repository.NewPGCustomerRepository(db)
+	err = c.Create(context.TODO(), cs)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), cs.ID)
+}
+
+func TestMarkVerified(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	query := "UPDATE customers SET verified = true, updated = \\? WHERE id = \\?"
+	mock.ExpectPrepare(query).ExpectExec().WithArgs(sqlmock.AnyArg(), int64(4)).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	c := repository.NewPGCustomerRepository(db)
+	err = c.MarkVerified(context.TODO(), int64(4))
+
+	assert.Equal(t, models.ErrNotFound, err)
+}
+
+func TestUseToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Now()
+	query := "UPDATE customer_tokens SET used_at = \\? WHERE hash = \\? AND purpose = \\? AND used_at IS NULL AND expires_at > \\?"
+
+	t.Run("success", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "customer_id", "purpose", "hash", "expires_at", "used_at", "created"}).
+			AddRow(1, 4, models.TokenVerifyEmail, "abc", now.Add(time.Hour), now, now.Add(-time.Hour))
+		mock.ExpectPrepare(query).ExpectQuery().WithArgs(now, "abc", models.TokenVerifyEmail, now).WillReturnRows(rows)
+
+		c := repository.NewPGCustomerRepository(db)
+		token, err := c.UseToken(context.TODO(), "abc", models.TokenVerifyEmail, now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(4), token.CustomerID)
+		assert.True(t, token.UsedAt.Valid)
+	})
+
+	t.Run("used or expired", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "customer_id", "purpose", "hash", "expires_at", "used_at", "created"})
+		mock.ExpectPrepare(query).ExpectQuery().WithArgs(now, "abc", models.TokenVerifyEmail, now).WillReturnRows(rows)
+
+		c := repository.NewPGCustomerRepository(db)
+		_, err := c.UseToken(context.TODO(), "abc", models.TokenVerifyEmail, now)
+
+		assert.Equal(t, models.ErrNotFound, err)
+	})
+}
+
+func TestIsAccessTokenRevoked(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectPrepare("SELECT count\\(token_id\\) FROM revoked_tokens WHERE token_id = \\?").ExpectQuery().
+		WithArgs("abc").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	c := repository.NewPGCustomerRepository(db)
+	revoked, err := c.IsAccessTokenRevoked(context.TODO(), "abc")
+
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+}
+
+func TestGetRoles(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows([]string{"role"}).AddRow("catalog_editor").AddRow("viewer")
+	mock.ExpectQuery("SELECT role FROM customer_roles WHERE customer_id = \\?").WithArgs(int64(4)).WillReturnRows(rows)
+
+	c := repository.NewPGCustomerRepository(db)
+	roles, err := c.GetRoles(context.TODO(), int64(4))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"catalog_editor", "viewer"}, roles)
+}
@@ -0,0 +1,27 @@
+package customer
+
+import (
+	"context"
+
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/utils"
+)
+
+// Usecase represent the customer usecase, it also hold the revoked access
+// tokens checked by the auth middleware
+type Usecase interface {
+	Register(ctx context.Context, customer *models.Customer, password string) error
+	Login(ctx context.Context, email string, password string) (*utils.AccessTokenData, error)
+	Refresh(ctx context.Context, refreshToken string) (*utils.AccessTokenData, error)
+	Logout(ctx context.Context, refreshToken string) error
+	IsRevoked(ctx context.Context, id string) (bool, error)
+	PurgeExpiredTokens(ctx context.Context) (int64, error)
+	GetRoles(ctx context.Context, customerID int64) ([]string, error)
+	AssignRole(ctx context.Context, customerID int64, role string) error
+	RevokeRole(ctx context.Context, customerID int64, role string) error
+	GetByID(ctx context.Context, id int64) (*models.Customer, error)
+	UpdateProfile(ctx context.Context, customer *models.Customer) error
+	VerifyEmail(ctx context.Context, token string) error
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token string, password string) error
+}
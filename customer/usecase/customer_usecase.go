@@ -0,0 +1,396 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/soerjadi/exam/audit"
+	"github.com/soerjadi/exam/auth"
+	"github.com/soerjadi/exam/customer"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/utils"
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/guregu/null.v3"
+)
+
+type customerUsecase struct {
+	repo           customer.Repository
+	notifier       customer.Notifier
+	signer         *auth.Signer
+	audit          audit.Usecase
+	contextTimeout time.Duration
+}
+
+// minPasswordLength is the shortest password accepted
+const minPasswordLength = 8
+
+// how long tokens sent to customers stay valid
+var tokenTTL = map[string]time.Duration{
+	models.TokenVerifyEmail:   48 * time.Hour,
+	models.TokenResetPassword: time.Hour,
+	models.TokenRefresh:       30 * 24 * time.Hour,
+}
+
+// dummyHash is compared against when the email is unknown so a failed login
+// take the same time whether the customer exists or not
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+
+var logger = utils.LogBuilder(true)
+
+// NewCustomerUsecase will create object that represent of customer.Usecase interface
+func NewCustomerUsecase(c customer.Repository, n customer.Notifier, s *auth.Signer, a audit.Usecase, timeout time.Duration) customer.Usecase {
+	return &customerUsecase{
+		repo:           c,
+		notifier:       n,
+		signer:         s,
+		audit:          a,
+		contextTimeout: timeout,
+	}
+}
+
+func (c *customerUsecase) record(ctx context.Context, id int64, action string, before interface{}, after interface{}) {
+	if err := c.audit.Record(ctx, models.AuditCustomer, id, action, before, after); err != nil {
+		logger.Error(err)
+	}
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// newToken store a single use token for purpose and return it in clear
+func (c *customerUsecase) newToken(ctx context.Context, customerID int64, purpose string) (string, error) {
+	token := utils.RandString(32)
+	err := c.repo.CreateToken(ctx, &models.CustomerToken{
+		CustomerID: customerID,
+		Purpose:    purpose,
+		Hash:       hashToken(token),
+		ExpiresAt:  time.Now().Add(tokenTTL[purpose]),
+	})
+
+	return token, err
+}
+
+// sendToken issue a token for purpose and hand it to the notifier
+func (c *customerUsecase) sendToken(ctx context.Context, cs *models.Customer, purpose string) error {
+	token, err := c.newToken(ctx, cs.ID, purpose)
+	if err != nil {
+		return err
+	}
+
+	return c.notifier.Notify(ctx, cs, purpose, token)
+}
+
+// issue sign an access token for the customer together with the refresh
+// token replacing it once expired. The roles are read at that time, so a
+// change of roles apply once the access token is refreshed.
+func (c *customerUsecase) issue(ctx context.Context, cs *models.Customer) (*utils.AccessTokenData, error) {
+	roles, err := c.repo.GetRoles(ctx, cs.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := auth.Claims{
+		Subject: cs.ID,
+		Email:   cs.Email,
+		Roles:   append([]string{auth.RoleCustomer}, roles...),
+	}
+
+	token, err := c.signer.Sign(claims, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	refresh, err := c.newToken(ctx, cs.ID, models.TokenRefresh)
+	if err != nil {
+		return nil, err
+	}
+
+	return &utils.AccessTokenData{
+		Token:        token,
+		RefreshToken: refresh,
+		ExpiresIn:    int64(c.signer.TTL() / time.Second),
+	}, nil
+}
+
+// Register create an unverified customer and send the verification token
+func (c *customerUsecase) Register(ctx context.Context, cs *models.Customer, password string) error {
+	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
+	defer cancel()
+
+	cs.Email = strings.ToLower(strings.TrimSpace(cs.Email))
+	if !strings.Contains(cs.Email, "@") || len(password) < minPasswordLength {
+		return models.ErrBadParamInput
+	}
+
+	_, err := c.repo.GetByEmail(ctx, cs.Email)
+	if err == nil {
+		return models.ErrConflict
+	}
+
+	if err != models.ErrNotFound {
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	cs.Password = string(hash)
+	cs.Verified = false
+
+	if err = c.repo.Create(ctx, cs); err != nil {
+		return err
+	}
+
+	c.record(ctx, cs.ID, models.AuditCreate, nil, cs)
+	return c.sendToken(ctx, cs, models.TokenVerifyEmail)
+}
+
+// Login check the credentials and return an access and a refresh token
+func (c *customerUsecase) Login(ctx context.Context, email string, password string) (*utils.AccessTokenData, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
+	defer cancel()
+
+	cs, err := c.repo.GetByEmail(ctx, strings.ToLower(strings.TrimSpace(email)))
+	if err != nil && err != models.ErrNotFound {
+		return nil, err
+	}
+
+	if cs == nil {
+		_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return nil, models.ErrUnauthorized
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(cs.Password), []byte(password)) != nil {
+		return nil, models.ErrUnauthorized
+	}
+
+	return c.issue(ctx, cs)
+}
+
+// Refresh exchange a refresh token for a new pair, the refresh token can not
+// be used again
+func (c *customerUsecase) Refresh(ctx context.Context, refreshToken string) (*utils.AccessTokenData, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
+	defer cancel()
+
+	used, err := c.repo.UseToken(ctx, hashToken(refreshToken), models.TokenRefresh, time.Now())
+	if err == models.ErrNotFound {
+		return nil, models.ErrUnauthorized
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	cs, err := c.repo.GetByID(ctx, used.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.issue(ctx, cs)
+}
+
+// Logout revoke the access token of the request and the refresh token given
+func (c *customerUsecase) Logout(ctx context.Context, refreshToken string) error {
+	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
+	defer cancel()
+
+	claims, ok := auth.ClaimsFrom(ctx)
+	if !ok && refreshToken == "" {
+		return models.ErrUnauthorized
+	}
+
+	if ok {
+		err := c.repo.RevokeAccessToken(ctx, claims.ID, time.Unix(claims.ExpiresAt, 0))
+		if err != nil {
+			return err
+		}
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+
+	_, err := c.repo.UseToken(ctx, hashToken(refreshToken), models.TokenRefresh, time.Now())
+	if err == models.ErrNotFound {
+		return nil
+	}
+
+	return err
+}
+
+func (c *customerUsecase) IsRevoked(ctx context.Context, id string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
+	defer cancel()
+
+	return c.repo.IsAccessTokenRevoked(ctx, id)
+}
+
+// PurgeExpiredTokens delete tokens that expired, they are refused anyway
+func (c *customerUsecase) PurgeExpiredTokens(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
+	defer cancel()
+
+	return c.repo.PurgeExpiredTokens(ctx, time.Now())
+}
+
+func (c *customerUsecase) GetByID(ctx context.Context, id int64) (*models.Customer, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
+	defer cancel()
+
+	return c.repo.GetByID(ctx, id)
+}
+
+// UpdateProfile change the profile of a customer, only the name for now
+func (c *customerUsecase) UpdateProfile(ctx context.Context, cs *models.Customer) error {
+	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
+	defer cancel()
+
+	before, err := c.repo.GetByID(ctx, cs.ID)
+	if err != nil {
+		return err
+	}
+
+	after := *before
+	after.Name = cs.Name
+	after.Updated = null.TimeFrom(time.Now())
+
+	if err = c.repo.Update(ctx, &after); err != nil {
+		return err
+	}
+
+	*cs = after
+	c.record(ctx, cs.ID, models.AuditUpdate, before, cs)
+	return nil
+}
+
+func (c *customerUsecase) VerifyEmail(ctx context.Context, token string) error {
+	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
+	defer cancel()
+
+	used, err := c.repo.UseToken(ctx, hashToken(token), models.TokenVerifyEmail, time.Now())
+	if err == models.ErrNotFound {
+		return models.ErrUnauthorized
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return c.repo.MarkVerified(ctx, used.CustomerID)
+}
+
+// RequestPasswordReset send a reset token to the customer of email. Unknown
+// emails are not reported so the endpoint can not tell who has an account.
+func (c *customerUsecase) RequestPasswordReset(ctx context.Context, email string) error {
+	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
+	defer cancel()
+
+	cs, err := c.repo.GetByEmail(ctx, strings.ToLower(strings.TrimSpace(email)))
+	if err == models.ErrNotFound {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return c.sendToken(ctx, cs, models.TokenResetPassword)
+}
+
+func (c *customerUsecase) ResetPassword(ctx context.Context, token string, password string) error {
+	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
+	defer cancel()
+
+	if len(password) < minPasswordLength {
+		return models.ErrBadParamInput
+	}
+
+	used, err := c.repo.UseToken(ctx, hashToken(token), models.TokenResetPassword, time.Now())
+	if err == models.ErrNotFound {
+		return models.ErrUnauthorized
+	}
+
+	if err != nil {
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	if err = c.repo.UpdatePassword(ctx, used.CustomerID, string(hash)); err != nil {
+		return err
+	}
+
+	// sessions opened with the old password can not be refreshed anymore
+	if err = c.repo.RevokeTokens(ctx, used.CustomerID, models.TokenRefresh, time.Now()); err != nil {
+		return err
+	}
+
+	// the customer received the token by email, so the address is proven too
+	return c.repo.MarkVerified(ctx, used.CustomerID)
+}
+
+// GetRoles list the roles assigned to the customer, the customer role every
+// account has is not listed
+func (c *customerUsecase) GetRoles(ctx context.Context, customerID int64) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
+	defer cancel()
+
+	if _, err := c.repo.GetByID(ctx, customerID); err != nil {
+		return nil, err
+	}
+
+	return c.repo.GetRoles(ctx, customerID)
+}
+
+// changeRole apply change to the roles of the customer and audit it
+func (c *customerUsecase) changeRole(ctx context.Context, customerID int64, role string, change func() error) error {
+	if role == auth.RoleCustomer || !auth.ValidRole(role) {
+		return models.ErrBadParamInput
+	}
+
+	before, err := c.GetRoles(ctx, customerID)
+	if err != nil {
+		return err
+	}
+
+	if err = change(); err != nil {
+		return err
+	}
+
+	after, err := c.repo.GetRoles(ctx, customerID)
+	if err != nil {
+		return err
+	}
+
+	c.record(ctx, customerID, models.AuditUpdate, map[string][]string{"roles": before}, map[string][]string{"roles": after})
+	return nil
+}
+
+func (c *customerUsecase) AssignRole(ctx context.Context, customerID int64, role string) error {
+	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
+	defer cancel()
+
+	return c.changeRole(ctx, customerID, role, func() error {
+		return c.repo.AddRole(ctx, customerID, role)
+	})
+}
+
+func (c *customerUsecase) RevokeRole(ctx context.Context, customerID int64, role string) error {
+	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
+	defer cancel()
+
+	return c.changeRole(ctx, customerID, role, func() error {
+		return c.repo.RemoveRole(ctx, customerID, role)
+	})
+}
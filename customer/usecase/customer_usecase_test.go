@@ -0,0 +1,250 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/soerjadi/exam/audit/audittest"
+	"github.com/soerjadi/exam/auth"
+	"github.com/soerjadi/exam/customer/mocks"
+	"github.com/soerjadi/exam/customer/usecase"
+	"github.com/soerjadi/exam/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var signer = auth.NewSigner("secret", time.Hour)
+
+func TestRegister(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockNotifier := new(mocks.Notifier)
+		mockRepo.On("GetByEmail", mock.Anything, "jane@example.com").Return(nil, models.ErrNotFound).Once()
+		mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Customer")).Return(nil).Once()
+		mockRepo.On("CreateToken", mock.Anything, mock.MatchedBy(func(token *models.CustomerToken) bool {
+			return token.Purpose == models.TokenVerifyEmail && len(token.Hash) == 64
+		})).Return(nil).Once()
+		mockNotifier.On("Notify", mock.Anything, mock.AnythingOfType("*models.Customer"), models.TokenVerifyEmail,
+			mock.AnythingOfType("string")).Return(nil).Once()
+
+		mockAudit := audittest.NewUsecase()
+		c := usecase.NewCustomerUsecase(mockRepo, mockNotifier, signer, mockAudit, time.Second*2)
+		cs := models.Customer{Email: " Jane@Example.com", Name: "Jane"}
+		err := c.Register(context.TODO(), &cs, "correct horse")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "jane@example.com", cs.Email)
+		assert.False(t, cs.Verified)
+		assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(cs.Password), []byte("correct horse")))
+		mockRepo.AssertExpectations(t)
+		mockNotifier.AssertExpectations(t)
+		audittest.AssertRecorded(t, mockAudit, models.AuditCustomer, cs.ID, models.AuditCreate, nil, &cs)
+	})
+
+	t.Run("email taken", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockRepo.On("GetByEmail", mock.Anything, "jane@example.com").Return(&models.Customer{ID: 4}, nil).Once()
+
+		c := usecase.NewCustomerUsecase(mockRepo, new(mocks.Notifier), signer, audittest.NewUsecase(), time.Second*2)
+		err := c.Register(context.TODO(), &models.Customer{Email: "jane@example.com"}, "correct horse")
+
+		assert.Equal(t, models.ErrConflict, err)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		c := usecase.NewCustomerUsecase(new(mocks.Repository), new(mocks.Notifier), signer, audittest.NewUsecase(), time.Second*2)
+
+		err := c.Register(context.TODO(), &models.Customer{Email: "jane"}, "correct horse")
+		assert.Equal(t, models.ErrBadParamInput, err)
+
+		err = c.Register(context.TODO(), &models.Customer{Email: "jane@example.com"}, "short")
+		assert.Equal(t, models.ErrBadParamInput, err)
+	})
+}
+
+func TestLogin(t *testing.T) {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	jane := &models.Customer{ID: 4, Email: "jane@example.com", Password: string(hash)}
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockRepo.On("GetByEmail", mock.Anything, "jane@example.com").Return(jane, nil).Once()
+		mockRepo.On("GetRoles", mock.Anything, jane.ID).Return([]string{auth.RoleCatalogEditor}, nil).Once()
+		mockRepo.On("CreateToken", mock.Anything, mock.MatchedBy(func(token *models.CustomerToken) bool {
+			return token.Purpose == models.TokenRefresh && token.CustomerID == jane.ID
+		})).Return(nil).Once()
+
+		c := usecase.NewCustomerUsecase(mockRepo, new(mocks.Notifier), signer, audittest.NewUsecase(), time.Second*2)
+		token, err := c.Login(context.TODO(), "JANE@example.com", "correct horse")
+		assert.NoError(t, err)
+		assert.NotEmpty(t, token.RefreshToken)
+		assert.Equal(t, int64(3600), token.ExpiresIn)
+
+		claims, err := signer.Verify(token.Token, time.Now())
+		assert.NoError(t, err)
+		assert.Equal(t, jane.ID, claims.Subject)
+		assert.NotEmpty(t, claims.ID)
+		assert.Equal(t, []string{auth.RoleCustomer, auth.RoleCatalogEditor}, claims.Roles)
+		assert.True(t, claims.Allowed(auth.CatalogWrite))
+		assert.False(t, claims.Allowed(auth.OrderWrite))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockRepo.On("GetByEmail", mock.Anything, "jane@example.com").Return(jane, nil).Once()
+
+		c := usecase.NewCustomerUsecase(mockRepo, new(mocks.Notifier), signer, audittest.NewUsecase(), time.Second*2)
+		_, err := c.Login(context.TODO(), "jane@example.com", "battery staple")
+
+		assert.Equal(t, models.ErrUnauthorized, err)
+	})
+
+	t.Run("unknown email", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockRepo.On("GetByEmail", mock.Anything, "john@example.com").Return(nil, models.ErrNotFound).Once()
+
+		c := usecase.NewCustomerUsecase(mockRepo, new(mocks.Notifier), signer, audittest.NewUsecase(), time.Second*2)
+		_, err := c.Login(context.TODO(), "john@example.com", "correct horse")
+
+		assert.Equal(t, models.ErrUnauthorized, err)
+	})
+}
+
+func TestRefresh(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockRepo.On("UseToken", mock.Anything, mock.AnythingOfType("string"), models.TokenRefresh, mock.AnythingOfType("time.Time")).
+			Return(&models.CustomerToken{ID: 3, CustomerID: 4}, nil).Once()
+		mockRepo.On("GetByID", mock.Anything, int64(4)).Return(&models.Customer{ID: 4, Email: "jane@example.com"}, nil).Once()
+		mockRepo.On("GetRoles", mock.Anything, int64(4)).Return([]string{}, nil).Once()
+		mockRepo.On("CreateToken", mock.Anything, mock.AnythingOfType("*models.CustomerToken")).Return(nil).Once()
+
+		c := usecase.NewCustomerUsecase(mockRepo, new(mocks.Notifier), signer, audittest.NewUsecase(), time.Second*2)
+		token, err := c.Refresh(context.TODO(), "refresh")
+		assert.NoError(t, err)
+		assert.NotEqual(t, "refresh", token.RefreshToken)
+
+		claims, err := signer.Verify(token.Token, time.Now())
+		assert.NoError(t, err)
+		assert.Equal(t, int64(4), claims.Subject)
+	})
+
+	t.Run("used token", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockRepo.On("UseToken", mock.Anything, mock.AnythingOfType("string"), models.TokenRefresh, mock.AnythingOfType("time.Time")).
+			Return(nil, models.ErrNotFound).Once()
+
+		c := usecase.NewCustomerUsecase(mockRepo, new(mocks.Notifier), signer, audittest.NewUsecase(), time.Second*2)
+		_, err := c.Refresh(context.TODO(), "refresh")
+
+		assert.Equal(t, models.ErrUnauthorized, err)
+	})
+}
+
+func TestLogout(t *testing.T) {
+	t.Run("revoke both tokens", func(t *testing.T) {
+		claims := &auth.Claims{ID: "abc", Subject: 4, ExpiresAt: time.Now().Add(time.Hour).Unix()}
+		mockRepo := new(mocks.Repository)
+		mockRepo.On("RevokeAccessToken", mock.Anything, "abc", time.Unix(claims.ExpiresAt, 0)).Return(nil).Once()
+		mockRepo.On("UseToken", mock.Anything, mock.AnythingOfType("string"), models.TokenRefresh, mock.AnythingOfType("time.Time")).
+			Return(&models.CustomerToken{ID: 3, CustomerID: 4}, nil).Once()
+
+		c := usecase.NewCustomerUsecase(mockRepo, new(mocks.Notifier), signer, audittest.NewUsecase(), time.Second*2)
+		err := c.Logout(auth.WithClaims(context.TODO(), claims), "refresh")
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("anonymous", func(t *testing.T) {
+		c := usecase.NewCustomerUsecase(new(mocks.Repository), new(mocks.Notifier), signer, audittest.NewUsecase(), time.Second*2)
+		err := c.Logout(context.TODO(), "")
+
+		assert.Equal(t, models.ErrUnauthorized, err)
+	})
+}
+
+func TestVerifyEmail(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockRepo.On("UseToken", mock.Anything, mock.AnythingOfType("string"), models.TokenVerifyEmail, mock.AnythingOfType("time.Time")).
+			Return(&models.CustomerToken{ID: 1, CustomerID: 4}, nil).Once()
+		mockRepo.On("MarkVerified", mock.Anything, int64(4)).Return(nil).Once()
+
+		c := usecase.NewCustomerUsecase(mockRepo, new(mocks.Notifier), signer, audittest.NewUsecase(), time.Second*2)
+		err := c.VerifyEmail(context.TODO(), "token")
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("used token", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockRepo.On("UseToken", mock.Anything, mock.AnythingOfType("string"), models.TokenVerifyEmail, mock.AnythingOfType("time.Time")).
+			Return(nil, models.ErrNotFound).Once()
+
+		c := usecase.NewCustomerUsecase(mockRepo, new(mocks.Notifier), signer, audittest.NewUsecase(), time.Second*2)
+		err := c.VerifyEmail(context.TODO(), "token")
+
+		assert.Equal(t, models.ErrUnauthorized, err)
+	})
+}
+
+func TestRequestPasswordReset(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockRepo.On("GetByEmail", mock.Anything, "john@example.com").Return(nil, models.ErrNotFound).Once()
+
+	c := usecase.NewCustomerUsecase(mockRepo, new(mocks.Notifier), signer, audittest.NewUsecase(), time.Second*2)
+	err := c.RequestPasswordReset(context.TODO(), "john@example.com")
+
+	assert.NoError(t, err)
+	mockRepo.AssertNotCalled(t, "CreateToken", mock.Anything, mock.Anything)
+}
+
+func TestResetPassword(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockRepo.On("UseToken", mock.Anything, mock.AnythingOfType("string"), models.TokenResetPassword, mock.AnythingOfType("time.Time")).
+		Return(&models.CustomerToken{ID: 2, CustomerID: 4}, nil).Once()
+	mockRepo.On("UpdatePassword", mock.Anything, int64(4), mock.MatchedBy(func(hash string) bool {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte("battery staple")) == nil
+	})).Return(nil).Once()
+	mockRepo.On("RevokeTokens", mock.Anything, int64(4), models.TokenRefresh, mock.AnythingOfType("time.Time")).Return(nil).Once()
+	mockRepo.On("MarkVerified", mock.Anything, int64(4)).Return(nil).Once()
+
+	c := usecase.NewCustomerUsecase(mockRepo, new(mocks.Notifier), signer, audittest.NewUsecase(), time.Second*2)
+	err := c.ResetPassword(context.TODO(), "token", "battery staple")
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAssignRole(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockRepo.On("GetByID", mock.Anything, int64(4)).Return(&models.Customer{ID: 4}, nil).Once()
+		mockRepo.On("GetRoles", mock.Anything, int64(4)).Return([]string{}, nil).Once()
+		mockRepo.On("AddRole", mock.Anything, int64(4), auth.RoleOrderManager).Return(nil).Once()
+		mockRepo.On("GetRoles", mock.Anything, int64(4)).Return([]string{auth.RoleOrderManager}, nil).Once()
+
+		mockAudit := audittest.NewUsecase()
+		c := usecase.NewCustomerUsecase(mockRepo, new(mocks.Notifier), signer, mockAudit, time.Second*2)
+		err := c.AssignRole(context.TODO(), int64(4), auth.RoleOrderManager)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		audittest.AssertRecorded(t, mockAudit, models.AuditCustomer, int64(4), models.AuditUpdate,
+			map[string][]string{"roles": {}}, map[string][]string{"roles": {auth.RoleOrderManager}})
+	})
+
+	t.Run("invalid role", func(t *testing.T) {
+		mockAudit := audittest.NewUsecase()
+		c := usecase.NewCustomerUsecase(new(mocks.Repository), new(mocks.Notifier), signer, mockAudit, time.Second*2)
+
+		assert.Equal(t, models.ErrBadParamInput, c.AssignRole(context.TODO(), int64(4), "superuser"))
+		assert.Equal(t, models.ErrBadParamInput, c.AssignRole(context.TODO(), int64(4), auth.RoleCustomer))
+		audittest.AssertNothingRecorded(t, mockAudit)
+	})
+}
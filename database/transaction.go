@@ -0,0 +1,91 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+)
+
+type txKey struct{}
+
+// Executor is the part of *sql.DB and *sql.Tx used by repositories
+type Executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Transactor run work of several repositories in one transaction
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type sqlTransactor struct {
+	Conn *sql.DB
+}
+
+// NewTransactor create Transactor on top of conn
+func NewTransactor(conn *sql.DB) Transactor {
+	return &sqlTransactor{conn}
+}
+
+// WithinTransaction call fn with a ctx carrying the transaction, it is
+// committed when fn succeed and rolled back otherwise. Nested calls join the
+// transaction already carried by ctx.
+func (t *sqlTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := TxFrom(ctx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := t.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err = fn(WithTx(ctx, tx)); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// WithTx return copy of ctx carrying tx
+func WithTx(ctx context.Context, tx *sql.Tx) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// TxFrom return the transaction carried by ctx
+func TxFrom(ctx context.Context) (*sql.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(*sql.Tx)
+	return tx, ok
+}
+
+// ExecutorFrom return the transaction carried by ctx, conn when there is none
+func ExecutorFrom(ctx context.Context, conn *sql.DB) Executor {
+	if tx, ok := TxFrom(ctx); ok {
+		return tx
+	}
+
+	return conn
+}
+
+// RunInTx call fn inside the transaction carried by ctx, or inside a new one
+// committed when fn succeed
+func RunInTx(ctx context.Context, conn *sql.DB, fn func(tx *sql.Tx) error) error {
+	if tx, ok := TxFrom(ctx); ok {
+		return fn(tx)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err = fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,13 @@
+package event
+
+import (
+	"context"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// Repository represent the outbox repository contract, events are written in
+// the transaction carried by ctx
+type Repository interface {
+	Create(ctx context.Context, event *models.Event) error
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/soerjadi/exam/database"
+	"github.com/soerjadi/exam/event"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/utils"
+)
+
+type pgEventRepository struct {
+	Conn *sql.DB
+}
+
+var logger = utils.LogBuilder(true)
+
+// NewPGEventRepository is bridge to create an object from event.Repository interface
+func NewPGEventRepository(Conn *sql.DB) event.Repository {
+	return &pgEventRepository{Conn}
+}
+
+// Create append event to the outbox
+func (p *pgEventRepository) Create(ctx context.Context, event *models.Event) error {
+	query := `INSERT INTO outbox_events(type, aggregate_id, payload) VALUES(?, ?, ?) returning id`
+
+	stmt, err := database.ExecutorFrom(ctx, p.Conn).PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	result, err := stmt.ExecContext(ctx, event.Type, event.AggregateID, []byte(event.Payload))
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	lastID, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	event.ID = lastID
+	return nil
+}
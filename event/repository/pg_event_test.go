@@ -0,0 +1,35 @@
+package repository_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/soerjadi/exam/event/repository"
+	"github.com/soerjadi/exam/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	event := &models.Event{
+		Type:        models.EventProductCreated,
+		AggregateID: 4,
+		Payload:     json.RawMessage(`{"id":4}`),
+	}
+
+	query := "INSERT INTO outbox_events\\(type, aggregate_id, payload\\) VALUES\\(\\?, \\?, \\?\\) returning id"
+	prep := mock.ExpectPrepare(query)
+	prep.ExpectExec().WithArgs(event.Type, event.AggregateID, []byte(event.Payload)).WillReturnResult(sqlmock.NewResult(7, 1))
+
+	r := repository.NewPGEventRepository(db)
+	err = r.Create(context.TODO(), event)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), event.ID)
+}
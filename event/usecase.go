@@ -0,0 +1,12 @@
+package event
+
+import (
+	"context"
+)
+
+// Usecase represent the domain events usecase. Emit is called inside the
+// transaction of the change so the event is only published when the change is
+// committed, its error has to fail that transaction.
+type Usecase interface {
+	Emit(ctx context.Context, eventType string, aggregateID int64, payload interface{}) error
+}
@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/soerjadi/exam/event"
+	"github.com/soerjadi/exam/models"
+)
+
+type eventUsecase struct {
+	repo    event.Repository
+	timeout time.Duration
+}
+
+// NewEventUsecase will create object that represent of event.Usecase interface
+func NewEventUsecase(r event.Repository, timeout time.Duration) event.Usecase {
+	return &eventUsecase{
+		repo:    r,
+		timeout: timeout,
+	}
+}
+
+// Emit write an event about aggregateID to the outbox, payload is encoded as
+// JSON
+func (u *eventUsecase) Emit(ctx context.Context, eventType string, aggregateID int64, payload interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	return u.repo.Create(ctx, &models.Event{
+		Type:        eventType,
+		AggregateID: aggregateID,
+		Payload:     data,
+	})
+}
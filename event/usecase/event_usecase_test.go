@@ -0,0 +1,37 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/soerjadi/exam/event/mocks"
+	"github.com/soerjadi/exam/event/usecase"
+	"github.com/soerjadi/exam/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestEmit(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(e *models.Event) bool {
+			return e.Type == models.EventOrderStatusChanged && e.AggregateID == 3 &&
+				string(e.Payload) == `{"order_id":3,"from":1,"to":2,"version":4}`
+		})).Return(nil).Once()
+
+		u := usecase.NewEventUsecase(mockRepo, time.Second*2)
+		err := u.Emit(context.TODO(), models.EventOrderStatusChanged, 3, models.OrderStatusChange{OrderID: 3, From: 1, To: 2, Version: 4})
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("payload not encodable", func(t *testing.T) {
+		u := usecase.NewEventUsecase(new(mocks.Repository), time.Second*2)
+		err := u.Emit(context.TODO(), models.EventProductUpdated, 1, make(chan int))
+
+		assert.Error(t, err)
+	})
+}
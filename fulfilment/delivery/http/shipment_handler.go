@@ -0,0 +1,271 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/soerjadi/exam/fulfilment"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/utils"
+)
+
+type shipmentItemData struct {
+	OrderItemID int64 `json:"order_item_id"`
+	Amount      int64 `json:"amount"`
+}
+
+type newShipmentData struct {
+	OrderID        int64               `json:"order_id"`
+	Carrier        string              `json:"carrier"`
+	TrackingNumber string              `json:"tracking_number"`
+	Items          []*shipmentItemData `json:"items"`
+}
+
+type shipData struct {
+	Carrier        string `json:"carrier"`
+	TrackingNumber string `json:"tracking_number"`
+}
+
+var logger = utils.LogBuilder(true)
+
+// ShipmentHandler represent the http handler for shipments
+type ShipmentHandler struct {
+	FulfilmentUsecase fulfilment.Usecase
+}
+
+// NewShipmentHandler initialize shipment resource endpoint
+func NewShipmentHandler(router *mux.Router, usecase fulfilment.Usecase) *mux.Router {
+	handler := &ShipmentHandler{
+		FulfilmentUsecase: usecase,
+	}
+
+	router.HandleFunc("/v1/order/{id:[0-9]+}/shipment", handler.GetByOrder).Methods("GET")
+
+	s := router.PathPrefix("/v1/admin/shipment").Subrouter()
+	s.HandleFunc("/add", handler.Create).Methods("POST")
+	s.HandleFunc("/detail", handler.Detail).Methods("GET")
+	s.HandleFunc("/{id:[0-9]+}/ship", handler.Ship).Methods("POST")
+	s.HandleFunc("/{id:[0-9]+}/deliver", handler.Deliver).Methods("POST")
+	s.HandleFunc("/{id:[0-9]+}/cancel", handler.Cancel).Methods("POST")
+	s.HandleFunc("/{id:[0-9]+}/packing_slip", handler.PackingSlip).Methods("GET")
+
+	return s
+}
+
+func decode(r *http.Request, v interface{}) error {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, v)
+}
+
+func pathID(r *http.Request) (int64, error) {
+	return strconv.ParseInt(mux.Vars(r)["id"], 0, 64)
+}
+
+// errorStatus map errors of the fulfilment usecase to http status
+func errorStatus(err error) int32 {
+	switch err {
+	case models.ErrUnauthorized:
+		return http.StatusUnauthorized
+	case models.ErrNotFound:
+		return http.StatusNotFound
+	case models.ErrConflict:
+		return http.StatusConflict
+	}
+
+	return http.StatusBadRequest
+}
+
+// GetByOrder endpoint to list the shipments of an order with their tracking
+func (h *ShipmentHandler) GetByOrder(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	list, err := h.FulfilmentUsecase.GetByOrder(ctx, id)
+
+	if err != nil {
+		utils.Error(w, errorStatus(err), err.Error())
+		return
+	}
+
+	entriesResult := &utils.EntriesResponse{
+		Data:  list,
+		Found: int64(len(list)),
+	}
+
+	utils.JSON(w, http.StatusOK, entriesResult)
+}
+
+// Create endpoint to pack lines of an order in a new shipment
+func (h *ShipmentHandler) Create(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var data newShipmentData
+	if err := decode(r, &data); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	shipment := &models.Shipment{
+		OrderID:        data.OrderID,
+		Carrier:        data.Carrier,
+		TrackingNumber: data.TrackingNumber,
+		Items:          make([]*models.ShipmentItem, 0, len(data.Items)),
+	}
+
+	for _, item := range data.Items {
+		shipment.Items = append(shipment.Items, &models.ShipmentItem{
+			OrderItemID: item.OrderItemID,
+			Amount:      item.Amount,
+		})
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	err := h.FulfilmentUsecase.Create(ctx, shipment)
+
+	if err != nil {
+		utils.Error(w, errorStatus(err), err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, shipment)
+}
+
+// Detail endpoint to get a shipment with its items
+func (h *ShipmentHandler) Detail(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 0, 64)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	shipment, err := h.FulfilmentUsecase.GetByID(ctx, id)
+
+	if err != nil {
+		utils.Error(w, errorStatus(err), err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, shipment)
+}
+
+// Ship endpoint to hand a shipment to the carrier with its tracking number
+func (h *ShipmentHandler) Ship(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := pathID(r)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var data shipData
+	if err := decode(r, &data); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	shipment, err := h.FulfilmentUsecase.Ship(ctx, id, data.Carrier, data.TrackingNumber)
+
+	if err != nil {
+		utils.Error(w, errorStatus(err), err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, shipment)
+}
+
+// Deliver endpoint to mark a shipment delivered
+func (h *ShipmentHandler) Deliver(w http.ResponseWriter, r *http.Request) {
+	h.change(w, r, h.FulfilmentUsecase.Deliver)
+}
+
+// Cancel endpoint to drop a shipment not shipped yet
+func (h *ShipmentHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	h.change(w, r, h.FulfilmentUsecase.Cancel)
+}
+
+// change run fn on the shipment of the path
+func (h *ShipmentHandler) change(w http.ResponseWriter, r *http.Request, fn func(context.Context, int64) (*models.Shipment, error)) {
+	id, err := pathID(r)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	shipment, err := fn(ctx, id)
+
+	if err != nil {
+		utils.Error(w, errorStatus(err), err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, shipment)
+}
+
+// PackingSlip endpoint to get the packing slip of a shipment, as plain text
+// ready to be printed when format=text is asked
+func (h *ShipmentHandler) PackingSlip(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	slip, err := h.FulfilmentUsecase.PackingSlip(ctx, id)
+
+	if err != nil {
+		utils.Error(w, errorStatus(err), err.Error())
+		return
+	}
+
+	if r.URL.Query().Get("format") != "text" {
+		utils.JSON(w, http.StatusOK, slip)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if err = fulfilment.RenderSlip(w, slip); err != nil {
+		logger.Error(err)
+	}
+}
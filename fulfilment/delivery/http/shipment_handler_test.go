@@ -0,0 +1,62 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	shipmentHttp "github.com/soerjadi/exam/fulfilment/delivery/http"
+	"github.com/soerjadi/exam/fulfilment/mocks"
+	"github.com/soerjadi/exam/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestShip(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("Ship", mock.Anything, int64(1), "JNE", "JN0001").
+		Return(&models.Shipment{ID: 1, OrderID: 8, Status: models.ShipmentShipped}, nil)
+
+	req, err := http.NewRequest("POST", "/v1/admin/shipment/1/ship", strings.NewReader(`{"carrier":"JNE","tracking_number":"JN0001"}`))
+	assert.NoError(t, err)
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+
+	handler := shipmentHttp.ShipmentHandler{
+		FulfilmentUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.Ship(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestPackingSlipText(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("PackingSlip", mock.Anything, int64(1)).Return(&models.PackingSlip{
+		OrderID:    8,
+		ShipmentID: 1,
+		ShipTo:     &models.OrderShipping{Name: "Budi", Line1: "Jl. Merdeka 1", City: "Jakarta", PostalCode: "10110", Country: "ID"},
+		Lines:      []*models.PackingSlipLine{&models.PackingSlipLine{ProductID: 2, SKU: "KMJ-01", Name: "Kemeja", Amount: 2}},
+	}, nil)
+
+	req, err := http.NewRequest("GET", "/v1/admin/shipment/1/packing_slip?format=text", nil)
+	assert.NoError(t, err)
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+
+	handler := shipmentHttp.ShipmentHandler{
+		FulfilmentUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.PackingSlip(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain")
+	assert.Contains(t, rec.Body.String(), "Order #8 - Shipment #1")
+	assert.Contains(t, rec.Body.String(), "KMJ-01")
+	assert.Contains(t, rec.Body.String(), "10110 Jakarta")
+}
@@ -0,0 +1,17 @@
+package fulfilment
+
+import (
+	"context"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// Repository represent the shipment repository contract
+type Repository interface {
+	GetByID(ctx context.Context, id int64) (*models.Shipment, error)
+	GetByOrder(ctx context.Context, orderID int64) ([]*models.Shipment, error)
+	GetItems(ctx context.Context, shipmentID int64) ([]*models.ShipmentItem, error)
+	Create(ctx context.Context, shipment *models.Shipment) error
+	Update(ctx context.Context, shipment *models.Shipment) error
+	LockItems(ctx context.Context, orderID int64) error
+}
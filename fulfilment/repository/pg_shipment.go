@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/soerjadi/exam/database"
+	"github.com/soerjadi/exam/fulfilment"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/utils"
+)
+
+type pgShipmentRepository struct {
+	Conn *sql.DB
+}
+
+var logger = utils.LogBuilder(true)
+
+// NewPGShipmentRepository is bridge to create an object from fulfilment.Repository interface
+func NewPGShipmentRepository(Conn *sql.DB) fulfilment.Repository {
+	return &pgShipmentRepository{Conn}
+}
+
+func (p *pgShipmentRepository) fetch(ctx context.Context, query string, args ...interface{}) ([]*models.Shipment, error) {
+	rows, err := database.ExecutorFrom(ctx, p.Conn).QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Error(err)
+		}
+	}()
+
+	result := make([]*models.Shipment, 0)
+	for rows.Next() {
+		t := new(models.Shipment)
+
+		err = rows.Scan(
+			&t.ID,
+			&t.OrderID,
+			&t.Carrier,
+			&t.TrackingNumber,
+			&t.Status,
+			&t.Shipped,
+			&t.Delivered,
+			&t.Created,
+			&t.Updated,
+			&t.Version,
+		)
+
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+
+		result = append(result, t)
+	}
+
+	return result, nil
+}
+
+func (p *pgShipmentRepository) GetByID(ctx context.Context, id int64) (*models.Shipment, error) {
+	query := `SELECT id, order_id, carrier, tracking_number, status, shipped, delivered, created, updated, version
+		FROM shipments WHERE id = ?`
+
+	list, err := p.fetch(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list) == 0 {
+		return nil, models.ErrNotFound
+	}
+
+	return list[0], nil
+}
+
+func (p *pgShipmentRepository) GetByOrder(ctx context.Context, orderID int64) ([]*models.Shipment, error) {
+	query := `SELECT id, order_id, carrier, tracking_number, status, shipped, delivered, created, updated, version
+		FROM shipments WHERE order_id = ? ORDER BY id`
+
+	return p.fetch(ctx, query, orderID)
+}
+
+func (p *pgShipmentRepository) GetItems(ctx context.Context, shipmentID int64) ([]*models.ShipmentItem, error) {
+	query := `SELECT id, shipment_id, order_item_id, product_id, amount FROM shipment_item WHERE shipment_id = ? ORDER BY id`
+
+	rows, err := database.ExecutorFrom(ctx, p.Conn).QueryContext(ctx, query, shipmentID)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Error(err)
+		}
+	}()
+
+	result := make([]*models.ShipmentItem, 0)
+	for rows.Next() {
+		t := new(models.ShipmentItem)
+
+		err = rows.Scan(&t.ID, &t.ShipmentID, &t.OrderItemID, &t.ProductID, &t.Amount)
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+
+		result = append(result, t)
+	}
+
+	return result, nil
+}
+
+// Create store the shipment with its items
+func (p *pgShipmentRepository) Create(ctx context.Context, shipment *models.Shipment) error {
+	var lastID int64
+
+	err := database.RunInTx(ctx, p.Conn, func(tx *sql.Tx) error {
+		query := `INSERT INTO shipments(order_id, carrier, tracking_number, status) VALUES(?, ?, ?, ?) returning id`
+
+		stmt, err := tx.PrepareContext(ctx, query)
+		if err != nil {
+			return err
+		}
+
+		result, err := stmt.ExecContext(ctx, shipment.OrderID, shipment.Carrier, shipment.TrackingNumber, shipment.Status)
+		if err != nil {
+			return err
+		}
+
+		lastID, err = result.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		itemStmt, err := tx.PrepareContext(ctx, `INSERT INTO shipment_item(shipment_id, order_item_id, product_id, amount) VALUES(?, ?, ?, ?)`)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range shipment.Items {
+			if _, err = itemStmt.ExecContext(ctx, lastID, item.OrderItemID, item.ProductID, item.Amount); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	shipment.ID = lastID
+	shipment.Version = 1
+	for _, item := range shipment.Items {
+		item.ShipmentID = lastID
+	}
+
+	return nil
+}
+
+// LockItems lock the lines of an order until the transaction carried by ctx
+// end, so the pieces its shipments pack are counted one shipment at a time
+func (p *pgShipmentRepository) LockItems(ctx context.Context, orderID int64) error {
+	query := `SELECT id FROM order_item WHERE order_id = ? FOR UPDATE`
+
+	rows, err := database.ExecutorFrom(ctx, p.Conn).QueryContext(ctx, query, orderID)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Error(err)
+		}
+	}()
+
+	for rows.Next() {
+	}
+
+	return rows.Err()
+}
+
+// Update store the state of the shipment if it did not change since it was
+// read, models.ErrConflict is returned otherwise
+func (p *pgShipmentRepository) Update(ctx context.Context, shipment *models.Shipment) error {
+	query := `UPDATE shipments SET carrier = ?, tracking_number = ?, status = ?, shipped = ?, delivered = ?, updated = ?,
+		version = version + 1 WHERE id = ? AND version = ?`
+
+	stmt, err := database.ExecutorFrom(ctx, p.Conn).PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	res, err := stmt.ExecContext(ctx, shipment.Carrier, shipment.TrackingNumber, shipment.Status, shipment.Shipped,
+		shipment.Delivered, shipment.Updated, shipment.ID, shipment.Version)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected != 1 {
+		return models.ErrConflict
+	}
+
+	shipment.Version++
+	return nil
+}
@@ -0,0 +1,103 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/soerjadi/exam/database"
+	"github.com/soerjadi/exam/fulfilment/repository"
+	"github.com/soerjadi/exam/models"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v3"
+)
+
+var columns = []string{"id", "order_id", "carrier", "tracking_number", "status", "shipped", "delivered", "created", "updated", "version"}
+
+func TestGetByOrder(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows(columns).
+		AddRow(1, 8, "JNE", "JN0001", models.ShipmentShipped, time.Now(), nil, time.Now(), time.Now(), 2).
+		AddRow(2, 8, "", "", models.ShipmentPending, nil, nil, time.Now(), nil, 1)
+
+	mock.ExpectQuery("SELECT (.+) FROM shipments WHERE order_id = \\? ORDER BY id").WithArgs(int64(8)).WillReturnRows(rows)
+
+	r := repository.NewPGShipmentRepository(db)
+	list, err := r.GetByOrder(context.TODO(), int64(8))
+
+	assert.NoError(t, err)
+	assert.Len(t, list, 2)
+	assert.Equal(t, "JN0001", list[0].TrackingNumber)
+	assert.False(t, list[1].Shipped.Valid)
+}
+
+func TestCreate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	shipment := &models.Shipment{OrderID: 8, Status: models.ShipmentPending,
+		Items: []*models.ShipmentItem{&models.ShipmentItem{OrderItemID: 11, ProductID: 2, Amount: 1}}}
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare("INSERT INTO shipments\\(order_id, carrier, tracking_number, status\\)").ExpectExec().
+		WithArgs(int64(8), "", "", models.ShipmentPending).
+		WillReturnResult(sqlmock.NewResult(4, 1))
+	mock.ExpectPrepare("INSERT INTO shipment_item").ExpectExec().
+		WithArgs(int64(4), int64(11), int64(2), int64(1)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	r := repository.NewPGShipmentRepository(db)
+	err = r.Create(context.TODO(), shipment)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), shipment.ID)
+	assert.Equal(t, int64(4), shipment.Items[0].ShipmentID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	shipment := &models.Shipment{ID: 4, Carrier: "JNE", TrackingNumber: "JN0001", Status: models.ShipmentShipped,
+		Shipped: null.TimeFrom(time.Now()), Version: 1}
+
+	mock.ExpectPrepare("UPDATE shipments SET (.+) WHERE id = \\? AND version = \\?").ExpectExec().
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	r := repository.NewPGShipmentRepository(db)
+	err = r.Update(context.TODO(), shipment)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), shipment.Version)
+}
+
+func TestLockItems(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM order_item WHERE order_id = \\? FOR UPDATE").WithArgs(int64(8)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11).AddRow(12))
+	mock.ExpectCommit()
+
+	r := repository.NewPGShipmentRepository(db)
+	err = database.NewTransactor(db).WithinTransaction(context.TODO(), func(ctx context.Context) error {
+		return r.LockItems(ctx, int64(8))
+	})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,60 @@
+package fulfilment
+
+import (
+	"io"
+	"text/template"
+
+	"github.com/soerjadi/exam/models"
+)
+
+var slipTemplate = template.Must(template.New("slip").Parse(`PACKING SLIP
+Order #{{.OrderID}} - Shipment #{{.ShipmentID}}
+{{- if .Carrier}}
+Carrier: {{.Carrier}}{{if .TrackingNumber}} ({{.TrackingNumber}}){{end}}
+{{- end}}
+{{with .ShipTo}}
+Ship to:
+  {{.Name}}
+  {{.Line1}}
+{{- if .Line2}}
+  {{.Line2}}
+{{- end}}
+  {{.PostalCode}} {{.City}}{{if .Region}}, {{.Region}}{{end}}
+  {{.Country}}
+{{- if .Phone}}
+  {{.Phone}}
+{{- end}}
+{{end}}
+QTY   SKU            PRODUCT
+{{range .Lines}}{{printf "%-5d %-14s %s" .Amount .SKU .Name}}
+{{end}}`))
+
+// Slip list the lines of shipment with the name and SKU of their product,
+// products missing from the catalog are listed by id only
+func Slip(order *models.Order, shipment *models.Shipment, products map[int64]*models.Product) *models.PackingSlip {
+	slip := &models.PackingSlip{
+		OrderID:        order.ID,
+		ShipmentID:     shipment.ID,
+		Carrier:        shipment.Carrier,
+		TrackingNumber: shipment.TrackingNumber,
+		ShipTo:         order.Shipping,
+		Lines:          make([]*models.PackingSlipLine, 0, len(shipment.Items)),
+	}
+
+	for _, item := range shipment.Items {
+		line := &models.PackingSlipLine{ProductID: item.ProductID, Amount: item.Amount}
+		if product, ok := products[item.ProductID]; ok {
+			line.SKU = product.SKU
+			line.Name = product.Name
+		}
+
+		slip.Lines = append(slip.Lines, line)
+	}
+
+	return slip
+}
+
+// RenderSlip write slip as plain text, ready to be printed
+func RenderSlip(w io.Writer, slip *models.PackingSlip) error {
+	return slipTemplate.Execute(w, slip)
+}
@@ -0,0 +1,18 @@
+package fulfilment
+
+import (
+	"context"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// Usecase represent the fulfilment usecase
+type Usecase interface {
+	GetByID(ctx context.Context, id int64) (*models.Shipment, error)
+	GetByOrder(ctx context.Context, orderID int64) ([]*models.Shipment, error)
+	Create(ctx context.Context, shipment *models.Shipment) error
+	Ship(ctx context.Context, id int64, carrier string, trackingNumber string) (*models.Shipment, error)
+	Deliver(ctx context.Context, id int64) (*models.Shipment, error)
+	Cancel(ctx context.Context, id int64) (*models.Shipment, error)
+	PackingSlip(ctx context.Context, id int64) (*models.PackingSlip, error)
+}
@@ -0,0 +1,358 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/soerjadi/exam/audit"
+	"github.com/soerjadi/exam/auth"
+	"github.com/soerjadi/exam/database"
+	"github.com/soerjadi/exam/fulfilment"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/order"
+	"github.com/soerjadi/exam/product"
+	"github.com/soerjadi/exam/utils"
+	"gopkg.in/guregu/null.v3"
+)
+
+type fulfilmentUsecase struct {
+	repo           fulfilment.Repository
+	orders         order.Usecase
+	products       product.Usecase
+	tx             database.Transactor
+	audit          audit.Usecase
+	contextTimeout time.Duration
+}
+
+var logger = utils.LogBuilder(true)
+
+// NewFulfilmentUsecase will create object that represent of fulfilment.Usecase
+// interface
+func NewFulfilmentUsecase(r fulfilment.Repository, o order.Usecase, p product.Usecase, tx database.Transactor, a audit.Usecase, timeout time.Duration) fulfilment.Usecase {
+	return &fulfilmentUsecase{
+		repo:           r,
+		orders:         o,
+		products:       p,
+		tx:             tx,
+		audit:          a,
+		contextTimeout: timeout,
+	}
+}
+
+func (f *fulfilmentUsecase) record(ctx context.Context, id int64, action string, before interface{}, after interface{}) {
+	if err := f.audit.Record(ctx, models.AuditShipment, id, action, before, after); err != nil {
+		logger.Error(err)
+	}
+}
+
+// owned return the order of id when it is visible to the caller, orders of
+// a customer are only visible to that customer
+func (f *fulfilmentUsecase) owned(ctx context.Context, id int64) (*models.Order, error) {
+	ord, err := f.orders.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if principal, ok := auth.PrincipalFrom(ctx); ok && principal.Allowed(auth.OrderRead) {
+		return ord, nil
+	}
+
+	customerID, ok := auth.CustomerID(ctx)
+	if !ok {
+		return nil, models.ErrUnauthorized
+	}
+
+	if !ord.CustomerID.Valid || ord.CustomerID.Int64 != customerID {
+		return nil, models.ErrNotFound
+	}
+
+	return ord, nil
+}
+
+// packed count the pieces of every line of an order in its shipments of one
+// of statuses
+func (f *fulfilmentUsecase) packed(shipments []*models.Shipment, statuses ...string) map[int64]int64 {
+	result := make(map[int64]int64)
+	for _, shipment := range shipments {
+		for _, status := range statuses {
+			if shipment.Status != status {
+				continue
+			}
+
+			for _, item := range shipment.Items {
+				result[item.OrderItemID] += item.Amount
+			}
+		}
+	}
+
+	return result
+}
+
+// shipments list the shipments of an order with their items
+func (f *fulfilmentUsecase) shipments(ctx context.Context, orderID int64) ([]*models.Shipment, error) {
+	list, err := f.repo.GetByOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, shipment := range list {
+		shipment.Items, err = f.repo.GetItems(ctx, shipment.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return list, nil
+}
+
+// sync bring the status of an order being fulfilled in line with its
+// shipments: completed once every piece is delivered, shipped once every
+// piece left and partially shipped while only some did
+func (f *fulfilmentUsecase) sync(ctx context.Context, orderID int64) error {
+	ord, err := f.orders.GetByID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+
+	if ord.Status != models.OrderProccessed && ord.Status != models.OrderPartiallyShipped && ord.Status != models.OrderShipped {
+		return nil
+	}
+
+	list, err := f.shipments(ctx, orderID)
+	if err != nil {
+		return err
+	}
+
+	shipped := f.packed(list, models.ShipmentShipped, models.ShipmentDelivered)
+	delivered := f.packed(list, models.ShipmentDelivered)
+
+	allShipped, allDelivered, anyShipped := true, true, false
+	for _, item := range ord.Items {
+		allShipped = allShipped && shipped[item.ID] >= item.Amount
+		allDelivered = allDelivered && delivered[item.ID] >= item.Amount
+		anyShipped = anyShipped || shipped[item.ID] > 0
+	}
+
+	status := models.OrderProccessed
+	switch {
+	case allDelivered:
+		status = models.OrderCompleted
+	case allShipped:
+		status = models.OrderShipped
+	case anyShipped:
+		status = models.OrderPartiallyShipped
+	}
+
+	if status == ord.Status {
+		return nil
+	}
+
+	return f.orders.Update(ctx, &models.Order{ID: ord.ID, Status: status, Version: ord.Version})
+}
+
+func (f *fulfilmentUsecase) GetByID(ctx context.Context, id int64) (*models.Shipment, error) {
+	ctx, cancel := context.WithTimeout(ctx, f.contextTimeout)
+	defer cancel()
+
+	shipment, err := f.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	shipment.Items, err = f.repo.GetItems(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return shipment, nil
+}
+
+// GetByOrder list the shipments of an order visible to the caller
+func (f *fulfilmentUsecase) GetByOrder(ctx context.Context, orderID int64) ([]*models.Shipment, error) {
+	ctx, cancel := context.WithTimeout(ctx, f.contextTimeout)
+	defer cancel()
+
+	if _, err := f.owned(ctx, orderID); err != nil {
+		return nil, err
+	}
+
+	return f.shipments(ctx, orderID)
+}
+
+// Create pack lines of a paid order in a new shipment. Pieces already packed
+// in shipments not cancelled can not be packed again.
+func (f *fulfilmentUsecase) Create(ctx context.Context, shipment *models.Shipment) error {
+	ctx, cancel := context.WithTimeout(ctx, f.contextTimeout)
+	defer cancel()
+
+	if len(shipment.Items) == 0 {
+		return models.ErrBadParamInput
+	}
+
+	err := f.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		// shipments for the same order wait for each other, what they count
+		// as packed stay so until this one is stored
+		if err := f.repo.LockItems(ctx, shipment.OrderID); err != nil {
+			return err
+		}
+
+		ord, err := f.orders.GetByID(ctx, shipment.OrderID)
+		if err != nil {
+			return err
+		}
+
+		if ord.Status != models.OrderProccessed && ord.Status != models.OrderPartiallyShipped {
+			return models.ErrBadParamInput
+		}
+
+		list, err := f.shipments(ctx, ord.ID)
+		if err != nil {
+			return err
+		}
+
+		packed := f.packed(list, models.ShipmentPending, models.ShipmentShipped, models.ShipmentDelivered)
+
+		ordered := make(map[int64]*models.OrderItem, len(ord.Items))
+		for _, item := range ord.Items {
+			ordered[item.ID] = item
+		}
+
+		for _, item := range shipment.Items {
+			line, ok := ordered[item.OrderItemID]
+			if !ok || item.Amount <= 0 || item.Amount > line.Amount-packed[line.ID] {
+				return models.ErrBadParamInput
+			}
+
+			packed[line.ID] += item.Amount
+			item.ProductID = line.ProductID
+		}
+
+		shipment.Carrier = strings.TrimSpace(shipment.Carrier)
+		shipment.TrackingNumber = strings.TrimSpace(shipment.TrackingNumber)
+		shipment.Status = models.ShipmentPending
+		shipment.Created = time.Now()
+
+		return f.repo.Create(ctx, shipment)
+	})
+	if err != nil {
+		return err
+	}
+
+	f.record(ctx, shipment.ID, models.AuditCreate, nil, shipment)
+	return nil
+}
+
+// change move the shipment of id from status from, fn apply the change then
+// the order is brought in line
+func (f *fulfilmentUsecase) change(ctx context.Context, id int64, from string, fn func(shipment *models.Shipment)) (*models.Shipment, error) {
+	shipment, err := f.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if shipment.Status != from {
+		return nil, models.ErrBadParamInput
+	}
+
+	before := *shipment
+
+	err = f.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		fn(shipment)
+		shipment.Updated = null.NewTime(
+			time.Now(), true,
+		)
+
+		if err := f.repo.Update(ctx, shipment); err != nil {
+			return err
+		}
+
+		return f.sync(ctx, shipment.OrderID)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	f.record(ctx, shipment.ID, models.AuditUpdate, &before, shipment)
+	return shipment, nil
+}
+
+// Ship hand a pending shipment to carrier, it is then tracked with
+// trackingNumber
+func (f *fulfilmentUsecase) Ship(ctx context.Context, id int64, carrier string, trackingNumber string) (*models.Shipment, error) {
+	ctx, cancel := context.WithTimeout(ctx, f.contextTimeout)
+	defer cancel()
+
+	carrier = strings.TrimSpace(carrier)
+	trackingNumber = strings.TrimSpace(trackingNumber)
+	if carrier == "" || trackingNumber == "" {
+		return nil, models.ErrBadParamInput
+	}
+
+	return f.change(ctx, id, models.ShipmentPending, func(shipment *models.Shipment) {
+		shipment.Carrier = carrier
+		shipment.TrackingNumber = trackingNumber
+		shipment.Status = models.ShipmentShipped
+		shipment.Shipped = null.NewTime(time.Now(), true)
+	})
+}
+
+// Deliver mark a shipped shipment delivered
+func (f *fulfilmentUsecase) Deliver(ctx context.Context, id int64) (*models.Shipment, error) {
+	ctx, cancel := context.WithTimeout(ctx, f.contextTimeout)
+	defer cancel()
+
+	return f.change(ctx, id, models.ShipmentShipped, func(shipment *models.Shipment) {
+		shipment.Status = models.ShipmentDelivered
+		shipment.Delivered = null.NewTime(time.Now(), true)
+	})
+}
+
+// Cancel drop a shipment not shipped yet, its lines can be packed again
+func (f *fulfilmentUsecase) Cancel(ctx context.Context, id int64) (*models.Shipment, error) {
+	ctx, cancel := context.WithTimeout(ctx, f.contextTimeout)
+	defer cancel()
+
+	return f.change(ctx, id, models.ShipmentPending, func(shipment *models.Shipment) {
+		shipment.Status = models.ShipmentCancelled
+	})
+}
+
+// PackingSlip list what goes in the shipment of id and where it goes
+func (f *fulfilmentUsecase) PackingSlip(ctx context.Context, id int64) (*models.PackingSlip, error) {
+	ctx, cancel := context.WithTimeout(ctx, f.contextTimeout)
+	defer cancel()
+
+	shipment, err := f.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	shipment.Items, err = f.repo.GetItems(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	ord, err := f.orders.GetByID(ctx, shipment.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	products := make(map[int64]*models.Product, len(shipment.Items))
+	for _, item := range shipment.Items {
+		// products trashed since are still named on the slip
+		product, err := f.products.GetAnyByID(ctx, item.ProductID)
+		if err == models.ErrNotFound {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		products[item.ProductID] = product
+	}
+
+	return fulfilment.Slip(ord, shipment, products), nil
+}
@@ -0,0 +1,195 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/soerjadi/exam/audit/audittest"
+	auditMocks "github.com/soerjadi/exam/audit/mocks"
+	"github.com/soerjadi/exam/fulfilment"
+	"github.com/soerjadi/exam/fulfilment/mocks"
+	"github.com/soerjadi/exam/fulfilment/usecase"
+	"github.com/soerjadi/exam/models"
+	orderMocks "github.com/soerjadi/exam/order/mocks"
+	pMocks "github.com/soerjadi/exam/product/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gopkg.in/guregu/null.v3"
+)
+
+// inlineTx run the work straight away, the repositories are mocked
+type inlineTx struct{}
+
+func (inlineTx) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+type deps struct {
+	repo     *mocks.Repository
+	orders   *orderMocks.Usecase
+	products *pMocks.Usecase
+	audit    *auditMocks.Usecase
+}
+
+func newDeps() *deps {
+	return &deps{
+		repo:     new(mocks.Repository),
+		orders:   new(orderMocks.Usecase),
+		products: new(pMocks.Usecase),
+		audit:    audittest.NewUsecase(),
+	}
+}
+
+func (d *deps) usecase() fulfilment.Usecase {
+	return usecase.NewFulfilmentUsecase(d.repo, d.orders, d.products, inlineTx{}, d.audit, time.Second*2)
+}
+
+// paidOrder is two pieces of product 2 and one of product 3
+func paidOrder(status int) *models.Order {
+	return &models.Order{
+		ID:         8,
+		CustomerID: null.IntFrom(4),
+		Status:     status,
+		Version:    2,
+		Items: []*models.OrderItem{
+			&models.OrderItem{ID: 11, OrderID: 8, ProductID: 2, Amount: 2, Price: 50000.0},
+			&models.OrderItem{ID: 12, OrderID: 8, ProductID: 3, Amount: 1, Price: 100000.0},
+		},
+		Shipping: &models.OrderShipping{Name: "Budi", Line1: "Jl. Merdeka 1", City: "Jakarta", PostalCode: "10110", Country: "ID"},
+	}
+}
+
+func TestCreate(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		d := newDeps()
+		d.repo.On("LockItems", mock.Anything, int64(8)).Return(nil).Once()
+		d.orders.On("GetByID", mock.Anything, int64(8)).Return(paidOrder(models.OrderProccessed), nil).Once()
+		d.repo.On("GetByOrder", mock.Anything, int64(8)).Return([]*models.Shipment{}, nil).Once()
+		d.repo.On("Create", mock.Anything, mock.MatchedBy(func(s *models.Shipment) bool {
+			return s.Status == models.ShipmentPending && s.Items[0].ProductID == 2
+		})).Return(nil).Once()
+
+		shipment := &models.Shipment{OrderID: 8, Items: []*models.ShipmentItem{&models.ShipmentItem{OrderItemID: 11, Amount: 2}}}
+		err := d.usecase().Create(context.TODO(), shipment)
+
+		assert.NoError(t, err)
+		d.repo.AssertExpectations(t)
+		audittest.AssertRecorded(t, d.audit, models.AuditShipment, shipment.ID, models.AuditCreate, nil, shipment)
+	})
+
+	t.Run("pieces already packed", func(t *testing.T) {
+		d := newDeps()
+		d.repo.On("LockItems", mock.Anything, int64(8)).Return(nil).Once()
+		d.orders.On("GetByID", mock.Anything, int64(8)).Return(paidOrder(models.OrderPartiallyShipped), nil).Once()
+		d.repo.On("GetByOrder", mock.Anything, int64(8)).Return([]*models.Shipment{
+			&models.Shipment{ID: 1, OrderID: 8, Status: models.ShipmentShipped},
+			&models.Shipment{ID: 2, OrderID: 8, Status: models.ShipmentCancelled},
+		}, nil).Once()
+		d.repo.On("GetItems", mock.Anything, int64(1)).
+			Return([]*models.ShipmentItem{&models.ShipmentItem{ShipmentID: 1, OrderItemID: 11, ProductID: 2, Amount: 1}}, nil).Once()
+		d.repo.On("GetItems", mock.Anything, int64(2)).
+			Return([]*models.ShipmentItem{&models.ShipmentItem{ShipmentID: 2, OrderItemID: 11, ProductID: 2, Amount: 1}}, nil).Once()
+
+		shipment := &models.Shipment{OrderID: 8, Items: []*models.ShipmentItem{&models.ShipmentItem{OrderItemID: 11, Amount: 2}}}
+		err := d.usecase().Create(context.TODO(), shipment)
+
+		assert.Equal(t, models.ErrBadParamInput, err)
+		d.repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		audittest.AssertNothingRecorded(t, d.audit)
+	})
+
+	t.Run("order not paid", func(t *testing.T) {
+		d := newDeps()
+		d.repo.On("LockItems", mock.Anything, int64(8)).Return(nil).Once()
+		d.orders.On("GetByID", mock.Anything, int64(8)).Return(paidOrder(models.OrderPending), nil).Once()
+
+		shipment := &models.Shipment{OrderID: 8, Items: []*models.ShipmentItem{&models.ShipmentItem{OrderItemID: 11, Amount: 1}}}
+		err := d.usecase().Create(context.TODO(), shipment)
+
+		assert.Equal(t, models.ErrBadParamInput, err)
+	})
+}
+
+func TestShip(t *testing.T) {
+	t.Run("partial fulfilment", func(t *testing.T) {
+		d := newDeps()
+		pending := models.Shipment{ID: 1, OrderID: 8, Status: models.ShipmentPending, Version: 1}
+		d.repo.On("GetByID", mock.Anything, int64(1)).
+			Return(&models.Shipment{ID: 1, OrderID: 8, Status: models.ShipmentPending, Version: 1}, nil).Once()
+		d.repo.On("Update", mock.Anything, mock.MatchedBy(func(s *models.Shipment) bool {
+			return s.Status == models.ShipmentShipped && s.Carrier == "JNE" && s.TrackingNumber == "JN0001" && s.Shipped.Valid
+		})).Return(nil).Once()
+		d.orders.On("GetByID", mock.Anything, int64(8)).Return(paidOrder(models.OrderProccessed), nil).Once()
+		d.repo.On("GetByOrder", mock.Anything, int64(8)).
+			Return([]*models.Shipment{&models.Shipment{ID: 1, OrderID: 8, Status: models.ShipmentShipped}}, nil).Once()
+		d.repo.On("GetItems", mock.Anything, int64(1)).
+			Return([]*models.ShipmentItem{&models.ShipmentItem{ShipmentID: 1, OrderItemID: 11, ProductID: 2, Amount: 2}}, nil).Once()
+		d.orders.On("Update", mock.Anything, mock.MatchedBy(func(o *models.Order) bool {
+			return o.ID == 8 && o.Status == models.OrderPartiallyShipped && o.Version == 2
+		})).Return(nil).Once()
+
+		shipment, err := d.usecase().Ship(context.TODO(), int64(1), " JNE ", "JN0001")
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.ShipmentShipped, shipment.Status)
+		d.repo.AssertExpectations(t)
+		d.orders.AssertExpectations(t)
+		audittest.AssertRecorded(t, d.audit, models.AuditShipment, int64(1), models.AuditUpdate, &pending, shipment)
+	})
+
+	t.Run("without tracking number", func(t *testing.T) {
+		d := newDeps()
+
+		_, err := d.usecase().Ship(context.TODO(), int64(1), "JNE", "")
+
+		assert.Equal(t, models.ErrBadParamInput, err)
+		d.repo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+	})
+}
+
+func TestDeliverCompleteOrder(t *testing.T) {
+	d := newDeps()
+	d.repo.On("GetByID", mock.Anything, int64(2)).
+		Return(&models.Shipment{ID: 2, OrderID: 8, Status: models.ShipmentShipped, Version: 2}, nil).Once()
+	d.repo.On("Update", mock.Anything, mock.AnythingOfType("*models.Shipment")).Return(nil).Once()
+	d.orders.On("GetByID", mock.Anything, int64(8)).Return(paidOrder(models.OrderShipped), nil).Once()
+	d.repo.On("GetByOrder", mock.Anything, int64(8)).Return([]*models.Shipment{
+		&models.Shipment{ID: 1, OrderID: 8, Status: models.ShipmentDelivered},
+		&models.Shipment{ID: 2, OrderID: 8, Status: models.ShipmentDelivered},
+	}, nil).Once()
+	d.repo.On("GetItems", mock.Anything, int64(1)).
+		Return([]*models.ShipmentItem{&models.ShipmentItem{ShipmentID: 1, OrderItemID: 11, ProductID: 2, Amount: 2}}, nil).Once()
+	d.repo.On("GetItems", mock.Anything, int64(2)).
+		Return([]*models.ShipmentItem{&models.ShipmentItem{ShipmentID: 2, OrderItemID: 12, ProductID: 3, Amount: 1}}, nil).Once()
+	d.orders.On("Update", mock.Anything, mock.MatchedBy(func(o *models.Order) bool {
+		return o.Status == models.OrderCompleted
+	})).Return(nil).Once()
+
+	_, err := d.usecase().Deliver(context.TODO(), int64(2))
+
+	assert.NoError(t, err)
+	d.orders.AssertExpectations(t)
+}
+
+func TestPackingSlip(t *testing.T) {
+	d := newDeps()
+	d.repo.On("GetByID", mock.Anything, int64(1)).
+		Return(&models.Shipment{ID: 1, OrderID: 8, Status: models.ShipmentPending}, nil).Once()
+	d.repo.On("GetItems", mock.Anything, int64(1)).Return([]*models.ShipmentItem{
+		&models.ShipmentItem{ShipmentID: 1, OrderItemID: 11, ProductID: 2, Amount: 2},
+		&models.ShipmentItem{ShipmentID: 1, OrderItemID: 12, ProductID: 3, Amount: 1},
+	}, nil).Once()
+	d.orders.On("GetByID", mock.Anything, int64(8)).Return(paidOrder(models.OrderProccessed), nil).Once()
+	// product 2 is in the trash, it is still named
+	d.products.On("GetAnyByID", mock.Anything, int64(2)).Return(&models.Product{ID: 2, Name: "Kemeja", SKU: "KMJ-01"}, nil).Once()
+	d.products.On("GetAnyByID", mock.Anything, int64(3)).Return(nil, models.ErrNotFound).Once()
+
+	slip, err := d.usecase().PackingSlip(context.TODO(), int64(1))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Jakarta", slip.ShipTo.City)
+	assert.Len(t, slip.Lines, 2)
+	assert.Equal(t, "KMJ-01", slip.Lines[0].SKU)
+	assert.Equal(t, "", slip.Lines[1].Name)
+}
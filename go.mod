@@ -14,6 +14,6 @@ require (
 	github.com/lib/pq v1.2.0
 	github.com/stretchr/testify v1.4.0
 	github.com/vektra/mockery v0.0.0-20181123154057-e78b021dcbb5 // indirect
-	golang.org/x/crypto v0.0.0-20191119213627-4f8c1d86b1ba // indirect
+	golang.org/x/crypto v0.0.0-20191119213627-4f8c1d86b1ba
 	gopkg.in/guregu/null.v3 v3.4.0
 )
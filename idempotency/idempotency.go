@@ -0,0 +1,33 @@
+package idempotency
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// Header carry the key a client choose for a request it may retry
+var Header = "Idempotency-Key"
+
+// ReplayedHeader is set on responses replayed from a previous request
+var ReplayedHeader = "Idempotent-Replayed"
+
+// MaxKeyLength is the longest key accepted
+var MaxKeyLength = 255
+
+// Lease is how long a request hold its key, a retry take over a key still in
+// progress after that as its request must have died with the server. It is
+// well past the write timeout of the server.
+var Lease = time.Minute
+
+// ResponseHeaders are the headers of a response kept to be replayed, the
+// others describe the request that got it e.g. X-Request-ID or rate limits
+var ResponseHeaders = []string{"Content-Type", "Content-Disposition", "Location", "ETag", "Last-Modified"}
+
+// Fingerprint identify a request by its method, path and body
+func Fingerprint(method string, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + " " + path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
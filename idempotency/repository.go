@@ -0,0 +1,19 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// Repository represent the idempotency records contract. Begin must claim a
+// key atomically so two requests made at once with the same key are not both
+// run, and let a retry take over a key whose lease expired before its
+// request completed.
+type Repository interface {
+	Begin(ctx context.Context, key string, fingerprint string, now time.Time, expires time.Time) (*models.IdempotencyRecord, error)
+	Complete(ctx context.Context, record *models.IdempotencyRecord) error
+	Release(ctx context.Context, key string) error
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/soerjadi/exam/idempotency"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/utils"
+)
+
+type pgIdempotencyRepository struct {
+	Conn *sql.DB
+}
+
+var logger = utils.LogBuilder(true)
+
+// NewPGIdempotencyRepository is bridge to create an object from
+// idempotency.Repository interface, records are shared by every instance of
+// the server
+func NewPGIdempotencyRepository(Conn *sql.DB) idempotency.Repository {
+	return &pgIdempotencyRepository{Conn}
+}
+
+// Begin insert a record for key leased until expires, the primary key make
+// sure only one request claim it. A record still in progress whose lease
+// expired, its request died with the server, is taken over by a request of
+// the same fingerprint. It return nil when the key was claimed, the record
+// holding it otherwise.
+func (p *pgIdempotencyRepository) Begin(ctx context.Context, key string, fingerprint string, now time.Time, expires time.Time) (*models.IdempotencyRecord, error) {
+	res, err := p.Conn.ExecContext(ctx, `INSERT INTO idempotency_keys(key, fingerprint, created, expires) VALUES(?, ?, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET created = EXCLUDED.created, expires = EXCLUDED.expires
+		WHERE idempotency_keys.status = 0 AND idempotency_keys.fingerprint = EXCLUDED.fingerprint AND idempotency_keys.expires < EXCLUDED.created`,
+		key, fingerprint, now, expires)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	if affected == 1 {
+		return nil, nil
+	}
+
+	record := &models.IdempotencyRecord{Key: key}
+	var header []byte
+	err = p.Conn.QueryRowContext(ctx, `SELECT fingerprint, status, headers, body, created FROM idempotency_keys WHERE key = ?`, key).
+		Scan(&record.Fingerprint, &record.Status, &header, &record.Body, &record.Created)
+
+	if err == sql.ErrNoRows {
+		// released in between, the request can run
+		return p.Begin(ctx, key, fingerprint, now, expires)
+	}
+
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	if err = json.Unmarshal(header, &record.Header); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// Complete store the response of the request holding the key of record
+func (p *pgIdempotencyRepository) Complete(ctx context.Context, record *models.IdempotencyRecord) error {
+	header, err := json.Marshal(record.Header)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.Conn.ExecContext(ctx, `UPDATE idempotency_keys SET status = ?, headers = ?, body = ? WHERE key = ?`,
+		record.Status, header, record.Body, record.Key)
+	return err
+}
+
+// Release free key so the request can be retried
+func (p *pgIdempotencyRepository) Release(ctx context.Context, key string) error {
+	_, err := p.Conn.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE key = ?`, key)
+	return err
+}
+
+// Purge remove records created before, it return how many were removed
+func (p *pgIdempotencyRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	res, err := p.Conn.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE created < ?`, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
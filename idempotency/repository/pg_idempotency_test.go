@@ -0,0 +1,159 @@
+package repository_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/soerjadi/exam/idempotency/repository"
+	"github.com/soerjadi/exam/models"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	insertQuery = "INSERT INTO idempotency_keys\\(key, fingerprint, created, expires\\) VALUES\\(\\?, \\?, \\?, \\?\\)\\s+" +
+		"ON CONFLICT \\(key\\) DO UPDATE SET created = EXCLUDED.created, expires = EXCLUDED.expires\\s+" +
+		"WHERE idempotency_keys.status = 0 AND idempotency_keys.fingerprint = EXCLUDED.fingerprint AND idempotency_keys.expires < EXCLUDED.created"
+	selectQuery = "SELECT fingerprint, status, headers, body, created FROM idempotency_keys WHERE key = \\?"
+)
+
+func TestBegin(t *testing.T) {
+	now := time.Now()
+	expires := now.Add(time.Minute)
+
+	t.Run("free", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+
+		mock.ExpectExec(insertQuery).WithArgs("customer:4|abc", "f1", now, expires).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		r := repository.NewPGIdempotencyRepository(db)
+		record, err := r.Begin(context.TODO(), "customer:4|abc", "f1", now, expires)
+
+		assert.NoError(t, err)
+		assert.Nil(t, record)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("taken", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+
+		rows := sqlmock.NewRows([]string{"fingerprint", "status", "headers", "body", "created"}).
+			AddRow("f1", 201, []byte(`{"Content-Type":["application/json"],"Location":["/v1/order/7"]}`), []byte(`{"id":7}`), now)
+
+		mock.ExpectExec(insertQuery).WithArgs("customer:4|abc", "f1", now, expires).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(selectQuery).WithArgs("customer:4|abc").WillReturnRows(rows)
+
+		r := repository.NewPGIdempotencyRepository(db)
+		record, err := r.Begin(context.TODO(), "customer:4|abc", "f1", now, expires)
+
+		assert.NoError(t, err)
+		assert.True(t, record.Completed())
+		assert.Equal(t, "customer:4|abc", record.Key)
+		assert.Equal(t, 201, record.Status)
+		assert.Equal(t, "/v1/order/7", record.Header.Get("Location"))
+		assert.Equal(t, []byte(`{"id":7}`), record.Body)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("in progress within its lease", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+
+		rows := sqlmock.NewRows([]string{"fingerprint", "status", "headers", "body", "created"}).
+			AddRow("f1", 0, []byte(`{}`), nil, now.Add(-10*time.Second))
+		mock.ExpectExec(insertQuery).WithArgs("customer:4|abc", "f1", now, expires).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(selectQuery).WithArgs("customer:4|abc").WillReturnRows(rows)
+
+		r := repository.NewPGIdempotencyRepository(db)
+		record, err := r.Begin(context.TODO(), "customer:4|abc", "f1", now, expires)
+
+		assert.NoError(t, err)
+		assert.False(t, record.Completed())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("released meanwhile", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+
+		mock.ExpectExec(insertQuery).WithArgs("customer:4|abc", "f1", now, expires).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(selectQuery).WithArgs("customer:4|abc").
+			WillReturnRows(sqlmock.NewRows([]string{"fingerprint", "status", "headers", "body", "created"}))
+		mock.ExpectExec(insertQuery).WithArgs("customer:4|abc", "f1", now, expires).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		r := repository.NewPGIdempotencyRepository(db)
+		record, err := r.Begin(context.TODO(), "customer:4|abc", "f1", now, expires)
+
+		assert.NoError(t, err)
+		assert.Nil(t, record)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestComplete(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectExec("UPDATE idempotency_keys SET status = \\?, headers = \\?, body = \\? WHERE key = \\?").
+		WithArgs(201, []byte(`{"Location":["/v1/order/7"]}`), []byte(`{"id":7}`), "customer:4|abc").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	r := repository.NewPGIdempotencyRepository(db)
+	err = r.Complete(context.TODO(), &models.IdempotencyRecord{
+		Key:    "customer:4|abc",
+		Status: 201,
+		Header: http.Header{"Location": {"/v1/order/7"}},
+		Body:   []byte(`{"id":7}`),
+	})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRelease(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectExec("DELETE FROM idempotency_keys WHERE key = \\?").
+		WithArgs("customer:4|abc").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	r := repository.NewPGIdempotencyRepository(db)
+	err = r.Release(context.TODO(), "customer:4|abc")
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurge(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	before := time.Now()
+	mock.ExpectExec("DELETE FROM idempotency_keys WHERE created < \\?").
+		WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 3))
+
+	r := repository.NewPGIdempotencyRepository(db)
+	removed, err := r.Purge(context.TODO(), before)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), removed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
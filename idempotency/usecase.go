@@ -0,0 +1,17 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// Usecase represent the idempotency usecase
+type Usecase interface {
+	Begin(ctx context.Context, key string, fingerprint string) (*models.IdempotencyRecord, error)
+	Complete(ctx context.Context, key string, status int, header http.Header, body []byte) error
+	Release(ctx context.Context, key string) error
+	Purge(ctx context.Context, ttl time.Duration) (int64, error)
+}
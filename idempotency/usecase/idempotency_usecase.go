@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/soerjadi/exam/idempotency"
+	"github.com/soerjadi/exam/models"
+)
+
+type idempotencyUsecase struct {
+	repo           idempotency.Repository
+	contextTimeout time.Duration
+}
+
+// NewIdempotencyUsecase will create object that represent of
+// idempotency.Usecase interface
+func NewIdempotencyUsecase(r idempotency.Repository, timeout time.Duration) idempotency.Usecase {
+	return &idempotencyUsecase{
+		repo:           r,
+		contextTimeout: timeout,
+	}
+}
+
+// Begin claim key for a request of fingerprint for idempotency.Lease. It
+// return nil when the key was free or its lease expired, the record holding
+// it otherwise.
+func (i *idempotencyUsecase) Begin(ctx context.Context, key string, fingerprint string) (*models.IdempotencyRecord, error) {
+	ctx, cancel := context.WithTimeout(ctx, i.contextTimeout)
+	defer cancel()
+
+	now := time.Now()
+	return i.repo.Begin(ctx, key, fingerprint, now, now.Add(idempotency.Lease))
+}
+
+// Complete store the response of the request holding key, with the headers
+// of idempotency.ResponseHeaders it carry
+func (i *idempotencyUsecase) Complete(ctx context.Context, key string, status int, header http.Header, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, i.contextTimeout)
+	defer cancel()
+
+	kept := make(http.Header)
+	for _, name := range idempotency.ResponseHeaders {
+		if values, ok := header[http.CanonicalHeaderKey(name)]; ok {
+			kept[http.CanonicalHeaderKey(name)] = values
+		}
+	}
+
+	return i.repo.Complete(ctx, &models.IdempotencyRecord{Key: key, Status: status, Header: kept, Body: body})
+}
+
+// Release free key so the request can be retried
+func (i *idempotencyUsecase) Release(ctx context.Context, key string) error {
+	ctx, cancel := context.WithTimeout(ctx, i.contextTimeout)
+	defer cancel()
+
+	return i.repo.Release(ctx, key)
+}
+
+// Purge remove the records older than ttl, it return how many were removed
+func (i *idempotencyUsecase) Purge(ctx context.Context, ttl time.Duration) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, i.contextTimeout)
+	defer cancel()
+
+	return i.repo.Purge(ctx, time.Now().Add(-ttl))
+}
@@ -0,0 +1,77 @@
+package usecase_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/soerjadi/exam/idempotency"
+	"github.com/soerjadi/exam/idempotency/mocks"
+	"github.com/soerjadi/exam/idempotency/usecase"
+	"github.com/soerjadi/exam/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestBegin(t *testing.T) {
+	mockIdempotencyRepo := new(mocks.Repository)
+
+	// the key is leased for idempotency.Lease from now
+	mockIdempotencyRepo.On("Begin", mock.Anything, "customer:4|abc", "f1", mock.AnythingOfType("time.Time"),
+		mock.AnythingOfType("time.Time")).Return(nil, nil).Once()
+
+	i := usecase.NewIdempotencyUsecase(mockIdempotencyRepo, time.Second*2)
+	record, err := i.Begin(context.TODO(), "customer:4|abc", "f1")
+
+	assert.NoError(t, err)
+	assert.Nil(t, record)
+	mockIdempotencyRepo.AssertExpectations(t)
+	now := mockIdempotencyRepo.Calls[0].Arguments.Get(3).(time.Time)
+	expires := mockIdempotencyRepo.Calls[0].Arguments.Get(4).(time.Time)
+	assert.Equal(t, idempotency.Lease, expires.Sub(now))
+}
+
+func TestComplete(t *testing.T) {
+	mockIdempotencyRepo := new(mocks.Repository)
+	header := http.Header{
+		"Content-Type": {"application/json"},
+		"Location":     {"/v1/order/7"},
+		"Etag":         {`"3"`},
+		"X-Request-Id": {"abc"},
+		"Set-Cookie":   {"session=1"},
+	}
+
+	mockIdempotencyRepo.On("Complete", mock.Anything, &models.IdempotencyRecord{
+		Key:    "customer:4|abc",
+		Status: http.StatusCreated,
+		Header: http.Header{
+			"Content-Type": {"application/json"},
+			"Location":     {"/v1/order/7"},
+			"Etag":         {`"3"`},
+		},
+		Body: []byte(`{"id":7}`),
+	}).Return(nil).Once()
+
+	i := usecase.NewIdempotencyUsecase(mockIdempotencyRepo, time.Second*2)
+	err := i.Complete(context.TODO(), "customer:4|abc", http.StatusCreated, header, []byte(`{"id":7}`))
+
+	assert.NoError(t, err)
+	mockIdempotencyRepo.AssertExpectations(t)
+}
+
+func TestPurge(t *testing.T) {
+	mockIdempotencyRepo := new(mocks.Repository)
+	ttl := 24 * time.Hour
+
+	mockIdempotencyRepo.On("Purge", mock.Anything, mock.MatchedBy(func(before time.Time) bool {
+		return before.Before(time.Now().Add(-ttl).Add(time.Second))
+	})).Return(int64(2), nil).Once()
+
+	i := usecase.NewIdempotencyUsecase(mockIdempotencyRepo, time.Second*2)
+	purged, err := i.Purge(context.TODO(), ttl)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), purged)
+	mockIdempotencyRepo.AssertExpectations(t)
+}
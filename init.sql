@@ -2,8 +2,19 @@ CREATE TABLE IF NOT EXISTS product(
     id      BIGSERIAL       PRIMARY KEY NOT NULL,
     name    varchar         NOT NULL,
     sku     varchar         NOT NULL,
+    status  SMALLINT        NOT NULL DEFAULT 0,
+    publish_at timestamp    NULL,
+    unpublish_at timestamp  NULL,
     created timestamp       NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    updated timestamp       NULL
+    updated timestamp       NULL,
+    deleted_at timestamp    NULL,
+    version BIGINT          NOT NULL DEFAULT 1,
+    weight  DOUBLE PRECISION NOT NULL DEFAULT 0,
+    length  DOUBLE PRECISION NOT NULL DEFAULT 0,
+    width   DOUBLE PRECISION NOT NULL DEFAULT 0,
+    height  DOUBLE PRECISION NOT NULL DEFAULT 0,
+    tax_class_id BIGINT     NULL,
+    stock   BIGINT          NOT NULL DEFAULT 0
 );
 
 CREATE TABLE IF NOT EXISTS categories (
@@ -11,7 +22,9 @@ CREATE TABLE IF NOT EXISTS categories (
     name        varchar        NOT NULL,
     parent_id   bigint         NULL DEFAULT 0,
     created     timestamp      NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    updated     timestamp      NULL
+    updated     timestamp      NULL,
+    deleted_at  timestamp      NULL,
+    version     bigint         NOT NULL DEFAULT 1
 );
 
 CREATE TABLE product_category (
@@ -19,19 +32,426 @@ CREATE TABLE product_category (
     product_id BIGINT NOT NULL,
     category_id BIGINT NOT NULL
 );
+CREATE INDEX product_category_category_idx ON product_category (category_id);
 
 CREATE TABLE IF NOT EXISTS product_price (
     id          BIGSERIAL   PRIMARY KEY NOT NULL,
     amount      BIGINT      NOT NULL,
     price       DOUBLE PRECISION DEFAULT 0.0::double PRECISION NOT NULL,
-    product_id  BIGINT      NOT NULL
+    product_id  BIGINT      NOT NULL,
+    valid_from  TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    valid_to    TIMESTAMP   NULL
 );
 
 CREATE TABLE IF NOT EXISTS order (
     id          BIGSERIAL   PRIMARY KEY NOT NULL,
+    customer_id BIGINT      NULL,
     product_id  BIGINT      NOT NULL,
     amount      BIGINT      NOT NULL,
     price       DOUBLE PRECISION DEFAULT 0.0::double PRECISION NOT NULL,
+    discount    DOUBLE PRECISION DEFAULT 0.0::double PRECISION NOT NULL,
+    total       DOUBLE PRECISION DEFAULT 0.0::double PRECISION NOT NULL,
+    tax         DOUBLE PRECISION DEFAULT 0.0::double PRECISION NOT NULL,
+    tax_included BOOLEAN    NOT NULL DEFAULT false,
     status      SMALLINT    NOT NULL,
+    created     TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    version     BIGINT      NOT NULL DEFAULT 1
+);
+CREATE INDEX orders_status_idx ON order (status, created);
+CREATE INDEX orders_customer_idx ON order (customer_id, created);
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id          BIGSERIAL   PRIMARY KEY NOT NULL,
+    entity_type VARCHAR     NOT NULL,
+    entity_id   BIGINT      NOT NULL,
+    action      VARCHAR     NOT NULL,
+    actor       VARCHAR     NOT NULL,
+    request_id  VARCHAR     NOT NULL DEFAULT '',
+    diff        JSONB       NOT NULL DEFAULT '{}'::jsonb,
+    created     TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX audit_log_entity_idx ON audit_log (entity_type, entity_id);
+
+CREATE TABLE IF NOT EXISTS promotions (
+    id           BIGSERIAL   PRIMARY KEY NOT NULL,
+    name         VARCHAR     NOT NULL,
+    product_id   BIGINT      NULL,
+    category_id  BIGINT      NULL,
+    min_quantity BIGINT      NOT NULL DEFAULT 0,
+    min_subtotal DOUBLE PRECISION NOT NULL DEFAULT 0.0,
+    start_at     TIMESTAMP   NULL,
+    end_at       TIMESTAMP   NULL,
+    action       VARCHAR     NOT NULL,
+    value        DOUBLE PRECISION NOT NULL,
+    priority     INT         NOT NULL DEFAULT 0,
+    exclusive    BOOLEAN     NOT NULL DEFAULT false,
+    active       BOOLEAN     NOT NULL DEFAULT true,
+    created      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated      TIMESTAMP   NULL
+);
+
+CREATE TABLE IF NOT EXISTS order_promotion (
+    id           BIGSERIAL   PRIMARY KEY NOT NULL,
+    order_id     BIGINT      NOT NULL,
+    promotion_id BIGINT      NOT NULL,
+    name         VARCHAR     NOT NULL,
+    discount     DOUBLE PRECISION NOT NULL
+);
+CREATE INDEX order_promotion_order_idx ON order_promotion (order_id);
+
+CREATE TABLE IF NOT EXISTS coupons (
+    id           BIGSERIAL   PRIMARY KEY NOT NULL,
+    code         VARCHAR     NOT NULL,
+    batch        VARCHAR     NOT NULL,
+    action       VARCHAR     NOT NULL,
+    value        DOUBLE PRECISION NOT NULL,
+    min_spend    DOUBLE PRECISION NOT NULL DEFAULT 0.0,
+    max_uses     BIGINT      NOT NULL DEFAULT 0,
+    per_customer BIGINT      NOT NULL DEFAULT 0,
+    used         BIGINT      NOT NULL DEFAULT 0,
+    expires_at   TIMESTAMP   NULL,
+    active       BOOLEAN     NOT NULL DEFAULT true,
+    created      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX coupons_code_idx ON coupons (code);
+CREATE INDEX coupons_batch_idx ON coupons (batch);
+
+CREATE TABLE IF NOT EXISTS order_coupon (
+    id           BIGSERIAL   PRIMARY KEY NOT NULL,
+    order_id     BIGINT      NOT NULL,
+    coupon_id    BIGINT      NOT NULL,
+    code         VARCHAR     NOT NULL,
+    customer     VARCHAR     NOT NULL DEFAULT '',
+    discount     DOUBLE PRECISION NOT NULL
+);
+CREATE UNIQUE INDEX order_coupon_order_idx ON order_coupon (order_id);
+CREATE INDEX order_coupon_customer_idx ON order_coupon (coupon_id, customer);
+
+CREATE TABLE IF NOT EXISTS order_item (
+    id          BIGSERIAL   PRIMARY KEY NOT NULL,
+    order_id    BIGINT      NOT NULL,
+    product_id  BIGINT      NOT NULL,
+    amount      BIGINT      NOT NULL,
+    price       DOUBLE PRECISION DEFAULT 0.0::double PRECISION NOT NULL,
+    tax_name    VARCHAR     NOT NULL DEFAULT '',
+    tax_rate    DOUBLE PRECISION DEFAULT 0.0::double PRECISION NOT NULL,
+    tax         DOUBLE PRECISION DEFAULT 0.0::double PRECISION NOT NULL
+);
+CREATE INDEX order_item_order_idx ON order_item (order_id);
+CREATE INDEX order_item_product_idx ON order_item (product_id);
+
+CREATE TABLE IF NOT EXISTS carts (
+    id          BIGSERIAL   PRIMARY KEY NOT NULL,
+    token       VARCHAR     NOT NULL,
+    customer    VARCHAR     NOT NULL DEFAULT '',
+    order_id    BIGINT      NULL,
+    expires_at  TIMESTAMP   NOT NULL,
+    created     TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated     TIMESTAMP   NULL
+);
+CREATE UNIQUE INDEX carts_token_idx ON carts (token);
+CREATE INDEX carts_customer_idx ON carts (customer) WHERE order_id IS NULL;
+
+CREATE TABLE IF NOT EXISTS cart_item (
+    id          BIGSERIAL   PRIMARY KEY NOT NULL,
+    cart_id     BIGINT      NOT NULL,
+    product_id  BIGINT      NOT NULL,
+    amount      BIGINT      NOT NULL
+);
+CREATE UNIQUE INDEX cart_item_product_idx ON cart_item (cart_id, product_id);
+
+CREATE TABLE IF NOT EXISTS customers (
+    id          BIGSERIAL   PRIMARY KEY NOT NULL,
+    email       VARCHAR     NOT NULL,
+    name        VARCHAR     NOT NULL DEFAULT '',
+    password    VARCHAR     NOT NULL,
+    verified    BOOLEAN     NOT NULL DEFAULT false,
+    created     TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated     TIMESTAMP   NULL
+);
+CREATE UNIQUE INDEX customers_email_idx ON customers (email);
+
+CREATE TABLE IF NOT EXISTS customer_tokens (
+    id          BIGSERIAL   PRIMARY KEY NOT NULL,
+    customer_id BIGINT      NOT NULL,
+    purpose     VARCHAR     NOT NULL,
+    hash        VARCHAR     NOT NULL,
+    expires_at  TIMESTAMP   NOT NULL,
+    used_at     TIMESTAMP   NULL,
     created     TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP
-);
\ No newline at end of file
+);
+CREATE UNIQUE INDEX customer_tokens_hash_idx ON customer_tokens (hash);
+
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    token_id    VARCHAR     PRIMARY KEY NOT NULL,
+    expires_at  TIMESTAMP   NOT NULL
+);
+CREATE INDEX customer_tokens_customer_idx ON customer_tokens (customer_id, purpose);
+
+CREATE TABLE IF NOT EXISTS customer_roles (
+    customer_id BIGINT      NOT NULL,
+    role        VARCHAR     NOT NULL,
+    created     TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (customer_id, role)
+);
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id           BIGSERIAL   PRIMARY KEY NOT NULL,
+    name         VARCHAR     NOT NULL,
+    prefix       VARCHAR     NOT NULL,
+    hash         VARCHAR     NOT NULL,
+    permissions  VARCHAR     NOT NULL DEFAULT '',
+    requests     BIGINT      NOT NULL DEFAULT 0,
+    last_used_at TIMESTAMP   NULL,
+    revoked_at   TIMESTAMP   NULL,
+    created      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated      TIMESTAMP   NULL
+);
+CREATE UNIQUE INDEX api_keys_hash_idx ON api_keys (hash);
+
+CREATE TABLE IF NOT EXISTS api_key_usage (
+    key_id      BIGINT      NOT NULL,
+    day         DATE        NOT NULL,
+    requests    BIGINT      NOT NULL DEFAULT 0,
+    PRIMARY KEY (key_id, day)
+);
+
+CREATE TABLE IF NOT EXISTS addresses (
+    id           BIGSERIAL   PRIMARY KEY NOT NULL,
+    customer_id  BIGINT      NOT NULL,
+    name         VARCHAR     NOT NULL,
+    line1        VARCHAR     NOT NULL,
+    line2        VARCHAR     NOT NULL DEFAULT '',
+    city         VARCHAR     NOT NULL,
+    region       VARCHAR     NOT NULL DEFAULT '',
+    postal_code  VARCHAR     NOT NULL DEFAULT '',
+    country      CHAR(2)     NOT NULL,
+    phone        VARCHAR     NOT NULL DEFAULT '',
+    is_default   BOOLEAN     NOT NULL DEFAULT false,
+    created      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated      TIMESTAMP   NULL
+);
+CREATE INDEX addresses_customer_idx ON addresses (customer_id);
+
+CREATE TABLE IF NOT EXISTS shipping_zones (
+    id           BIGSERIAL   PRIMARY KEY NOT NULL,
+    name         VARCHAR     NOT NULL,
+    created      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS shipping_zone_region (
+    zone_id      BIGINT      NOT NULL,
+    country      CHAR(2)     NOT NULL,
+    region       VARCHAR     NOT NULL DEFAULT '',
+    PRIMARY KEY (zone_id, country, region)
+);
+CREATE INDEX shipping_zone_region_country_idx ON shipping_zone_region (country, region);
+
+CREATE TABLE IF NOT EXISTS shipping_methods (
+    id           BIGSERIAL   PRIMARY KEY NOT NULL,
+    zone_id      BIGINT      NOT NULL,
+    name         VARCHAR     NOT NULL,
+    type         VARCHAR     NOT NULL,
+    cost         DOUBLE PRECISION NOT NULL DEFAULT 0,
+    active       BOOLEAN     NOT NULL DEFAULT true,
+    created      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated      TIMESTAMP   NULL
+);
+CREATE INDEX shipping_methods_zone_idx ON shipping_methods (zone_id);
+
+CREATE TABLE IF NOT EXISTS shipping_rates (
+    id           BIGSERIAL   PRIMARY KEY NOT NULL,
+    method_id    BIGINT      NOT NULL,
+    min          DOUBLE PRECISION NOT NULL DEFAULT 0,
+    cost         DOUBLE PRECISION NOT NULL
+);
+CREATE INDEX shipping_rates_method_idx ON shipping_rates (method_id);
+
+CREATE TABLE IF NOT EXISTS order_shipping (
+    id           BIGSERIAL   PRIMARY KEY NOT NULL,
+    order_id     BIGINT      NOT NULL,
+    method_id    BIGINT      NOT NULL,
+    method       VARCHAR     NOT NULL,
+    cost         DOUBLE PRECISION NOT NULL,
+    name         VARCHAR     NOT NULL,
+    line1        VARCHAR     NOT NULL,
+    line2        VARCHAR     NOT NULL DEFAULT '',
+    city         VARCHAR     NOT NULL,
+    region       VARCHAR     NOT NULL DEFAULT '',
+    postal_code  VARCHAR     NOT NULL DEFAULT '',
+    country      CHAR(2)     NOT NULL,
+    phone        VARCHAR     NOT NULL DEFAULT ''
+);
+CREATE UNIQUE INDEX order_shipping_order_idx ON order_shipping (order_id);
+
+CREATE TABLE IF NOT EXISTS tax_classes (
+    id           BIGSERIAL   PRIMARY KEY NOT NULL,
+    name         VARCHAR     NOT NULL,
+    created      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS tax_rates (
+    id           BIGSERIAL   PRIMARY KEY NOT NULL,
+    class_id     BIGINT      NOT NULL,
+    country      CHAR(2)     NOT NULL,
+    region       VARCHAR     NOT NULL DEFAULT '',
+    name         VARCHAR     NOT NULL,
+    rate         DOUBLE PRECISION NOT NULL,
+    created      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated      TIMESTAMP   NULL
+);
+CREATE INDEX tax_rates_class_idx ON tax_rates (class_id, country, region);
+
+CREATE TABLE IF NOT EXISTS payments (
+    id           BIGSERIAL   PRIMARY KEY NOT NULL,
+    order_id     BIGINT      NOT NULL,
+    provider     VARCHAR     NOT NULL,
+    reference    VARCHAR     NOT NULL DEFAULT '',
+    status       VARCHAR     NOT NULL,
+    amount       DOUBLE PRECISION NOT NULL DEFAULT 0,
+    captured     DOUBLE PRECISION NOT NULL DEFAULT 0,
+    refunded     DOUBLE PRECISION NOT NULL DEFAULT 0,
+    created      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated      TIMESTAMP   NULL,
+    version      BIGINT      NOT NULL DEFAULT 1
+);
+CREATE INDEX payments_order_idx ON payments (order_id);
+CREATE INDEX payments_reference_idx ON payments (provider, reference);
+CREATE UNIQUE INDEX payments_open_order_idx ON payments (order_id) WHERE status NOT IN ('failed', 'voided');
+
+CREATE TABLE IF NOT EXISTS returns (
+    id           BIGSERIAL   PRIMARY KEY NOT NULL,
+    order_id     BIGINT      NOT NULL,
+    customer_id  BIGINT      NULL,
+    status       VARCHAR     NOT NULL,
+    reason       TEXT        NOT NULL DEFAULT '',
+    restock      BOOLEAN     NOT NULL DEFAULT false,
+    refund       DOUBLE PRECISION NOT NULL DEFAULT 0,
+    created      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated      TIMESTAMP   NULL,
+    version      BIGINT      NOT NULL DEFAULT 1
+);
+CREATE INDEX returns_order_idx ON returns (order_id);
+CREATE INDEX returns_status_idx ON returns (status, created);
+
+CREATE TABLE IF NOT EXISTS return_item (
+    id            BIGSERIAL  PRIMARY KEY NOT NULL,
+    return_id     BIGINT     NOT NULL,
+    order_item_id BIGINT     NOT NULL,
+    product_id    BIGINT     NOT NULL,
+    amount        BIGINT     NOT NULL
+);
+CREATE INDEX return_item_return_idx ON return_item (return_id);
+
+CREATE TABLE IF NOT EXISTS credit_notes (
+    id           BIGSERIAL   PRIMARY KEY NOT NULL,
+    number       VARCHAR     NOT NULL DEFAULT '',
+    return_id    BIGINT      NOT NULL,
+    order_id     BIGINT      NOT NULL,
+    lines        JSONB       NOT NULL,
+    tax          DOUBLE PRECISION NOT NULL DEFAULT 0,
+    adjustment   DOUBLE PRECISION NOT NULL DEFAULT 0,
+    amount       DOUBLE PRECISION NOT NULL DEFAULT 0,
+    created      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX credit_notes_return_idx ON credit_notes (return_id);
+
+CREATE TABLE IF NOT EXISTS shipments (
+    id              BIGSERIAL   PRIMARY KEY NOT NULL,
+    order_id        BIGINT      NOT NULL,
+    carrier         VARCHAR     NOT NULL DEFAULT '',
+    tracking_number VARCHAR     NOT NULL DEFAULT '',
+    status          VARCHAR     NOT NULL,
+    shipped         TIMESTAMP   NULL,
+    delivered       TIMESTAMP   NULL,
+    created         TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated         TIMESTAMP   NULL,
+    version         BIGINT      NOT NULL DEFAULT 1
+);
+CREATE INDEX shipments_order_idx ON shipments (order_id);
+
+CREATE TABLE IF NOT EXISTS shipment_item (
+    id            BIGSERIAL  PRIMARY KEY NOT NULL,
+    shipment_id   BIGINT     NOT NULL,
+    order_item_id BIGINT     NOT NULL,
+    product_id    BIGINT     NOT NULL,
+    amount        BIGINT     NOT NULL
+);
+CREATE INDEX shipment_item_shipment_idx ON shipment_item (shipment_id);
+
+CREATE TABLE IF NOT EXISTS invoice_sequences (
+    year         INT         PRIMARY KEY NOT NULL,
+    last         BIGINT      NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS invoices (
+    id           BIGSERIAL   PRIMARY KEY NOT NULL,
+    number       VARCHAR     NOT NULL,
+    year         INT         NOT NULL,
+    order_id     BIGINT      NOT NULL,
+    customer_id  BIGINT      NULL,
+    total        DOUBLE PRECISION NOT NULL DEFAULT 0,
+    issued       TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    snapshot     JSONB       NOT NULL
+);
+CREATE UNIQUE INDEX invoices_number_idx ON invoices (number);
+CREATE UNIQUE INDEX invoices_order_idx ON invoices (order_id);
+-- issued invoices are never changed nor removed
+CREATE RULE invoices_no_update AS ON UPDATE TO invoices DO INSTEAD NOTHING;
+CREATE RULE invoices_no_delete AS ON DELETE TO invoices DO INSTEAD NOTHING;
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key          VARCHAR     PRIMARY KEY NOT NULL,
+    fingerprint  VARCHAR     NOT NULL,
+    status       INT         NOT NULL DEFAULT 0,
+    headers      JSONB       NOT NULL DEFAULT '{}',
+    body         BYTEA       NULL,
+    created      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idempotency_keys_created_idx ON idempotency_keys (created);
+
+CREATE TABLE IF NOT EXISTS product_related (
+    product_id  BIGINT      NOT NULL,
+    related_id  BIGINT      NOT NULL,
+    score       BIGINT      NOT NULL,
+    computed    TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (product_id, related_id)
+);
+
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id            BIGSERIAL   PRIMARY KEY NOT NULL,
+    type          VARCHAR     NOT NULL,
+    aggregate_id  BIGINT      NOT NULL,
+    payload       JSONB       NOT NULL,
+    created       TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    dispatched    TIMESTAMP   NULL
+);
+CREATE INDEX outbox_events_pending_idx ON outbox_events (id) WHERE dispatched IS NULL;
+
+CREATE TABLE IF NOT EXISTS webhooks (
+    id       BIGSERIAL   PRIMARY KEY NOT NULL,
+    url      VARCHAR     NOT NULL,
+    secret   VARCHAR     NOT NULL,
+    events   JSONB       NOT NULL DEFAULT '[]',
+    active   BOOLEAN     NOT NULL DEFAULT TRUE,
+    created  TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated  TIMESTAMP   NULL,
+    version  BIGINT      NOT NULL DEFAULT 1
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id             BIGSERIAL   PRIMARY KEY NOT NULL,
+    webhook_id     BIGINT      NOT NULL,
+    event_id       BIGINT      NOT NULL,
+    event_type     VARCHAR     NOT NULL,
+    status         VARCHAR     NOT NULL,
+    attempts       INT         NOT NULL DEFAULT 0,
+    next_attempt   TIMESTAMP   NULL,
+    response_code  INT         NULL,
+    error          TEXT        NOT NULL DEFAULT '',
+    created        TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    delivered      TIMESTAMP   NULL,
+    UNIQUE (webhook_id, event_id)
+);
+CREATE INDEX webhook_deliveries_due_idx ON webhook_deliveries (status, next_attempt);
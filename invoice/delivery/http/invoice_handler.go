@@ -0,0 +1,99 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/soerjadi/exam/invoice"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/utils"
+)
+
+// InvoiceHandler represent the http handler for invoices
+type InvoiceHandler struct {
+	InvoiceUsecase invoice.Usecase
+}
+
+// NewInvoiceHandler initialize invoice resource endpoint
+func NewInvoiceHandler(router *mux.Router, usecase invoice.Usecase) *mux.Router {
+	handler := &InvoiceHandler{
+		InvoiceUsecase: usecase,
+	}
+
+	router.HandleFunc("/v1/order/{id:[0-9]+}/invoice", handler.GetByOrder).Methods("GET")
+
+	return router
+}
+
+// errorStatus map errors of the invoice usecase to http status
+func errorStatus(err error) int32 {
+	switch err {
+	case models.ErrUnauthorized:
+		return http.StatusUnauthorized
+	case models.ErrNotFound:
+		return http.StatusNotFound
+	}
+
+	return http.StatusBadRequest
+}
+
+// GetByOrder endpoint to download the invoice of an order, as PDF unless
+// format=html or format=json is asked
+func (h *InvoiceHandler) GetByOrder(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 0, 64)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "pdf"
+	}
+
+	if format != "pdf" && format != "html" && format != "json" {
+		utils.Error(w, http.StatusBadRequest, "unknown invoice format")
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	inv, err := h.InvoiceUsecase.GetByOrder(ctx, id)
+
+	if err != nil {
+		utils.Error(w, errorStatus(err), err.Error())
+		return
+	}
+
+	if format == "json" {
+		utils.JSON(w, http.StatusOK, inv)
+		return
+	}
+
+	// rendered in memory first so a failure is still reported as an error
+	var body bytes.Buffer
+	if format == "html" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		err = invoice.RenderHTML(&body, inv)
+	} else {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", inv.Number+".pdf"))
+		err = invoice.RenderPDF(&body, inv)
+	}
+
+	if err != nil {
+		w.Header().Del("Content-Disposition")
+		utils.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_, _ = body.WriteTo(w)
+}
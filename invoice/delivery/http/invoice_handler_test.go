@@ -0,0 +1,96 @@
+package http_test
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	invoiceHttp "github.com/soerjadi/exam/invoice/delivery/http"
+	"github.com/soerjadi/exam/invoice/mocks"
+	"github.com/soerjadi/exam/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func issued() *models.Invoice {
+	return &models.Invoice{
+		ID:       3,
+		Number:   "INV-2019-000003",
+		Year:     2019,
+		OrderID:  8,
+		BillTo:   &models.OrderShipping{Name: "Budi", Line1: "Jl. Merdeka (1)", City: "Jakarta", PostalCode: "10110", Country: "ID"},
+		Lines:    []*models.InvoiceLine{&models.InvoiceLine{ProductID: 2, SKU: "KMJ-01", Name: "Kemeja", Amount: 2, Price: 50000.0, Total: 100000.0}},
+		Taxes:    []*models.OrderTax{&models.OrderTax{Name: "VAT", Rate: 10, Amount: 10000.0}},
+		Subtotal: 100000.0,
+		Tax:      10000.0,
+		Total:    110000.0,
+		Issued:   time.Date(2019, 11, 27, 10, 0, 0, 0, time.UTC),
+	}
+}
+
+func get(t *testing.T, mockUsecase *mocks.Usecase, format string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest("GET", "/v1/order/8/invoice?format="+format, nil)
+	assert.NoError(t, err)
+	req = mux.SetURLVars(req, map[string]string{"id": "8"})
+
+	handler := invoiceHttp.InvoiceHandler{
+		InvoiceUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.GetByOrder(rec, req)
+	return rec
+}
+
+func TestGetPDF(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("GetByOrder", mock.Anything, int64(8)).Return(issued(), nil)
+
+	rec := get(t, mockUsecase, "")
+	body := rec.Body.Bytes()
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/pdf", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), "INV-2019-000003.pdf")
+	assert.True(t, bytes.HasPrefix(body, []byte("%PDF-1.4")))
+	assert.Contains(t, string(body), `Jl. Merdeka \(1\)`)
+
+	// every object is where the cross reference table say it is
+	xref := regexp.MustCompile(`startxref\n(\d+)`).FindSubmatch(body)
+	assert.NotNil(t, xref)
+	start, _ := strconv.Atoi(string(xref[1]))
+	offsets := regexp.MustCompile(`(\d{10}) 00000 n`).FindAllSubmatch(body[start:], -1)
+	assert.Len(t, offsets, 5)
+	for i, offset := range offsets {
+		at, _ := strconv.Atoi(string(offset[1]))
+		assert.True(t, bytes.HasPrefix(body[at:], []byte(fmt.Sprintf("%d 0 obj", i+1))))
+	}
+}
+
+func TestGetHTML(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("GetByOrder", mock.Anything, int64(8)).Return(issued(), nil)
+
+	rec := get(t, mockUsecase, "html")
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, rec.Body.String(), "Invoice INV-2019-000003")
+	assert.Contains(t, rec.Body.String(), "110000.00")
+}
+
+func TestGetNotPaid(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("GetByOrder", mock.Anything, int64(8)).Return(nil, models.ErrBadParamInput)
+
+	rec := get(t, mockUsecase, "pdf")
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Empty(t, rec.Header().Get("Content-Disposition"))
+}
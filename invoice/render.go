@@ -0,0 +1,115 @@
+package invoice
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+
+	"github.com/soerjadi/exam/models"
+)
+
+var htmlTemplate = template.Must(template.New("invoice").Funcs(template.FuncMap{
+	"money": money,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Invoice {{.Number}}</title>
+<style>
+body { font-family: sans-serif; font-size: 14px; }
+table { border-collapse: collapse; width: 100%; }
+th, td { padding: 4px 8px; border-bottom: 1px solid #ddd; text-align: left; }
+td.num, th.num { text-align: right; }
+</style>
+</head>
+<body>
+<h1>Invoice {{.Number}}</h1>
+<p>Order #{{.OrderID}}<br>Issued {{.Issued.Format "2006-01-02"}}</p>
+{{with .BillTo}}<p>Bill to:<br>{{.Name}}<br>{{.Line1}}<br>{{if .Line2}}{{.Line2}}<br>{{end}}{{.PostalCode}} {{.City}}{{if .Region}}, {{.Region}}{{end}}<br>{{.Country}}</p>{{end}}
+<table>
+<tr><th>SKU</th><th>Product</th><th class="num">Qty</th><th class="num">Price</th><th>Tax</th><th class="num">Total</th></tr>
+{{range .Lines}}<tr><td>{{.SKU}}</td><td>{{.Name}}</td><td class="num">{{.Amount}}</td><td class="num">{{money .Price}}</td><td>{{.TaxName}}</td><td class="num">{{money .Total}}</td></tr>
+{{end}}</table>
+<table>
+<tr><td>Subtotal</td><td class="num">{{money .Subtotal}}</td></tr>
+{{if .Discount}}<tr><td>Discount</td><td class="num">-{{money .Discount}}</td></tr>
+{{end}}{{if .Shipping}}<tr><td>Shipping</td><td class="num">{{money .Shipping}}</td></tr>
+{{end}}{{range .Taxes}}<tr><td>{{.Name}} {{.Rate}}%{{if $.TaxIncluded}} (included){{end}}</td><td class="num">{{money .Amount}}</td></tr>
+{{end}}<tr><th>Total</th><th class="num">{{money .Total}}</th></tr>
+</table>
+</body>
+</html>
+`))
+
+func money(v float64) string {
+	return fmt.Sprintf("%.2f", v)
+}
+
+// RenderHTML write invoice as an HTML page
+func RenderHTML(w io.Writer, invoice *models.Invoice) error {
+	return htmlTemplate.Execute(w, invoice)
+}
+
+// lines lay invoice out as lines of fixed width text
+func lines(invoice *models.Invoice) []string {
+	result := []string{
+		"INVOICE " + invoice.Number,
+		"",
+		fmt.Sprintf("Order #%d", invoice.OrderID),
+		"Issued " + invoice.Issued.Format("2006-01-02"),
+		"",
+	}
+
+	if to := invoice.BillTo; to != nil {
+		result = append(result, "Bill to:", "  "+to.Name, "  "+to.Line1)
+		if to.Line2 != "" {
+			result = append(result, "  "+to.Line2)
+		}
+
+		city := to.PostalCode + " " + to.City
+		if to.Region != "" {
+			city += ", " + to.Region
+		}
+
+		result = append(result, "  "+city, "  "+to.Country, "")
+	}
+
+	result = append(result, fmt.Sprintf("%-12s %-30s %5s %12s %14s", "SKU", "PRODUCT", "QTY", "PRICE", "TOTAL"))
+	for _, line := range invoice.Lines {
+		name := line.Name
+		if len(name) > 30 {
+			name = name[:30]
+		}
+
+		result = append(result, fmt.Sprintf("%-12s %-30s %5d %12s %14s", line.SKU, name, line.Amount, money(line.Price), money(line.Total)))
+	}
+
+	total := func(label string, v float64) string {
+		return fmt.Sprintf("%62s %14s", label, money(v))
+	}
+
+	result = append(result, "", total("Subtotal", invoice.Subtotal))
+	if invoice.Discount != 0 {
+		result = append(result, total("Discount", -invoice.Discount))
+	}
+
+	if invoice.Shipping != 0 {
+		result = append(result, total("Shipping", invoice.Shipping))
+	}
+
+	for _, tax := range invoice.Taxes {
+		label := fmt.Sprintf("%s %g%%", tax.Name, tax.Rate)
+		if invoice.TaxIncluded {
+			label += " (included)"
+		}
+
+		result = append(result, total(label, tax.Amount))
+	}
+
+	return append(result, total("Total", invoice.Total))
+}
+
+// RenderPDF write invoice as a PDF document
+func RenderPDF(w io.Writer, invoice *models.Invoice) error {
+	return writePDF(w, lines(invoice))
+}
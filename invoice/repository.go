@@ -0,0 +1,14 @@
+package invoice
+
+import (
+	"context"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// Repository represent the invoice repository contract, invoices are only
+// ever created
+type Repository interface {
+	GetByOrder(ctx context.Context, orderID int64) (*models.Invoice, error)
+	Create(ctx context.Context, invoice *models.Invoice) error
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/soerjadi/exam/database"
+	"github.com/soerjadi/exam/invoice"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/utils"
+)
+
+type pgInvoiceRepository struct {
+	Conn *sql.DB
+}
+
+var logger = utils.LogBuilder(true)
+
+// NewPGInvoiceRepository is bridge to create an object from invoice.Repository interface
+func NewPGInvoiceRepository(Conn *sql.DB) invoice.Repository {
+	return &pgInvoiceRepository{Conn}
+}
+
+func (p *pgInvoiceRepository) GetByOrder(ctx context.Context, orderID int64) (*models.Invoice, error) {
+	query := `SELECT id, number, snapshot FROM invoices WHERE order_id = ?`
+
+	var id int64
+	var number string
+	var snapshot []byte
+
+	err := database.ExecutorFrom(ctx, p.Conn).QueryRowContext(ctx, query, orderID).Scan(&id, &number, &snapshot)
+	if err == sql.ErrNoRows {
+		return nil, models.ErrNotFound
+	}
+
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	result := new(models.Invoice)
+	if err = json.Unmarshal(snapshot, result); err != nil {
+		return nil, err
+	}
+
+	result.ID = id
+	result.Number = number
+	return result, nil
+}
+
+// Create number the invoice and store its snapshot. The number is taken from
+// the sequence of the year in the same transaction, the row lock keep
+// concurrent invoices in line and a rolled back invoice give its number
+// back, so numbers run without gap.
+func (p *pgInvoiceRepository) Create(ctx context.Context, inv *models.Invoice) error {
+	return database.RunInTx(ctx, p.Conn, func(tx *sql.Tx) error {
+		var seq int64
+
+		err := tx.QueryRowContext(ctx, `INSERT INTO invoice_sequences(year, last) VALUES(?, 1)
+			ON CONFLICT (year) DO UPDATE SET last = invoice_sequences.last + 1 RETURNING last`, inv.Year).Scan(&seq)
+		if err != nil {
+			return err
+		}
+
+		inv.Number = invoice.Number(inv.Year, seq)
+
+		snapshot, err := json.Marshal(inv)
+		if err != nil {
+			return err
+		}
+
+		query := `INSERT INTO invoices(number, year, order_id, customer_id, total, issued, snapshot)
+			VALUES(?, ?, ?, ?, ?, ?, ?) returning id`
+
+		stmt, err := tx.PrepareContext(ctx, query)
+		if err != nil {
+			return err
+		}
+
+		result, err := stmt.ExecContext(ctx, inv.Number, inv.Year, inv.OrderID, inv.CustomerID, inv.Total, inv.Issued, snapshot)
+		if err != nil {
+			return err
+		}
+
+		inv.ID, err = result.LastInsertId()
+		return err
+	})
+}
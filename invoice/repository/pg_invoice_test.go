@@ -0,0 +1,82 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/soerjadi/exam/invoice/repository"
+	"github.com/soerjadi/exam/models"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v3"
+)
+
+func TestGetByOrder(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "number", "snapshot"}).
+		AddRow(3, "INV-2019-000003", []byte(`{"order_id":8,"lines":[{"product_id":2,"amount":2,"price":50000,"total":100000}],"total":100000}`))
+
+	mock.ExpectQuery("SELECT id, number, snapshot FROM invoices WHERE order_id = \\?").WithArgs(int64(8)).WillReturnRows(rows)
+
+	r := repository.NewPGInvoiceRepository(db)
+	inv, err := r.GetByOrder(context.TODO(), int64(8))
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), inv.ID)
+	assert.Equal(t, "INV-2019-000003", inv.Number)
+	assert.Len(t, inv.Lines, 1)
+	assert.Equal(t, 100000.0, inv.Total)
+}
+
+func TestCreate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	issued := time.Date(2019, 11, 27, 10, 0, 0, 0, time.UTC)
+	inv := &models.Invoice{Year: 2019, OrderID: 8, CustomerID: null.IntFrom(4), Total: 100000.0, Issued: issued}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO invoice_sequences\\(year, last\\) (.+) ON CONFLICT \\(year\\) DO UPDATE").WithArgs(2019).
+		WillReturnRows(sqlmock.NewRows([]string{"last"}).AddRow(12))
+	mock.ExpectPrepare("INSERT INTO invoices\\(number, year, order_id, customer_id, total, issued, snapshot\\)").ExpectExec().
+		WithArgs("INV-2019-000012", 2019, int64(8), null.IntFrom(4), 100000.0, issued, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(5, 1))
+	mock.ExpectCommit()
+
+	r := repository.NewPGInvoiceRepository(db)
+	err = r.Create(context.TODO(), inv)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), inv.ID)
+	assert.Equal(t, "INV-2019-000012", inv.Number)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateRollback(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	inv := &models.Invoice{Year: 2019, OrderID: 8, Issued: time.Now()}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO invoice_sequences").WithArgs(2019).
+		WillReturnRows(sqlmock.NewRows([]string{"last"}).AddRow(13))
+	mock.ExpectPrepare("INSERT INTO invoices").ExpectExec().WillReturnError(assert.AnError)
+	// the number taken is given back with the rollback
+	mock.ExpectRollback()
+
+	r := repository.NewPGInvoiceRepository(db)
+	err = r.Create(context.TODO(), inv)
+
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,69 @@
+package invoice
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// round to the cent
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// Number format the number of the seq-th invoice issued in year
+func Number(year int, seq int64) string {
+	return fmt.Sprintf("INV-%d-%06d", year, seq)
+}
+
+// Snapshot copy what was charged on order into an invoice issued at issued,
+// with the name and SKU products have at that time. Products missing from
+// the catalog are listed by id only.
+func Snapshot(order *models.Order, products map[int64]*models.Product, issued time.Time) *models.Invoice {
+	invoice := &models.Invoice{
+		Year:        issued.Year(),
+		OrderID:     order.ID,
+		CustomerID:  order.CustomerID,
+		BillTo:      order.Shipping,
+		Lines:       make([]*models.InvoiceLine, 0, len(order.Items)),
+		Taxes:       order.Taxes,
+		Discount:    order.Discount,
+		Tax:         order.Tax,
+		TaxIncluded: order.TaxIncluded,
+		Total:       order.Total,
+		Issued:      issued,
+	}
+
+	if invoice.Taxes == nil {
+		invoice.Taxes = make([]*models.OrderTax, 0)
+	}
+
+	if order.Shipping != nil {
+		invoice.Shipping = order.Shipping.Cost
+	}
+
+	for _, item := range order.Items {
+		line := &models.InvoiceLine{
+			ProductID: item.ProductID,
+			Amount:    item.Amount,
+			Price:     item.Price,
+			TaxName:   item.TaxName,
+			TaxRate:   item.TaxRate,
+			Tax:       item.Tax,
+			Total:     round(item.Price * float64(item.Amount)),
+		}
+
+		if product, ok := products[item.ProductID]; ok {
+			line.SKU = product.SKU
+			line.Name = product.Name
+		}
+
+		invoice.Lines = append(invoice.Lines, line)
+		invoice.Subtotal += line.Total
+	}
+
+	invoice.Subtotal = round(invoice.Subtotal)
+	return invoice
+}
@@ -0,0 +1,13 @@
+package invoice
+
+import (
+	"context"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// Usecase represent the invoice usecase
+type Usecase interface {
+	GetByOrder(ctx context.Context, orderID int64) (*models.Invoice, error)
+	Issue(ctx context.Context, orderID int64) (*models.Invoice, error)
+}
@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/soerjadi/exam/audit"
+	"github.com/soerjadi/exam/auth"
+	"github.com/soerjadi/exam/invoice"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/order"
+	"github.com/soerjadi/exam/product"
+	"github.com/soerjadi/exam/utils"
+)
+
+type invoiceUsecase struct {
+	repo           invoice.Repository
+	orders         order.Usecase
+	products       product.Usecase
+	audit          audit.Usecase
+	contextTimeout time.Duration
+}
+
+var logger = utils.LogBuilder(true)
+
+// NewInvoiceUsecase will create object that represent of invoice.Usecase
+// interface
+func NewInvoiceUsecase(r invoice.Repository, o order.Usecase, p product.Usecase, a audit.Usecase, timeout time.Duration) invoice.Usecase {
+	return &invoiceUsecase{
+		repo:           r,
+		orders:         o,
+		products:       p,
+		audit:          a,
+		contextTimeout: timeout,
+	}
+}
+
+// owned return the order of id when it is visible to the caller, orders of
+// a customer are only visible to that customer
+func (i *invoiceUsecase) owned(ctx context.Context, id int64) (*models.Order, error) {
+	ord, err := i.orders.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if principal, ok := auth.PrincipalFrom(ctx); ok && principal.Allowed(auth.OrderRead) {
+		return ord, nil
+	}
+
+	customerID, ok := auth.CustomerID(ctx)
+	if !ok {
+		return nil, models.ErrUnauthorized
+	}
+
+	if !ord.CustomerID.Valid || ord.CustomerID.Int64 != customerID {
+		return nil, models.ErrNotFound
+	}
+
+	return ord, nil
+}
+
+// GetByOrder return the invoice of an order visible to the caller,
+// models.ErrNotFound until it is issued
+func (i *invoiceUsecase) GetByOrder(ctx context.Context, orderID int64) (*models.Invoice, error) {
+	ctx, cancel := context.WithTimeout(ctx, i.contextTimeout)
+	defer cancel()
+
+	if _, err := i.owned(ctx, orderID); err != nil {
+		return nil, err
+	}
+
+	return i.repo.GetByOrder(ctx, orderID)
+}
+
+// Issue the invoice of a paid order, the one already issued when there is
+// one. Lines carry the name and SKU products have at that time, products
+// already in the trash included.
+func (i *invoiceUsecase) Issue(ctx context.Context, orderID int64) (*models.Invoice, error) {
+	ctx, cancel := context.WithTimeout(ctx, i.contextTimeout)
+	defer cancel()
+
+	inv, err := i.repo.GetByOrder(ctx, orderID)
+	if err != models.ErrNotFound {
+		return inv, err
+	}
+
+	ord, err := i.orders.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if ord.Status == models.OrderPending || ord.Status == models.OrderCancelled {
+		return nil, models.ErrBadParamInput
+	}
+
+	products := make(map[int64]*models.Product, len(ord.Items))
+	for _, item := range ord.Items {
+		product, err := i.products.GetAnyByID(ctx, item.ProductID)
+		if err == models.ErrNotFound {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		products[item.ProductID] = product
+	}
+
+	inv = invoice.Snapshot(ord, products, time.Now())
+	if err = i.repo.Create(ctx, inv); err != nil {
+		// another capture may have issued it first, an order has one invoice
+		if existing, getErr := i.repo.GetByOrder(ctx, orderID); getErr == nil {
+			return existing, nil
+		}
+
+		return nil, err
+	}
+
+	if err = i.audit.Record(ctx, models.AuditInvoice, inv.ID, models.AuditCreate, nil, inv); err != nil {
+		logger.Error(err)
+	}
+
+	return inv, nil
+}
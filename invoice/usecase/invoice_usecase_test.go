@@ -0,0 +1,162 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/soerjadi/exam/audit/audittest"
+	"github.com/soerjadi/exam/auth"
+	"github.com/soerjadi/exam/invoice/mocks"
+	"github.com/soerjadi/exam/invoice/usecase"
+	"github.com/soerjadi/exam/models"
+	orderMocks "github.com/soerjadi/exam/order/mocks"
+	pMocks "github.com/soerjadi/exam/product/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gopkg.in/guregu/null.v3"
+)
+
+func paidOrder(status int) *models.Order {
+	return &models.Order{
+		ID:         8,
+		CustomerID: null.IntFrom(4),
+		Status:     status,
+		Discount:   10000.0,
+		Tax:        19000.0,
+		Total:      224000.0,
+		Items: []*models.OrderItem{
+			&models.OrderItem{ID: 11, OrderID: 8, ProductID: 2, Amount: 2, Price: 50000.0, TaxName: "VAT", TaxRate: 10, Tax: 9500.0},
+			&models.OrderItem{ID: 12, OrderID: 8, ProductID: 3, Amount: 1, Price: 100000.0, TaxName: "VAT", TaxRate: 10, Tax: 9500.0},
+		},
+		Taxes:    []*models.OrderTax{&models.OrderTax{Name: "VAT", Rate: 10, Amount: 19000.0}},
+		Shipping: &models.OrderShipping{Name: "Budi", City: "Jakarta", Country: "ID", Cost: 15000.0},
+	}
+}
+
+func customer(id int64) context.Context {
+	return auth.WithClaims(context.TODO(), &auth.Claims{Subject: id})
+}
+
+func TestGetByOrder(t *testing.T) {
+	t.Run("issued", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockOrders := new(orderMocks.Usecase)
+
+		mockOrders.On("GetByID", mock.Anything, int64(8)).Return(paidOrder(models.OrderCompleted), nil).Once()
+		mockRepo.On("GetByOrder", mock.Anything, int64(8)).
+			Return(&models.Invoice{ID: 3, Number: "INV-2019-000003", OrderID: 8}, nil).Once()
+
+		u := usecase.NewInvoiceUsecase(mockRepo, mockOrders, new(pMocks.Usecase), audittest.NewUsecase(), time.Second*2)
+		inv, err := u.GetByOrder(customer(4), int64(8))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "INV-2019-000003", inv.Number)
+	})
+
+	t.Run("not issued yet", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockOrders := new(orderMocks.Usecase)
+
+		mockOrders.On("GetByID", mock.Anything, int64(8)).Return(paidOrder(models.OrderProccessed), nil).Once()
+		mockRepo.On("GetByOrder", mock.Anything, int64(8)).Return(nil, models.ErrNotFound).Once()
+
+		mockAudit := audittest.NewUsecase()
+		u := usecase.NewInvoiceUsecase(mockRepo, mockOrders, new(pMocks.Usecase), mockAudit, time.Second*2)
+		_, err := u.GetByOrder(customer(4), int64(8))
+
+		// reading never issue one
+		assert.Equal(t, models.ErrNotFound, err)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		audittest.AssertNothingRecorded(t, mockAudit)
+	})
+
+	t.Run("order of another customer", func(t *testing.T) {
+		mockOrders := new(orderMocks.Usecase)
+		mockOrders.On("GetByID", mock.Anything, int64(8)).Return(paidOrder(models.OrderProccessed), nil).Once()
+
+		u := usecase.NewInvoiceUsecase(new(mocks.Repository), mockOrders, new(pMocks.Usecase), audittest.NewUsecase(), time.Second*2)
+		_, err := u.GetByOrder(customer(5), int64(8))
+
+		assert.Equal(t, models.ErrNotFound, err)
+	})
+}
+
+func TestIssue(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockOrders := new(orderMocks.Usecase)
+		mockProducts := new(pMocks.Usecase)
+
+		mockRepo.On("GetByOrder", mock.Anything, int64(8)).Return(nil, models.ErrNotFound).Once()
+		mockOrders.On("GetByID", mock.Anything, int64(8)).Return(paidOrder(models.OrderProccessed), nil).Once()
+		mockProducts.On("GetAnyByID", mock.Anything, int64(2)).Return(&models.Product{ID: 2, Name: "Kemeja", SKU: "KMJ-01"}, nil).Once()
+		// already in the trash, still named on the invoice
+		mockProducts.On("GetAnyByID", mock.Anything, int64(3)).
+			Return(&models.Product{ID: 3, Name: "Celana", SKU: "CLN-01", DeletedAt: null.TimeFrom(time.Now())}, nil).Once()
+		mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(i *models.Invoice) bool {
+			return i.OrderID == 8 && i.Year == time.Now().Year() && i.Subtotal == 200000.0 && i.Shipping == 15000.0 &&
+				i.Total == 224000.0 && i.Lines[0].Name == "Kemeja" && i.Lines[1].Name == "Celana" && i.Lines[1].Total == 100000.0
+		})).Return(nil).Once()
+
+		mockAudit := audittest.NewUsecase()
+		u := usecase.NewInvoiceUsecase(mockRepo, mockOrders, mockProducts, mockAudit, time.Second*2)
+		inv, err := u.Issue(context.TODO(), int64(8))
+
+		assert.NoError(t, err)
+		assert.Len(t, inv.Taxes, 1)
+		mockRepo.AssertExpectations(t)
+		mockProducts.AssertExpectations(t)
+		audittest.AssertRecorded(t, mockAudit, models.AuditInvoice, inv.ID, models.AuditCreate, nil, inv)
+	})
+
+	t.Run("already issued", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockRepo.On("GetByOrder", mock.Anything, int64(8)).
+			Return(&models.Invoice{ID: 3, Number: "INV-2019-000003", OrderID: 8}, nil).Once()
+
+		mockOrders := new(orderMocks.Usecase)
+		u := usecase.NewInvoiceUsecase(mockRepo, mockOrders, new(pMocks.Usecase), audittest.NewUsecase(), time.Second*2)
+		inv, err := u.Issue(context.TODO(), int64(8))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "INV-2019-000003", inv.Number)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		mockOrders.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("order not paid", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockOrders := new(orderMocks.Usecase)
+
+		mockRepo.On("GetByOrder", mock.Anything, int64(8)).Return(nil, models.ErrNotFound).Once()
+		mockOrders.On("GetByID", mock.Anything, int64(8)).Return(paidOrder(models.OrderPending), nil).Once()
+
+		u := usecase.NewInvoiceUsecase(mockRepo, mockOrders, new(pMocks.Usecase), audittest.NewUsecase(), time.Second*2)
+		_, err := u.Issue(context.TODO(), int64(8))
+
+		assert.Equal(t, models.ErrBadParamInput, err)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("issued meanwhile", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockOrders := new(orderMocks.Usecase)
+		mockProducts := new(pMocks.Usecase)
+
+		mockRepo.On("GetByOrder", mock.Anything, int64(8)).Return(nil, models.ErrNotFound).Once()
+		mockOrders.On("GetByID", mock.Anything, int64(8)).Return(paidOrder(models.OrderProccessed), nil).Once()
+		mockProducts.On("GetAnyByID", mock.Anything, mock.AnythingOfType("int64")).Return(nil, models.ErrNotFound)
+		mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Invoice")).Return(models.ErrConflict).Once()
+		mockRepo.On("GetByOrder", mock.Anything, int64(8)).
+			Return(&models.Invoice{ID: 3, Number: "INV-2019-000003", OrderID: 8}, nil).Once()
+
+		mockAudit := audittest.NewUsecase()
+		u := usecase.NewInvoiceUsecase(mockRepo, mockOrders, mockProducts, mockAudit, time.Second*2)
+		inv, err := u.Issue(context.TODO(), int64(8))
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), inv.ID)
+		audittest.AssertNothingRecorded(t, mockAudit)
+	})
+}
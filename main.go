@@ -13,6 +13,7 @@ import (
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 	"github.com/soerjadi/exam/middleware"
+	"github.com/soerjadi/exam/scheduler"
 	"github.com/soerjadi/exam/utils"
 )
 
@@ -57,22 +58,30 @@ func init() {
 func main() {
 	r := mux.NewRouter().StrictSlash(true)
 	midl := middleware.InitMiddleware()
+	r.Use(midl.RequestIDMiddleware)
 	r.Use(midl.LoggingMiddleware)
 	r.Use(handlers.RecoveryHandler(handlers.PrintRecoveryStack(enablePrintRecovery)))
 
-	routers := RegisterRouter(r)
+	sched := scheduler.New()
+	routers := RegisterRouter(r, midl, sched)
 
 	allowedOrigin := handlers.AllowedOrigins([]string{"*"})
-	allowedHeaders := handlers.AllowedHeaders([]string{"X-Access-Token", "Content-Type"})
+	allowedHeaders := handlers.AllowedHeaders([]string{"X-Access-Token", "X-API-Key", "X-Request-ID", "Content-Type", "If-Match", "If-None-Match",
+		"Idempotency-Key"})
 	allowedMethods := handlers.AllowedMethods([]string{"GET", "POST", "OPTIONS"})
+	exposedHeaders := handlers.ExposedHeaders([]string{"ETag", "X-Request-ID", "Retry-After",
+		"X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", "Idempotent-Replayed", "Location"})
 
 	server := &http.Server{
-		Handler:      handlers.CORS(allowedOrigin, allowedHeaders, allowedMethods)(routers),
+		Handler:      handlers.CORS(allowedOrigin, allowedHeaders, allowedMethods, exposedHeaders)(routers),
 		Addr:         address,
 		WriteTimeout: time.Duration(15) * time.Second,
 		ReadTimeout:  time.Duration(15) * time.Second,
 	}
 
+	// Background jobs (e.g. purging the trash) run next to the server.
+	sched.Start()
+
 	// Run our server in a goroutine so that it doesn't block.
 	go func() {
 		if err := server.ListenAndServe(); err != nil {
@@ -94,6 +103,7 @@ func main() {
 	// Doesn't block if no connections, but will otherwise wait
 	// until the timeout deadline.
 	server.Shutdown(ctx)
+	sched.Stop()
 	// Optionally, you could run srv.Shutdown in a goroutine and block on
 	// <-ctx.Done() if your application should wait for other services
 	// to finalize based on context cancellation.
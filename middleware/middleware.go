@@ -1,15 +1,52 @@
 package middleware
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io/ioutil"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/gorilla/mux"
+	"github.com/soerjadi/exam/auth"
+	"github.com/soerjadi/exam/idempotency"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/ratelimit"
 	"github.com/soerjadi/exam/utils"
 )
 
 // MuxMiddleware represent the data-struct for middleware
 type MuxMiddleware struct {
-	// todo(*): code here
+	// Signer check access tokens, requests are left anonymous while it is nil
+	Signer *auth.Signer
+	// Revocations deny access tokens revoked before they expired, optional
+	Revocations auth.Revocations
+	// Keys resolve API keys, they are not accepted while it is nil
+	Keys auth.KeyAuthenticator
+	// Permissions hold the permission needed by a route, keyed by method and
+	// path template e.g. "POST /v1/product/add". Other routes are public.
+	Permissions map[string]auth.Permission
+	// RateLimits limit requests by group of routes, the first policy whose
+	// prefix match the path apply. Policies sharing a name share buckets.
+	RateLimits []ratelimit.Policy
+	// RateStore keep the buckets, requests are not limited while it is nil
+	RateStore ratelimit.Store
+	// TrustProxy take the client IP from X-Forwarded-For, only safe behind a
+	// proxy overwriting it
+	TrustProxy bool
+	// Idempotency keep the responses of POST requests made with an
+	// Idempotency-Key, keys are ignored while it is nil
+	Idempotency idempotency.Usecase
+	// Streams list the event stream routes accepting stream tokens, keyed by
+	// method and path template e.g. "GET /v1/order/stream"
+	Streams map[string]bool
 }
 
 var (
@@ -30,6 +67,377 @@ func (m *MuxMiddleware) LoggingMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// RequestIDMiddleware tag every request with an ID, taken from X-Request-ID
+// when the client send one, so log and audit entries can be correlated
+func (m *MuxMiddleware) RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get("X-Request-ID")
+		if requestID == "" {
+			requestID = utils.RandString(16)
+		}
+
+		w.Header().Set("X-Request-ID", requestID)
+		ctx := utils.WithRequestID(r.Context(), requestID)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// streamToken return the stream token of r, it is only looked for on GET
+// requests for an event stream route of Streams
+func (m *MuxMiddleware) streamToken(r *http.Request) string {
+	if r.Method != http.MethodGet || !m.Streams[routeKey(r)] {
+		return ""
+	}
+
+	if !strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		return ""
+	}
+
+	if token := r.URL.Query().Get(auth.StreamTokenParam); token != "" {
+		return token
+	}
+
+	if cookie, err := r.Cookie(auth.StreamTokenParam); err == nil {
+		return cookie.Value
+	}
+
+	return ""
+}
+
+// principal resolve who r is made by from its X-API-Key or X-Access-Token
+// header, or from its stream token, it is nil for a request carrying none
+func (m *MuxMiddleware) principal(r *http.Request) (auth.Principal, error) {
+	if key := r.Header.Get("X-API-Key"); key != "" && m.Keys != nil {
+		principal, err := m.Keys.Authenticate(r.Context(), key)
+		if err != nil {
+			return nil, err
+		}
+
+		return principal, nil
+	}
+
+	token, scope := r.Header.Get("X-Access-Token"), ""
+	if token == "" {
+		token, scope = m.streamToken(r), auth.ScopeStream
+	}
+
+	if token == "" || m.Signer == nil {
+		return nil, nil
+	}
+
+	claims, err := m.Signer.Verify(token, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	// each kind of token is only accepted where it is meant to be
+	if claims.Scope != scope {
+		return nil, auth.ErrInvalidToken
+	}
+
+	if m.Revocations != nil {
+		revoked, err := m.Revocations.IsRevoked(r.Context(), claims.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		if revoked {
+			return nil, auth.ErrInvalidToken
+		}
+	}
+
+	return claims, nil
+}
+
+// AuthMiddleware identify the principal of the request, a customer or an API
+// key. A request without credentials stay anonymous, one with invalid
+// credentials is refused.
+func (m *MuxMiddleware) AuthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, err := m.principal(r)
+
+		switch err {
+		case nil:
+		case auth.ErrInvalidToken, models.ErrUnauthorized:
+			utils.Error(w, http.StatusUnauthorized, err.Error())
+			return
+		default:
+			logger.Error(err)
+			utils.Error(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		if principal == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := auth.WithPrincipal(r.Context(), principal)
+		ctx = utils.WithActor(ctx, principal.Actor())
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// routeKey return method and path template of the route matching r
+func routeKey(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return ""
+	}
+
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return ""
+	}
+
+	return r.Method + " " + template
+}
+
+// PermissionMiddleware check the principal is allowed the permission of the
+// route, answering 401 to anonymous requests and 403 when the roles do not
+// grant it. It must run after AuthMiddleware.
+func (m *MuxMiddleware) PermissionMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		permission, ok := m.Permissions[routeKey(r)]
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		principal, ok := auth.PrincipalFrom(r.Context())
+		if !ok {
+			utils.Error(w, http.StatusUnauthorized, models.ErrUnauthorized.Error())
+			return
+		}
+
+		if !principal.Allowed(permission) {
+			utils.Error(w, http.StatusForbidden, models.ErrForbidden.Error())
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientIP return the IP the request come from
+func (m *MuxMiddleware) clientIP(r *http.Request) string {
+	if m.TrustProxy {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
+
+// rateClients list the buckets r take a token from for the limits of policy
+// without touching the database. Only an access token whose signature and
+// scope check out get a bucket of its own, any other request is counted
+// against its IP. An API key can not be checked before AuthMiddleware look it
+// up, so on top of the bucket of its hash every key sent from an IP share one
+// more: made up keys do not get a fresh bucket each.
+func (m *MuxMiddleware) rateClients(r *http.Request, policy ratelimit.Policy) map[string]ratelimit.Limit {
+	ip := m.clientIP(r)
+	clients := make(map[string]ratelimit.Limit)
+	if policy.AlwaysIP {
+		clients["ip:"+ip] = policy.IP
+	}
+
+	if key := r.Header.Get("X-API-Key"); key != "" && m.Keys != nil {
+		sum := sha256.Sum256([]byte(key))
+		clients["apikey:"+hex.EncodeToString(sum[:8])] = policy.Key
+		clients["apikeys:"+ip] = policy.Key
+		return clients
+	}
+
+	if token := r.Header.Get("X-Access-Token"); token != "" && m.Signer != nil {
+		if claims, err := m.Signer.Verify(token, time.Now()); err == nil && claims.Scope == "" {
+			clients[claims.Actor()] = policy.Customer
+			return clients
+		}
+	}
+
+	clients["ip:"+ip] = policy.IP
+	return clients
+}
+
+// RateLimitMiddleware take a token from the bucket of the client for the
+// route group, answering 429 once it is empty. Customers and API keys have
+// their own bucket, anonymous clients and invalid tokens share one per IP. It must run before
+// AuthMiddleware so throttled requests never reach the database.
+func (m *MuxMiddleware) RateLimitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		policy, ok := ratelimit.Match(m.RateLimits, r.URL.Path)
+		if !ok || m.RateStore == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		clients := m.rateClients(r, policy)
+
+		var result *ratelimit.Result
+		for client, limit := range clients {
+			if limit.Unlimited() {
+				continue
+			}
+
+			taken, err := m.RateStore.Take(r.Context(), policy.Name+"|"+client, limit, time.Now())
+			if err != nil {
+				// a broken store must not take the API down with it
+				logger.Error(err)
+				continue
+			}
+
+			// the client see the bucket closest to refusing it
+			if result == nil || !taken.Allowed || (result.Allowed && taken.Remaining < result.Remaining) {
+				result = &taken
+			}
+		}
+
+		if result == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		w.Header().Set("X-RateLimit-Reset", ceilSeconds(result.Reset))
+
+		if !result.Allowed {
+			w.Header().Set("Retry-After", ceilSeconds(result.RetryAfter))
+			utils.Error(w, http.StatusTooManyRequests, models.ErrTooManyRequests.Error())
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// recorder keep a copy of the response written through it
+type recorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// IdempotencyMiddleware run a POST request made with an Idempotency-Key once.
+// Retries with the same key get the stored response back, a key reused for
+// another request is refused with 422 and one whose first request is still
+// running with 409. Server errors and panics are not stored so the request
+// can be retried. Keys are scoped by principal, it must run after
+// AuthMiddleware.
+func (m *MuxMiddleware) IdempotencyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotency.Header)
+		if key == "" || r.Method != http.MethodPost || m.Idempotency == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if len(key) > idempotency.MaxKeyLength {
+			utils.Error(w, http.StatusBadRequest, models.ErrBadParamInput.Error())
+			return
+		}
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			utils.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		scope := "anonymous"
+		if principal, ok := auth.PrincipalFrom(r.Context()); ok {
+			scope = principal.Actor()
+		}
+
+		key = scope + "|" + key
+		fingerprint := idempotency.Fingerprint(r.Method, r.URL.Path, body)
+
+		record, err := m.Idempotency.Begin(r.Context(), key, fingerprint)
+		if err != nil {
+			// running it without the guarantee could make the duplicate the
+			// client is trying to avoid
+			logger.Error(err)
+			utils.Error(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		if record != nil {
+			switch {
+			case record.Fingerprint != fingerprint:
+				utils.Error(w, http.StatusUnprocessableEntity, models.ErrIdempotencyMismatch.Error())
+			case !record.Completed():
+				utils.Error(w, http.StatusConflict, models.ErrIdempotencyInProgress.Error())
+			default:
+				for name, values := range record.Header {
+					w.Header()[name] = values
+				}
+				w.Header().Set(idempotency.ReplayedHeader, "true")
+				w.WriteHeader(record.Status)
+				_, _ = w.Write(record.Body)
+			}
+
+			return
+		}
+
+		// the client may be gone, which is when the response matter most
+		ctx := context.Background()
+
+		defer func() {
+			if p := recover(); p != nil {
+				if err := m.Idempotency.Release(ctx, key); err != nil {
+					logger.Error(err)
+				}
+
+				// left to the recovery handler
+				panic(p)
+			}
+		}()
+
+		rec := &recorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+
+		if rec.status == 0 || rec.status >= http.StatusInternalServerError {
+			err = m.Idempotency.Release(ctx, key)
+		} else {
+			err = m.Idempotency.Complete(ctx, key, rec.status, w.Header(), rec.body.Bytes())
+		}
+
+		if err != nil {
+			logger.Error(err)
+		}
+	})
+}
+
 // InitMiddleware initialize middleware
 func InitMiddleware() *MuxMiddleware {
 	return &MuxMiddleware{}
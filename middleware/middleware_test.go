@@ -0,0 +1,387 @@
+package middleware_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/soerjadi/exam/auth"
+	"github.com/soerjadi/exam/auth/mocks"
+	"github.com/soerjadi/exam/idempotency"
+	idemMocks "github.com/soerjadi/exam/idempotency/mocks"
+	"github.com/soerjadi/exam/middleware"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/ratelimit"
+	"github.com/soerjadi/exam/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newRouter serve /v1/order/update, needing order:write, and the public
+// /v1/product/list and /v1/customer/login behind m
+func newRouter(m *middleware.MuxMiddleware) *mux.Router {
+	m.Permissions = map[string]auth.Permission{
+		"POST /v1/order/update": auth.OrderWrite,
+	}
+
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		actor := ""
+		if principal, found := auth.PrincipalFrom(r.Context()); found {
+			actor = principal.Actor()
+		}
+
+		utils.JSON(w, http.StatusOK, actor)
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/v1/order/update", ok).Methods("POST")
+	router.HandleFunc("/v1/product/list", ok).Methods("GET")
+	router.HandleFunc("/v1/customer/login", ok).Methods("POST")
+	router.Use(m.RateLimitMiddleware)
+	router.Use(m.AuthMiddleware)
+	router.Use(m.PermissionMiddleware)
+
+	return router
+}
+
+func serve(router http.Handler, method string, path string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAuthPermission(t *testing.T) {
+	signer := auth.NewSigner("s3cret", 15*time.Minute)
+	token := func(id string, roles ...string) string {
+		signed, err := signer.Sign(auth.Claims{ID: id, Subject: 4, Roles: roles}, time.Now())
+		assert.NoError(t, err)
+		return signed
+	}
+
+	revocations := new(mocks.Revocations)
+	revocations.On("IsRevoked", mock.Anything, "revoked").Return(true, nil)
+	revocations.On("IsRevoked", mock.Anything, "broken").Return(false, errors.New("Unexpected error"))
+	revocations.On("IsRevoked", mock.Anything, mock.AnythingOfType("string")).Return(false, nil)
+
+	keys := new(mocks.KeyAuthenticator)
+	keys.On("Authenticate", mock.Anything, "key_orders").
+		Return(&auth.KeyPrincipal{ID: 3, Permissions: []auth.Permission{auth.OrderWrite}}, nil)
+	keys.On("Authenticate", mock.Anything, "key_catalog").
+		Return(&auth.KeyPrincipal{ID: 5, Permissions: []auth.Permission{auth.CatalogWrite}}, nil)
+	keys.On("Authenticate", mock.Anything, "key_unknown").Return(nil, models.ErrUnauthorized)
+
+	router := newRouter(&middleware.MuxMiddleware{Signer: signer, Revocations: revocations, Keys: keys})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		header http.Header
+		status int
+	}{
+		{"public anonymous", "GET", "/v1/product/list", nil, http.StatusOK},
+		{"public with invalid token", "GET", "/v1/product/list", http.Header{"X-Access-Token": {"not-a-token"}}, http.StatusUnauthorized},
+		{"anonymous", "POST", "/v1/order/update", nil, http.StatusUnauthorized},
+		{"invalid token", "POST", "/v1/order/update", http.Header{"X-Access-Token": {token("a", auth.RoleAdmin) + "x"}}, http.StatusUnauthorized},
+		{"revoked token", "POST", "/v1/order/update", http.Header{"X-Access-Token": {token("revoked", auth.RoleAdmin)}}, http.StatusUnauthorized},
+		{"revocations unavailable", "POST", "/v1/order/update", http.Header{"X-Access-Token": {token("broken", auth.RoleAdmin)}}, http.StatusInternalServerError},
+		{"role without permission", "POST", "/v1/order/update", http.Header{"X-Access-Token": {token("b", auth.RoleViewer)}}, http.StatusForbidden},
+		{"customer", "POST", "/v1/order/update", http.Header{"X-Access-Token": {token("c")}}, http.StatusForbidden},
+		{"role with permission", "POST", "/v1/order/update", http.Header{"X-Access-Token": {token("d", auth.RoleOrderManager)}}, http.StatusOK},
+		{"key scoped", "POST", "/v1/order/update", http.Header{"X-Api-Key": {"key_orders"}}, http.StatusOK},
+		{"key of another scope", "POST", "/v1/order/update", http.Header{"X-Api-Key": {"key_catalog"}}, http.StatusForbidden},
+		{"unknown key", "POST", "/v1/order/update", http.Header{"X-Api-Key": {"key_unknown"}}, http.StatusUnauthorized},
+	}
+
+	for _, test := range tests {
+		rec := serve(router, test.method, test.path, test.header)
+		assert.Equal(t, test.status, rec.Code, test.name)
+	}
+}
+
+func TestAuthActor(t *testing.T) {
+	keys := new(mocks.KeyAuthenticator)
+	keys.On("Authenticate", mock.Anything, "key_orders").
+		Return(&auth.KeyPrincipal{ID: 3, Permissions: []auth.Permission{auth.OrderWrite}}, nil).Once()
+
+	router := newRouter(&middleware.MuxMiddleware{Keys: keys})
+	rec := serve(router, "POST", "/v1/order/update", http.Header{"X-Api-Key": {"key_orders"}})
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"result":"apikey:3"`)
+	keys.AssertExpectations(t)
+}
+
+func TestAuthStreamToken(t *testing.T) {
+	signer := auth.NewSigner("s3cret", 15*time.Minute)
+	sign := func(scope string) string {
+		signed, err := signer.Sign(auth.Claims{Subject: 4, Roles: []string{auth.RoleViewer}, Scope: scope}, time.Now())
+		assert.NoError(t, err)
+		return signed
+	}
+
+	m := &middleware.MuxMiddleware{
+		Signer: signer,
+		Permissions: map[string]auth.Permission{
+			"GET /v1/order/stream":  auth.OrderRead,
+			"GET /v1/order/list":    auth.OrderRead,
+			"POST /v1/order/stream": auth.OrderRead,
+		},
+		Streams: map[string]bool{"GET /v1/order/stream": true},
+	}
+
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		utils.JSON(w, http.StatusOK, "streaming")
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/v1/order/stream", ok).Methods("GET", "POST")
+	router.HandleFunc("/v1/order/list", ok).Methods("GET")
+	router.Use(m.AuthMiddleware)
+	router.Use(m.PermissionMiddleware)
+
+	stream := sign(auth.ScopeStream)
+	events := http.Header{"Accept": {"text/event-stream"}}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		header http.Header
+		status int
+	}{
+		{"query", "GET", "/v1/order/stream?stream_token=" + stream, events, http.StatusOK},
+		{"cookie", "GET", "/v1/order/stream", http.Header{"Accept": {"text/event-stream"}, "Cookie": {"stream_token=" + stream}}, http.StatusOK},
+		{"not asking for events", "GET", "/v1/order/stream?stream_token=" + stream, nil, http.StatusUnauthorized},
+		{"access token in the query", "GET", "/v1/order/stream?stream_token=" + sign(""), events, http.StatusUnauthorized},
+		{"stream token in the header", "GET", "/v1/order/stream", http.Header{"X-Access-Token": {stream}}, http.StatusUnauthorized},
+		{"access token in the header", "GET", "/v1/order/stream", http.Header{"X-Access-Token": {sign("")}}, http.StatusOK},
+		{"other route", "GET", "/v1/order/list?stream_token=" + stream, events, http.StatusUnauthorized},
+		{"other method", "POST", "/v1/order/stream?stream_token=" + stream, events, http.StatusUnauthorized},
+	}
+
+	for _, test := range tests {
+		rec := serve(router, test.method, test.path, test.header)
+		assert.Equal(t, test.status, rec.Code, test.name)
+	}
+}
+
+func TestRateLimitAuthRoutesByIP(t *testing.T) {
+	signer := auth.NewSigner("s3cret", 15*time.Minute)
+	token := func(subject int64) string {
+		signed, err := signer.Sign(auth.Claims{Subject: subject}, time.Now())
+		assert.NoError(t, err)
+		return signed
+	}
+
+	limit := ratelimit.Limit{Requests: 2, Per: time.Minute}
+	router := newRouter(&middleware.MuxMiddleware{
+		Signer:    signer,
+		RateStore: ratelimit.NewMemoryStore(),
+		RateLimits: []ratelimit.Policy{
+			{Name: "auth", Prefix: "/v1/customer/login", IP: limit, Customer: limit, AlwaysIP: true},
+		},
+	})
+
+	rec := serve(router, "POST", "/v1/customer/login", http.Header{"X-Access-Token": {token(4)}})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "1", rec.Header().Get("X-RateLimit-Remaining"))
+
+	rec = serve(router, "POST", "/v1/customer/login", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// another token from the same IP does not get a fresh bucket
+	rec = serve(router, "POST", "/v1/customer/login", http.Header{"X-Access-Token": {token(5)}})
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "30", rec.Header().Get("Retry-After"))
+
+	// other routes are not limited
+	rec = serve(router, "GET", "/v1/product/list", http.Header{"X-Access-Token": {token(5)}})
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestRateLimitBeforeAuth(t *testing.T) {
+	keys := new(mocks.KeyAuthenticator)
+	keys.On("Authenticate", mock.Anything, "key_orders").
+		Return(&auth.KeyPrincipal{ID: 3, Permissions: []auth.Permission{auth.OrderWrite}}, nil)
+
+	router := newRouter(&middleware.MuxMiddleware{
+		Keys:      keys,
+		RateStore: ratelimit.NewMemoryStore(),
+		RateLimits: []ratelimit.Policy{
+			{Name: "default", Prefix: "/",
+				IP:  ratelimit.Limit{Requests: 1, Per: time.Minute},
+				Key: ratelimit.Limit{Requests: 1, Per: time.Minute}},
+		},
+	})
+
+	rec := serve(router, "POST", "/v1/order/update", http.Header{"X-Api-Key": {"key_orders"}})
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// the key has its own bucket, not the one of its IP
+	rec = serve(router, "GET", "/v1/product/list", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = serve(router, "POST", "/v1/order/update", http.Header{"X-Api-Key": {"key_orders"}})
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+
+	// the throttled request never looked the key up
+	keys.AssertNumberOfCalls(t, "Authenticate", 1)
+}
+
+func TestRateLimitUnverifiedCredentials(t *testing.T) {
+	keys := new(mocks.KeyAuthenticator)
+	keys.On("Authenticate", mock.Anything, mock.Anything).Return(nil, models.ErrUnauthorized)
+
+	router := newRouter(&middleware.MuxMiddleware{
+		Signer:    auth.NewSigner("s3cret", 15*time.Minute),
+		Keys:      keys,
+		RateStore: ratelimit.NewMemoryStore(),
+		RateLimits: []ratelimit.Policy{
+			{Name: "default", Prefix: "/",
+				IP:       ratelimit.Limit{Requests: 1, Per: time.Minute},
+				Customer: ratelimit.Limit{Requests: 5, Per: time.Minute},
+				Key:      ratelimit.Limit{Requests: 2, Per: time.Minute}},
+		},
+	})
+
+	// every made up key from the IP draw from the same bucket
+	for i, status := range []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests} {
+		rec := serve(router, "GET", "/v1/product/list", http.Header{"X-Api-Key": {"key_" + utils.RandString(8)}})
+		assert.Equal(t, status, rec.Code, i)
+	}
+
+	keys.AssertNumberOfCalls(t, "Authenticate", 2)
+
+	// so does every forged token, along with anonymous requests
+	rec := serve(router, "GET", "/v1/product/list", http.Header{"X-Access-Token": {"forged"}})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = serve(router, "GET", "/v1/product/list", http.Header{"X-Access-Token": {"forged.again"}})
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+
+	rec = serve(router, "GET", "/v1/product/list", nil)
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+}
+
+func TestIdempotency(t *testing.T) {
+	body := []byte(`{"cart_id":3}`)
+	fingerprint := idempotency.Fingerprint("POST", "/v1/order/add", body)
+	key := "anonymous|abc"
+
+	post := func(m *middleware.MuxMiddleware, next http.HandlerFunc) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/v1/order/add", bytes.NewReader(body))
+		req.Header.Set(idempotency.Header, "abc")
+
+		rec := httptest.NewRecorder()
+		m.IdempotencyMiddleware(next).ServeHTTP(rec, req)
+		return rec
+	}
+
+	unreached := func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the handler was not expected to run")
+	}
+
+	t.Run("replay", func(t *testing.T) {
+		mockIdempotency := new(idemMocks.Usecase)
+		mockIdempotency.On("Begin", mock.Anything, key, fingerprint).Return(&models.IdempotencyRecord{
+			Key:         key,
+			Fingerprint: fingerprint,
+			Status:      http.StatusCreated,
+			Header: http.Header{
+				"Content-Type": {"application/json"},
+				"Location":     {"/v1/order/7"},
+				"Etag":         {`"1"`},
+			},
+			Body: []byte(`{"id":7}`),
+		}, nil).Once()
+
+		rec := post(&middleware.MuxMiddleware{Idempotency: mockIdempotency}, unreached)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, "/v1/order/7", rec.Header().Get("Location"))
+		assert.Equal(t, `"1"`, rec.Header().Get("ETag"))
+		assert.Equal(t, "true", rec.Header().Get(idempotency.ReplayedHeader))
+		assert.Equal(t, `{"id":7}`, rec.Body.String())
+		mockIdempotency.AssertExpectations(t)
+	})
+
+	t.Run("key of another request", func(t *testing.T) {
+		mockIdempotency := new(idemMocks.Usecase)
+		mockIdempotency.On("Begin", mock.Anything, key, fingerprint).
+			Return(&models.IdempotencyRecord{Key: key, Fingerprint: "other", Status: http.StatusCreated}, nil).Once()
+
+		rec := post(&middleware.MuxMiddleware{Idempotency: mockIdempotency}, unreached)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		mockIdempotency.AssertExpectations(t)
+	})
+
+	t.Run("in progress", func(t *testing.T) {
+		mockIdempotency := new(idemMocks.Usecase)
+		mockIdempotency.On("Begin", mock.Anything, key, fingerprint).
+			Return(&models.IdempotencyRecord{Key: key, Fingerprint: fingerprint}, nil).Once()
+
+		rec := post(&middleware.MuxMiddleware{Idempotency: mockIdempotency}, unreached)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		mockIdempotency.AssertExpectations(t)
+	})
+
+	t.Run("completed", func(t *testing.T) {
+		mockIdempotency := new(idemMocks.Usecase)
+		mockIdempotency.On("Begin", mock.Anything, key, fingerprint).Return(nil, nil).Once()
+		mockIdempotency.On("Complete", mock.Anything, key, http.StatusCreated,
+			mock.MatchedBy(func(header http.Header) bool {
+				return header.Get("Location") == "/v1/order/7"
+			}), []byte(`{"id":7}`)).Return(nil).Once()
+
+		rec := post(&middleware.MuxMiddleware{Idempotency: mockIdempotency}, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Location", "/v1/order/7")
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":7}`))
+		})
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Empty(t, rec.Header().Get(idempotency.ReplayedHeader))
+		mockIdempotency.AssertExpectations(t)
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		mockIdempotency := new(idemMocks.Usecase)
+		mockIdempotency.On("Begin", mock.Anything, key, fingerprint).Return(nil, nil).Once()
+		mockIdempotency.On("Release", mock.Anything, key).Return(nil).Once()
+
+		rec := post(&middleware.MuxMiddleware{Idempotency: mockIdempotency}, func(w http.ResponseWriter, r *http.Request) {
+			utils.Error(w, http.StatusInternalServerError, "Unexpected error")
+		})
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		mockIdempotency.AssertNotCalled(t, "Complete", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockIdempotency.AssertExpectations(t)
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		mockIdempotency := new(idemMocks.Usecase)
+		mockIdempotency.On("Begin", mock.Anything, key, fingerprint).Return(nil, nil).Once()
+		mockIdempotency.On("Release", mock.Anything, key).Return(nil).Once()
+
+		assert.Panics(t, func() {
+			post(&middleware.MuxMiddleware{Idempotency: mockIdempotency}, func(w http.ResponseWriter, r *http.Request) {
+				panic("boom")
+			})
+		})
+
+		mockIdempotency.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE products ADD COLUMN deleted_at timestamp NULL;
+ALTER TABLE categories ADD COLUMN deleted_at timestamp NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE products DROP COLUMN deleted_at;
+ALTER TABLE categories DROP COLUMN deleted_at;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS audit_log (
+    id          BIGSERIAL   PRIMARY KEY NOT NULL,
+    entity_type VARCHAR     NOT NULL,
+    entity_id   BIGINT      NOT NULL,
+    action      VARCHAR     NOT NULL,
+    actor       VARCHAR     NOT NULL,
+    request_id  VARCHAR     NOT NULL DEFAULT '',
+    diff        JSONB       NOT NULL DEFAULT '{}'::jsonb,
+    created     TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX audit_log_entity_idx ON audit_log (entity_type, entity_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE audit_log;
+-- +goose StatementEnd
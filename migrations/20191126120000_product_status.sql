@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE products ADD COLUMN status SMALLINT NOT NULL DEFAULT 0;
+ALTER TABLE products ADD COLUMN publish_at timestamp NULL;
+ALTER TABLE products ADD COLUMN unpublish_at timestamp NULL;
+-- products created before the lifecycle existed were already live
+UPDATE products SET status = 1;
+CREATE INDEX products_publish_at_idx ON products(publish_at) WHERE publish_at IS NOT NULL;
+CREATE INDEX products_unpublish_at_idx ON products(unpublish_at) WHERE unpublish_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX products_unpublish_at_idx;
+DROP INDEX products_publish_at_idx;
+ALTER TABLE products DROP COLUMN unpublish_at;
+ALTER TABLE products DROP COLUMN publish_at;
+ALTER TABLE products DROP COLUMN status;
+-- +goose StatementEnd
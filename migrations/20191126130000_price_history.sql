@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE product_price ADD COLUMN valid_from timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE product_price ADD COLUMN valid_to timestamp NULL;
+-- existing prices are assumed to be in effect since the product was created
+UPDATE product_price SET valid_from = products.created FROM products WHERE products.id = product_price.product_id;
+CREATE INDEX product_price_validity_idx ON product_price(product_id, valid_from, valid_to);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX product_price_validity_idx;
+ALTER TABLE product_price DROP COLUMN valid_to;
+ALTER TABLE product_price DROP COLUMN valid_from;
+-- +goose StatementEnd
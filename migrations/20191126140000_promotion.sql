@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS promotions (
+    id           BIGSERIAL   PRIMARY KEY NOT NULL,
+    name         VARCHAR     NOT NULL,
+    product_id   BIGINT      NULL,
+    category_id  BIGINT      NULL,
+    min_quantity BIGINT      NOT NULL DEFAULT 0,
+    min_subtotal DOUBLE PRECISION NOT NULL DEFAULT 0.0,
+    start_at     TIMESTAMP   NULL,
+    end_at       TIMESTAMP   NULL,
+    action       VARCHAR     NOT NULL,
+    value        DOUBLE PRECISION NOT NULL,
+    priority     INT         NOT NULL DEFAULT 0,
+    exclusive    BOOLEAN     NOT NULL DEFAULT false,
+    active       BOOLEAN     NOT NULL DEFAULT true,
+    created      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated      TIMESTAMP   NULL
+);
+
+CREATE TABLE IF NOT EXISTS order_promotion (
+    id           BIGSERIAL   PRIMARY KEY NOT NULL,
+    order_id     BIGINT      NOT NULL,
+    promotion_id BIGINT      NOT NULL,
+    name         VARCHAR     NOT NULL,
+    discount     DOUBLE PRECISION NOT NULL
+);
+CREATE INDEX order_promotion_order_idx ON order_promotion (order_id);
+ALTER TABLE orders ADD COLUMN discount DOUBLE PRECISION NOT NULL DEFAULT 0.0;
+ALTER TABLE orders ADD COLUMN total DOUBLE PRECISION NOT NULL DEFAULT 0.0;
+UPDATE orders SET total = price * amount;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE orders DROP COLUMN total;
+ALTER TABLE orders DROP COLUMN discount;
+DROP TABLE order_promotion;
+DROP TABLE promotions;
+-- +goose StatementEnd
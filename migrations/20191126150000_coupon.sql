@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS coupons (
+    id           BIGSERIAL   PRIMARY KEY NOT NULL,
+    code         VARCHAR     NOT NULL,
+    batch        VARCHAR     NOT NULL,
+    action       VARCHAR     NOT NULL,
+    value        DOUBLE PRECISION NOT NULL,
+    min_spend    DOUBLE PRECISION NOT NULL DEFAULT 0.0,
+    max_uses     BIGINT      NOT NULL DEFAULT 0,
+    per_customer BIGINT      NOT NULL DEFAULT 0,
+    used         BIGINT      NOT NULL DEFAULT 0,
+    expires_at   TIMESTAMP   NULL,
+    active       BOOLEAN     NOT NULL DEFAULT true,
+    created      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX coupons_code_idx ON coupons (code);
+CREATE INDEX coupons_batch_idx ON coupons (batch);
+
+CREATE TABLE IF NOT EXISTS order_coupon (
+    id           BIGSERIAL   PRIMARY KEY NOT NULL,
+    order_id     BIGINT      NOT NULL,
+    coupon_id    BIGINT      NOT NULL,
+    code         VARCHAR     NOT NULL,
+    customer     VARCHAR     NOT NULL DEFAULT '',
+    discount     DOUBLE PRECISION NOT NULL
+);
+CREATE UNIQUE INDEX order_coupon_order_idx ON order_coupon (order_id);
+CREATE INDEX order_coupon_customer_idx ON order_coupon (coupon_id, customer);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE order_coupon;
+DROP TABLE coupons;
+-- +goose StatementEnd
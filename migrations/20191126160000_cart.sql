@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS order_item (
+    id          BIGSERIAL   PRIMARY KEY NOT NULL,
+    order_id    BIGINT      NOT NULL,
+    product_id  BIGINT      NOT NULL,
+    amount      BIGINT      NOT NULL,
+    price       DOUBLE PRECISION DEFAULT 0.0::double PRECISION NOT NULL
+);
+CREATE INDEX order_item_order_idx ON order_item (order_id);
+INSERT INTO order_item(order_id, product_id, amount, price) SELECT id, product_id, amount, price FROM orders;
+
+CREATE TABLE IF NOT EXISTS carts (
+    id          BIGSERIAL   PRIMARY KEY NOT NULL,
+    token       VARCHAR     NOT NULL,
+    customer    VARCHAR     NOT NULL DEFAULT '',
+    order_id    BIGINT      NULL,
+    expires_at  TIMESTAMP   NOT NULL,
+    created     TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated     TIMESTAMP   NULL
+);
+CREATE UNIQUE INDEX carts_token_idx ON carts (token);
+CREATE INDEX carts_customer_idx ON carts (customer) WHERE order_id IS NULL;
+
+CREATE TABLE IF NOT EXISTS cart_item (
+    id          BIGSERIAL   PRIMARY KEY NOT NULL,
+    cart_id     BIGINT      NOT NULL,
+    product_id  BIGINT      NOT NULL,
+    amount      BIGINT      NOT NULL
+);
+CREATE UNIQUE INDEX cart_item_product_idx ON cart_item (cart_id, product_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE cart_item;
+DROP TABLE carts;
+DROP TABLE order_item;
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS customers (
+    id          BIGSERIAL   PRIMARY KEY NOT NULL,
+    email       VARCHAR     NOT NULL,
+    name        VARCHAR     NOT NULL DEFAULT '',
+    password    VARCHAR     NOT NULL,
+    verified    BOOLEAN     NOT NULL DEFAULT false,
+    created     TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated     TIMESTAMP   NULL
+);
+CREATE UNIQUE INDEX customers_email_idx ON customers (email);
+
+CREATE TABLE IF NOT EXISTS customer_tokens (
+    id          BIGSERIAL   PRIMARY KEY NOT NULL,
+    customer_id BIGINT      NOT NULL,
+    purpose     VARCHAR     NOT NULL,
+    hash        VARCHAR     NOT NULL,
+    expires_at  TIMESTAMP   NOT NULL,
+    used_at     TIMESTAMP   NULL,
+    created     TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX customer_tokens_hash_idx ON customer_tokens (hash);
+
+ALTER TABLE orders ADD COLUMN customer_id BIGINT NULL;
+CREATE INDEX orders_customer_idx ON orders (customer_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX orders_customer_idx;
+ALTER TABLE orders DROP COLUMN customer_id;
+DROP TABLE customer_tokens;
+DROP TABLE customers;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    token_id    VARCHAR     PRIMARY KEY NOT NULL,
+    expires_at  TIMESTAMP   NOT NULL
+);
+CREATE INDEX customer_tokens_customer_idx ON customer_tokens (customer_id, purpose);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX customer_tokens_customer_idx;
+DROP TABLE revoked_tokens;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the first admin is granted by hand:
+-- INSERT INTO customer_roles(customer_id, role) VALUES(<id>, 'admin');
+CREATE TABLE IF NOT EXISTS customer_roles (
+    customer_id BIGINT      NOT NULL,
+    role        VARCHAR     NOT NULL,
+    created     TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (customer_id, role)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE customer_roles;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS api_keys (
+    id           BIGSERIAL   PRIMARY KEY NOT NULL,
+    name         VARCHAR     NOT NULL,
+    prefix       VARCHAR     NOT NULL,
+    hash         VARCHAR     NOT NULL,
+    permissions  VARCHAR     NOT NULL DEFAULT '',
+    requests     BIGINT      NOT NULL DEFAULT 0,
+    last_used_at TIMESTAMP   NULL,
+    revoked_at   TIMESTAMP   NULL,
+    created      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated      TIMESTAMP   NULL
+);
+CREATE UNIQUE INDEX api_keys_hash_idx ON api_keys (hash);
+
+CREATE TABLE IF NOT EXISTS api_key_usage (
+    key_id      BIGINT      NOT NULL,
+    day         DATE        NOT NULL,
+    requests    BIGINT      NOT NULL DEFAULT 0,
+    PRIMARY KEY (key_id, day)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE api_key_usage;
+DROP TABLE api_keys;
+-- +goose StatementEnd
@@ -0,0 +1,89 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE products ADD COLUMN weight DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE products ADD COLUMN length DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE products ADD COLUMN width DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE products ADD COLUMN height DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS addresses (
+    id           BIGSERIAL   PRIMARY KEY NOT NULL,
+    customer_id  BIGINT      NOT NULL,
+    name         VARCHAR     NOT NULL,
+    line1        VARCHAR     NOT NULL,
+    line2        VARCHAR     NOT NULL DEFAULT '',
+    city         VARCHAR     NOT NULL,
+    region       VARCHAR     NOT NULL DEFAULT '',
+    postal_code  VARCHAR     NOT NULL DEFAULT '',
+    country      CHAR(2)     NOT NULL,
+    phone        VARCHAR     NOT NULL DEFAULT '',
+    is_default   BOOLEAN     NOT NULL DEFAULT false,
+    created      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated      TIMESTAMP   NULL
+);
+CREATE INDEX addresses_customer_idx ON addresses (customer_id);
+
+CREATE TABLE IF NOT EXISTS shipping_zones (
+    id           BIGSERIAL   PRIMARY KEY NOT NULL,
+    name         VARCHAR     NOT NULL,
+    created      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS shipping_zone_region (
+    zone_id      BIGINT      NOT NULL,
+    country      CHAR(2)     NOT NULL,
+    region       VARCHAR     NOT NULL DEFAULT '',
+    PRIMARY KEY (zone_id, country, region)
+);
+CREATE INDEX shipping_zone_region_country_idx ON shipping_zone_region (country, region);
+
+CREATE TABLE IF NOT EXISTS shipping_methods (
+    id           BIGSERIAL   PRIMARY KEY NOT NULL,
+    zone_id      BIGINT      NOT NULL,
+    name         VARCHAR     NOT NULL,
+    type         VARCHAR     NOT NULL,
+    cost         DOUBLE PRECISION NOT NULL DEFAULT 0,
+    active       BOOLEAN     NOT NULL DEFAULT true,
+    created      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated      TIMESTAMP   NULL
+);
+CREATE INDEX shipping_methods_zone_idx ON shipping_methods (zone_id);
+
+CREATE TABLE IF NOT EXISTS shipping_rates (
+    id           BIGSERIAL   PRIMARY KEY NOT NULL,
+    method_id    BIGINT      NOT NULL,
+    min          DOUBLE PRECISION NOT NULL DEFAULT 0,
+    cost         DOUBLE PRECISION NOT NULL
+);
+CREATE INDEX shipping_rates_method_idx ON shipping_rates (method_id);
+
+CREATE TABLE IF NOT EXISTS order_shipping (
+    id           BIGSERIAL   PRIMARY KEY NOT NULL,
+    order_id     BIGINT      NOT NULL,
+    method_id    BIGINT      NOT NULL,
+    method       VARCHAR     NOT NULL,
+    cost         DOUBLE PRECISION NOT NULL,
+    name         VARCHAR     NOT NULL,
+    line1        VARCHAR     NOT NULL,
+    line2        VARCHAR     NOT NULL DEFAULT '',
+    city         VARCHAR     NOT NULL,
+    region       VARCHAR     NOT NULL DEFAULT '',
+    postal_code  VARCHAR     NOT NULL DEFAULT '',
+    country      CHAR(2)     NOT NULL,
+    phone        VARCHAR     NOT NULL DEFAULT ''
+);
+CREATE UNIQUE INDEX order_shipping_order_idx ON order_shipping (order_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE order_shipping;
+DROP TABLE shipping_rates;
+DROP TABLE shipping_methods;
+DROP TABLE shipping_zone_region;
+DROP TABLE shipping_zones;
+DROP TABLE addresses;
+ALTER TABLE products DROP COLUMN weight;
+ALTER TABLE products DROP COLUMN length;
+ALTER TABLE products DROP COLUMN width;
+ALTER TABLE products DROP COLUMN height;
+-- +goose StatementEnd
@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE products ADD COLUMN tax_class_id BIGINT NULL;
+ALTER TABLE orders ADD COLUMN tax DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN tax_included BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE order_item ADD COLUMN tax_name VARCHAR NOT NULL DEFAULT '';
+ALTER TABLE order_item ADD COLUMN tax_rate DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE order_item ADD COLUMN tax DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS tax_classes (
+    id           BIGSERIAL   PRIMARY KEY NOT NULL,
+    name         VARCHAR     NOT NULL,
+    created      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS tax_rates (
+    id           BIGSERIAL   PRIMARY KEY NOT NULL,
+    class_id     BIGINT      NOT NULL,
+    country      CHAR(2)     NOT NULL,
+    region       VARCHAR     NOT NULL DEFAULT '',
+    name         VARCHAR     NOT NULL,
+    rate         DOUBLE PRECISION NOT NULL,
+    created      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated      TIMESTAMP   NULL
+);
+CREATE INDEX tax_rates_class_idx ON tax_rates (class_id, country, region);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE tax_rates;
+DROP TABLE tax_classes;
+ALTER TABLE order_item DROP COLUMN tax;
+ALTER TABLE order_item DROP COLUMN tax_rate;
+ALTER TABLE order_item DROP COLUMN tax_name;
+ALTER TABLE orders DROP COLUMN tax_included;
+ALTER TABLE orders DROP COLUMN tax;
+ALTER TABLE products DROP COLUMN tax_class_id;
+-- +goose StatementEnd
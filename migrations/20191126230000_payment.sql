@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS payments (
+    id           BIGSERIAL   PRIMARY KEY NOT NULL,
+    order_id     BIGINT      NOT NULL,
+    provider     VARCHAR     NOT NULL,
+    reference    VARCHAR     NOT NULL DEFAULT '',
+    status       VARCHAR     NOT NULL,
+    amount       DOUBLE PRECISION NOT NULL DEFAULT 0,
+    captured     DOUBLE PRECISION NOT NULL DEFAULT 0,
+    refunded     DOUBLE PRECISION NOT NULL DEFAULT 0,
+    created      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated      TIMESTAMP   NULL,
+    version      BIGINT      NOT NULL DEFAULT 1
+);
+CREATE INDEX payments_order_idx ON payments (order_id);
+CREATE INDEX payments_reference_idx ON payments (provider, reference);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE payments;
+-- +goose StatementEnd
@@ -0,0 +1,49 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE products ADD COLUMN stock BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS returns (
+    id           BIGSERIAL   PRIMARY KEY NOT NULL,
+    order_id     BIGINT      NOT NULL,
+    customer_id  BIGINT      NULL,
+    status       VARCHAR     NOT NULL,
+    reason       TEXT        NOT NULL DEFAULT '',
+    restock      BOOLEAN     NOT NULL DEFAULT false,
+    refund       DOUBLE PRECISION NOT NULL DEFAULT 0,
+    created      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated      TIMESTAMP   NULL,
+    version      BIGINT      NOT NULL DEFAULT 1
+);
+CREATE INDEX returns_order_idx ON returns (order_id);
+CREATE INDEX returns_status_idx ON returns (status, created);
+
+CREATE TABLE IF NOT EXISTS return_item (
+    id            BIGSERIAL  PRIMARY KEY NOT NULL,
+    return_id     BIGINT     NOT NULL,
+    order_item_id BIGINT     NOT NULL,
+    product_id    BIGINT     NOT NULL,
+    amount        BIGINT     NOT NULL
+);
+CREATE INDEX return_item_return_idx ON return_item (return_id);
+
+CREATE TABLE IF NOT EXISTS credit_notes (
+    id           BIGSERIAL   PRIMARY KEY NOT NULL,
+    number       VARCHAR     NOT NULL DEFAULT '',
+    return_id    BIGINT      NOT NULL,
+    order_id     BIGINT      NOT NULL,
+    lines        JSONB       NOT NULL,
+    tax          DOUBLE PRECISION NOT NULL DEFAULT 0,
+    adjustment   DOUBLE PRECISION NOT NULL DEFAULT 0,
+    amount       DOUBLE PRECISION NOT NULL DEFAULT 0,
+    created      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX credit_notes_return_idx ON credit_notes (return_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE credit_notes;
+DROP TABLE return_item;
+DROP TABLE returns;
+ALTER TABLE products DROP COLUMN stock;
+-- +goose StatementEnd
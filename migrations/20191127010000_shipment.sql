@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS shipments (
+    id              BIGSERIAL   PRIMARY KEY NOT NULL,
+    order_id        BIGINT      NOT NULL,
+    carrier         VARCHAR     NOT NULL DEFAULT '',
+    tracking_number VARCHAR     NOT NULL DEFAULT '',
+    status          VARCHAR     NOT NULL,
+    shipped         TIMESTAMP   NULL,
+    delivered       TIMESTAMP   NULL,
+    created         TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated         TIMESTAMP   NULL,
+    version         BIGINT      NOT NULL DEFAULT 1
+);
+CREATE INDEX shipments_order_idx ON shipments (order_id);
+
+CREATE TABLE IF NOT EXISTS shipment_item (
+    id            BIGSERIAL  PRIMARY KEY NOT NULL,
+    shipment_id   BIGINT     NOT NULL,
+    order_item_id BIGINT     NOT NULL,
+    product_id    BIGINT     NOT NULL,
+    amount        BIGINT     NOT NULL
+);
+CREATE INDEX shipment_item_shipment_idx ON shipment_item (shipment_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE shipment_item;
+DROP TABLE shipments;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS invoice_sequences (
+    year         INT         PRIMARY KEY NOT NULL,
+    last         BIGINT      NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS invoices (
+    id           BIGSERIAL   PRIMARY KEY NOT NULL,
+    number       VARCHAR     NOT NULL,
+    year         INT         NOT NULL,
+    order_id     BIGINT      NOT NULL,
+    customer_id  BIGINT      NULL,
+    total        DOUBLE PRECISION NOT NULL DEFAULT 0,
+    issued       TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    snapshot     JSONB       NOT NULL
+);
+CREATE UNIQUE INDEX invoices_number_idx ON invoices (number);
+CREATE UNIQUE INDEX invoices_order_idx ON invoices (order_id);
+-- issued invoices are never changed nor removed
+CREATE RULE invoices_no_update AS ON UPDATE TO invoices DO INSTEAD NOTHING;
+CREATE RULE invoices_no_delete AS ON DELETE TO invoices DO INSTEAD NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE invoices;
+DROP TABLE invoice_sequences;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key          VARCHAR     PRIMARY KEY NOT NULL,
+    fingerprint  VARCHAR     NOT NULL,
+    status       INT         NOT NULL DEFAULT 0,
+    content_type VARCHAR     NOT NULL DEFAULT '',
+    body         BYTEA       NULL,
+    created      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idempotency_keys_created_idx ON idempotency_keys (created);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE idempotency_keys;
+-- +goose StatementEnd
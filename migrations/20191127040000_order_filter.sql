@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX orders_status_idx ON orders (status, created);
+CREATE INDEX orders_customer_idx ON orders (customer_id, created);
+CREATE INDEX order_item_product_idx ON order_item (product_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX order_item_product_idx;
+DROP INDEX orders_customer_idx;
+DROP INDEX orders_status_idx;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS product_related (
+    product_id  BIGINT      NOT NULL,
+    related_id  BIGINT      NOT NULL,
+    score       BIGINT      NOT NULL,
+    computed    TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (product_id, related_id)
+);
+CREATE INDEX product_category_category_idx ON product_category (category_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX product_category_category_idx;
+DROP TABLE IF EXISTS product_related;
+-- +goose StatementEnd
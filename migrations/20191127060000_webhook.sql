@@ -0,0 +1,46 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id            BIGSERIAL   PRIMARY KEY NOT NULL,
+    type          VARCHAR     NOT NULL,
+    aggregate_id  BIGINT      NOT NULL,
+    payload       JSONB       NOT NULL,
+    created       TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    dispatched    TIMESTAMP   NULL
+);
+CREATE INDEX outbox_events_pending_idx ON outbox_events (id) WHERE dispatched IS NULL;
+
+CREATE TABLE IF NOT EXISTS webhooks (
+    id       BIGSERIAL   PRIMARY KEY NOT NULL,
+    url      VARCHAR     NOT NULL,
+    secret   VARCHAR     NOT NULL,
+    events   JSONB       NOT NULL DEFAULT '[]',
+    active   BOOLEAN     NOT NULL DEFAULT TRUE,
+    created  TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated  TIMESTAMP   NULL,
+    version  BIGINT      NOT NULL DEFAULT 1
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id             BIGSERIAL   PRIMARY KEY NOT NULL,
+    webhook_id     BIGINT      NOT NULL,
+    event_id       BIGINT      NOT NULL,
+    event_type     VARCHAR     NOT NULL,
+    status         VARCHAR     NOT NULL,
+    attempts       INT         NOT NULL DEFAULT 0,
+    next_attempt   TIMESTAMP   NULL,
+    response_code  INT         NULL,
+    error          TEXT        NOT NULL DEFAULT '',
+    created        TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    delivered      TIMESTAMP   NULL,
+    UNIQUE (webhook_id, event_id)
+);
+CREATE INDEX webhook_deliveries_due_idx ON webhook_deliveries (status, next_attempt);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
+DROP TABLE IF EXISTS outbox_events;
+-- +goose StatementEnd
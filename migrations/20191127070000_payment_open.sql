@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE UNIQUE INDEX payments_open_order_idx ON payments (order_id) WHERE status NOT IN ('failed', 'voided');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX payments_open_order_idx;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE idempotency_keys ADD COLUMN headers JSONB NOT NULL DEFAULT '{}';
+UPDATE idempotency_keys SET headers = json_build_object('Content-Type', json_build_array(content_type)) WHERE content_type <> '';
+ALTER TABLE idempotency_keys DROP COLUMN content_type;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE idempotency_keys ADD COLUMN content_type VARCHAR NOT NULL DEFAULT '';
+UPDATE idempotency_keys SET content_type = headers->'Content-Type'->>0 WHERE headers ? 'Content-Type';
+ALTER TABLE idempotency_keys DROP COLUMN headers;
+-- +goose StatementEnd
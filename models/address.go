@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"gopkg.in/guregu/null.v3"
+)
+
+// Address model, an entry of the address book of a customer. Country is the
+// ISO 3166-1 alpha-2 code, Region the province or state within it.
+type Address struct {
+	ID         int64     `json:"id"`
+	CustomerID int64     `json:"customer_id"`
+	Name       string    `json:"name"`
+	Line1      string    `json:"line1"`
+	Line2      string    `json:"line2"`
+	City       string    `json:"city"`
+	Region     string    `json:"region"`
+	PostalCode string    `json:"postal_code"`
+	Country    string    `json:"country"`
+	Phone      string    `json:"phone"`
+	IsDefault  bool      `json:"is_default"`
+	Created    time.Time `json:"created"`
+	Updated    null.Time `json:"updated"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"gopkg.in/guregu/null.v3"
+)
+
+// APIKey model of a key used by integrations. Only the SHA-256 of the key is
+// stored, Prefix is kept in clear so it can be recognised in lists and Key
+// is only set right after it is created or rotated.
+type APIKey struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	Prefix      string    `json:"prefix"`
+	Key         string    `json:"key,omitempty"`
+	Hash        string    `json:"-"`
+	Permissions []string  `json:"permissions"`
+	Requests    int64     `json:"requests"`
+	LastUsedAt  null.Time `json:"last_used_at"`
+	RevokedAt   null.Time `json:"revoked_at"`
+	Created     time.Time `json:"created"`
+	Updated     null.Time `json:"updated"`
+}
+
+// APIKeyUsage count the requests made with a key during a day
+type APIKeyUsage struct {
+	KeyID    int64     `json:"key_id"`
+	Day      time.Time `json:"day"`
+	Requests int64     `json:"requests"`
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditLog model
+type AuditLog struct {
+	ID         int64           `json:"id"`
+	EntityType string          `json:"entity_type"`
+	EntityID   int64           `json:"entity_id"`
+	Action     string          `json:"action"`
+	Actor      string          `json:"actor"`
+	RequestID  string          `json:"request_id"`
+	Diff       json.RawMessage `json:"diff"`
+	Created    time.Time       `json:"created"`
+}
+
+// AuditCreate action recorded when an entity is created
+var AuditCreate = "create"
+
+// AuditUpdate action recorded when an entity is updated
+var AuditUpdate = "update"
+
+// AuditDelete action recorded when an entity is deleted
+var AuditDelete = "delete"
+
+// AuditRestore action recorded when an entity is restored from the trash
+var AuditRestore = "restore"
+
+// AuditProduct entity type of product changes
+var AuditProduct = "product"
+
+// AuditCategory entity type of category changes
+var AuditCategory = "category"
+
+// AuditProductCategory entity type of product category link changes
+var AuditProductCategory = "product_category"
+
+// AuditProductPrice entity type of product price changes
+var AuditProductPrice = "product_price"
+
+// AuditOrder entity type of order changes
+var AuditOrder = "order"
+
+// AuditPromotion entity type of promotion changes
+var AuditPromotion = "promotion"
+
+// AuditCoupon entity type of coupon changes
+var AuditCoupon = "coupon"
+
+// AuditCustomer entity type of customer changes
+var AuditCustomer = "customer"
+
+// AuditAPIKey entity type of API key changes
+var AuditAPIKey = "api_key"
+
+// AuditAddress entity type of address book changes
+var AuditAddress = "address"
+
+// AuditShipping entity type of shipping zone and method changes
+var AuditShipping = "shipping"
+
+// AuditTax entity type of tax class and rate changes
+var AuditTax = "tax"
+
+// AuditPayment entity type of payment changes
+var AuditPayment = "payment"
+
+// AuditReturn entity type of return changes
+var AuditReturn = "return"
+
+// AuditShipment entity type of shipment changes
+var AuditShipment = "shipment"
+
+// AuditInvoice entity type of invoices issued
+var AuditInvoice = "invoice"
+
+// AuditWebhook entity type of webhook endpoint changes
+var AuditWebhook = "webhook"
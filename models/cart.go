@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"gopkg.in/guregu/null.v3"
+)
+
+// Cart model, a basket filled before checkout. Carts without customer are
+// anonymous and reached by their token only. Prices are never stored, they
+// are computed from the price list every time the cart is read.
+type Cart struct {
+	ID         int64             `json:"id"`
+	Token      string            `json:"token"`
+	Customer   string            `json:"customer"`
+	OrderID    null.Int          `json:"order_id"`
+	ExpiresAt  time.Time         `json:"expires_at"`
+	Created    time.Time         `json:"created"`
+	Updated    null.Time         `json:"updated"`
+	Items      []*CartItem       `json:"items"`
+	Subtotal   float64           `json:"subtotal"`
+	Discount   float64           `json:"discount"`
+	Total      float64           `json:"total"`
+	Promotions []*OrderPromotion `json:"promotions"`
+}
+
+// CartItem is a product put in a cart
+type CartItem struct {
+	ID        int64   `json:"id"`
+	CartID    int64   `json:"cart_id"`
+	ProductID int64   `json:"product_id"`
+	Amount    int64   `json:"amount"`
+	Price     float64 `json:"price"`
+	Subtotal  float64 `json:"subtotal"`
+}
@@ -8,9 +8,11 @@ import (
 
 // Category model
 type Category struct {
-	ID       int64     `json:"id"`
-	Name     string    `json:"name"`
-	ParentID null.Int  `json:"parent_id"`
-	Created  time.Time `json:"created"`
-	Updated  null.Time `json:"updated"`
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	ParentID  null.Int  `json:"parent_id"`
+	Created   time.Time `json:"created"`
+	Updated   null.Time `json:"updated"`
+	DeletedAt null.Time `json:"deleted_at"`
+	Version   int64     `json:"version"`
 }
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"gopkg.in/guregu/null.v3"
+)
+
+// Coupon model, a code given to customers that take a discount off an order.
+// MaxUses and PerCustomer left at zero mean unlimited.
+type Coupon struct {
+	ID          int64     `json:"id"`
+	Code        string    `json:"code"`
+	Batch       string    `json:"batch"`
+	Action      string    `json:"action"`
+	Value       float64   `json:"value"`
+	MinSpend    float64   `json:"min_spend"`
+	MaxUses     int64     `json:"max_uses"`
+	PerCustomer int64     `json:"per_customer"`
+	Used        int64     `json:"used"`
+	ExpiresAt   null.Time `json:"expires_at"`
+	Active      bool      `json:"active"`
+	Created     time.Time `json:"created"`
+}
+
+// OrderCoupon is a coupon redeemed by an order
+type OrderCoupon struct {
+	ID       int64   `json:"id"`
+	OrderID  int64   `json:"order_id"`
+	CouponID int64   `json:"coupon_id"`
+	Code     string  `json:"code"`
+	Customer string  `json:"customer"`
+	Discount float64 `json:"discount"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"gopkg.in/guregu/null.v3"
+)
+
+// Customer model, Password hold the bcrypt hash and is never sent out
+type Customer struct {
+	ID       int64     `json:"id"`
+	Email    string    `json:"email"`
+	Name     string    `json:"name"`
+	Password string    `json:"-"`
+	Verified bool      `json:"verified"`
+	Created  time.Time `json:"created"`
+	Updated  null.Time `json:"updated"`
+}
+
+// CustomerToken is a single use token sent to the customer by email, only
+// the SHA-256 of the token is stored
+type CustomerToken struct {
+	ID         int64     `json:"id"`
+	CustomerID int64     `json:"customer_id"`
+	Purpose    string    `json:"purpose"`
+	Hash       string    `json:"-"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	UsedAt     null.Time `json:"used_at"`
+	Created    time.Time `json:"created"`
+}
+
+// TokenVerifyEmail purpose of tokens confirming the email of a customer
+var TokenVerifyEmail = "verify_email"
+
+// TokenResetPassword purpose of tokens allowing to choose a new password
+var TokenResetPassword = "reset_password"
+
+// TokenRefresh purpose of tokens exchanged for a new access token, each is
+// used once and replaced by a new one
+var TokenRefresh = "refresh"
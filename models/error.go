@@ -8,4 +8,37 @@ var (
 
 	// ErrInternalServerError will throw if any the Internal Server Error happen
 	ErrInternalServerError = errors.New("Internal Server Error")
+
+	// ErrConflict will throw if the models changed since the version the client hold
+	ErrConflict = errors.New("Conflict")
+
+	// ErrBadParamInput will throw if the given request-body or params is not valid
+	ErrBadParamInput = errors.New("Given param is not valid")
+
+	// ErrUnauthorized will throw if the credentials or token given are not valid
+	ErrUnauthorized = errors.New("Unauthorized")
+
+	// ErrForbidden will throw if the principal is not allowed the operation
+	ErrForbidden = errors.New("Forbidden")
+
+	// ErrTooManyRequests will throw if the client used up its rate limit
+	ErrTooManyRequests = errors.New("Too many requests")
+
+	// ErrIdempotencyMismatch will throw if an idempotency key is reused for a different request
+	ErrIdempotencyMismatch = errors.New("Idempotency key was used for a different request")
+
+	// ErrIdempotencyInProgress will throw if the request first made with an idempotency key did not finish yet
+	ErrIdempotencyInProgress = errors.New("A request with this idempotency key is in progress")
+
+	// ErrCouponUnavailable will throw if the coupon is expired, used up or the order does not qualify
+	ErrCouponUnavailable = errors.New("Coupon can not be redeemed")
+
+	// ErrShippingUnavailable will throw if the shipping method does not ship to the destination
+	ErrShippingUnavailable = errors.New("Shipping method not available for the destination")
+
+	// ErrPaymentDeclined will throw if the payment provider refused to authorize the payment
+	ErrPaymentDeclined = errors.New("Payment was declined")
+
+	// ErrPaymentProvider will throw if the payment provider could not be reached or failed
+	ErrPaymentProvider = errors.New("Payment provider is unavailable")
 )
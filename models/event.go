@@ -0,0 +1,68 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gopkg.in/guregu/null.v3"
+)
+
+// Event is a domain event written to the outbox in the transaction of the
+// change it describe, Dispatched is set once it is handed to the webhooks
+// subscribed to its type
+type Event struct {
+	ID          int64           `json:"id"`
+	Type        string          `json:"type"`
+	AggregateID int64           `json:"aggregate_id"`
+	Payload     json.RawMessage `json:"data"`
+	Created     time.Time       `json:"created"`
+	Dispatched  null.Time       `json:"-"`
+}
+
+// OrderStatusChange is the payload of EventOrderStatusChanged
+type OrderStatusChange struct {
+	OrderID int64 `json:"order_id"`
+	From    int   `json:"from"`
+	To      int   `json:"to"`
+	Version int64 `json:"version"`
+}
+
+// OrderUpdate is streamed live to the back-office when an order is created or
+// its status changed, From is the status it had before a change
+type OrderUpdate struct {
+	From  null.Int `json:"from"`
+	Order *Order   `json:"order"`
+}
+
+// PriceChange is the payload of EventPriceChanged, the prices of the product
+// in effect from ValidFrom
+type PriceChange struct {
+	ProductID int64           `json:"product_id"`
+	ValidFrom time.Time       `json:"valid_from"`
+	Prices    []*ProductPrice `json:"prices"`
+}
+
+// EventProductCreated product added to the catalogue
+var EventProductCreated = "product.created"
+
+// EventProductUpdated product details or status changed
+var EventProductUpdated = "product.updated"
+
+// EventProductDeleted product moved to the trash
+var EventProductDeleted = "product.deleted"
+
+// EventProductRestored product taken back from the trash
+var EventProductRestored = "product.restored"
+
+// EventPriceChanged prices of a product changed or were scheduled to
+var EventPriceChanged = "price.changed"
+
+// EventOrderCreated order placed
+var EventOrderCreated = "order.created"
+
+// EventOrderStatusChanged status of an order changed
+var EventOrderStatusChanged = "order.status_changed"
+
+// EventTypes list every event webhooks can subscribe to
+var EventTypes = []string{EventProductCreated, EventProductUpdated, EventProductDeleted, EventProductRestored,
+	EventPriceChanged, EventOrderCreated, EventOrderStatusChanged}
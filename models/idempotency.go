@@ -0,0 +1,22 @@
+package models
+
+import (
+	"net/http"
+	"time"
+)
+
+// IdempotencyRecord is what is kept of a request made with an
+// Idempotency-Key, Status is 0 until its response is stored
+type IdempotencyRecord struct {
+	Key         string
+	Fingerprint string
+	Status      int
+	Header      http.Header
+	Body        []byte
+	Created     time.Time
+}
+
+// Completed tell whether the response of the request is stored
+func (r *IdempotencyRecord) Completed() bool {
+	return r.Status != 0
+}
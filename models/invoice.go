@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"gopkg.in/guregu/null.v3"
+)
+
+// Invoice model, a snapshot of an order taken when it is invoiced. It is
+// never changed afterwards, even when the order or its products are.
+// Number run without gap within the year the invoice is issued.
+type Invoice struct {
+	ID          int64          `json:"id"`
+	Number      string         `json:"number"`
+	Year        int            `json:"year"`
+	OrderID     int64          `json:"order_id"`
+	CustomerID  null.Int       `json:"customer_id"`
+	BillTo      *OrderShipping `json:"bill_to,omitempty"`
+	Lines       []*InvoiceLine `json:"lines"`
+	Taxes       []*OrderTax    `json:"taxes"`
+	Subtotal    float64        `json:"subtotal"`
+	Discount    float64        `json:"discount"`
+	Shipping    float64        `json:"shipping"`
+	Tax         float64        `json:"tax"`
+	TaxIncluded bool           `json:"tax_included"`
+	Total       float64        `json:"total"`
+	Issued      time.Time      `json:"issued"`
+}
+
+// InvoiceLine is a line of an order as it was invoiced, Total is the price
+// of all its pieces before discounts and tax added on top
+type InvoiceLine struct {
+	ProductID int64   `json:"product_id"`
+	SKU       string  `json:"sku"`
+	Name      string  `json:"name"`
+	Amount    int64   `json:"amount"`
+	Price     float64 `json:"price"`
+	TaxName   string  `json:"tax_name"`
+	TaxRate   float64 `json:"tax_rate"`
+	Tax       float64 `json:"tax"`
+	Total     float64 `json:"total"`
+}
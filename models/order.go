@@ -1,15 +1,60 @@
 package models
 
-import "time"
+import (
+	"time"
 
-// Order model
+	"gopkg.in/guregu/null.v3"
+)
+
+// Order model, TaxIncluded tell the prices of the order already include Tax,
+// it is added to Total otherwise
 type Order struct {
-	ID        int64     `json:"id"`
-	ProductID int64     `json:"product_id"`
-	Amount    int64     `json:"amount"`
-	Price     float64   `json:"price"`
-	Status    int       `json:"status"`
-	Created   time.Time `json:"created"`
+	ID          int64             `json:"id"`
+	CustomerID  null.Int          `json:"customer_id"`
+	ProductID   int64             `json:"product_id"`
+	Amount      int64             `json:"amount"`
+	Price       float64           `json:"price"`
+	Discount    float64           `json:"discount"`
+	Total       float64           `json:"total"`
+	Tax         float64           `json:"tax"`
+	TaxIncluded bool              `json:"tax_included"`
+	Status      int               `json:"status"`
+	Created     time.Time         `json:"created"`
+	Version     int64             `json:"version"`
+	Items       []*OrderItem      `json:"items,omitempty"`
+	Promotions  []*OrderPromotion `json:"promotions,omitempty"`
+	Coupon      *OrderCoupon      `json:"coupon,omitempty"`
+	Shipping    *OrderShipping    `json:"shipping,omitempty"`
+	Taxes       []*OrderTax       `json:"taxes,omitempty"`
+}
+
+// OrderItem is a line of an order, price is the unit price charged and tax
+// the tax charged on the whole line
+type OrderItem struct {
+	ID        int64   `json:"id"`
+	OrderID   int64   `json:"order_id"`
+	ProductID int64   `json:"product_id"`
+	Amount    int64   `json:"amount"`
+	Price     float64 `json:"price"`
+	TaxName   string  `json:"tax_name"`
+	TaxRate   float64 `json:"tax_rate"`
+	Tax       float64 `json:"tax"`
+}
+
+// OrderFilter narrow a list of orders, fields left null do not filter. From
+// and To bound when orders were created, To excluded. Query match the order
+// number or the number of its invoice, Sort is a field optionally prefixed
+// with - for descending order.
+type OrderFilter struct {
+	Status     null.Int
+	ProductID  null.Int
+	CustomerID null.Int
+	From       null.Time
+	To         null.Time
+	MinTotal   null.Float
+	MaxTotal   null.Float
+	Query      string
+	Sort       string
 }
 
 // OrderPending for initialize pending payment order
@@ -23,3 +68,12 @@ var OrderShipped = 2
 
 // OrderCompleted order are finished
 var OrderCompleted = 3
+
+// OrderCancelled order whose payment was voided, it will not be shipped
+var OrderCancelled = 4
+
+// OrderReturned order whose every line was sent back
+var OrderReturned = 5
+
+// OrderPartiallyShipped order whose shipments cover only some of its pieces
+var OrderPartiallyShipped = 6
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"gopkg.in/guregu/null.v3"
+)
+
+// Payment model, a payment of an order made through a payment provider.
+// Amount is what was authorized, of which Captured was charged and Refunded
+// given back.
+type Payment struct {
+	ID        int64     `json:"id"`
+	OrderID   int64     `json:"order_id"`
+	Provider  string    `json:"provider"`
+	Reference string    `json:"reference"`
+	Status    string    `json:"status"`
+	Amount    float64   `json:"amount"`
+	Captured  float64   `json:"captured"`
+	Refunded  float64   `json:"refunded"`
+	Created   time.Time `json:"created"`
+	Updated   null.Time `json:"updated"`
+	Version   int64     `json:"version"`
+}
+
+// PaymentEvent is a change of a payment reported by its provider, Type is
+// the status the payment moved to and Amount the total authorized, captured
+// or refunded so far so replayed events change nothing
+type PaymentEvent struct {
+	Reference string  `json:"reference"`
+	Type      string  `json:"type"`
+	Amount    float64 `json:"amount"`
+}
+
+// PaymentPending payment waiting for the provider to authorize it
+var PaymentPending = "pending"
+
+// PaymentAuthorized payment whose amount is held, waiting to be captured
+var PaymentAuthorized = "authorized"
+
+// PaymentCaptured payment charged, a part of it could be refunded
+var PaymentCaptured = "captured"
+
+// PaymentRefunded payment whose captured amount was all refunded
+var PaymentRefunded = "refunded"
+
+// PaymentVoided payment whose authorization was released before capture
+var PaymentVoided = "voided"
+
+// PaymentFailed payment declined by the provider
+var PaymentFailed = "failed"
@@ -8,9 +8,34 @@ import (
 
 // Product model
 type Product struct {
-	ID      int64     `json:"id"`
-	Name    string    `json:"name"`
-	SKU     string    `json:"SKU"`
-	Created time.Time `json:"created"`
-	Updated null.Time `json:"updated"`
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	SKU         string    `json:"SKU"`
+	Status      int       `json:"status"`
+	PublishAt   null.Time `json:"publish_at"`
+	UnpublishAt null.Time `json:"unpublish_at"`
+	Created     time.Time `json:"created"`
+	Updated     null.Time `json:"updated"`
+	DeletedAt   null.Time `json:"deleted_at"`
+	Version     int64     `json:"version"`
+	TaxClassID  null.Int  `json:"tax_class_id"`
+	// Stock is the number of pieces on hand, taken by orders placed and
+	// given back by cancelled orders and restocked returns. It goes below
+	// zero when more pieces were sold than were on hand.
+	Stock int64 `json:"stock"`
+	// Weight in kilograms and dimensions in centimeters of the packed
+	// product, used to compute shipping costs
+	Weight float64 `json:"weight"`
+	Length float64 `json:"length"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
 }
+
+// ProductDraft product that is only visible to admins
+var ProductDraft = 0
+
+// ProductPublished product that is visible on the storefront and can be ordered
+var ProductPublished = 1
+
+// ProductArchived product that is no longer sold
+var ProductArchived = 2
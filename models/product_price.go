@@ -1,9 +1,18 @@
 package models
 
-// ProductPrice model
+import (
+	"time"
+
+	"gopkg.in/guregu/null.v3"
+)
+
+// ProductPrice model, a price is in effect from ValidFrom until ValidTo
+// and open ended while ValidTo is null
 type ProductPrice struct {
-	ID        int64   `json:"id"`
-	Amount    int64   `json:"amount"`
-	Price     float64 `json:"price"`
-	ProductID int64   `json:"product_id"`
+	ID        int64     `json:"id"`
+	Amount    int64     `json:"amount"`
+	Price     float64   `json:"price"`
+	ProductID int64     `json:"product_id"`
+	ValidFrom time.Time `json:"valid_from"`
+	ValidTo   null.Time `json:"valid_to"`
 }
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"gopkg.in/guregu/null.v3"
+)
+
+// Promotion model, a discount given when every condition is met.
+// Conditions left empty always match.
+type Promotion struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	ProductID   null.Int  `json:"product_id"`
+	CategoryID  null.Int  `json:"category_id"`
+	MinQuantity int64     `json:"min_quantity"`
+	MinSubtotal float64   `json:"min_subtotal"`
+	StartAt     null.Time `json:"start_at"`
+	EndAt       null.Time `json:"end_at"`
+	Action      string    `json:"action"`
+	Value       float64   `json:"value"`
+	Priority    int       `json:"priority"`
+	Exclusive   bool      `json:"exclusive"`
+	Active      bool      `json:"active"`
+	Created     time.Time `json:"created"`
+	Updated     null.Time `json:"updated"`
+}
+
+// PromotionPercentOff take Value percent off the matching lines
+var PromotionPercentOff = "percent_off"
+
+// PromotionFixedOff take Value off the order once
+var PromotionFixedOff = "fixed_off"
+
+// PromotionFreeItem give Value units for free for every MinQuantity units bought
+var PromotionFreeItem = "free_item"
+
+// OrderPromotion is a promotion applied to an order, name is copied so the
+// order still tell what was applied after the promotion changed
+type OrderPromotion struct {
+	ID          int64   `json:"id"`
+	OrderID     int64   `json:"order_id"`
+	PromotionID int64   `json:"promotion_id"`
+	Name        string  `json:"name"`
+	Discount    float64 `json:"discount"`
+}
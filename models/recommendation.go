@@ -0,0 +1,18 @@
+package models
+
+// Where a related product comes from
+const (
+	RelatedBoughtTogether = "bought_together"
+	RelatedSameCategory   = "same_category"
+)
+
+// RelatedProduct is a product recommended next to another one. Score is how
+// many orders held both products when bought together, or how many categories
+// they share when coming from the same category.
+type RelatedProduct struct {
+	ProductID int64  `json:"product_id"`
+	Name      string `json:"name"`
+	SKU       string `json:"SKU"`
+	Score     int64  `json:"score"`
+	Source    string `json:"source"`
+}
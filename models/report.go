@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"gopkg.in/guregu/null.v3"
+)
+
+// Report periods sales are grouped by
+const (
+	PeriodDay   = "day"
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+)
+
+// ReportFilter bound the orders a report is computed on to those created
+// from From to To, To excluded. Period group the sales over time, Sort order
+// product sales by "units" or "revenue" and Limit keep the first of them.
+type ReportFilter struct {
+	From   time.Time
+	To     time.Time
+	Period string
+	Sort   string
+	Limit  int64
+}
+
+// SalesSummary is the revenue, orders and units sold over a whole range,
+// revenue is the total paid for the orders less what their credit notes
+// refunded, units are those ordered
+type SalesSummary struct {
+	From         time.Time `json:"from"`
+	To           time.Time `json:"to"`
+	Revenue      float64   `json:"revenue"`
+	Orders       int64     `json:"orders"`
+	Units        int64     `json:"units"`
+	AverageOrder float64   `json:"average_order"`
+}
+
+// SalesPoint is the sales of one period, Period is when it starts
+type SalesPoint struct {
+	Period       time.Time `json:"period"`
+	Revenue      float64   `json:"revenue"`
+	Orders       int64     `json:"orders"`
+	Units        int64     `json:"units"`
+	AverageOrder float64   `json:"average_order"`
+}
+
+// ProductSales is what was sold of a product, revenue is the price of its
+// lines before order level discounts
+type ProductSales struct {
+	ProductID int64   `json:"product_id"`
+	Name      string  `json:"name"`
+	Units     int64   `json:"units"`
+	Revenue   float64 `json:"revenue"`
+	Orders    int64   `json:"orders"`
+}
+
+// CategorySales is what was sold of the products of a category and of its
+// sub categories, a product is counted once even when linked to several of
+// them
+type CategorySales struct {
+	CategoryID int64    `json:"category_id"`
+	Name       string   `json:"name"`
+	ParentID   null.Int `json:"parent_id"`
+	Units      int64    `json:"units"`
+	Revenue    float64  `json:"revenue"`
+}
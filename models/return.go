@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"gopkg.in/guregu/null.v3"
+)
+
+// Return model, a request of a customer to send back lines of an order
+// (RMA). Refund is what is given back once the return is approved.
+type Return struct {
+	ID         int64         `json:"id"`
+	OrderID    int64         `json:"order_id"`
+	CustomerID null.Int      `json:"customer_id"`
+	Status     string        `json:"status"`
+	Reason     string        `json:"reason"`
+	Restock    bool          `json:"restock"`
+	Refund     float64       `json:"refund"`
+	Created    time.Time     `json:"created"`
+	Updated    null.Time     `json:"updated"`
+	Version    int64         `json:"version"`
+	Items      []*ReturnItem `json:"items,omitempty"`
+	CreditNote *CreditNote   `json:"credit_note,omitempty"`
+}
+
+// ReturnItem is a line of an order sent back, amount is the number of pieces
+type ReturnItem struct {
+	ID          int64 `json:"id"`
+	ReturnID    int64 `json:"return_id"`
+	OrderItemID int64 `json:"order_item_id"`
+	ProductID   int64 `json:"product_id"`
+	Amount      int64 `json:"amount"`
+}
+
+// CreditNote is the document crediting the customer for a return. Lines are
+// credited at what was paid for them, Adjustment is what was kept back on
+// top of it, a restocking fee for instance, and Amount what is refunded.
+type CreditNote struct {
+	ID         int64             `json:"id"`
+	Number     string            `json:"number"`
+	ReturnID   int64             `json:"return_id"`
+	OrderID    int64             `json:"order_id"`
+	Lines      []*CreditNoteLine `json:"lines"`
+	Tax        float64           `json:"tax"`
+	Adjustment float64           `json:"adjustment"`
+	Amount     float64           `json:"amount"`
+	Created    time.Time         `json:"created"`
+}
+
+// CreditNoteLine is a line of a credit note, Price is the unit price paid
+// after discounts and Total what is credited for the line, tax included
+type CreditNoteLine struct {
+	ProductID int64   `json:"product_id"`
+	Amount    int64   `json:"amount"`
+	Price     float64 `json:"price"`
+	TaxRate   float64 `json:"tax_rate"`
+	Tax       float64 `json:"tax"`
+	Total     float64 `json:"total"`
+}
+
+// ReturnRequested return waiting for an admin to approve it
+var ReturnRequested = "requested"
+
+// ReturnApproved return accepted, its goods restocked and its credit note
+// issued, waiting for the refund to go through
+var ReturnApproved = "approved"
+
+// ReturnRefunded return whose refund was issued
+var ReturnRefunded = "refunded"
+
+// ReturnRejected return refused by an admin
+var ReturnRejected = "rejected"
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"gopkg.in/guregu/null.v3"
+)
+
+// Shipment model, a parcel sent for some or all lines of an order
+type Shipment struct {
+	ID             int64           `json:"id"`
+	OrderID        int64           `json:"order_id"`
+	Carrier        string          `json:"carrier"`
+	TrackingNumber string          `json:"tracking_number"`
+	Status         string          `json:"status"`
+	Shipped        null.Time       `json:"shipped"`
+	Delivered      null.Time       `json:"delivered"`
+	Created        time.Time       `json:"created"`
+	Updated        null.Time       `json:"updated"`
+	Version        int64           `json:"version"`
+	Items          []*ShipmentItem `json:"items,omitempty"`
+}
+
+// ShipmentItem is a line of an order packed in a shipment, amount is the
+// number of pieces
+type ShipmentItem struct {
+	ID          int64 `json:"id"`
+	ShipmentID  int64 `json:"shipment_id"`
+	OrderItemID int64 `json:"order_item_id"`
+	ProductID   int64 `json:"product_id"`
+	Amount      int64 `json:"amount"`
+}
+
+// PackingSlip list what goes in a shipment and where it goes
+type PackingSlip struct {
+	OrderID        int64              `json:"order_id"`
+	ShipmentID     int64              `json:"shipment_id"`
+	Carrier        string             `json:"carrier"`
+	TrackingNumber string             `json:"tracking_number"`
+	ShipTo         *OrderShipping     `json:"ship_to"`
+	Lines          []*PackingSlipLine `json:"lines"`
+}
+
+// PackingSlipLine is a product to pack
+type PackingSlipLine struct {
+	ProductID int64  `json:"product_id"`
+	SKU       string `json:"sku"`
+	Name      string `json:"name"`
+	Amount    int64  `json:"amount"`
+}
+
+// ShipmentPending shipment being packed
+var ShipmentPending = "pending"
+
+// ShipmentShipped shipment handed to the carrier
+var ShipmentShipped = "shipped"
+
+// ShipmentDelivered shipment the carrier delivered
+var ShipmentDelivered = "delivered"
+
+// ShipmentCancelled shipment that will not be sent, its lines can be shipped
+// again
+var ShipmentCancelled = "cancelled"
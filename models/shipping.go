@@ -0,0 +1,98 @@
+package models
+
+import (
+	"time"
+
+	"gopkg.in/guregu/null.v3"
+)
+
+// ShippingZone model, a group of destinations sharing the same shipping
+// methods. A region left empty cover the whole country.
+type ShippingZone struct {
+	ID      int64         `json:"id"`
+	Name    string        `json:"name"`
+	Regions []*ZoneRegion `json:"regions"`
+	Created time.Time     `json:"created"`
+}
+
+// ZoneRegion is a destination covered by a shipping zone
+type ZoneRegion struct {
+	ZoneID  int64  `json:"zone_id"`
+	Country string `json:"country"`
+	Region  string `json:"region"`
+}
+
+// ShippingMethod model, a way to ship to a zone. Flat methods cost Cost,
+// weight and price based methods look the cost up in their rate table.
+type ShippingMethod struct {
+	ID      int64           `json:"id"`
+	ZoneID  int64           `json:"zone_id"`
+	Name    string          `json:"name"`
+	Type    string          `json:"type"`
+	Cost    float64         `json:"cost"`
+	Active  bool            `json:"active"`
+	Rates   []*ShippingRate `json:"rates"`
+	Created time.Time       `json:"created"`
+	Updated null.Time       `json:"updated"`
+}
+
+// ShippingRate is a line of a rate table, Cost apply from Min kg or Min of
+// order subtotal up to the next line
+type ShippingRate struct {
+	ID       int64   `json:"id"`
+	MethodID int64   `json:"method_id"`
+	Min      float64 `json:"min"`
+	Cost     float64 `json:"cost"`
+}
+
+// ShippingQuote is the cost of shipping a parcel with a method
+type ShippingQuote struct {
+	MethodID int64   `json:"method_id"`
+	Name     string  `json:"name"`
+	Cost     float64 `json:"cost"`
+}
+
+// Parcel is what is shipped, Weight is the billable weight in kg
+type Parcel struct {
+	Weight   float64 `json:"weight"`
+	Subtotal float64 `json:"subtotal"`
+}
+
+// OrderShipping is the destination and shipping cost of an order, the
+// address is copied so later changes to the address book leave it intact
+type OrderShipping struct {
+	ID         int64   `json:"id"`
+	OrderID    int64   `json:"order_id"`
+	MethodID   int64   `json:"method_id"`
+	Method     string  `json:"method"`
+	Cost       float64 `json:"cost"`
+	Name       string  `json:"name"`
+	Line1      string  `json:"line1"`
+	Line2      string  `json:"line2"`
+	City       string  `json:"city"`
+	Region     string  `json:"region"`
+	PostalCode string  `json:"postal_code"`
+	Country    string  `json:"country"`
+	Phone      string  `json:"phone"`
+}
+
+// Checkout is what the customer choose when placing an order from a cart.
+// AddressID point to the address book, guests give Address instead.
+type Checkout struct {
+	Coupon           string   `json:"coupon"`
+	AddressID        int64    `json:"address_id"`
+	Address          *Address `json:"address"`
+	ShippingMethodID int64    `json:"shipping_method_id"`
+}
+
+// ShippingFlat method cost the same whatever is shipped
+var ShippingFlat = "flat"
+
+// ShippingWeight method cost is looked up by billable weight of the parcel
+var ShippingWeight = "weight"
+
+// ShippingPrice method cost is looked up by subtotal of the order
+var ShippingPrice = "price"
+
+// VolumetricDivisor turn cm³ into volumetric kg
+var VolumetricDivisor = 5000.0
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"gopkg.in/guregu/null.v3"
+)
+
+// TaxClass model, products of a class are taxed at the same rates, e.g.
+// standard, reduced or zero rated goods
+type TaxClass struct {
+	ID      int64     `json:"id"`
+	Name    string    `json:"name"`
+	Created time.Time `json:"created"`
+}
+
+// TaxRate model, the rate in percent charged on a tax class in a region of
+// a country. A region left empty apply to the whole country.
+type TaxRate struct {
+	ID      int64     `json:"id"`
+	ClassID int64     `json:"class_id"`
+	Country string    `json:"country"`
+	Region  string    `json:"region"`
+	Name    string    `json:"name"`
+	Rate    float64   `json:"rate"`
+	Created time.Time `json:"created"`
+	Updated null.Time `json:"updated"`
+}
+
+// OrderTax is a line of the tax breakdown of an order, the tax of every item
+// charged the same rate summed up
+type OrderTax struct {
+	Name   string  `json:"name"`
+	Rate   float64 `json:"rate"`
+	Amount float64 `json:"amount"`
+}
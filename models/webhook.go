@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"gopkg.in/guregu/null.v3"
+)
+
+// Webhook is an endpoint events are posted to. Secret key the HMAC signature
+// of every delivery, it is only shown when the webhook is created.
+type Webhook struct {
+	ID      int64     `json:"id"`
+	URL     string    `json:"url"`
+	Secret  string    `json:"secret,omitempty"`
+	Events  []string  `json:"events"`
+	Active  bool      `json:"active"`
+	Created time.Time `json:"created"`
+	Updated null.Time `json:"updated"`
+	Version int64     `json:"version"`
+}
+
+// WebhookDelivery is an event to post to a webhook and the outcome of the
+// last attempt, ResponseCode is missing when the endpoint could not be
+// reached
+type WebhookDelivery struct {
+	ID           int64     `json:"id"`
+	WebhookID    int64     `json:"webhook_id"`
+	EventID      int64     `json:"event_id"`
+	EventType    string    `json:"event_type"`
+	Status       string    `json:"status"`
+	Attempts     int       `json:"attempts"`
+	NextAttempt  null.Time `json:"next_attempt"`
+	ResponseCode null.Int  `json:"response_code"`
+	Error        string    `json:"error"`
+	Created      time.Time `json:"created"`
+	Delivered    null.Time `json:"delivered"`
+}
+
+// DeliveryPending delivery waiting for its next attempt
+var DeliveryPending = "pending"
+
+// DeliveryDelivered delivery the endpoint acknowledged with a 2xx status
+var DeliveryDelivered = "delivered"
+
+// DeliveryFailed delivery given up after too many attempts
+var DeliveryFailed = "failed"
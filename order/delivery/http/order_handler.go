@@ -5,43 +5,67 @@ import (
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/soerjadi/exam/auth"
+	"github.com/soerjadi/exam/coupon"
 	"github.com/soerjadi/exam/models"
 	"github.com/soerjadi/exam/order"
 	"github.com/soerjadi/exam/product"
 	price "github.com/soerjadi/exam/product_price"
+	"github.com/soerjadi/exam/promotion"
+	"github.com/soerjadi/exam/tax"
+	t "github.com/soerjadi/exam/types"
 	"github.com/soerjadi/exam/utils"
+	"gopkg.in/guregu/null.v3"
 )
 
 type newOrder struct {
 	ProductID int64   `json:"product_id"`
 	Amount    int64   `json:"amount"`
 	Price     float64 `json:"price"`
-	Status    int     `json:"status"`
+	Coupon    string  `json:"coupon"`
+}
+
+type updateOrderData struct {
+	ID      int64 `json:"id"`
+	Status  int   `json:"status"`
+	Version int64 `json:"version"`
 }
 
 // OrderHandler represent the http handler for order
 type OrderHandler struct {
-	OrderUsecase   order.Usecase
-	ProductUsecase product.Usecase
-	PriceUsecase   price.Usecase
+	OrderUsecase     order.Usecase
+	ProductUsecase   product.Usecase
+	PriceUsecase     price.Usecase
+	PromotionUsecase promotion.Usecase
+	CouponUsecase    coupon.Usecase
+	TaxUsecase       tax.Usecase
 }
 
 var logger = utils.LogBuilder(true)
 
 // NewOrderHandler initialize product resource endpoint
-func NewOrderHandler(router *mux.Router, usecase order.Usecase, productUsecase product.Usecase) *mux.Router {
+func NewOrderHandler(router *mux.Router, usecase order.Usecase, productUsecase product.Usecase, priceUsecase price.Usecase, promotionUsecase promotion.Usecase, couponUsecase coupon.Usecase, taxUsecase tax.Usecase) *mux.Router {
 	handler := &OrderHandler{
-		OrderUsecase:   usecase,
-		ProductUsecase: productUsecase,
+		OrderUsecase:     usecase,
+		ProductUsecase:   productUsecase,
+		PriceUsecase:     priceUsecase,
+		PromotionUsecase: promotionUsecase,
+		CouponUsecase:    couponUsecase,
+		TaxUsecase:       taxUsecase,
 	}
 
 	p := router.PathPrefix("/v1/order").Subrouter()
 	p.HandleFunc("/add", handler.CreateOrder).Methods("POST")
+	p.HandleFunc("/update", handler.UpdateOrder).Methods("POST")
 	p.HandleFunc("/list", handler.GetList).Methods("GET")
+	p.HandleFunc("/{id:[0-9]+}", handler.GetByID).Methods("GET")
 	p.HandleFunc("/delete", handler.Delete).Methods("GET")
+	p.HandleFunc("/{id:[0-9]+}/price_audit", handler.PriceAudit).Methods("GET")
 
 	return p
 }
@@ -68,7 +92,8 @@ func (h *OrderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 		ctx = context.Background()
 	}
 
-	product, err := h.ProductUsecase.GetByID(ctx, newOrder.ProductID)
+	// only published products can be ordered
+	product, err := h.ProductUsecase.GetPublishedByID(ctx, newOrder.ProductID)
 	if err != nil {
 		utils.Error(w, http.StatusBadRequest, err.Error())
 		return
@@ -83,7 +108,25 @@ func (h *OrderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 		ProductID: newOrder.ProductID,
 		Price:     newOrder.Price,
 		Amount:    newOrder.Amount,
-		Status:    newOrder.Status,
+		Status:    models.OrderPending,
+	}
+
+	if customerID, ok := auth.CustomerID(ctx); ok {
+		order.CustomerID = null.IntFrom(customerID)
+	}
+
+	err = h.priceOrder(ctx, &order, newOrder.Coupon, time.Now())
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// orders placed directly are not shipped, they are taxed at the origin
+	classes := map[int64]int64{product.ID: product.TaxClassID.Int64}
+	err = h.TaxUsecase.Apply(ctx, &order, classes, nil)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
 	}
 
 	err = h.OrderUsecase.Create(ctx, &order)
@@ -97,7 +140,216 @@ func (h *OrderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 
 }
 
-// GetList endpoint for get list an order
+// priceOrder is the order pricing step, the list price in effect win over
+// the price sent by the client, active promotions are applied on top of it
+// and the coupon, when one is given, on what is left to pay
+func (h *OrderHandler) priceOrder(ctx context.Context, order *models.Order, code string, now time.Time) error {
+	listPrice, err := h.PriceUsecase.GetPriceAt(ctx, order.ProductID, order.Amount, now)
+
+	if err != nil && err != models.ErrNotFound {
+		return err
+	}
+
+	if listPrice != nil {
+		order.Price = listPrice.Price
+	}
+
+	lines := []promotion.Line{
+		promotion.Line{
+			ProductID: order.ProductID,
+			Quantity:  order.Amount,
+			UnitPrice: order.Price,
+		},
+	}
+
+	applied, err := h.PromotionUsecase.Evaluate(ctx, lines, now)
+	if err != nil {
+		return err
+	}
+
+	order.Discount = 0
+	for _, promo := range applied {
+		order.Discount += promo.Discount
+	}
+
+	order.Promotions = applied
+	order.Total = lines[0].Subtotal() - order.Discount
+
+	if code == "" {
+		return nil
+	}
+
+	redeemed, err := h.CouponUsecase.Apply(ctx, code, auth.CustomerKey(ctx), order.Total, now)
+	if err != nil {
+		return err
+	}
+
+	order.Coupon = redeemed
+	order.Discount += redeemed.Discount
+	order.Total -= redeemed.Discount
+	return nil
+}
+
+// UpdateOrder endpoint for change status of an order
+func (h *OrderHandler) UpdateOrder(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var updateOrder updateOrderData
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = json.Unmarshal(body, &updateOrder)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// If-Match take precedence over the version sent in the body
+	version, ok, err := utils.IfMatchVersion(r)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if ok {
+		updateOrder.Version = version
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	order := models.Order{
+		ID:      updateOrder.ID,
+		Status:  updateOrder.Status,
+		Version: updateOrder.Version,
+	}
+
+	err = h.OrderUsecase.Update(ctx, &order)
+
+	if err == models.ErrConflict {
+		utils.Error(w, http.StatusPreconditionFailed, err.Error())
+		return
+	}
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("ETag", utils.ETag(order.Version))
+	utils.JSON(w, http.StatusOK, order)
+}
+
+// parseDate read a date of a filter, a day or an RFC 3339 time
+func parseDate(value string) (null.Time, error) {
+	if value == "" {
+		return null.Time{}, nil
+	}
+
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		date, err = time.Parse(time.RFC3339, value)
+	}
+
+	if err != nil {
+		return null.Time{}, models.ErrBadParamInput
+	}
+
+	return null.TimeFrom(date), nil
+}
+
+// orderFilter read the filters of a list of orders from the query string
+func orderFilter(params url.Values) (*models.OrderFilter, error) {
+	filter := &models.OrderFilter{
+		Query: params.Get("q"),
+		Sort:  params.Get("sort"),
+	}
+
+	ints := map[string]*null.Int{
+		"status":      &filter.Status,
+		"product_id":  &filter.ProductID,
+		"customer_id": &filter.CustomerID,
+	}
+
+	for name, field := range ints {
+		if value := params.Get(name); value != "" {
+			parsed, err := strconv.ParseInt(value, 0, 64)
+			if err != nil {
+				return nil, err
+			}
+
+			*field = null.IntFrom(parsed)
+		}
+	}
+
+	floats := map[string]*null.Float{
+		"min_total": &filter.MinTotal,
+		"max_total": &filter.MaxTotal,
+	}
+
+	for name, field := range floats {
+		if value := params.Get(name); value != "" {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, err
+			}
+
+			*field = null.FloatFrom(parsed)
+		}
+	}
+
+	var err error
+	if filter.From, err = parseDate(params.Get("from")); err != nil {
+		return nil, err
+	}
+
+	if filter.To, err = parseDate(params.Get("to")); err != nil {
+		return nil, err
+	}
+
+	return filter, nil
+}
+
+// GetByID endpoint to get an order with its items, customers only see their
+// own orders
+func (h *OrderHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 0, 64)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	order, err := h.OrderUsecase.GetVisibleByID(ctx, id)
+
+	switch err {
+	case nil:
+	case models.ErrUnauthorized:
+		utils.Error(w, http.StatusUnauthorized, err.Error())
+		return
+	case models.ErrNotFound:
+		utils.Error(w, http.StatusNotFound, err.Error())
+		return
+	default:
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("ETag", utils.ETag(order.Version))
+	utils.JSON(w, http.StatusOK, order)
+}
+
+// GetList endpoint for get list an order, filtered by status, product_id,
+// customer_id, from, to, min_total, max_total and q and ordered by sort
 func (h *OrderHandler) GetList(w http.ResponseWriter, r *http.Request) {
 	params := r.URL.Query()
 	limit, err := strconv.ParseInt(params.Get("limit"), 0, 64)
@@ -116,12 +368,18 @@ func (h *OrderHandler) GetList(w http.ResponseWriter, r *http.Request) {
 		limit = 10
 	}
 
+	filter, err := orderFilter(params)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	ctx := r.Context()
 	if ctx == nil {
 		ctx = context.Background()
 	}
 
-	orders, found, err := h.OrderUsecase.GetList(ctx, offset, limit)
+	orders, found, err := h.OrderUsecase.GetList(ctx, filter, offset, limit)
 
 	if err != nil {
 		utils.Error(w, http.StatusBadRequest, err.Error())
@@ -154,5 +412,72 @@ func (h *OrderHandler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	err = h.OrderUsecase.Delete(ctx, id)
 
+	switch err {
+	case nil:
+	case models.ErrNotFound:
+		utils.Error(w, http.StatusNotFound, err.Error())
+		return
+	case models.ErrConflict:
+		utils.Error(w, http.StatusConflict, err.Error())
+		return
+	default:
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	utils.JSON(w, http.StatusOK, "success")
 }
+
+// PriceAudit compare the price of every line of an order with the list price
+// of its product at the time it was placed
+func (h *OrderHandler) PriceAudit(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 0, 64)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	order, err := h.OrderUsecase.GetByID(ctx, id)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result := t.OrderPriceAudit{
+		OrderID: order.ID,
+		Lines:   make([]*t.LinePriceAudit, 0, len(order.Items)),
+		Match:   true,
+	}
+
+	for _, item := range order.Items {
+		line := &t.LinePriceAudit{
+			OrderItemID: item.ID,
+			ProductID:   item.ProductID,
+			Amount:      item.Amount,
+			Charged:     item.Price,
+		}
+
+		listPrice, err := h.PriceUsecase.GetPriceAt(ctx, item.ProductID, item.Amount, order.Created)
+		if err != nil && err != models.ErrNotFound {
+			utils.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if listPrice != nil {
+			line.ListPrice = listPrice
+			line.Difference = item.Price - listPrice.Price
+			line.Match = line.Difference == 0
+		}
+
+		result.Lines = append(result.Lines, line)
+		result.Match = result.Match && line.Match
+	}
+
+	utils.JSON(w, http.StatusOK, result)
+}
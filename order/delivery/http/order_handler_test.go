@@ -7,14 +7,23 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/bxcodec/faker"
+	"github.com/gorilla/mux"
+	"github.com/soerjadi/exam/auth"
+	couponMocks "github.com/soerjadi/exam/coupon/mocks"
 	"github.com/soerjadi/exam/models"
 	orderHttp "github.com/soerjadi/exam/order/delivery/http"
 	"github.com/soerjadi/exam/order/mocks"
 	pMocks "github.com/soerjadi/exam/product/mocks"
+	priceMocks "github.com/soerjadi/exam/product_price/mocks"
+	promoMocks "github.com/soerjadi/exam/promotion/mocks"
+	taxMocks "github.com/soerjadi/exam/tax/mocks"
+	"github.com/soerjadi/exam/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"gopkg.in/guregu/null.v3"
 )
 
 type newOrder struct {
@@ -22,6 +31,7 @@ type newOrder struct {
 	Amount    int64   `json:"amount"`
 	Price     float64 `json:"price"`
 	Status    int     `json:"status"`
+	Coupon    string  `json:"coupon"`
 }
 
 func TestCreate(t *testing.T) {
@@ -33,9 +43,10 @@ func TestCreate(t *testing.T) {
 	}
 
 	mockProduct := models.Product{
-		ID:   int64(9),
-		Name: "product 9",
-		SKU:  "sku9",
+		ID:         int64(9),
+		Name:       "product 9",
+		SKU:        "sku9",
+		TaxClassID: null.IntFrom(1),
 	}
 
 	inputOrder := newOrder{
@@ -51,8 +62,26 @@ func TestCreate(t *testing.T) {
 	mockUsecase := new(mocks.Usecase)
 	mockProductUsecase := new(pMocks.Usecase)
 
-	mockUsecase.On("Create", mock.Anything, mock.AnythingOfType("*models.Order")).Return(nil)
-	mockProductUsecase.On("GetByID", mock.Anything, mock.AnythingOfType("int64")).Return(&mockProduct, nil)
+	mockPriceUsecase := new(priceMocks.Usecase)
+	mockPromotionUsecase := new(promoMocks.Usecase)
+	mockTaxUsecase := new(taxMocks.Usecase)
+
+	// list price of the 10 pieces tier win over the price sent by client,
+	// tax is charged on what is left after promotions
+	mockUsecase.On("Create", mock.Anything, mock.MatchedBy(func(o *models.Order) bool {
+		return o.Price == 9000.0 && o.Discount == 9000.0 && o.Tax == 8100.0 && o.Total == 89100.0 && len(o.Promotions) == 1
+	})).Return(nil)
+	mockTaxUsecase.On("Apply", mock.Anything, mock.AnythingOfType("*models.Order"), map[int64]int64{9: 1}, (*models.Address)(nil)).
+		Return(nil).Run(func(args mock.Arguments) {
+		o := args.Get(1).(*models.Order)
+		o.Tax = 8100.0
+		o.Total += o.Tax
+	})
+	mockProductUsecase.On("GetPublishedByID", mock.Anything, mock.AnythingOfType("int64")).Return(&mockProduct, nil)
+	mockPriceUsecase.On("GetPriceAt", mock.Anything, mockOrder.ProductID, mockOrder.Amount, mock.AnythingOfType("time.Time")).
+		Return(&models.ProductPrice{ID: 1, Amount: 10, Price: 9000.0, ProductID: 9}, nil)
+	mockPromotionUsecase.On("Evaluate", mock.Anything, mock.Anything, mock.AnythingOfType("time.Time")).
+		Return([]*models.OrderPromotion{&models.OrderPromotion{PromotionID: 2, Name: "10% off", Discount: 9000.0}}, nil)
 
 	j, err := json.Marshal(inputOrder)
 	assert.NoError(t, err)
@@ -63,8 +92,11 @@ func TestCreate(t *testing.T) {
 
 	rec := httptest.NewRecorder()
 	handler := orderHttp.OrderHandler{
-		OrderUsecase:   mockUsecase,
-		ProductUsecase: mockProductUsecase,
+		OrderUsecase:     mockUsecase,
+		ProductUsecase:   mockProductUsecase,
+		PriceUsecase:     mockPriceUsecase,
+		PromotionUsecase: mockPromotionUsecase,
+		TaxUsecase:       mockTaxUsecase,
 	}
 
 	handler.CreateOrder(rec, req)
@@ -73,6 +105,87 @@ func TestCreate(t *testing.T) {
 	mockUsecase.AssertExpectations(t)
 }
 
+func TestCreateWithCoupon(t *testing.T) {
+	mockProduct := models.Product{ID: int64(9), Name: "product 9", SKU: "sku9"}
+	inputOrder := newOrder{
+		ProductID: 9,
+		Amount:    10,
+		Coupon:    "xmas",
+	}
+
+	mockUsecase := new(mocks.Usecase)
+	mockProductUsecase := new(pMocks.Usecase)
+	mockPriceUsecase := new(priceMocks.Usecase)
+	mockPromotionUsecase := new(promoMocks.Usecase)
+	mockCouponUsecase := new(couponMocks.Usecase)
+	mockTaxUsecase := new(taxMocks.Usecase)
+
+	mockProductUsecase.On("GetPublishedByID", mock.Anything, int64(9)).Return(&mockProduct, nil)
+	mockPriceUsecase.On("GetPriceAt", mock.Anything, int64(9), int64(10), mock.AnythingOfType("time.Time")).
+		Return(&models.ProductPrice{ID: 1, Amount: 10, Price: 9000.0, ProductID: 9}, nil)
+	mockPromotionUsecase.On("Evaluate", mock.Anything, mock.Anything, mock.AnythingOfType("time.Time")).
+		Return([]*models.OrderPromotion{&models.OrderPromotion{PromotionID: 2, Name: "10% off", Discount: 9000.0}}, nil)
+	mockTaxUsecase.On("Apply", mock.Anything, mock.AnythingOfType("*models.Order"), mock.Anything, mock.Anything).Return(nil)
+
+	t.Run("success", func(t *testing.T) {
+		// the coupon apply on what is left after promotions
+		mockCouponUsecase.On("Apply", mock.Anything, "xmas", "4", 81000.0, mock.AnythingOfType("time.Time")).
+			Return(&models.OrderCoupon{CouponID: 3, Code: "XMAS", Customer: "4", Discount: 8100.0}, nil).Once()
+		mockUsecase.On("Create", mock.Anything, mock.MatchedBy(func(o *models.Order) bool {
+			return o.Discount == 17100.0 && o.Total == 72900.0 && o.Coupon.CouponID == 3 && o.CustomerID.Int64 == 4
+		})).Return(nil).Once()
+
+		j, err := json.Marshal(inputOrder)
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest("POST", "/v1/order/add", strings.NewReader(string(j)))
+		assert.NoError(t, err)
+		req = req.WithContext(auth.WithClaims(req.Context(), &auth.Claims{Subject: 4}))
+
+		rec := httptest.NewRecorder()
+		handler := orderHttp.OrderHandler{
+			OrderUsecase:     mockUsecase,
+			ProductUsecase:   mockProductUsecase,
+			PriceUsecase:     mockPriceUsecase,
+			PromotionUsecase: mockPromotionUsecase,
+			CouponUsecase:    mockCouponUsecase,
+			TaxUsecase:       mockTaxUsecase,
+		}
+
+		handler.CreateOrder(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("coupon unavailable", func(t *testing.T) {
+		mockCouponUsecase.On("Apply", mock.Anything, "xmas", "4", 81000.0, mock.AnythingOfType("time.Time")).
+			Return(nil, models.ErrCouponUnavailable).Once()
+
+		j, err := json.Marshal(inputOrder)
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest("POST", "/v1/order/add", strings.NewReader(string(j)))
+		assert.NoError(t, err)
+		req = req.WithContext(auth.WithClaims(req.Context(), &auth.Claims{Subject: 4}))
+
+		rec := httptest.NewRecorder()
+		handler := orderHttp.OrderHandler{
+			OrderUsecase:     mockUsecase,
+			ProductUsecase:   mockProductUsecase,
+			PriceUsecase:     mockPriceUsecase,
+			PromotionUsecase: mockPromotionUsecase,
+			CouponUsecase:    mockCouponUsecase,
+			TaxUsecase:       mockTaxUsecase,
+		}
+
+		handler.CreateOrder(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), models.ErrCouponUnavailable.Error())
+	})
+}
+
 func TestDelete(t *testing.T) {
 	var mockOrder models.Order
 	err := faker.FakeData(&mockOrder)
@@ -94,3 +207,174 @@ func TestDelete(t *testing.T) {
 	assert.Equal(t, http.StatusOK, rec.Code)
 	mockUsecase.AssertExpectations(t)
 }
+
+func TestDeleteWithHistory(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("Delete", mock.Anything, int64(8)).Return(models.ErrConflict).Once()
+
+	req, err := http.NewRequest("GET", "/v1/order/delete?id=8", strings.NewReader(""))
+	assert.NoError(t, err)
+
+	handler := orderHttp.OrderHandler{
+		OrderUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.Delete(rec, req)
+
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestUpdateOrder(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+
+	mockUsecase.On("Update", mock.Anything, mock.MatchedBy(func(o *models.Order) bool {
+		return o.ID == 8 && o.Status == models.OrderProccessed && o.Version == 2
+	})).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.Order).Version = 3
+	})
+
+	req, err := http.NewRequest("POST", "/v1/order/update", strings.NewReader(`{"id":8,"status":1}`))
+	assert.NoError(t, err)
+	req.Header.Set("If-Match", `"2"`)
+
+	handler := orderHttp.OrderHandler{
+		OrderUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.UpdateOrder(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `"3"`, rec.Header().Get("ETag"))
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestPriceAudit(t *testing.T) {
+	created := time.Now().Add(-24 * time.Hour)
+	// placed from a cart, the lines hold the products
+	mockOrder := models.Order{
+		ID:      int64(8),
+		Created: created,
+		Items: []*models.OrderItem{
+			{ID: 1, ProductID: 9, Amount: 10, Price: 9000.0},
+			{ID: 2, ProductID: 4, Amount: 1, Price: 1500.0},
+			{ID: 3, ProductID: 5, Amount: 2, Price: 700.0},
+		},
+	}
+
+	mockUsecase := new(mocks.Usecase)
+	mockPriceUsecase := new(priceMocks.Usecase)
+	mockUsecase.On("GetByID", mock.Anything, mockOrder.ID).Return(&mockOrder, nil)
+	mockPriceUsecase.On("GetPriceAt", mock.Anything, int64(9), int64(10), created).
+		Return(&models.ProductPrice{ID: 2, Amount: 5, Price: 8500.0, ProductID: 9}, nil).Once()
+	mockPriceUsecase.On("GetPriceAt", mock.Anything, int64(4), int64(1), created).
+		Return(&models.ProductPrice{ID: 3, Amount: 1, Price: 1500.0, ProductID: 4}, nil).Once()
+	mockPriceUsecase.On("GetPriceAt", mock.Anything, int64(5), int64(2), created).
+		Return(nil, models.ErrNotFound).Once()
+
+	req, err := http.NewRequest("GET", "/v1/order/8/price_audit", strings.NewReader(""))
+	assert.NoError(t, err)
+	req = mux.SetURLVars(req, map[string]string{"id": "8"})
+
+	handler := orderHttp.OrderHandler{
+		OrderUsecase: mockUsecase,
+		PriceUsecase: mockPriceUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.PriceAudit(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var body struct {
+		Result types.OrderPriceAudit `json:"result"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.False(t, body.Result.Match)
+	assert.Len(t, body.Result.Lines, 3)
+	assert.Equal(t, 500.0, body.Result.Lines[0].Difference)
+	assert.False(t, body.Result.Lines[0].Match)
+	assert.True(t, body.Result.Lines[1].Match)
+	assert.Nil(t, body.Result.Lines[2].ListPrice)
+	mockPriceUsecase.AssertExpectations(t)
+}
+
+func TestGetByID(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockUsecase := new(mocks.Usecase)
+		mockUsecase.On("GetVisibleByID", mock.Anything, int64(8)).
+			Return(&models.Order{ID: 8, Status: models.OrderShipped, Version: 3}, nil)
+
+		req, err := http.NewRequest("GET", "/v1/order/8", nil)
+		assert.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "8"})
+
+		handler := orderHttp.OrderHandler{
+			OrderUsecase: mockUsecase,
+		}
+
+		rec := httptest.NewRecorder()
+		handler.GetByID(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `"3"`, rec.Header().Get("ETag"))
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("order of another customer", func(t *testing.T) {
+		mockUsecase := new(mocks.Usecase)
+		mockUsecase.On("GetVisibleByID", mock.Anything, int64(8)).Return(nil, models.ErrNotFound)
+
+		req, err := http.NewRequest("GET", "/v1/order/8", nil)
+		assert.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "8"})
+
+		handler := orderHttp.OrderHandler{
+			OrderUsecase: mockUsecase,
+		}
+
+		rec := httptest.NewRecorder()
+		handler.GetByID(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestGetListFiltered(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("GetList", mock.Anything, mock.MatchedBy(func(f *models.OrderFilter) bool {
+		return f.Status.Int64 == int64(models.OrderShipped) && f.CustomerID.Int64 == 4 && !f.ProductID.Valid &&
+			f.From.Time.Equal(time.Date(2019, 11, 1, 0, 0, 0, 0, time.UTC)) && f.MaxTotal.Float64 == 500000.0 &&
+			f.Query == "INV-2019" && f.Sort == "-created"
+	}), int64(0), int64(20)).Return([]*models.Order{}, int64(0), nil)
+
+	req, err := http.NewRequest("GET",
+		"/v1/order/list?offset=0&limit=20&status=2&customer_id=4&from=2019-11-01&max_total=500000&q=INV-2019&sort=-created", nil)
+	assert.NoError(t, err)
+
+	handler := orderHttp.OrderHandler{
+		OrderUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.GetList(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestGetListBadDate(t *testing.T) {
+	req, err := http.NewRequest("GET", "/v1/order/list?offset=0&limit=10&from=yesterday", nil)
+	assert.NoError(t, err)
+
+	handler := orderHttp.OrderHandler{
+		OrderUsecase: new(mocks.Usecase),
+	}
+
+	rec := httptest.NewRecorder()
+	handler.GetList(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
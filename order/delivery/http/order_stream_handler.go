@@ -0,0 +1,223 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/soerjadi/exam/auth"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/stream"
+	"github.com/soerjadi/exam/utils"
+)
+
+// reconnectDelay is how long browsers wait before reconnecting a closed stream
+const reconnectDelay = time.Second
+
+// OrderStreamHandler represent the http handler streaming order updates
+type OrderStreamHandler struct {
+	Broker *stream.Broker
+	// Signer issue the stream tokens, with their own short TTL
+	Signer *auth.Signer
+	// Lifetime is how long a stream stay open, it has to end before the write
+	// timeout of the server. Clients reconnect with the ID of the last event
+	// they received and the ones published meanwhile are replayed.
+	Lifetime time.Duration
+}
+
+// NewOrderStreamHandler initialize the order updates stream endpoint
+func NewOrderStreamHandler(router *mux.Router, broker *stream.Broker, signer *auth.Signer, lifetime time.Duration) *mux.Router {
+	handler := &OrderStreamHandler{
+		Broker:   broker,
+		Signer:   signer,
+		Lifetime: lifetime,
+	}
+
+	router.HandleFunc("/v1/order/stream", handler.Stream).Methods("GET")
+	router.HandleFunc("/v1/order/stream/token", handler.Token).Methods("POST")
+
+	return router
+}
+
+// streamFilter build the filter of a stream from the query string. type list
+// the event types wanted, both when empty, status and customer_id narrow them
+// to the orders having that status after the event or placed by that customer.
+func streamFilter(params url.Values) (stream.Filter, error) {
+	types := map[string]bool{
+		models.EventOrderCreated:       true,
+		models.EventOrderStatusChanged: true,
+	}
+
+	if value := params.Get("type"); value != "" {
+		wanted := make(map[string]bool)
+		for _, t := range strings.Split(value, ",") {
+			if !types[t] {
+				return nil, models.ErrBadParamInput
+			}
+			wanted[t] = true
+		}
+		types = wanted
+	}
+
+	status := -1
+	if value := params.Get("status"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, models.ErrBadParamInput
+		}
+		status = parsed
+	}
+
+	var customerID int64
+	if value := params.Get("customer_id"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, models.ErrBadParamInput
+		}
+		customerID = parsed
+	}
+
+	return func(msg *stream.Message) bool {
+		update, ok := msg.Payload.(*models.OrderUpdate)
+		if !ok || !types[msg.Type] {
+			return false
+		}
+
+		if status >= 0 && update.Order.Status != status {
+			return false
+		}
+
+		if customerID != 0 && update.Order.CustomerID.Int64 != customerID {
+			return false
+		}
+
+		return true
+	}, nil
+}
+
+// Token endpoint issuing a stream token for the access token of the request,
+// for browsers whose EventSource cannot send the X-Access-Token header. It is
+// returned and set as a cookie of the stream path, the stream accept it from
+// either. It share the ID of the access token so revoking it revoke both. API
+// keys send their header and get none.
+func (h *OrderStreamHandler) Token(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.ClaimsFrom(r.Context())
+	if !ok {
+		utils.Error(w, http.StatusForbidden, models.ErrForbidden.Error())
+		return
+	}
+
+	token, err := h.Signer.Sign(auth.Claims{
+		ID:      claims.ID,
+		Subject: claims.Subject,
+		Email:   claims.Email,
+		Roles:   claims.Roles,
+		Scope:   auth.ScopeStream,
+	}, time.Now())
+	if err != nil {
+		logger.Error(err)
+		utils.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     auth.StreamTokenParam,
+		Value:    token,
+		Path:     "/v1/order/stream",
+		MaxAge:   int(h.Signer.TTL() / time.Second),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+
+	utils.JSON(w, http.StatusOK, utils.AccessTokenData{
+		Token:     token,
+		ExpiresIn: int64(h.Signer.TTL() / time.Second),
+	})
+}
+
+// writeEvent write msg in the text/event-stream format
+func writeEvent(w http.ResponseWriter, msg *stream.Message) error {
+	data, err := json.Marshal(msg.Payload)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", msg.ID, msg.Type, data)
+	return err
+}
+
+// Stream endpoint streaming the orders created and their status changes as
+// Server-Sent Events. A client reconnecting with a Last-Event-ID header first
+// get the events it missed, as far as the broker still keep them. Browsers
+// authenticate with a stream token from Token and ask for a new one when it
+// expired.
+func (h *OrderStreamHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		utils.Error(w, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+
+	filter, err := streamFilter(r.URL.Query())
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var lastID int64
+	if value := r.Header.Get("Last-Event-ID"); value != "" {
+		lastID, err = strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			utils.Error(w, http.StatusBadRequest, models.ErrBadParamInput.Error())
+			return
+		}
+	}
+
+	sub, backlog := h.Broker.Subscribe(lastID, filter)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// keep proxies from buffering the stream
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", reconnectDelay/time.Millisecond)
+	for _, msg := range backlog {
+		if err = writeEvent(w, msg); err != nil {
+			logger.Error(err)
+			return
+		}
+	}
+	flusher.Flush()
+
+	end := time.NewTimer(h.Lifetime)
+	defer end.Stop()
+
+	for {
+		select {
+		case msg, open := <-sub.C:
+			// closed when we fell behind, the client resume on reconnect
+			if !open {
+				return
+			}
+
+			if err = writeEvent(w, msg); err != nil {
+				logger.Error(err)
+				return
+			}
+			flusher.Flush()
+		case <-end.C:
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package http_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/soerjadi/exam/auth"
+	"github.com/soerjadi/exam/models"
+	orderHttp "github.com/soerjadi/exam/order/delivery/http"
+	"github.com/soerjadi/exam/stream"
+	"github.com/soerjadi/exam/utils"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v3"
+)
+
+func TestStreamResumeFromLastEventID(t *testing.T) {
+	broker := stream.NewBroker(10, 10)
+	sub, _ := broker.Subscribe(0, nil)
+
+	broker.Publish(models.EventOrderCreated, &models.OrderUpdate{Order: &models.Order{ID: 1, CustomerID: null.IntFrom(7)}})
+	broker.Publish(models.EventOrderCreated, &models.OrderUpdate{Order: &models.Order{ID: 2, CustomerID: null.IntFrom(8)}})
+	broker.Publish(models.EventOrderStatusChanged, &models.OrderUpdate{
+		From:  null.IntFrom(int64(models.OrderPending)),
+		Order: &models.Order{ID: 1, CustomerID: null.IntFrom(7), Status: models.OrderProccessed},
+	})
+
+	first := <-sub.C
+	second := <-sub.C
+	third := <-sub.C
+	sub.Close()
+
+	req, err := http.NewRequest("GET", "/v1/order/stream?customer_id=7", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Last-Event-ID", strconv.FormatInt(first.ID, 10))
+
+	handler := orderHttp.OrderStreamHandler{
+		Broker:   broker,
+		Lifetime: 10 * time.Millisecond,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.Stream(rec, req)
+
+	body := rec.Body.String()
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+	assert.NotContains(t, body, fmt.Sprintf("id: %d\n", first.ID))
+	assert.NotContains(t, body, fmt.Sprintf("id: %d\n", second.ID))
+	assert.Contains(t, body, fmt.Sprintf("id: %d\nevent: order.status_changed\ndata: ", third.ID))
+	assert.True(t, strings.HasPrefix(body, "retry: 1000\n\n"))
+}
+
+func TestStreamInvalidFilter(t *testing.T) {
+	req, err := http.NewRequest("GET", "/v1/order/stream?type=order.deleted", nil)
+	assert.NoError(t, err)
+
+	handler := orderHttp.OrderStreamHandler{
+		Broker:   stream.NewBroker(10, 10),
+		Lifetime: 10 * time.Millisecond,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.Stream(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestStreamToken(t *testing.T) {
+	handler := orderHttp.OrderStreamHandler{
+		Broker: stream.NewBroker(10, 10),
+		Signer: auth.NewSigner("s3cret", time.Minute),
+	}
+
+	t.Run("access token", func(t *testing.T) {
+		req, err := http.NewRequest("POST", "/v1/order/stream/token", nil)
+		assert.NoError(t, err)
+		req = req.WithContext(auth.WithClaims(req.Context(), &auth.Claims{ID: "abc", Subject: 4, Roles: []string{auth.RoleViewer}}))
+
+		rec := httptest.NewRecorder()
+		handler.Token(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var body struct {
+			Result utils.AccessTokenData `json:"result"`
+		}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, int64(60), body.Result.ExpiresIn)
+
+		claims, err := handler.Signer.Verify(body.Result.Token, time.Now())
+		assert.NoError(t, err)
+		assert.Equal(t, auth.ScopeStream, claims.Scope)
+		assert.Equal(t, "abc", claims.ID)
+		assert.Equal(t, int64(4), claims.Subject)
+		assert.Equal(t, []string{auth.RoleViewer}, claims.Roles)
+
+		cookies := rec.Result().Cookies()
+		assert.Len(t, cookies, 1)
+		assert.Equal(t, auth.StreamTokenParam, cookies[0].Name)
+		assert.Equal(t, body.Result.Token, cookies[0].Value)
+		assert.Equal(t, "/v1/order/stream", cookies[0].Path)
+		assert.True(t, cookies[0].HttpOnly)
+	})
+
+	t.Run("api key", func(t *testing.T) {
+		req, err := http.NewRequest("POST", "/v1/order/stream/token", nil)
+		assert.NoError(t, err)
+		req = req.WithContext(auth.WithPrincipal(req.Context(), &auth.KeyPrincipal{ID: 3}))
+
+		rec := httptest.NewRecorder()
+		handler.Token(rec, req)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
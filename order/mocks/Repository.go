@@ -39,13 +39,13 @@ func (_m *Repository) Delete(ctx context.Context, id int64) error {
 	return r0
 }
 
-// GetList provides a mock function with given fields: ctx, offset, limit
-func (_m *Repository) GetList(ctx context.Context, offset int64, limit int64) ([]*models.Order, int64, error) {
-	ret := _m.Called(ctx, offset, limit)
+// GetByCustomer provides a mock function with given fields: ctx, customerID, offset, limit
+func (_m *Repository) GetByCustomer(ctx context.Context, customerID int64, offset int64, limit int64) ([]*models.Order, int64, error) {
+	ret := _m.Called(ctx, customerID, offset, limit)
 
 	var r0 []*models.Order
-	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) []*models.Order); ok {
-		r0 = rf(ctx, offset, limit)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, int64) []*models.Order); ok {
+		r0 = rf(ctx, customerID, offset, limit)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*models.Order)
@@ -53,18 +53,191 @@ func (_m *Repository) GetList(ctx context.Context, offset int64, limit int64) ([
 	}
 
 	var r1 int64
-	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) int64); ok {
-		r1 = rf(ctx, offset, limit)
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, int64) int64); ok {
+		r1 = rf(ctx, customerID, offset, limit)
 	} else {
 		r1 = ret.Get(1).(int64)
 	}
 
 	var r2 error
-	if rf, ok := ret.Get(2).(func(context.Context, int64, int64) error); ok {
-		r2 = rf(ctx, offset, limit)
+	if rf, ok := ret.Get(2).(func(context.Context, int64, int64, int64) error); ok {
+		r2 = rf(ctx, customerID, offset, limit)
 	} else {
 		r2 = ret.Error(2)
 	}
 
 	return r0, r1, r2
 }
+
+// GetByID provides a mock function with given fields: ctx, id
+func (_m *Repository) GetByID(ctx context.Context, id int64) (*models.Order, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *models.Order
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *models.Order); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Order)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCoupon provides a mock function with given fields: ctx, orderID
+func (_m *Repository) GetCoupon(ctx context.Context, orderID int64) (*models.OrderCoupon, error) {
+	ret := _m.Called(ctx, orderID)
+
+	var r0 *models.OrderCoupon
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *models.OrderCoupon); ok {
+		r0 = rf(ctx, orderID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.OrderCoupon)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, orderID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetItems provides a mock function with given fields: ctx, orderID
+func (_m *Repository) GetItems(ctx context.Context, orderID int64) ([]*models.OrderItem, error) {
+	ret := _m.Called(ctx, orderID)
+
+	var r0 []*models.OrderItem
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []*models.OrderItem); ok {
+		r0 = rf(ctx, orderID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.OrderItem)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, orderID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetList provides a mock function with given fields: ctx, filter, offset, limit
+func (_m *Repository) GetList(ctx context.Context, filter *models.OrderFilter, offset int64, limit int64) ([]*models.Order, int64, error) {
+	ret := _m.Called(ctx, filter, offset, limit)
+
+	var r0 []*models.Order
+	if rf, ok := ret.Get(0).(func(context.Context, *models.OrderFilter, int64, int64) []*models.Order); ok {
+		r0 = rf(ctx, filter, offset, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Order)
+		}
+	}
+
+	var r1 int64
+	if rf, ok := ret.Get(1).(func(context.Context, *models.OrderFilter, int64, int64) int64); ok {
+		r1 = rf(ctx, filter, offset, limit)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, *models.OrderFilter, int64, int64) error); ok {
+		r2 = rf(ctx, filter, offset, limit)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetPromotions provides a mock function with given fields: ctx, orderID
+func (_m *Repository) GetPromotions(ctx context.Context, orderID int64) ([]*models.OrderPromotion, error) {
+	ret := _m.Called(ctx, orderID)
+
+	var r0 []*models.OrderPromotion
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []*models.OrderPromotion); ok {
+		r0 = rf(ctx, orderID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.OrderPromotion)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, orderID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetShipping provides a mock function with given fields: ctx, orderID
+func (_m *Repository) GetShipping(ctx context.Context, orderID int64) (*models.OrderShipping, error) {
+	ret := _m.Called(ctx, orderID)
+
+	var r0 *models.OrderShipping
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *models.OrderShipping); ok {
+		r0 = rf(ctx, orderID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.OrderShipping)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, orderID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReleaseStock provides a mock function with given fields: ctx, orderID
+func (_m *Repository) ReleaseStock(ctx context.Context, orderID int64) error {
+	ret := _m.Called(ctx, orderID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, orderID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: ctx, _a1
+func (_m *Repository) Update(ctx context.Context, _a1 *models.Order) error {
+	ret := _m.Called(ctx, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Order) error); ok {
+		r0 = rf(ctx, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -8,7 +8,15 @@ import (
 
 // Repository represent the order repository interface
 type Repository interface {
-	GetList(ctx context.Context, offset int64, limit int64) ([]*models.Order, int64, error)
+	GetList(ctx context.Context, filter *models.OrderFilter, offset int64, limit int64) ([]*models.Order, int64, error)
+	GetByCustomer(ctx context.Context, customerID int64, offset int64, limit int64) ([]*models.Order, int64, error)
+	GetByID(ctx context.Context, id int64) (*models.Order, error)
 	Create(ctx context.Context, order *models.Order) error
+	GetItems(ctx context.Context, orderID int64) ([]*models.OrderItem, error)
+	GetPromotions(ctx context.Context, orderID int64) ([]*models.OrderPromotion, error)
+	GetCoupon(ctx context.Context, orderID int64) (*models.OrderCoupon, error)
+	GetShipping(ctx context.Context, orderID int64) (*models.OrderShipping, error)
+	Update(ctx context.Context, order *models.Order) error
+	ReleaseStock(ctx context.Context, orderID int64) error
 	Delete(ctx context.Context, id int64) error
 }
@@ -4,7 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 
+	"github.com/soerjadi/exam/database"
 	"github.com/soerjadi/exam/models"
 	"github.com/soerjadi/exam/order"
 	"github.com/soerjadi/exam/utils"
@@ -22,7 +24,7 @@ func NewPGOrderRepository(Conn *sql.DB) order.Repository {
 }
 
 func (o *pgOrderRepository) fetch(ctx context.Context, query string, args ...interface{}) ([]*models.Order, error) {
-	rows, err := o.Conn.QueryContext(ctx, query, args...)
+	rows, err := database.ExecutorFrom(ctx, o.Conn).QueryContext(ctx, query, args...)
 	if err != nil {
 		logger.Error(err)
 		return nil, err
@@ -41,11 +43,17 @@ func (o *pgOrderRepository) fetch(ctx context.Context, query string, args ...int
 
 		err = rows.Scan(
 			&t.ID,
+			&t.CustomerID,
 			&t.ProductID,
 			&t.Amount,
 			&t.Price,
+			&t.Discount,
+			&t.Total,
+			&t.Tax,
+			&t.TaxIncluded,
 			&t.Status,
 			&t.Created,
+			&t.Version,
 		)
 
 		if err != nil {
@@ -60,7 +68,7 @@ func (o *pgOrderRepository) fetch(ctx context.Context, query string, args ...int
 }
 
 func (o *pgOrderRepository) fetchRow(ctx context.Context, query string, args ...interface{}) (*sql.Row, error) {
-	stmt, err := o.Conn.PrepareContext(ctx, query)
+	stmt, err := database.ExecutorFrom(ctx, o.Conn).PrepareContext(ctx, query)
 
 	if err != nil {
 		logger.Error(err)
@@ -77,21 +85,132 @@ func (o *pgOrderRepository) fetchRow(ctx context.Context, query string, args ...
 	return stmt.QueryRow(args...), nil
 }
 
-func (o *pgOrderRepository) GetList(ctx context.Context, offset int64, limit int64) (orders []*models.Order, found int64, err error) {
-	query := `SELECT id, product_id, amount, price, status, created FROM orders ORDER BY created OFFSET ? LIMIT ?`
-	qCount := `SELECT count(id) FROM orders`
+// orderSorts map the sort options of a list of orders to their ORDER BY
+var orderSorts = map[string]string{
+	"":         "created",
+	"created":  "created",
+	"-created": "created DESC",
+	"total":    "total",
+	"-total":   "total DESC",
+	"id":       "id",
+	"-id":      "id DESC",
+	"status":   "status",
+	"-status":  "status DESC",
+}
+
+// where build the condition selecting the orders matching filter
+func where(filter *models.OrderFilter) (string, []interface{}) {
+	conditions := make([]string, 0)
+	args := make([]interface{}, 0)
+
+	if filter == nil {
+		return "", args
+	}
+
+	if filter.Status.Valid {
+		conditions = append(conditions, "status = ?")
+		args = append(args, filter.Status.Int64)
+	}
+
+	if filter.ProductID.Valid {
+		conditions = append(conditions, "(product_id = ? OR id IN (SELECT order_id FROM order_item WHERE product_id = ?))")
+		args = append(args, filter.ProductID.Int64, filter.ProductID.Int64)
+	}
+
+	if filter.CustomerID.Valid {
+		conditions = append(conditions, "customer_id = ?")
+		args = append(args, filter.CustomerID.Int64)
+	}
 
-	result, err := o.fetch(ctx, query, offset, limit)
+	if filter.From.Valid {
+		conditions = append(conditions, "created >= ?")
+		args = append(args, filter.From.Time)
+	}
+
+	if filter.To.Valid {
+		conditions = append(conditions, "created < ?")
+		args = append(args, filter.To.Time)
+	}
+
+	if filter.MinTotal.Valid {
+		conditions = append(conditions, "total >= ?")
+		args = append(args, filter.MinTotal.Float64)
+	}
+
+	if filter.MaxTotal.Valid {
+		conditions = append(conditions, "total <= ?")
+		args = append(args, filter.MaxTotal.Float64)
+	}
+
+	if query := strings.TrimPrefix(strings.TrimSpace(filter.Query), "#"); query != "" {
+		conditions = append(conditions, "(CAST(id AS TEXT) = ? OR id IN (SELECT order_id FROM invoices WHERE number ILIKE ?))")
+		args = append(args, query, "%"+query+"%")
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// GetList list the orders matching filter, every order when it is nil.
+// models.ErrBadParamInput is returned for an unknown sort.
+func (o *pgOrderRepository) GetList(ctx context.Context, filter *models.OrderFilter, offset int64, limit int64) (orders []*models.Order, found int64, err error) {
+	sort := ""
+	if filter != nil {
+		sort = filter.Sort
+	}
+
+	orderBy, ok := orderSorts[sort]
+	if !ok {
+		return nil, 0, models.ErrBadParamInput
+	}
+
+	condition, args := where(filter)
+
+	query := `SELECT id, customer_id, product_id, amount, price, discount, total, tax, tax_included, status, created, version FROM orders` +
+		condition + ` ORDER BY ` + orderBy + ` OFFSET ? LIMIT ?`
+	qCount := `SELECT count(id) FROM orders` + condition
+
+	result, err := o.fetch(ctx, query, append(args, offset, limit)...)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	rows, err := o.fetchRow(ctx, qCount)
+	rows, err := o.fetchRow(ctx, qCount, args...)
+	if err != nil {
+		logger.Error(err)
+		return nil, 0, err
+	}
+
+	var count int64
+	err = rows.Scan(&count)
+
 	if err != nil {
 		logger.Error(err)
 		return nil, 0, err
 	}
 
+	return result, count, nil
+}
+
+// GetByCustomer list orders of a customer, newest first
+func (o *pgOrderRepository) GetByCustomer(ctx context.Context, customerID int64, offset int64, limit int64) ([]*models.Order, int64, error) {
+	query := `SELECT id, customer_id, product_id, amount, price, discount, total, tax, tax_included, status, created, version FROM orders
+		WHERE customer_id = ? ORDER BY created DESC OFFSET ? LIMIT ?`
+	qCount := `SELECT count(id) FROM orders WHERE customer_id = ?`
+
+	result, err := o.fetch(ctx, query, customerID, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := o.fetchRow(ctx, qCount, customerID)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	var count int64
 	err = rows.Scan(&count)
 
@@ -103,50 +222,488 @@ func (o *pgOrderRepository) GetList(ctx context.Context, offset int64, limit int
 	return result, count, nil
 }
 
+func (o *pgOrderRepository) GetByID(ctx context.Context, id int64) (order *models.Order, err error) {
+	query := `SELECT id, customer_id, product_id, amount, price, discount, total, tax, tax_included, status, created, version FROM orders WHERE id = ?`
+
+	orders, err := o.fetch(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(orders) > 0 {
+		order = orders[0]
+	} else {
+		return nil, models.ErrNotFound
+	}
+
+	return
+}
+
+// Create store the order together with its items, the promotions and coupon
+// applied to it and where it is shipped. It join the transaction carried by ctx when there is one.
+// Orders placed without items are stored with a single item made of the
+// product, amount and price of the order.
 func (o *pgOrderRepository) Create(ctx context.Context, order *models.Order) error {
-	query := `INSERT INTO orders(product_id, amount, price, status) VALUES(?, ?, ?, ?) returning id`
+	items := order.Items
+	if len(items) == 0 {
+		items = []*models.OrderItem{
+			&models.OrderItem{ProductID: order.ProductID, Amount: order.Amount, Price: order.Price},
+		}
+	}
+
+	var lastID int64
+	err := database.RunInTx(ctx, o.Conn, func(tx *sql.Tx) error {
+		query := `INSERT INTO orders(customer_id, product_id, amount, price, discount, total, tax, tax_included, status)
+			VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?) returning id`
+
+		stmt, err := tx.PrepareContext(ctx, query)
+		if err != nil {
+			return err
+		}
+
+		result, err := stmt.ExecContext(ctx, order.CustomerID, order.ProductID, order.Amount, order.Price, order.Discount, order.Total,
+			order.Tax, order.TaxIncluded, order.Status)
+		if err != nil {
+			return err
+		}
+
+		lastID, err = result.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		stmt, err = tx.PrepareContext(ctx, `INSERT INTO order_item(order_id, product_id, amount, price, tax_name, tax_rate, tax) VALUES(?, ?, ?, ?, ?, ?, ?) returning id`)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			result, err = stmt.ExecContext(ctx, lastID, item.ProductID, item.Amount, item.Price, item.TaxName, item.TaxRate, item.Tax)
+			if err != nil {
+				logger.Error(err)
+				return err
+			}
+
+			itemID, err := result.LastInsertId()
+			if err != nil {
+				return err
+			}
+
+			item.ID = itemID
+			item.OrderID = lastID
+		}
+
+		if err = takeStock(ctx, tx, items); err != nil {
+			return err
+		}
+
+		if len(order.Promotions) > 0 {
+			stmt, err = tx.PrepareContext(ctx, `INSERT INTO order_promotion(order_id, promotion_id, name, discount) VALUES(?, ?, ?, ?) returning id`)
+			if err != nil {
+				return err
+			}
+
+			for _, promo := range order.Promotions {
+				result, err = stmt.ExecContext(ctx, lastID, promo.PromotionID, promo.Name, promo.Discount)
+				if err != nil {
+					logger.Error(err)
+					return err
+				}
+
+				promoID, err := result.LastInsertId()
+				if err != nil {
+					return err
+				}
+
+				promo.ID = promoID
+				promo.OrderID = lastID
+			}
+		}
+
+		if order.Shipping != nil {
+			if err = saveShipping(ctx, tx, lastID, order.Shipping); err != nil {
+				return err
+			}
+		}
+
+		if order.Coupon != nil {
+			return redeemCoupon(ctx, tx, lastID, order.Coupon)
+		}
+
+		return nil
+	})
 
-	stmt, err := o.Conn.PrepareContext(ctx, query)
 	if err != nil {
 		return err
 	}
 
-	result, err := stmt.ExecContext(ctx, order.ProductID, order.Amount, order.Price, order.Status)
+	order.ID = lastID
+	order.Items = items
+	order.Version = 1
+	return nil
+}
+
+// takeStock take the pieces of items out of the stock of their products
+// inside the order transaction
+func takeStock(ctx context.Context, tx *sql.Tx, items []*models.OrderItem) error {
+	stmt, err := tx.PrepareContext(ctx, `UPDATE products SET stock = stock - ? WHERE id = ?`)
 	if err != nil {
 		return err
 	}
 
+	for _, item := range items {
+		if _, err = stmt.ExecContext(ctx, item.Amount, item.ProductID); err != nil {
+			logger.Error(err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// releaseStock put the pieces of an order back in the stock of their
+// products, lines of the same product are summed as postgres update a row
+// once per statement
+const releaseStock = `UPDATE products SET stock = stock + i.amount
+	FROM (SELECT product_id, sum(amount) AS amount FROM order_item WHERE order_id = ? GROUP BY product_id) i
+	WHERE products.id = i.product_id`
+
+// ReleaseStock put the pieces of the order back in the stock of their
+// products, it join the transaction carried by ctx when there is one
+func (o *pgOrderRepository) ReleaseStock(ctx context.Context, orderID int64) error {
+	if _, err := database.ExecutorFrom(ctx, o.Conn).ExecContext(ctx, releaseStock, orderID); err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// redeemCoupon count a use of the coupon inside the order transaction. The
+// conditional update lock the coupon row so concurrent orders redeeming the
+// same coupon wait for each other and the per customer count that follow see
+// the redemptions committed before.
+func redeemCoupon(ctx context.Context, tx *sql.Tx, orderID int64, coupon *models.OrderCoupon) error {
+	query := `UPDATE coupons SET used = used + 1 WHERE id = ? AND active = true
+		AND (max_uses = 0 OR used < max_uses) AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)`
+
+	res, err := tx.ExecContext(ctx, query, coupon.CouponID)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected != 1 {
+		return models.ErrCouponUnavailable
+	}
+
+	var limit, used int64
+	err = tx.QueryRowContext(ctx, `SELECT per_customer, (SELECT count(id) FROM order_coupon WHERE coupon_id = ? AND customer = ?) FROM coupons WHERE id = ?`,
+		coupon.CouponID, coupon.Customer, coupon.CouponID).Scan(&limit, &used)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	if limit > 0 && used >= limit {
+		return models.ErrCouponUnavailable
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO order_coupon(order_id, coupon_id, code, customer, discount) VALUES(?, ?, ?, ?, ?) returning id`)
+	if err != nil {
+		return err
+	}
+
+	result, err := stmt.ExecContext(ctx, orderID, coupon.CouponID, coupon.Code, coupon.Customer, coupon.Discount)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
 	lastID, err := result.LastInsertId()
 	if err != nil {
 		return err
 	}
 
-	order.ID = lastID
+	coupon.ID = lastID
+	coupon.OrderID = orderID
 	return nil
 }
 
+// saveShipping store the destination and shipping cost of the order
+func saveShipping(ctx context.Context, tx *sql.Tx, orderID int64, shipping *models.OrderShipping) error {
+	query := `INSERT INTO order_shipping(order_id, method_id, method, cost, name, line1, line2, city, region, postal_code, country, phone)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) returning id`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	result, err := stmt.ExecContext(ctx, orderID, shipping.MethodID, shipping.Method, shipping.Cost, shipping.Name, shipping.Line1,
+		shipping.Line2, shipping.City, shipping.Region, shipping.PostalCode, shipping.Country, shipping.Phone)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	lastID, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	shipping.ID = lastID
+	shipping.OrderID = orderID
+	return nil
+}
+
+func (o *pgOrderRepository) GetShipping(ctx context.Context, orderID int64) (*models.OrderShipping, error) {
+	query := `SELECT id, order_id, method_id, method, cost, name, line1, line2, city, region, postal_code, country, phone
+		FROM order_shipping WHERE order_id = ?`
+
+	rows, err := o.fetchRow(ctx, query, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	t := new(models.OrderShipping)
+	err = rows.Scan(&t.ID, &t.OrderID, &t.MethodID, &t.Method, &t.Cost, &t.Name, &t.Line1, &t.Line2, &t.City,
+		&t.Region, &t.PostalCode, &t.Country, &t.Phone)
+
+	if err == sql.ErrNoRows {
+		return nil, models.ErrNotFound
+	}
+
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (o *pgOrderRepository) GetCoupon(ctx context.Context, orderID int64) (*models.OrderCoupon, error) {
+	query := `SELECT id, order_id, coupon_id, code, customer, discount FROM order_coupon WHERE order_id = ?`
+
+	rows, err := o.fetchRow(ctx, query, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	t := new(models.OrderCoupon)
+	err = rows.Scan(&t.ID, &t.OrderID, &t.CouponID, &t.Code, &t.Customer, &t.Discount)
+
+	if err == sql.ErrNoRows {
+		return nil, models.ErrNotFound
+	}
+
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (o *pgOrderRepository) GetItems(ctx context.Context, orderID int64) ([]*models.OrderItem, error) {
+	query := `SELECT id, order_id, product_id, amount, price, tax_name, tax_rate, tax FROM order_item WHERE order_id = ? ORDER BY id`
+
+	rows, err := database.ExecutorFrom(ctx, o.Conn).QueryContext(ctx, query, orderID)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Error(err)
+		}
+	}()
+
+	result := make([]*models.OrderItem, 0)
+	for rows.Next() {
+		t := new(models.OrderItem)
+
+		err = rows.Scan(
+			&t.ID,
+			&t.OrderID,
+			&t.ProductID,
+			&t.Amount,
+			&t.Price,
+			&t.TaxName,
+			&t.TaxRate,
+			&t.Tax,
+		)
+
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+
+		result = append(result, t)
+	}
+
+	return result, nil
+}
+
+func (o *pgOrderRepository) GetPromotions(ctx context.Context, orderID int64) ([]*models.OrderPromotion, error) {
+	query := `SELECT id, order_id, promotion_id, name, discount FROM order_promotion WHERE order_id = ? ORDER BY id`
+
+	rows, err := o.Conn.QueryContext(ctx, query, orderID)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Error(err)
+		}
+	}()
+
+	result := make([]*models.OrderPromotion, 0)
+	for rows.Next() {
+		t := new(models.OrderPromotion)
+
+		err = rows.Scan(
+			&t.ID,
+			&t.OrderID,
+			&t.PromotionID,
+			&t.Name,
+			&t.Discount,
+		)
+
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+
+		result = append(result, t)
+	}
+
+	return result, nil
+}
+
+func (o *pgOrderRepository) Update(ctx context.Context, order *models.Order) error {
+	query := `UPDATE orders SET status = ?, version = version + 1 WHERE id = ? AND version = ?`
+
+	stmt, err := database.ExecutorFrom(ctx, o.Conn).PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	res, err := stmt.ExecContext(ctx, order.Status, order.ID, order.Version)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected != 1 {
+		return models.ErrConflict
+	}
+
+	order.Version++
+	return nil
+}
+
+// Delete remove an order nothing happened to yet. Orders with payments,
+// shipments, returns or invoices are kept for the record, deleting them
+// return models.ErrConflict.
 func (o *pgOrderRepository) Delete(ctx context.Context, id int64) error {
-	query := `DELETE FROM orders WHERE id = ?`
+	tx, err := o.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	var status int
+	err = tx.QueryRowContext(ctx, `SELECT status FROM orders WHERE id = ? FOR UPDATE`, id).Scan(&status)
+	if err == sql.ErrNoRows {
+		_ = tx.Rollback()
+		return models.ErrNotFound
+	}
 
-	stmt, err := o.Conn.PrepareContext(ctx, query)
 	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	var used bool
+	err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM payments WHERE order_id = ?)
+		OR EXISTS (SELECT 1 FROM shipments WHERE order_id = ?)
+		OR EXISTS (SELECT 1 FROM returns WHERE order_id = ?)
+		OR EXISTS (SELECT 1 FROM invoices WHERE order_id = ?)`, id, id, id, id).Scan(&used)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if used {
+		_ = tx.Rollback()
+		return models.ErrConflict
+	}
+
+	// the use of the coupon is given back with the order
+	if _, err = tx.ExecContext(ctx, `UPDATE coupons SET used = used - 1 WHERE id IN (SELECT coupon_id FROM order_coupon WHERE order_id = ?)`, id); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	// and so are its pieces, unless it was cancelled and they already are
+	if status == models.OrderPending || status == models.OrderProccessed {
+		if _, err = tx.ExecContext(ctx, releaseStock, id); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM order_coupon WHERE order_id = ?`, id); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM order_shipping WHERE order_id = ?`, id); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM order_promotion WHERE order_id = ?`, id); err != nil {
+		_ = tx.Rollback()
 		return err
 	}
 
-	res, err := stmt.ExecContext(ctx, id)
+	if _, err = tx.ExecContext(ctx, `DELETE FROM order_item WHERE order_id = ?`, id); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM orders WHERE id = ?`, id)
 	if err != nil {
+		_ = tx.Rollback()
 		return err
 	}
 
 	rowsAffected, err := res.RowsAffected()
 	if err != nil {
+		_ = tx.Rollback()
 		return err
 	}
 
 	if rowsAffected != 1 {
+		_ = tx.Rollback()
 		err = fmt.Errorf("Internal Server Error")
 		return err
 	}
 
-	return nil
+	return tx.Commit()
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/soerjadi/exam/models"
 	"github.com/soerjadi/exam/order/repository"
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v3"
 )
 
 func TestGetList(t *testing.T) {
@@ -37,20 +38,20 @@ func TestGetList(t *testing.T) {
 	}
 
 	found := int64(2)
-	rows := sqlmock.NewRows([]string{"id", "product_id", "amount", "price", "status", "created"}).
-		AddRow(mockOrder[0].ID, mockOrder[0].ProductID, mockOrder[0].Amount, mockOrder[0].Price, mockOrder[0].Status, mockOrder[0].Created).
-		AddRow(mockOrder[0].ID, mockOrder[1].ProductID, mockOrder[1].Amount, mockOrder[1].Price, mockOrder[1].Status, mockOrder[1].Created)
+	rows := sqlmock.NewRows([]string{"id", "customer_id", "product_id", "amount", "price", "discount", "total", "tax", "tax_included", "status", "created", "version"}).
+		AddRow(mockOrder[0].ID, nil, mockOrder[0].ProductID, mockOrder[0].Amount, mockOrder[0].Price, mockOrder[0].Discount, mockOrder[0].Total, 0.0, false, mockOrder[0].Status, mockOrder[0].Created, 1).
+		AddRow(mockOrder[0].ID, 4, mockOrder[1].ProductID, mockOrder[1].Amount, mockOrder[1].Price, mockOrder[1].Discount, mockOrder[1].Total, 0.0, false, mockOrder[1].Status, mockOrder[1].Created, 1)
 
 	rowCount := sqlmock.NewRows([]string{"count"}).AddRow(found)
 
-	query := "SELECT id, product_id, amount, price, status, created FROM orders ORDER BY created OFFSET \\? LIMIT \\?"
+	query := "SELECT id, customer_id, product_id, amount, price, discount, total, tax, tax_included, status, created, version FROM orders ORDER BY created OFFSET \\? LIMIT \\?"
 	cQuery := "SELECT count\\(id\\) FROM orders"
 
 	mock.ExpectQuery(query).WithArgs(int64(0), int64(10)).WillReturnRows(rows)
 	mock.ExpectPrepare(cQuery).ExpectQuery().WillReturnRows(rowCount)
 
 	p := repository.NewPGOrderRepository(db)
-	result, count, err := p.GetList(context.TODO(), int64(0), int64(10))
+	result, count, err := p.GetList(context.TODO(), nil, int64(0), int64(10))
 
 	assert.NoError(t, err)
 	assert.Equal(t, found, count)
@@ -70,31 +71,447 @@ func TestCreate(t *testing.T) {
 		Status:    models.OrderProccessed,
 	}
 
-	query := "INSERT INTO orders\\(product_id, amount, price, status\\) VALUES\\(\\?, \\?, \\?, \\?\\) returning id"
-	prep := mock.ExpectPrepare(query)
-	prep.ExpectExec().WithArgs(order.ProductID, order.Amount, order.Price, order.Status).WillReturnResult(sqlmock.NewResult(89, 1))
+	query := "INSERT INTO orders\\(customer_id, product_id, amount, price, discount, total, tax, tax_included, status\\)\\s+VALUES\\((.+)\\) returning id"
+	itemQuery := "INSERT INTO order_item\\(order_id, product_id, amount, price, tax_name, tax_rate, tax\\)"
+	stockQuery := "UPDATE products SET stock = stock - \\? WHERE id = \\?"
+
+	t.Run("without promotion", func(t *testing.T) {
+		mock.ExpectBegin()
+		prep := mock.ExpectPrepare(query)
+		prep.ExpectExec().WithArgs(order.CustomerID, order.ProductID, order.Amount, order.Price, order.Discount, order.Total, order.Tax, order.TaxIncluded, order.Status).WillReturnResult(sqlmock.NewResult(89, 1))
+		mock.ExpectPrepare(itemQuery).ExpectExec().WithArgs(int64(89), order.ProductID, order.Amount, order.Price, "", 0.0, 0.0).WillReturnResult(sqlmock.NewResult(1, 1))
+		// the pieces ordered are taken out of the stock
+		mock.ExpectPrepare(stockQuery).ExpectExec().WithArgs(order.Amount, order.ProductID).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		p := repository.NewPGOrderRepository(db)
+
+		err = p.Create(context.TODO(), order)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(89), order.ID)
+		assert.Len(t, order.Items, 1)
+		assert.Equal(t, int64(89), order.Items[0].OrderID)
+	})
+
+	t.Run("with promotion", func(t *testing.T) {
+		promoted := &models.Order{
+			ProductID: int64(2),
+			Amount:    int64(3),
+			Price:     8000.0,
+			Discount:  8000.0,
+			Total:     16000.0,
+			Promotions: []*models.OrderPromotion{
+				&models.OrderPromotion{PromotionID: 4, Name: "buy 2 get 1", Discount: 8000.0},
+			},
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectPrepare(query).ExpectExec().
+			WithArgs(promoted.CustomerID, promoted.ProductID, promoted.Amount, promoted.Price, promoted.Discount, promoted.Total, promoted.Tax, promoted.TaxIncluded, promoted.Status).
+			WillReturnResult(sqlmock.NewResult(90, 1))
+		mock.ExpectPrepare(itemQuery).ExpectExec().WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectPrepare(stockQuery).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectPrepare("INSERT INTO order_promotion\\(order_id, promotion_id, name, discount\\)").ExpectExec().
+			WithArgs(int64(90), int64(4), "buy 2 get 1", 8000.0).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		p := repository.NewPGOrderRepository(db)
+
+		err = p.Create(context.TODO(), promoted)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(90), promoted.Promotions[0].OrderID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("with coupon", func(t *testing.T) {
+		couponed := &models.Order{
+			ProductID: int64(2),
+			Amount:    int64(1),
+			Price:     8000.0,
+			Discount:  800.0,
+			Total:     7200.0,
+			Coupon:    &models.OrderCoupon{CouponID: 5, Code: "XMAS", Customer: "jane@example.com", Discount: 800.0},
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectPrepare(query).ExpectExec().
+			WithArgs(couponed.CustomerID, couponed.ProductID, couponed.Amount, couponed.Price, couponed.Discount, couponed.Total, couponed.Tax, couponed.TaxIncluded, couponed.Status).
+			WillReturnResult(sqlmock.NewResult(91, 1))
+		mock.ExpectPrepare(itemQuery).ExpectExec().WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectPrepare(stockQuery).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE coupons SET used = used \\+ 1 WHERE id = \\? AND active = true").WithArgs(int64(5)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("SELECT per_customer").WithArgs(int64(5), "jane@example.com", int64(5)).
+			WillReturnRows(sqlmock.NewRows([]string{"per_customer", "count"}).AddRow(2, 1))
+		mock.ExpectPrepare("INSERT INTO order_coupon\\(order_id, coupon_id, code, customer, discount\\)").ExpectExec().
+			WithArgs(int64(91), int64(5), "XMAS", "jane@example.com", 800.0).
+			WillReturnResult(sqlmock.NewResult(3, 1))
+		mock.ExpectCommit()
+
+		p := repository.NewPGOrderRepository(db)
+
+		err = p.Create(context.TODO(), couponed)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), couponed.Coupon.ID)
+		assert.Equal(t, int64(91), couponed.Coupon.OrderID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("with shipping", func(t *testing.T) {
+		shipped := &models.Order{
+			ProductID: int64(2),
+			Amount:    int64(1),
+			Price:     8000.0,
+			Total:     17000.0,
+			Shipping: &models.OrderShipping{MethodID: 1, Method: "regular", Cost: 9000.0, Name: "Jane",
+				Line1: "Jl. Sudirman 1", City: "Jakarta", Region: "DKI Jakarta", PostalCode: "10220", Country: "ID"},
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectPrepare(query).ExpectExec().WillReturnResult(sqlmock.NewResult(94, 1))
+		mock.ExpectPrepare(itemQuery).ExpectExec().WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectPrepare(stockQuery).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectPrepare("INSERT INTO order_shipping\\(order_id, method_id, method, cost, (.+)\\)").ExpectExec().
+			WithArgs(int64(94), int64(1), "regular", 9000.0, "Jane", "Jl. Sudirman 1", "", "Jakarta", "DKI Jakarta", "10220", "ID", "").
+			WillReturnResult(sqlmock.NewResult(4, 1))
+		mock.ExpectCommit()
+
+		p := repository.NewPGOrderRepository(db)
+
+		err = p.Create(context.TODO(), shipped)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(4), shipped.Shipping.ID)
+		assert.Equal(t, int64(94), shipped.Shipping.OrderID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("coupon used up", func(t *testing.T) {
+		couponed := &models.Order{
+			ProductID: int64(2),
+			Amount:    int64(1),
+			Price:     8000.0,
+			Coupon:    &models.OrderCoupon{CouponID: 6, Code: "ONCE"},
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectPrepare(query).ExpectExec().WillReturnResult(sqlmock.NewResult(92, 1))
+		mock.ExpectPrepare(itemQuery).ExpectExec().WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectPrepare(stockQuery).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE coupons SET used = used \\+ 1").WithArgs(int64(6)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		p := repository.NewPGOrderRepository(db)
+
+		err = p.Create(context.TODO(), couponed)
+
+		assert.Equal(t, models.ErrCouponUnavailable, err)
+		assert.Equal(t, int64(0), couponed.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("per customer limit reached", func(t *testing.T) {
+		couponed := &models.Order{
+			ProductID: int64(2),
+			Amount:    int64(1),
+			Price:     8000.0,
+			Coupon:    &models.OrderCoupon{CouponID: 7, Code: "WELCOME", Customer: "jane@example.com"},
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectPrepare(query).ExpectExec().WillReturnResult(sqlmock.NewResult(93, 1))
+		mock.ExpectPrepare(itemQuery).ExpectExec().WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectPrepare(stockQuery).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE coupons SET used = used \\+ 1").WithArgs(int64(7)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("SELECT per_customer").WithArgs(int64(7), "jane@example.com", int64(7)).
+			WillReturnRows(sqlmock.NewRows([]string{"per_customer", "count"}).AddRow(1, 1))
+		mock.ExpectRollback()
+
+		p := repository.NewPGOrderRepository(db)
+
+		err = p.Create(context.TODO(), couponed)
+
+		assert.Equal(t, models.ErrCouponUnavailable, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+const (
+	lockOrderQuery = "SELECT status FROM orders WHERE id = \\? FOR UPDATE"
+	historyQuery   = "SELECT EXISTS \\(SELECT 1 FROM payments WHERE order_id = \\?\\)(.+)invoices WHERE order_id = \\?\\)"
+)
+
+func TestDelete(t *testing.T) {
+	expectDelete := func(mock sqlmock.Sqlmock) {
+		mock.ExpectExec("DELETE FROM order_coupon WHERE order_id = \\?").WithArgs(int64(9)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("DELETE FROM order_shipping WHERE order_id = \\?").WithArgs(int64(9)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("DELETE FROM order_promotion WHERE order_id = \\?").WithArgs(int64(9)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("DELETE FROM order_item WHERE order_id = \\?").WithArgs(int64(9)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("DELETE FROM orders WHERE id = \\?").WithArgs(int64(9)).WillReturnResult(sqlmock.NewResult(9, 1))
+		mock.ExpectCommit()
+	}
+
+	t.Run("pending", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockOrderQuery).WithArgs(int64(9)).WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(models.OrderPending))
+		mock.ExpectQuery(historyQuery).WithArgs(int64(9), int64(9), int64(9), int64(9)).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectExec("UPDATE coupons SET used = used - 1").WithArgs(int64(9)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE products SET stock = stock \\+ i.amount").WithArgs(int64(9)).WillReturnResult(sqlmock.NewResult(0, 2))
+		expectDelete(mock)
+
+		p := repository.NewPGOrderRepository(db)
+
+		err = p.Delete(context.TODO(), int64(9))
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("cancelled keep the stock", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockOrderQuery).WithArgs(int64(9)).WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(models.OrderCancelled))
+		mock.ExpectQuery(historyQuery).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectExec("UPDATE coupons SET used = used - 1").WithArgs(int64(9)).WillReturnResult(sqlmock.NewResult(0, 1))
+		expectDelete(mock)
+
+		p := repository.NewPGOrderRepository(db)
+
+		err = p.Delete(context.TODO(), int64(9))
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("with history", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockOrderQuery).WithArgs(int64(9)).WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(models.OrderShipped))
+		mock.ExpectQuery(historyQuery).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectRollback()
+
+		p := repository.NewPGOrderRepository(db)
+
+		err = p.Delete(context.TODO(), int64(9))
+		assert.Equal(t, models.ErrConflict, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockOrderQuery).WithArgs(int64(9)).WillReturnRows(sqlmock.NewRows([]string{"status"}))
+		mock.ExpectRollback()
+
+		p := repository.NewPGOrderRepository(db)
+
+		err = p.Delete(context.TODO(), int64(9))
+		assert.Equal(t, models.ErrNotFound, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestReleaseStock(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectExec("UPDATE products SET stock = stock \\+ i.amount\\s+FROM \\(SELECT product_id, sum\\(amount\\) AS amount FROM order_item WHERE order_id = \\? GROUP BY product_id\\) i").
+		WithArgs(int64(9)).WillReturnResult(sqlmock.NewResult(0, 2))
 
 	p := repository.NewPGOrderRepository(db)
 
-	err = p.Create(context.TODO(), order)
+	err = p.ReleaseStock(context.TODO(), int64(9))
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "customer_id", "product_id", "amount", "price", "discount", "total", "tax", "tax_included", "status", "created", "version"}).
+		AddRow(8, nil, 2, 20, 160000.0, 0.0, 3200000.0, 0.0, false, models.OrderProccessed, time.Now(), 1)
+
+	query := "SELECT id, customer_id, product_id, amount, price, discount, total, tax, tax_included, status, created, version FROM orders WHERE id = \\?"
+
+	mock.ExpectQuery(query).WithArgs(int64(8)).WillReturnRows(rows)
+	o := repository.NewPGOrderRepository(db)
+
+	order, err := o.GetByID(context.TODO(), int64(8))
 
 	assert.NoError(t, err)
-	assert.Equal(t, int64(89), order.ID)
+	assert.Equal(t, int64(8), order.ID)
 }
 
-func TestDelete(t *testing.T) {
+func TestUpdate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	order := &models.Order{
+		ID:      8,
+		Status:  models.OrderShipped,
+		Version: 2,
+	}
+
+	query := "UPDATE orders SET status = \\?, version = version \\+ 1 WHERE id = \\? AND version = \\?"
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectPrepare(query).ExpectExec().WithArgs(order.Status, order.ID, int64(2)).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		o := repository.NewPGOrderRepository(db)
+
+		err = o.Update(context.TODO(), order)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), order.Version)
+	})
+
+	t.Run("stale version", func(t *testing.T) {
+		mock.ExpectPrepare(query).ExpectExec().WithArgs(order.Status, order.ID, int64(3)).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		o := repository.NewPGOrderRepository(db)
+
+		err = o.Update(context.TODO(), order)
+		assert.Equal(t, models.ErrConflict, err)
+	})
+}
+
+func TestGetPromotions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "order_id", "promotion_id", "name", "discount"}).
+		AddRow(1, 8, 4, "10% off shoes", 1600.0)
+
+	query := "SELECT id, order_id, promotion_id, name, discount FROM order_promotion WHERE order_id = \\?"
+	mock.ExpectQuery(query).WithArgs(int64(8)).WillReturnRows(rows)
+
+	o := repository.NewPGOrderRepository(db)
+	promotions, err := o.GetPromotions(context.TODO(), int64(8))
+
+	assert.NoError(t, err)
+	assert.Len(t, promotions, 1)
+	assert.Equal(t, 1600.0, promotions[0].Discount)
+}
+
+func TestGetItems(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "order_id", "product_id", "amount", "price", "tax_name", "tax_rate", "tax"}).
+		AddRow(1, 8, 2, 3, 8000.0, "VAT", 11.0, 2640.0).
+		AddRow(2, 8, 5, 1, 12000.0, "", 0.0, 0.0)
+
+	query := "SELECT id, order_id, product_id, amount, price, tax_name, tax_rate, tax FROM order_item WHERE order_id = \\?"
+	mock.ExpectQuery(query).WithArgs(int64(8)).WillReturnRows(rows)
+
+	o := repository.NewPGOrderRepository(db)
+	items, err := o.GetItems(context.TODO(), int64(8))
+
+	assert.NoError(t, err)
+	assert.Len(t, items, 2)
+	assert.Equal(t, 2640.0, items[0].Tax)
+	assert.Equal(t, int64(5), items[1].ProductID)
+}
+
+func TestGetByCustomer(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
 
-	query := "DELETE FROM orders WHERE id = \\?"
+	rows := sqlmock.NewRows([]string{"id", "customer_id", "product_id", "amount", "price", "discount", "total", "tax", "tax_included", "status", "created", "version"}).
+		AddRow(12, 4, 2, 1, 10000.0, 0.0, 10000.0, 990.0, true, models.OrderPending, time.Now(), 1)
 
-	prep := mock.ExpectPrepare(query)
-	prep.ExpectExec().WithArgs(int64(9)).WillReturnResult(sqlmock.NewResult(9, 1))
+	query := "SELECT (.+) FROM orders WHERE customer_id = \\? ORDER BY created DESC OFFSET \\? LIMIT \\?"
+	mock.ExpectQuery(query).WithArgs(int64(4), int64(0), int64(10)).WillReturnRows(rows)
+	mock.ExpectPrepare("SELECT count\\(id\\) FROM orders WHERE customer_id = \\?").ExpectQuery().WithArgs(int64(4)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
 
 	p := repository.NewPGOrderRepository(db)
+	result, count, err := p.GetByCustomer(context.TODO(), int64(4), int64(0), int64(10))
 
-	err = p.Delete(context.TODO(), int64(9))
 	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	assert.Equal(t, int64(4), result[0].CustomerID.Int64)
+}
+
+func TestGetListFiltered(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	from := time.Date(2019, 11, 1, 0, 0, 0, 0, time.UTC)
+	filter := &models.OrderFilter{
+		Status:    null.IntFrom(int64(models.OrderShipped)),
+		ProductID: null.IntFrom(3),
+		From:      null.TimeFrom(from),
+		MinTotal:  null.FloatFrom(10000.0),
+		Query:     "#9",
+		Sort:      "-total",
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "customer_id", "product_id", "amount", "price", "discount", "total", "tax", "tax_included", "status", "created", "version"}).
+		AddRow(9, 4, 3, 1, 10000.0, 0.0, 10000.0, 0.0, false, models.OrderShipped, time.Now(), 1)
+
+	query := "SELECT (.+) FROM orders WHERE status = \\? AND \\(product_id = \\? OR id IN \\(SELECT order_id FROM order_item WHERE product_id = \\?\\)\\) " +
+		"AND created >= \\? AND total >= \\? AND \\(CAST\\(id AS TEXT\\) = \\? OR id IN \\(SELECT order_id FROM invoices WHERE number ILIKE \\?\\)\\) " +
+		"ORDER BY total DESC OFFSET \\? LIMIT \\?"
+
+	mock.ExpectQuery(query).
+		WithArgs(int64(models.OrderShipped), int64(3), int64(3), from, 10000.0, "9", "%9%", int64(0), int64(10)).
+		WillReturnRows(rows)
+	mock.ExpectPrepare("SELECT count\\(id\\) FROM orders WHERE status = \\?").ExpectQuery().
+		WithArgs(int64(models.OrderShipped), int64(3), int64(3), from, 10000.0, "9", "%9%").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	p := repository.NewPGOrderRepository(db)
+	result, count, err := p.GetList(context.TODO(), filter, int64(0), int64(10))
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	assert.Len(t, result, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetListUnknownSort(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	p := repository.NewPGOrderRepository(db)
+	_, _, err = p.GetList(context.TODO(), &models.OrderFilter{Sort: "customer_id; DROP TABLE orders"}, int64(0), int64(10))
+
+	assert.Equal(t, models.ErrBadParamInput, err)
 }
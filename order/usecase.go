@@ -8,7 +8,11 @@ import (
 
 // Usecase represent the order usecase
 type Usecase interface {
-	GetList(ctx context.Context, offset int64, limit int64) ([]*models.Order, int64, error)
+	GetList(ctx context.Context, filter *models.OrderFilter, offset int64, limit int64) ([]*models.Order, int64, error)
+	GetByCustomer(ctx context.Context, customerID int64, offset int64, limit int64) ([]*models.Order, int64, error)
+	GetByID(ctx context.Context, id int64) (*models.Order, error)
+	GetVisibleByID(ctx context.Context, id int64) (*models.Order, error)
 	Create(ctx context.Context, order *models.Order) error
+	Update(ctx context.Context, order *models.Order) error
 	Delete(ctx context.Context, id int64) error
 }
@@ -5,13 +5,39 @@ import (
 	"testing"
 	"time"
 
+	"github.com/soerjadi/exam/audit/audittest"
+	"github.com/soerjadi/exam/auth"
+	eventMocks "github.com/soerjadi/exam/event/mocks"
 	"github.com/soerjadi/exam/models"
 	"github.com/soerjadi/exam/order/mocks"
 	"github.com/soerjadi/exam/order/usecase"
+	streamMocks "github.com/soerjadi/exam/stream/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"gopkg.in/guregu/null.v3"
 )
 
+// inlineTx run the work straight away, the repositories are mocked
+type inlineTx struct{}
+
+func (inlineTx) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func newEventMock() *eventMocks.Usecase {
+	mockEvents := new(eventMocks.Usecase)
+	mockEvents.On("Emit", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("int64"), mock.Anything).Return(nil)
+
+	return mockEvents
+}
+
+func newLiveMock() *streamMocks.Publisher {
+	mockLive := new(streamMocks.Publisher)
+	mockLive.On("Publish", mock.AnythingOfType("string"), mock.Anything).Return()
+
+	return mockLive
+}
+
 func TestGetList(t *testing.T) {
 	mockOrderRepo := new(mocks.Repository)
 	mockOrder1 := models.Order{
@@ -34,12 +60,12 @@ func TestGetList(t *testing.T) {
 	mockOrders = append(mockOrders, &mockOrder2)
 
 	t.Run("success", func(t *testing.T) {
-		mockOrderRepo.On("GetList", mock.Anything, mock.AnythingOfType("int64"), mock.AnythingOfType("int64")).
+		mockOrderRepo.On("GetList", mock.Anything, mock.Anything, mock.AnythingOfType("int64"), mock.AnythingOfType("int64")).
 			Return(mockOrders, int64(2), nil).Once()
 
-		p := usecase.NewOrderUsecase(mockOrderRepo, time.Second*2)
+		p := usecase.NewOrderUsecase(mockOrderRepo, inlineTx{}, newEventMock(), newLiveMock(), audittest.NewUsecase(), time.Second*2)
 
-		orders, _, err := p.GetList(context.TODO(), int64(0), int64(10))
+		orders, _, err := p.GetList(context.TODO(), nil, int64(0), int64(10))
 
 		assert.NoError(t, err)
 		assert.Equal(t, mockOrders, orders)
@@ -48,11 +74,11 @@ func TestGetList(t *testing.T) {
 	})
 
 	t.Run("fail", func(t *testing.T) {
-		mockOrderRepo.On("GetList", mock.Anything, mock.AnythingOfType("int64"), mock.AnythingOfType("int64")).
+		mockOrderRepo.On("GetList", mock.Anything, mock.Anything, mock.AnythingOfType("int64"), mock.AnythingOfType("int64")).
 			Return(nil, int64(0), models.ErrInternalServerError).Once()
 
-		o := usecase.NewOrderUsecase(mockOrderRepo, time.Second*2)
-		orders, found, err := o.GetList(context.TODO(), int64(-1), int64(-2))
+		o := usecase.NewOrderUsecase(mockOrderRepo, inlineTx{}, newEventMock(), newLiveMock(), audittest.NewUsecase(), time.Second*2)
+		orders, found, err := o.GetList(context.TODO(), nil, int64(-1), int64(-2))
 
 		assert.Error(t, err)
 		assert.Equal(t, int64(0), found)
@@ -82,7 +108,13 @@ func TestCreate(t *testing.T) {
 		tmpMockOrder.ID = 0
 		mockOrderRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Order")).Return(nil).Once()
 
-		o := usecase.NewOrderUsecase(mockOrderRepo, time.Second*2)
+		mockLive := new(streamMocks.Publisher)
+		mockLive.On("Publish", models.EventOrderCreated, mock.MatchedBy(func(u *models.OrderUpdate) bool {
+			return !u.From.Valid && u.Order.ProductID == mockOrder1.ProductID
+		})).Return().Once()
+
+		mockAudit := audittest.NewUsecase()
+		o := usecase.NewOrderUsecase(mockOrderRepo, inlineTx{}, newEventMock(), mockLive, mockAudit, time.Second*2)
 
 		err := o.Create(context.TODO(), &mockOrder1)
 
@@ -90,17 +122,38 @@ func TestCreate(t *testing.T) {
 		assert.Equal(t, tmpMockOrder.ID, mockOrder1.ID)
 
 		mockOrderRepo.AssertExpectations(t)
+		mockLive.AssertExpectations(t)
+		audittest.AssertRecorded(t, mockAudit, models.AuditOrder, mockOrder1.ID, models.AuditCreate, nil, &mockOrder1)
+	})
+
+	t.Run("placed pending", func(t *testing.T) {
+		mockOrderRepo.On("Create", mock.Anything, mock.MatchedBy(func(o *models.Order) bool {
+			return o.Status == models.OrderPending
+		})).Return(nil).Once()
+
+		o := usecase.NewOrderUsecase(mockOrderRepo, inlineTx{}, newEventMock(), newLiveMock(), audittest.NewUsecase(), time.Second*2)
+
+		order := models.Order{ProductID: 1, Amount: 1, Price: 18000.0, Status: models.OrderProccessed}
+		err := o.Create(context.TODO(), &order)
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.OrderPending, order.Status)
+		mockOrderRepo.AssertExpectations(t)
 	})
 
 	t.Run("fail", func(t *testing.T) {
 		mockOrderRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Order")).Return(models.ErrNotFound).Once()
 
-		o := usecase.NewOrderUsecase(mockOrderRepo, time.Second*2)
+		mockLive := new(streamMocks.Publisher)
+		mockAudit := audittest.NewUsecase()
+		o := usecase.NewOrderUsecase(mockOrderRepo, inlineTx{}, newEventMock(), mockLive, mockAudit, time.Second*2)
 
 		err := o.Create(context.TODO(), &mockOrder2)
 
 		assert.Error(t, err)
 		assert.Equal(t, err, models.ErrNotFound)
+		mockLive.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+		audittest.AssertNothingRecorded(t, mockAudit)
 
 		mockOrderRepo.AssertExpectations(t)
 	})
@@ -117,13 +170,241 @@ func TestDelete(t *testing.T) {
 	}
 
 	t.Run("success", func(t *testing.T) {
+		mockOrderRepo.On("GetByID", mock.Anything, mockOrder2.ID).Return(&mockOrder2, nil).Once()
 		mockOrderRepo.On("Delete", mock.Anything, mock.AnythingOfType("int64")).Return(nil).Once()
 
-		p := usecase.NewOrderUsecase(mockOrderRepo, time.Second*2)
+		mockAudit := audittest.NewUsecase()
+		p := usecase.NewOrderUsecase(mockOrderRepo, inlineTx{}, newEventMock(), newLiveMock(), mockAudit, time.Second*2)
 
 		err := p.Delete(context.TODO(), mockOrder2.ID)
 
 		assert.NoError(t, err)
 		mockOrderRepo.AssertExpectations(t)
+		audittest.AssertRecorded(t, mockAudit, models.AuditOrder, mockOrder2.ID, models.AuditDelete, &mockOrder2, nil)
+	})
+}
+
+func TestUpdate(t *testing.T) {
+	mockOrderRepo := new(mocks.Repository)
+	current := models.Order{
+		ID:        int64(9),
+		ProductID: int64(3),
+		Amount:    int64(1),
+		Price:     10000.0,
+		Status:    models.OrderPending,
+		Version:   1,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockOrderRepo.On("GetByID", mock.Anything, current.ID).Return(&current, nil).Once()
+		mockOrderRepo.On("Update", mock.Anything, mock.MatchedBy(func(o *models.Order) bool {
+			return o.Status == models.OrderProccessed && o.ProductID == current.ProductID
+		})).Return(nil).Once()
+
+		mockEvents := new(eventMocks.Usecase)
+		mockEvents.On("Emit", mock.Anything, models.EventOrderStatusChanged, current.ID, &models.OrderStatusChange{
+			OrderID: current.ID,
+			From:    models.OrderPending,
+			To:      models.OrderProccessed,
+			Version: 1,
+		}).Return(nil).Once()
+
+		mockLive := new(streamMocks.Publisher)
+		mockLive.On("Publish", models.EventOrderStatusChanged, mock.MatchedBy(func(u *models.OrderUpdate) bool {
+			return u.From.Int64 == int64(models.OrderPending) && u.Order.Status == models.OrderProccessed
+		})).Return().Once()
+
+		mockAudit := audittest.NewUsecase()
+		o := usecase.NewOrderUsecase(mockOrderRepo, inlineTx{}, mockEvents, mockLive, mockAudit, time.Second*2)
+
+		order := models.Order{ID: current.ID, Status: models.OrderProccessed, Version: 1}
+		err := o.Update(context.TODO(), &order)
+
+		assert.NoError(t, err)
+		assert.Equal(t, current.Price, order.Price)
+		mockOrderRepo.AssertExpectations(t)
+		mockEvents.AssertExpectations(t)
+		mockLive.AssertExpectations(t)
+		audittest.AssertRecorded(t, mockAudit, models.AuditOrder, current.ID, models.AuditUpdate, &current, &order)
+	})
+
+	t.Run("same status emit nothing", func(t *testing.T) {
+		mockOrderRepo.On("GetByID", mock.Anything, current.ID).Return(&current, nil).Once()
+		mockOrderRepo.On("Update", mock.Anything, mock.AnythingOfType("*models.Order")).Return(nil).Once()
+
+		mockEvents := new(eventMocks.Usecase)
+		mockLive := new(streamMocks.Publisher)
+		o := usecase.NewOrderUsecase(mockOrderRepo, inlineTx{}, mockEvents, mockLive, audittest.NewUsecase(), time.Second*2)
+
+		order := models.Order{ID: current.ID, Status: models.OrderPending, Version: 1}
+		err := o.Update(context.TODO(), &order)
+
+		assert.NoError(t, err)
+		mockEvents.AssertNotCalled(t, "Emit", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockLive.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+		mockOrderRepo.AssertNotCalled(t, "ReleaseStock", mock.Anything, mock.Anything)
+	})
+
+	t.Run("cancelled give the stock back", func(t *testing.T) {
+		mockOrderRepo.On("GetByID", mock.Anything, current.ID).Return(&current, nil).Once()
+		mockOrderRepo.On("Update", mock.Anything, mock.AnythingOfType("*models.Order")).Return(nil).Once()
+		mockOrderRepo.On("ReleaseStock", mock.Anything, current.ID).Return(nil).Once()
+
+		mockEvents := new(eventMocks.Usecase)
+		mockEvents.On("Emit", mock.Anything, models.EventOrderStatusChanged, current.ID, mock.Anything).Return(nil).Once()
+		mockLive := new(streamMocks.Publisher)
+		mockLive.On("Publish", models.EventOrderStatusChanged, mock.Anything).Return().Once()
+		o := usecase.NewOrderUsecase(mockOrderRepo, inlineTx{}, mockEvents, mockLive, audittest.NewUsecase(), time.Second*2)
+
+		order := models.Order{ID: current.ID, Status: models.OrderCancelled, Version: 1}
+		err := o.Update(context.TODO(), &order)
+
+		assert.NoError(t, err)
+		mockOrderRepo.AssertExpectations(t)
+	})
+
+	t.Run("transition not allowed", func(t *testing.T) {
+		tests := []struct {
+			from int
+			to   int
+		}{
+			{models.OrderPending, models.OrderShipped},
+			{models.OrderPending, models.OrderReturned},
+			{models.OrderPartiallyShipped, models.OrderCancelled},
+			{models.OrderShipped, models.OrderCancelled},
+			{models.OrderShipped, models.OrderProccessed},
+			{models.OrderReturned, models.OrderCancelled},
+			{models.OrderCancelled, models.OrderPending},
+			{models.OrderCompleted, models.OrderShipped},
+		}
+
+		for _, test := range tests {
+			before := current
+			before.Status = test.from
+
+			mockOrderRepo := new(mocks.Repository)
+			mockOrderRepo.On("GetByID", mock.Anything, current.ID).Return(&before, nil).Once()
+
+			mockAudit := audittest.NewUsecase()
+			o := usecase.NewOrderUsecase(mockOrderRepo, inlineTx{}, newEventMock(), newLiveMock(), mockAudit, time.Second*2)
+
+			order := models.Order{ID: current.ID, Status: test.to, Version: 1}
+			err := o.Update(context.TODO(), &order)
+
+			assert.Equal(t, models.ErrBadParamInput, err, "%d to %d", test.from, test.to)
+			mockOrderRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+			mockOrderRepo.AssertNotCalled(t, "ReleaseStock", mock.Anything, mock.Anything)
+			audittest.AssertNothingRecorded(t, mockAudit)
+		}
+	})
+
+	t.Run("stale version", func(t *testing.T) {
+		mockOrderRepo.On("GetByID", mock.Anything, current.ID).Return(&current, nil).Once()
+
+		mockAudit := audittest.NewUsecase()
+		o := usecase.NewOrderUsecase(mockOrderRepo, inlineTx{}, newEventMock(), newLiveMock(), mockAudit, time.Second*2)
+
+		order := models.Order{ID: current.ID, Status: models.OrderProccessed, Version: 5}
+		err := o.Update(context.TODO(), &order)
+
+		assert.Equal(t, models.ErrConflict, err)
+		audittest.AssertNothingRecorded(t, mockAudit)
+		mockOrderRepo.AssertExpectations(t)
+	})
+}
+
+func TestGetByID(t *testing.T) {
+	mockOrderRepo := new(mocks.Repository)
+	mockOrder := models.Order{
+		ID:        int64(9),
+		ProductID: int64(3),
+		Amount:    int64(3),
+		Price:     10000.0,
+		Discount:  10000.0,
+		Total:     20000.0,
+		Status:    models.OrderPending,
+	}
+	promotions := []*models.OrderPromotion{
+		&models.OrderPromotion{ID: 1, OrderID: 9, PromotionID: 4, Name: "buy 2 get 1", Discount: 10000.0},
+	}
+
+	mockOrderRepo.On("GetByID", mock.Anything, mockOrder.ID).Return(&mockOrder, nil).Once()
+	mockOrderRepo.On("GetItems", mock.Anything, mockOrder.ID).Return([]*models.OrderItem{
+		&models.OrderItem{ID: 1, OrderID: 9, ProductID: 3, Amount: 3, Price: 10000.0},
+	}, nil).Once()
+	mockOrderRepo.On("GetPromotions", mock.Anything, mockOrder.ID).Return(promotions, nil).Once()
+	mockOrderRepo.On("GetCoupon", mock.Anything, mockOrder.ID).Return(nil, models.ErrNotFound).Once()
+	mockOrderRepo.On("GetShipping", mock.Anything, mockOrder.ID).
+		Return(&models.OrderShipping{ID: 2, OrderID: 9, MethodID: 1, Method: "regular", Cost: 9000.0, Country: "ID"}, nil).Once()
+
+	o := usecase.NewOrderUsecase(mockOrderRepo, inlineTx{}, newEventMock(), newLiveMock(), audittest.NewUsecase(), time.Second*2)
+
+	order, err := o.GetByID(context.TODO(), mockOrder.ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, promotions, order.Promotions)
+	assert.Len(t, order.Items, 1)
+	assert.Nil(t, order.Coupon)
+	assert.Equal(t, 9000.0, order.Shipping.Cost)
+	mockOrderRepo.AssertExpectations(t)
+}
+
+func TestGetListSwappedRange(t *testing.T) {
+	mockOrderRepo := new(mocks.Repository)
+	o := usecase.NewOrderUsecase(mockOrderRepo, inlineTx{}, newEventMock(), newLiveMock(), audittest.NewUsecase(), time.Second*2)
+
+	filter := &models.OrderFilter{MinTotal: null.FloatFrom(50000.0), MaxTotal: null.FloatFrom(10000.0)}
+	_, _, err := o.GetList(context.TODO(), filter, int64(0), int64(10))
+
+	assert.Equal(t, models.ErrBadParamInput, err)
+	mockOrderRepo.AssertNotCalled(t, "GetList", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetVisibleByID(t *testing.T) {
+	newRepo := func() *mocks.Repository {
+		mockOrderRepo := new(mocks.Repository)
+		mockOrderRepo.On("GetByID", mock.Anything, int64(9)).
+			Return(&models.Order{ID: 9, CustomerID: null.IntFrom(4), Status: models.OrderProccessed}, nil).Once()
+		mockOrderRepo.On("GetItems", mock.Anything, int64(9)).Return([]*models.OrderItem{}, nil).Once()
+		mockOrderRepo.On("GetPromotions", mock.Anything, int64(9)).Return([]*models.OrderPromotion{}, nil).Once()
+		mockOrderRepo.On("GetCoupon", mock.Anything, int64(9)).Return(nil, models.ErrNotFound).Once()
+		mockOrderRepo.On("GetShipping", mock.Anything, int64(9)).Return(nil, models.ErrNotFound).Once()
+		return mockOrderRepo
+	}
+
+	t.Run("own order", func(t *testing.T) {
+		o := usecase.NewOrderUsecase(newRepo(), inlineTx{}, newEventMock(), newLiveMock(), audittest.NewUsecase(), time.Second*2)
+		ctx := auth.WithClaims(context.TODO(), &auth.Claims{Subject: 4})
+
+		order, err := o.GetVisibleByID(ctx, int64(9))
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(9), order.ID)
+	})
+
+	t.Run("order of another customer", func(t *testing.T) {
+		o := usecase.NewOrderUsecase(newRepo(), inlineTx{}, newEventMock(), newLiveMock(), audittest.NewUsecase(), time.Second*2)
+		ctx := auth.WithClaims(context.TODO(), &auth.Claims{Subject: 5})
+
+		_, err := o.GetVisibleByID(ctx, int64(9))
+
+		assert.Equal(t, models.ErrNotFound, err)
+	})
+
+	t.Run("api key allowed to read orders", func(t *testing.T) {
+		o := usecase.NewOrderUsecase(newRepo(), inlineTx{}, newEventMock(), newLiveMock(), audittest.NewUsecase(), time.Second*2)
+		ctx := auth.WithPrincipal(context.TODO(), &auth.KeyPrincipal{ID: 1, Permissions: []auth.Permission{auth.OrderRead}})
+
+		_, err := o.GetVisibleByID(ctx, int64(9))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("anonymous", func(t *testing.T) {
+		o := usecase.NewOrderUsecase(newRepo(), inlineTx{}, newEventMock(), newLiveMock(), audittest.NewUsecase(), time.Second*2)
+
+		_, err := o.GetVisibleByID(context.TODO(), int64(9))
+
+		assert.Equal(t, models.ErrUnauthorized, err)
 	})
 }
@@ -4,28 +4,77 @@ import (
 	"context"
 	"time"
 
+	"github.com/soerjadi/exam/audit"
+	"github.com/soerjadi/exam/auth"
+	"github.com/soerjadi/exam/database"
+	"github.com/soerjadi/exam/event"
 	"github.com/soerjadi/exam/models"
 	"github.com/soerjadi/exam/order"
+	"github.com/soerjadi/exam/stream"
+	"github.com/soerjadi/exam/tax"
+	"github.com/soerjadi/exam/utils"
+	"gopkg.in/guregu/null.v3"
 )
 
 type orderUsecase struct {
 	repo    order.Repository
+	tx      database.Transactor
+	events  event.Usecase
+	live    stream.Publisher
+	audit   audit.Usecase
 	timeout time.Duration
 }
 
-// NewOrderUsecase will create object that represent of order.Usecase interface
-func NewOrderUsecase(o order.Repository, timeout time.Duration) order.Usecase {
+var logger = utils.LogBuilder(true)
+
+// NewOrderUsecase will create object that represent of order.Usecase interface.
+// Orders created and status changes are published to live once committed.
+func NewOrderUsecase(o order.Repository, tx database.Transactor, e event.Usecase, live stream.Publisher, a audit.Usecase, timeout time.Duration) order.Usecase {
 	return &orderUsecase{
 		repo:    o,
+		tx:      tx,
+		events:  e,
+		live:    live,
+		audit:   a,
 		timeout: timeout,
 	}
 }
 
-func (o *orderUsecase) GetList(ctx context.Context, offset int64, limit int64) ([]*models.Order, int64, error) {
+func (o *orderUsecase) record(ctx context.Context, id int64, action string, before interface{}, after interface{}) {
+	if err := o.audit.Record(ctx, models.AuditOrder, id, action, before, after); err != nil {
+		logger.Error(err)
+	}
+}
+
+// GetList list the orders matching filter, a date or total range whose
+// bounds are swapped is refused
+func (o *orderUsecase) GetList(ctx context.Context, filter *models.OrderFilter, offset int64, limit int64) ([]*models.Order, int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, o.timeout)
+	defer cancel()
+
+	if filter != nil {
+		if filter.From.Valid && filter.To.Valid && !filter.From.Time.Before(filter.To.Time) {
+			return nil, 0, models.ErrBadParamInput
+		}
+
+		if filter.MinTotal.Valid && filter.MaxTotal.Valid && filter.MinTotal.Float64 > filter.MaxTotal.Float64 {
+			return nil, 0, models.ErrBadParamInput
+		}
+	}
+
+	orders, found, err := o.repo.GetList(ctx, filter, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return orders, found, nil
+}
+
+func (o *orderUsecase) GetByCustomer(ctx context.Context, customerID int64, offset int64, limit int64) ([]*models.Order, int64, error) {
 	ctx, cancel := context.WithTimeout(ctx, o.timeout)
 	defer cancel()
 
-	orders, found, err := o.repo.GetList(ctx, offset, limit)
+	orders, found, err := o.repo.GetByCustomer(ctx, customerID, offset, limit)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -33,15 +82,179 @@ func (o *orderUsecase) GetList(ctx context.Context, offset int64, limit int64) (
 	return orders, found, nil
 }
 
+func (o *orderUsecase) GetByID(ctx context.Context, id int64) (*models.Order, error) {
+	ctx, cancel := context.WithTimeout(ctx, o.timeout)
+	defer cancel()
+
+	order, err := o.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	order.Items, err = o.repo.GetItems(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	order.Taxes = tax.Breakdown(order.Items)
+
+	order.Promotions, err = o.repo.GetPromotions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	order.Coupon, err = o.repo.GetCoupon(ctx, id)
+	if err != nil && err != models.ErrNotFound {
+		return nil, err
+	}
+
+	order.Shipping, err = o.repo.GetShipping(ctx, id)
+	if err != nil && err != models.ErrNotFound {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// GetVisibleByID return the order of id when the caller may see it: orders
+// of a customer are only visible to that customer, every order is visible to
+// a principal allowed to read orders
+func (o *orderUsecase) GetVisibleByID(ctx context.Context, id int64) (*models.Order, error) {
+	order, err := o.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if principal, ok := auth.PrincipalFrom(ctx); ok && principal.Allowed(auth.OrderRead) {
+		return order, nil
+	}
+
+	customerID, ok := auth.CustomerID(ctx)
+	if !ok {
+		return nil, models.ErrUnauthorized
+	}
+
+	if !order.CustomerID.Valid || order.CustomerID.Int64 != customerID {
+		return nil, models.ErrNotFound
+	}
+
+	return order, nil
+}
+
 func (o *orderUsecase) Create(ctx context.Context, order *models.Order) error {
 	ctx, cancel := context.WithTimeout(ctx, o.timeout)
 	defer cancel()
 
-	err := o.repo.Create(ctx, order)
+	// orders are placed unpaid, only their payment move them on
+	order.Status = models.OrderPending
+
+	err := o.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := o.repo.Create(ctx, order); err != nil {
+			return err
+		}
+
+		return o.events.Emit(ctx, models.EventOrderCreated, order.ID, order)
+	})
 	if err != nil {
 		return err
 	}
 
+	// subscribers read it after we return, it must not change under them
+	created := *order
+	o.live.Publish(models.EventOrderCreated, &models.OrderUpdate{Order: &created})
+
+	o.record(ctx, order.ID, models.AuditCreate, nil, order)
+	return nil
+}
+
+// transitions list the statuses an order can move to from each status.
+// Orders only go forward: cancelled and returned ones are closed, and once a
+// piece was shipped an order can no longer be cancelled, its stock is gone.
+var transitions = map[int]map[int]bool{
+	models.OrderPending: {
+		models.OrderProccessed: true,
+		models.OrderCancelled:  true,
+	},
+	models.OrderProccessed: {
+		models.OrderPartiallyShipped: true,
+		models.OrderShipped:          true,
+		models.OrderCompleted:        true,
+		models.OrderCancelled:        true,
+		models.OrderReturned:         true,
+	},
+	models.OrderPartiallyShipped: {
+		models.OrderShipped:   true,
+		models.OrderCompleted: true,
+		models.OrderReturned:  true,
+	},
+	models.OrderShipped: {
+		models.OrderCompleted: true,
+		models.OrderReturned:  true,
+	},
+	models.OrderCompleted: {
+		models.OrderReturned: true,
+	},
+}
+
+func (o *orderUsecase) Update(ctx context.Context, order *models.Order) error {
+	ctx, cancel := context.WithTimeout(ctx, o.timeout)
+	defer cancel()
+
+	before, err := o.repo.GetByID(ctx, order.ID)
+	if err != nil {
+		return err
+	}
+
+	if order.Version == 0 {
+		order.Version = before.Version
+	}
+
+	if order.Version != before.Version {
+		return models.ErrConflict
+	}
+
+	if order.Status != before.Status && !transitions[before.Status][order.Status] {
+		return models.ErrBadParamInput
+	}
+
+	// only the status of a placed order can be changed
+	after := *before
+	after.Status = order.Status
+
+	err = o.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := o.repo.Update(ctx, &after); err != nil {
+			return err
+		}
+
+		if after.Status == before.Status {
+			return nil
+		}
+
+		if after.Status == models.OrderCancelled {
+			if err := o.repo.ReleaseStock(ctx, after.ID); err != nil {
+				return err
+			}
+		}
+
+		return o.events.Emit(ctx, models.EventOrderStatusChanged, after.ID, &models.OrderStatusChange{
+			OrderID: after.ID,
+			From:    before.Status,
+			To:      after.Status,
+			Version: after.Version,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	if after.Status != before.Status {
+		o.live.Publish(models.EventOrderStatusChanged, &models.OrderUpdate{
+			From:  null.IntFrom(int64(before.Status)),
+			Order: &after,
+		})
+	}
+
+	*order = after
+	o.record(ctx, order.ID, models.AuditUpdate, before, order)
 	return nil
 }
 
@@ -49,5 +262,16 @@ func (o *orderUsecase) Delete(ctx context.Context, id int64) error {
 	ctx, cancel := context.WithTimeout(ctx, o.timeout)
 	defer cancel()
 
-	return o.repo.Delete(ctx, id)
+	exists, err := o.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = o.repo.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	o.record(ctx, id, models.AuditDelete, exists, nil)
+	return nil
 }
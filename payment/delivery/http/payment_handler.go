@@ -0,0 +1,225 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/payment"
+	"github.com/soerjadi/exam/utils"
+)
+
+// SignatureHeader carry the signature of the callbacks of the provider
+var SignatureHeader = "X-Payment-Signature"
+
+type authorizeData struct {
+	Source string `json:"source"`
+}
+
+type amountData struct {
+	Amount float64 `json:"amount"`
+}
+
+// PaymentHandler represent the http handler for payments
+type PaymentHandler struct {
+	PaymentUsecase payment.Usecase
+}
+
+// NewPaymentHandler initialize payment resource endpoint
+func NewPaymentHandler(router *mux.Router, usecase payment.Usecase) *mux.Router {
+	handler := &PaymentHandler{
+		PaymentUsecase: usecase,
+	}
+
+	router.HandleFunc("/v1/order/{id:[0-9]+}/payment", handler.Authorize).Methods("POST")
+	router.HandleFunc("/v1/order/{id:[0-9]+}/payment", handler.GetByOrder).Methods("GET")
+	router.HandleFunc("/v1/payment/callback", handler.Callback).Methods("POST")
+
+	p := router.PathPrefix("/v1/admin/payment").Subrouter()
+	p.HandleFunc("/{id:[0-9]+}/capture", handler.Capture).Methods("POST")
+	p.HandleFunc("/{id:[0-9]+}/refund", handler.Refund).Methods("POST")
+	p.HandleFunc("/{id:[0-9]+}/void", handler.Void).Methods("POST")
+
+	return p
+}
+
+func decode(r *http.Request, v interface{}) error {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, v)
+}
+
+func pathID(r *http.Request) (int64, error) {
+	return strconv.ParseInt(mux.Vars(r)["id"], 0, 64)
+}
+
+// errorStatus map errors of the payment usecase to http status
+func errorStatus(err error) int32 {
+	switch err {
+	case models.ErrUnauthorized:
+		return http.StatusUnauthorized
+	case models.ErrConflict:
+		return http.StatusConflict
+	case models.ErrPaymentDeclined:
+		return http.StatusPaymentRequired
+	case models.ErrPaymentProvider:
+		return http.StatusBadGateway
+	}
+
+	return http.StatusBadRequest
+}
+
+// Authorize endpoint to pay an order with the payment source sent in body
+func (h *PaymentHandler) Authorize(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := pathID(r)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var data authorizeData
+	if err := decode(r, &data); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	pay, err := h.PaymentUsecase.Authorize(ctx, id, data.Source)
+
+	if err != nil {
+		utils.Error(w, errorStatus(err), err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, pay)
+}
+
+// GetByOrder endpoint to list the payments of an order
+func (h *PaymentHandler) GetByOrder(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	payments, err := h.PaymentUsecase.GetByOrder(ctx, id)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entriesResult := &utils.EntriesResponse{
+		Data:  payments,
+		Found: int64(len(payments)),
+	}
+
+	utils.JSON(w, http.StatusOK, entriesResult)
+}
+
+// Capture endpoint to charge an authorized payment, the whole authorization
+// when no amount is sent
+func (h *PaymentHandler) Capture(w http.ResponseWriter, r *http.Request) {
+	h.change(w, r, h.PaymentUsecase.Capture)
+}
+
+// Refund endpoint to give back a captured payment, what is left of it when
+// no amount is sent
+func (h *PaymentHandler) Refund(w http.ResponseWriter, r *http.Request) {
+	h.change(w, r, h.PaymentUsecase.Refund)
+}
+
+// change decode the amount sent to a capture or a refund and run it
+func (h *PaymentHandler) change(w http.ResponseWriter, r *http.Request, fn func(context.Context, int64, float64) (*models.Payment, error)) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := pathID(r)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var data amountData
+	if err := decode(r, &data); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	pay, err := fn(ctx, id, data.Amount)
+
+	if err != nil {
+		utils.Error(w, errorStatus(err), err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, pay)
+}
+
+// Void endpoint to release a payment not captured yet and cancel its order
+func (h *PaymentHandler) Void(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	pay, err := h.PaymentUsecase.Void(ctx, id)
+
+	if err != nil {
+		utils.Error(w, errorStatus(err), err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, pay)
+}
+
+// Callback endpoint the payment provider report changes of payments to
+func (h *PaymentHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	payload, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	err = h.PaymentUsecase.Callback(ctx, payload, r.Header.Get(SignatureHeader))
+
+	if err != nil {
+		utils.Error(w, errorStatus(err), err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, "success")
+}
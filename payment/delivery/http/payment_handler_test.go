@@ -0,0 +1,76 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/soerjadi/exam/models"
+	paymentHttp "github.com/soerjadi/exam/payment/delivery/http"
+	"github.com/soerjadi/exam/payment/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAuthorizeDeclined(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("Authorize", mock.Anything, int64(8), "tok_decline").
+		Return(&models.Payment{ID: 1, OrderID: 8, Status: models.PaymentFailed}, models.ErrPaymentDeclined)
+
+	req, err := http.NewRequest("POST", "/v1/order/8/payment", strings.NewReader(`{"source":"tok_decline"}`))
+	assert.NoError(t, err)
+	req = mux.SetURLVars(req, map[string]string{"id": "8"})
+
+	handler := paymentHttp.PaymentHandler{
+		PaymentUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.Authorize(rec, req)
+
+	assert.Equal(t, http.StatusPaymentRequired, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestCapture(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("Capture", mock.Anything, int64(3), 50000.0).
+		Return(&models.Payment{ID: 3, OrderID: 8, Status: models.PaymentCaptured, Captured: 50000.0}, nil)
+
+	req, err := http.NewRequest("POST", "/v1/admin/payment/3/capture", strings.NewReader(`{"amount":50000}`))
+	assert.NoError(t, err)
+	req = mux.SetURLVars(req, map[string]string{"id": "3"})
+
+	handler := paymentHttp.PaymentHandler{
+		PaymentUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.Capture(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestCallbackForged(t *testing.T) {
+	payload := `{"reference":"fake_abc","type":"captured","amount":90000}`
+
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("Callback", mock.Anything, []byte(payload), "deadbeef").Return(models.ErrUnauthorized)
+
+	req, err := http.NewRequest("POST", "/v1/payment/callback", strings.NewReader(payload))
+	assert.NoError(t, err)
+	req.Header.Set(paymentHttp.SignatureHeader, "deadbeef")
+
+	handler := paymentHttp.PaymentHandler{
+		PaymentUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.Callback(rec, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
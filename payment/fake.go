@@ -0,0 +1,109 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/utils"
+)
+
+// FakeApprove fake provider mode authorizing every payment straight away
+var FakeApprove = "approve"
+
+// FakePending fake provider mode leaving authorizations pending until a
+// callback report them
+var FakePending = "pending"
+
+// FakeDecline fake provider mode declining every payment
+var FakeDecline = "decline"
+
+// FakeError fake provider mode failing every call as if it was down
+var FakeError = "error"
+
+// FakeDeclineSource is a source the fake provider always decline, whatever
+// its mode
+var FakeDeclineSource = "tok_decline"
+
+// FakeProvider is a payment provider keeping nothing and charging no one,
+// for tests and local development. Callbacks are signed with an HMAC-SHA256
+// of the payload keyed by Secret, hex encoded.
+type FakeProvider struct {
+	Mode   string
+	Secret string
+}
+
+// NewFakeProvider return a fake provider behaving as mode, unknown modes approve
+func NewFakeProvider(mode string, secret string) *FakeProvider {
+	return &FakeProvider{Mode: mode, Secret: secret}
+}
+
+// Name of the fake provider stored with its payments
+func (f *FakeProvider) Name() string {
+	return "fake"
+}
+
+// Authorize hold amount on source, depending on the mode
+func (f *FakeProvider) Authorize(ctx context.Context, amount float64, source string) (*Authorization, error) {
+	switch {
+	case f.Mode == FakeError:
+		return nil, models.ErrPaymentProvider
+	case f.Mode == FakeDecline || source == FakeDeclineSource:
+		return nil, models.ErrPaymentDeclined
+	}
+
+	status := models.PaymentAuthorized
+	if f.Mode == FakePending {
+		status = models.PaymentPending
+	}
+
+	return &Authorization{Reference: "fake_" + utils.RandString(12), Status: status}, nil
+}
+
+// Capture always succeed unless the provider is down
+func (f *FakeProvider) Capture(ctx context.Context, reference string, amount float64) error {
+	return f.call()
+}
+
+// Refund always succeed unless the provider is down
+func (f *FakeProvider) Refund(ctx context.Context, reference string, amount float64) error {
+	return f.call()
+}
+
+// Void always succeed unless the provider is down
+func (f *FakeProvider) Void(ctx context.Context, reference string) error {
+	return f.call()
+}
+
+func (f *FakeProvider) call() error {
+	if f.Mode == FakeError {
+		return models.ErrPaymentProvider
+	}
+
+	return nil
+}
+
+// Sign return the signature the fake provider send along payload
+func (f *FakeProvider) Sign(payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(f.Secret))
+	mac.Write(payload)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ParseEvent decode a callback signed with Sign
+func (f *FakeProvider) ParseEvent(payload []byte, signature string) (*models.PaymentEvent, error) {
+	if !hmac.Equal([]byte(f.Sign(payload)), []byte(signature)) {
+		return nil, models.ErrUnauthorized
+	}
+
+	event := new(models.PaymentEvent)
+	if err := json.Unmarshal(payload, event); err != nil {
+		return nil, models.ErrBadParamInput
+	}
+
+	return event, nil
+}
@@ -0,0 +1,91 @@
+package payment_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/payment"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeAuthorize(t *testing.T) {
+	tests := []struct {
+		mode   string
+		source string
+		status string
+		err    error
+	}{
+		{payment.FakeApprove, "tok_visa", models.PaymentAuthorized, nil},
+		{payment.FakePending, "tok_visa", models.PaymentPending, nil},
+		{payment.FakeDecline, "tok_visa", "", models.ErrPaymentDeclined},
+		{payment.FakeApprove, payment.FakeDeclineSource, "", models.ErrPaymentDeclined},
+		{payment.FakeError, "tok_visa", "", models.ErrPaymentProvider},
+		{"unknown", "tok_visa", models.PaymentAuthorized, nil},
+	}
+
+	for _, test := range tests {
+		provider := payment.NewFakeProvider(test.mode, "s3cret")
+		authorization, err := provider.Authorize(context.TODO(), 90000.0, test.source)
+
+		assert.Equal(t, test.err, err, test.mode)
+		if test.err != nil {
+			assert.Nil(t, authorization, test.mode)
+			continue
+		}
+
+		assert.Equal(t, test.status, authorization.Status, test.mode)
+		assert.Contains(t, authorization.Reference, "fake_", test.mode)
+	}
+}
+
+func TestFakeCalls(t *testing.T) {
+	up := payment.NewFakeProvider(payment.FakeDecline, "s3cret")
+	assert.NoError(t, up.Capture(context.TODO(), "fake_abc", 90000.0))
+	assert.NoError(t, up.Refund(context.TODO(), "fake_abc", 90000.0))
+	assert.NoError(t, up.Void(context.TODO(), "fake_abc"))
+
+	down := payment.NewFakeProvider(payment.FakeError, "s3cret")
+	assert.Equal(t, models.ErrPaymentProvider, down.Capture(context.TODO(), "fake_abc", 90000.0))
+	assert.Equal(t, models.ErrPaymentProvider, down.Refund(context.TODO(), "fake_abc", 90000.0))
+	assert.Equal(t, models.ErrPaymentProvider, down.Void(context.TODO(), "fake_abc"))
+}
+
+func TestFakeParseEvent(t *testing.T) {
+	provider := payment.NewFakeProvider(payment.FakeApprove, "s3cret")
+	payload := []byte(`{"reference":"fake_abc","type":"captured","amount":90000}`)
+
+	t.Run("signed", func(t *testing.T) {
+		event, err := provider.ParseEvent(payload, provider.Sign(payload))
+
+		assert.NoError(t, err)
+		assert.Equal(t, &models.PaymentEvent{Reference: "fake_abc", Type: models.PaymentCaptured, Amount: 90000.0}, event)
+	})
+
+	t.Run("forged", func(t *testing.T) {
+		forger := payment.NewFakeProvider(payment.FakeApprove, "guess")
+		tests := []struct {
+			name      string
+			payload   []byte
+			signature string
+		}{
+			{"other secret", payload, forger.Sign(payload)},
+			{"other payload", []byte(`{"reference":"fake_abc","type":"refunded","amount":90000}`), provider.Sign(payload)},
+			{"unsigned", payload, ""},
+		}
+
+		for _, test := range tests {
+			event, err := provider.ParseEvent(test.payload, test.signature)
+
+			assert.Equal(t, models.ErrUnauthorized, err, test.name)
+			assert.Nil(t, event, test.name)
+		}
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		malformed := []byte(`{"reference":`)
+		_, err := provider.ParseEvent(malformed, provider.Sign(malformed))
+
+		assert.Equal(t, models.ErrBadParamInput, err)
+	})
+}
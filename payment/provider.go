@@ -0,0 +1,28 @@
+package payment
+
+import (
+	"context"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// Authorization is the answer of a provider to an authorization, Status is
+// pending when the provider report the outcome later through a callback
+type Authorization struct {
+	Reference string
+	Status    string
+}
+
+// Provider is a payment gateway. Amounts are in the currency of the shop,
+// reference is the id the provider gave the payment on authorization.
+// Declined payments return models.ErrPaymentDeclined.
+type Provider interface {
+	Name() string
+	Authorize(ctx context.Context, amount float64, source string) (*Authorization, error)
+	Capture(ctx context.Context, reference string, amount float64) error
+	Refund(ctx context.Context, reference string, amount float64) error
+	Void(ctx context.Context, reference string) error
+	// ParseEvent verify the signature of a callback of the provider and
+	// decode it, forged callbacks return models.ErrUnauthorized
+	ParseEvent(payload []byte, signature string) (*models.PaymentEvent, error)
+}
@@ -0,0 +1,16 @@
+package payment
+
+import (
+	"context"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// Repository represent the payment repository contract
+type Repository interface {
+	GetByID(ctx context.Context, id int64) (*models.Payment, error)
+	GetByOrder(ctx context.Context, orderID int64) ([]*models.Payment, error)
+	GetByReference(ctx context.Context, provider string, reference string) (*models.Payment, error)
+	Create(ctx context.Context, payment *models.Payment) error
+	Update(ctx context.Context, payment *models.Payment) error
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/payment"
+	"github.com/soerjadi/exam/utils"
+)
+
+type pgPaymentRepository struct {
+	Conn *sql.DB
+}
+
+var logger = utils.LogBuilder(true)
+
+// uniqueViolation is the postgres error code of a duplicate key
+const uniqueViolation = "23505"
+
+// NewPGPaymentRepository is bridge to create an object from payment.Repository interface
+func NewPGPaymentRepository(Conn *sql.DB) payment.Repository {
+	return &pgPaymentRepository{Conn}
+}
+
+func (p *pgPaymentRepository) fetch(ctx context.Context, query string, args ...interface{}) ([]*models.Payment, error) {
+	rows, err := p.Conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Error(err)
+		}
+	}()
+
+	result := make([]*models.Payment, 0)
+	for rows.Next() {
+		t := new(models.Payment)
+
+		err = rows.Scan(
+			&t.ID,
+			&t.OrderID,
+			&t.Provider,
+			&t.Reference,
+			&t.Status,
+			&t.Amount,
+			&t.Captured,
+			&t.Refunded,
+			&t.Created,
+			&t.Updated,
+			&t.Version,
+		)
+
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+
+		result = append(result, t)
+	}
+
+	return result, nil
+}
+
+func (p *pgPaymentRepository) one(ctx context.Context, query string, args ...interface{}) (*models.Payment, error) {
+	payments, err := p.fetch(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(payments) == 0 {
+		return nil, models.ErrNotFound
+	}
+
+	return payments[0], nil
+}
+
+func (p *pgPaymentRepository) GetByID(ctx context.Context, id int64) (*models.Payment, error) {
+	query := `SELECT id, order_id, provider, reference, status, amount, captured, refunded, created, updated, version
+		FROM payments WHERE id = ?`
+
+	return p.one(ctx, query, id)
+}
+
+func (p *pgPaymentRepository) GetByOrder(ctx context.Context, orderID int64) ([]*models.Payment, error) {
+	query := `SELECT id, order_id, provider, reference, status, amount, captured, refunded, created, updated, version
+		FROM payments WHERE order_id = ? ORDER BY id`
+
+	return p.fetch(ctx, query, orderID)
+}
+
+func (p *pgPaymentRepository) GetByReference(ctx context.Context, provider string, reference string) (*models.Payment, error) {
+	query := `SELECT id, order_id, provider, reference, status, amount, captured, refunded, created, updated, version
+		FROM payments WHERE provider = ? AND reference = ?`
+
+	return p.one(ctx, query, provider, reference)
+}
+
+// Create store a new payment, models.ErrConflict is returned when the order
+// already has one which is neither failed nor voided
+func (p *pgPaymentRepository) Create(ctx context.Context, payment *models.Payment) error {
+	query := `INSERT INTO payments(order_id, provider, reference, status, amount, captured, refunded)
+		VALUES(?, ?, ?, ?, ?, ?, ?) returning id`
+
+	stmt, err := p.Conn.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	result, err := stmt.ExecContext(ctx, payment.OrderID, payment.Provider, payment.Reference, payment.Status,
+		payment.Amount, payment.Captured, payment.Refunded)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolation {
+		// the order already has a payment which is neither failed nor voided
+		return models.ErrConflict
+	}
+
+	if err != nil {
+		return err
+	}
+
+	lastID, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	payment.ID = lastID
+	payment.Version = 1
+	return nil
+}
+
+// Update store the state of the payment if it did not change since it was
+// read, models.ErrConflict is returned otherwise
+func (p *pgPaymentRepository) Update(ctx context.Context, payment *models.Payment) error {
+	query := `UPDATE payments SET reference = ?, status = ?, amount = ?, captured = ?, refunded = ?, updated = ?,
+		version = version + 1 WHERE id = ? AND version = ?`
+
+	stmt, err := p.Conn.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	res, err := stmt.ExecContext(ctx, payment.Reference, payment.Status, payment.Amount, payment.Captured, payment.Refunded,
+		payment.Updated, payment.ID, payment.Version)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected != 1 {
+		return models.ErrConflict
+	}
+
+	payment.Version++
+	return nil
+}
@@ -0,0 +1,122 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/payment/repository"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v3"
+)
+
+var columns = []string{"id", "order_id", "provider", "reference", "status", "amount", "captured", "refunded", "created", "updated", "version"}
+
+func TestGetByOrder(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows(columns).
+		AddRow(1, 8, "fake", "", models.PaymentFailed, 90000.0, 0, 0, time.Now(), nil, 1).
+		AddRow(2, 8, "fake", "fake_abc", models.PaymentCaptured, 90000.0, 90000.0, 0, time.Now(), time.Now(), 2)
+
+	mock.ExpectQuery("SELECT (.+) FROM payments WHERE order_id = \\? ORDER BY id").WithArgs(int64(8)).WillReturnRows(rows)
+
+	r := repository.NewPGPaymentRepository(db)
+	payments, err := r.GetByOrder(context.TODO(), int64(8))
+
+	assert.NoError(t, err)
+	assert.Len(t, payments, 2)
+	assert.Equal(t, models.PaymentCaptured, payments[1].Status)
+}
+
+func TestGetByReference(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectQuery("SELECT (.+) FROM payments WHERE provider = \\? AND reference = \\?").WithArgs("fake", "fake_xyz").
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	r := repository.NewPGPaymentRepository(db)
+	_, err = r.GetByReference(context.TODO(), "fake", "fake_xyz")
+
+	assert.Equal(t, models.ErrNotFound, err)
+}
+
+func TestCreate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	payment := &models.Payment{OrderID: 8, Provider: "fake", Reference: "fake_abc", Status: models.PaymentAuthorized, Amount: 90000.0}
+
+	mock.ExpectPrepare("INSERT INTO payments\\(order_id, provider, reference, status, amount, captured, refunded\\)").ExpectExec().
+		WithArgs(int64(8), "fake", "fake_abc", models.PaymentAuthorized, 90000.0, 0.0, 0.0).
+		WillReturnResult(sqlmock.NewResult(3, 1))
+
+	r := repository.NewPGPaymentRepository(db)
+	err = r.Create(context.TODO(), payment)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), payment.ID)
+	assert.Equal(t, int64(1), payment.Version)
+}
+
+func TestCreateOrderAlreadyClaimed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	payment := &models.Payment{OrderID: 8, Provider: "fake", Status: models.PaymentPending, Amount: 90000.0}
+
+	// payments_open_order_idx refuse a second open payment of the order
+	mock.ExpectPrepare("INSERT INTO payments\\(order_id, provider, reference, status, amount, captured, refunded\\)").ExpectExec().
+		WillReturnError(&pq.Error{Code: "23505"})
+
+	r := repository.NewPGPaymentRepository(db)
+	err = r.Create(context.TODO(), payment)
+
+	assert.Equal(t, models.ErrConflict, err)
+	assert.Zero(t, payment.ID)
+}
+
+func TestUpdate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	query := "UPDATE payments SET reference = \\?, status = \\?, amount = \\?, captured = \\?, refunded = \\?, updated = \\?, version = version \\+ 1 WHERE id = \\? AND version = \\?"
+	payment := &models.Payment{ID: 3, Reference: "fake_abc", Status: models.PaymentCaptured, Amount: 90000.0, Captured: 90000.0,
+		Updated: null.TimeFrom(time.Now()), Version: 1}
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectPrepare(query).ExpectExec().
+			WithArgs("fake_abc", models.PaymentCaptured, 90000.0, 90000.0, 0.0, payment.Updated, int64(3), int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		r := repository.NewPGPaymentRepository(db)
+		err := r.Update(context.TODO(), payment)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), payment.Version)
+	})
+
+	t.Run("changed meanwhile", func(t *testing.T) {
+		mock.ExpectPrepare(query).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 0))
+
+		r := repository.NewPGPaymentRepository(db)
+		err := r.Update(context.TODO(), payment)
+
+		assert.Equal(t, models.ErrConflict, err)
+	})
+}
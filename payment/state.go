@@ -0,0 +1,61 @@
+package payment
+
+import (
+	"math"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// round to the cent so refunds add up to what was captured
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// Apply move payment to the state reported by event and tell whether it
+// changed. Events moving a payment backward or repeating a change already
+// applied are ignored, providers replay their callbacks and send them out
+// of order.
+func Apply(payment *models.Payment, event *models.PaymentEvent) bool {
+	open := payment.Status == models.PaymentPending || payment.Status == models.PaymentAuthorized
+
+	switch event.Type {
+	case models.PaymentAuthorized:
+		if payment.Status != models.PaymentPending {
+			return false
+		}
+
+		if event.Amount > 0 {
+			payment.Amount = round(event.Amount)
+		}
+	case models.PaymentCaptured:
+		if !open {
+			return false
+		}
+
+		payment.Captured = round(event.Amount)
+	case models.PaymentRefunded:
+		charged := payment.Status == models.PaymentCaptured || payment.Status == models.PaymentRefunded
+		if !charged || round(event.Amount) <= payment.Refunded {
+			return false
+		}
+
+		payment.Refunded = math.Min(round(event.Amount), payment.Captured)
+		if payment.Refunded < payment.Captured {
+			// partly refunded payments stay captured
+			return true
+		}
+	case models.PaymentVoided:
+		if !open {
+			return false
+		}
+	case models.PaymentFailed:
+		if payment.Status != models.PaymentPending {
+			return false
+		}
+	default:
+		return false
+	}
+
+	payment.Status = event.Type
+	return true
+}
@@ -0,0 +1,112 @@
+package payment_test
+
+import (
+	"testing"
+
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/payment"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name    string
+		payment models.Payment
+		event   models.PaymentEvent
+		changed bool
+		after   models.Payment
+	}{
+		{
+			name:    "pending authorized",
+			payment: models.Payment{Status: models.PaymentPending, Amount: 90000.0},
+			event:   models.PaymentEvent{Type: models.PaymentAuthorized, Amount: 85000.004},
+			changed: true,
+			after:   models.Payment{Status: models.PaymentAuthorized, Amount: 85000.0},
+		},
+		{
+			name:    "authorized twice",
+			payment: models.Payment{Status: models.PaymentAuthorized, Amount: 90000.0},
+			event:   models.PaymentEvent{Type: models.PaymentAuthorized, Amount: 10.0},
+			after:   models.Payment{Status: models.PaymentAuthorized, Amount: 90000.0},
+		},
+		{
+			name:    "authorized captured",
+			payment: models.Payment{Status: models.PaymentAuthorized, Amount: 90000.0},
+			event:   models.PaymentEvent{Type: models.PaymentCaptured, Amount: 90000.0},
+			changed: true,
+			after:   models.Payment{Status: models.PaymentCaptured, Amount: 90000.0, Captured: 90000.0},
+		},
+		{
+			name:    "authorized after captured",
+			payment: models.Payment{Status: models.PaymentCaptured, Amount: 90000.0, Captured: 90000.0},
+			event:   models.PaymentEvent{Type: models.PaymentAuthorized, Amount: 90000.0},
+			after:   models.Payment{Status: models.PaymentCaptured, Amount: 90000.0, Captured: 90000.0},
+		},
+		{
+			name:    "voided captured",
+			payment: models.Payment{Status: models.PaymentCaptured, Amount: 90000.0, Captured: 90000.0},
+			event:   models.PaymentEvent{Type: models.PaymentVoided},
+			after:   models.Payment{Status: models.PaymentCaptured, Amount: 90000.0, Captured: 90000.0},
+		},
+		{
+			name:    "partly refunded",
+			payment: models.Payment{Status: models.PaymentCaptured, Amount: 90000.0, Captured: 90000.0},
+			event:   models.PaymentEvent{Type: models.PaymentRefunded, Amount: 30000.0},
+			changed: true,
+			after:   models.Payment{Status: models.PaymentCaptured, Amount: 90000.0, Captured: 90000.0, Refunded: 30000.0},
+		},
+		{
+			name:    "refund replayed",
+			payment: models.Payment{Status: models.PaymentCaptured, Amount: 90000.0, Captured: 90000.0, Refunded: 30000.0},
+			event:   models.PaymentEvent{Type: models.PaymentRefunded, Amount: 30000.0},
+			after:   models.Payment{Status: models.PaymentCaptured, Amount: 90000.0, Captured: 90000.0, Refunded: 30000.0},
+		},
+		{
+			name:    "refunded beyond captured",
+			payment: models.Payment{Status: models.PaymentCaptured, Amount: 90000.0, Captured: 90000.0, Refunded: 30000.0},
+			event:   models.PaymentEvent{Type: models.PaymentRefunded, Amount: 100000.0},
+			changed: true,
+			after:   models.Payment{Status: models.PaymentRefunded, Amount: 90000.0, Captured: 90000.0, Refunded: 90000.0},
+		},
+		{
+			name:    "refunded before captured",
+			payment: models.Payment{Status: models.PaymentAuthorized, Amount: 90000.0},
+			event:   models.PaymentEvent{Type: models.PaymentRefunded, Amount: 90000.0},
+			after:   models.Payment{Status: models.PaymentAuthorized, Amount: 90000.0},
+		},
+		{
+			name:    "pending voided",
+			payment: models.Payment{Status: models.PaymentPending, Amount: 90000.0},
+			event:   models.PaymentEvent{Type: models.PaymentVoided},
+			changed: true,
+			after:   models.Payment{Status: models.PaymentVoided, Amount: 90000.0},
+		},
+		{
+			name:    "pending failed",
+			payment: models.Payment{Status: models.PaymentPending, Amount: 90000.0},
+			event:   models.PaymentEvent{Type: models.PaymentFailed},
+			changed: true,
+			after:   models.Payment{Status: models.PaymentFailed, Amount: 90000.0},
+		},
+		{
+			name:    "authorized failed",
+			payment: models.Payment{Status: models.PaymentAuthorized, Amount: 90000.0},
+			event:   models.PaymentEvent{Type: models.PaymentFailed},
+			after:   models.Payment{Status: models.PaymentAuthorized, Amount: 90000.0},
+		},
+		{
+			name:    "unknown event",
+			payment: models.Payment{Status: models.PaymentPending, Amount: 90000.0},
+			event:   models.PaymentEvent{Type: "disputed"},
+			after:   models.Payment{Status: models.PaymentPending, Amount: 90000.0},
+		},
+	}
+
+	for _, test := range tests {
+		pay := test.payment
+		changed := payment.Apply(&pay, &test.event)
+
+		assert.Equal(t, test.changed, changed, test.name)
+		assert.Equal(t, test.after, pay, test.name)
+	}
+}
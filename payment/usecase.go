@@ -0,0 +1,18 @@
+package payment
+
+import (
+	"context"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// Usecase represent the payment usecase
+type Usecase interface {
+	GetByID(ctx context.Context, id int64) (*models.Payment, error)
+	GetByOrder(ctx context.Context, orderID int64) ([]*models.Payment, error)
+	Authorize(ctx context.Context, orderID int64, source string) (*models.Payment, error)
+	Capture(ctx context.Context, id int64, amount float64) (*models.Payment, error)
+	Refund(ctx context.Context, id int64, amount float64) (*models.Payment, error)
+	Void(ctx context.Context, id int64) (*models.Payment, error)
+	Callback(ctx context.Context, payload []byte, signature string) error
+}
@@ -0,0 +1,310 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/soerjadi/exam/audit"
+	"github.com/soerjadi/exam/auth"
+	"github.com/soerjadi/exam/invoice"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/order"
+	"github.com/soerjadi/exam/payment"
+	"github.com/soerjadi/exam/utils"
+	"gopkg.in/guregu/null.v3"
+)
+
+type paymentUsecase struct {
+	repo           payment.Repository
+	orders         order.Usecase
+	invoices       invoice.Usecase
+	provider       payment.Provider
+	audit          audit.Usecase
+	contextTimeout time.Duration
+}
+
+var logger = utils.LogBuilder(true)
+
+// NewPaymentUsecase will create object that represent of payment.Usecase
+// interface, payments are made through provider and orders are invoiced
+// once their payment is captured
+func NewPaymentUsecase(p payment.Repository, o order.Usecase, i invoice.Usecase, provider payment.Provider, a audit.Usecase, timeout time.Duration) payment.Usecase {
+	return &paymentUsecase{
+		repo:           p,
+		orders:         o,
+		invoices:       i,
+		provider:       provider,
+		audit:          a,
+		contextTimeout: timeout,
+	}
+}
+
+func (p *paymentUsecase) record(ctx context.Context, id int64, action string, before interface{}, after interface{}) {
+	if err := p.audit.Record(ctx, models.AuditPayment, id, action, before, after); err != nil {
+		logger.Error(err)
+	}
+}
+
+// managed return the payment of id if it was made through the provider in use
+func (p *paymentUsecase) managed(ctx context.Context, id int64) (*models.Payment, error) {
+	pay, err := p.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if pay.Provider != p.provider.Name() {
+		return nil, models.ErrPaymentProvider
+	}
+
+	return pay, nil
+}
+
+// apply store the change event make to pay, then bring the order in line
+// with the payment. The order is settled even when the payment did not
+// change so a callback replayed after a failure finish the work.
+func (p *paymentUsecase) apply(ctx context.Context, pay *models.Payment, event *models.PaymentEvent) (*models.Payment, error) {
+	before := *pay
+
+	if payment.Apply(pay, event) {
+		pay.Updated = null.NewTime(
+			time.Now(), true,
+		)
+
+		if err := p.repo.Update(ctx, pay); err != nil {
+			return nil, err
+		}
+
+		p.record(ctx, pay.ID, models.AuditUpdate, &before, pay)
+	}
+
+	return pay, p.settle(ctx, pay)
+}
+
+// settle move a pending order to processed and issue its invoice once its
+// payment is captured, or cancel it once its payment is voided. Orders
+// already moved on are left alone, an invoice missing is still issued.
+func (p *paymentUsecase) settle(ctx context.Context, pay *models.Payment) error {
+	var status int
+	switch pay.Status {
+	case models.PaymentCaptured:
+		status = models.OrderProccessed
+	case models.PaymentVoided:
+		status = models.OrderCancelled
+	default:
+		return nil
+	}
+
+	ord, err := p.orders.GetByID(ctx, pay.OrderID)
+	if err != nil {
+		return err
+	}
+
+	if ord.Status == models.OrderPending {
+		if err = p.orders.Update(ctx, &models.Order{ID: ord.ID, Status: status, Version: ord.Version}); err != nil {
+			return err
+		}
+	}
+
+	if status != models.OrderProccessed {
+		return nil
+	}
+
+	_, err = p.invoices.Issue(ctx, ord.ID)
+	return err
+}
+
+func (p *paymentUsecase) GetByID(ctx context.Context, id int64) (*models.Payment, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.contextTimeout)
+	defer cancel()
+
+	return p.repo.GetByID(ctx, id)
+}
+
+func (p *paymentUsecase) GetByOrder(ctx context.Context, orderID int64) ([]*models.Payment, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.contextTimeout)
+	defer cancel()
+
+	return p.repo.GetByOrder(ctx, orderID)
+}
+
+// Authorize hold the total of a pending order on source. Orders of a
+// customer are only paid by that customer, and an order is paid once:
+// another payment is only made after the previous one failed. Declined
+// payments are kept as failed.
+func (p *paymentUsecase) Authorize(ctx context.Context, orderID int64, source string) (*models.Payment, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.contextTimeout)
+	defer cancel()
+
+	ord, err := p.orders.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if ord.CustomerID.Valid {
+		if customerID, ok := auth.CustomerID(ctx); !ok || customerID != ord.CustomerID.Int64 {
+			return nil, models.ErrNotFound
+		}
+	}
+
+	if ord.Status != models.OrderPending || ord.Total <= 0 {
+		return nil, models.ErrBadParamInput
+	}
+
+	payments, err := p.repo.GetByOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, previous := range payments {
+		if previous.Status != models.PaymentFailed && previous.Status != models.PaymentVoided {
+			return nil, models.ErrBadParamInput
+		}
+	}
+
+	// the pending payment claim the order before the provider is called, the
+	// repository refuse a second one racing it
+	pay := &models.Payment{
+		OrderID:  ord.ID,
+		Provider: p.provider.Name(),
+		Status:   models.PaymentPending,
+		Amount:   ord.Total,
+	}
+
+	err = p.repo.Create(ctx, pay)
+	if err == models.ErrConflict {
+		return nil, models.ErrBadParamInput
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	authorization, err := p.provider.Authorize(ctx, ord.Total, source)
+	if err != nil {
+		// release the order, declined or not the customer can try again
+		pay.Status = models.PaymentFailed
+	} else {
+		pay.Reference = authorization.Reference
+		pay.Status = authorization.Status
+	}
+
+	pay.Updated = null.TimeFrom(time.Now())
+	if updateErr := p.repo.Update(ctx, pay); updateErr != nil {
+		// nothing would ever capture or release the amount held
+		if authorization != nil {
+			if voidErr := p.provider.Void(ctx, authorization.Reference); voidErr != nil {
+				logger.Error(voidErr)
+			}
+		}
+
+		return nil, updateErr
+	}
+
+	p.record(ctx, pay.ID, models.AuditCreate, nil, pay)
+
+	if err != nil && err != models.ErrPaymentDeclined {
+		return nil, err
+	}
+
+	if pay.Status == models.PaymentFailed {
+		return pay, models.ErrPaymentDeclined
+	}
+
+	return pay, p.settle(ctx, pay)
+}
+
+// Capture charge amount of an authorized payment, the whole authorization
+// when amount is 0. What is not captured is released by the provider.
+func (p *paymentUsecase) Capture(ctx context.Context, id int64, amount float64) (*models.Payment, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.contextTimeout)
+	defer cancel()
+
+	pay, err := p.managed(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if amount == 0 {
+		amount = pay.Amount
+	}
+
+	if pay.Status != models.PaymentAuthorized || amount < 0 || amount > pay.Amount {
+		return nil, models.ErrBadParamInput
+	}
+
+	if err = p.provider.Capture(ctx, pay.Reference, amount); err != nil {
+		return nil, err
+	}
+
+	return p.apply(ctx, pay, &models.PaymentEvent{Reference: pay.Reference, Type: models.PaymentCaptured, Amount: amount})
+}
+
+// Refund give back amount of a captured payment, what is left of it when
+// amount is 0
+func (p *paymentUsecase) Refund(ctx context.Context, id int64, amount float64) (*models.Payment, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.contextTimeout)
+	defer cancel()
+
+	pay, err := p.managed(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	left := pay.Captured - pay.Refunded
+	if amount == 0 {
+		amount = left
+	}
+
+	if pay.Status != models.PaymentCaptured || amount <= 0 || amount > left {
+		return nil, models.ErrBadParamInput
+	}
+
+	if err = p.provider.Refund(ctx, pay.Reference, amount); err != nil {
+		return nil, err
+	}
+
+	return p.apply(ctx, pay, &models.PaymentEvent{Reference: pay.Reference, Type: models.PaymentRefunded, Amount: pay.Refunded + amount})
+}
+
+// Void release the authorization of a payment not captured yet, its order is
+// cancelled
+func (p *paymentUsecase) Void(ctx context.Context, id int64) (*models.Payment, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.contextTimeout)
+	defer cancel()
+
+	pay, err := p.managed(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if pay.Status != models.PaymentPending && pay.Status != models.PaymentAuthorized {
+		return nil, models.ErrBadParamInput
+	}
+
+	if err = p.provider.Void(ctx, pay.Reference); err != nil {
+		return nil, err
+	}
+
+	return p.apply(ctx, pay, &models.PaymentEvent{Reference: pay.Reference, Type: models.PaymentVoided})
+}
+
+// Callback apply a change reported by the provider, payload is only trusted
+// once its signature is verified
+func (p *paymentUsecase) Callback(ctx context.Context, payload []byte, signature string) error {
+	ctx, cancel := context.WithTimeout(ctx, p.contextTimeout)
+	defer cancel()
+
+	event, err := p.provider.ParseEvent(payload, signature)
+	if err != nil {
+		return err
+	}
+
+	pay, err := p.repo.GetByReference(ctx, p.provider.Name(), event.Reference)
+	if err != nil {
+		return err
+	}
+
+	ctx = utils.WithActor(ctx, "payment:"+p.provider.Name())
+	_, err = p.apply(ctx, pay, event)
+	return err
+}
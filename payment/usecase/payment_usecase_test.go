@@ -0,0 +1,303 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/soerjadi/exam/audit/audittest"
+	"github.com/soerjadi/exam/auth"
+	invMocks "github.com/soerjadi/exam/invoice/mocks"
+	"github.com/soerjadi/exam/models"
+	orderMocks "github.com/soerjadi/exam/order/mocks"
+	"github.com/soerjadi/exam/payment"
+	"github.com/soerjadi/exam/payment/mocks"
+	"github.com/soerjadi/exam/payment/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gopkg.in/guregu/null.v3"
+)
+
+func pendingOrder() *models.Order {
+	return &models.Order{ID: 8, CustomerID: null.IntFrom(4), Total: 90000.0, Status: models.OrderPending, Version: 2}
+}
+
+func customer(id int64) context.Context {
+	return auth.WithClaims(context.TODO(), &auth.Claims{Subject: id})
+}
+
+func TestAuthorize(t *testing.T) {
+	// the pending payment claiming the order, given its ID as the repository would
+	claim := func(mockRepo *mocks.Repository) {
+		mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(p *models.Payment) bool {
+			return p.Status == models.PaymentPending && p.Amount == 90000.0 && p.Provider == "fake" && p.Reference == ""
+		})).Return(nil).Run(func(args mock.Arguments) {
+			args.Get(1).(*models.Payment).ID = 6
+		}).Once()
+	}
+
+	t.Run("approved", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockOrders := new(orderMocks.Usecase)
+
+		mockOrders.On("GetByID", mock.Anything, int64(8)).Return(pendingOrder(), nil).Once()
+		mockRepo.On("GetByOrder", mock.Anything, int64(8)).
+			Return([]*models.Payment{&models.Payment{ID: 1, OrderID: 8, Status: models.PaymentFailed}}, nil).Once()
+		claim(mockRepo)
+		mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(p *models.Payment) bool {
+			return p.ID == 6 && p.Status == models.PaymentAuthorized && p.Reference != "" && p.Updated.Valid
+		})).Return(nil).Once()
+
+		mockAudit := audittest.NewUsecase()
+		u := usecase.NewPaymentUsecase(mockRepo, mockOrders, new(invMocks.Usecase), payment.NewFakeProvider(payment.FakeApprove, "s3cret"), mockAudit, time.Second*2)
+		pay, err := u.Authorize(customer(4), int64(8), "tok_visa")
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.PaymentAuthorized, pay.Status)
+		mockRepo.AssertExpectations(t)
+		audittest.AssertRecorded(t, mockAudit, models.AuditPayment, int64(6), models.AuditCreate, nil, pay)
+	})
+
+	t.Run("declined", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockOrders := new(orderMocks.Usecase)
+
+		mockOrders.On("GetByID", mock.Anything, int64(8)).Return(pendingOrder(), nil).Once()
+		mockRepo.On("GetByOrder", mock.Anything, int64(8)).Return([]*models.Payment{}, nil).Once()
+		claim(mockRepo)
+		mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(p *models.Payment) bool {
+			return p.ID == 6 && p.Status == models.PaymentFailed
+		})).Return(nil).Once()
+
+		u := usecase.NewPaymentUsecase(mockRepo, mockOrders, new(invMocks.Usecase), payment.NewFakeProvider(payment.FakeApprove, "s3cret"), audittest.NewUsecase(), time.Second*2)
+		_, err := u.Authorize(customer(4), int64(8), payment.FakeDeclineSource)
+
+		assert.Equal(t, models.ErrPaymentDeclined, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("order of another customer", func(t *testing.T) {
+		mockOrders := new(orderMocks.Usecase)
+		mockOrders.On("GetByID", mock.Anything, int64(8)).Return(pendingOrder(), nil).Once()
+
+		u := usecase.NewPaymentUsecase(new(mocks.Repository), mockOrders, new(invMocks.Usecase), payment.NewFakeProvider(payment.FakeApprove, "s3cret"), audittest.NewUsecase(), time.Second*2)
+		_, err := u.Authorize(customer(5), int64(8), "tok_visa")
+
+		assert.Equal(t, models.ErrNotFound, err)
+	})
+
+	t.Run("already paid", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockOrders := new(orderMocks.Usecase)
+
+		mockOrders.On("GetByID", mock.Anything, int64(8)).Return(pendingOrder(), nil).Once()
+		mockRepo.On("GetByOrder", mock.Anything, int64(8)).
+			Return([]*models.Payment{&models.Payment{ID: 1, OrderID: 8, Status: models.PaymentAuthorized}}, nil).Once()
+
+		u := usecase.NewPaymentUsecase(mockRepo, mockOrders, new(invMocks.Usecase), payment.NewFakeProvider(payment.FakeApprove, "s3cret"), audittest.NewUsecase(), time.Second*2)
+		_, err := u.Authorize(customer(4), int64(8), "tok_visa")
+
+		assert.Equal(t, models.ErrBadParamInput, err)
+	})
+
+	t.Run("claimed by a concurrent request", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockOrders := new(orderMocks.Usecase)
+
+		mockOrders.On("GetByID", mock.Anything, int64(8)).Return(pendingOrder(), nil).Once()
+		mockRepo.On("GetByOrder", mock.Anything, int64(8)).Return([]*models.Payment{}, nil).Once()
+		mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Payment")).Return(models.ErrConflict).Once()
+
+		mockAudit := audittest.NewUsecase()
+		u := usecase.NewPaymentUsecase(mockRepo, mockOrders, new(invMocks.Usecase), payment.NewFakeProvider(payment.FakeApprove, "s3cret"), mockAudit, time.Second*2)
+		_, err := u.Authorize(customer(4), int64(8), "tok_visa")
+
+		// the provider is never asked to hold a second amount
+		assert.Equal(t, models.ErrBadParamInput, err)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		audittest.AssertNothingRecorded(t, mockAudit)
+	})
+
+	t.Run("provider down", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockOrders := new(orderMocks.Usecase)
+
+		mockOrders.On("GetByID", mock.Anything, int64(8)).Return(pendingOrder(), nil).Once()
+		mockRepo.On("GetByOrder", mock.Anything, int64(8)).Return([]*models.Payment{}, nil).Once()
+		claim(mockRepo)
+		// the claim is released so the customer can try again
+		mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(p *models.Payment) bool {
+			return p.ID == 6 && p.Status == models.PaymentFailed
+		})).Return(nil).Once()
+
+		u := usecase.NewPaymentUsecase(mockRepo, mockOrders, new(invMocks.Usecase), payment.NewFakeProvider(payment.FakeError, "s3cret"), audittest.NewUsecase(), time.Second*2)
+		_, err := u.Authorize(customer(4), int64(8), "tok_visa")
+
+		assert.Equal(t, models.ErrPaymentProvider, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestCapture(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockOrders := new(orderMocks.Usecase)
+
+	authorized := models.Payment{ID: 3, OrderID: 8, Provider: "fake", Reference: "fake_abc", Status: models.PaymentAuthorized,
+		Amount: 90000.0, Version: 1}
+	current := authorized
+	mockRepo.On("GetByID", mock.Anything, int64(3)).Return(&current, nil).Once()
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(p *models.Payment) bool {
+		return p.Status == models.PaymentCaptured && p.Captured == 90000.0 && p.Updated.Valid
+	})).Return(nil).Once()
+	// the order is processed once its payment is captured
+	mockOrders.On("GetByID", mock.Anything, int64(8)).Return(pendingOrder(), nil).Once()
+	mockOrders.On("Update", mock.Anything, &models.Order{ID: 8, Status: models.OrderProccessed, Version: 2}).Return(nil).Once()
+	// and invoiced
+	mockInvoices := new(invMocks.Usecase)
+	mockInvoices.On("Issue", mock.Anything, int64(8)).Return(&models.Invoice{ID: 5, OrderID: 8}, nil).Once()
+
+	mockAudit := audittest.NewUsecase()
+	u := usecase.NewPaymentUsecase(mockRepo, mockOrders, mockInvoices, payment.NewFakeProvider(payment.FakeApprove, "s3cret"), mockAudit, time.Second*2)
+	pay, err := u.Capture(context.TODO(), int64(3), 0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.PaymentCaptured, pay.Status)
+	mockRepo.AssertExpectations(t)
+	mockOrders.AssertExpectations(t)
+	mockInvoices.AssertExpectations(t)
+	audittest.AssertRecorded(t, mockAudit, models.AuditPayment, int64(3), models.AuditUpdate, &authorized, pay)
+}
+
+func TestRefund(t *testing.T) {
+	captured := func() *models.Payment {
+		return &models.Payment{ID: 3, OrderID: 8, Provider: "fake", Reference: "fake_abc", Status: models.PaymentCaptured,
+			Amount: 90000.0, Captured: 90000.0, Refunded: 30000.0, Version: 2}
+	}
+	processed := &models.Order{ID: 8, Total: 90000.0, Status: models.OrderProccessed}
+
+	t.Run("partial", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockOrders := new(orderMocks.Usecase)
+
+		mockRepo.On("GetByID", mock.Anything, int64(3)).Return(captured(), nil).Once()
+		mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(p *models.Payment) bool {
+			return p.Status == models.PaymentCaptured && p.Refunded == 50000.0
+		})).Return(nil).Once()
+		mockOrders.On("GetByID", mock.Anything, int64(8)).Return(processed, nil).Maybe()
+		// the payment is still captured, its invoice is the one already issued
+		mockInvoices := new(invMocks.Usecase)
+		mockInvoices.On("Issue", mock.Anything, int64(8)).Return(&models.Invoice{ID: 5, OrderID: 8}, nil).Maybe()
+
+		u := usecase.NewPaymentUsecase(mockRepo, mockOrders, mockInvoices, payment.NewFakeProvider(payment.FakeApprove, "s3cret"), audittest.NewUsecase(), time.Second*2)
+		_, err := u.Refund(context.TODO(), int64(3), 20000.0)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		mockOrders.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("what is left", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+
+		mockRepo.On("GetByID", mock.Anything, int64(3)).Return(captured(), nil).Once()
+		mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(p *models.Payment) bool {
+			return p.Status == models.PaymentRefunded && p.Refunded == 90000.0
+		})).Return(nil).Once()
+
+		u := usecase.NewPaymentUsecase(mockRepo, new(orderMocks.Usecase), new(invMocks.Usecase), payment.NewFakeProvider(payment.FakeApprove, "s3cret"), audittest.NewUsecase(), time.Second*2)
+		_, err := u.Refund(context.TODO(), int64(3), 0)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("more than captured", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockRepo.On("GetByID", mock.Anything, int64(3)).Return(captured(), nil).Once()
+
+		u := usecase.NewPaymentUsecase(mockRepo, new(orderMocks.Usecase), new(invMocks.Usecase), payment.NewFakeProvider(payment.FakeApprove, "s3cret"), audittest.NewUsecase(), time.Second*2)
+		_, err := u.Refund(context.TODO(), int64(3), 70000.0)
+
+		assert.Equal(t, models.ErrBadParamInput, err)
+	})
+}
+
+func TestCallback(t *testing.T) {
+	provider := payment.NewFakeProvider(payment.FakePending, "s3cret")
+	pending := func() *models.Payment {
+		return &models.Payment{ID: 3, OrderID: 8, Provider: "fake", Reference: "fake_abc", Status: models.PaymentPending,
+			Amount: 90000.0, Version: 1}
+	}
+
+	t.Run("voided", func(t *testing.T) {
+		payload, _ := json.Marshal(&models.PaymentEvent{Reference: "fake_abc", Type: models.PaymentVoided})
+
+		mockRepo := new(mocks.Repository)
+		mockOrders := new(orderMocks.Usecase)
+
+		mockRepo.On("GetByReference", mock.Anything, "fake", "fake_abc").Return(pending(), nil).Once()
+		mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(p *models.Payment) bool {
+			return p.Status == models.PaymentVoided
+		})).Return(nil).Once()
+		mockOrders.On("GetByID", mock.Anything, int64(8)).Return(pendingOrder(), nil).Once()
+		mockOrders.On("Update", mock.Anything, &models.Order{ID: 8, Status: models.OrderCancelled, Version: 2}).Return(nil).Once()
+
+		u := usecase.NewPaymentUsecase(mockRepo, mockOrders, new(invMocks.Usecase), provider, audittest.NewUsecase(), time.Second*2)
+		err := u.Callback(context.TODO(), payload, provider.Sign(payload))
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		mockOrders.AssertExpectations(t)
+	})
+
+	t.Run("replayed", func(t *testing.T) {
+		payload, _ := json.Marshal(&models.PaymentEvent{Reference: "fake_abc", Type: models.PaymentAuthorized, Amount: 90000.0})
+		authorized := pending()
+		authorized.Status = models.PaymentAuthorized
+
+		mockRepo := new(mocks.Repository)
+		mockRepo.On("GetByReference", mock.Anything, "fake", "fake_abc").Return(authorized, nil).Once()
+
+		mockAudit := audittest.NewUsecase()
+		u := usecase.NewPaymentUsecase(mockRepo, new(orderMocks.Usecase), new(invMocks.Usecase), provider, mockAudit, time.Second*2)
+		err := u.Callback(context.TODO(), payload, provider.Sign(payload))
+
+		assert.NoError(t, err)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		audittest.AssertNothingRecorded(t, mockAudit)
+	})
+
+	t.Run("capture replayed after invoicing failed", func(t *testing.T) {
+		payload, _ := json.Marshal(&models.PaymentEvent{Reference: "fake_abc", Type: models.PaymentCaptured, Amount: 90000.0})
+		captured := pending()
+		captured.Status = models.PaymentCaptured
+		captured.Captured = 90000.0
+		processed := pendingOrder()
+		processed.Status = models.OrderProccessed
+
+		mockRepo := new(mocks.Repository)
+		mockRepo.On("GetByReference", mock.Anything, "fake", "fake_abc").Return(captured, nil).Once()
+		mockOrders := new(orderMocks.Usecase)
+		mockOrders.On("GetByID", mock.Anything, int64(8)).Return(processed, nil).Once()
+		mockInvoices := new(invMocks.Usecase)
+		mockInvoices.On("Issue", mock.Anything, int64(8)).Return(&models.Invoice{ID: 5, OrderID: 8}, nil).Once()
+
+		u := usecase.NewPaymentUsecase(mockRepo, mockOrders, mockInvoices, provider, audittest.NewUsecase(), time.Second*2)
+		err := u.Callback(context.TODO(), payload, provider.Sign(payload))
+
+		// the order already moved on, the invoice is still issued
+		assert.NoError(t, err)
+		mockOrders.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		mockInvoices.AssertExpectations(t)
+	})
+
+	t.Run("forged", func(t *testing.T) {
+		payload, _ := json.Marshal(&models.PaymentEvent{Reference: "fake_abc", Type: models.PaymentCaptured, Amount: 90000.0})
+
+		u := usecase.NewPaymentUsecase(new(mocks.Repository), new(orderMocks.Usecase), new(invMocks.Usecase), provider, audittest.NewUsecase(), time.Second*2)
+		err := u.Callback(context.TODO(), payload, payment.NewFakeProvider(payment.FakePending, "guess").Sign(payload))
+
+		assert.Equal(t, models.ErrUnauthorized, err)
+	})
+}
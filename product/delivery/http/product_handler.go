@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/soerjadi/exam/category"
@@ -15,6 +16,7 @@ import (
 	price "github.com/soerjadi/exam/product_price"
 	t "github.com/soerjadi/exam/types"
 	"github.com/soerjadi/exam/utils"
+	"gopkg.in/guregu/null.v3"
 )
 
 type productPrice struct {
@@ -23,10 +25,19 @@ type productPrice struct {
 }
 
 type newProduct struct {
-	Name       string         `json:"name"`
-	SKU        string         `json:"sku"`
-	CategoryID []int64        `json:"category_id"`
-	Price      []productPrice `json:"price"`
+	Name        string         `json:"name"`
+	SKU         string         `json:"sku"`
+	CategoryID  []int64        `json:"category_id"`
+	Price       []productPrice `json:"price"`
+	Status      int            `json:"status"`
+	PublishAt   null.Time      `json:"publish_at"`
+	UnpublishAt null.Time      `json:"unpublish_at"`
+	Weight      float64        `json:"weight"`
+	Length      float64        `json:"length"`
+	Width       float64        `json:"width"`
+	Height      float64        `json:"height"`
+	TaxClassID  null.Int       `json:"tax_class_id"`
+	Stock       int64          `json:"stock"`
 }
 
 type updateProductData struct {
@@ -35,6 +46,41 @@ type updateProductData struct {
 	SKU        string         `json:"sku"`
 	CategoryID []int64        `json:"category_id"`
 	Price      []productPrice `json:"price"`
+	Version    int64          `json:"version"`
+	Weight     float64        `json:"weight"`
+	Length     float64        `json:"length"`
+	Width      float64        `json:"width"`
+	Height     float64        `json:"height"`
+	TaxClassID null.Int       `json:"tax_class_id"`
+}
+
+// sentFields tell which fields the JSON object in body has, a field set to
+// null is sent while one left out is not
+func sentFields(body []byte) (map[string]bool, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, err
+	}
+
+	sent := make(map[string]bool, len(fields))
+	for name := range fields {
+		sent[name] = true
+	}
+
+	return sent, nil
+}
+
+type schedulePriceData struct {
+	ValidFrom time.Time      `json:"valid_from"`
+	Price     []productPrice `json:"price"`
+}
+
+type productStatusData struct {
+	ID          int64     `json:"id"`
+	Status      int       `json:"status"`
+	PublishAt   null.Time `json:"publish_at"`
+	UnpublishAt null.Time `json:"unpublish_at"`
+	Version     int64     `json:"version"`
 }
 
 // ProductHandler represent the http handler for product
@@ -48,11 +94,12 @@ type ProductHandler struct {
 var logger = utils.LogBuilder(true)
 
 // NewProductHandler initialize product resource endpoint
-func NewProductHandler(router *mux.Router, usecase product.Usecase, catUsecase cat.Usecase, categoryUsecase category.Usecase) *mux.Router {
+func NewProductHandler(router *mux.Router, usecase product.Usecase, catUsecase cat.Usecase, categoryUsecase category.Usecase, priceUsecase price.Usecase) *mux.Router {
 	handler := &ProductHandler{
 		ProductUsecase:    usecase,
 		ProductCatUsecase: catUsecase,
 		CategoryUsecase:   categoryUsecase,
+		PriceUsecase:      priceUsecase,
 	}
 
 	p := router.PathPrefix("/v1/product").Subrouter()
@@ -61,6 +108,17 @@ func NewProductHandler(router *mux.Router, usecase product.Usecase, catUsecase c
 	p.HandleFunc("/detail", handler.GetByID).Methods("GET")
 	p.HandleFunc("/compare/{ID1}/{ID2}", handler.CompareProduct).Methods("GET")
 	p.HandleFunc("/search", handler.SearchProduct).Methods("GET")
+	p.HandleFunc("/delete", handler.DeleteProduct).Methods("GET")
+	p.HandleFunc("/trash", handler.Trash).Methods("GET")
+	p.HandleFunc("/restore", handler.Restore).Methods("GET")
+	p.HandleFunc("/{id:[0-9]+}/prices", handler.PriceTimeline).Methods("GET")
+	p.HandleFunc("/{id:[0-9]+}/prices", handler.SchedulePrice).Methods("POST")
+
+	// admin endpoints see products regardless of their status
+	a := router.PathPrefix("/v1/admin/product").Subrouter()
+	a.HandleFunc("/detail", handler.AdminGetByID).Methods("GET")
+	a.HandleFunc("/search", handler.AdminSearchProduct).Methods("GET")
+	a.HandleFunc("/status", handler.UpdateStatus).Methods("POST")
 	return p
 }
 
@@ -83,8 +141,17 @@ func (h *ProductHandler) AddProduct(w http.ResponseWriter, r *http.Request) {
 	}
 
 	product := models.Product{
-		Name: newProduct.Name,
-		SKU:  newProduct.SKU,
+		Name:        newProduct.Name,
+		SKU:         newProduct.SKU,
+		Status:      newProduct.Status,
+		PublishAt:   newProduct.PublishAt,
+		UnpublishAt: newProduct.UnpublishAt,
+		Weight:      newProduct.Weight,
+		Length:      newProduct.Length,
+		Width:       newProduct.Width,
+		Height:      newProduct.Height,
+		TaxClassID:  newProduct.TaxClassID,
+		Stock:       newProduct.Stock,
 	}
 	err = h.ProductUsecase.Create(ctx, &product)
 
@@ -151,30 +218,75 @@ func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	product := models.Product{
-		ID:   updateProduct.ID,
-		Name: updateProduct.Name,
-		SKU:  updateProduct.SKU,
+	// If-Match take precedence over the version sent in the body
+	version, ok, err := utils.IfMatchVersion(r)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	err = h.ProductUsecase.Update(ctx, &product)
+	if ok {
+		updateProduct.Version = version
+	}
 
+	sent, err := sentFields(body)
 	if err != nil {
 		utils.Error(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	err = h.ProductCatUsecase.DeleteByProductID(ctx, product.ID)
+	product := models.Product{
+		ID:         updateProduct.ID,
+		Name:       updateProduct.Name,
+		SKU:        updateProduct.SKU,
+		Version:    updateProduct.Version,
+		Weight:     updateProduct.Weight,
+		Length:     updateProduct.Length,
+		Width:      updateProduct.Width,
+		Height:     updateProduct.Height,
+		TaxClassID: updateProduct.TaxClassID,
+	}
+
+	// the package and tax class left out of the body stay as they are, a
+	// zero dimension or a null tax class clear them
+	if !sent["weight"] {
+		product.Weight = origProduct.Weight
+	}
+
+	if !sent["length"] {
+		product.Length = origProduct.Length
+	}
+
+	if !sent["width"] {
+		product.Width = origProduct.Width
+	}
+
+	if !sent["height"] {
+		product.Height = origProduct.Height
+	}
+
+	if !sent["tax_class_id"] {
+		product.TaxClassID = origProduct.TaxClassID
+	}
+
+	err = h.ProductUsecase.Update(ctx, &product)
+
+	if err == models.ErrConflict {
+		utils.Error(w, http.StatusPreconditionFailed, err.Error())
+		return
+	}
 
-	// Revert when get an error update link product category
 	if err != nil {
-		_ = h.ProductUsecase.Update(ctx, origProduct)
 		utils.Error(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	err = h.PriceUsecase.DeleteByProductID(ctx, product.ID)
-	// Revert when get an error update price
+	// a revert is a regular update on top of the version we just wrote
+	origProduct.Version = product.Version
+
+	err = h.ProductCatUsecase.DeleteByProductID(ctx, product.ID)
+
+	// Revert when get an error update link product category
 	if err != nil {
 		_ = h.ProductUsecase.Update(ctx, origProduct)
 		utils.Error(w, http.StatusBadRequest, err.Error())
@@ -194,26 +306,39 @@ func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	for _, price := range updateProduct.Price {
-		_price := models.ProductPrice{
-			Amount:    price.Amount,
-			Price:     price.Price,
-			ProductID: product.ID,
+	// prices are left untouched when the payload omit them, prices in effect
+	// are closed rather than deleted to keep the history
+	if updateProduct.Price != nil {
+		prices := make([]*models.ProductPrice, 0)
+		for _, price := range updateProduct.Price {
+			prices = append(prices, &models.ProductPrice{
+				Amount: price.Amount,
+				Price:  price.Price,
+			})
 		}
 
-		err = h.PriceUsecase.Create(ctx, &_price)
-
+		err = h.PriceUsecase.Schedule(ctx, product.ID, prices, time.Time{})
 		if err != nil {
 			utils.Error(w, http.StatusBadRequest, err.Error())
 			return
 		}
 	}
 
+	w.Header().Set("ETag", utils.ETag(product.Version))
 	utils.JSON(w, http.StatusOK, product)
 }
 
-// GetByID get detail product from given ID
+// GetByID get detail of a published product from given ID
 func (h *ProductHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	h.detail(w, r, h.ProductUsecase.GetPublishedByID)
+}
+
+// AdminGetByID get detail product from given ID whatever its status
+func (h *ProductHandler) AdminGetByID(w http.ResponseWriter, r *http.Request) {
+	h.detail(w, r, h.ProductUsecase.GetByID)
+}
+
+func (h *ProductHandler) detail(w http.ResponseWriter, r *http.Request, get func(context.Context, int64) (*models.Product, error)) {
 	params := r.URL.Query()
 	id, err := strconv.ParseInt(params.Get("id"), 0, 64)
 
@@ -227,7 +352,7 @@ func (h *ProductHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		ctx = context.Background()
 	}
 
-	product, err := h.ProductUsecase.GetByID(ctx, id)
+	product, err := get(ctx, id)
 	cats := make([]*models.Category, 0)
 
 	if err != nil {
@@ -252,13 +377,43 @@ func (h *ProductHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	}
 
 	result := t.Product{
-		ID:       product.ID,
-		Name:     product.Name,
-		SKU:      product.SKU,
-		Category: cats,
+		ID:          product.ID,
+		Name:        product.Name,
+		SKU:         product.SKU,
+		Status:      product.Status,
+		PublishAt:   product.PublishAt,
+		UnpublishAt: product.UnpublishAt,
+		Category:    cats,
+		Version:     product.Version,
+		Weight:      product.Weight,
+		Length:      product.Length,
+		Width:       product.Width,
+		Height:      product.Height,
+		TaxClassID:  product.TaxClassID,
+		Stock:       product.Stock,
+	}
+
+	body, err := json.Marshal(&utils.DefaultResponse{
+		Message: "success",
+		Code:    http.StatusOK,
+		Result:  result,
+	})
+	if err != nil {
+		utils.Error(w, http.StatusInternalServerError, "failed to parse json")
+		return
 	}
 
-	utils.JSON(w, http.StatusOK, result)
+	// the categories are part of the response but not of the product version
+	etag := utils.ContentETag(product.Version, body)
+	w.Header().Set("ETag", etag)
+
+	if utils.NotModified(r, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	utils.Respond(w, http.StatusOK, body)
 }
 
 // CompareProduct detail product
@@ -293,8 +448,17 @@ func (h *ProductHandler) CompareProduct(w http.ResponseWriter, r *http.Request)
 	utils.JSON(w, http.StatusOK, products)
 }
 
-// SearchProduct search product from specific query
+// SearchProduct search published product from specific query
 func (h *ProductHandler) SearchProduct(w http.ResponseWriter, r *http.Request) {
+	h.search(w, r, h.ProductUsecase.SearchPublished)
+}
+
+// AdminSearchProduct search product from specific query whatever its status
+func (h *ProductHandler) AdminSearchProduct(w http.ResponseWriter, r *http.Request) {
+	h.search(w, r, h.ProductUsecase.Search)
+}
+
+func (h *ProductHandler) search(w http.ResponseWriter, r *http.Request, find func(context.Context, *string, int64, int64) ([]*models.Product, int64, error)) {
 	params := r.URL.Query()
 	query := params.Get("query")
 	limit, err := strconv.ParseInt(params.Get("limit"), 0, 64)
@@ -318,7 +482,7 @@ func (h *ProductHandler) SearchProduct(w http.ResponseWriter, r *http.Request) {
 		ctx = context.Background()
 	}
 
-	products, found, err := h.ProductUsecase.Search(ctx, &query, offset, limit)
+	products, found, err := find(ctx, &query, offset, limit)
 
 	if err != nil {
 		utils.Error(w, http.StatusBadRequest, err.Error())
@@ -333,6 +497,59 @@ func (h *ProductHandler) SearchProduct(w http.ResponseWriter, r *http.Request) {
 	utils.JSON(w, http.StatusOK, entriesResult)
 }
 
+// UpdateStatus change status and publishing schedule of a product
+func (h *ProductHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var statusData productStatusData
+	body, _ := ioutil.ReadAll(r.Body)
+	err := json.Unmarshal(body, &statusData)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// If-Match take precedence over the version sent in the body
+	version, ok, err := utils.IfMatchVersion(r)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if ok {
+		statusData.Version = version
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	product := models.Product{
+		ID:          statusData.ID,
+		Status:      statusData.Status,
+		PublishAt:   statusData.PublishAt,
+		UnpublishAt: statusData.UnpublishAt,
+		Version:     statusData.Version,
+	}
+
+	err = h.ProductUsecase.UpdateStatus(ctx, &product)
+
+	if err == models.ErrConflict {
+		utils.Error(w, http.StatusPreconditionFailed, err.Error())
+		return
+	}
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("ETag", utils.ETag(product.Version))
+	utils.JSON(w, http.StatusOK, product)
+}
+
 // DeleteProduct will delete product by given ID
 func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 	params := r.URL.Query()
@@ -355,8 +572,149 @@ func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// escape error because there is no connection between product and category already
-	_ = h.ProductCatUsecase.DeleteByProductID(ctx, id)
+	// product category links are kept so the product can be restored,
+	// they are removed together with the product once it is purged
+	utils.JSON(w, http.StatusOK, "success")
+}
+
+// Trash list deleted products that still can be restored
+func (h *ProductHandler) Trash(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	limit, err := strconv.ParseInt(params.Get("limit"), 0, 64)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	offset, err := strconv.ParseInt(params.Get("offset"), 0, 64)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if limit == 0 {
+		limit = 10
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	products, found, err := h.ProductUsecase.Trash(ctx, offset, limit)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entriesResult := &utils.EntriesResponse{
+		Data:  products,
+		Found: found,
+	}
+
+	utils.JSON(w, http.StatusOK, entriesResult)
+}
+
+// Restore will bring back deleted product by given ID
+func (h *ProductHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	id, err := strconv.ParseInt(params.Get("id"), 0, 64)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	err = h.ProductUsecase.Restore(ctx, id)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	utils.JSON(w, http.StatusOK, "success")
 }
+
+// PriceTimeline list every price a product had, has and will have
+func (h *ProductHandler) PriceTimeline(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 0, 64)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	prices, err := h.PriceUsecase.GetTimeline(ctx, id)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, prices)
+}
+
+// SchedulePrice replace prices of a product starting from given time,
+// immediately when valid_from is omitted
+func (h *ProductHandler) SchedulePrice(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 0, 64)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var schedule schedulePriceData
+	body, _ := ioutil.ReadAll(r.Body)
+	err = json.Unmarshal(body, &schedule)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !schedule.ValidFrom.IsZero() && schedule.ValidFrom.Before(time.Now()) {
+		utils.Error(w, http.StatusBadRequest, models.ErrBadParamInput.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if _, err = h.ProductUsecase.GetByID(ctx, id); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	prices := make([]*models.ProductPrice, 0)
+	for _, price := range schedule.Price {
+		prices = append(prices, &models.ProductPrice{
+			Amount: price.Amount,
+			Price:  price.Price,
+		})
+	}
+
+	err = h.PriceUsecase.Schedule(ctx, id, prices, schedule.ValidFrom)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, prices)
+}
@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/bxcodec/faker"
 	"github.com/gorilla/mux"
@@ -114,7 +115,7 @@ func TestUpdate(t *testing.T) {
 	mockUsecase.On("GetByID", mock.Anything, mock.AnythingOfType("int64")).Return(&mockProduct, nil)
 	mockUsecase.On("Update", mock.Anything, mock.AnythingOfType("*models.Product")).Return(nil)
 	mockCatUsecase.On("DeleteByProductID", mock.Anything, mock.AnythingOfType("int64")).Return(nil)
-	mockPriceUsecase.On("DeleteByProductID", mock.Anything, mock.AnythingOfType("int64")).Return(nil)
+	mockPriceUsecase.On("Schedule", mock.Anything, mock.AnythingOfType("int64"), mock.Anything, mock.AnythingOfType("time.Time")).Return(nil)
 
 	j, err := json.Marshal(mockProduct)
 	assert.NoError(t, err)
@@ -145,6 +146,85 @@ func TestUpdate(t *testing.T) {
 	assert.Equal(t, http.StatusOK, rec.Code)
 	assert.Equal(t, string(expectedResponse), rec.Body.String())
 	mockUsecase.AssertExpectations(t)
+	mockPriceUsecase.AssertNotCalled(t, "Schedule", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateWithPrices(t *testing.T) {
+	mockProduct := models.Product{
+		ID:   90,
+		Name: "product 90",
+		SKU:  "sku90",
+	}
+
+	mockUsecase := new(mocks.Usecase)
+	mockCatUsecase := new(catMocks.Usecase)
+	mockPriceUsecase := new(price.Usecase)
+
+	mockUsecase.On("GetByID", mock.Anything, mockProduct.ID).Return(&mockProduct, nil)
+	mockUsecase.On("Update", mock.Anything, mock.AnythingOfType("*models.Product")).Return(nil)
+	mockCatUsecase.On("DeleteByProductID", mock.Anything, mockProduct.ID).Return(nil)
+	mockPriceUsecase.On("Schedule", mock.Anything, mockProduct.ID, mock.MatchedBy(func(prices []*models.ProductPrice) bool {
+		return len(prices) == 1 && prices[0].Price == 7000.0
+	}), time.Time{}).Return(nil).Once()
+
+	body := `{"id":90,"name":"product 90","sku":"sku90","price":[{"amount":1,"price":7000}]}`
+	req, err := http.NewRequest("POST", "/v1/product/update", strings.NewReader(body))
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	handler := productHttp.ProductHandler{
+		ProductUsecase:    mockUsecase,
+		ProductCatUsecase: mockCatUsecase,
+		PriceUsecase:      mockPriceUsecase,
+	}
+
+	handler.UpdateProduct(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockPriceUsecase.AssertExpectations(t)
+}
+
+func TestUpdatePackage(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		check func(p *models.Product) bool
+	}{
+		{"left out keep the current ones", `{"id":92,"name":"renamed","height":12}`, func(p *models.Product) bool {
+			return p.Weight == 1.5 && p.Length == 30 && p.Width == 20 && p.Height == 12 && p.TaxClassID.Int64 == 2
+		}},
+		{"zero and null clear them", `{"id":92,"name":"renamed","weight":0,"length":0,"width":0,"height":0,"tax_class_id":null}`, func(p *models.Product) bool {
+			return p.Weight == 0 && p.Length == 0 && p.Width == 0 && p.Height == 0 && !p.TaxClassID.Valid
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockProduct := models.Product{ID: 92, Name: "product 92", Weight: 1.5, Length: 30, Width: 20, Height: 10,
+				TaxClassID: null.IntFrom(2)}
+
+			mockUsecase := new(mocks.Usecase)
+			mockCatUsecase := new(catMocks.Usecase)
+			mockUsecase.On("GetByID", mock.Anything, mockProduct.ID).Return(&mockProduct, nil)
+			mockUsecase.On("Update", mock.Anything, mock.MatchedBy(test.check)).Return(nil).Once()
+			mockCatUsecase.On("DeleteByProductID", mock.Anything, mockProduct.ID).Return(nil)
+
+			req, err := http.NewRequest("POST", "/v1/product/update", strings.NewReader(test.body))
+			assert.NoError(t, err)
+
+			rec := httptest.NewRecorder()
+			handler := productHttp.ProductHandler{
+				ProductUsecase:    mockUsecase,
+				ProductCatUsecase: mockCatUsecase,
+				PriceUsecase:      new(price.Usecase),
+			}
+
+			handler.UpdateProduct(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			mockUsecase.AssertExpectations(t)
+		})
+	}
 }
 
 func TestGetByID(t *testing.T) {
@@ -170,7 +250,7 @@ func TestGetByID(t *testing.T) {
 		ParentID: null.NewInt(int64(0), true),
 	}
 
-	mockUsecase.On("GetByID", mock.Anything, id).Return(&mockProduct, nil)
+	mockUsecase.On("GetPublishedByID", mock.Anything, id).Return(&mockProduct, nil)
 	mockCatUsecase.On("GetByProductID", mock.Anything, mock.AnythingOfType("int64")).Return(cats, nil)
 	mockCategoryUsecase.On("GetByID", mock.Anything, mock.AnythingOfType("int64")).Return(&category, nil)
 
@@ -247,3 +327,259 @@ func TestDeleteProduct(t *testing.T) {
 	assert.Equal(t, http.StatusOK, rec.Code)
 	mockUsecase.AssertExpectations(t)
 }
+
+func TestTrash(t *testing.T) {
+	var mockProduct models.Product
+	err := faker.FakeData(&mockProduct)
+	assert.NoError(t, err)
+
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("Trash", mock.Anything, int64(0), int64(10)).Return([]*models.Product{&mockProduct}, int64(1), nil)
+
+	req, err := http.NewRequest("GET", "/v1/product/trash?offset=0&limit=10", strings.NewReader(""))
+	assert.NoError(t, err)
+
+	handler := productHttp.ProductHandler{
+		ProductUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.Trash(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestRestore(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("Restore", mock.Anything, int64(89)).Return(nil)
+
+	req, err := http.NewRequest("GET", "/v1/product/restore?id=89", strings.NewReader(""))
+	assert.NoError(t, err)
+
+	handler := productHttp.ProductHandler{
+		ProductUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.Restore(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestGetByIDNotModified(t *testing.T) {
+	mockProduct := models.Product{
+		ID:      89,
+		Name:    "product 89",
+		SKU:     "sku89",
+		Version: 4,
+	}
+
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("GetPublishedByID", mock.Anything, mockProduct.ID).Return(&mockProduct, nil)
+
+	mockCatUsecase := new(catMocks.Usecase)
+	mockCatUsecase.On("GetByProductID", mock.Anything, mockProduct.ID).
+		Return([]*models.ProductCategory{{ID: 1, ProductID: 89, CategoryID: 10}}, nil).Twice()
+	mockCatUsecase.On("GetByProductID", mock.Anything, mockProduct.ID).
+		Return([]*models.ProductCategory{{ID: 1, ProductID: 89, CategoryID: 10}, {ID: 2, ProductID: 89, CategoryID: 11}}, nil).Once()
+
+	mockCategoryUsecase := new(categoryMocks.Usecase)
+	mockCategoryUsecase.On("GetByID", mock.Anything, int64(10)).Return(&models.Category{ID: 10, Name: "shoes"}, nil)
+	mockCategoryUsecase.On("GetByID", mock.Anything, int64(11)).Return(&models.Category{ID: 11, Name: "sale"}, nil)
+
+	handler := productHttp.ProductHandler{
+		ProductUsecase:    mockUsecase,
+		ProductCatUsecase: mockCatUsecase,
+		CategoryUsecase:   mockCategoryUsecase,
+	}
+
+	get := func(etag string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("GET", "/v1/product/detail?id=89", strings.NewReader(""))
+		assert.NoError(t, err)
+		req.Header.Set("If-None-Match", etag)
+
+		rec := httptest.NewRecorder()
+		handler.GetByID(rec, req)
+		return rec
+	}
+
+	rec := get("")
+	etag := rec.Header().Get("ETag")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, strings.HasPrefix(etag, `"4-`))
+
+	rec = get(etag)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+
+	// a category added to the product change the response, not the version
+	rec = get(etag)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotEqual(t, etag, rec.Header().Get("ETag"))
+	mockUsecase.AssertExpectations(t)
+	mockCatUsecase.AssertExpectations(t)
+}
+
+func TestUpdateStale(t *testing.T) {
+	mockProduct := models.Product{
+		ID:      89,
+		Name:    "product 89",
+		SKU:     "sku89",
+		Version: 4,
+	}
+
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("GetByID", mock.Anything, mock.AnythingOfType("int64")).Return(&mockProduct, nil)
+	mockUsecase.On("Update", mock.Anything, mock.MatchedBy(func(p *models.Product) bool {
+		return p.Version == 3
+	})).Return(models.ErrConflict)
+
+	j, err := json.Marshal(mockProduct)
+	assert.NoError(t, err)
+
+	req, err := http.NewRequest("POST", "/v1/product/update", strings.NewReader(string(j)))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	// the tag of the detail response, the version come first
+	req.Header.Set("If-Match", `"3-5d41402abc4b2a76"`)
+
+	handler := productHttp.ProductHandler{
+		ProductUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.UpdateProduct(rec, req)
+
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestAdminGetByID(t *testing.T) {
+	mockProduct := models.Product{
+		ID:      90,
+		Name:    "product 90",
+		SKU:     "sku90",
+		Status:  models.ProductDraft,
+		Version: 1,
+	}
+
+	mockUsecase := new(mocks.Usecase)
+	mockCatUsecase := new(catMocks.Usecase)
+	mockUsecase.On("GetByID", mock.Anything, mockProduct.ID).Return(&mockProduct, nil)
+	mockCatUsecase.On("GetByProductID", mock.Anything, mockProduct.ID).Return([]*models.ProductCategory{}, nil)
+
+	req, err := http.NewRequest("GET", "/v1/admin/product/detail?id=90", strings.NewReader(""))
+	assert.NoError(t, err)
+
+	handler := productHttp.ProductHandler{
+		ProductUsecase:    mockUsecase,
+		ProductCatUsecase: mockCatUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.AdminGetByID(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"status":0`)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestUpdateStatus(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("UpdateStatus", mock.Anything, mock.MatchedBy(func(p *models.Product) bool {
+		return p.ID == 90 && p.Status == models.ProductDraft && p.PublishAt.Valid && p.Version == 1
+	})).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.Product).Version = 2
+	})
+
+	body := `{"id":90,"status":0,"publish_at":"2019-11-29T00:00:00Z"}`
+	req, err := http.NewRequest("POST", "/v1/admin/product/status", strings.NewReader(body))
+	assert.NoError(t, err)
+	req.Header.Set("If-Match", `"1"`)
+
+	handler := productHttp.ProductHandler{
+		ProductUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.UpdateStatus(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `"2"`, rec.Header().Get("ETag"))
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestSchedulePrice(t *testing.T) {
+	mockProduct := models.Product{
+		ID:   91,
+		Name: "product 91",
+	}
+
+	mockUsecase := new(mocks.Usecase)
+	mockPriceUsecase := new(price.Usecase)
+	mockUsecase.On("GetByID", mock.Anything, mockProduct.ID).Return(&mockProduct, nil)
+	mockPriceUsecase.On("Schedule", mock.Anything, mockProduct.ID, mock.MatchedBy(func(prices []*models.ProductPrice) bool {
+		return len(prices) == 1 && prices[0].Price == 7000.0
+	}), mock.AnythingOfType("time.Time")).Return(nil)
+
+	validFrom := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	body := `{"valid_from":"` + validFrom + `","price":[{"amount":1,"price":7000}]}`
+	req, err := http.NewRequest("POST", "/v1/product/91/prices", strings.NewReader(body))
+	assert.NoError(t, err)
+	req = mux.SetURLVars(req, map[string]string{"id": "91"})
+
+	handler := productHttp.ProductHandler{
+		ProductUsecase: mockUsecase,
+		PriceUsecase:   mockPriceUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.SchedulePrice(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockPriceUsecase.AssertExpectations(t)
+}
+
+func TestSchedulePriceInPast(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockPriceUsecase := new(price.Usecase)
+
+	body := `{"valid_from":"2019-11-29T00:00:00Z","price":[{"amount":1,"price":7000}]}`
+	req, err := http.NewRequest("POST", "/v1/product/91/prices", strings.NewReader(body))
+	assert.NoError(t, err)
+	req = mux.SetURLVars(req, map[string]string{"id": "91"})
+
+	handler := productHttp.ProductHandler{
+		ProductUsecase: mockUsecase,
+		PriceUsecase:   mockPriceUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.SchedulePrice(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockPriceUsecase.AssertNotCalled(t, "Schedule", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPriceTimeline(t *testing.T) {
+	mockPriceUsecase := new(price.Usecase)
+	mockPriceUsecase.On("GetTimeline", mock.Anything, int64(91)).Return([]*models.ProductPrice{
+		&models.ProductPrice{ID: 1, Amount: 1, Price: 9000.0, ProductID: 91},
+	}, nil)
+
+	req, err := http.NewRequest("GET", "/v1/product/91/prices", strings.NewReader(""))
+	assert.NoError(t, err)
+	req = mux.SetURLVars(req, map[string]string{"id": "91"})
+
+	handler := productHttp.ProductHandler{
+		PriceUsecase: mockPriceUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.PriceTimeline(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockPriceUsecase.AssertExpectations(t)
+}
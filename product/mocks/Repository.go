@@ -3,6 +3,7 @@
 package mocks
 
 import context "context"
+import time "time"
 import mock "github.com/stretchr/testify/mock"
 import models "github.com/soerjadi/exam/models"
 
@@ -11,6 +12,29 @@ type Repository struct {
 	mock.Mock
 }
 
+// ArchiveDue provides a mock function with given fields: ctx, now
+func (_m *Repository) ArchiveDue(ctx context.Context, now time.Time) ([]int64, error) {
+	ret := _m.Called(ctx, now)
+
+	var r0 []int64
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) []int64); ok {
+		r0 = rf(ctx, now)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]int64)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Create provides a mock function with given fields: ctx, _a1
 func (_m *Repository) Create(ctx context.Context, _a1 *models.Product) error {
 	ret := _m.Called(ctx, _a1)
@@ -39,6 +63,29 @@ func (_m *Repository) Delete(ctx context.Context, id int64) error {
 	return r0
 }
 
+// GetAnyByID provides a mock function with given fields: ctx, id
+func (_m *Repository) GetAnyByID(ctx context.Context, id int64) (*models.Product, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *models.Product
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *models.Product); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Product)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetByID provides a mock function with given fields: ctx, id
 func (_m *Repository) GetByID(ctx context.Context, id int64) (*models.Product, error) {
 	ret := _m.Called(ctx, id)
@@ -62,6 +109,131 @@ func (_m *Repository) GetByID(ctx context.Context, id int64) (*models.Product, e
 	return r0, r1
 }
 
+// GetPublishedByID provides a mock function with given fields: ctx, id
+func (_m *Repository) GetPublishedByID(ctx context.Context, id int64) (*models.Product, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *models.Product
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *models.Product); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Product)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTrash provides a mock function with given fields: ctx, offset, limit
+func (_m *Repository) GetTrash(ctx context.Context, offset int64, limit int64) ([]*models.Product, int64, error) {
+	ret := _m.Called(ctx, offset, limit)
+
+	var r0 []*models.Product
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) []*models.Product); ok {
+		r0 = rf(ctx, offset, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Product)
+		}
+	}
+
+	var r1 int64
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) int64); ok {
+		r1 = rf(ctx, offset, limit)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, int64, int64) error); ok {
+		r2 = rf(ctx, offset, limit)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// PublishDue provides a mock function with given fields: ctx, now
+func (_m *Repository) PublishDue(ctx context.Context, now time.Time) ([]int64, error) {
+	ret := _m.Called(ctx, now)
+
+	var r0 []int64
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) []int64); ok {
+		r0 = rf(ctx, now)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]int64)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Purge provides a mock function with given fields: ctx, before
+func (_m *Repository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	ret := _m.Called(ctx, before)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, before)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Restock provides a mock function with given fields: ctx, id, amount
+func (_m *Repository) Restock(ctx context.Context, id int64, amount int64) error {
+	ret := _m.Called(ctx, id, amount)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
+		r0 = rf(ctx, id, amount)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Restore provides a mock function with given fields: ctx, id
+func (_m *Repository) Restore(ctx context.Context, id int64) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Search provides a mock function with given fields: ctx, query, offset, limit
 func (_m *Repository) Search(ctx context.Context, query *string, offset int64, limit int64) ([]*models.Product, int64, error) {
 	ret := _m.Called(ctx, query, offset, limit)
@@ -92,6 +264,36 @@ func (_m *Repository) Search(ctx context.Context, query *string, offset int64, l
 	return r0, r1, r2
 }
 
+// SearchPublished provides a mock function with given fields: ctx, query, offset, limit
+func (_m *Repository) SearchPublished(ctx context.Context, query *string, offset int64, limit int64) ([]*models.Product, int64, error) {
+	ret := _m.Called(ctx, query, offset, limit)
+
+	var r0 []*models.Product
+	if rf, ok := ret.Get(0).(func(context.Context, *string, int64, int64) []*models.Product); ok {
+		r0 = rf(ctx, query, offset, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Product)
+		}
+	}
+
+	var r1 int64
+	if rf, ok := ret.Get(1).(func(context.Context, *string, int64, int64) int64); ok {
+		r1 = rf(ctx, query, offset, limit)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, *string, int64, int64) error); ok {
+		r2 = rf(ctx, query, offset, limit)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // Update provides a mock function with given fields: ctx, _a1
 func (_m *Repository) Update(ctx context.Context, _a1 *models.Product) error {
 	ret := _m.Called(ctx, _a1)
@@ -105,3 +307,17 @@ func (_m *Repository) Update(ctx context.Context, _a1 *models.Product) error {
 
 	return r0
 }
+
+// UpdateStatus provides a mock function with given fields: ctx, _a1
+func (_m *Repository) UpdateStatus(ctx context.Context, _a1 *models.Product) error {
+	ret := _m.Called(ctx, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Product) error); ok {
+		r0 = rf(ctx, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -3,6 +3,7 @@
 package mocks
 
 import context "context"
+import time "time"
 import mock "github.com/stretchr/testify/mock"
 import models "github.com/soerjadi/exam/models"
 
@@ -11,6 +12,27 @@ type Usecase struct {
 	mock.Mock
 }
 
+// ApplySchedule provides a mock function with given fields: ctx
+func (_m *Usecase) ApplySchedule(ctx context.Context) (int64, error) {
+	ret := _m.Called(ctx)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Compare provides a mock function with given fields: ctx, id1, id2
 func (_m *Usecase) Compare(ctx context.Context, id1 int64, id2 int64) ([]*models.Product, error) {
 	ret := _m.Called(ctx, id1, id2)
@@ -62,6 +84,29 @@ func (_m *Usecase) Delete(ctx context.Context, id int64) error {
 	return r0
 }
 
+// GetAnyByID provides a mock function with given fields: ctx, id
+func (_m *Usecase) GetAnyByID(ctx context.Context, id int64) (*models.Product, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *models.Product
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *models.Product); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Product)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetByID provides a mock function with given fields: ctx, id
 func (_m *Usecase) GetByID(ctx context.Context, id int64) (*models.Product, error) {
 	ret := _m.Called(ctx, id)
@@ -85,6 +130,78 @@ func (_m *Usecase) GetByID(ctx context.Context, id int64) (*models.Product, erro
 	return r0, r1
 }
 
+// GetPublishedByID provides a mock function with given fields: ctx, id
+func (_m *Usecase) GetPublishedByID(ctx context.Context, id int64) (*models.Product, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *models.Product
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *models.Product); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Product)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Purge provides a mock function with given fields: ctx, retention
+func (_m *Usecase) Purge(ctx context.Context, retention time.Duration) (int64, error) {
+	ret := _m.Called(ctx, retention)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, time.Duration) int64); ok {
+		r0 = rf(ctx, retention)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Duration) error); ok {
+		r1 = rf(ctx, retention)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Restock provides a mock function with given fields: ctx, id, amount
+func (_m *Usecase) Restock(ctx context.Context, id int64, amount int64) error {
+	ret := _m.Called(ctx, id, amount)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
+		r0 = rf(ctx, id, amount)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Restore provides a mock function with given fields: ctx, id
+func (_m *Usecase) Restore(ctx context.Context, id int64) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Search provides a mock function with given fields: ctx, query, offset, limit
 func (_m *Usecase) Search(ctx context.Context, query *string, offset int64, limit int64) ([]*models.Product, int64, error) {
 	ret := _m.Called(ctx, query, offset, limit)
@@ -115,6 +232,66 @@ func (_m *Usecase) Search(ctx context.Context, query *string, offset int64, limi
 	return r0, r1, r2
 }
 
+// SearchPublished provides a mock function with given fields: ctx, query, offset, limit
+func (_m *Usecase) SearchPublished(ctx context.Context, query *string, offset int64, limit int64) ([]*models.Product, int64, error) {
+	ret := _m.Called(ctx, query, offset, limit)
+
+	var r0 []*models.Product
+	if rf, ok := ret.Get(0).(func(context.Context, *string, int64, int64) []*models.Product); ok {
+		r0 = rf(ctx, query, offset, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Product)
+		}
+	}
+
+	var r1 int64
+	if rf, ok := ret.Get(1).(func(context.Context, *string, int64, int64) int64); ok {
+		r1 = rf(ctx, query, offset, limit)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, *string, int64, int64) error); ok {
+		r2 = rf(ctx, query, offset, limit)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Trash provides a mock function with given fields: ctx, offset, limit
+func (_m *Usecase) Trash(ctx context.Context, offset int64, limit int64) ([]*models.Product, int64, error) {
+	ret := _m.Called(ctx, offset, limit)
+
+	var r0 []*models.Product
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) []*models.Product); ok {
+		r0 = rf(ctx, offset, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Product)
+		}
+	}
+
+	var r1 int64
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) int64); ok {
+		r1 = rf(ctx, offset, limit)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, int64, int64) error); ok {
+		r2 = rf(ctx, offset, limit)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // Update provides a mock function with given fields: ctx, _a1
 func (_m *Usecase) Update(ctx context.Context, _a1 *models.Product) error {
 	ret := _m.Called(ctx, _a1)
@@ -128,3 +305,17 @@ func (_m *Usecase) Update(ctx context.Context, _a1 *models.Product) error {
 
 	return r0
 }
+
+// UpdateStatus provides a mock function with given fields: ctx, _a1
+func (_m *Usecase) UpdateStatus(ctx context.Context, _a1 *models.Product) error {
+	ret := _m.Called(ctx, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Product) error); ok {
+		r0 = rf(ctx, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
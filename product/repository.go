@@ -2,6 +2,7 @@ package product
 
 import (
 	"context"
+	"time"
 
 	"github.com/soerjadi/exam/models"
 )
@@ -9,8 +10,18 @@ import (
 // Repository represent the product's repository contract
 type Repository interface {
 	GetByID(ctx context.Context, id int64) (product *models.Product, err error)
+	GetPublishedByID(ctx context.Context, id int64) (*models.Product, error)
+	GetAnyByID(ctx context.Context, id int64) (*models.Product, error)
 	Create(ctx context.Context, product *models.Product) error
 	Update(ctx context.Context, product *models.Product) error
+	UpdateStatus(ctx context.Context, product *models.Product) error
+	Restock(ctx context.Context, id int64, amount int64) error
+	PublishDue(ctx context.Context, now time.Time) ([]int64, error)
+	ArchiveDue(ctx context.Context, now time.Time) ([]int64, error)
 	Delete(ctx context.Context, id int64) error
+	GetTrash(ctx context.Context, offset int64, limit int64) ([]*models.Product, int64, error)
+	Restore(ctx context.Context, id int64) error
+	Purge(ctx context.Context, before time.Time) (int64, error)
 	Search(ctx context.Context, query *string, offset int64, limit int64) ([]*models.Product, int64, error)
+	SearchPublished(ctx context.Context, query *string, offset int64, limit int64) ([]*models.Product, int64, error)
 }
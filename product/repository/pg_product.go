@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/soerjadi/exam/database"
 	"github.com/soerjadi/exam/models"
 	"github.com/soerjadi/exam/product"
 	"github.com/soerjadi/exam/utils"
@@ -24,7 +25,7 @@ func NewPGProductRepository(Conn *sql.DB) product.Repository {
 }
 
 func (p *pgProductRepository) fetch(ctx context.Context, query string, args ...interface{}) ([]*models.Product, error) {
-	rows, err := p.Conn.QueryContext(ctx, query, args...)
+	rows, err := database.ExecutorFrom(ctx, p.Conn).QueryContext(ctx, query, args...)
 	if err != nil {
 		logger.Error(err)
 		return nil, err
@@ -45,8 +46,19 @@ func (p *pgProductRepository) fetch(ctx context.Context, query string, args ...i
 			&t.ID,
 			&t.Name,
 			&t.SKU,
+			&t.Status,
+			&t.PublishAt,
+			&t.UnpublishAt,
 			&t.Created,
 			&t.Updated,
+			&t.DeletedAt,
+			&t.Version,
+			&t.Weight,
+			&t.Length,
+			&t.Width,
+			&t.Height,
+			&t.TaxClassID,
+			&t.Stock,
 		)
 
 		if err != nil {
@@ -97,7 +109,7 @@ func (p *pgProductRepository) fetchRow(ctx context.Context, query string, args .
 }
 
 func (p *pgProductRepository) GetByID(ctx context.Context, id int64) (product *models.Product, err error) {
-	query := `SELECT id, name, sku, created, updated FROM products WHERE id = ?`
+	query := `SELECT id, name, sku, status, publish_at, unpublish_at, created, updated, deleted_at, version, weight, length, width, height, tax_class_id, stock FROM products WHERE id = ? AND deleted_at IS NULL`
 
 	products, err := p.fetch(ctx, query, id)
 	if err != nil {
@@ -113,14 +125,47 @@ func (p *pgProductRepository) GetByID(ctx context.Context, id int64) (product *m
 	return
 }
 
+// GetAnyByID return the product of id, even when it is in the trash
+func (p *pgProductRepository) GetAnyByID(ctx context.Context, id int64) (*models.Product, error) {
+	query := `SELECT id, name, sku, status, publish_at, unpublish_at, created, updated, deleted_at, version, weight, length, width, height, tax_class_id, stock FROM products WHERE id = ?`
+
+	products, err := p.fetch(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(products) == 0 {
+		return nil, models.ErrNotFound
+	}
+
+	return products[0], nil
+}
+
+func (p *pgProductRepository) GetPublishedByID(ctx context.Context, id int64) (*models.Product, error) {
+	query := `SELECT id, name, sku, status, publish_at, unpublish_at, created, updated, deleted_at, version, weight, length, width, height, tax_class_id, stock FROM products WHERE id = ? AND status = ? AND deleted_at IS NULL`
+
+	products, err := p.fetch(ctx, query, id, models.ProductPublished)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(products) == 0 {
+		return nil, models.ErrNotFound
+	}
+
+	return products[0], nil
+}
+
 func (p *pgProductRepository) Create(ctx context.Context, product *models.Product) error {
-	query := `INSERT INTO products(name, sku) VALUES(?, ?) returning id`
-	stmt, err := p.Conn.PrepareContext(ctx, query)
+	query := `INSERT INTO products(name, sku, status, publish_at, unpublish_at, weight, length, width, height, tax_class_id, stock)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) returning id`
+	stmt, err := database.ExecutorFrom(ctx, p.Conn).PrepareContext(ctx, query)
 	if err != nil {
 		return err
 	}
 
-	result, err := stmt.ExecContext(ctx, product.Name, product.SKU)
+	result, err := stmt.ExecContext(ctx, product.Name, product.SKU, product.Status, product.PublishAt, product.UnpublishAt,
+		product.Weight, product.Length, product.Width, product.Height, product.TaxClassID, product.Stock)
 	if err != nil {
 		return err
 	}
@@ -131,18 +176,21 @@ func (p *pgProductRepository) Create(ctx context.Context, product *models.Produc
 	}
 
 	product.ID = lastID
+	product.Version = 1
 	return nil
 }
 
 func (p *pgProductRepository) Update(ctx context.Context, product *models.Product) error {
-	query := "UPDATE products SET name = ?, sku = ?, updated = ? WHERE id = ?"
+	query := `UPDATE products SET name = ?, sku = ?, weight = ?, length = ?, width = ?, height = ?, tax_class_id = ?, updated = ?,
+		version = version + 1 WHERE id = ? AND version = ? AND deleted_at IS NULL`
 
-	stmt, err := p.Conn.PrepareContext(ctx, query)
+	stmt, err := database.ExecutorFrom(ctx, p.Conn).PrepareContext(ctx, query)
 	if err != nil {
-		return nil
+		return err
 	}
 
-	res, err := stmt.ExecContext(ctx, product.Name, product.SKU, time.Now(), product.ID)
+	res, err := stmt.ExecContext(ctx, product.Name, product.SKU, product.Weight, product.Length, product.Width, product.Height,
+		product.TaxClassID, time.Now(), product.ID, product.Version)
 	if err != nil {
 		return err
 	}
@@ -152,23 +200,119 @@ func (p *pgProductRepository) Update(ctx context.Context, product *models.Produc
 		return err
 	}
 
+	// the row is either gone or someone else already bumped its version
 	if affected != 1 {
-		return fmt.Errorf("Total affected: %d", affected)
+		return models.ErrConflict
 	}
 
+	product.Version++
 	return nil
+}
+
+// Restock add amount pieces to the stock of the product, it join the
+// transaction carried by ctx when there is one
+func (p *pgProductRepository) Restock(ctx context.Context, id int64, amount int64) error {
+	query := `UPDATE products SET stock = stock + ?, version = version + 1 WHERE id = ?`
+
+	stmt, err := database.ExecutorFrom(ctx, p.Conn).PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	res, err := stmt.ExecContext(ctx, amount, id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
 
+	if affected != 1 {
+		return models.ErrNotFound
+	}
+
+	return nil
+}
+
+func (p *pgProductRepository) UpdateStatus(ctx context.Context, product *models.Product) error {
+	query := "UPDATE products SET status = ?, publish_at = ?, unpublish_at = ?, updated = ?, version = version + 1 WHERE id = ? AND version = ? AND deleted_at IS NULL"
+
+	stmt, err := database.ExecutorFrom(ctx, p.Conn).PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	res, err := stmt.ExecContext(ctx, product.Status, product.PublishAt, product.UnpublishAt, time.Now(), product.ID, product.Version)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected != 1 {
+		return models.ErrConflict
+	}
+
+	product.Version++
+	return nil
+}
+
+func (p *pgProductRepository) PublishDue(ctx context.Context, now time.Time) ([]int64, error) {
+	query := `UPDATE products SET status = ?, publish_at = NULL, updated = ?, version = version + 1
+		WHERE status = ? AND publish_at IS NOT NULL AND publish_at <= ? AND deleted_at IS NULL returning id`
+
+	return p.fetchIDs(ctx, query, models.ProductPublished, now, models.ProductDraft, now)
+}
+
+func (p *pgProductRepository) ArchiveDue(ctx context.Context, now time.Time) ([]int64, error) {
+	query := `UPDATE products SET status = ?, unpublish_at = NULL, updated = ?, version = version + 1
+		WHERE status = ? AND unpublish_at IS NOT NULL AND unpublish_at <= ? AND deleted_at IS NULL returning id`
+
+	return p.fetchIDs(ctx, query, models.ProductArchived, now, models.ProductPublished, now)
+}
+
+func (p *pgProductRepository) fetchIDs(ctx context.Context, query string, args ...interface{}) ([]int64, error) {
+	rows, err := database.ExecutorFrom(ctx, p.Conn).QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Error(err)
+		}
+	}()
+
+	ids := make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
 }
 
 func (p *pgProductRepository) Delete(ctx context.Context, id int64) error {
-	query := "DELETE FROM products WHERE id = ?"
+	query := "UPDATE products SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL"
 
-	stmt, err := p.Conn.PrepareContext(ctx, query)
+	stmt, err := database.ExecutorFrom(ctx, p.Conn).PrepareContext(ctx, query)
 	if err != nil {
 		return err
 	}
 
-	res, err := stmt.ExecContext(ctx, id)
+	res, err := stmt.ExecContext(ctx, time.Now(), id)
 	if err != nil {
 		return err
 	}
@@ -186,15 +330,121 @@ func (p *pgProductRepository) Delete(ctx context.Context, id int64) error {
 	return nil
 }
 
+func (p *pgProductRepository) GetTrash(ctx context.Context, offset int64, limit int64) ([]*models.Product, int64, error) {
+	query := `SELECT id, name, sku, status, publish_at, unpublish_at, created, updated, deleted_at, version, weight, length, width, height, tax_class_id, stock FROM products WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC LIMIT ? OFFSET ?`
+	qCount := `SELECT count(id) FROM products WHERE deleted_at IS NOT NULL`
+
+	result, err := p.fetch(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := p.fetchRow(ctx, qCount)
+	if err != nil {
+		logger.Error(err)
+		return nil, 0, err
+	}
+
+	var count int64
+	err = rows.Scan(&count)
+
+	if err != nil {
+		logger.Error(err)
+		return nil, 0, err
+	}
+
+	return result, count, nil
+}
+
+func (p *pgProductRepository) Restore(ctx context.Context, id int64) error {
+	query := "UPDATE products SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL"
+
+	stmt, err := database.ExecutorFrom(ctx, p.Conn).PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	res, err := stmt.ExecContext(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected != 1 {
+		return models.ErrNotFound
+	}
+
+	return nil
+}
+
+// purgeable matches products that stay in the trash longer than the
+// retention period and are not referenced by any order, cart, shipment or
+// return line.
+const purgeable = `deleted_at IS NOT NULL AND deleted_at < ?
+	AND NOT EXISTS (SELECT 1 FROM orders o WHERE o.product_id = products.id)
+	AND NOT EXISTS (SELECT 1 FROM order_item oi WHERE oi.product_id = products.id)
+	AND NOT EXISTS (SELECT 1 FROM cart_item ci WHERE ci.product_id = products.id)
+	AND NOT EXISTS (SELECT 1 FROM shipment_item si WHERE si.product_id = products.id)
+	AND NOT EXISTS (SELECT 1 FROM return_item ri WHERE ri.product_id = products.id)`
+
+func (p *pgProductRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	tx, err := p.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	queries := []string{
+		"DELETE FROM product_category WHERE product_id IN (SELECT id FROM products WHERE " + purgeable + ")",
+		"DELETE FROM product_price WHERE product_id IN (SELECT id FROM products WHERE " + purgeable + ")",
+		"DELETE FROM product_related WHERE product_id IN (SELECT id FROM products WHERE " + purgeable + ")",
+		"DELETE FROM product_related WHERE related_id IN (SELECT id FROM products WHERE " + purgeable + ")",
+	}
+
+	for _, query := range queries {
+		if _, err = tx.ExecContext(ctx, query, before); err != nil {
+			logger.Error(err)
+			_ = tx.Rollback()
+			return 0, err
+		}
+	}
+
+	res, err := tx.ExecContext(ctx, "DELETE FROM products WHERE "+purgeable, before)
+	if err != nil {
+		logger.Error(err)
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	purged, err := res.RowsAffected()
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	return purged, tx.Commit()
+}
+
 func (p *pgProductRepository) Search(ctx context.Context, query *string, offset int64, limit int64) ([]*models.Product, int64, error) {
+	return p.search(ctx, "", query, offset, limit)
+}
+
+func (p *pgProductRepository) SearchPublished(ctx context.Context, query *string, offset int64, limit int64) ([]*models.Product, int64, error) {
+	return p.search(ctx, fmt.Sprintf(" AND status = %d", models.ProductPublished), query, offset, limit)
+}
+
+func (p *pgProductRepository) search(ctx context.Context, filter string, query *string, offset int64, limit int64) ([]*models.Product, int64, error) {
 	var searchQuery string
 
 	if query != nil && len(*query) > 0 {
-		searchQuery = "WHERE LOWER(name) LIKE '%?%' OR LOWER(sku) LIKE '%?%'"
+		searchQuery = " AND (LOWER(name) LIKE '%?%' OR LOWER(sku) LIKE '%?%')"
 	}
 
-	q := fmt.Sprintf("SELECT id, name, sku, created, updated FROM products %s ORDER BY created LIMIT ? OFFSET ?", searchQuery)
-	qCount := fmt.Sprintf("SELECT count(id) FROM products %s", searchQuery)
+	q := fmt.Sprintf("SELECT id, name, sku, status, publish_at, unpublish_at, created, updated, deleted_at, version, weight, length, width, height, tax_class_id, stock FROM products WHERE deleted_at IS NULL%s%s ORDER BY created LIMIT ? OFFSET ?", filter, searchQuery)
+	qCount := fmt.Sprintf("SELECT count(id) FROM products WHERE deleted_at IS NULL%s%s", filter, searchQuery)
 
 	result, err := p.fetch(ctx, q, strings.ToLower(*query), strings.ToLower(*query), limit, offset)
 
@@ -10,6 +10,7 @@ import (
 	"github.com/soerjadi/exam/models"
 	"github.com/soerjadi/exam/product/repository"
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v3"
 )
 
 func TestSearch(t *testing.T) {
@@ -34,15 +35,15 @@ func TestSearch(t *testing.T) {
 	}
 
 	found := int64(2)
-	rows := sqlmock.NewRows([]string{"id", "name", "sku", "created", "updated"}).
-		AddRow(mockProduct[0].ID, mockProduct[0].Name, mockProduct[0].SKU, mockProduct[0].Created, mockProduct[0].Updated).
-		AddRow(mockProduct[1].ID, mockProduct[1].Name, mockProduct[1].SKU, mockProduct[1].Created, mockProduct[1].Updated)
+	rows := sqlmock.NewRows([]string{"id", "name", "sku", "status", "publish_at", "unpublish_at", "created", "updated", "deleted_at", "version", "weight", "length", "width", "height", "tax_class_id", "stock"}).
+		AddRow(mockProduct[0].ID, mockProduct[0].Name, mockProduct[0].SKU, mockProduct[0].Status, mockProduct[0].PublishAt, mockProduct[0].UnpublishAt, mockProduct[0].Created, mockProduct[0].Updated, mockProduct[0].DeletedAt, mockProduct[0].Version, 0.5, 20.0, 10.0, 5.0, nil, 0).
+		AddRow(mockProduct[1].ID, mockProduct[1].Name, mockProduct[1].SKU, mockProduct[1].Status, mockProduct[1].PublishAt, mockProduct[1].UnpublishAt, mockProduct[1].Created, mockProduct[1].Updated, mockProduct[1].DeletedAt, mockProduct[1].Version, 0.0, 0.0, 0.0, 0.0, nil, 0)
 
 	rowCount := sqlmock.NewRows([]string{"count"}).
 		AddRow(found)
 
-	query := "SELECT id, name, sku, created, updated FROM products WHERE LOWER\\(name\\) LIKE '\\%\\?\\%' OR LOWER\\(sku\\) LIKE '\\%\\?\\%' ORDER BY created LIMIT \\? OFFSET \\?"
-	countQuery := "SELECT count\\(id\\) FROM products WHERE LOWER\\(name\\) LIKE '\\%\\?\\%' OR LOWER\\(sku\\) LIKE '\\%\\?\\%'"
+	query := "SELECT id, name, sku, status, publish_at, unpublish_at, created, updated, deleted_at, version, weight, length, width, height, tax_class_id, stock FROM products WHERE deleted_at IS NULL AND \\(LOWER\\(name\\) LIKE '\\%\\?\\%' OR LOWER\\(sku\\) LIKE '\\%\\?\\%'\\) ORDER BY created LIMIT \\? OFFSET \\?"
+	countQuery := "SELECT count\\(id\\) FROM products WHERE deleted_at IS NULL AND \\(LOWER\\(name\\) LIKE '\\%\\?\\%' OR LOWER\\(sku\\) LIKE '\\%\\?\\%'\\)"
 	searchQuery := strings.ToLower("product")
 
 	mock.ExpectQuery(query).WithArgs(searchQuery, searchQuery, 10, 0).WillReturnRows(rows)
@@ -62,10 +63,10 @@ func TestGetByID(t *testing.T) {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
 
-	rows := sqlmock.NewRows([]string{"id", "name", "sku", "created", "updated"}).
-		AddRow(1, "product 1", "sku", time.Now(), time.Now())
+	rows := sqlmock.NewRows([]string{"id", "name", "sku", "status", "publish_at", "unpublish_at", "created", "updated", "deleted_at", "version", "weight", "length", "width", "height", "tax_class_id", "stock"}).
+		AddRow(1, "product 1", "sku", models.ProductPublished, nil, nil, time.Now(), time.Now(), nil, 1, 0.5, 20.0, 10.0, 5.0, nil, 12)
 
-	query := "SELECT id, name, sku, created, updated FROM products WHERE id = \\?"
+	query := "SELECT id, name, sku, status, publish_at, unpublish_at, created, updated, deleted_at, version, weight, length, width, height, tax_class_id, stock FROM products WHERE id = \\? AND deleted_at IS NULL"
 
 	mock.ExpectQuery(query).WithArgs(int64(1)).WillReturnRows(rows)
 	p := repository.NewPGProductRepository(db)
@@ -74,6 +75,7 @@ func TestGetByID(t *testing.T) {
 
 	assert.NoError(t, err)
 	assert.NotNil(t, product)
+	assert.Equal(t, int64(12), product.Stock)
 }
 
 func TestCreate(t *testing.T) {
@@ -83,13 +85,15 @@ func TestCreate(t *testing.T) {
 	}
 
 	product := &models.Product{
-		Name: "product",
-		SKU:  "sku",
+		Name:   "product",
+		SKU:    "sku",
+		Weight: 0.5,
 	}
 
-	query := "INSERT INTO products\\(name, sku\\) VALUES\\(\\?, \\?\\) returning id"
+	query := "INSERT INTO products\\(name, sku, status, publish_at, unpublish_at, weight, length, width, height, tax_class_id, stock\\) VALUES\\((.+)\\) returning id"
 	prep := mock.ExpectPrepare(query)
-	prep.ExpectExec().WithArgs(product.Name, product.SKU).WillReturnResult(sqlmock.NewResult(2, 1))
+	prep.ExpectExec().WithArgs(product.Name, product.SKU, product.Status, product.PublishAt, product.UnpublishAt,
+		product.Weight, product.Length, product.Width, product.Height, product.TaxClassID, product.Stock).WillReturnResult(sqlmock.NewResult(2, 1))
 
 	p := repository.NewPGProductRepository(db)
 
@@ -105,37 +109,285 @@ func TestUpdate(t *testing.T) {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
 
-	product := &models.Product{
-		ID:      2,
-		Name:    "product",
-		SKU:     "sku",
-		Created: time.Now(),
+	query := "UPDATE products SET name = \\?, sku = \\?, weight = \\?, length = \\?, width = \\?, height = \\?, tax_class_id = \\?, updated = \\?,\\s+version = version \\+ 1 WHERE id = \\? AND version = \\? AND deleted_at IS NULL"
+
+	t.Run("success", func(t *testing.T) {
+		product := &models.Product{
+			ID:      2,
+			Name:    "product",
+			SKU:     "sku",
+			Created: time.Now(),
+			Version: 3,
+		}
+
+		prep := mock.ExpectPrepare(query)
+		prep.ExpectExec().WithArgs(product.Name, product.SKU, 0.0, 0.0, 0.0, 0.0, product.TaxClassID, sqlmock.AnyArg(), product.ID, int64(3)).WillReturnResult(sqlmock.NewResult(2, 1))
+
+		p := repository.NewPGProductRepository(db)
+
+		err = p.Update(context.TODO(), product)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(4), product.Version)
+	})
+
+	t.Run("stale version", func(t *testing.T) {
+		product := &models.Product{
+			ID:      2,
+			Name:    "product",
+			SKU:     "sku",
+			Version: 2,
+		}
+
+		prep := mock.ExpectPrepare(query)
+		prep.ExpectExec().WithArgs(product.Name, product.SKU, 0.0, 0.0, 0.0, 0.0, product.TaxClassID, sqlmock.AnyArg(), product.ID, int64(2)).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		p := repository.NewPGProductRepository(db)
+
+		err = p.Update(context.TODO(), product)
+		assert.Equal(t, models.ErrConflict, err)
+		assert.Equal(t, int64(2), product.Version)
+	})
+}
+
+func TestDelete(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
 
-	query := "UPDATE product SET name = \\?, sku = \\? WHERE id = \\?"
+	query := "UPDATE products SET deleted_at = \\? WHERE id = \\? AND deleted_at IS NULL"
 
 	prep := mock.ExpectPrepare(query)
-	prep.ExpectExec().WithArgs(product.Name, product.SKU, product.ID).WillReturnResult(sqlmock.NewResult(2, 1))
+	prep.ExpectExec().WithArgs(sqlmock.AnyArg(), 2).WillReturnResult(sqlmock.NewResult(2, 1))
 
 	p := repository.NewPGProductRepository(db)
 
-	err = p.Update(context.TODO(), product)
+	err = p.Delete(context.TODO(), int64(2))
 	assert.NoError(t, err)
 }
 
-func TestDelete(t *testing.T) {
+func TestGetTrash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	deletedAt := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "name", "sku", "status", "publish_at", "unpublish_at", "created", "updated", "deleted_at", "version", "weight", "length", "width", "height", "tax_class_id", "stock"}).
+		AddRow(1, "product 1", "sku 1", models.ProductDraft, nil, nil, time.Now(), nil, deletedAt, 1, 0.0, 0.0, 0.0, 0.0, nil, 0)
+
+	rowCount := sqlmock.NewRows([]string{"count"}).
+		AddRow(1)
+
+	query := "SELECT id, name, sku, status, publish_at, unpublish_at, created, updated, deleted_at, version, weight, length, width, height, tax_class_id, stock FROM products WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC LIMIT \\? OFFSET \\?"
+	countQuery := "SELECT count\\(id\\) FROM products WHERE deleted_at IS NOT NULL"
+
+	mock.ExpectQuery(query).WithArgs(10, 0).WillReturnRows(rows)
+	mock.ExpectPrepare(countQuery).ExpectQuery().WillReturnRows(rowCount)
+
+	p := repository.NewPGProductRepository(db)
+	result, count, err := p.GetTrash(context.TODO(), int64(0), int64(10))
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	assert.Len(t, result, 1)
+	assert.True(t, result[0].DeletedAt.Valid)
+}
+
+func TestRestore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	query := "UPDATE products SET deleted_at = NULL WHERE id = \\? AND deleted_at IS NOT NULL"
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectPrepare(query).ExpectExec().WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		p := repository.NewPGProductRepository(db)
+
+		err = p.Restore(context.TODO(), int64(2))
+		assert.NoError(t, err)
+	})
+
+	t.Run("not in trash", func(t *testing.T) {
+		mock.ExpectPrepare(query).ExpectExec().WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		p := repository.NewPGProductRepository(db)
+
+		err = p.Restore(context.TODO(), int64(3))
+		assert.Equal(t, models.ErrNotFound, err)
+	})
+}
+
+func TestPurge(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	before := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM product_category WHERE product_id IN").WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("DELETE FROM product_price WHERE product_id IN").WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM product_related WHERE product_id IN").WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM product_related WHERE related_id IN").WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 0))
+	// products still on the lines of orders, carts, shipments or returns are kept
+	mock.ExpectExec("DELETE FROM products WHERE deleted_at IS NOT NULL AND deleted_at < \\?(?s).+order_item oi WHERE oi.product_id = products.id.+cart_item.+shipment_item.+return_item").
+		WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	p := repository.NewPGProductRepository(db)
+
+	purged, err := p.Purge(context.TODO(), before)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAnyByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "name", "sku", "status", "publish_at", "unpublish_at", "created", "updated", "deleted_at", "version", "weight", "length", "width", "height", "tax_class_id", "stock"}).
+		AddRow(4, "product 4", "sku4", models.ProductArchived, nil, nil, time.Now(), nil, time.Now(), 2, 0.0, 0.0, 0.0, 0.0, nil, 0)
+
+	query := "SELECT id, name, sku, status, publish_at, unpublish_at, created, updated, deleted_at, version, weight, length, width, height, tax_class_id, stock FROM products WHERE id = \\?$"
+
+	mock.ExpectQuery(query).WithArgs(int64(4)).WillReturnRows(rows)
+	p := repository.NewPGProductRepository(db)
+
+	// products in the trash are found too
+	product, err := p.GetAnyByID(context.TODO(), int64(4))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "product 4", product.Name)
+	assert.True(t, product.DeletedAt.Valid)
+}
+
+func TestGetPublishedByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	query := "SELECT id, name, sku, status, publish_at, unpublish_at, created, updated, deleted_at, version, weight, length, width, height, tax_class_id, stock FROM products WHERE id = \\? AND status = \\? AND deleted_at IS NULL"
+
+	t.Run("success", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "name", "sku", "status", "publish_at", "unpublish_at", "created", "updated", "deleted_at", "version", "weight", "length", "width", "height", "tax_class_id", "stock"}).
+			AddRow(1, "product 1", "sku", models.ProductPublished, nil, nil, time.Now(), nil, nil, 1, 0.0, 0.0, 0.0, 0.0, nil, 0)
+
+		mock.ExpectQuery(query).WithArgs(int64(1), models.ProductPublished).WillReturnRows(rows)
+		p := repository.NewPGProductRepository(db)
+
+		product, err := p.GetPublishedByID(context.TODO(), int64(1))
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.ProductPublished, product.Status)
+	})
+
+	t.Run("draft", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "name", "sku", "status", "publish_at", "unpublish_at", "created", "updated", "deleted_at", "version", "weight", "length", "width", "height", "tax_class_id", "stock"})
+
+		mock.ExpectQuery(query).WithArgs(int64(2), models.ProductPublished).WillReturnRows(rows)
+		p := repository.NewPGProductRepository(db)
+
+		product, err := p.GetPublishedByID(context.TODO(), int64(2))
+
+		assert.Equal(t, models.ErrNotFound, err)
+		assert.Nil(t, product)
+	})
+}
+
+func TestSearchPublished(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "name", "sku", "status", "publish_at", "unpublish_at", "created", "updated", "deleted_at", "version", "weight", "length", "width", "height", "tax_class_id", "stock"}).
+		AddRow(1, "product 1", "sku 1", models.ProductPublished, nil, nil, time.Now(), nil, nil, 1, 0.0, 0.0, 0.0, 0.0, nil, 0)
+
+	rowCount := sqlmock.NewRows([]string{"count"}).
+		AddRow(1)
+
+	query := "SELECT (.+) FROM products WHERE deleted_at IS NULL AND status = 1 AND \\(LOWER\\(name\\) LIKE"
+	countQuery := "SELECT count\\(id\\) FROM products WHERE deleted_at IS NULL AND status = 1 AND \\(LOWER\\(name\\) LIKE"
+	searchQuery := "product"
+
+	mock.ExpectQuery(query).WithArgs(searchQuery, searchQuery, 10, 0).WillReturnRows(rows)
+	mock.ExpectPrepare(countQuery).ExpectQuery().WithArgs(searchQuery, searchQuery).WillReturnRows(rowCount)
+
+	p := repository.NewPGProductRepository(db)
+	result, count, err := p.SearchPublished(context.TODO(), &searchQuery, int64(0), int64(10))
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	assert.Len(t, result, 1)
+}
+
+func TestUpdateStatus(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
 
-	query := "DELETE FROM products WHERE id = \\?"
+	query := "UPDATE products SET status = \\?, publish_at = \\?, unpublish_at = \\?, updated = \\?, version = version \\+ 1 WHERE id = \\? AND version = \\? AND deleted_at IS NULL"
+
+	product := &models.Product{
+		ID:        2,
+		Status:    models.ProductDraft,
+		PublishAt: null.NewTime(time.Now().Add(time.Hour), true),
+		Version:   1,
+	}
 
 	prep := mock.ExpectPrepare(query)
-	prep.ExpectExec().WithArgs(2).WillReturnResult(sqlmock.NewResult(2, 1))
+	prep.ExpectExec().WithArgs(product.Status, product.PublishAt, product.UnpublishAt, sqlmock.AnyArg(), product.ID, int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
 
 	p := repository.NewPGProductRepository(db)
 
-	err = p.Delete(context.TODO(), int64(2))
+	err = p.UpdateStatus(context.TODO(), product)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), product.Version)
+}
+
+func TestPublishDue(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"id"}).
+		AddRow(3).
+		AddRow(5)
+
+	query := "UPDATE products SET status = \\?, publish_at = NULL, (.+) WHERE status = \\? AND publish_at IS NOT NULL AND publish_at <= \\?"
+	mock.ExpectQuery(query).WithArgs(models.ProductPublished, now, models.ProductDraft, now).WillReturnRows(rows)
+
+	p := repository.NewPGProductRepository(db)
+
+	ids, err := p.PublishDue(context.TODO(), now)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3, 5}, ids)
+}
+
+func TestRestock(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	query := "UPDATE products SET stock = stock \\+ \\?, version = version \\+ 1 WHERE id = \\?"
+	mock.ExpectPrepare(query).ExpectExec().WithArgs(int64(2), int64(9)).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	p := repository.NewPGProductRepository(db)
+
+	err = p.Restock(context.TODO(), int64(9), int64(2))
 	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -2,6 +2,7 @@ package product
 
 import (
 	"context"
+	"time"
 
 	"github.com/soerjadi/exam/models"
 )
@@ -9,9 +10,18 @@ import (
 // Usecase represent the product's usecase
 type Usecase interface {
 	Search(ctx context.Context, query *string, offset int64, limit int64) ([]*models.Product, int64, error)
+	SearchPublished(ctx context.Context, query *string, offset int64, limit int64) ([]*models.Product, int64, error)
 	GetByID(ctx context.Context, id int64) (*models.Product, error)
+	GetPublishedByID(ctx context.Context, id int64) (*models.Product, error)
+	GetAnyByID(ctx context.Context, id int64) (*models.Product, error)
 	Create(ctx context.Context, product *models.Product) error
 	Update(ctx context.Context, product *models.Product) error
+	UpdateStatus(ctx context.Context, product *models.Product) error
+	Restock(ctx context.Context, id int64, amount int64) error
+	ApplySchedule(ctx context.Context) (int64, error)
 	Delete(ctx context.Context, id int64) error
+	Trash(ctx context.Context, offset int64, limit int64) ([]*models.Product, int64, error)
+	Restore(ctx context.Context, id int64) error
+	Purge(ctx context.Context, retention time.Duration) (int64, error)
 	Compare(ctx context.Context, id1 int64, id2 int64) ([]*models.Product, error)
 }
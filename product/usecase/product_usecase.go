@@ -4,24 +4,61 @@ import (
 	"context"
 	"time"
 
+	"github.com/soerjadi/exam/audit"
+	"github.com/soerjadi/exam/database"
+	"github.com/soerjadi/exam/event"
 	"github.com/soerjadi/exam/models"
 	"github.com/soerjadi/exam/product"
+	"github.com/soerjadi/exam/utils"
 	"gopkg.in/guregu/null.v3"
 )
 
 type productUsecase struct {
 	repo           product.Repository
+	tx             database.Transactor
+	events         event.Usecase
+	audit          audit.Usecase
 	contextTimeout time.Duration
 }
 
+var logger = utils.LogBuilder(true)
+
 // NewProductUsecase will create object that represent of product.Usecase interface
-func NewProductUsecase(p product.Repository, timeout time.Duration) product.Usecase {
+func NewProductUsecase(p product.Repository, tx database.Transactor, e event.Usecase, a audit.Usecase, timeout time.Duration) product.Usecase {
 	return &productUsecase{
 		repo:           p,
+		tx:             tx,
+		events:         e,
+		audit:          a,
 		contextTimeout: timeout,
 	}
 }
 
+// record write the change to the audit log, failing to do so must not
+// fail the change itself as it is already persisted.
+func (p *productUsecase) record(ctx context.Context, id int64, action string, before interface{}, after interface{}) {
+	if err := p.audit.Record(ctx, models.AuditProduct, id, action, before, after); err != nil {
+		logger.Error(err)
+	}
+}
+
+// emitUpdated publish the products ids as they are now, inside the
+// transaction of ctx which changed them
+func (p *productUsecase) emitUpdated(ctx context.Context, ids []int64) error {
+	for _, id := range ids {
+		product, err := p.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if err = p.events.Emit(ctx, models.EventProductUpdated, id, product); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (p *productUsecase) Search(ctx context.Context, query *string, offset int64, limit int64) ([]*models.Product, int64, error) {
 	ctx, cancel := context.WithTimeout(ctx, p.contextTimeout)
 	defer cancel()
@@ -34,6 +71,18 @@ func (p *productUsecase) Search(ctx context.Context, query *string, offset int64
 	return products, found, nil
 }
 
+func (p *productUsecase) SearchPublished(ctx context.Context, query *string, offset int64, limit int64) ([]*models.Product, int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.contextTimeout)
+	defer cancel()
+
+	products, found, err := p.repo.SearchPublished(ctx, query, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return products, found, nil
+}
+
 func (p *productUsecase) GetByID(ctx context.Context, id int64) (*models.Product, error) {
 	ctx, cancel := context.WithTimeout(ctx, p.contextTimeout)
 	defer cancel()
@@ -47,15 +96,71 @@ func (p *productUsecase) GetByID(ctx context.Context, id int64) (*models.Product
 	return product, nil
 }
 
+func (p *productUsecase) GetPublishedByID(ctx context.Context, id int64) (*models.Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.contextTimeout)
+	defer cancel()
+
+	return p.repo.GetPublishedByID(ctx, id)
+}
+
+// GetAnyByID return the product of id, even when it is in the trash, for
+// records like invoices naming what was sold
+func (p *productUsecase) GetAnyByID(ctx context.Context, id int64) (*models.Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.contextTimeout)
+	defer cancel()
+
+	return p.repo.GetAnyByID(ctx, id)
+}
+
+// validateLifecycle make sure product carry a known status and its
+// publishing window, when both ends are set, is not empty.
+func validateLifecycle(product *models.Product) error {
+	switch product.Status {
+	case models.ProductDraft, models.ProductPublished, models.ProductArchived:
+	default:
+		return models.ErrBadParamInput
+	}
+
+	if product.PublishAt.Valid && product.UnpublishAt.Valid && !product.PublishAt.Time.Before(product.UnpublishAt.Time) {
+		return models.ErrBadParamInput
+	}
+
+	return nil
+}
+
+// validatePackage reject negative weight or dimensions, and negative stock
+func validatePackage(product *models.Product) error {
+	if product.Weight < 0 || product.Length < 0 || product.Width < 0 || product.Height < 0 || product.Stock < 0 {
+		return models.ErrBadParamInput
+	}
+
+	return nil
+}
+
 func (p *productUsecase) Create(ctx context.Context, product *models.Product) error {
 	ctx, cancel := context.WithTimeout(ctx, p.contextTimeout)
 	defer cancel()
 
-	err := p.repo.Create(ctx, product)
+	if err := validateLifecycle(product); err != nil {
+		return err
+	}
+
+	if err := validatePackage(product); err != nil {
+		return err
+	}
+
+	err := p.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := p.repo.Create(ctx, product); err != nil {
+			return err
+		}
+
+		return p.events.Emit(ctx, models.EventProductCreated, product.ID, product)
+	})
 	if err != nil {
 		return err
 	}
 
+	p.record(ctx, product.ID, models.AuditCreate, nil, product)
 	return nil
 }
 
@@ -63,11 +168,154 @@ func (p *productUsecase) Update(ctx context.Context, product *models.Product) er
 	ctx, cancel := context.WithTimeout(ctx, p.contextTimeout)
 	defer cancel()
 
+	if err := validatePackage(product); err != nil {
+		return err
+	}
+
+	before, err := p.repo.GetByID(ctx, product.ID)
+	if err != nil {
+		return err
+	}
+
+	// clients that do not send a version keep the last write wins behaviour
+	if product.Version == 0 {
+		product.Version = before.Version
+	}
+
+	if product.Version != before.Version {
+		return models.ErrConflict
+	}
+
+	// lifecycle is only changed through UpdateStatus
+	product.Status = before.Status
+	product.PublishAt = before.PublishAt
+	product.UnpublishAt = before.UnpublishAt
+	// stock is only moved by orders and restocks
+	product.Stock = before.Stock
 	product.Updated = null.NewTime(
 		time.Now(), true,
 	)
 
-	return p.repo.Update(ctx, product)
+	err = p.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := p.repo.Update(ctx, product); err != nil {
+			return err
+		}
+
+		return p.events.Emit(ctx, models.EventProductUpdated, product.ID, product)
+	})
+	if err != nil {
+		return err
+	}
+
+	p.record(ctx, product.ID, models.AuditUpdate, before, product)
+	return nil
+}
+
+// Restock put amount pieces back in the stock of the product, like goods
+// sent back by customers. It join the transaction carried by ctx.
+func (p *productUsecase) Restock(ctx context.Context, id int64, amount int64) error {
+	ctx, cancel := context.WithTimeout(ctx, p.contextTimeout)
+	defer cancel()
+
+	if amount <= 0 {
+		return models.ErrBadParamInput
+	}
+
+	return p.repo.Restock(ctx, id, amount)
+}
+
+func (p *productUsecase) UpdateStatus(ctx context.Context, product *models.Product) error {
+	ctx, cancel := context.WithTimeout(ctx, p.contextTimeout)
+	defer cancel()
+
+	if err := validateLifecycle(product); err != nil {
+		return err
+	}
+
+	before, err := p.repo.GetByID(ctx, product.ID)
+	if err != nil {
+		return err
+	}
+
+	if product.Version == 0 {
+		product.Version = before.Version
+	}
+
+	if product.Version != before.Version {
+		return models.ErrConflict
+	}
+
+	after := *before
+	after.Status = product.Status
+	after.PublishAt = product.PublishAt
+	after.UnpublishAt = product.UnpublishAt
+	after.Updated = null.NewTime(
+		time.Now(), true,
+	)
+
+	err = p.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := p.repo.UpdateStatus(ctx, &after); err != nil {
+			return err
+		}
+
+		return p.events.Emit(ctx, models.EventProductUpdated, after.ID, &after)
+	})
+	if err != nil {
+		return err
+	}
+
+	*product = after
+	p.record(ctx, product.ID, models.AuditUpdate, before, product)
+	return nil
+}
+
+// ApplySchedule publish draft products whose publish_at has come and
+// archive published products whose unpublish_at has passed.
+func (p *productUsecase) ApplySchedule(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.contextTimeout)
+	defer cancel()
+
+	now := time.Now()
+
+	var published []int64
+	err := p.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if published, err = p.repo.PublishDue(ctx, now); err != nil {
+			return err
+		}
+
+		return p.emitUpdated(ctx, published)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, id := range published {
+		p.record(ctx, id, models.AuditUpdate,
+			map[string]int{"status": models.ProductDraft},
+			map[string]int{"status": models.ProductPublished})
+	}
+
+	var archived []int64
+	err = p.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if archived, err = p.repo.ArchiveDue(ctx, now); err != nil {
+			return err
+		}
+
+		return p.emitUpdated(ctx, archived)
+	})
+	if err != nil {
+		return int64(len(published)), err
+	}
+
+	for _, id := range archived {
+		p.record(ctx, id, models.AuditUpdate,
+			map[string]int{"status": models.ProductPublished},
+			map[string]int{"status": models.ProductArchived})
+	}
+
+	return int64(len(published) + len(archived)), nil
 }
 
 func (p *productUsecase) Delete(ctx context.Context, id int64) error {
@@ -83,7 +331,19 @@ func (p *productUsecase) Delete(ctx context.Context, id int64) error {
 		return models.ErrNotFound
 	}
 
-	return p.repo.Delete(ctx, id)
+	err = p.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := p.repo.Delete(ctx, id); err != nil {
+			return err
+		}
+
+		return p.events.Emit(ctx, models.EventProductDeleted, id, exists)
+	})
+	if err != nil {
+		return err
+	}
+
+	p.record(ctx, id, models.AuditDelete, exists, nil)
+	return nil
 }
 
 func (p *productUsecase) Compare(ctx context.Context, id1 int64, id2 int64) ([]*models.Product, error) {
@@ -91,13 +351,13 @@ func (p *productUsecase) Compare(ctx context.Context, id1 int64, id2 int64) ([]*
 	defer cancel()
 
 	products := make([]*models.Product, 0)
-	product1, err := p.repo.GetByID(ctx, id1)
+	product1, err := p.repo.GetPublishedByID(ctx, id1)
 
 	if err == nil {
 		products = append(products, product1)
 	}
 
-	product2, err := p.repo.GetByID(ctx, id2)
+	product2, err := p.repo.GetPublishedByID(ctx, id2)
 
 	if err == nil {
 		products = append(products, product2)
@@ -105,3 +365,46 @@ func (p *productUsecase) Compare(ctx context.Context, id1 int64, id2 int64) ([]*
 
 	return products, nil
 }
+
+func (p *productUsecase) Trash(ctx context.Context, offset int64, limit int64) ([]*models.Product, int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.contextTimeout)
+	defer cancel()
+
+	products, found, err := p.repo.GetTrash(ctx, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return products, found, nil
+}
+
+func (p *productUsecase) Restore(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, p.contextTimeout)
+	defer cancel()
+
+	err := p.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := p.repo.Restore(ctx, id); err != nil {
+			return err
+		}
+
+		product, err := p.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		return p.events.Emit(ctx, models.EventProductRestored, id, product)
+	})
+	if err != nil {
+		return err
+	}
+
+	p.record(ctx, id, models.AuditRestore, nil, nil)
+	return nil
+}
+
+func (p *productUsecase) Purge(ctx context.Context, retention time.Duration) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.contextTimeout)
+	defer cancel()
+
+	return p.repo.Purge(ctx, time.Now().Add(-retention))
+}
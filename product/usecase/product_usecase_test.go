@@ -7,13 +7,30 @@ import (
 	"testing"
 	"time"
 
+	"github.com/soerjadi/exam/audit/audittest"
+	eventMocks "github.com/soerjadi/exam/event/mocks"
 	"github.com/soerjadi/exam/models"
 	"github.com/soerjadi/exam/product/mocks"
 	"github.com/soerjadi/exam/product/usecase"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"gopkg.in/guregu/null.v3"
 )
 
+// inlineTx run the work straight away, the repositories are mocked
+type inlineTx struct{}
+
+func (inlineTx) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func newEventMock() *eventMocks.Usecase {
+	mockEvents := new(eventMocks.Usecase)
+	mockEvents.On("Emit", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("int64"), mock.Anything).Return(nil)
+
+	return mockEvents
+}
+
 func TestSearch(t *testing.T) {
 	mockProductRepo := new(mocks.Repository)
 	mockProduct := &models.Product{
@@ -31,7 +48,7 @@ func TestSearch(t *testing.T) {
 			mock.AnythingOfType("int64"), mock.AnythingOfType("int64")).
 			Return(mockListProducts, int64(1), nil).Once()
 
-		p := usecase.NewProductUsecase(mockProductRepo, time.Second*2)
+		p := usecase.NewProductUsecase(mockProductRepo, inlineTx{}, newEventMock(), audittest.NewUsecase(), time.Second*2)
 		products, found, err := p.Search(context.TODO(), &searchQuery, int64(0), int64(10))
 
 		assert.NoError(t, err)
@@ -46,7 +63,7 @@ func TestSearch(t *testing.T) {
 			mock.AnythingOfType("int64"), mock.AnythingOfType("int64")).
 			Return(nil, int64(0), errors.New("Unexpected Error")).Once()
 
-		p := usecase.NewProductUsecase(mockProductRepo, time.Second*2)
+		p := usecase.NewProductUsecase(mockProductRepo, inlineTx{}, newEventMock(), audittest.NewUsecase(), time.Second*2)
 		products, found, err := p.Search(context.TODO(), &searchQuery, int64(0), int64(10))
 
 		assert.Error(t, err)
@@ -69,7 +86,7 @@ func TestGetByID(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mockProductRepo.On("GetByID", mock.Anything, mock.AnythingOfType("int64")).Return(mockProduct, nil).Once()
 
-		p := usecase.NewProductUsecase(mockProductRepo, time.Second*2)
+		p := usecase.NewProductUsecase(mockProductRepo, inlineTx{}, newEventMock(), audittest.NewUsecase(), time.Second*2)
 
 		product, err := p.GetByID(context.TODO(), mockProduct.ID)
 
@@ -82,7 +99,7 @@ func TestGetByID(t *testing.T) {
 	t.Run("fail", func(t *testing.T) {
 		mockProductRepo.On("GetByID", mock.Anything, mock.AnythingOfType("int64")).Return(nil, errors.New("Unexpected errors")).Once()
 
-		p := usecase.NewProductUsecase(mockProductRepo, time.Second*2)
+		p := usecase.NewProductUsecase(mockProductRepo, inlineTx{}, newEventMock(), audittest.NewUsecase(), time.Second*2)
 
 		product, err := p.GetByID(context.TODO(), mockProduct.ID)
 
@@ -105,7 +122,8 @@ func TestCreate(t *testing.T) {
 		tmpMockProduct.ID = 0
 		mockProductRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Product")).Return(nil).Once()
 
-		p := usecase.NewProductUsecase(mockProductRepo, time.Second*2)
+		mockAudit := audittest.NewUsecase()
+		p := usecase.NewProductUsecase(mockProductRepo, inlineTx{}, newEventMock(), mockAudit, time.Second*2)
 
 		err := p.Create(context.TODO(), &tmpMockProduct)
 
@@ -113,12 +131,13 @@ func TestCreate(t *testing.T) {
 		assert.Equal(t, mockProduct.Name, tmpMockProduct.Name)
 
 		mockProductRepo.AssertExpectations(t)
+		audittest.AssertRecorded(t, mockAudit, models.AuditProduct, tmpMockProduct.ID, models.AuditCreate, nil, &tmpMockProduct)
 	})
 
 	t.Run("fail", func(t *testing.T) {
 		mockProductRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Product")).Return(errors.New("Unexpected error")).Once()
 
-		p := usecase.NewProductUsecase(mockProductRepo, time.Second*2)
+		p := usecase.NewProductUsecase(mockProductRepo, inlineTx{}, newEventMock(), audittest.NewUsecase(), time.Second*2)
 
 		err := p.Create(context.TODO(), &mockProduct)
 
@@ -126,6 +145,50 @@ func TestCreate(t *testing.T) {
 
 		mockProductRepo.AssertExpectations(t)
 	})
+
+	t.Run("emit product created", func(t *testing.T) {
+		created := mockProduct
+		mockProductRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Product")).Return(nil).Once()
+
+		mockEvents := new(eventMocks.Usecase)
+		mockEvents.On("Emit", mock.Anything, models.EventProductCreated, mock.AnythingOfType("int64"), &created).Return(nil).Once()
+
+		p := usecase.NewProductUsecase(mockProductRepo, inlineTx{}, mockEvents, audittest.NewUsecase(), time.Second*2)
+
+		err := p.Create(context.TODO(), &created)
+
+		assert.NoError(t, err)
+		mockEvents.AssertExpectations(t)
+	})
+
+	t.Run("outbox failure fail the change", func(t *testing.T) {
+		created := mockProduct
+		mockProductRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Product")).Return(nil).Once()
+
+		mockEvents := new(eventMocks.Usecase)
+		mockEvents.On("Emit", mock.Anything, models.EventProductCreated, mock.AnythingOfType("int64"), &created).
+			Return(errors.New("Unexpected error")).Once()
+
+		mockAudit := audittest.NewUsecase()
+		p := usecase.NewProductUsecase(mockProductRepo, inlineTx{}, mockEvents, mockAudit, time.Second*2)
+
+		err := p.Create(context.TODO(), &created)
+
+		assert.Error(t, err)
+		audittest.AssertNothingRecorded(t, mockAudit)
+	})
+
+	t.Run("negative dimension", func(t *testing.T) {
+		invalid := mockProduct
+		invalid.Weight = 1.2
+		invalid.Height = -3
+
+		p := usecase.NewProductUsecase(mockProductRepo, inlineTx{}, newEventMock(), audittest.NewUsecase(), time.Second*2)
+
+		err := p.Create(context.TODO(), &invalid)
+
+		assert.Equal(t, models.ErrBadParamInput, err)
+	})
 }
 
 func TestUpdate(t *testing.T) {
@@ -138,12 +201,34 @@ func TestUpdate(t *testing.T) {
 	}
 
 	t.Run("success", func(t *testing.T) {
+		before := mockProduct
+		mockProductRepo.On("GetByID", mock.Anything, mockProduct.ID).Return(&before, nil).Once()
 		mockProductRepo.On("Update", mock.Anything, &mockProduct).Return(nil).Once()
 
-		p := usecase.NewProductUsecase(mockProductRepo, time.Second*2)
+		mockAudit := audittest.NewUsecase()
+		p := usecase.NewProductUsecase(mockProductRepo, inlineTx{}, newEventMock(), mockAudit, time.Second*2)
 
 		err := p.Update(context.TODO(), &mockProduct)
 
+		assert.NoError(t, err)
+		mockProductRepo.AssertExpectations(t)
+		audittest.AssertRecorded(t, mockAudit, models.AuditProduct, mockProduct.ID, models.AuditUpdate, &before, &mockProduct)
+	})
+
+	t.Run("package and tax class can be cleared", func(t *testing.T) {
+		before := models.Product{ID: 65, Name: "product 65", SKU: "sku65", Weight: 1.5, Length: 30, Width: 20, Height: 10,
+			TaxClassID: null.IntFrom(2), Stock: 7, Version: 2}
+		mockProductRepo.On("GetByID", mock.Anything, before.ID).Return(&before, nil).Once()
+		mockProductRepo.On("Update", mock.Anything, mock.MatchedBy(func(p *models.Product) bool {
+			return p.Name == "renamed" && p.Weight == 0 && p.Length == 0 && p.Width == 0 && p.Height == 12 &&
+				!p.TaxClassID.Valid && p.Stock == 7
+		})).Return(nil).Once()
+
+		p := usecase.NewProductUsecase(mockProductRepo, inlineTx{}, newEventMock(), audittest.NewUsecase(), time.Second*2)
+
+		// the stock is not the client's to set
+		err := p.Update(context.TODO(), &models.Product{ID: 65, Name: "renamed", SKU: "sku65", Height: 12, Stock: 100})
+
 		assert.NoError(t, err)
 		mockProductRepo.AssertExpectations(t)
 	})
@@ -161,17 +246,23 @@ func TestDelete(t *testing.T) {
 		mockProductRepo.On("GetByID", mock.Anything, mock.AnythingOfType("int64")).Return(&mockProduct, nil).Once()
 		mockProductRepo.On("Delete", mock.Anything, mock.AnythingOfType("int64")).Return(nil).Once()
 
-		p := usecase.NewProductUsecase(mockProductRepo, time.Second*2)
+		mockEvents := new(eventMocks.Usecase)
+		mockEvents.On("Emit", mock.Anything, models.EventProductDeleted, mockProduct.ID, &mockProduct).Return(nil).Once()
+
+		mockAudit := audittest.NewUsecase()
+		p := usecase.NewProductUsecase(mockProductRepo, inlineTx{}, mockEvents, mockAudit, time.Second*2)
 
 		err := p.Delete(context.TODO(), mockProduct.ID)
 
 		assert.NoError(t, err)
 		mockProductRepo.AssertExpectations(t)
+		mockEvents.AssertExpectations(t)
+		audittest.AssertRecorded(t, mockAudit, models.AuditProduct, mockProduct.ID, models.AuditDelete, &mockProduct, nil)
 	})
 	t.Run("item is not exist", func(t *testing.T) {
 		mockProductRepo.On("GetByID", mock.Anything, mock.AnythingOfType("int64")).Return(nil, nil).Once()
 
-		p := usecase.NewProductUsecase(mockProductRepo, time.Second*2)
+		p := usecase.NewProductUsecase(mockProductRepo, inlineTx{}, newEventMock(), audittest.NewUsecase(), time.Second*2)
 
 		err := p.Delete(context.TODO(), mockProduct.ID)
 
@@ -200,10 +291,10 @@ func TestCompare(t *testing.T) {
 	}
 
 	t.Run("success", func(t *testing.T) {
-		mockProductRepo.On("GetByID", mock.Anything, mock.AnythingOfType("int64")).Return(&mockProduct1, nil).Once()
-		mockProductRepo.On("GetByID", mock.Anything, mock.AnythingOfType("int64")).Return(&mockProduct2, nil).Once()
+		mockProductRepo.On("GetPublishedByID", mock.Anything, mock.AnythingOfType("int64")).Return(&mockProduct1, nil).Once()
+		mockProductRepo.On("GetPublishedByID", mock.Anything, mock.AnythingOfType("int64")).Return(&mockProduct2, nil).Once()
 
-		p := usecase.NewProductUsecase(mockProductRepo, time.Second*2)
+		p := usecase.NewProductUsecase(mockProductRepo, inlineTx{}, newEventMock(), audittest.NewUsecase(), time.Second*2)
 
 		products, err := p.Compare(context.TODO(), mockProduct1.ID, mockProduct2.ID)
 
@@ -214,3 +305,176 @@ func TestCompare(t *testing.T) {
 		mockProductRepo.AssertExpectations(t)
 	})
 }
+
+func TestTrash(t *testing.T) {
+	mockProductRepo := new(mocks.Repository)
+	mockProduct := &models.Product{
+		ID:        64,
+		Name:      "product 64",
+		SKU:       "sku64",
+		Created:   time.Now(),
+		DeletedAt: null.NewTime(time.Now(), true),
+	}
+
+	mockProductRepo.On("GetTrash", mock.Anything, int64(0), int64(10)).
+		Return([]*models.Product{mockProduct}, int64(1), nil).Once()
+
+	p := usecase.NewProductUsecase(mockProductRepo, inlineTx{}, newEventMock(), audittest.NewUsecase(), time.Second*2)
+	products, found, err := p.Trash(context.TODO(), int64(0), int64(10))
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), found)
+	assert.Len(t, products, 1)
+	mockProductRepo.AssertExpectations(t)
+}
+
+func TestRestore(t *testing.T) {
+	mockProductRepo := new(mocks.Repository)
+
+	t.Run("success", func(t *testing.T) {
+		restored := &models.Product{ID: 64, Name: "product 64", SKU: "sku64"}
+		mockProductRepo.On("Restore", mock.Anything, int64(64)).Return(nil).Once()
+		mockProductRepo.On("GetByID", mock.Anything, int64(64)).Return(restored, nil).Once()
+
+		mockEvents := new(eventMocks.Usecase)
+		mockEvents.On("Emit", mock.Anything, models.EventProductRestored, int64(64), restored).Return(nil).Once()
+
+		mockAudit := audittest.NewUsecase()
+		p := usecase.NewProductUsecase(mockProductRepo, inlineTx{}, mockEvents, mockAudit, time.Second*2)
+
+		err := p.Restore(context.TODO(), int64(64))
+
+		assert.NoError(t, err)
+		mockProductRepo.AssertExpectations(t)
+		mockEvents.AssertExpectations(t)
+		audittest.AssertRecorded(t, mockAudit, models.AuditProduct, int64(64), models.AuditRestore, nil, nil)
+	})
+
+	t.Run("not in trash", func(t *testing.T) {
+		mockProductRepo.On("Restore", mock.Anything, int64(65)).Return(models.ErrNotFound).Once()
+
+		mockEvents := new(eventMocks.Usecase)
+		mockAudit := audittest.NewUsecase()
+		p := usecase.NewProductUsecase(mockProductRepo, inlineTx{}, mockEvents, mockAudit, time.Second*2)
+
+		err := p.Restore(context.TODO(), int64(65))
+
+		assert.Equal(t, models.ErrNotFound, err)
+		mockEvents.AssertNotCalled(t, "Emit", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		audittest.AssertNothingRecorded(t, mockAudit)
+		mockProductRepo.AssertExpectations(t)
+	})
+}
+
+func TestPurge(t *testing.T) {
+	mockProductRepo := new(mocks.Repository)
+	retention := 24 * time.Hour
+
+	mockProductRepo.On("Purge", mock.Anything, mock.MatchedBy(func(before time.Time) bool {
+		return before.Before(time.Now().Add(-retention).Add(time.Second))
+	})).Return(int64(2), nil).Once()
+
+	p := usecase.NewProductUsecase(mockProductRepo, inlineTx{}, newEventMock(), audittest.NewUsecase(), time.Second*2)
+
+	purged, err := p.Purge(context.TODO(), retention)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), purged)
+	mockProductRepo.AssertExpectations(t)
+}
+
+func TestUpdateConflict(t *testing.T) {
+	mockProductRepo := new(mocks.Repository)
+	current := models.Product{
+		ID:      64,
+		Name:    "product 64",
+		SKU:     "sku64",
+		Version: 3,
+	}
+
+	stale := models.Product{
+		ID:      64,
+		Name:    "product sixty four",
+		SKU:     "sku64",
+		Version: 2,
+	}
+
+	mockProductRepo.On("GetByID", mock.Anything, current.ID).Return(&current, nil).Once()
+
+	p := usecase.NewProductUsecase(mockProductRepo, inlineTx{}, newEventMock(), audittest.NewUsecase(), time.Second*2)
+
+	err := p.Update(context.TODO(), &stale)
+
+	assert.Equal(t, models.ErrConflict, err)
+	mockProductRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	mockProductRepo.AssertExpectations(t)
+}
+
+func TestUpdateStatus(t *testing.T) {
+	mockProductRepo := new(mocks.Repository)
+	current := models.Product{
+		ID:      64,
+		Name:    "product 64",
+		SKU:     "sku64",
+		Status:  models.ProductDraft,
+		Version: 2,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockProductRepo.On("GetByID", mock.Anything, current.ID).Return(&current, nil).Once()
+		mockProductRepo.On("UpdateStatus", mock.Anything, mock.MatchedBy(func(p *models.Product) bool {
+			return p.Status == models.ProductPublished && p.Name == current.Name
+		})).Return(nil).Once()
+
+		p := usecase.NewProductUsecase(mockProductRepo, inlineTx{}, newEventMock(), audittest.NewUsecase(), time.Second*2)
+
+		product := models.Product{ID: current.ID, Status: models.ProductPublished}
+		err := p.UpdateStatus(context.TODO(), &product)
+
+		assert.NoError(t, err)
+		assert.Equal(t, current.SKU, product.SKU)
+		mockProductRepo.AssertExpectations(t)
+	})
+
+	t.Run("unknown status", func(t *testing.T) {
+		p := usecase.NewProductUsecase(mockProductRepo, inlineTx{}, newEventMock(), audittest.NewUsecase(), time.Second*2)
+
+		product := models.Product{ID: current.ID, Status: 9}
+		err := p.UpdateStatus(context.TODO(), &product)
+
+		assert.Equal(t, models.ErrBadParamInput, err)
+	})
+
+	t.Run("empty publishing window", func(t *testing.T) {
+		p := usecase.NewProductUsecase(mockProductRepo, inlineTx{}, newEventMock(), audittest.NewUsecase(), time.Second*2)
+
+		now := time.Now()
+		product := models.Product{
+			ID:          current.ID,
+			PublishAt:   null.NewTime(now, true),
+			UnpublishAt: null.NewTime(now.Add(-time.Hour), true),
+		}
+		err := p.UpdateStatus(context.TODO(), &product)
+
+		assert.Equal(t, models.ErrBadParamInput, err)
+	})
+}
+
+func TestApplySchedule(t *testing.T) {
+	mockProductRepo := new(mocks.Repository)
+	mockProductRepo.On("PublishDue", mock.Anything, mock.AnythingOfType("time.Time")).Return([]int64{1, 2}, nil).Once()
+	mockProductRepo.On("ArchiveDue", mock.Anything, mock.AnythingOfType("time.Time")).Return([]int64{3}, nil).Once()
+	mockProductRepo.On("GetByID", mock.Anything, mock.AnythingOfType("int64")).Return(&models.Product{}, nil).Times(3)
+
+	mockAudit := audittest.NewUsecase()
+	mockEvents := newEventMock()
+	p := usecase.NewProductUsecase(mockProductRepo, inlineTx{}, mockEvents, mockAudit, time.Second*2)
+
+	changed, err := p.ApplySchedule(context.TODO())
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), changed)
+	mockAudit.AssertNumberOfCalls(t, "Record", 3)
+	mockEvents.AssertNumberOfCalls(t, "Emit", 3)
+	mockProductRepo.AssertExpectations(t)
+}
@@ -67,8 +67,12 @@ func (p *pgProductCategoryRepository) GetByProductID(ctx context.Context, id int
 	return cats, nil
 }
 
+// GetByCategoryID list the products of a category, the ones in the trash are
+// left out
 func (p *pgProductCategoryRepository) GetByCategoryID(ctx context.Context, id int64) ([]*models.ProductCategory, error) {
-	query := `SELECT id, product_id, category_id FROM product_category WHERE category_id = ?`
+	query := `SELECT pc.id, pc.product_id, pc.category_id FROM product_category pc
+		JOIN products p ON p.id = pc.product_id AND p.deleted_at IS NULL
+		WHERE pc.category_id = ?`
 
 	products, err := p.fetch(ctx, query, id)
 	if err != nil {
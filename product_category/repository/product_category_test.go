@@ -66,7 +66,7 @@ func TestGetByCategoryID(t *testing.T) {
 		AddRow(7, 34, 10).
 		AddRow(20, 56, 10)
 
-	query := "SELECT id, product_id, category_id FROM product_category WHERE category_id = \\?"
+	query := "SELECT pc.id, pc.product_id, pc.category_id FROM product_category pc\\s+JOIN products p ON p.id = pc.product_id AND p.deleted_at IS NULL\\s+WHERE pc.category_id = \\?"
 
 	mock.ExpectQuery(query).WillReturnRows(rows)
 	p := repository.NewPGProductCategoryRepository(db)
@@ -4,25 +4,44 @@ import (
 	"context"
 	"time"
 
+	"github.com/soerjadi/exam/audit"
 	"github.com/soerjadi/exam/models"
 	"github.com/soerjadi/exam/product"
 	cat "github.com/soerjadi/exam/product_category"
+	"github.com/soerjadi/exam/utils"
 )
 
 type pcUsecase struct {
 	productRepo    product.Repository
 	pcRepo         cat.Repository
+	audit          audit.Usecase
 	contextTimeout time.Duration
 }
 
+var logger = utils.LogBuilder(true)
+
 // NewPCUsecase will create new product category usecase object representation of category usecase interface
-func NewPCUsecase(pc cat.Repository, timeout time.Duration) cat.Usecase {
+func NewPCUsecase(pc cat.Repository, a audit.Usecase, timeout time.Duration) cat.Usecase {
 	return &pcUsecase{
 		pcRepo:         pc,
+		audit:          a,
 		contextTimeout: timeout,
 	}
 }
 
+func (pc *pcUsecase) record(ctx context.Context, id int64, action string, before interface{}, after interface{}) {
+	if err := pc.audit.Record(ctx, models.AuditProductCategory, id, action, before, after); err != nil {
+		logger.Error(err)
+	}
+}
+
+// recordDeleted write an audit entry for every link that is about to be removed
+func (pc *pcUsecase) recordDeleted(ctx context.Context, links []*models.ProductCategory) {
+	for _, link := range links {
+		pc.record(ctx, link.ID, models.AuditDelete, link, nil)
+	}
+}
+
 func (pc *pcUsecase) GetByProductID(ctx context.Context, productID int64) ([]*models.ProductCategory, error) {
 	ctx, cancel := context.WithTimeout(ctx, pc.contextTimeout)
 	defer cancel()
@@ -58,6 +77,7 @@ func (pc *pcUsecase) Create(ctx context.Context, cat *models.ProductCategory) er
 		return err
 	}
 
+	pc.record(ctx, cat.ID, models.AuditCreate, nil, cat)
 	return nil
 }
 
@@ -65,12 +85,34 @@ func (pc *pcUsecase) DeleteByProductID(ctx context.Context, productID int64) err
 	ctx, cancel := context.WithTimeout(ctx, pc.contextTimeout)
 	defer cancel()
 
-	return pc.pcRepo.DeleteByProductID(ctx, productID)
+	links, err := pc.pcRepo.GetByProductID(ctx, productID)
+	if err != nil {
+		return err
+	}
+
+	err = pc.pcRepo.DeleteByProductID(ctx, productID)
+	if err != nil {
+		return err
+	}
+
+	pc.recordDeleted(ctx, links)
+	return nil
 }
 
 func (pc *pcUsecase) DeleteByCategoryID(ctx context.Context, categoryID int64) error {
 	ctx, cancel := context.WithTimeout(ctx, pc.contextTimeout)
 	defer cancel()
 
-	return pc.pcRepo.DeleteByCategoryID(ctx, categoryID)
+	links, err := pc.pcRepo.GetByCategoryID(ctx, categoryID)
+	if err != nil {
+		return err
+	}
+
+	err = pc.pcRepo.DeleteByCategoryID(ctx, categoryID)
+	if err != nil {
+		return err
+	}
+
+	pc.recordDeleted(ctx, links)
+	return nil
 }
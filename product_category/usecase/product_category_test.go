@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/soerjadi/exam/audit/audittest"
 	"github.com/soerjadi/exam/models"
 	"github.com/soerjadi/exam/product_category/mocks"
 	"github.com/soerjadi/exam/product_category/usecase"
@@ -40,7 +41,7 @@ func TestGetByProductID(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mockCatsRepo.On("GetByProductID", mock.Anything, mock.AnythingOfType("int64")).Return(mockCats, nil).Once()
 
-		p := usecase.NewPCUsecase(mockCatsRepo, time.Second*2)
+		p := usecase.NewPCUsecase(mockCatsRepo, audittest.NewUsecase(), time.Second*2)
 
 		cats, err := p.GetByProductID(context.TODO(), int64(8))
 
@@ -53,7 +54,7 @@ func TestGetByProductID(t *testing.T) {
 	t.Run("fail", func(t *testing.T) {
 		mockCatsRepo.On("GetByProductID", mock.Anything, mock.AnythingOfType("int64")).Return(mockCats[:1], nil).Once()
 
-		p := usecase.NewPCUsecase(mockCatsRepo, time.Second*2)
+		p := usecase.NewPCUsecase(mockCatsRepo, audittest.NewUsecase(), time.Second*2)
 
 		cats, err := p.GetByProductID(context.TODO(), int64(8))
 
@@ -92,7 +93,7 @@ func TestGetByCategoryID(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mockCatsRepo.On("GetByCategoryID", mock.Anything, mock.AnythingOfType("int64")).Return(mockCats, nil).Once()
 
-		p := usecase.NewPCUsecase(mockCatsRepo, time.Second*2)
+		p := usecase.NewPCUsecase(mockCatsRepo, audittest.NewUsecase(), time.Second*2)
 
 		cats, err := p.GetByCategoryID(context.TODO(), int64(8))
 
@@ -105,7 +106,7 @@ func TestGetByCategoryID(t *testing.T) {
 	t.Run("fail", func(t *testing.T) {
 		mockCatsRepo.On("GetByCategoryID", mock.Anything, mock.AnythingOfType("int64")).Return(mockCats[:1], nil).Once()
 
-		p := usecase.NewPCUsecase(mockCatsRepo, time.Second*2)
+		p := usecase.NewPCUsecase(mockCatsRepo, audittest.NewUsecase(), time.Second*2)
 
 		cats, err := p.GetByCategoryID(context.TODO(), int64(8))
 
@@ -129,7 +130,8 @@ func TestCreate(t *testing.T) {
 
 		mockCatsRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.ProductCategory")).Return(nil).Once()
 
-		p := usecase.NewPCUsecase(mockCatsRepo, time.Second*2)
+		mockAudit := audittest.NewUsecase()
+		p := usecase.NewPCUsecase(mockCatsRepo, mockAudit, time.Second*2)
 
 		err := p.Create(context.TODO(), &tmpMockCat)
 
@@ -137,6 +139,7 @@ func TestCreate(t *testing.T) {
 		assert.Equal(t, mockCat.ID, tmpMockCat.ID)
 
 		mockCatsRepo.AssertExpectations(t)
+		audittest.AssertRecorded(t, mockAudit, models.AuditProductCategory, tmpMockCat.ID, models.AuditCreate, nil, &tmpMockCat)
 	})
 
 	t.Run("fail", func(t *testing.T) {
@@ -145,11 +148,13 @@ func TestCreate(t *testing.T) {
 
 		mockCatsRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.ProductCategory")).Return(errors.New("Unexpected error")).Once()
 
-		p := usecase.NewPCUsecase(mockCatsRepo, time.Second*2)
+		mockAudit := audittest.NewUsecase()
+		p := usecase.NewPCUsecase(mockCatsRepo, mockAudit, time.Second*2)
 
 		err := p.Create(context.TODO(), &tmpMockCat)
 
 		assert.Error(t, err)
+		audittest.AssertNothingRecorded(t, mockAudit)
 
 		mockCatsRepo.AssertExpectations(t)
 	})
@@ -180,20 +185,25 @@ func TestDeleteByProductID(t *testing.T) {
 	mockCats = append(mockCats, &cat3)
 
 	t.Run("success", func(t *testing.T) {
+		mockCatsRepo.On("GetByProductID", mock.Anything, mock.AnythingOfType("int64")).Return(mockCats, nil).Once()
 		mockCatsRepo.On("DeleteByProductID", mock.Anything, mock.AnythingOfType("int64")).Return(nil).Once()
 
-		p := usecase.NewPCUsecase(mockCatsRepo, time.Second*2)
+		mockAudit := audittest.NewUsecase()
+		p := usecase.NewPCUsecase(mockCatsRepo, mockAudit, time.Second*2)
 
 		err := p.DeleteByProductID(context.TODO(), int64(8))
 
 		assert.NoError(t, err)
 		mockCatsRepo.AssertExpectations(t)
+		for _, cat := range mockCats {
+			audittest.AssertRecorded(t, mockAudit, models.AuditProductCategory, cat.ID, models.AuditDelete, cat, nil)
+		}
 	})
 
 	t.Run("items not found", func(t *testing.T) {
 		mockCatsRepo.On("GetByProductID", mock.Anything, mock.AnythingOfType("int64")).Return(nil, nil).Once()
 
-		p := usecase.NewPCUsecase(mockCatsRepo, time.Second*2)
+		p := usecase.NewPCUsecase(mockCatsRepo, audittest.NewUsecase(), time.Second*2)
 
 		result, err := p.GetByProductID(context.TODO(), int64(8))
 
@@ -228,20 +238,25 @@ func TestDeleteByCategoryID(t *testing.T) {
 	mockCats = append(mockCats, &cat3)
 
 	t.Run("success", func(t *testing.T) {
+		mockCatsRepo.On("GetByCategoryID", mock.Anything, mock.AnythingOfType("int64")).Return(mockCats, nil).Once()
 		mockCatsRepo.On("DeleteByCategoryID", mock.Anything, mock.AnythingOfType("int64")).Return(nil).Once()
 
-		p := usecase.NewPCUsecase(mockCatsRepo, time.Second*2)
+		mockAudit := audittest.NewUsecase()
+		p := usecase.NewPCUsecase(mockCatsRepo, mockAudit, time.Second*2)
 
 		err := p.DeleteByCategoryID(context.TODO(), int64(8))
 
 		assert.NoError(t, err)
 		mockCatsRepo.AssertExpectations(t)
+		for _, cat := range mockCats {
+			audittest.AssertRecorded(t, mockAudit, models.AuditProductCategory, cat.ID, models.AuditDelete, cat, nil)
+		}
 	})
 
 	t.Run("items not found", func(t *testing.T) {
 		mockCatsRepo.On("GetByCategoryID", mock.Anything, mock.AnythingOfType("int64")).Return(nil, nil).Once()
 
-		p := usecase.NewPCUsecase(mockCatsRepo, time.Second*2)
+		p := usecase.NewPCUsecase(mockCatsRepo, audittest.NewUsecase(), time.Second*2)
 
 		result, err := p.GetByCategoryID(context.TODO(), int64(8))
 
@@ -3,6 +3,7 @@
 package mocks
 
 import context "context"
+import time "time"
 import mock "github.com/stretchr/testify/mock"
 import models "github.com/soerjadi/exam/models"
 
@@ -25,20 +26,6 @@ func (_m *Usecase) Create(ctx context.Context, price *models.ProductPrice) error
 	return r0
 }
 
-// DeleteByProductID provides a mock function with given fields: ctx, id
-func (_m *Usecase) DeleteByProductID(ctx context.Context, id int64) error {
-	ret := _m.Called(ctx, id)
-
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
-		r0 = rf(ctx, id)
-	} else {
-		r0 = ret.Error(0)
-	}
-
-	return r0
-}
-
 // GetByProductID provides a mock function with given fields: ctx, id
 func (_m *Usecase) GetByProductID(ctx context.Context, id int64) ([]*models.ProductPrice, error) {
 	ret := _m.Called(ctx, id)
@@ -62,6 +49,29 @@ func (_m *Usecase) GetByProductID(ctx context.Context, id int64) ([]*models.Prod
 	return r0, r1
 }
 
+// GetPriceAt provides a mock function with given fields: ctx, productID, amount, at
+func (_m *Usecase) GetPriceAt(ctx context.Context, productID int64, amount int64, at time.Time) (*models.ProductPrice, error) {
+	ret := _m.Called(ctx, productID, amount, at)
+
+	var r0 *models.ProductPrice
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, time.Time) *models.ProductPrice); ok {
+		r0 = rf(ctx, productID, amount, at)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.ProductPrice)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, time.Time) error); ok {
+		r1 = rf(ctx, productID, amount, at)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetPriceByAmount provides a mock function with given fields: ctx, amount
 func (_m *Usecase) GetPriceByAmount(ctx context.Context, amount int64) (*models.ProductPrice, error) {
 	ret := _m.Called(ctx, amount)
@@ -84,3 +94,40 @@ func (_m *Usecase) GetPriceByAmount(ctx context.Context, amount int64) (*models.
 
 	return r0, r1
 }
+
+// GetTimeline provides a mock function with given fields: ctx, id
+func (_m *Usecase) GetTimeline(ctx context.Context, id int64) ([]*models.ProductPrice, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 []*models.ProductPrice
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []*models.ProductPrice); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.ProductPrice)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Schedule provides a mock function with given fields: ctx, productID, prices, from
+func (_m *Usecase) Schedule(ctx context.Context, productID int64, prices []*models.ProductPrice, from time.Time) error {
+	ret := _m.Called(ctx, productID, prices, from)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, []*models.ProductPrice, time.Time) error); ok {
+		r0 = rf(ctx, productID, prices, from)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -2,6 +2,7 @@ package product_price
 
 import (
 	"context"
+	"time"
 
 	"github.com/soerjadi/exam/models"
 )
@@ -9,7 +10,10 @@ import (
 // Repository represent the product price repository contract
 type Repository interface {
 	Create(ctx context.Context, price *models.ProductPrice) error
-	DeleteByProductID(ctx context.Context, id int64) error
+	Schedule(ctx context.Context, productID int64, prices []*models.ProductPrice, from time.Time) error
 	GetByProductID(ctx context.Context, id int64) ([]*models.ProductPrice, error)
+	GetByProductIDAt(ctx context.Context, id int64, at time.Time) ([]*models.ProductPrice, error)
+	GetTimeline(ctx context.Context, id int64) ([]*models.ProductPrice, error)
+	GetPriceAt(ctx context.Context, productID int64, amount int64, at time.Time) (*models.ProductPrice, error)
 	GetPriceByAmount(ctx context.Context, amount int64) (price *models.ProductPrice, err error)
 }
@@ -3,10 +3,13 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"time"
 
+	"github.com/soerjadi/exam/database"
 	"github.com/soerjadi/exam/models"
 	price "github.com/soerjadi/exam/product_price"
 	"github.com/soerjadi/exam/utils"
+	"gopkg.in/guregu/null.v3"
 )
 
 type pgProductPriceRepository struct {
@@ -43,6 +46,8 @@ func (p *pgProductPriceRepository) fetch(ctx context.Context, query string, args
 			&t.Amount,
 			&t.Price,
 			&t.ProductID,
+			&t.ValidFrom,
+			&t.ValidTo,
 		)
 
 		if err != nil {
@@ -57,13 +62,17 @@ func (p *pgProductPriceRepository) fetch(ctx context.Context, query string, args
 }
 
 func (p *pgProductPriceRepository) Create(ctx context.Context, price *models.ProductPrice) error {
-	query := `INSERT INTO product_price(amount, price, product_id) VALUES(?, ?, ?) returning id`
-	stmt, err := p.Conn.PrepareContext(ctx, query)
+	if price.ValidFrom.IsZero() {
+		price.ValidFrom = time.Now()
+	}
+
+	query := `INSERT INTO product_price(amount, price, product_id, valid_from, valid_to) VALUES(?, ?, ?, ?, ?) returning id`
+	stmt, err := database.ExecutorFrom(ctx, p.Conn).PrepareContext(ctx, query)
 	if err != nil {
 		return err
 	}
 
-	result, err := stmt.ExecContext(ctx, price.Amount, price.Price, price.ProductID)
+	result, err := stmt.ExecContext(ctx, price.Amount, price.Price, price.ProductID, price.ValidFrom, price.ValidTo)
 	if err != nil {
 		return err
 	}
@@ -78,7 +87,25 @@ func (p *pgProductPriceRepository) Create(ctx context.Context, price *models.Pro
 }
 
 func (p *pgProductPriceRepository) GetByProductID(ctx context.Context, id int64) ([]*models.ProductPrice, error) {
-	query := `SELECT id, amount, price, product_id FROM product_price WHERE product_id = ?`
+	return p.GetByProductIDAt(ctx, id, time.Now())
+}
+
+func (p *pgProductPriceRepository) GetByProductIDAt(ctx context.Context, id int64, at time.Time) ([]*models.ProductPrice, error) {
+	query := `SELECT id, amount, price, product_id, valid_from, valid_to FROM product_price
+		WHERE product_id = ? AND valid_from <= ? AND (valid_to IS NULL OR valid_to > ?) ORDER BY amount`
+
+	prices, err := p.fetch(ctx, query, id, at, at)
+	if err != nil {
+		return nil, err
+	}
+
+	return prices, nil
+}
+
+func (p *pgProductPriceRepository) GetTimeline(ctx context.Context, id int64) ([]*models.ProductPrice, error) {
+	// rows superseded before they ever took effect are left out
+	query := `SELECT id, amount, price, product_id, valid_from, valid_to FROM product_price
+		WHERE product_id = ? AND (valid_to IS NULL OR valid_to > valid_from) ORDER BY valid_from, amount`
 
 	prices, err := p.fetch(ctx, query, id)
 	if err != nil {
@@ -88,35 +115,80 @@ func (p *pgProductPriceRepository) GetByProductID(ctx context.Context, id int64)
 	return prices, nil
 }
 
-func (p *pgProductPriceRepository) GetPriceByAmount(ctx context.Context, amount int64) (price *models.ProductPrice, err error) {
-	query := `SELECT id, amount, price, product_id FROM product_price WHERE amount < ? LIMIT 1`
+func (p *pgProductPriceRepository) GetPriceAt(ctx context.Context, productID int64, amount int64, at time.Time) (*models.ProductPrice, error) {
+	query := `SELECT id, amount, price, product_id, valid_from, valid_to FROM product_price
+		WHERE product_id = ? AND amount <= ? AND valid_from <= ? AND (valid_to IS NULL OR valid_to > ?) ORDER BY amount DESC LIMIT 1`
 
-	prices, err := p.fetch(ctx, query, amount)
+	prices, err := p.fetch(ctx, query, productID, amount, at, at)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(prices) > 0 {
-		price = prices[0]
-	} else {
+	if len(prices) == 0 {
 		return nil, models.ErrNotFound
 	}
 
-	return
+	return prices[0], nil
 }
 
-func (p *pgProductPriceRepository) DeleteByProductID(ctx context.Context, id int64) error {
-	query := `DELETE FROM product_price WHERE product_id = ?`
+// Schedule close prices in effect at from and insert the new ones starting
+// at from. The new prices end where the next already scheduled change start.
+func (p *pgProductPriceRepository) Schedule(ctx context.Context, productID int64, prices []*models.ProductPrice, from time.Time) error {
+	return database.RunInTx(ctx, p.Conn, func(tx *sql.Tx) error {
+		expire := `UPDATE product_price SET valid_to = ? WHERE product_id = ? AND valid_from <= ? AND (valid_to IS NULL OR valid_to > ?)`
+		if _, err := tx.ExecContext(ctx, expire, from, productID, from, from); err != nil {
+			logger.Error(err)
+			return err
+		}
+
+		var next null.Time
+		err := tx.QueryRowContext(ctx, `SELECT MIN(valid_from) FROM product_price WHERE product_id = ? AND valid_from > ?`, productID, from).Scan(&next)
+		if err != nil {
+			logger.Error(err)
+			return err
+		}
+
+		stmt, err := tx.PrepareContext(ctx, `INSERT INTO product_price(amount, price, product_id, valid_from, valid_to) VALUES(?, ?, ?, ?, ?) returning id`)
+		if err != nil {
+			return err
+		}
+
+		for _, price := range prices {
+			price.ProductID = productID
+			price.ValidFrom = from
+			price.ValidTo = next
+
+			result, err := stmt.ExecContext(ctx, price.Amount, price.Price, price.ProductID, price.ValidFrom, price.ValidTo)
+			if err != nil {
+				logger.Error(err)
+				return err
+			}
+
+			lastID, err := result.LastInsertId()
+			if err != nil {
+				return err
+			}
+
+			price.ID = lastID
+		}
 
-	stmt, err := p.Conn.PrepareContext(ctx, query)
-	if err != nil {
 		return nil
-	}
+	})
+}
+
+func (p *pgProductPriceRepository) GetPriceByAmount(ctx context.Context, amount int64) (price *models.ProductPrice, err error) {
+	query := `SELECT id, amount, price, product_id, valid_from, valid_to FROM product_price WHERE amount < ? LIMIT 1`
 
-	_, err = stmt.ExecContext(ctx, id)
+	prices, err := p.fetch(ctx, query, amount)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	if len(prices) > 0 {
+		price = prices[0]
+	} else {
+		return nil, models.ErrNotFound
+	}
+
+	return
 }
@@ -3,11 +3,13 @@ package repository_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/soerjadi/exam/models"
 	"github.com/soerjadi/exam/product_price/repository"
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v3"
 )
 
 func TestCreate(t *testing.T) {
@@ -22,9 +24,9 @@ func TestCreate(t *testing.T) {
 		ProductID: 8,
 	}
 
-	query := "INSERT INTO product_price\\(amount, price, product_id\\) VALUES\\(\\?, \\?, \\?\\) returning id"
+	query := "INSERT INTO product_price\\(amount, price, product_id, valid_from, valid_to\\) VALUES\\(\\?, \\?, \\?, \\?, \\?\\) returning id"
 	prep := mock.ExpectPrepare(query)
-	prep.ExpectExec().WithArgs(price.Amount, price.Price, price.ProductID).WillReturnResult(sqlmock.NewResult(6, 1))
+	prep.ExpectExec().WithArgs(price.Amount, price.Price, price.ProductID, sqlmock.AnyArg(), price.ValidTo).WillReturnResult(sqlmock.NewResult(6, 1))
 
 	p := repository.NewPGProductPriceRepository(db)
 
@@ -55,13 +57,13 @@ func TestGetByProductID(t *testing.T) {
 		},
 	}
 
-	rows := sqlmock.NewRows([]string{"id", "amount", "price", "product_id"}).
-		AddRow(mockProductPrice[0].ID, mockProductPrice[0].Amount, mockProductPrice[0].Price, mockProductPrice[0].ProductID).
-		AddRow(mockProductPrice[1].ID, mockProductPrice[1].Amount, mockProductPrice[1].Price, mockProductPrice[1].ProductID)
+	rows := sqlmock.NewRows([]string{"id", "amount", "price", "product_id", "valid_from", "valid_to"}).
+		AddRow(mockProductPrice[0].ID, mockProductPrice[0].Amount, mockProductPrice[0].Price, mockProductPrice[0].ProductID, mockProductPrice[0].ValidFrom, mockProductPrice[0].ValidTo).
+		AddRow(mockProductPrice[1].ID, mockProductPrice[1].Amount, mockProductPrice[1].Price, mockProductPrice[1].ProductID, mockProductPrice[1].ValidFrom, mockProductPrice[1].ValidTo)
 
-	query := "SELECT id, amount, price, product_id FROM product_price WHERE product_id = \\?"
+	query := "SELECT id, amount, price, product_id, valid_from, valid_to FROM product_price\\s+WHERE product_id = \\? AND valid_from <= \\? AND \\(valid_to IS NULL OR valid_to > \\?\\)"
 
-	mock.ExpectQuery(query).WithArgs(int64(8)).WillReturnRows(rows)
+	mock.ExpectQuery(query).WithArgs(int64(8), sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnRows(rows)
 
 	p := repository.NewPGProductPriceRepository(db)
 	prices, err := p.GetByProductID(context.TODO(), int64(8))
@@ -93,10 +95,10 @@ func TestGetPriceByAmount(t *testing.T) {
 		ProductID: 8,
 	}
 
-	rows := sqlmock.NewRows([]string{"id", "amount", "price", "product_id"}).
-		AddRow(mockProductPrice2.ID, mockProductPrice2.Amount, mockProductPrice2.Price, mockProductPrice2.ProductID)
+	rows := sqlmock.NewRows([]string{"id", "amount", "price", "product_id", "valid_from", "valid_to"}).
+		AddRow(mockProductPrice2.ID, mockProductPrice2.Amount, mockProductPrice2.Price, mockProductPrice2.ProductID, mockProductPrice2.ValidFrom, mockProductPrice2.ValidTo)
 
-	query := "SELECT id, amount, price, product_id FROM product_price WHERE amount < \\?"
+	query := "SELECT id, amount, price, product_id, valid_from, valid_to FROM product_price WHERE amount < \\?"
 
 	mock.ExpectQuery(query).WithArgs(int64(35)).WillReturnRows(rows)
 
@@ -111,19 +113,90 @@ func TestGetPriceByAmount(t *testing.T) {
 
 }
 
-func TestDeleteByProductID(t *testing.T) {
+func TestGetTimeline(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
 
-	query := "DELETE FROM product_price WHERE product_id = \\?"
+	friday := time.Date(2019, 11, 29, 0, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{"id", "amount", "price", "product_id", "valid_from", "valid_to"}).
+		AddRow(1, 1, 9000.0, 8, friday.AddDate(0, -1, 0), friday).
+		AddRow(2, 1, 7000.0, 8, friday, nil)
 
-	prep := mock.ExpectPrepare(query)
-	prep.ExpectExec().WithArgs(2).WillReturnResult(sqlmock.NewResult(2, 1))
+	query := "SELECT (.+) FROM product_price\\s+WHERE product_id = \\? AND \\(valid_to IS NULL OR valid_to > valid_from\\) ORDER BY valid_from, amount"
+	mock.ExpectQuery(query).WithArgs(int64(8)).WillReturnRows(rows)
+
+	p := repository.NewPGProductPriceRepository(db)
+	prices, err := p.GetTimeline(context.TODO(), int64(8))
+
+	assert.NoError(t, err)
+	assert.Len(t, prices, 2)
+	assert.True(t, prices[0].ValidTo.Valid)
+	assert.False(t, prices[1].ValidTo.Valid)
+}
+
+func TestGetPriceAt(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	at := time.Now().Add(-48 * time.Hour)
+	query := "SELECT (.+) FROM product_price\\s+WHERE product_id = \\? AND amount <= \\? AND valid_from <= \\? AND \\(valid_to IS NULL OR valid_to > \\?\\) ORDER BY amount DESC LIMIT 1"
+
+	t.Run("success", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "amount", "price", "product_id", "valid_from", "valid_to"}).
+			AddRow(4, 10, 8500.0, 8, at.Add(-time.Hour), nil)
+
+		mock.ExpectQuery(query).WithArgs(int64(8), int64(12), at, at).WillReturnRows(rows)
+
+		p := repository.NewPGProductPriceRepository(db)
+		price, err := p.GetPriceAt(context.TODO(), int64(8), int64(12), at)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 8500.0, price.Price)
+	})
+
+	t.Run("no price", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "amount", "price", "product_id", "valid_from", "valid_to"})
+
+		mock.ExpectQuery(query).WithArgs(int64(8), int64(1), at, at).WillReturnRows(rows)
+
+		p := repository.NewPGProductPriceRepository(db)
+		price, err := p.GetPriceAt(context.TODO(), int64(8), int64(1), at)
+
+		assert.Equal(t, models.ErrNotFound, err)
+		assert.Nil(t, price)
+	})
+}
+
+func TestSchedule(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	from := time.Date(2019, 11, 29, 0, 0, 0, 0, time.UTC)
+	next := from.AddDate(0, 0, 3)
+	prices := []*models.ProductPrice{
+		&models.ProductPrice{Amount: 1, Price: 7000.0},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE product_price SET valid_to = \\? WHERE product_id = \\? AND valid_from <= \\?").
+		WithArgs(from, int64(8), from, from).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectQuery("SELECT MIN\\(valid_from\\) FROM product_price WHERE product_id = \\? AND valid_from > \\?").
+		WithArgs(int64(8), from).WillReturnRows(sqlmock.NewRows([]string{"min"}).AddRow(next))
+	mock.ExpectPrepare("INSERT INTO product_price").ExpectExec().
+		WithArgs(1, 7000.0, int64(8), from, null.TimeFrom(next)).WillReturnResult(sqlmock.NewResult(11, 1))
+	mock.ExpectCommit()
 
 	p := repository.NewPGProductPriceRepository(db)
+	err = p.Schedule(context.TODO(), int64(8), prices, from)
 
-	err = p.DeleteByProductID(context.TODO(), int64(2))
 	assert.NoError(t, err)
+	assert.Equal(t, int64(11), prices[0].ID)
+	assert.Equal(t, next, prices[0].ValidTo.Time)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -6,13 +6,30 @@ import (
 	"testing"
 	"time"
 
+	"github.com/soerjadi/exam/audit/audittest"
+	eventMocks "github.com/soerjadi/exam/event/mocks"
 	"github.com/soerjadi/exam/models"
 	"github.com/soerjadi/exam/product_price/mocks"
 	"github.com/soerjadi/exam/product_price/usecase"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"gopkg.in/guregu/null.v3"
 )
 
+// inlineTx run the work straight away, the repositories are mocked
+type inlineTx struct{}
+
+func (inlineTx) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func newEventMock() *eventMocks.Usecase {
+	mockEvents := new(eventMocks.Usecase)
+	mockEvents.On("Emit", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("int64"), mock.Anything).Return(nil)
+
+	return mockEvents
+}
+
 func TestCreate(t *testing.T) {
 	mockProductPriceRepo := new(mocks.Repository)
 	mockProductPrice := models.ProductPrice{
@@ -26,7 +43,8 @@ func TestCreate(t *testing.T) {
 		tmpMockProductPrice.ID = 0
 		mockProductPriceRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.ProductPrice")).Return(nil).Once()
 
-		p := usecase.NewProductPriceUsecase(mockProductPriceRepo, time.Second*2)
+		mockAudit := audittest.NewUsecase()
+		p := usecase.NewProductPriceUsecase(mockProductPriceRepo, inlineTx{}, newEventMock(), mockAudit, time.Second*2)
 
 		err := p.Create(context.TODO(), &tmpMockProductPrice)
 
@@ -34,49 +52,107 @@ func TestCreate(t *testing.T) {
 		assert.Equal(t, mockProductPrice, tmpMockProductPrice)
 
 		mockProductPriceRepo.AssertExpectations(t)
+		audittest.AssertRecorded(t, mockAudit, models.AuditProductPrice, tmpMockProductPrice.ID, models.AuditCreate, nil, &tmpMockProductPrice)
 	})
 
 	t.Run("fail", func(t *testing.T) {
 		mockProductPriceRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.ProductPrice")).Return(errors.New("Unexpected")).Once()
 
-		p := usecase.NewProductPriceUsecase(mockProductPriceRepo, time.Second*2)
+		mockAudit := audittest.NewUsecase()
+		p := usecase.NewProductPriceUsecase(mockProductPriceRepo, inlineTx{}, newEventMock(), mockAudit, time.Second*2)
 
 		err := p.Create(context.TODO(), &mockProductPrice)
 
 		assert.Error(t, err)
+		audittest.AssertNothingRecorded(t, mockAudit)
 
 		mockProductPriceRepo.AssertExpectations(t)
 	})
 }
 
-func TestDeleteByProductID(t *testing.T) {
+func TestSchedule(t *testing.T) {
 	mockProductPriceRepo := new(mocks.Repository)
-	mockProductPrice := models.ProductPrice{
-		Amount:    10,
+	current := models.ProductPrice{
+		ID:        3,
+		Amount:    1,
 		Price:     9000.0,
 		ProductID: 2,
+		ValidFrom: time.Now().Add(-time.Hour),
 	}
+	from := time.Now().Add(24 * time.Hour)
 
 	t.Run("success", func(t *testing.T) {
-		mockProductPriceRepo.On("DeleteByProductID", mock.Anything, mock.AnythingOfType("int64")).Return(nil).Once()
+		prices := []*models.ProductPrice{
+			&models.ProductPrice{Amount: 1, Price: 7000.0},
+		}
+
+		mockProductPriceRepo.On("GetByProductIDAt", mock.Anything, current.ProductID, from).
+			Return([]*models.ProductPrice{&current}, nil).Once()
+		mockProductPriceRepo.On("Schedule", mock.Anything, current.ProductID, prices, from).Return(nil).Once()
 
-		p := usecase.NewProductPriceUsecase(mockProductPriceRepo, time.Second*2)
-		err := p.DeleteByProductID(context.TODO(), mockProductPrice.ProductID)
+		mockAudit := audittest.NewUsecase()
+		mockEvents := new(eventMocks.Usecase)
+		mockEvents.On("Emit", mock.Anything, models.EventPriceChanged, current.ProductID, &models.PriceChange{
+			ProductID: current.ProductID,
+			ValidFrom: from,
+			Prices:    prices,
+		}).Return(nil).Once()
+
+		p := usecase.NewProductPriceUsecase(mockProductPriceRepo, inlineTx{}, mockEvents, mockAudit, time.Second*2)
+		err := p.Schedule(context.TODO(), current.ProductID, prices, from)
 
 		assert.NoError(t, err)
+		mockAudit.AssertNumberOfCalls(t, "Record", 2)
+		closed := current
+		closed.ValidTo = null.TimeFrom(from)
+		audittest.AssertRecorded(t, mockAudit, models.AuditProductPrice, current.ID, models.AuditUpdate, &current, &closed)
+		audittest.AssertRecorded(t, mockAudit, models.AuditProductPrice, prices[0].ID, models.AuditCreate, nil, prices[0])
+		mockEvents.AssertExpectations(t)
 		mockProductPriceRepo.AssertExpectations(t)
 	})
 
-	t.Run("item is not exist", func(t *testing.T) {
-		mockProductPriceRepo.On("GetByProductID", mock.Anything, mock.AnythingOfType("int64")).Return(nil, models.ErrNotFound).Once()
+	t.Run("unchanged", func(t *testing.T) {
+		prices := []*models.ProductPrice{
+			&models.ProductPrice{Amount: 1, Price: 9000.0},
+		}
 
-		p := usecase.NewProductPriceUsecase(mockProductPriceRepo, time.Second*2)
+		mockProductPriceRepo.On("GetByProductIDAt", mock.Anything, current.ProductID, from).
+			Return([]*models.ProductPrice{&current}, nil).Once()
 
-		result, err := p.GetByProductID(context.TODO(), mockProductPrice.ProductID)
+		mockAudit := audittest.NewUsecase()
+		p := usecase.NewProductPriceUsecase(mockProductPriceRepo, inlineTx{}, newEventMock(), mockAudit, time.Second*2)
+		err := p.Schedule(context.TODO(), current.ProductID, prices, from)
 
-		assert.Error(t, err)
-		assert.Nil(t, result)
+		assert.NoError(t, err)
 		mockProductPriceRepo.AssertExpectations(t)
+		audittest.AssertNothingRecorded(t, mockAudit)
+	})
+
+	t.Run("in the past", func(t *testing.T) {
+		prices := []*models.ProductPrice{
+			&models.ProductPrice{Amount: 1, Price: 7000.0},
+		}
+
+		mockRepo := new(mocks.Repository)
+		mockAudit := audittest.NewUsecase()
+		p := usecase.NewProductPriceUsecase(mockRepo, inlineTx{}, newEventMock(), mockAudit, time.Second*2)
+		err := p.Schedule(context.TODO(), current.ProductID, prices, time.Now().Add(-time.Hour))
+
+		assert.Equal(t, models.ErrBadParamInput, err)
+		mockRepo.AssertNotCalled(t, "Schedule", mock.Anything, current.ProductID, prices, mock.Anything)
+		audittest.AssertNothingRecorded(t, mockAudit)
+	})
+
+	t.Run("duplicate amount", func(t *testing.T) {
+		prices := []*models.ProductPrice{
+			&models.ProductPrice{Amount: 1, Price: 7000.0},
+			&models.ProductPrice{Amount: 1, Price: 6000.0},
+		}
+
+		p := usecase.NewProductPriceUsecase(mockProductPriceRepo, inlineTx{}, newEventMock(), audittest.NewUsecase(), time.Second*2)
+		err := p.Schedule(context.TODO(), current.ProductID, prices, from)
+
+		assert.Equal(t, models.ErrBadParamInput, err)
 	})
 }
 
@@ -91,7 +167,7 @@ func TestGetByProductID(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mockProductPriceRepo.On("GetByProductID", mock.Anything, mock.AnythingOfType("int64")).Return([]*models.ProductPrice{&mockProductPrice}, nil).Once()
 
-		p := usecase.NewProductPriceUsecase(mockProductPriceRepo, time.Second*2)
+		p := usecase.NewProductPriceUsecase(mockProductPriceRepo, inlineTx{}, newEventMock(), audittest.NewUsecase(), time.Second*2)
 
 		result, err := p.GetByProductID(context.TODO(), mockProductPrice.ProductID)
 
@@ -118,7 +194,7 @@ func TestGetPriceByAmount(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mockProductPriceRepo.On("GetPriceByAmount", mock.Anything, mock.AnythingOfType("int64")).Return(&mockProductPrice2, nil).Once()
 
-		p := usecase.NewProductPriceUsecase(mockProductPriceRepo, time.Second*2)
+		p := usecase.NewProductPriceUsecase(mockProductPriceRepo, inlineTx{}, newEventMock(), audittest.NewUsecase(), time.Second*2)
 
 		result, err := p.GetPriceByAmount(context.TODO(), int64(24))
 
@@ -131,7 +207,7 @@ func TestGetPriceByAmount(t *testing.T) {
 	t.Run("fail", func(t *testing.T) {
 		mockProductPriceRepo.On("GetPriceByAmount", mock.Anything, mock.AnythingOfType("int64")).Return(&mockProductPrice2, nil).Once()
 
-		p := usecase.NewProductPriceUsecase(mockProductPriceRepo, time.Second*2)
+		p := usecase.NewProductPriceUsecase(mockProductPriceRepo, inlineTx{}, newEventMock(), audittest.NewUsecase(), time.Second*2)
 
 		result, err := p.GetPriceByAmount(context.TODO(), int64(24))
 
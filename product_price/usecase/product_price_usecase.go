@@ -4,47 +4,142 @@ import (
 	"context"
 	"time"
 
+	"github.com/soerjadi/exam/audit"
+	"github.com/soerjadi/exam/database"
+	"github.com/soerjadi/exam/event"
 	"github.com/soerjadi/exam/models"
 	productPrice "github.com/soerjadi/exam/product_price"
+	"github.com/soerjadi/exam/utils"
+	"gopkg.in/guregu/null.v3"
 )
 
 type productPriceUsecase struct {
 	repo           productPrice.Repository
+	tx             database.Transactor
+	events         event.Usecase
+	audit          audit.Usecase
 	contextTimeout time.Duration
 }
 
+var logger = utils.LogBuilder(true)
+
 // NewProductPriceUsecase will create object that represent of product price usecase interface
-func NewProductPriceUsecase(p productPrice.Repository, timeout time.Duration) productPrice.Usecase {
+func NewProductPriceUsecase(p productPrice.Repository, tx database.Transactor, e event.Usecase, a audit.Usecase, timeout time.Duration) productPrice.Usecase {
 	return &productPriceUsecase{
 		repo:           p,
+		tx:             tx,
+		events:         e,
+		audit:          a,
 		contextTimeout: timeout,
 	}
 }
 
+func (p *productPriceUsecase) record(ctx context.Context, id int64, action string, before interface{}, after interface{}) {
+	if err := p.audit.Record(ctx, models.AuditProductPrice, id, action, before, after); err != nil {
+		logger.Error(err)
+	}
+}
+
 func (p *productPriceUsecase) Create(ctx context.Context, price *models.ProductPrice) error {
 	ctx, cancel := context.WithTimeout(ctx, p.contextTimeout)
 	defer cancel()
 
-	err := p.repo.Create(ctx, price)
+	err := p.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := p.repo.Create(ctx, price); err != nil {
+			return err
+		}
+
+		return p.events.Emit(ctx, models.EventPriceChanged, price.ProductID, &models.PriceChange{
+			ProductID: price.ProductID,
+			ValidFrom: price.ValidFrom,
+			Prices:    []*models.ProductPrice{price},
+		})
+	})
 	if err != nil {
 		return err
 	}
 
+	p.record(ctx, price.ID, models.AuditCreate, nil, price)
 	return nil
 }
 
-func (p *productPriceUsecase) DeleteByProductID(ctx context.Context, id int64) error {
+// Schedule replace prices of a product starting at from, prices in effect
+// at that time are closed instead of deleted so the history is kept. A zero
+// from means now, the past can not be rewritten.
+func (p *productPriceUsecase) Schedule(ctx context.Context, productID int64, prices []*models.ProductPrice, from time.Time) error {
 	ctx, cancel := context.WithTimeout(ctx, p.contextTimeout)
 	defer cancel()
 
-	err := p.repo.DeleteByProductID(ctx, id)
+	now := time.Now()
+	if from.IsZero() {
+		from = now
+	}
+
+	if from.Before(now) {
+		return models.ErrBadParamInput
+	}
+
+	amounts := make(map[int64]float64)
+	for _, price := range prices {
+		if _, ok := amounts[price.Amount]; ok || price.Price < 0 {
+			return models.ErrBadParamInput
+		}
+
+		amounts[price.Amount] = price.Price
+	}
+
+	before, err := p.repo.GetByProductIDAt(ctx, productID, from)
 	if err != nil {
 		return err
 	}
 
+	// nothing change, keep the timeline free of identical entries
+	if samePrices(before, amounts) {
+		return nil
+	}
+
+	err = p.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := p.repo.Schedule(ctx, productID, prices, from); err != nil {
+			return err
+		}
+
+		return p.events.Emit(ctx, models.EventPriceChanged, productID, &models.PriceChange{
+			ProductID: productID,
+			ValidFrom: from,
+			Prices:    prices,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, price := range before {
+		after := *price
+		after.ValidTo = null.TimeFrom(from)
+		p.record(ctx, price.ID, models.AuditUpdate, price, &after)
+	}
+
+	for _, price := range prices {
+		p.record(ctx, price.ID, models.AuditCreate, nil, price)
+	}
+
 	return nil
 }
 
+func samePrices(prices []*models.ProductPrice, amounts map[int64]float64) bool {
+	if len(prices) != len(amounts) {
+		return false
+	}
+
+	for _, price := range prices {
+		if value, ok := amounts[price.Amount]; !ok || value != price.Price {
+			return false
+		}
+	}
+
+	return true
+}
+
 func (p *productPriceUsecase) GetByProductID(ctx context.Context, id int64) ([]*models.ProductPrice, error) {
 	ctx, cancel := context.WithTimeout(ctx, p.contextTimeout)
 	defer cancel()
@@ -57,6 +152,20 @@ func (p *productPriceUsecase) GetByProductID(ctx context.Context, id int64) ([]*
 	return prices, nil
 }
 
+func (p *productPriceUsecase) GetTimeline(ctx context.Context, id int64) ([]*models.ProductPrice, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.contextTimeout)
+	defer cancel()
+
+	return p.repo.GetTimeline(ctx, id)
+}
+
+func (p *productPriceUsecase) GetPriceAt(ctx context.Context, productID int64, amount int64, at time.Time) (*models.ProductPrice, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.contextTimeout)
+	defer cancel()
+
+	return p.repo.GetPriceAt(ctx, productID, amount, at)
+}
+
 func (p *productPriceUsecase) GetPriceByAmount(ctx context.Context, amount int64) (*models.ProductPrice, error) {
 	ctx, cancel := context.WithTimeout(ctx, p.contextTimeout)
 	defer cancel()
@@ -0,0 +1,227 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/promotion"
+	"github.com/soerjadi/exam/utils"
+	"gopkg.in/guregu/null.v3"
+)
+
+type promotionData struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	ProductID   null.Int  `json:"product_id"`
+	CategoryID  null.Int  `json:"category_id"`
+	MinQuantity int64     `json:"min_quantity"`
+	MinSubtotal float64   `json:"min_subtotal"`
+	StartAt     null.Time `json:"start_at"`
+	EndAt       null.Time `json:"end_at"`
+	Action      string    `json:"action"`
+	Value       float64   `json:"value"`
+	Priority    int       `json:"priority"`
+	Exclusive   bool      `json:"exclusive"`
+	Active      bool      `json:"active"`
+}
+
+func (d promotionData) toModel() models.Promotion {
+	return models.Promotion{
+		ID:          d.ID,
+		Name:        d.Name,
+		ProductID:   d.ProductID,
+		CategoryID:  d.CategoryID,
+		MinQuantity: d.MinQuantity,
+		MinSubtotal: d.MinSubtotal,
+		StartAt:     d.StartAt,
+		EndAt:       d.EndAt,
+		Action:      d.Action,
+		Value:       d.Value,
+		Priority:    d.Priority,
+		Exclusive:   d.Exclusive,
+		Active:      d.Active,
+	}
+}
+
+// PromotionHandler represent the http handler for promotion
+type PromotionHandler struct {
+	PromotionUsecase promotion.Usecase
+}
+
+// NewPromotionHandler initialize promotion resource endpoint
+func NewPromotionHandler(router *mux.Router, usecase promotion.Usecase) *mux.Router {
+	handler := &PromotionHandler{
+		PromotionUsecase: usecase,
+	}
+
+	p := router.PathPrefix("/v1/promotion").Subrouter()
+	p.HandleFunc("/add", handler.AddPromotion).Methods("POST")
+	p.HandleFunc("/update", handler.UpdatePromotion).Methods("POST")
+	p.HandleFunc("/detail", handler.GetByID).Methods("GET")
+	p.HandleFunc("/list", handler.GetList).Methods("GET")
+	p.HandleFunc("/delete", handler.Delete).Methods("GET")
+
+	return p
+}
+
+// AddPromotion will add promotion from given body to DB
+func (h *PromotionHandler) AddPromotion(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var data promotionData
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = json.Unmarshal(body, &data)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	promo := data.toModel()
+	promo.ID = 0
+
+	err = h.PromotionUsecase.Create(ctx, &promo)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, promo)
+}
+
+// UpdatePromotion will update promotion from given body
+func (h *PromotionHandler) UpdatePromotion(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var data promotionData
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = json.Unmarshal(body, &data)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	promo := data.toModel()
+	err = h.PromotionUsecase.Update(ctx, &promo)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, promo)
+}
+
+// GetByID get detail promotion from given ID
+func (h *PromotionHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	id, err := strconv.ParseInt(params.Get("id"), 0, 64)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	promo, err := h.PromotionUsecase.GetByID(ctx, id)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, promo)
+}
+
+// GetList list every promotion, newest first
+func (h *PromotionHandler) GetList(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	limit, err := strconv.ParseInt(params.Get("limit"), 0, 64)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	offset, err := strconv.ParseInt(params.Get("offset"), 0, 64)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if limit == 0 {
+		limit = 10
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	promotions, found, err := h.PromotionUsecase.GetList(ctx, offset, limit)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entriesResult := &utils.EntriesResponse{
+		Data:  promotions,
+		Found: found,
+	}
+
+	utils.JSON(w, http.StatusOK, entriesResult)
+}
+
+// Delete will delete promotion by given ID, orders keep the promotions applied to them
+func (h *PromotionHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	id, err := strconv.ParseInt(params.Get("id"), 0, 64)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	err = h.PromotionUsecase.Delete(ctx, id)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, "success")
+}
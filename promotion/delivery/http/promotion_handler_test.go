@@ -0,0 +1,92 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/soerjadi/exam/models"
+	promotionHttp "github.com/soerjadi/exam/promotion/delivery/http"
+	"github.com/soerjadi/exam/promotion/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAddPromotion(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("Create", mock.Anything, mock.MatchedBy(func(p *models.Promotion) bool {
+		return p.Action == models.PromotionPercentOff && p.CategoryID.Int64 == 3 && !p.ProductID.Valid
+	})).Return(nil)
+
+	body := `{"name":"10% off shoes","category_id":3,"action":"percent_off","value":10,"active":true}`
+	req, err := http.NewRequest("POST", "/v1/promotion/add", strings.NewReader(body))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	handler := promotionHttp.PromotionHandler{
+		PromotionUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.AddPromotion(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestAddPromotionInvalid(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("Create", mock.Anything, mock.AnythingOfType("*models.Promotion")).Return(models.ErrBadParamInput)
+
+	body := `{"name":"free money","action":"cashback","value":10}`
+	req, err := http.NewRequest("POST", "/v1/promotion/add", strings.NewReader(body))
+	assert.NoError(t, err)
+
+	handler := promotionHttp.PromotionHandler{
+		PromotionUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.AddPromotion(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestGetList(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("GetList", mock.Anything, int64(0), int64(10)).
+		Return([]*models.Promotion{&models.Promotion{ID: 1, Name: "10% off shoes"}}, int64(1), nil)
+
+	req, err := http.NewRequest("GET", "/v1/promotion/list?offset=0&limit=10", strings.NewReader(""))
+	assert.NoError(t, err)
+
+	handler := promotionHttp.PromotionHandler{
+		PromotionUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.GetList(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestDelete(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("Delete", mock.Anything, int64(5)).Return(nil)
+
+	req, err := http.NewRequest("GET", "/v1/promotion/delete?id=5", strings.NewReader(""))
+	assert.NoError(t, err)
+
+	handler := promotionHttp.PromotionHandler{
+		PromotionUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.Delete(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
@@ -0,0 +1,156 @@
+package promotion
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// Line is a product being priced together with the categories it belong to
+type Line struct {
+	ProductID   int64
+	CategoryIDs []int64
+	Quantity    int64
+	UnitPrice   float64
+}
+
+// Subtotal return price of the line before any discount
+func (l Line) Subtotal() float64 {
+	return l.UnitPrice * float64(l.Quantity)
+}
+
+func (l Line) inCategory(categoryID int64) bool {
+	for _, id := range l.CategoryIDs {
+		if id == categoryID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Evaluate apply promotions to lines in priority order and return the ones
+// that give a discount. An exclusive promotion stop the evaluation once it is
+// applied and the total discount never exceed the subtotal.
+func Evaluate(promotions []*models.Promotion, lines []Line, now time.Time) []*models.OrderPromotion {
+	sorted := make([]*models.Promotion, len(promotions))
+	copy(sorted, promotions)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Priority > sorted[j].Priority
+	})
+
+	var subtotal float64
+	for _, line := range lines {
+		subtotal += line.Subtotal()
+	}
+
+	applied := make([]*models.OrderPromotion, 0)
+	remaining := subtotal
+
+	for _, promo := range sorted {
+		if remaining <= 0 {
+			break
+		}
+
+		if !promo.Active || !inWindow(promo, now) || subtotal < promo.MinSubtotal {
+			continue
+		}
+
+		matched := matchLines(promo, lines)
+		if len(matched) == 0 {
+			continue
+		}
+
+		var quantity int64
+		for _, line := range matched {
+			quantity += line.Quantity
+		}
+
+		if quantity < promo.MinQuantity {
+			continue
+		}
+
+		discount := math.Min(discountOf(promo, matched), remaining)
+		if discount <= 0 {
+			continue
+		}
+
+		remaining -= discount
+		applied = append(applied, &models.OrderPromotion{
+			PromotionID: promo.ID,
+			Name:        promo.Name,
+			Discount:    round(discount),
+		})
+
+		if promo.Exclusive {
+			break
+		}
+	}
+
+	return applied
+}
+
+func inWindow(promo *models.Promotion, now time.Time) bool {
+	if promo.StartAt.Valid && now.Before(promo.StartAt.Time) {
+		return false
+	}
+
+	if promo.EndAt.Valid && !now.Before(promo.EndAt.Time) {
+		return false
+	}
+
+	return true
+}
+
+func matchLines(promo *models.Promotion, lines []Line) []Line {
+	matched := make([]Line, 0)
+	for _, line := range lines {
+		if promo.ProductID.Valid && line.ProductID != promo.ProductID.Int64 {
+			continue
+		}
+
+		if promo.CategoryID.Valid && !line.inCategory(promo.CategoryID.Int64) {
+			continue
+		}
+
+		matched = append(matched, line)
+	}
+
+	return matched
+}
+
+func discountOf(promo *models.Promotion, lines []Line) float64 {
+	var subtotal float64
+	for _, line := range lines {
+		subtotal += line.Subtotal()
+	}
+
+	switch promo.Action {
+	case models.PromotionPercentOff:
+		return subtotal * promo.Value / 100
+	case models.PromotionFixedOff:
+		return math.Min(promo.Value, subtotal)
+	case models.PromotionFreeItem:
+		// buy MinQuantity get Value free, counted per line
+		free := int64(promo.Value)
+		if promo.MinQuantity < 1 || free < 1 {
+			return 0
+		}
+
+		var discount float64
+		for _, line := range lines {
+			groups := line.Quantity / (promo.MinQuantity + free)
+			discount += float64(groups*free) * line.UnitPrice
+		}
+
+		return discount
+	}
+
+	return 0
+}
+
+func round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,18 @@
+package promotion
+
+import (
+	"context"
+	"time"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// Repository represent the promotion repository contract
+type Repository interface {
+	GetByID(ctx context.Context, id int64) (*models.Promotion, error)
+	GetList(ctx context.Context, offset int64, limit int64) ([]*models.Promotion, int64, error)
+	GetActive(ctx context.Context, now time.Time) ([]*models.Promotion, error)
+	Create(ctx context.Context, promotion *models.Promotion) error
+	Update(ctx context.Context, promotion *models.Promotion) error
+	Delete(ctx context.Context, id int64) error
+}
@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/promotion"
+	"github.com/soerjadi/exam/utils"
+)
+
+type pgPromotionRepository struct {
+	Conn *sql.DB
+}
+
+var logger = utils.LogBuilder(true)
+
+// NewPGPromotionRepository is bridge to create an object from promotion.Repository interface
+func NewPGPromotionRepository(Conn *sql.DB) promotion.Repository {
+	return &pgPromotionRepository{Conn}
+}
+
+func (p *pgPromotionRepository) fetch(ctx context.Context, query string, args ...interface{}) ([]*models.Promotion, error) {
+	rows, err := p.Conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Error(err)
+		}
+	}()
+
+	result := make([]*models.Promotion, 0)
+	for rows.Next() {
+		t := new(models.Promotion)
+
+		err = rows.Scan(
+			&t.ID,
+			&t.Name,
+			&t.ProductID,
+			&t.CategoryID,
+			&t.MinQuantity,
+			&t.MinSubtotal,
+			&t.StartAt,
+			&t.EndAt,
+			&t.Action,
+			&t.Value,
+			&t.Priority,
+			&t.Exclusive,
+			&t.Active,
+			&t.Created,
+			&t.Updated,
+		)
+
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+
+		result = append(result, t)
+	}
+
+	return result, nil
+}
+
+func (p *pgPromotionRepository) fetchRow(ctx context.Context, query string, args ...interface{}) (*sql.Row, error) {
+	stmt, err := p.Conn.PrepareContext(ctx, query)
+
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	defer func() {
+		err := stmt.Close()
+		if err != nil {
+			logger.Error(err)
+		}
+	}()
+
+	return stmt.QueryRow(args...), nil
+}
+
+func (p *pgPromotionRepository) GetByID(ctx context.Context, id int64) (*models.Promotion, error) {
+	query := `SELECT id, name, product_id, category_id, min_quantity, min_subtotal, start_at, end_at, action, value, priority, exclusive, active, created, updated
+		FROM promotions WHERE id = ?`
+
+	promotions, err := p.fetch(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(promotions) == 0 {
+		return nil, models.ErrNotFound
+	}
+
+	return promotions[0], nil
+}
+
+func (p *pgPromotionRepository) GetList(ctx context.Context, offset int64, limit int64) ([]*models.Promotion, int64, error) {
+	query := `SELECT id, name, product_id, category_id, min_quantity, min_subtotal, start_at, end_at, action, value, priority, exclusive, active, created, updated
+		FROM promotions ORDER BY created DESC LIMIT ? OFFSET ?`
+	qCount := `SELECT count(id) FROM promotions`
+
+	result, err := p.fetch(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := p.fetchRow(ctx, qCount)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var count int64
+	err = rows.Scan(&count)
+
+	if err != nil {
+		logger.Error(err)
+		return nil, 0, err
+	}
+
+	return result, count, nil
+}
+
+func (p *pgPromotionRepository) GetActive(ctx context.Context, now time.Time) ([]*models.Promotion, error) {
+	query := `SELECT id, name, product_id, category_id, min_quantity, min_subtotal, start_at, end_at, action, value, priority, exclusive, active, created, updated
+		FROM promotions WHERE active = true AND (start_at IS NULL OR start_at <= ?) AND (end_at IS NULL OR end_at > ?)
+		ORDER BY priority DESC, id`
+
+	return p.fetch(ctx, query, now, now)
+}
+
+func (p *pgPromotionRepository) Create(ctx context.Context, promo *models.Promotion) error {
+	query := `INSERT INTO promotions(name, product_id, category_id, min_quantity, min_subtotal, start_at, end_at, action, value, priority, exclusive, active)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) returning id`
+	stmt, err := p.Conn.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	result, err := stmt.ExecContext(ctx, promo.Name, promo.ProductID, promo.CategoryID, promo.MinQuantity, promo.MinSubtotal,
+		promo.StartAt, promo.EndAt, promo.Action, promo.Value, promo.Priority, promo.Exclusive, promo.Active)
+	if err != nil {
+		return err
+	}
+
+	lastID, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	promo.ID = lastID
+	return nil
+}
+
+func (p *pgPromotionRepository) Update(ctx context.Context, promo *models.Promotion) error {
+	query := `UPDATE promotions SET name = ?, product_id = ?, category_id = ?, min_quantity = ?, min_subtotal = ?, start_at = ?, end_at = ?,
+		action = ?, value = ?, priority = ?, exclusive = ?, active = ?, updated = ? WHERE id = ?`
+
+	stmt, err := p.Conn.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	res, err := stmt.ExecContext(ctx, promo.Name, promo.ProductID, promo.CategoryID, promo.MinQuantity, promo.MinSubtotal,
+		promo.StartAt, promo.EndAt, promo.Action, promo.Value, promo.Priority, promo.Exclusive, promo.Active, promo.Updated, promo.ID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected != 1 {
+		return models.ErrNotFound
+	}
+
+	return nil
+}
+
+func (p *pgPromotionRepository) Delete(ctx context.Context, id int64) error {
+	query := `DELETE FROM promotions WHERE id = ?`
+
+	stmt, err := p.Conn.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	res, err := stmt.ExecContext(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected != 1 {
+		return fmt.Errorf("Total affected: %d", affected)
+	}
+
+	return nil
+}
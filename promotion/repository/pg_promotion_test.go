@@ -0,0 +1,153 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/promotion/repository"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v3"
+)
+
+var columns = []string{"id", "name", "product_id", "category_id", "min_quantity", "min_subtotal", "start_at", "end_at",
+	"action", "value", "priority", "exclusive", "active", "created", "updated"}
+
+func TestGetByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows(columns).
+		AddRow(1, "10% off shoes", nil, 3, 0, 0.0, nil, nil, models.PromotionPercentOff, 10.0, 0, false, true, time.Now(), nil)
+
+	query := "SELECT (.+) FROM promotions WHERE id = \\?"
+	mock.ExpectQuery(query).WithArgs(int64(1)).WillReturnRows(rows)
+
+	p := repository.NewPGPromotionRepository(db)
+	promo, err := p.GetByID(context.TODO(), int64(1))
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), promo.CategoryID.Int64)
+	assert.False(t, promo.ProductID.Valid)
+}
+
+func TestGetList(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows(columns).
+		AddRow(1, "10% off shoes", nil, 3, 0, 0.0, nil, nil, models.PromotionPercentOff, 10.0, 0, false, true, time.Now(), nil).
+		AddRow(2, "buy 2 get 1", 8, nil, 2, 0.0, nil, nil, models.PromotionFreeItem, 1.0, 0, false, true, time.Now(), nil)
+
+	query := "SELECT (.+) FROM promotions ORDER BY created DESC LIMIT \\? OFFSET \\?"
+	mock.ExpectQuery(query).WithArgs(int64(10), int64(0)).WillReturnRows(rows)
+	mock.ExpectPrepare("SELECT count\\(id\\) FROM promotions").ExpectQuery().WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+	p := repository.NewPGPromotionRepository(db)
+	promotions, found, err := p.GetList(context.TODO(), int64(0), int64(10))
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), found)
+	assert.Len(t, promotions, 2)
+}
+
+func TestGetActive(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Now()
+	rows := sqlmock.NewRows(columns).
+		AddRow(1, "10% off shoes", nil, 3, 0, 0.0, now.Add(-time.Hour), now.Add(time.Hour), models.PromotionPercentOff, 10.0, 0, false, true, now, nil)
+
+	query := "SELECT (.+) FROM promotions WHERE active = true AND \\(start_at IS NULL OR start_at <= \\?\\) AND \\(end_at IS NULL OR end_at > \\?\\)"
+	mock.ExpectQuery(query).WithArgs(now, now).WillReturnRows(rows)
+
+	p := repository.NewPGPromotionRepository(db)
+	promotions, err := p.GetActive(context.TODO(), now)
+
+	assert.NoError(t, err)
+	assert.Len(t, promotions, 1)
+}
+
+func TestCreate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	promo := &models.Promotion{
+		Name:       "10% off shoes",
+		CategoryID: null.IntFrom(3),
+		Action:     models.PromotionPercentOff,
+		Value:      10,
+		Active:     true,
+	}
+
+	query := "INSERT INTO promotions\\(name, product_id, category_id, (.+)\\) returning id"
+	mock.ExpectPrepare(query).ExpectExec().
+		WithArgs(promo.Name, promo.ProductID, promo.CategoryID, promo.MinQuantity, promo.MinSubtotal, promo.StartAt, promo.EndAt,
+			promo.Action, promo.Value, promo.Priority, promo.Exclusive, promo.Active).
+		WillReturnResult(sqlmock.NewResult(5, 1))
+
+	p := repository.NewPGPromotionRepository(db)
+	err = p.Create(context.TODO(), promo)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), promo.ID)
+}
+
+func TestUpdate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	promo := &models.Promotion{
+		ID:     5,
+		Name:   "15% off shoes",
+		Action: models.PromotionPercentOff,
+		Value:  15,
+	}
+
+	query := "UPDATE promotions SET name = \\?, (.+) WHERE id = \\?"
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectPrepare(query).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+
+		p := repository.NewPGPromotionRepository(db)
+		err = p.Update(context.TODO(), promo)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mock.ExpectPrepare(query).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 0))
+
+		p := repository.NewPGPromotionRepository(db)
+		err = p.Update(context.TODO(), promo)
+
+		assert.Equal(t, models.ErrNotFound, err)
+	})
+}
+
+func TestDelete(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectPrepare("DELETE FROM promotions WHERE id = \\?").ExpectExec().WithArgs(int64(5)).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	p := repository.NewPGPromotionRepository(db)
+	err = p.Delete(context.TODO(), int64(5))
+
+	assert.NoError(t, err)
+}
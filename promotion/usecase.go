@@ -0,0 +1,18 @@
+package promotion
+
+import (
+	"context"
+	"time"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// Usecase represent the promotion usecase
+type Usecase interface {
+	GetByID(ctx context.Context, id int64) (*models.Promotion, error)
+	GetList(ctx context.Context, offset int64, limit int64) ([]*models.Promotion, int64, error)
+	Create(ctx context.Context, promotion *models.Promotion) error
+	Update(ctx context.Context, promotion *models.Promotion) error
+	Delete(ctx context.Context, id int64) error
+	Evaluate(ctx context.Context, lines []Line, now time.Time) ([]*models.OrderPromotion, error)
+}
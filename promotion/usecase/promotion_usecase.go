@@ -0,0 +1,181 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/soerjadi/exam/audit"
+	"github.com/soerjadi/exam/models"
+	cat "github.com/soerjadi/exam/product_category"
+	"github.com/soerjadi/exam/promotion"
+	"github.com/soerjadi/exam/utils"
+	"gopkg.in/guregu/null.v3"
+)
+
+type promotionUsecase struct {
+	repo           promotion.Repository
+	pcRepo         cat.Repository
+	audit          audit.Usecase
+	contextTimeout time.Duration
+}
+
+var logger = utils.LogBuilder(true)
+
+// NewPromotionUsecase will create object that represent of promotion.Usecase interface
+func NewPromotionUsecase(p promotion.Repository, pc cat.Repository, a audit.Usecase, timeout time.Duration) promotion.Usecase {
+	return &promotionUsecase{
+		repo:           p,
+		pcRepo:         pc,
+		audit:          a,
+		contextTimeout: timeout,
+	}
+}
+
+func (p *promotionUsecase) record(ctx context.Context, id int64, action string, before interface{}, after interface{}) {
+	if err := p.audit.Record(ctx, models.AuditPromotion, id, action, before, after); err != nil {
+		logger.Error(err)
+	}
+}
+
+// validate reject promotions the engine would never be able to apply
+func validate(promo *models.Promotion) error {
+	if promo.Name == "" || promo.Value <= 0 || promo.MinQuantity < 0 || promo.MinSubtotal < 0 {
+		return models.ErrBadParamInput
+	}
+
+	switch promo.Action {
+	case models.PromotionPercentOff:
+		if promo.Value > 100 {
+			return models.ErrBadParamInput
+		}
+	case models.PromotionFixedOff:
+	case models.PromotionFreeItem:
+		if promo.MinQuantity < 1 || promo.Value != math.Trunc(promo.Value) {
+			return models.ErrBadParamInput
+		}
+	default:
+		return models.ErrBadParamInput
+	}
+
+	if promo.StartAt.Valid && promo.EndAt.Valid && !promo.StartAt.Time.Before(promo.EndAt.Time) {
+		return models.ErrBadParamInput
+	}
+
+	return nil
+}
+
+func (p *promotionUsecase) GetByID(ctx context.Context, id int64) (*models.Promotion, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.contextTimeout)
+	defer cancel()
+
+	return p.repo.GetByID(ctx, id)
+}
+
+func (p *promotionUsecase) GetList(ctx context.Context, offset int64, limit int64) ([]*models.Promotion, int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.contextTimeout)
+	defer cancel()
+
+	promotions, found, err := p.repo.GetList(ctx, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return promotions, found, nil
+}
+
+func (p *promotionUsecase) Create(ctx context.Context, promo *models.Promotion) error {
+	ctx, cancel := context.WithTimeout(ctx, p.contextTimeout)
+	defer cancel()
+
+	if err := validate(promo); err != nil {
+		return err
+	}
+
+	err := p.repo.Create(ctx, promo)
+	if err != nil {
+		return err
+	}
+
+	p.record(ctx, promo.ID, models.AuditCreate, nil, promo)
+	return nil
+}
+
+func (p *promotionUsecase) Update(ctx context.Context, promo *models.Promotion) error {
+	ctx, cancel := context.WithTimeout(ctx, p.contextTimeout)
+	defer cancel()
+
+	if err := validate(promo); err != nil {
+		return err
+	}
+
+	before, err := p.repo.GetByID(ctx, promo.ID)
+	if err != nil {
+		return err
+	}
+
+	promo.Created = before.Created
+	promo.Updated = null.NewTime(
+		time.Now(), true,
+	)
+
+	err = p.repo.Update(ctx, promo)
+	if err != nil {
+		return err
+	}
+
+	p.record(ctx, promo.ID, models.AuditUpdate, before, promo)
+	return nil
+}
+
+func (p *promotionUsecase) Delete(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, p.contextTimeout)
+	defer cancel()
+
+	exists, err := p.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = p.repo.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	p.record(ctx, id, models.AuditDelete, exists, nil)
+	return nil
+}
+
+// Evaluate run every promotion active at now against lines, categories of
+// lines are looked up when the caller did not provide them.
+func (p *promotionUsecase) Evaluate(ctx context.Context, lines []promotion.Line, now time.Time) ([]*models.OrderPromotion, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.contextTimeout)
+	defer cancel()
+
+	active, err := p.repo.GetActive(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(active) == 0 {
+		return make([]*models.OrderPromotion, 0), nil
+	}
+
+	for i := range lines {
+		if lines[i].CategoryIDs != nil {
+			continue
+		}
+
+		pCats, err := p.pcRepo.GetByProductID(ctx, lines[i].ProductID)
+		if err != nil {
+			return nil, err
+		}
+
+		lines[i].CategoryIDs = make([]int64, 0, len(pCats))
+		for _, pCat := range pCats {
+			lines[i].CategoryIDs = append(lines[i].CategoryIDs, pCat.CategoryID)
+		}
+	}
+
+	return promotion.Evaluate(active, lines, now), nil
+}
@@ -0,0 +1,191 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/soerjadi/exam/audit/audittest"
+	"github.com/soerjadi/exam/models"
+	catMocks "github.com/soerjadi/exam/product_category/mocks"
+	"github.com/soerjadi/exam/promotion"
+	"github.com/soerjadi/exam/promotion/mocks"
+	"github.com/soerjadi/exam/promotion/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gopkg.in/guregu/null.v3"
+)
+
+func TestCreate(t *testing.T) {
+	mockPromotionRepo := new(mocks.Repository)
+
+	t.Run("success", func(t *testing.T) {
+		promo := models.Promotion{
+			Name:        "buy 2 get 1",
+			ProductID:   null.IntFrom(8),
+			MinQuantity: 2,
+			Action:      models.PromotionFreeItem,
+			Value:       1,
+			Active:      true,
+		}
+
+		mockPromotionRepo.On("Create", mock.Anything, &promo).Return(nil).Once()
+
+		mockAudit := audittest.NewUsecase()
+		p := usecase.NewPromotionUsecase(mockPromotionRepo, new(catMocks.Repository), mockAudit, time.Second*2)
+		err := p.Create(context.TODO(), &promo)
+
+		assert.NoError(t, err)
+		mockPromotionRepo.AssertExpectations(t)
+		audittest.AssertRecorded(t, mockAudit, models.AuditPromotion, promo.ID, models.AuditCreate, nil, &promo)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		mockAudit := audittest.NewUsecase()
+		p := usecase.NewPromotionUsecase(mockPromotionRepo, new(catMocks.Repository), mockAudit, time.Second*2)
+
+		invalid := []models.Promotion{
+			{Name: "more than everything", Action: models.PromotionPercentOff, Value: 120},
+			{Name: "free without buying", Action: models.PromotionFreeItem, Value: 1},
+			{Name: "unknown", Action: "cashback", Value: 5},
+			{Name: "ended before started", Action: models.PromotionFixedOff, Value: 5,
+				StartAt: null.TimeFrom(time.Now()), EndAt: null.TimeFrom(time.Now().Add(-time.Hour))},
+		}
+
+		for _, promo := range invalid {
+			err := p.Create(context.TODO(), &promo)
+			assert.Equal(t, models.ErrBadParamInput, err, promo.Name)
+		}
+		audittest.AssertNothingRecorded(t, mockAudit)
+	})
+}
+
+func TestUpdate(t *testing.T) {
+	mockPromotionRepo := new(mocks.Repository)
+	before := models.Promotion{
+		ID:      5,
+		Name:    "10% off",
+		Action:  models.PromotionPercentOff,
+		Value:   10,
+		Created: time.Now().Add(-time.Hour),
+	}
+	promo := before
+	promo.Value = 15
+	promo.Created = time.Time{}
+
+	mockPromotionRepo.On("GetByID", mock.Anything, before.ID).Return(&before, nil).Once()
+	mockPromotionRepo.On("Update", mock.Anything, &promo).Return(nil).Once()
+
+	mockAudit := audittest.NewUsecase()
+	p := usecase.NewPromotionUsecase(mockPromotionRepo, new(catMocks.Repository), mockAudit, time.Second*2)
+	err := p.Update(context.TODO(), &promo)
+
+	assert.NoError(t, err)
+	assert.Equal(t, before.Created, promo.Created)
+	assert.True(t, promo.Updated.Valid)
+	mockPromotionRepo.AssertExpectations(t)
+	audittest.AssertRecorded(t, mockAudit, models.AuditPromotion, before.ID, models.AuditUpdate, &before, &promo)
+}
+
+func TestDelete(t *testing.T) {
+	mockPromotionRepo := new(mocks.Repository)
+	promo := models.Promotion{ID: 5, Name: "10% off"}
+
+	mockPromotionRepo.On("GetByID", mock.Anything, promo.ID).Return(&promo, nil).Once()
+	mockPromotionRepo.On("Delete", mock.Anything, promo.ID).Return(nil).Once()
+
+	mockAudit := audittest.NewUsecase()
+	p := usecase.NewPromotionUsecase(mockPromotionRepo, new(catMocks.Repository), mockAudit, time.Second*2)
+	err := p.Delete(context.TODO(), promo.ID)
+
+	assert.NoError(t, err)
+	mockPromotionRepo.AssertExpectations(t)
+	audittest.AssertRecorded(t, mockAudit, models.AuditPromotion, promo.ID, models.AuditDelete, &promo, nil)
+}
+
+func TestEvaluate(t *testing.T) {
+	now := time.Now()
+	shoes := int64(3)
+
+	percentOffShoes := &models.Promotion{
+		ID: 1, Name: "10% off shoes", CategoryID: null.IntFrom(shoes),
+		Action: models.PromotionPercentOff, Value: 10, Active: true,
+	}
+	buy2get1 := &models.Promotion{
+		ID: 2, Name: "buy 2 get 1", ProductID: null.IntFrom(8), MinQuantity: 2,
+		Action: models.PromotionFreeItem, Value: 1, Active: true, Priority: 10,
+	}
+	bigSpender := &models.Promotion{
+		ID: 3, Name: "5000 off above 100000", MinSubtotal: 100000,
+		Action: models.PromotionFixedOff, Value: 5000, Active: true,
+	}
+	expired := &models.Promotion{
+		ID: 4, Name: "last week sale", EndAt: null.TimeFrom(now.Add(-time.Hour)),
+		Action: models.PromotionPercentOff, Value: 50, Active: true,
+	}
+	exclusive := &models.Promotion{
+		ID: 5, Name: "staff discount", Action: models.PromotionPercentOff, Value: 30,
+		Active: true, Exclusive: true, Priority: 20,
+	}
+
+	t.Run("category percent off", func(t *testing.T) {
+		mockPromotionRepo := new(mocks.Repository)
+		mockPCRepo := new(catMocks.Repository)
+		mockPromotionRepo.On("GetActive", mock.Anything, now).Return([]*models.Promotion{percentOffShoes}, nil).Once()
+		mockPCRepo.On("GetByProductID", mock.Anything, int64(8)).
+			Return([]*models.ProductCategory{&models.ProductCategory{ProductID: 8, CategoryID: shoes}}, nil).Once()
+
+		p := usecase.NewPromotionUsecase(mockPromotionRepo, mockPCRepo, audittest.NewUsecase(), time.Second*2)
+		applied, err := p.Evaluate(context.TODO(), []promotion.Line{{ProductID: 8, Quantity: 2, UnitPrice: 25000}}, now)
+
+		assert.NoError(t, err)
+		assert.Len(t, applied, 1)
+		assert.Equal(t, 5000.0, applied[0].Discount)
+		mockPCRepo.AssertExpectations(t)
+	})
+
+	t.Run("buy 2 get 1 free stack with subtotal discount", func(t *testing.T) {
+		mockPromotionRepo := new(mocks.Repository)
+		mockPromotionRepo.On("GetActive", mock.Anything, now).
+			Return([]*models.Promotion{bigSpender, buy2get1, expired}, nil).Once()
+
+		p := usecase.NewPromotionUsecase(mockPromotionRepo, new(catMocks.Repository), audittest.NewUsecase(), time.Second*2)
+		lines := []promotion.Line{{ProductID: 8, CategoryIDs: []int64{}, Quantity: 7, UnitPrice: 20000}}
+		applied, err := p.Evaluate(context.TODO(), lines, now)
+
+		assert.NoError(t, err)
+		assert.Len(t, applied, 2)
+		assert.Equal(t, buy2get1.ID, applied[0].PromotionID)
+		assert.Equal(t, 40000.0, applied[0].Discount)
+		assert.Equal(t, bigSpender.ID, applied[1].PromotionID)
+		assert.Equal(t, 5000.0, applied[1].Discount)
+	})
+
+	t.Run("exclusive stop evaluation", func(t *testing.T) {
+		mockPromotionRepo := new(mocks.Repository)
+		mockPromotionRepo.On("GetActive", mock.Anything, now).
+			Return([]*models.Promotion{buy2get1, exclusive}, nil).Once()
+
+		p := usecase.NewPromotionUsecase(mockPromotionRepo, new(catMocks.Repository), audittest.NewUsecase(), time.Second*2)
+		lines := []promotion.Line{{ProductID: 8, CategoryIDs: []int64{}, Quantity: 3, UnitPrice: 10000}}
+		applied, err := p.Evaluate(context.TODO(), lines, now)
+
+		assert.NoError(t, err)
+		assert.Len(t, applied, 1)
+		assert.Equal(t, exclusive.ID, applied[0].PromotionID)
+		assert.Equal(t, 9000.0, applied[0].Discount)
+	})
+
+	t.Run("below minimum", func(t *testing.T) {
+		mockPromotionRepo := new(mocks.Repository)
+		mockPromotionRepo.On("GetActive", mock.Anything, now).
+			Return([]*models.Promotion{bigSpender, buy2get1}, nil).Once()
+
+		p := usecase.NewPromotionUsecase(mockPromotionRepo, new(catMocks.Repository), audittest.NewUsecase(), time.Second*2)
+		lines := []promotion.Line{{ProductID: 8, CategoryIDs: []int64{}, Quantity: 2, UnitPrice: 20000}}
+		applied, err := p.Evaluate(context.TODO(), lines, now)
+
+		assert.NoError(t, err)
+		assert.Len(t, applied, 0)
+	})
+}
@@ -0,0 +1,80 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/soerjadi/exam/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTake(t *testing.T) {
+	// 10 requests a minute is one every 6 seconds, up to 3 at once
+	limit := ratelimit.Limit{Requests: 10, Per: time.Minute, Burst: 3}
+	store := ratelimit.NewMemoryStore()
+	now := time.Now()
+
+	t.Run("burst", func(t *testing.T) {
+		for i := 2; i >= 0; i-- {
+			result, err := store.Take(context.TODO(), "auth|ip:10.0.0.1", limit, now)
+
+			assert.NoError(t, err)
+			assert.True(t, result.Allowed)
+			assert.Equal(t, 3, result.Limit)
+			assert.Equal(t, i, result.Remaining)
+			assert.Zero(t, result.RetryAfter)
+		}
+
+		result, err := store.Take(context.TODO(), "auth|ip:10.0.0.1", limit, now)
+
+		assert.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, 6*time.Second, result.RetryAfter)
+		assert.Equal(t, 18*time.Second, result.Reset)
+	})
+
+	t.Run("other key has its own bucket", func(t *testing.T) {
+		result, err := store.Take(context.TODO(), "auth|ip:10.0.0.2", limit, now)
+
+		assert.NoError(t, err)
+		assert.True(t, result.Allowed)
+	})
+
+	t.Run("refill", func(t *testing.T) {
+		result, err := store.Take(context.TODO(), "auth|ip:10.0.0.1", limit, now.Add(3*time.Second))
+		assert.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, 3*time.Second, result.RetryAfter)
+
+		result, err = store.Take(context.TODO(), "auth|ip:10.0.0.1", limit, now.Add(6*time.Second))
+		assert.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 0, result.Remaining)
+	})
+
+	t.Run("never more than the burst", func(t *testing.T) {
+		later := now.Add(time.Hour)
+		for i := 0; i < 3; i++ {
+			result, err := store.Take(context.TODO(), "auth|ip:10.0.0.1", limit, later)
+			assert.NoError(t, err)
+			assert.True(t, result.Allowed)
+		}
+
+		result, err := store.Take(context.TODO(), "auth|ip:10.0.0.1", limit, later)
+		assert.NoError(t, err)
+		assert.False(t, result.Allowed)
+	})
+}
+
+func TestSweep(t *testing.T) {
+	limit := ratelimit.Limit{Requests: 10, Per: time.Minute}
+	store := ratelimit.NewMemoryStore()
+	now := time.Now()
+
+	store.Take(context.TODO(), "default|ip:10.0.0.1", limit, now.Add(-time.Hour))
+	store.Take(context.TODO(), "default|ip:10.0.0.2", limit, now)
+
+	assert.Equal(t, 1, store.Sweep(now, 10*time.Minute))
+	assert.Equal(t, 0, store.Sweep(now, 10*time.Minute))
+}
@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Limit is a token bucket allowing Requests every Per on average, with bursts
+// of up to Burst requests. The zero Limit does not limit anything.
+type Limit struct {
+	Requests int
+	Per      time.Duration
+	Burst    int
+}
+
+// Unlimited tell whether l let every request through
+func (l Limit) Unlimited() bool {
+	return l.Requests <= 0 || l.Per <= 0
+}
+
+// Capacity is the size of the bucket, Requests when Burst is not set
+func (l Limit) Capacity() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+
+	return l.Requests
+}
+
+// rate return how many tokens are added back every second
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Per.Seconds()
+}
+
+// Result of taking a token from a bucket
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter is how long to wait before a request is allowed again, zero
+	// when it was allowed
+	RetryAfter time.Duration
+	// Reset is how long until the bucket is full again
+	Reset time.Duration
+}
+
+// Store keep the buckets. MemoryStore keep them in the process, a store
+// shared by several instances must take the token atomically.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error)
+}
+
+// Policy is the limits of a group of routes, by kind of client. Anonymous
+// clients are limited by IP, signed in customers and API keys each by their
+// own bucket.
+type Policy struct {
+	Name     string
+	Prefix   string
+	IP       Limit
+	Customer Limit
+	Key      Limit
+	// AlwaysIP count the requests of customers and API keys against the IP
+	// limit too, e.g. for routes guessing passwords through
+	AlwaysIP bool
+}
+
+// Match return the first policy whose prefix path start with
+func Match(policies []Policy, path string) (Policy, bool) {
+	for _, policy := range policies {
+		if strings.HasPrefix(path, policy.Prefix) {
+			return policy, true
+		}
+	}
+
+	return Policy{}, false
+}
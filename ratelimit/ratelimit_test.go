@@ -0,0 +1,46 @@
+package ratelimit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/soerjadi/exam/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimit(t *testing.T) {
+	assert.True(t, ratelimit.Limit{}.Unlimited())
+	assert.True(t, ratelimit.Limit{Requests: 10}.Unlimited())
+	assert.False(t, ratelimit.Limit{Requests: 10, Per: time.Minute}.Unlimited())
+
+	assert.Equal(t, 10, ratelimit.Limit{Requests: 10, Per: time.Minute}.Capacity())
+	assert.Equal(t, 5, ratelimit.Limit{Requests: 10, Per: time.Minute, Burst: 5}.Capacity())
+}
+
+func TestMatch(t *testing.T) {
+	policies := []ratelimit.Policy{
+		{Name: "auth", Prefix: "/v1/customer/login"},
+		{Name: "admin", Prefix: "/v1/admin/"},
+		{Name: "default", Prefix: "/"},
+	}
+
+	tests := []struct {
+		path string
+		name string
+	}{
+		{"/v1/customer/login", "auth"},
+		{"/v1/admin/apikey/add", "admin"},
+		{"/v1/admin", "default"},
+		{"/v1/product/list", "default"},
+	}
+
+	for _, test := range tests {
+		policy, ok := ratelimit.Match(policies, test.path)
+
+		assert.True(t, ok, test.path)
+		assert.Equal(t, test.name, policy.Name, test.path)
+	}
+
+	_, ok := ratelimit.Match(policies[:2], "/v1/product/list")
+	assert.False(t, ok)
+}
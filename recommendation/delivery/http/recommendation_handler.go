@@ -0,0 +1,73 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/recommendation"
+	"github.com/soerjadi/exam/utils"
+)
+
+// defaultLimit is how many related products are listed when limit is not given
+const defaultLimit = 8
+
+// RecommendationHandler represent the http handler for related products
+type RecommendationHandler struct {
+	RecommendationUsecase recommendation.Usecase
+}
+
+// NewRecommendationHandler initialize related products endpoint
+func NewRecommendationHandler(router *mux.Router, usecase recommendation.Usecase) *mux.Router {
+	handler := &RecommendationHandler{
+		RecommendationUsecase: usecase,
+	}
+
+	router.HandleFunc("/v1/product/{id:[0-9]+}/related", handler.Related).Methods("GET")
+
+	return router
+}
+
+// Related endpoint to list the products frequently bought with a product
+func (h *RecommendationHandler) Related(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 0, 64)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	limit := int64(defaultLimit)
+	if value := r.URL.Query().Get("limit"); value != "" {
+		limit, err = strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			utils.Error(w, http.StatusBadRequest, models.ErrBadParamInput.Error())
+			return
+		}
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	related, err := h.RecommendationUsecase.Related(ctx, id, limit)
+
+	if err == models.ErrNotFound {
+		utils.Error(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entriesResult := &utils.EntriesResponse{
+		Data:  related,
+		Found: int64(len(related)),
+	}
+
+	utils.JSON(w, http.StatusOK, entriesResult)
+}
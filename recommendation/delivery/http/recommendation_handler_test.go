@@ -0,0 +1,55 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/soerjadi/exam/models"
+	recHttp "github.com/soerjadi/exam/recommendation/delivery/http"
+	"github.com/soerjadi/exam/recommendation/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRelated(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("Related", mock.Anything, int64(1), int64(8)).Return([]*models.RelatedProduct{
+		&models.RelatedProduct{ProductID: 2, Name: "Celana", Score: 12, Source: models.RelatedBoughtTogether},
+	}, nil)
+
+	req, err := http.NewRequest("GET", "/v1/product/1/related", nil)
+	assert.NoError(t, err)
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+
+	handler := recHttp.RecommendationHandler{
+		RecommendationUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.Related(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"source":"bought_together"`)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestRelatedNotFound(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("Related", mock.Anything, int64(9), int64(4)).Return(nil, models.ErrNotFound)
+
+	req, err := http.NewRequest("GET", "/v1/product/9/related?limit=4", nil)
+	assert.NoError(t, err)
+	req = mux.SetURLVars(req, map[string]string{"id": "9"})
+
+	handler := recHttp.RecommendationHandler{
+		RecommendationUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.Related(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
@@ -0,0 +1,16 @@
+package recommendation
+
+import (
+	"context"
+	"time"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// Repository represent the related products repository contract, only
+// published products are returned
+type Repository interface {
+	GetBoughtTogether(ctx context.Context, productID int64, limit int64) ([]*models.RelatedProduct, error)
+	GetSameCategory(ctx context.Context, productID int64, limit int64) ([]*models.RelatedProduct, error)
+	Refresh(ctx context.Context, since time.Time, minSupport int64) (int64, error)
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/soerjadi/exam/database"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/recommendation"
+	"github.com/soerjadi/exam/utils"
+)
+
+type pgRecommendationRepository struct {
+	Conn *sql.DB
+}
+
+var logger = utils.LogBuilder(true)
+
+// NewPGRecommendationRepository is bridge to create an object from recommendation.Repository interface
+func NewPGRecommendationRepository(Conn *sql.DB) recommendation.Repository {
+	return &pgRecommendationRepository{Conn}
+}
+
+func (p *pgRecommendationRepository) fetch(ctx context.Context, source string, query string, args ...interface{}) ([]*models.RelatedProduct, error) {
+	rows, err := p.Conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Error(err)
+		}
+	}()
+
+	result := make([]*models.RelatedProduct, 0)
+	for rows.Next() {
+		r := &models.RelatedProduct{Source: source}
+
+		err = rows.Scan(&r.ProductID, &r.Name, &r.SKU, &r.Score)
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+
+		result = append(result, r)
+	}
+
+	if err = rows.Err(); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetBoughtTogether list the products most often ordered with productID as
+// computed by the last Refresh
+func (p *pgRecommendationRepository) GetBoughtTogether(ctx context.Context, productID int64, limit int64) ([]*models.RelatedProduct, error) {
+	query := `SELECT r.related_id, p.name, p.sku, r.score FROM product_related r
+		JOIN products p ON p.id = r.related_id
+		WHERE r.product_id = ? AND p.status = ? AND p.deleted_at IS NULL
+		ORDER BY r.score DESC, r.related_id LIMIT ?`
+
+	return p.fetch(ctx, models.RelatedBoughtTogether, query, productID, models.ProductPublished, limit)
+}
+
+// GetSameCategory list the products sharing the most categories with productID
+func (p *pgRecommendationRepository) GetSameCategory(ctx context.Context, productID int64, limit int64) ([]*models.RelatedProduct, error) {
+	query := `SELECT p.id, p.name, p.sku, count(DISTINCT b.category_id) FROM product_category a
+		JOIN product_category b ON b.category_id = a.category_id AND b.product_id <> a.product_id
+		JOIN products p ON p.id = b.product_id
+		WHERE a.product_id = ? AND p.status = ? AND p.deleted_at IS NULL
+		GROUP BY p.id, p.name, p.sku ORDER BY 4 DESC, p.id LIMIT ?`
+
+	return p.fetch(ctx, models.RelatedSameCategory, query, productID, models.ProductPublished, limit)
+}
+
+// Refresh recompute which products are bought together from the orders paid
+// since since, pairs found in less than minSupport orders and products in the
+// trash are left out. The previous pairs are replaced in one transaction so
+// readers never see them half computed.
+func (p *pgRecommendationRepository) Refresh(ctx context.Context, since time.Time, minSupport int64) (int64, error) {
+	var affected int64
+	err := database.RunInTx(ctx, p.Conn, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM product_related`); err != nil {
+			return err
+		}
+
+		query := `INSERT INTO product_related(product_id, related_id, score)
+			SELECT a.product_id, b.product_id, count(DISTINCT a.order_id) FROM order_item a
+			JOIN order_item b ON b.order_id = a.order_id AND b.product_id <> a.product_id
+			JOIN orders o ON o.id = a.order_id
+			JOIN products p ON p.id = b.product_id AND p.deleted_at IS NULL
+			WHERE o.created >= ? AND o.status IN (?, ?, ?, ?)
+			GROUP BY a.product_id, b.product_id HAVING count(DISTINCT a.order_id) >= ?`
+
+		res, err := tx.ExecContext(ctx, query, since, models.OrderProccessed, models.OrderPartiallyShipped,
+			models.OrderShipped, models.OrderCompleted, minSupport)
+		if err != nil {
+			return err
+		}
+
+		affected, err = res.RowsAffected()
+		return err
+	})
+	if err != nil {
+		logger.Error(err)
+		return 0, err
+	}
+
+	return affected, nil
+}
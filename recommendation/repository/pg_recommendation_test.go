@@ -0,0 +1,93 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/recommendation/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+var relatedColumns = []string{"id", "name", "sku", "score"}
+
+func TestGetBoughtTogether(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectQuery("SELECT r.related_id, p.name, p.sku, r.score FROM product_related r (.+) ORDER BY r.score DESC, r.related_id LIMIT \\?").
+		WithArgs(int64(1), models.ProductPublished, int64(8)).
+		WillReturnRows(sqlmock.NewRows(relatedColumns).AddRow(2, "Celana", "CLN-01", 12).AddRow(3, "Sabuk", "SBK-01", 4))
+
+	r := repository.NewPGRecommendationRepository(db)
+	related, err := r.GetBoughtTogether(context.TODO(), int64(1), int64(8))
+
+	assert.NoError(t, err)
+	assert.Len(t, related, 2)
+	assert.Equal(t, int64(12), related[0].Score)
+	assert.Equal(t, models.RelatedBoughtTogether, related[0].Source)
+}
+
+func TestGetSameCategory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectQuery("SELECT p.id, p.name, p.sku, count\\(DISTINCT b.category_id\\) FROM product_category a (.+) LIMIT \\?").
+		WithArgs(int64(1), models.ProductPublished, int64(8)).
+		WillReturnRows(sqlmock.NewRows(relatedColumns).AddRow(5, "Kaos", "KOS-01", 2))
+
+	r := repository.NewPGRecommendationRepository(db)
+	related, err := r.GetSameCategory(context.TODO(), int64(1), int64(8))
+
+	assert.NoError(t, err)
+	assert.Len(t, related, 1)
+	assert.Equal(t, models.RelatedSameCategory, related[0].Source)
+}
+
+func TestGetSameCategoryRowError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectQuery("SELECT p.id, p.name, p.sku, count\\(DISTINCT b.category_id\\) FROM product_category a (.+) LIMIT \\?").
+		WithArgs(int64(1), models.ProductPublished, int64(8)).
+		WillReturnRows(sqlmock.NewRows(relatedColumns).AddRow(5, "Kaos", "KOS-01", 2).AddRow(6, "Topi", "TOP-01", 1).
+			RowError(1, errors.New("connection reset")))
+
+	r := repository.NewPGRecommendationRepository(db)
+	related, err := r.GetSameCategory(context.TODO(), int64(1), int64(8))
+
+	assert.Error(t, err)
+	assert.Nil(t, related)
+}
+
+func TestRefresh(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	since := time.Now().Add(-24 * time.Hour)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM product_related").WillReturnResult(sqlmock.NewResult(0, 30))
+	mock.ExpectExec("INSERT INTO product_related\\(product_id, related_id, score\\) SELECT (.+) HAVING count\\(DISTINCT a.order_id\\) >= \\?").
+		WithArgs(since, models.OrderProccessed, models.OrderPartiallyShipped, models.OrderShipped, models.OrderCompleted, int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 42))
+	mock.ExpectCommit()
+
+	r := repository.NewPGRecommendationRepository(db)
+	pairs, err := r.Refresh(context.TODO(), since, int64(2))
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), pairs)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,13 @@
+package recommendation
+
+import (
+	"context"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// Usecase represent the related products usecase
+type Usecase interface {
+	Related(ctx context.Context, productID int64, limit int64) ([]*models.RelatedProduct, error)
+	Refresh(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/product"
+	"github.com/soerjadi/exam/recommendation"
+)
+
+// MaxRelated is the most related products listed at once
+const MaxRelated = 50
+
+type recommendationUsecase struct {
+	repo       recommendation.Repository
+	products   product.Usecase
+	window     time.Duration
+	minSupport int64
+	timeout    time.Duration
+}
+
+// NewRecommendationUsecase will create object that represent of recommendation.Usecase interface.
+// Refresh mine the orders of the last window, a pair of products has to be
+// bought together in minSupport orders to be related.
+func NewRecommendationUsecase(r recommendation.Repository, p product.Usecase, window time.Duration, minSupport int64, timeout time.Duration) recommendation.Usecase {
+	return &recommendationUsecase{
+		repo:       r,
+		products:   p,
+		window:     window,
+		minSupport: minSupport,
+		timeout:    timeout,
+	}
+}
+
+// Related list the products frequently bought with a published product. When
+// the order history is too thin to fill limit, products of the same
+// categories complete the list.
+func (u *recommendationUsecase) Related(ctx context.Context, productID int64, limit int64) ([]*models.RelatedProduct, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	if limit <= 0 || limit > MaxRelated {
+		return nil, models.ErrBadParamInput
+	}
+
+	if _, err := u.products.GetPublishedByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	related, err := u.repo.GetBoughtTogether(ctx, productID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	missing := limit - int64(len(related))
+	if missing == 0 {
+		return related, nil
+	}
+
+	// products already listed can come back, ask for enough to skip them
+	same, err := u.repo.GetSameCategory(ctx, productID, limit+int64(len(related)))
+	if err != nil {
+		return nil, err
+	}
+
+	listed := make(map[int64]bool, len(related))
+	for _, r := range related {
+		listed[r.ProductID] = true
+	}
+
+	for _, r := range same {
+		if missing == 0 {
+			break
+		}
+
+		if listed[r.ProductID] {
+			continue
+		}
+
+		related = append(related, r)
+		missing--
+	}
+
+	return related, nil
+}
+
+// Refresh recompute the products bought together, it return how many pairs
+// were found
+func (u *recommendationUsecase) Refresh(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	return u.repo.Refresh(ctx, time.Now().Add(-u.window), u.minSupport)
+}
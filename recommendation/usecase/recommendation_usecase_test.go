@@ -0,0 +1,93 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/soerjadi/exam/models"
+	productMocks "github.com/soerjadi/exam/product/mocks"
+	"github.com/soerjadi/exam/recommendation/mocks"
+	"github.com/soerjadi/exam/recommendation/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRelated(t *testing.T) {
+	t.Run("enough history", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockProduct := new(productMocks.Usecase)
+		mockProduct.On("GetPublishedByID", mock.Anything, int64(1)).Return(&models.Product{ID: 1}, nil)
+		mockRepo.On("GetBoughtTogether", mock.Anything, int64(1), int64(2)).Return([]*models.RelatedProduct{
+			&models.RelatedProduct{ProductID: 2, Source: models.RelatedBoughtTogether},
+			&models.RelatedProduct{ProductID: 3, Source: models.RelatedBoughtTogether},
+		}, nil)
+
+		u := usecase.NewRecommendationUsecase(mockRepo, mockProduct, time.Hour, 2, time.Second*2)
+		related, err := u.Related(context.TODO(), int64(1), int64(2))
+
+		assert.NoError(t, err)
+		assert.Len(t, related, 2)
+		mockRepo.AssertNotCalled(t, "GetSameCategory", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("thin history falls back to same category", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockProduct := new(productMocks.Usecase)
+		mockProduct.On("GetPublishedByID", mock.Anything, int64(1)).Return(&models.Product{ID: 1}, nil)
+		mockRepo.On("GetBoughtTogether", mock.Anything, int64(1), int64(3)).Return([]*models.RelatedProduct{
+			&models.RelatedProduct{ProductID: 2, Source: models.RelatedBoughtTogether},
+		}, nil)
+		// the listed product can take one of the places asked for
+		mockRepo.On("GetSameCategory", mock.Anything, int64(1), int64(4)).Return([]*models.RelatedProduct{
+			&models.RelatedProduct{ProductID: 2, Source: models.RelatedSameCategory},
+			&models.RelatedProduct{ProductID: 4, Source: models.RelatedSameCategory},
+			&models.RelatedProduct{ProductID: 5, Source: models.RelatedSameCategory},
+		}, nil)
+
+		u := usecase.NewRecommendationUsecase(mockRepo, mockProduct, time.Hour, 2, time.Second*2)
+		related, err := u.Related(context.TODO(), int64(1), int64(3))
+
+		assert.NoError(t, err)
+		assert.Len(t, related, 3)
+		assert.Equal(t, models.RelatedBoughtTogether, related[0].Source)
+		assert.Equal(t, int64(4), related[1].ProductID)
+		assert.Equal(t, int64(5), related[2].ProductID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("unpublished product", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockProduct := new(productMocks.Usecase)
+		mockProduct.On("GetPublishedByID", mock.Anything, int64(9)).Return(nil, models.ErrNotFound)
+
+		u := usecase.NewRecommendationUsecase(mockRepo, mockProduct, time.Hour, 2, time.Second*2)
+		_, err := u.Related(context.TODO(), int64(9), int64(3))
+
+		assert.Equal(t, models.ErrNotFound, err)
+	})
+
+	t.Run("limit too large", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockProduct := new(productMocks.Usecase)
+
+		u := usecase.NewRecommendationUsecase(mockRepo, mockProduct, time.Hour, 2, time.Second*2)
+		_, err := u.Related(context.TODO(), int64(1), int64(usecase.MaxRelated+1))
+
+		assert.Equal(t, models.ErrBadParamInput, err)
+	})
+}
+
+func TestRefresh(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockProduct := new(productMocks.Usecase)
+	mockRepo.On("Refresh", mock.Anything, mock.AnythingOfType("time.Time"), int64(3)).Return(int64(10), nil)
+
+	u := usecase.NewRecommendationUsecase(mockRepo, mockProduct, 24*time.Hour, 3, time.Second*2)
+	pairs, err := u.Refresh(context.TODO())
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), pairs)
+	since := mockRepo.Calls[0].Arguments.Get(1).(time.Time)
+	assert.WithinDuration(t, time.Now().Add(-24*time.Hour), since, time.Minute)
+}
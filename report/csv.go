@@ -0,0 +1,67 @@
+package report
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/soerjadi/exam/models"
+)
+
+const csvDate = "2006-01-02"
+
+func money(value float64) string {
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
+
+func count(value int64) string {
+	return strconv.FormatInt(value, 10)
+}
+
+func day(value time.Time) string {
+	return value.Format(csvDate)
+}
+
+// WriteCSV write a report as CSV with a header row, report is what one of the
+// Usecase methods returned
+func WriteCSV(w io.Writer, report interface{}) error {
+	var rows [][]string
+
+	switch r := report.(type) {
+	case *models.SalesSummary:
+		rows = [][]string{
+			{"from", "to", "revenue", "orders", "units", "average_order"},
+			{day(r.From), day(r.To), money(r.Revenue), count(r.Orders), count(r.Units), money(r.AverageOrder)},
+		}
+	case []*models.SalesPoint:
+		rows = [][]string{{"period", "revenue", "orders", "units", "average_order"}}
+		for _, p := range r {
+			rows = append(rows, []string{day(p.Period), money(p.Revenue), count(p.Orders), count(p.Units), money(p.AverageOrder)})
+		}
+	case []*models.ProductSales:
+		rows = [][]string{{"product_id", "name", "units", "revenue", "orders"}}
+		for _, p := range r {
+			rows = append(rows, []string{count(p.ProductID), p.Name, count(p.Units), money(p.Revenue), count(p.Orders)})
+		}
+	case []*models.CategorySales:
+		rows = [][]string{{"category_id", "name", "parent_id", "units", "revenue"}}
+		for _, c := range r {
+			parent := ""
+			if c.ParentID.Valid {
+				parent = count(c.ParentID.Int64)
+			}
+			rows = append(rows, []string{count(c.CategoryID), c.Name, parent, count(c.Units), money(c.Revenue)})
+		}
+	default:
+		return errors.New("report: unknown report type")
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+
+	return writer.Error()
+}
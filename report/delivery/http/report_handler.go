@@ -0,0 +1,199 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/report"
+	"github.com/soerjadi/exam/utils"
+)
+
+// defaultLimit is how many top sellers are listed when limit is not given
+const defaultLimit = 10
+
+var logger = utils.LogBuilder(true)
+
+// ReportHandler represent the http handler for sales reports
+type ReportHandler struct {
+	ReportUsecase report.Usecase
+}
+
+// NewReportHandler initialize sales report endpoint
+func NewReportHandler(router *mux.Router, usecase report.Usecase) *mux.Router {
+	handler := &ReportHandler{
+		ReportUsecase: usecase,
+	}
+
+	s := router.PathPrefix("/v1/admin/report").Subrouter()
+	s.HandleFunc("/summary", handler.Summary).Methods("GET")
+	s.HandleFunc("/sales", handler.Sales).Methods("GET")
+	s.HandleFunc("/products", handler.Products).Methods("GET")
+	s.HandleFunc("/categories", handler.Categories).Methods("GET")
+
+	return s
+}
+
+func parseDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		date, err = time.Parse(time.RFC3339, value)
+	}
+
+	if err != nil {
+		return time.Time{}, models.ErrBadParamInput
+	}
+
+	return date, nil
+}
+
+// reportFilter read the range and options of a report from the query string,
+// to is excluded from the range
+func reportFilter(params url.Values) (*models.ReportFilter, error) {
+	filter := &models.ReportFilter{
+		Period: params.Get("period"),
+		Sort:   params.Get("sort"),
+		Limit:  defaultLimit,
+	}
+
+	var err error
+	if filter.From, err = parseDate(params.Get("from")); err != nil {
+		return nil, err
+	}
+
+	if filter.To, err = parseDate(params.Get("to")); err != nil {
+		return nil, err
+	}
+
+	if value := params.Get("limit"); value != "" {
+		if filter.Limit, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return nil, models.ErrBadParamInput
+		}
+	}
+
+	return filter, nil
+}
+
+// respond write the report as JSON, or as a CSV attachment when format=csv
+func respond(w http.ResponseWriter, r *http.Request, name string, data interface{}, found int64) {
+	if r.URL.Query().Get("format") != "csv" {
+		if found < 0 {
+			utils.JSON(w, http.StatusOK, data)
+			return
+		}
+
+		utils.JSON(w, http.StatusOK, &utils.EntriesResponse{
+			Data:  data,
+			Found: found,
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, name))
+	w.WriteHeader(http.StatusOK)
+	if err := report.WriteCSV(w, data); err != nil {
+		logger.Error(err)
+	}
+}
+
+// Summary endpoint to get the revenue, orders, units and average order value of a range
+func (h *ReportHandler) Summary(w http.ResponseWriter, r *http.Request) {
+	filter, err := reportFilter(r.URL.Query())
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	summary, err := h.ReportUsecase.Summary(ctx, filter)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respond(w, r, "summary", summary, -1)
+}
+
+// Sales endpoint to get the sales of a range by day, week or month
+func (h *ReportHandler) Sales(w http.ResponseWriter, r *http.Request) {
+	filter, err := reportFilter(r.URL.Query())
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	points, err := h.ReportUsecase.Sales(ctx, filter)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respond(w, r, "sales", points, int64(len(points)))
+}
+
+// Products endpoint to list the top selling products of a range
+func (h *ReportHandler) Products(w http.ResponseWriter, r *http.Request) {
+	filter, err := reportFilter(r.URL.Query())
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	products, err := h.ReportUsecase.Products(ctx, filter)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respond(w, r, "products", products, int64(len(products)))
+}
+
+// Categories endpoint to list the sales of every category of a range
+func (h *ReportHandler) Categories(w http.ResponseWriter, r *http.Request) {
+	filter, err := reportFilter(r.URL.Query())
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	categories, err := h.ReportUsecase.Categories(ctx, filter)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respond(w, r, "categories", categories, int64(len(categories)))
+}
@@ -0,0 +1,80 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/soerjadi/exam/models"
+	reportHttp "github.com/soerjadi/exam/report/delivery/http"
+	"github.com/soerjadi/exam/report/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSalesCSV(t *testing.T) {
+	from := time.Date(2019, 11, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2019, 11, 3, 0, 0, 0, 0, time.UTC)
+
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("Sales", mock.Anything, &models.ReportFilter{From: from, To: to, Period: "day", Limit: 10}).Return([]*models.SalesPoint{
+		&models.SalesPoint{Period: from, Revenue: 100, Orders: 2, Units: 3, AverageOrder: 50},
+		&models.SalesPoint{Period: from.AddDate(0, 0, 1)},
+	}, nil)
+
+	req, err := http.NewRequest("GET", "/v1/admin/report/sales?from=2019-11-01&to=2019-11-03&period=day&format=csv", nil)
+	assert.NoError(t, err)
+
+	handler := reportHttp.ReportHandler{
+		ReportUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.Sales(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "period,revenue,orders,units,average_order\n"+
+		"2019-11-01,100.00,2,3,50.00\n"+
+		"2019-11-02,0.00,0,0,0.00\n", rec.Body.String())
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestProducts(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("Products", mock.Anything, &models.ReportFilter{Sort: "units", Limit: 5}).Return([]*models.ProductSales{
+		&models.ProductSales{ProductID: 2, Name: "Kemeja", Units: 10, Revenue: 1000, Orders: 4},
+	}, nil)
+
+	req, err := http.NewRequest("GET", "/v1/admin/report/products?sort=units&limit=5", nil)
+	assert.NoError(t, err)
+
+	handler := reportHttp.ReportHandler{
+		ReportUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.Products(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"name":"Kemeja"`)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestSummaryBadDate(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+
+	req, err := http.NewRequest("GET", "/v1/admin/report/summary?from=yesterday", nil)
+	assert.NoError(t, err)
+
+	handler := reportHttp.ReportHandler{
+		ReportUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.Summary(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockUsecase.AssertNotCalled(t, "Summary", mock.Anything, mock.Anything)
+}
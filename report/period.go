@@ -0,0 +1,74 @@
+package report
+
+import (
+	"math"
+	"time"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// ValidPeriod tell whether sales can be grouped by period
+func ValidPeriod(period string) bool {
+	switch period {
+	case models.PeriodDay, models.PeriodWeek, models.PeriodMonth:
+		return true
+	}
+
+	return false
+}
+
+// Truncate give the start of the period t is in, weeks start on monday as
+// they do for postgres date_trunc
+func Truncate(t time.Time, period string) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch period {
+	case models.PeriodWeek:
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case models.PeriodMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	return day
+}
+
+func next(t time.Time, period string) time.Time {
+	switch period {
+	case models.PeriodWeek:
+		return t.AddDate(0, 0, 7)
+	case models.PeriodMonth:
+		return t.AddDate(0, 1, 0)
+	}
+
+	return t.AddDate(0, 0, 1)
+}
+
+// Fill add an empty point for every period from from to to without sales so
+// the series has no hole
+func Fill(points []*models.SalesPoint, from time.Time, to time.Time, period string) []*models.SalesPoint {
+	found := make(map[int64]*models.SalesPoint, len(points))
+	for _, p := range points {
+		found[p.Period.Unix()] = p
+	}
+
+	result := make([]*models.SalesPoint, 0, len(points))
+	for t := Truncate(from, period); t.Before(to); t = next(t, period) {
+		if p, ok := found[t.Unix()]; ok {
+			result = append(result, p)
+			continue
+		}
+
+		result = append(result, &models.SalesPoint{Period: t})
+	}
+
+	return result
+}
+
+// Average is revenue divided by orders rounded to the cent, 0 without orders
+func Average(revenue float64, orders int64) float64 {
+	if orders == 0 {
+		return 0
+	}
+
+	return math.Round(revenue/float64(orders)*100) / 100
+}
@@ -0,0 +1,19 @@
+package report
+
+import (
+	"context"
+	"time"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// Repository represent the sales reporting repository contract, it only
+// count orders created from from to to, to excluded, which were paid and not
+// cancelled nor returned
+type Repository interface {
+	Summary(ctx context.Context, from time.Time, to time.Time) (*models.SalesSummary, error)
+	Sales(ctx context.Context, from time.Time, to time.Time, period string) ([]*models.SalesPoint, error)
+	ProductSales(ctx context.Context, from time.Time, to time.Time) ([]*models.ProductSales, error)
+	Categories(ctx context.Context) ([]*models.Category, error)
+	ProductCategories(ctx context.Context) ([]*models.ProductCategory, error)
+}
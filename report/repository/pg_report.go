@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/report"
+	"github.com/soerjadi/exam/utils"
+)
+
+type pgReportRepository struct {
+	Conn *sql.DB
+}
+
+var logger = utils.LogBuilder(true)
+
+// sold is the condition on orders counted as sales, they were paid and
+// neither cancelled nor sent back
+const sold = `o.created >= ? AND o.created < ? AND o.status IN (?, ?, ?, ?)`
+
+// totals join the units of every order and what was refunded of it through
+// credit notes, revenue is net of those refunds
+const totals = `LEFT JOIN (SELECT order_id, sum(amount) AS units FROM order_item GROUP BY order_id) i ON i.order_id = o.id
+		LEFT JOIN (SELECT order_id, sum(amount) AS credited FROM credit_notes GROUP BY order_id) c ON c.order_id = o.id`
+
+// revenue is the sum of what was paid for the orders less their refunds
+const revenue = `COALESCE(sum(o.total - COALESCE(c.credited, 0)), 0)`
+
+// NewPGReportRepository is bridge to create an object from report.Repository interface
+func NewPGReportRepository(Conn *sql.DB) report.Repository {
+	return &pgReportRepository{Conn}
+}
+
+func soldArgs(from time.Time, to time.Time) []interface{} {
+	return []interface{}{from, to, models.OrderProccessed, models.OrderPartiallyShipped, models.OrderShipped, models.OrderCompleted}
+}
+
+func (p *pgReportRepository) query(ctx context.Context, query string, args []interface{}, scan func(rows *sql.Rows) error) error {
+	rows, err := p.Conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Error(err)
+		}
+	}()
+
+	for rows.Next() {
+		if err = scan(rows); err != nil {
+			logger.Error(err)
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// Summary count the orders, revenue and units sold over the whole range,
+// refunds are taken off the revenue of the orders they were made for
+func (p *pgReportRepository) Summary(ctx context.Context, from time.Time, to time.Time) (*models.SalesSummary, error) {
+	query := `SELECT count(o.id), ` + revenue + `, COALESCE(sum(i.units), 0) FROM orders o
+		` + totals + ` WHERE ` + sold
+
+	summary := &models.SalesSummary{From: from, To: to}
+	err := p.Conn.QueryRowContext(ctx, query, soldArgs(from, to)...).Scan(&summary.Orders, &summary.Revenue, &summary.Units)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+// Sales group the orders by the day, week or month they were created, periods
+// without sales are left out
+func (p *pgReportRepository) Sales(ctx context.Context, from time.Time, to time.Time, period string) ([]*models.SalesPoint, error) {
+	query := `SELECT date_trunc(?, o.created), count(o.id), ` + revenue + `, COALESCE(sum(i.units), 0) FROM orders o
+		` + totals + ` WHERE ` + sold + ` GROUP BY 1 ORDER BY 1`
+
+	args := append([]interface{}{period}, soldArgs(from, to)...)
+
+	result := make([]*models.SalesPoint, 0)
+	err := p.query(ctx, query, args, func(rows *sql.Rows) error {
+		s := new(models.SalesPoint)
+		if err := rows.Scan(&s.Period, &s.Orders, &s.Revenue, &s.Units); err != nil {
+			return err
+		}
+
+		result = append(result, s)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ProductSales sum the lines of every product sold, the best revenue first
+func (p *pgReportRepository) ProductSales(ctx context.Context, from time.Time, to time.Time) ([]*models.ProductSales, error) {
+	query := `SELECT i.product_id, COALESCE(p.name, ''), sum(i.amount), sum(i.price * i.amount), count(DISTINCT i.order_id) FROM order_item i
+		JOIN orders o ON o.id = i.order_id
+		LEFT JOIN products p ON p.id = i.product_id
+		WHERE ` + sold + ` GROUP BY i.product_id, p.name ORDER BY 4 DESC, i.product_id`
+
+	result := make([]*models.ProductSales, 0)
+	err := p.query(ctx, query, soldArgs(from, to), func(rows *sql.Rows) error {
+		s := new(models.ProductSales)
+		if err := rows.Scan(&s.ProductID, &s.Name, &s.Units, &s.Revenue, &s.Orders); err != nil {
+			return err
+		}
+
+		result = append(result, s)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Categories list every category which is not deleted
+func (p *pgReportRepository) Categories(ctx context.Context) ([]*models.Category, error) {
+	query := `SELECT id, name, parent_id FROM categories WHERE deleted_at IS NULL ORDER BY id`
+
+	result := make([]*models.Category, 0)
+	err := p.query(ctx, query, nil, func(rows *sql.Rows) error {
+		c := new(models.Category)
+		if err := rows.Scan(&c.ID, &c.Name, &c.ParentID); err != nil {
+			return err
+		}
+
+		result = append(result, c)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ProductCategories list every link of a product to a category
+func (p *pgReportRepository) ProductCategories(ctx context.Context) ([]*models.ProductCategory, error) {
+	query := `SELECT id, product_id, category_id FROM product_category`
+
+	result := make([]*models.ProductCategory, 0)
+	err := p.query(ctx, query, nil, func(rows *sql.Rows) error {
+		c := new(models.ProductCategory)
+		if err := rows.Scan(&c.ID, &c.ProductID, &c.CategoryID); err != nil {
+			return err
+		}
+
+		result = append(result, c)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
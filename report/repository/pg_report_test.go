@@ -0,0 +1,103 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/report/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	from = time.Date(2019, 11, 1, 0, 0, 0, 0, time.UTC)
+	to   = time.Date(2019, 12, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func TestSummary(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectQuery("SELECT count\\(o.id\\), COALESCE\\(sum\\(o.total - COALESCE\\(c.credited, 0\\)\\), 0\\), COALESCE\\(sum\\(i.units\\), 0\\) FROM orders o (.+) LEFT JOIN \\(SELECT order_id, sum\\(amount\\) AS credited FROM credit_notes GROUP BY order_id\\) c ON c.order_id = o.id WHERE o.created >= \\? AND o.created < \\? AND o.status IN").
+		WithArgs(from, to, models.OrderProccessed, models.OrderPartiallyShipped, models.OrderShipped, models.OrderCompleted).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "revenue", "units"}).AddRow(4, 1000.5, 9))
+
+	r := repository.NewPGReportRepository(db)
+	summary, err := r.Summary(context.TODO(), from, to)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), summary.Orders)
+	assert.Equal(t, 1000.5, summary.Revenue)
+	assert.Equal(t, int64(9), summary.Units)
+	assert.Equal(t, from, summary.From)
+}
+
+func TestSales(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectQuery("SELECT date_trunc\\(\\?, o.created\\), count\\(o.id\\), COALESCE\\(sum\\(o.total - COALESCE\\(c.credited, 0\\)\\), 0\\), (.+) FROM credit_notes (.+) GROUP BY 1 ORDER BY 1").
+		WithArgs(models.PeriodWeek, from, to, models.OrderProccessed, models.OrderPartiallyShipped, models.OrderShipped, models.OrderCompleted).
+		WillReturnRows(sqlmock.NewRows([]string{"period", "count", "revenue", "units"}).
+			AddRow(time.Date(2019, 11, 4, 0, 0, 0, 0, time.UTC), 2, 300, 3).
+			AddRow(time.Date(2019, 11, 18, 0, 0, 0, 0, time.UTC), 1, 50, 1))
+
+	r := repository.NewPGReportRepository(db)
+	points, err := r.Sales(context.TODO(), from, to, models.PeriodWeek)
+
+	assert.NoError(t, err)
+	assert.Len(t, points, 2)
+	assert.Equal(t, int64(2), points[0].Orders)
+	assert.Equal(t, float64(50), points[1].Revenue)
+}
+
+func TestProductSales(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectQuery("SELECT i.product_id, (.+) FROM order_item i (.+) GROUP BY i.product_id, p.name ORDER BY 4 DESC, i.product_id").
+		WithArgs(from, to, models.OrderProccessed, models.OrderPartiallyShipped, models.OrderShipped, models.OrderCompleted).
+		WillReturnRows(sqlmock.NewRows([]string{"product_id", "name", "units", "revenue", "orders"}).
+			AddRow(2, "Kemeja", 5, 500, 3).
+			AddRow(7, "", 1, 20, 1))
+
+	r := repository.NewPGReportRepository(db)
+	products, err := r.ProductSales(context.TODO(), from, to)
+
+	assert.NoError(t, err)
+	assert.Len(t, products, 2)
+	assert.Equal(t, "Kemeja", products[0].Name)
+	assert.Equal(t, int64(3), products[0].Orders)
+}
+
+func TestCategories(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectQuery("SELECT id, name, parent_id FROM categories WHERE deleted_at IS NULL ORDER BY id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "parent_id"}).AddRow(1, "Fashion", nil).AddRow(2, "Shirts", 1))
+	mock.ExpectQuery("SELECT id, product_id, category_id FROM product_category").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "product_id", "category_id"}).AddRow(1, 5, 2))
+
+	r := repository.NewPGReportRepository(db)
+	categories, err := r.Categories(context.TODO())
+
+	assert.NoError(t, err)
+	assert.Len(t, categories, 2)
+	assert.Equal(t, int64(1), categories[1].ParentID.Int64)
+
+	links, err := r.ProductCategories(context.TODO())
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), links[0].ProductID)
+}
@@ -0,0 +1,68 @@
+package report
+
+import (
+	"sort"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// Rollup sum the sales of products into the categories they are linked to and
+// into every ancestor of those categories. A product linked to several
+// categories of the same branch is counted once in each of them. Categories
+// come sorted by revenue, the best first.
+func Rollup(categories []*models.Category, links []*models.ProductCategory, sales []*models.ProductSales) []*models.CategorySales {
+	byID := make(map[int64]*models.CategorySales, len(categories))
+	parents := make(map[int64]int64, len(categories))
+	result := make([]*models.CategorySales, 0, len(categories))
+
+	for _, category := range categories {
+		entry := &models.CategorySales{
+			CategoryID: category.ID,
+			Name:       category.Name,
+			ParentID:   category.ParentID,
+		}
+		byID[category.ID] = entry
+		result = append(result, entry)
+
+		if category.ParentID.Valid {
+			parents[category.ID] = category.ParentID.Int64
+		}
+	}
+
+	linked := make(map[int64][]int64)
+	for _, link := range links {
+		linked[link.ProductID] = append(linked[link.ProductID], link.CategoryID)
+	}
+
+	for _, product := range sales {
+		counted := make(map[int64]bool)
+
+		for _, id := range linked[product.ProductID] {
+			for {
+				entry, ok := byID[id]
+				if !ok || counted[id] {
+					break
+				}
+
+				counted[id] = true
+				entry.Units += product.Units
+				entry.Revenue += product.Revenue
+
+				parent, ok := parents[id]
+				if !ok {
+					break
+				}
+				id = parent
+			}
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Revenue != result[j].Revenue {
+			return result[i].Revenue > result[j].Revenue
+		}
+		return result[i].CategoryID < result[j].CategoryID
+	})
+
+	return result
+}
@@ -0,0 +1,15 @@
+package report
+
+import (
+	"context"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// Usecase represent the sales reporting usecase
+type Usecase interface {
+	Summary(ctx context.Context, filter *models.ReportFilter) (*models.SalesSummary, error)
+	Sales(ctx context.Context, filter *models.ReportFilter) ([]*models.SalesPoint, error)
+	Products(ctx context.Context, filter *models.ReportFilter) ([]*models.ProductSales, error)
+	Categories(ctx context.Context, filter *models.ReportFilter) ([]*models.CategorySales, error)
+}
@@ -0,0 +1,194 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/report"
+)
+
+// defaultRange is how far back reports look when the range is not given
+const defaultRange = 30 * 24 * time.Hour
+
+// maxYears is the longest range in years a report grouped by a period can
+// cover, reports which are not grouped are bound like months
+var maxYears = map[string]int{
+	models.PeriodDay:   2,
+	models.PeriodWeek:  5,
+	models.PeriodMonth: 10,
+}
+
+type reportUsecase struct {
+	repo    report.Repository
+	timeout time.Duration
+}
+
+// NewReportUsecase will create object that represent of report.Usecase interface
+func NewReportUsecase(r report.Repository, timeout time.Duration) report.Usecase {
+	return &reportUsecase{
+		repo:    r,
+		timeout: timeout,
+	}
+}
+
+func round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+// bounds complete the range of filter, it end with today and last 30 days
+// when not given. A range which does not end after it start, or which is
+// longer than period allow, is refused.
+func bounds(filter *models.ReportFilter, period string) (time.Time, time.Time, error) {
+	from, to := filter.From, filter.To
+
+	if to.IsZero() {
+		to = report.Truncate(time.Now(), models.PeriodDay).AddDate(0, 0, 1)
+	}
+
+	if from.IsZero() {
+		from = to.Add(-defaultRange)
+	}
+
+	if !from.Before(to) || to.After(from.AddDate(maxYears[period], 0, 0)) {
+		return from, to, models.ErrBadParamInput
+	}
+
+	return from, to, nil
+}
+
+// Summary give the revenue, orders, units and average order value of the range
+func (u *reportUsecase) Summary(ctx context.Context, filter *models.ReportFilter) (*models.SalesSummary, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	from, to, err := bounds(filter, models.PeriodMonth)
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := u.repo.Summary(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	summary.Revenue = round(summary.Revenue)
+	summary.AverageOrder = report.Average(summary.Revenue, summary.Orders)
+
+	return summary, nil
+}
+
+// Sales give the sales of every day, week or month of the range, periods
+// without sales included
+func (u *reportUsecase) Sales(ctx context.Context, filter *models.ReportFilter) ([]*models.SalesPoint, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	period := filter.Period
+	if period == "" {
+		period = models.PeriodDay
+	}
+
+	if !report.ValidPeriod(period) {
+		return nil, models.ErrBadParamInput
+	}
+
+	from, to, err := bounds(filter, period)
+	if err != nil {
+		return nil, err
+	}
+
+	points, err := u.repo.Sales(ctx, from, to, period)
+	if err != nil {
+		return nil, err
+	}
+
+	points = report.Fill(points, from, to, period)
+	for _, p := range points {
+		p.Revenue = round(p.Revenue)
+		p.AverageOrder = report.Average(p.Revenue, p.Orders)
+	}
+
+	return points, nil
+}
+
+// Products give the products sold in the range, the top sellers by revenue or
+// units first, Limit keep only the first of them
+func (u *reportUsecase) Products(ctx context.Context, filter *models.ReportFilter) ([]*models.ProductSales, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	if filter.Limit < 0 {
+		return nil, models.ErrBadParamInput
+	}
+
+	var less func(a, b *models.ProductSales) bool
+	switch filter.Sort {
+	case "", "revenue":
+		less = func(a, b *models.ProductSales) bool { return a.Revenue > b.Revenue }
+	case "units":
+		less = func(a, b *models.ProductSales) bool { return a.Units > b.Units }
+	default:
+		return nil, models.ErrBadParamInput
+	}
+
+	from, to, err := bounds(filter, models.PeriodMonth)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := u.repo.ProductSales(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(products, func(i, j int) bool {
+		return less(products[i], products[j])
+	})
+
+	if filter.Limit > 0 && int64(len(products)) > filter.Limit {
+		products = products[:filter.Limit]
+	}
+
+	for _, p := range products {
+		p.Revenue = round(p.Revenue)
+	}
+
+	return products, nil
+}
+
+// Categories give the sales of every category, those of its sub categories
+// included
+func (u *reportUsecase) Categories(ctx context.Context, filter *models.ReportFilter) ([]*models.CategorySales, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	from, to, err := bounds(filter, models.PeriodMonth)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := u.repo.ProductSales(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := u.repo.Categories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	links, err := u.repo.ProductCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := report.Rollup(categories, links, products)
+	for _, c := range result {
+		c.Revenue = round(c.Revenue)
+	}
+
+	return result, nil
+}
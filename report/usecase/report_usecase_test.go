@@ -0,0 +1,195 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/report/mocks"
+	"github.com/soerjadi/exam/report/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gopkg.in/guregu/null.v3"
+)
+
+var (
+	from = time.Date(2019, 11, 1, 0, 0, 0, 0, time.UTC)
+	to   = time.Date(2019, 11, 8, 0, 0, 0, 0, time.UTC)
+)
+
+func TestSummary(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockRepo.On("Summary", mock.Anything, from, to).
+		Return(&models.SalesSummary{From: from, To: to, Orders: 3, Revenue: 100, Units: 4}, nil)
+
+	u := usecase.NewReportUsecase(mockRepo, time.Second*2)
+	summary, err := u.Summary(context.TODO(), &models.ReportFilter{From: from, To: to})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 33.33, summary.AverageOrder)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSummaryDefaultRange(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockRepo.On("Summary", mock.Anything, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).
+		Return(&models.SalesSummary{}, nil)
+
+	u := usecase.NewReportUsecase(mockRepo, time.Second*2)
+	_, err := u.Summary(context.TODO(), &models.ReportFilter{})
+
+	assert.NoError(t, err)
+	start := mockRepo.Calls[0].Arguments.Get(1).(time.Time)
+	end := mockRepo.Calls[0].Arguments.Get(2).(time.Time)
+	assert.Equal(t, 30*24*time.Hour, end.Sub(start))
+	assert.True(t, end.After(time.Now()))
+}
+
+func TestSummarySwappedRange(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+
+	u := usecase.NewReportUsecase(mockRepo, time.Second*2)
+	_, err := u.Summary(context.TODO(), &models.ReportFilter{From: to, To: from})
+
+	assert.Equal(t, models.ErrBadParamInput, err)
+	mockRepo.AssertNotCalled(t, "Summary", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSummaryRangeTooLong(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+
+	u := usecase.NewReportUsecase(mockRepo, time.Second*2)
+	_, err := u.Summary(context.TODO(), &models.ReportFilter{From: from, To: from.AddDate(10, 1, 0)})
+
+	assert.Equal(t, models.ErrBadParamInput, err)
+	mockRepo.AssertNotCalled(t, "Summary", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSales(t *testing.T) {
+	t.Run("fill days without sales", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockRepo.On("Sales", mock.Anything, from, to, models.PeriodDay).Return([]*models.SalesPoint{
+			&models.SalesPoint{Period: time.Date(2019, 11, 3, 0, 0, 0, 0, time.UTC), Orders: 2, Revenue: 50, Units: 2},
+		}, nil)
+
+		u := usecase.NewReportUsecase(mockRepo, time.Second*2)
+		points, err := u.Sales(context.TODO(), &models.ReportFilter{From: from, To: to})
+
+		assert.NoError(t, err)
+		assert.Len(t, points, 7)
+		assert.Equal(t, int64(0), points[0].Orders)
+		assert.Equal(t, float64(25), points[2].AverageOrder)
+	})
+
+	t.Run("weeks start on monday", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockRepo.On("Sales", mock.Anything, from, to, models.PeriodWeek).Return([]*models.SalesPoint{}, nil)
+
+		u := usecase.NewReportUsecase(mockRepo, time.Second*2)
+		points, err := u.Sales(context.TODO(), &models.ReportFilter{From: from, To: to, Period: models.PeriodWeek})
+
+		assert.NoError(t, err)
+		assert.Len(t, points, 2)
+		assert.Equal(t, time.Date(2019, 10, 28, 0, 0, 0, 0, time.UTC), points[0].Period)
+	})
+
+	t.Run("unknown period", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+
+		u := usecase.NewReportUsecase(mockRepo, time.Second*2)
+		_, err := u.Sales(context.TODO(), &models.ReportFilter{From: from, To: to, Period: "year"})
+
+		assert.Equal(t, models.ErrBadParamInput, err)
+	})
+
+	t.Run("range too long for the period", func(t *testing.T) {
+		tests := []struct {
+			period string
+			to     time.Time
+			err    error
+		}{
+			{models.PeriodDay, from.AddDate(2, 0, 0), nil},
+			{models.PeriodDay, from.AddDate(2, 0, 1), models.ErrBadParamInput},
+			{models.PeriodWeek, from.AddDate(5, 0, 1), models.ErrBadParamInput},
+			{models.PeriodMonth, from.AddDate(10, 0, 0), nil},
+			{models.PeriodMonth, from.AddDate(10, 1, 0), models.ErrBadParamInput},
+		}
+
+		for _, test := range tests {
+			mockRepo := new(mocks.Repository)
+			mockRepo.On("Sales", mock.Anything, from, test.to, test.period).Return([]*models.SalesPoint{}, nil)
+
+			u := usecase.NewReportUsecase(mockRepo, time.Second*2)
+			_, err := u.Sales(context.TODO(), &models.ReportFilter{From: from, To: test.to, Period: test.period})
+
+			assert.Equal(t, test.err, err, test.period)
+			if test.err != nil {
+				mockRepo.AssertNotCalled(t, "Sales", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		}
+	})
+}
+
+func TestProducts(t *testing.T) {
+	sales := func() []*models.ProductSales {
+		return []*models.ProductSales{
+			&models.ProductSales{ProductID: 1, Units: 1, Revenue: 300},
+			&models.ProductSales{ProductID: 2, Units: 10, Revenue: 100},
+			&models.ProductSales{ProductID: 3, Units: 5, Revenue: 50},
+		}
+	}
+
+	t.Run("top units", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockRepo.On("ProductSales", mock.Anything, from, to).Return(sales(), nil)
+
+		u := usecase.NewReportUsecase(mockRepo, time.Second*2)
+		products, err := u.Products(context.TODO(), &models.ReportFilter{From: from, To: to, Sort: "units", Limit: 2})
+
+		assert.NoError(t, err)
+		assert.Len(t, products, 2)
+		assert.Equal(t, int64(2), products[0].ProductID)
+		assert.Equal(t, int64(3), products[1].ProductID)
+	})
+
+	t.Run("unknown sort", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+
+		u := usecase.NewReportUsecase(mockRepo, time.Second*2)
+		_, err := u.Products(context.TODO(), &models.ReportFilter{From: from, To: to, Sort: "name"})
+
+		assert.Equal(t, models.ErrBadParamInput, err)
+	})
+}
+
+func TestCategories(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockRepo.On("ProductSales", mock.Anything, from, to).Return([]*models.ProductSales{
+		&models.ProductSales{ProductID: 10, Units: 2, Revenue: 200},
+		&models.ProductSales{ProductID: 11, Units: 1, Revenue: 30},
+	}, nil)
+	mockRepo.On("Categories", mock.Anything).Return([]*models.Category{
+		&models.Category{ID: 1, Name: "Fashion"},
+		&models.Category{ID: 2, Name: "Shirts", ParentID: null.IntFrom(1)},
+		&models.Category{ID: 3, Name: "Sale", ParentID: null.IntFrom(1)},
+		&models.Category{ID: 4, Name: "Books"},
+	}, nil)
+	mockRepo.On("ProductCategories", mock.Anything).Return([]*models.ProductCategory{
+		&models.ProductCategory{ProductID: 10, CategoryID: 2},
+		&models.ProductCategory{ProductID: 10, CategoryID: 3},
+		&models.ProductCategory{ProductID: 11, CategoryID: 3},
+	}, nil)
+
+	u := usecase.NewReportUsecase(mockRepo, time.Second*2)
+	categories, err := u.Categories(context.TODO(), &models.ReportFilter{From: from, To: to})
+
+	assert.NoError(t, err)
+	assert.Len(t, categories, 4)
+	assert.Equal(t, int64(1), categories[0].CategoryID)
+	assert.Equal(t, float64(230), categories[0].Revenue)
+	assert.Equal(t, int64(3), categories[0].Units)
+	assert.Equal(t, float64(230), categories[1].Revenue)
+	assert.Equal(t, float64(200), categories[2].Revenue)
+	assert.Equal(t, float64(0), categories[3].Revenue)
+}
@@ -0,0 +1,64 @@
+package returns
+
+import (
+	"math"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// Round to the cent
+func Round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// Credit price the lines of a return at what was paid for them on order.
+// Discounts of the order are spread over its items by their share of the
+// subtotal, the way tax was charged, and tax is credited in proportion of
+// the pieces sent back. Lines not on order or sending back more pieces than
+// were ordered are rejected.
+func Credit(order *models.Order, items []*models.ReturnItem) (*models.CreditNote, error) {
+	ordered := make(map[int64]*models.OrderItem, len(order.Items))
+	var subtotal float64
+	for _, item := range order.Items {
+		ordered[item.ID] = item
+		subtotal += item.Price * float64(item.Amount)
+	}
+
+	paid := 1.0
+	if subtotal > 0 {
+		paid = math.Max(subtotal-order.Discount, 0) / subtotal
+	}
+
+	note := &models.CreditNote{
+		OrderID: order.ID,
+		Lines:   make([]*models.CreditNoteLine, 0, len(items)),
+	}
+
+	for _, item := range items {
+		line, ok := ordered[item.OrderItemID]
+		if !ok || item.Amount <= 0 || item.Amount > line.Amount {
+			return nil, models.ErrBadParamInput
+		}
+
+		credit := &models.CreditNoteLine{
+			ProductID: line.ProductID,
+			Amount:    item.Amount,
+			Price:     Round(line.Price * paid),
+			TaxRate:   line.TaxRate,
+			Tax:       Round(line.Tax * float64(item.Amount) / float64(line.Amount)),
+		}
+
+		credit.Total = Round(line.Price * paid * float64(item.Amount))
+		if !order.TaxIncluded {
+			credit.Total = Round(credit.Total + credit.Tax)
+		}
+
+		note.Lines = append(note.Lines, credit)
+		note.Tax += credit.Tax
+		note.Amount += credit.Total
+	}
+
+	note.Tax = Round(note.Tax)
+	note.Amount = Round(note.Amount)
+	return note, nil
+}
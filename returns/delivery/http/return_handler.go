@@ -0,0 +1,287 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/returns"
+	"github.com/soerjadi/exam/utils"
+)
+
+type returnItemData struct {
+	OrderItemID int64 `json:"order_item_id"`
+	Amount      int64 `json:"amount"`
+}
+
+type requestData struct {
+	Reason string            `json:"reason"`
+	Items  []*returnItemData `json:"items"`
+}
+
+type approveData struct {
+	Refund  float64 `json:"refund"`
+	Restock bool    `json:"restock"`
+}
+
+// ReturnHandler represent the http handler for returns
+type ReturnHandler struct {
+	ReturnUsecase returns.Usecase
+}
+
+// NewReturnHandler initialize return resource endpoint
+func NewReturnHandler(router *mux.Router, usecase returns.Usecase) *mux.Router {
+	handler := &ReturnHandler{
+		ReturnUsecase: usecase,
+	}
+
+	router.HandleFunc("/v1/order/{id:[0-9]+}/return", handler.Request).Methods("POST")
+	router.HandleFunc("/v1/order/{id:[0-9]+}/return", handler.GetByOrder).Methods("GET")
+
+	r := router.PathPrefix("/v1/admin/return").Subrouter()
+	r.HandleFunc("/list", handler.List).Methods("GET")
+	r.HandleFunc("/detail", handler.Detail).Methods("GET")
+	r.HandleFunc("/{id:[0-9]+}/approve", handler.Approve).Methods("POST")
+	r.HandleFunc("/{id:[0-9]+}/reject", handler.Reject).Methods("POST")
+	r.HandleFunc("/{id:[0-9]+}/refund", handler.Refund).Methods("POST")
+
+	return r
+}
+
+func decode(r *http.Request, v interface{}) error {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, v)
+}
+
+func pathID(r *http.Request) (int64, error) {
+	return strconv.ParseInt(mux.Vars(r)["id"], 0, 64)
+}
+
+// errorStatus map errors of the return usecase to http status
+func errorStatus(err error) int32 {
+	switch err {
+	case models.ErrUnauthorized:
+		return http.StatusUnauthorized
+	case models.ErrNotFound:
+		return http.StatusNotFound
+	case models.ErrConflict:
+		return http.StatusConflict
+	case models.ErrPaymentProvider:
+		return http.StatusBadGateway
+	}
+
+	return http.StatusBadRequest
+}
+
+// Request endpoint for a customer to send back lines of an order
+func (h *ReturnHandler) Request(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := pathID(r)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var data requestData
+	if err := decode(r, &data); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ret := &models.Return{
+		OrderID: id,
+		Reason:  data.Reason,
+		Items:   make([]*models.ReturnItem, 0, len(data.Items)),
+	}
+
+	for _, item := range data.Items {
+		ret.Items = append(ret.Items, &models.ReturnItem{
+			OrderItemID: item.OrderItemID,
+			Amount:      item.Amount,
+		})
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	err = h.ReturnUsecase.Request(ctx, ret)
+
+	if err != nil {
+		utils.Error(w, errorStatus(err), err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, ret)
+}
+
+// GetByOrder endpoint to list the returns of an order
+func (h *ReturnHandler) GetByOrder(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	list, err := h.ReturnUsecase.GetByOrder(ctx, id)
+
+	if err != nil {
+		utils.Error(w, errorStatus(err), err.Error())
+		return
+	}
+
+	entriesResult := &utils.EntriesResponse{
+		Data:  list,
+		Found: int64(len(list)),
+	}
+
+	utils.JSON(w, http.StatusOK, entriesResult)
+}
+
+// List endpoint to list returns, of a status when one is asked
+func (h *ReturnHandler) List(w http.ResponseWriter, r *http.Request) {
+	var offset, limit int64
+	var err error
+
+	queryValues := r.URL.Query()
+
+	if queryValues.Get("offset") != "" {
+		offset, err = strconv.ParseInt(queryValues.Get("offset"), 0, 64)
+		if err != nil {
+			utils.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	limit = 10
+	if queryValues.Get("limit") != "" {
+		limit, err = strconv.ParseInt(queryValues.Get("limit"), 0, 64)
+		if err != nil {
+			utils.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	list, count, err := h.ReturnUsecase.GetList(ctx, queryValues.Get("status"), offset, limit)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entriesResult := &utils.EntriesResponse{
+		Data:  list,
+		Found: count,
+	}
+
+	utils.JSON(w, http.StatusOK, entriesResult)
+}
+
+// Detail endpoint to get a return with its items and credit note
+func (h *ReturnHandler) Detail(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 0, 64)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	ret, err := h.ReturnUsecase.GetByID(ctx, id)
+
+	if err != nil {
+		utils.Error(w, errorStatus(err), err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, ret)
+}
+
+// Approve endpoint to accept a return, restock its goods when asked and
+// refund it. The refund is what was paid for the lines unless a lower one is
+// sent.
+func (h *ReturnHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := pathID(r)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var data approveData
+	if err := decode(r, &data); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	ret, err := h.ReturnUsecase.Approve(ctx, id, data.Refund, data.Restock)
+
+	if err != nil {
+		utils.Error(w, errorStatus(err), err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, ret)
+}
+
+// Reject endpoint to refuse a return
+func (h *ReturnHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	h.change(w, r, h.ReturnUsecase.Reject)
+}
+
+// Refund endpoint to retry the refund of an approved return
+func (h *ReturnHandler) Refund(w http.ResponseWriter, r *http.Request) {
+	h.change(w, r, h.ReturnUsecase.Refund)
+}
+
+// change run fn on the return of the path
+func (h *ReturnHandler) change(w http.ResponseWriter, r *http.Request, fn func(context.Context, int64) (*models.Return, error)) {
+	id, err := pathID(r)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	ret, err := fn(ctx, id)
+
+	if err != nil {
+		utils.Error(w, errorStatus(err), err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, ret)
+}
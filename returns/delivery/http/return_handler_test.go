@@ -0,0 +1,76 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/soerjadi/exam/models"
+	returnHttp "github.com/soerjadi/exam/returns/delivery/http"
+	"github.com/soerjadi/exam/returns/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRequest(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("Request", mock.Anything, mock.MatchedBy(func(r *models.Return) bool {
+		return r.OrderID == 8 && r.Reason == "too small" && len(r.Items) == 1 && r.Items[0].OrderItemID == 11
+	})).Return(nil)
+
+	req, err := http.NewRequest("POST", "/v1/order/8/return",
+		strings.NewReader(`{"reason":"too small","items":[{"order_item_id":11,"amount":1}]}`))
+	assert.NoError(t, err)
+	req = mux.SetURLVars(req, map[string]string{"id": "8"})
+
+	handler := returnHttp.ReturnHandler{
+		ReturnUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.Request(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestGetByOrderOfAnotherCustomer(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("GetByOrder", mock.Anything, int64(8)).Return(nil, models.ErrNotFound)
+
+	req, err := http.NewRequest("GET", "/v1/order/8/return", nil)
+	assert.NoError(t, err)
+	req = mux.SetURLVars(req, map[string]string{"id": "8"})
+
+	handler := returnHttp.ReturnHandler{
+		ReturnUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.GetByOrder(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestApprove(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("Approve", mock.Anything, int64(5), 40000.0, true).
+		Return(&models.Return{ID: 5, OrderID: 8, Status: models.ReturnRefunded, Refund: 40000.0}, nil)
+
+	req, err := http.NewRequest("POST", "/v1/admin/return/5/approve", strings.NewReader(`{"refund":40000,"restock":true}`))
+	assert.NoError(t, err)
+	req = mux.SetURLVars(req, map[string]string{"id": "5"})
+
+	handler := returnHttp.ReturnHandler{
+		ReturnUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.Approve(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
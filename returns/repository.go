@@ -0,0 +1,20 @@
+package returns
+
+import (
+	"context"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// Repository represent the return repository contract
+type Repository interface {
+	GetByID(ctx context.Context, id int64) (*models.Return, error)
+	GetByOrder(ctx context.Context, orderID int64) ([]*models.Return, error)
+	GetByStatus(ctx context.Context, status string, offset int64, limit int64) ([]*models.Return, int64, error)
+	GetItems(ctx context.Context, returnID int64) ([]*models.ReturnItem, error)
+	GetCreditNote(ctx context.Context, returnID int64) (*models.CreditNote, error)
+	Create(ctx context.Context, rma *models.Return) error
+	Update(ctx context.Context, rma *models.Return) error
+	CreateCreditNote(ctx context.Context, note *models.CreditNote) error
+	LockItems(ctx context.Context, orderID int64) error
+}
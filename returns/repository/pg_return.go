@@ -0,0 +1,324 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/soerjadi/exam/database"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/returns"
+	"github.com/soerjadi/exam/utils"
+)
+
+type pgReturnRepository struct {
+	Conn *sql.DB
+}
+
+var logger = utils.LogBuilder(true)
+
+// NewPGReturnRepository is bridge to create an object from returns.Repository interface
+func NewPGReturnRepository(Conn *sql.DB) returns.Repository {
+	return &pgReturnRepository{Conn}
+}
+
+func (p *pgReturnRepository) fetch(ctx context.Context, query string, args ...interface{}) ([]*models.Return, error) {
+	rows, err := database.ExecutorFrom(ctx, p.Conn).QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Error(err)
+		}
+	}()
+
+	result := make([]*models.Return, 0)
+	for rows.Next() {
+		t := new(models.Return)
+
+		err = rows.Scan(
+			&t.ID,
+			&t.OrderID,
+			&t.CustomerID,
+			&t.Status,
+			&t.Reason,
+			&t.Restock,
+			&t.Refund,
+			&t.Created,
+			&t.Updated,
+			&t.Version,
+		)
+
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+
+		result = append(result, t)
+	}
+
+	return result, nil
+}
+
+func (p *pgReturnRepository) GetByID(ctx context.Context, id int64) (*models.Return, error) {
+	query := `SELECT id, order_id, customer_id, status, reason, restock, refund, created, updated, version
+		FROM returns WHERE id = ?`
+
+	list, err := p.fetch(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list) == 0 {
+		return nil, models.ErrNotFound
+	}
+
+	return list[0], nil
+}
+
+func (p *pgReturnRepository) GetByOrder(ctx context.Context, orderID int64) ([]*models.Return, error) {
+	query := `SELECT id, order_id, customer_id, status, reason, restock, refund, created, updated, version
+		FROM returns WHERE order_id = ? ORDER BY id`
+
+	return p.fetch(ctx, query, orderID)
+}
+
+// GetByStatus list returns in status, oldest first so they are handled in
+// the order they came. Every return is listed when status is empty.
+func (p *pgReturnRepository) GetByStatus(ctx context.Context, status string, offset int64, limit int64) ([]*models.Return, int64, error) {
+	filter := ""
+	args := make([]interface{}, 0, 3)
+	if status != "" {
+		filter = " WHERE status = ?"
+		args = append(args, status)
+	}
+
+	var count int64
+	err := p.Conn.QueryRowContext(ctx, "SELECT count(id) FROM returns"+filter, args...).Scan(&count)
+	if err != nil {
+		logger.Error(err)
+		return nil, 0, err
+	}
+
+	query := `SELECT id, order_id, customer_id, status, reason, restock, refund, created, updated, version
+		FROM returns` + filter + ` ORDER BY created OFFSET ? LIMIT ?`
+
+	result, err := p.fetch(ctx, query, append(args, offset, limit)...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return result, count, nil
+}
+
+func (p *pgReturnRepository) GetItems(ctx context.Context, returnID int64) ([]*models.ReturnItem, error) {
+	query := `SELECT id, return_id, order_item_id, product_id, amount FROM return_item WHERE return_id = ? ORDER BY id`
+
+	rows, err := database.ExecutorFrom(ctx, p.Conn).QueryContext(ctx, query, returnID)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Error(err)
+		}
+	}()
+
+	result := make([]*models.ReturnItem, 0)
+	for rows.Next() {
+		t := new(models.ReturnItem)
+
+		err = rows.Scan(&t.ID, &t.ReturnID, &t.OrderItemID, &t.ProductID, &t.Amount)
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+
+		result = append(result, t)
+	}
+
+	return result, nil
+}
+
+func (p *pgReturnRepository) GetCreditNote(ctx context.Context, returnID int64) (*models.CreditNote, error) {
+	query := `SELECT id, number, return_id, order_id, lines, tax, adjustment, amount, created
+		FROM credit_notes WHERE return_id = ?`
+
+	note := new(models.CreditNote)
+	var lines []byte
+
+	err := database.ExecutorFrom(ctx, p.Conn).QueryRowContext(ctx, query, returnID).Scan(
+		&note.ID,
+		&note.Number,
+		&note.ReturnID,
+		&note.OrderID,
+		&lines,
+		&note.Tax,
+		&note.Adjustment,
+		&note.Amount,
+		&note.Created,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, models.ErrNotFound
+	}
+
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	if err = json.Unmarshal(lines, &note.Lines); err != nil {
+		return nil, err
+	}
+
+	return note, nil
+}
+
+// Create store the return with its items
+func (p *pgReturnRepository) Create(ctx context.Context, ret *models.Return) error {
+	var lastID int64
+
+	err := database.RunInTx(ctx, p.Conn, func(tx *sql.Tx) error {
+		query := `INSERT INTO returns(order_id, customer_id, status, reason) VALUES(?, ?, ?, ?) returning id`
+
+		stmt, err := tx.PrepareContext(ctx, query)
+		if err != nil {
+			return err
+		}
+
+		result, err := stmt.ExecContext(ctx, ret.OrderID, ret.CustomerID, ret.Status, ret.Reason)
+		if err != nil {
+			return err
+		}
+
+		lastID, err = result.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		itemStmt, err := tx.PrepareContext(ctx, `INSERT INTO return_item(return_id, order_item_id, product_id, amount) VALUES(?, ?, ?, ?)`)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range ret.Items {
+			if _, err = itemStmt.ExecContext(ctx, lastID, item.OrderItemID, item.ProductID, item.Amount); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	ret.ID = lastID
+	ret.Version = 1
+	for _, item := range ret.Items {
+		item.ReturnID = lastID
+	}
+
+	return nil
+}
+
+// Update store the state of the return if it did not change since it was
+// read, models.ErrConflict is returned otherwise. It join the transaction
+// carried by ctx when there is one.
+func (p *pgReturnRepository) Update(ctx context.Context, ret *models.Return) error {
+	query := `UPDATE returns SET status = ?, restock = ?, refund = ?, updated = ?, version = version + 1
+		WHERE id = ? AND version = ?`
+
+	stmt, err := database.ExecutorFrom(ctx, p.Conn).PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	res, err := stmt.ExecContext(ctx, ret.Status, ret.Restock, ret.Refund, ret.Updated, ret.ID, ret.Version)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected != 1 {
+		return models.ErrConflict
+	}
+
+	ret.Version++
+	return nil
+}
+
+// CreateCreditNote store the credit note and number it after its id. It
+// join the transaction carried by ctx when there is one.
+func (p *pgReturnRepository) CreateCreditNote(ctx context.Context, note *models.CreditNote) error {
+	lines, err := json.Marshal(note.Lines)
+	if err != nil {
+		return err
+	}
+
+	return database.RunInTx(ctx, p.Conn, func(tx *sql.Tx) error {
+		query := `INSERT INTO credit_notes(return_id, order_id, lines, tax, adjustment, amount) VALUES(?, ?, ?, ?, ?, ?) returning id`
+
+		stmt, err := tx.PrepareContext(ctx, query)
+		if err != nil {
+			return err
+		}
+
+		result, err := stmt.ExecContext(ctx, note.ReturnID, note.OrderID, lines, note.Tax, note.Adjustment, note.Amount)
+		if err != nil {
+			return err
+		}
+
+		lastID, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		number := fmt.Sprintf("CN-%06d", lastID)
+		if _, err = tx.ExecContext(ctx, `UPDATE credit_notes SET number = ? WHERE id = ?`, number, lastID); err != nil {
+			return err
+		}
+
+		note.ID = lastID
+		note.Number = number
+		return nil
+	})
+}
+
+// LockItems lock the lines of an order until the transaction carried by ctx
+// end, so the pieces its returns send back are counted one return at a time
+func (p *pgReturnRepository) LockItems(ctx context.Context, orderID int64) error {
+	query := `SELECT id FROM order_item WHERE order_id = ? FOR UPDATE`
+
+	rows, err := database.ExecutorFrom(ctx, p.Conn).QueryContext(ctx, query, orderID)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Error(err)
+		}
+	}()
+
+	for rows.Next() {
+	}
+
+	return rows.Err()
+}
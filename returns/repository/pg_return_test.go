@@ -0,0 +1,149 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/soerjadi/exam/database"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/returns/repository"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v3"
+)
+
+var columns = []string{"id", "order_id", "customer_id", "status", "reason", "restock", "refund", "created", "updated", "version"}
+
+func TestGetByStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows(columns).
+		AddRow(1, 8, 4, models.ReturnRequested, "too small", false, 0, time.Now(), nil, 1)
+
+	mock.ExpectQuery("SELECT count\\(id\\) FROM returns WHERE status = \\?").WithArgs(models.ReturnRequested).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("SELECT (.+) FROM returns WHERE status = \\? ORDER BY created OFFSET \\? LIMIT \\?").
+		WithArgs(models.ReturnRequested, int64(0), int64(10)).WillReturnRows(rows)
+
+	r := repository.NewPGReturnRepository(db)
+	list, count, err := r.GetByStatus(context.TODO(), models.ReturnRequested, int64(0), int64(10))
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	assert.Len(t, list, 1)
+	assert.Equal(t, int64(4), list[0].CustomerID.Int64)
+}
+
+func TestGetCreditNote(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "number", "return_id", "order_id", "lines", "tax", "adjustment", "amount", "created"}).
+		AddRow(3, "CN-000003", 5, 8, []byte(`[{"product_id":2,"amount":1,"price":47500,"tax_rate":0.1,"tax":4750,"total":52250}]`),
+			4750.0, 0, 52250.0, time.Now())
+
+	mock.ExpectQuery("SELECT (.+) FROM credit_notes WHERE return_id = \\?").WithArgs(int64(5)).WillReturnRows(rows)
+
+	r := repository.NewPGReturnRepository(db)
+	note, err := r.GetCreditNote(context.TODO(), int64(5))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "CN-000003", note.Number)
+	assert.Len(t, note.Lines, 1)
+	assert.Equal(t, 52250.0, note.Lines[0].Total)
+}
+
+func TestCreate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	ret := &models.Return{OrderID: 8, CustomerID: null.IntFrom(4), Status: models.ReturnRequested, Reason: "too small",
+		Items: []*models.ReturnItem{&models.ReturnItem{OrderItemID: 11, ProductID: 2, Amount: 1}}}
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare("INSERT INTO returns\\(order_id, customer_id, status, reason\\)").ExpectExec().
+		WithArgs(int64(8), null.IntFrom(4), models.ReturnRequested, "too small").
+		WillReturnResult(sqlmock.NewResult(5, 1))
+	mock.ExpectPrepare("INSERT INTO return_item").ExpectExec().
+		WithArgs(int64(5), int64(11), int64(2), int64(1)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	r := repository.NewPGReturnRepository(db)
+	err = r.Create(context.TODO(), ret)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), ret.ID)
+	assert.Equal(t, int64(5), ret.Items[0].ReturnID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateConflict(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	ret := &models.Return{ID: 5, Status: models.ReturnApproved, Refund: 52250.0, Version: 1}
+
+	mock.ExpectPrepare("UPDATE returns SET (.+) WHERE id = \\? AND version = \\?").ExpectExec().
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	r := repository.NewPGReturnRepository(db)
+	err = r.Update(context.TODO(), ret)
+
+	assert.Equal(t, models.ErrConflict, err)
+	assert.Equal(t, int64(1), ret.Version)
+}
+
+func TestCreateCreditNote(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	note := &models.CreditNote{ReturnID: 5, OrderID: 8, Tax: 4750.0, Amount: 52250.0,
+		Lines: []*models.CreditNoteLine{&models.CreditNoteLine{ProductID: 2, Amount: 1, Price: 47500.0, Tax: 4750.0, Total: 52250.0}}}
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare("INSERT INTO credit_notes").ExpectExec().
+		WillReturnResult(sqlmock.NewResult(3, 1))
+	mock.ExpectExec("UPDATE credit_notes SET number = \\? WHERE id = \\?").WithArgs("CN-000003", int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	r := repository.NewPGReturnRepository(db)
+	err = r.CreateCreditNote(context.TODO(), note)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "CN-000003", note.Number)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLockItems(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM order_item WHERE order_id = \\? FOR UPDATE").WithArgs(int64(8)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11).AddRow(12))
+	mock.ExpectCommit()
+
+	r := repository.NewPGReturnRepository(db)
+	err = database.NewTransactor(db).WithinTransaction(context.TODO(), func(ctx context.Context) error {
+		return r.LockItems(ctx, int64(8))
+	})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
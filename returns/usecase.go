@@ -0,0 +1,18 @@
+package returns
+
+import (
+	"context"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// Usecase represent the return usecase
+type Usecase interface {
+	GetByID(ctx context.Context, id int64) (*models.Return, error)
+	GetByOrder(ctx context.Context, orderID int64) ([]*models.Return, error)
+	GetList(ctx context.Context, status string, offset int64, limit int64) ([]*models.Return, int64, error)
+	Request(ctx context.Context, rma *models.Return) error
+	Approve(ctx context.Context, id int64, refund float64, restock bool) (*models.Return, error)
+	Reject(ctx context.Context, id int64) (*models.Return, error)
+	Refund(ctx context.Context, id int64) (*models.Return, error)
+}
@@ -0,0 +1,422 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/soerjadi/exam/audit"
+	"github.com/soerjadi/exam/auth"
+	"github.com/soerjadi/exam/database"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/order"
+	"github.com/soerjadi/exam/payment"
+	"github.com/soerjadi/exam/product"
+	"github.com/soerjadi/exam/returns"
+	"github.com/soerjadi/exam/utils"
+	"gopkg.in/guregu/null.v3"
+)
+
+type returnUsecase struct {
+	repo           returns.Repository
+	orders         order.Usecase
+	products       product.Usecase
+	payments       payment.Usecase
+	tx             database.Transactor
+	audit          audit.Usecase
+	contextTimeout time.Duration
+}
+
+var logger = utils.LogBuilder(true)
+
+// NewReturnUsecase will create object that represent of returns.Usecase
+// interface, refunds are given back through the payments of the order
+func NewReturnUsecase(r returns.Repository, o order.Usecase, p product.Usecase, pay payment.Usecase, tx database.Transactor, a audit.Usecase, timeout time.Duration) returns.Usecase {
+	return &returnUsecase{
+		repo:           r,
+		orders:         o,
+		products:       p,
+		payments:       pay,
+		tx:             tx,
+		audit:          a,
+		contextTimeout: timeout,
+	}
+}
+
+func (r *returnUsecase) record(ctx context.Context, id int64, action string, before interface{}, after interface{}) {
+	if err := r.audit.Record(ctx, models.AuditReturn, id, action, before, after); err != nil {
+		logger.Error(err)
+	}
+}
+
+// owned return the order of id when it is visible to the caller, orders of
+// a customer are only visible to that customer
+func (r *returnUsecase) owned(ctx context.Context, id int64) (*models.Order, error) {
+	ord, err := r.orders.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if principal, ok := auth.PrincipalFrom(ctx); ok && principal.Allowed(auth.OrderRead) {
+		return ord, nil
+	}
+
+	customerID, ok := auth.CustomerID(ctx)
+	if !ok {
+		return nil, models.ErrUnauthorized
+	}
+
+	if !ord.CustomerID.Valid || ord.CustomerID.Int64 != customerID {
+		return nil, models.ErrNotFound
+	}
+
+	return ord, nil
+}
+
+// returned count the pieces of every line of an order sent back by its
+// returns in one of statuses
+func (r *returnUsecase) returned(ctx context.Context, orderID int64, statuses ...string) (map[int64]int64, error) {
+	list, err := r.repo.GetByOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[int64]int64)
+	for _, ret := range list {
+		counted := false
+		for _, status := range statuses {
+			counted = counted || ret.Status == status
+		}
+
+		if !counted {
+			continue
+		}
+
+		items, err := r.repo.GetItems(ctx, ret.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range items {
+			result[item.OrderItemID] += item.Amount
+		}
+	}
+
+	return result, nil
+}
+
+// detail fill the items and credit note of ret
+func (r *returnUsecase) detail(ctx context.Context, ret *models.Return) (*models.Return, error) {
+	var err error
+	ret.Items, err = r.repo.GetItems(ctx, ret.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	ret.CreditNote, err = r.repo.GetCreditNote(ctx, ret.ID)
+	if err != nil && err != models.ErrNotFound {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *returnUsecase) GetByID(ctx context.Context, id int64) (*models.Return, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.contextTimeout)
+	defer cancel()
+
+	ret, err := r.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.detail(ctx, ret)
+}
+
+// GetByOrder list the returns of an order visible to the caller
+func (r *returnUsecase) GetByOrder(ctx context.Context, orderID int64) ([]*models.Return, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.contextTimeout)
+	defer cancel()
+
+	if _, err := r.owned(ctx, orderID); err != nil {
+		return nil, err
+	}
+
+	list, err := r.repo.GetByOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ret := range list {
+		if _, err = r.detail(ctx, ret); err != nil {
+			return nil, err
+		}
+	}
+
+	return list, nil
+}
+
+func (r *returnUsecase) GetList(ctx context.Context, status string, offset int64, limit int64) ([]*models.Return, int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.contextTimeout)
+	defer cancel()
+
+	return r.repo.GetByStatus(ctx, status, offset, limit)
+}
+
+// Request open a return for lines of an order that reached the customer or
+// is about to. Pieces already sent back by returns not rejected can not be
+// returned again.
+func (r *returnUsecase) Request(ctx context.Context, ret *models.Return) error {
+	ctx, cancel := context.WithTimeout(ctx, r.contextTimeout)
+	defer cancel()
+
+	ord, err := r.owned(ctx, ret.OrderID)
+	if err != nil {
+		return err
+	}
+
+	switch ord.Status {
+	case models.OrderProccessed, models.OrderPartiallyShipped, models.OrderShipped, models.OrderCompleted:
+	default:
+		return models.ErrBadParamInput
+	}
+
+	if len(ret.Items) == 0 {
+		return models.ErrBadParamInput
+	}
+
+	ordered := make(map[int64]*models.OrderItem, len(ord.Items))
+	for _, item := range ord.Items {
+		ordered[item.ID] = item
+	}
+
+	err = r.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		// requests for the same order wait for each other, what they count as
+		// sent back stay so until this one is stored
+		if err := r.repo.LockItems(ctx, ord.ID); err != nil {
+			return err
+		}
+
+		returned, err := r.returned(ctx, ord.ID, models.ReturnRequested, models.ReturnApproved, models.ReturnRefunded)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range ret.Items {
+			line, ok := ordered[item.OrderItemID]
+			if !ok || item.Amount <= 0 || item.Amount > line.Amount-returned[line.ID] {
+				return models.ErrBadParamInput
+			}
+
+			returned[line.ID] += item.Amount
+			item.ProductID = line.ProductID
+		}
+
+		ret.CustomerID = ord.CustomerID
+		ret.Status = models.ReturnRequested
+		ret.Restock = false
+		ret.Refund = 0
+		ret.Created = time.Now()
+
+		return r.repo.Create(ctx, ret)
+	})
+	if err != nil {
+		return err
+	}
+
+	r.record(ctx, ret.ID, models.AuditCreate, nil, ret)
+	return nil
+}
+
+// Approve accept a requested return. Its goods are put back in stock when
+// restock is set and a credit note is issued for what was paid for them,
+// refund override the amount given back when it is not 0 but can not exceed
+// it. The order is marked returned in the same transaction once every piece
+// of it was sent back. The refund is then made, a return whose refund failed
+// stay approved until it is retried with Refund.
+func (r *returnUsecase) Approve(ctx context.Context, id int64, refund float64, restock bool) (*models.Return, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.contextTimeout)
+	defer cancel()
+
+	ret, err := r.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if ret.Status != models.ReturnRequested {
+		return nil, models.ErrBadParamInput
+	}
+
+	ret.Items, err = r.repo.GetItems(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *ret
+
+	var note *models.CreditNote
+	err = r.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		// approvals for the same order wait for each other, the last one see
+		// the others and close the order
+		if err := r.repo.LockItems(ctx, ret.OrderID); err != nil {
+			return err
+		}
+
+		ord, err := r.orders.GetByID(ctx, ret.OrderID)
+		if err != nil {
+			return err
+		}
+
+		note, err = returns.Credit(ord, ret.Items)
+		if err != nil {
+			return err
+		}
+
+		if refund < 0 || refund > note.Amount {
+			return models.ErrBadParamInput
+		}
+
+		if refund > 0 {
+			note.Adjustment = returns.Round(refund - note.Amount)
+			note.Amount = refund
+		}
+
+		note.ReturnID = ret.ID
+		ret.Status = models.ReturnApproved
+		ret.Restock = restock
+		ret.Refund = note.Amount
+		ret.Updated = null.NewTime(
+			time.Now(), true,
+		)
+
+		if err := r.repo.Update(ctx, ret); err != nil {
+			return err
+		}
+
+		if restock {
+			for _, item := range ret.Items {
+				if err := r.products.Restock(ctx, item.ProductID, item.Amount); err != nil {
+					return err
+				}
+			}
+		}
+
+		note.Created = time.Now()
+		if err := r.repo.CreateCreditNote(ctx, note); err != nil {
+			return err
+		}
+
+		return r.close(ctx, ord)
+	})
+
+	if err != nil {
+		*ret = before
+		return nil, err
+	}
+
+	ret.CreditNote = note
+	r.record(ctx, ret.ID, models.AuditUpdate, &before, ret)
+
+	return r.refund(ctx, ret)
+}
+
+// close mark an order returned once every piece of it was sent back by
+// approved returns
+func (r *returnUsecase) close(ctx context.Context, ord *models.Order) error {
+	returned, err := r.returned(ctx, ord.ID, models.ReturnApproved, models.ReturnRefunded)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range ord.Items {
+		if returned[item.ID] < item.Amount {
+			return nil
+		}
+	}
+
+	return r.orders.Update(ctx, &models.Order{ID: ord.ID, Status: models.OrderReturned, Version: ord.Version})
+}
+
+// refund give back the amount of an approved return through a captured
+// payment of its order with enough left on it
+func (r *returnUsecase) refund(ctx context.Context, ret *models.Return) (*models.Return, error) {
+	if ret.Refund > 0 {
+		payments, err := r.payments.GetByOrder(ctx, ret.OrderID)
+		if err != nil {
+			return nil, err
+		}
+
+		var pay *models.Payment
+		for _, candidate := range payments {
+			if candidate.Status == models.PaymentCaptured && candidate.Captured-candidate.Refunded >= ret.Refund {
+				pay = candidate
+				break
+			}
+		}
+
+		if pay == nil {
+			return nil, models.ErrBadParamInput
+		}
+
+		if _, err = r.payments.Refund(ctx, pay.ID, ret.Refund); err != nil {
+			return nil, err
+		}
+	}
+
+	before := *ret
+	ret.Status = models.ReturnRefunded
+	ret.Updated = null.NewTime(
+		time.Now(), true,
+	)
+
+	if err := r.repo.Update(ctx, ret); err != nil {
+		return nil, err
+	}
+
+	r.record(ctx, ret.ID, models.AuditUpdate, &before, ret)
+	return ret, nil
+}
+
+// Refund retry the refund of an approved return
+func (r *returnUsecase) Refund(ctx context.Context, id int64) (*models.Return, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.contextTimeout)
+	defer cancel()
+
+	ret, err := r.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if ret.Status != models.ReturnApproved {
+		return nil, models.ErrBadParamInput
+	}
+
+	return r.refund(ctx, ret)
+}
+
+// Reject refuse a requested return
+func (r *returnUsecase) Reject(ctx context.Context, id int64) (*models.Return, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.contextTimeout)
+	defer cancel()
+
+	ret, err := r.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if ret.Status != models.ReturnRequested {
+		return nil, models.ErrBadParamInput
+	}
+
+	before := *ret
+	ret.Status = models.ReturnRejected
+	ret.Updated = null.NewTime(
+		time.Now(), true,
+	)
+
+	if err = r.repo.Update(ctx, ret); err != nil {
+		return nil, err
+	}
+
+	r.record(ctx, ret.ID, models.AuditUpdate, &before, ret)
+	return ret, nil
+}
@@ -0,0 +1,281 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/soerjadi/exam/audit/audittest"
+	auditMocks "github.com/soerjadi/exam/audit/mocks"
+	"github.com/soerjadi/exam/auth"
+	"github.com/soerjadi/exam/models"
+	orderMocks "github.com/soerjadi/exam/order/mocks"
+	payMocks "github.com/soerjadi/exam/payment/mocks"
+	pMocks "github.com/soerjadi/exam/product/mocks"
+	"github.com/soerjadi/exam/returns"
+	"github.com/soerjadi/exam/returns/mocks"
+	"github.com/soerjadi/exam/returns/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gopkg.in/guregu/null.v3"
+)
+
+// inlineTx run the work straight away, the repositories are mocked
+type inlineTx struct{}
+
+func (inlineTx) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+type deps struct {
+	repo     *mocks.Repository
+	orders   *orderMocks.Usecase
+	products *pMocks.Usecase
+	payments *payMocks.Usecase
+	audit    *auditMocks.Usecase
+}
+
+func newDeps() *deps {
+	return &deps{
+		repo:     new(mocks.Repository),
+		orders:   new(orderMocks.Usecase),
+		products: new(pMocks.Usecase),
+		payments: new(payMocks.Usecase),
+		audit:    audittest.NewUsecase(),
+	}
+}
+
+func (d *deps) usecase() returns.Usecase {
+	return usecase.NewReturnUsecase(d.repo, d.orders, d.products, d.payments, inlineTx{}, d.audit, time.Second*2)
+}
+
+// shippedOrder is two pieces of product 2 and one of product 3, with a
+// discount of 5% of the subtotal and tax charged on top of the prices
+func shippedOrder() *models.Order {
+	return &models.Order{
+		ID:         8,
+		CustomerID: null.IntFrom(4),
+		Status:     models.OrderShipped,
+		Discount:   10000.0,
+		Version:    3,
+		Items: []*models.OrderItem{
+			&models.OrderItem{ID: 11, OrderID: 8, ProductID: 2, Amount: 2, Price: 50000.0, TaxRate: 0.1, Tax: 9500.0},
+			&models.OrderItem{ID: 12, OrderID: 8, ProductID: 3, Amount: 1, Price: 100000.0, TaxRate: 0.1, Tax: 9500.0},
+		},
+	}
+}
+
+func customer(id int64) context.Context {
+	return auth.WithClaims(context.TODO(), &auth.Claims{Subject: id})
+}
+
+func TestRequest(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		d := newDeps()
+		d.orders.On("GetByID", mock.Anything, int64(8)).Return(shippedOrder(), nil).Once()
+		d.repo.On("LockItems", mock.Anything, int64(8)).Return(nil).Once()
+		d.repo.On("GetByOrder", mock.Anything, int64(8)).Return([]*models.Return{}, nil).Once()
+		d.repo.On("Create", mock.Anything, mock.MatchedBy(func(r *models.Return) bool {
+			return r.Status == models.ReturnRequested && r.CustomerID.Int64 == 4 && r.Items[0].ProductID == 2
+		})).Return(nil).Once()
+
+		ret := &models.Return{OrderID: 8, Reason: "too small", Items: []*models.ReturnItem{
+			&models.ReturnItem{OrderItemID: 11, Amount: 2},
+		}}
+
+		err := d.usecase().Request(customer(4), ret)
+
+		assert.NoError(t, err)
+		d.repo.AssertExpectations(t)
+		audittest.AssertRecorded(t, d.audit, models.AuditReturn, ret.ID, models.AuditCreate, nil, ret)
+	})
+
+	t.Run("pieces already returned", func(t *testing.T) {
+		d := newDeps()
+		d.orders.On("GetByID", mock.Anything, int64(8)).Return(shippedOrder(), nil).Once()
+		d.repo.On("LockItems", mock.Anything, int64(8)).Return(nil).Once()
+		d.repo.On("GetByOrder", mock.Anything, int64(8)).Return([]*models.Return{
+			&models.Return{ID: 1, OrderID: 8, Status: models.ReturnApproved},
+			&models.Return{ID: 2, OrderID: 8, Status: models.ReturnRejected},
+		}, nil).Once()
+		d.repo.On("GetItems", mock.Anything, int64(1)).
+			Return([]*models.ReturnItem{&models.ReturnItem{ReturnID: 1, OrderItemID: 11, ProductID: 2, Amount: 1}}, nil).Once()
+
+		ret := &models.Return{OrderID: 8, Items: []*models.ReturnItem{
+			&models.ReturnItem{OrderItemID: 11, Amount: 2},
+		}}
+
+		err := d.usecase().Request(customer(4), ret)
+
+		assert.Equal(t, models.ErrBadParamInput, err)
+		d.repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		audittest.AssertNothingRecorded(t, d.audit)
+	})
+
+	t.Run("order of another customer", func(t *testing.T) {
+		d := newDeps()
+		d.orders.On("GetByID", mock.Anything, int64(8)).Return(shippedOrder(), nil).Once()
+
+		ret := &models.Return{OrderID: 8, Items: []*models.ReturnItem{
+			&models.ReturnItem{OrderItemID: 11, Amount: 1},
+		}}
+
+		err := d.usecase().Request(customer(5), ret)
+
+		assert.Equal(t, models.ErrNotFound, err)
+	})
+
+	t.Run("order not delivered", func(t *testing.T) {
+		order := shippedOrder()
+		order.Status = models.OrderPending
+
+		d := newDeps()
+		d.orders.On("GetByID", mock.Anything, int64(8)).Return(order, nil).Once()
+
+		ret := &models.Return{OrderID: 8, Items: []*models.ReturnItem{
+			&models.ReturnItem{OrderItemID: 11, Amount: 1},
+		}}
+
+		err := d.usecase().Request(customer(4), ret)
+
+		assert.Equal(t, models.ErrBadParamInput, err)
+	})
+}
+
+func TestApprove(t *testing.T) {
+	t.Run("whole order restocked and refunded", func(t *testing.T) {
+		items := []*models.ReturnItem{
+			&models.ReturnItem{ID: 1, ReturnID: 5, OrderItemID: 11, ProductID: 2, Amount: 2},
+			&models.ReturnItem{ID: 2, ReturnID: 5, OrderItemID: 12, ProductID: 3, Amount: 1},
+		}
+
+		d := newDeps()
+		d.repo.On("GetByID", mock.Anything, int64(5)).
+			Return(&models.Return{ID: 5, OrderID: 8, Status: models.ReturnRequested, Version: 1}, nil).Once()
+		d.repo.On("GetItems", mock.Anything, int64(5)).Return(items, nil)
+		d.repo.On("LockItems", mock.Anything, int64(8)).Return(nil).Once()
+		d.orders.On("GetByID", mock.Anything, int64(8)).Return(shippedOrder(), nil).Once()
+		d.repo.On("Update", mock.Anything, mock.AnythingOfType("*models.Return")).Return(nil).Twice()
+		d.products.On("Restock", mock.Anything, int64(2), int64(2)).Return(nil).Once()
+		d.products.On("Restock", mock.Anything, int64(3), int64(1)).Return(nil).Once()
+		d.repo.On("CreateCreditNote", mock.Anything, mock.MatchedBy(func(n *models.CreditNote) bool {
+			// 190000 paid for the goods and 19000 of tax
+			return n.ReturnID == 5 && n.Tax == 19000.0 && n.Amount == 209000.0 && n.Lines[0].Price == 47500.0
+		})).Return(nil).Once()
+		d.repo.On("GetByOrder", mock.Anything, int64(8)).
+			Return([]*models.Return{&models.Return{ID: 5, OrderID: 8, Status: models.ReturnApproved}}, nil).Once()
+		d.orders.On("Update", mock.Anything, mock.MatchedBy(func(o *models.Order) bool {
+			return o.ID == 8 && o.Status == models.OrderReturned
+		})).Return(nil).Once()
+		d.payments.On("GetByOrder", mock.Anything, int64(8)).Return([]*models.Payment{
+			&models.Payment{ID: 1, OrderID: 8, Status: models.PaymentFailed},
+			&models.Payment{ID: 2, OrderID: 8, Status: models.PaymentCaptured, Captured: 219000.0},
+		}, nil).Once()
+		d.payments.On("Refund", mock.Anything, int64(2), 209000.0).Return(&models.Payment{ID: 2}, nil).Once()
+
+		ret, err := d.usecase().Approve(context.TODO(), int64(5), 0, true)
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.ReturnRefunded, ret.Status)
+		assert.Equal(t, 209000.0, ret.Refund)
+		d.repo.AssertExpectations(t)
+		d.orders.AssertExpectations(t)
+		d.products.AssertExpectations(t)
+		d.payments.AssertExpectations(t)
+	})
+
+	t.Run("refund failure leave the return approved", func(t *testing.T) {
+		items := []*models.ReturnItem{
+			&models.ReturnItem{ID: 1, ReturnID: 5, OrderItemID: 11, ProductID: 2, Amount: 1},
+		}
+
+		d := newDeps()
+		d.repo.On("GetByID", mock.Anything, int64(5)).
+			Return(&models.Return{ID: 5, OrderID: 8, Status: models.ReturnRequested, Version: 1}, nil).Once()
+		d.repo.On("GetItems", mock.Anything, int64(5)).Return(items, nil)
+		d.repo.On("LockItems", mock.Anything, int64(8)).Return(nil).Once()
+		d.orders.On("GetByID", mock.Anything, int64(8)).Return(shippedOrder(), nil).Once()
+		d.repo.On("Update", mock.Anything, mock.MatchedBy(func(r *models.Return) bool {
+			return r.Status == models.ReturnApproved && r.Refund == 40000.0 && !r.Restock
+		})).Return(nil).Once()
+		d.repo.On("CreateCreditNote", mock.Anything, mock.MatchedBy(func(n *models.CreditNote) bool {
+			// 52250 is credited for the piece, 12250 of it kept back
+			return n.Amount == 40000.0 && n.Adjustment == -12250.0
+		})).Return(nil).Once()
+		d.repo.On("GetByOrder", mock.Anything, int64(8)).
+			Return([]*models.Return{&models.Return{ID: 5, OrderID: 8, Status: models.ReturnApproved}}, nil).Once()
+		d.payments.On("GetByOrder", mock.Anything, int64(8)).Return([]*models.Payment{
+			&models.Payment{ID: 2, OrderID: 8, Status: models.PaymentCaptured, Captured: 219000.0},
+		}, nil).Once()
+		d.payments.On("Refund", mock.Anything, int64(2), 40000.0).Return(nil, models.ErrPaymentProvider).Once()
+
+		_, err := d.usecase().Approve(context.TODO(), int64(5), 40000.0, false)
+
+		assert.Equal(t, models.ErrPaymentProvider, err)
+		d.repo.AssertExpectations(t)
+		audittest.AssertRecorded(t, d.audit, models.AuditReturn, int64(5), models.AuditUpdate,
+			mock.MatchedBy(func(r *models.Return) bool { return r.Status == models.ReturnRequested }),
+			mock.MatchedBy(func(r *models.Return) bool {
+				return r.Status == models.ReturnApproved && r.Refund == 40000.0 && r.CreditNote != nil
+			}))
+		d.products.AssertNotCalled(t, "Restock", mock.Anything, mock.Anything, mock.Anything)
+		d.orders.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("order changed meanwhile", func(t *testing.T) {
+		items := []*models.ReturnItem{
+			&models.ReturnItem{ID: 1, ReturnID: 5, OrderItemID: 11, ProductID: 2, Amount: 2},
+			&models.ReturnItem{ID: 2, ReturnID: 5, OrderItemID: 12, ProductID: 3, Amount: 1},
+		}
+
+		d := newDeps()
+		d.repo.On("GetByID", mock.Anything, int64(5)).
+			Return(&models.Return{ID: 5, OrderID: 8, Status: models.ReturnRequested, Version: 1}, nil).Once()
+		d.repo.On("GetItems", mock.Anything, int64(5)).Return(items, nil)
+		d.repo.On("LockItems", mock.Anything, int64(8)).Return(nil).Once()
+		d.orders.On("GetByID", mock.Anything, int64(8)).Return(shippedOrder(), nil).Once()
+		d.repo.On("Update", mock.Anything, mock.AnythingOfType("*models.Return")).Return(nil).Once()
+		d.repo.On("CreateCreditNote", mock.Anything, mock.AnythingOfType("*models.CreditNote")).Return(nil).Once()
+		d.repo.On("GetByOrder", mock.Anything, int64(8)).
+			Return([]*models.Return{&models.Return{ID: 5, OrderID: 8, Status: models.ReturnApproved}}, nil).Once()
+		d.orders.On("Update", mock.Anything, mock.MatchedBy(func(o *models.Order) bool {
+			return o.ID == 8 && o.Status == models.OrderReturned && o.Version == 3
+		})).Return(models.ErrConflict).Once()
+
+		ret, err := d.usecase().Approve(context.TODO(), int64(5), 0, false)
+
+		// the approval is rolled back with the order left open, nothing is refunded
+		assert.Equal(t, models.ErrConflict, err)
+		assert.Nil(t, ret)
+		d.orders.AssertExpectations(t)
+		d.payments.AssertNotCalled(t, "Refund", mock.Anything, mock.Anything, mock.Anything)
+		audittest.AssertNothingRecorded(t, d.audit)
+	})
+
+	t.Run("refund more than credited", func(t *testing.T) {
+		d := newDeps()
+		d.repo.On("GetByID", mock.Anything, int64(5)).
+			Return(&models.Return{ID: 5, OrderID: 8, Status: models.ReturnRequested, Version: 1}, nil).Once()
+		d.repo.On("GetItems", mock.Anything, int64(5)).
+			Return([]*models.ReturnItem{&models.ReturnItem{ID: 1, ReturnID: 5, OrderItemID: 11, ProductID: 2, Amount: 1}}, nil).Once()
+		d.repo.On("LockItems", mock.Anything, int64(8)).Return(nil).Once()
+		d.orders.On("GetByID", mock.Anything, int64(8)).Return(shippedOrder(), nil).Once()
+
+		_, err := d.usecase().Approve(context.TODO(), int64(5), 60000.0, false)
+
+		assert.Equal(t, models.ErrBadParamInput, err)
+		d.repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+}
+
+func TestReject(t *testing.T) {
+	d := newDeps()
+	d.repo.On("GetByID", mock.Anything, int64(5)).
+		Return(&models.Return{ID: 5, OrderID: 8, Status: models.ReturnRefunded, Version: 3}, nil).Once()
+
+	_, err := d.usecase().Reject(context.TODO(), int64(5))
+
+	assert.Equal(t, models.ErrBadParamInput, err)
+	d.repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	audittest.AssertNothingRecorded(t, d.audit)
+}
@@ -1,11 +1,75 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/soerjadi/exam/auth"
+	"github.com/soerjadi/exam/customer"
 	"github.com/soerjadi/exam/database"
+	"github.com/soerjadi/exam/middleware"
+	"github.com/soerjadi/exam/payment"
+	"github.com/soerjadi/exam/ratelimit"
+	"github.com/soerjadi/exam/scheduler"
+	"github.com/soerjadi/exam/stream"
 	"github.com/soerjadi/exam/utils"
+	"github.com/soerjadi/exam/webhook"
+
+	addrHttp "github.com/soerjadi/exam/address/delivery/http"
+	addrRepo "github.com/soerjadi/exam/address/repository"
+	addrUsecase "github.com/soerjadi/exam/address/usecase"
+
+	shipHttp "github.com/soerjadi/exam/shipping/delivery/http"
+	shipRepo "github.com/soerjadi/exam/shipping/repository"
+	shipUsecase "github.com/soerjadi/exam/shipping/usecase"
+
+	taxHttp "github.com/soerjadi/exam/tax/delivery/http"
+	taxRepo "github.com/soerjadi/exam/tax/repository"
+	taxUsecase "github.com/soerjadi/exam/tax/usecase"
+
+	payHttp "github.com/soerjadi/exam/payment/delivery/http"
+	payRepo "github.com/soerjadi/exam/payment/repository"
+	payUsecase "github.com/soerjadi/exam/payment/usecase"
+
+	retHttp "github.com/soerjadi/exam/returns/delivery/http"
+	retRepo "github.com/soerjadi/exam/returns/repository"
+	retUsecase "github.com/soerjadi/exam/returns/usecase"
+
+	shipmentHttp "github.com/soerjadi/exam/fulfilment/delivery/http"
+	shipmentRepo "github.com/soerjadi/exam/fulfilment/repository"
+	shipmentUsecase "github.com/soerjadi/exam/fulfilment/usecase"
+
+	invHttp "github.com/soerjadi/exam/invoice/delivery/http"
+	invRepo "github.com/soerjadi/exam/invoice/repository"
+	invUsecase "github.com/soerjadi/exam/invoice/usecase"
+
+	repHttp "github.com/soerjadi/exam/report/delivery/http"
+	repRepo "github.com/soerjadi/exam/report/repository"
+	repUsecase "github.com/soerjadi/exam/report/usecase"
+
+	recHttp "github.com/soerjadi/exam/recommendation/delivery/http"
+	recRepo "github.com/soerjadi/exam/recommendation/repository"
+	recUsecase "github.com/soerjadi/exam/recommendation/usecase"
+
+	evRepo "github.com/soerjadi/exam/event/repository"
+	evUsecase "github.com/soerjadi/exam/event/usecase"
+
+	hookHttp "github.com/soerjadi/exam/webhook/delivery/http"
+	hookRepo "github.com/soerjadi/exam/webhook/repository"
+	hookUsecase "github.com/soerjadi/exam/webhook/usecase"
+
+	idemRepo "github.com/soerjadi/exam/idempotency/repository"
+	idemUsecase "github.com/soerjadi/exam/idempotency/usecase"
+
+	keyHttp "github.com/soerjadi/exam/apikey/delivery/http"
+	keyRepo "github.com/soerjadi/exam/apikey/repository"
+	keyUsecase "github.com/soerjadi/exam/apikey/usecase"
+
+	aHttp "github.com/soerjadi/exam/audit/delivery/http"
+	aRepo "github.com/soerjadi/exam/audit/repository"
+	aUsecase "github.com/soerjadi/exam/audit/usecase"
 
 	pHttp "github.com/soerjadi/exam/product/delivery/http"
 	pRepo "github.com/soerjadi/exam/product/repository"
@@ -15,35 +79,337 @@ import (
 	cRepo "github.com/soerjadi/exam/category/repository"
 	cUsecase "github.com/soerjadi/exam/category/usecase"
 
+	priceRepo "github.com/soerjadi/exam/product_price/repository"
+	priceUsecase "github.com/soerjadi/exam/product_price/usecase"
+
 	catRepo "github.com/soerjadi/exam/product_category/repository"
 	cateUsecase "github.com/soerjadi/exam/product_category/usecase"
 
+	cartHttp "github.com/soerjadi/exam/cart/delivery/http"
+	cartRepo "github.com/soerjadi/exam/cart/repository"
+	cartUsecase "github.com/soerjadi/exam/cart/usecase"
+
+	couponHttp "github.com/soerjadi/exam/coupon/delivery/http"
+	couponRepo "github.com/soerjadi/exam/coupon/repository"
+	couponUsecase "github.com/soerjadi/exam/coupon/usecase"
+
+	promoHttp "github.com/soerjadi/exam/promotion/delivery/http"
+	promoRepo "github.com/soerjadi/exam/promotion/repository"
+	promoUsecase "github.com/soerjadi/exam/promotion/usecase"
+
+	customerHttp "github.com/soerjadi/exam/customer/delivery/http"
+	customerRepo "github.com/soerjadi/exam/customer/repository"
+	customerUsecase "github.com/soerjadi/exam/customer/usecase"
+
 	oHttp "github.com/soerjadi/exam/order/delivery/http"
 	oRepo "github.com/soerjadi/exam/order/repository"
 	oUsecase "github.com/soerjadi/exam/order/usecase"
 )
 
+// routePermissions declare the permission every back-office route need, keyed
+// by method and path template. Routes not listed are public, storefront
+// writes (cart, placing an order and the customer account flows) included.
+var routePermissions = map[string]auth.Permission{
+	"GET /v1/category/trash":                          auth.CatalogRead,
+	"POST /v1/category/add":                           auth.CatalogWrite,
+	"POST /v1/category/update":                        auth.CatalogWrite,
+	"GET /v1/category/delete":                         auth.CatalogWrite,
+	"GET /v1/category/restore":                        auth.CatalogWrite,
+	"GET /v1/product/trash":                           auth.CatalogRead,
+	"GET /v1/admin/product/detail":                    auth.CatalogRead,
+	"GET /v1/admin/product/search":                    auth.CatalogRead,
+	"POST /v1/product/add":                            auth.CatalogWrite,
+	"POST /v1/product/update":                         auth.CatalogWrite,
+	"GET /v1/product/delete":                          auth.CatalogWrite,
+	"GET /v1/product/restore":                         auth.CatalogWrite,
+	"POST /v1/product/{id:[0-9]+}/prices":             auth.CatalogWrite,
+	"POST /v1/admin/product/status":                   auth.CatalogWrite,
+	"POST /v1/promotion/add":                          auth.CatalogWrite,
+	"POST /v1/promotion/update":                       auth.CatalogWrite,
+	"GET /v1/promotion/delete":                        auth.CatalogWrite,
+	"GET /v1/coupon/detail":                           auth.CatalogRead,
+	"GET /v1/coupon/list":                             auth.CatalogRead,
+	"POST /v1/coupon/generate":                        auth.CatalogWrite,
+	"GET /v1/coupon/deactivate":                       auth.CatalogWrite,
+	"GET /v1/order/list":                              auth.OrderRead,
+	"GET /v1/order/stream":                            auth.OrderRead,
+	"POST /v1/order/stream/token":                     auth.OrderRead,
+	"GET /v1/order/{id:[0-9]+}/price_audit":           auth.OrderRead,
+	"GET /v1/order/{id:[0-9]+}/payment":               auth.OrderRead,
+	"POST /v1/admin/payment/{id:[0-9]+}/capture":      auth.OrderWrite,
+	"POST /v1/admin/payment/{id:[0-9]+}/refund":       auth.OrderWrite,
+	"POST /v1/admin/payment/{id:[0-9]+}/void":         auth.OrderWrite,
+	"GET /v1/admin/return/list":                       auth.OrderRead,
+	"GET /v1/admin/return/detail":                     auth.OrderRead,
+	"POST /v1/admin/return/{id:[0-9]+}/approve":       auth.OrderWrite,
+	"POST /v1/admin/return/{id:[0-9]+}/reject":        auth.OrderWrite,
+	"POST /v1/admin/return/{id:[0-9]+}/refund":        auth.OrderWrite,
+	"POST /v1/admin/shipment/add":                     auth.OrderWrite,
+	"GET /v1/admin/shipment/detail":                   auth.OrderRead,
+	"POST /v1/admin/shipment/{id:[0-9]+}/ship":        auth.OrderWrite,
+	"POST /v1/admin/shipment/{id:[0-9]+}/deliver":     auth.OrderWrite,
+	"POST /v1/admin/shipment/{id:[0-9]+}/cancel":      auth.OrderWrite,
+	"GET /v1/admin/shipment/{id:[0-9]+}/packing_slip": auth.OrderRead,
+	"GET /v1/admin/report/summary":                    auth.ReportRead,
+	"GET /v1/admin/report/sales":                      auth.ReportRead,
+	"GET /v1/admin/report/products":                   auth.ReportRead,
+	"GET /v1/admin/report/categories":                 auth.ReportRead,
+	"POST /v1/order/update":                           auth.OrderWrite,
+	"GET /v1/order/delete":                            auth.OrderWrite,
+	"GET /v1/audit":                                   auth.AuditRead,
+	"GET /v1/admin/role":                              auth.RoleManage,
+	"GET /v1/admin/customer/{id:[0-9]+}/roles":        auth.RoleManage,
+	"POST /v1/admin/customer/{id:[0-9]+}/roles":       auth.RoleManage,
+	"GET /v1/admin/customer/{id:[0-9]+}/roles/remove": auth.RoleManage,
+	"POST /v1/admin/apikey/add":                       auth.KeyManage,
+	"GET /v1/admin/apikey/list":                       auth.KeyManage,
+	"GET /v1/admin/apikey/detail":                     auth.KeyManage,
+	"GET /v1/admin/apikey/revoke":                     auth.KeyManage,
+	"POST /v1/admin/apikey/{id:[0-9]+}/rotate":        auth.KeyManage,
+	"GET /v1/admin/apikey/{id:[0-9]+}/usage":          auth.KeyManage,
+	"POST /v1/admin/webhook/add":                      auth.WebhookManage,
+	"GET /v1/admin/webhook/list":                      auth.WebhookManage,
+	"GET /v1/admin/webhook/detail":                    auth.WebhookManage,
+	"POST /v1/admin/webhook/update":                   auth.WebhookManage,
+	"GET /v1/admin/webhook/delete":                    auth.WebhookManage,
+	"GET /v1/admin/webhook/{id:[0-9]+}/deliveries":    auth.WebhookManage,
+	"POST /v1/admin/webhook/redeliver":                auth.WebhookManage,
+	"GET /v1/admin/shipping/zone/list":                auth.CatalogRead,
+	"GET /v1/admin/shipping/zone/detail":              auth.CatalogRead,
+	"POST /v1/admin/shipping/zone/add":                auth.CatalogWrite,
+	"POST /v1/admin/shipping/zone/update":             auth.CatalogWrite,
+	"GET /v1/admin/shipping/zone/delete":              auth.CatalogWrite,
+	"GET /v1/admin/shipping/method/list":              auth.CatalogRead,
+	"GET /v1/admin/shipping/method/detail":            auth.CatalogRead,
+	"POST /v1/admin/shipping/method/add":              auth.CatalogWrite,
+	"POST /v1/admin/shipping/method/update":           auth.CatalogWrite,
+	"GET /v1/admin/shipping/method/delete":            auth.CatalogWrite,
+	"GET /v1/admin/tax/class/list":                    auth.CatalogRead,
+	"GET /v1/admin/tax/class/detail":                  auth.CatalogRead,
+	"POST /v1/admin/tax/class/add":                    auth.CatalogWrite,
+	"POST /v1/admin/tax/class/update":                 auth.CatalogWrite,
+	"GET /v1/admin/tax/class/delete":                  auth.CatalogWrite,
+	"GET /v1/admin/tax/rate/list":                     auth.CatalogRead,
+	"GET /v1/admin/tax/rate/detail":                   auth.CatalogRead,
+	"POST /v1/admin/tax/rate/add":                     auth.CatalogWrite,
+	"POST /v1/admin/tax/rate/update":                  auth.CatalogWrite,
+	"GET /v1/admin/tax/rate/delete":                   auth.CatalogWrite,
+}
+
+// streamRoutes are the event streams browsers open with a stream token
+var streamRoutes = map[string]bool{
+	"GET /v1/order/stream": true,
+}
+
+// ratePolicies limit requests by group of routes, the first matching prefix
+// apply. The account flows share the strict "auth" buckets against password
+// guessing, counted by IP whoever make the request, every other route fall
+// back to "default".
+var ratePolicies = []ratelimit.Policy{
+	{Name: "auth", Prefix: "/v1/customer/login", IP: authLimit, Customer: authLimit, Key: authLimit, AlwaysIP: true},
+	{Name: "auth", Prefix: "/v1/customer/register", IP: authLimit, Customer: authLimit, Key: authLimit, AlwaysIP: true},
+	{Name: "auth", Prefix: "/v1/customer/password/", IP: authLimit, Customer: authLimit, Key: authLimit, AlwaysIP: true},
+	{Name: "auth", Prefix: "/v1/customer/verify", IP: authLimit, Customer: authLimit, Key: authLimit, AlwaysIP: true},
+	{Name: "auth", Prefix: "/v1/customer/token/", IP: authLimit, Customer: authLimit, Key: authLimit, AlwaysIP: true},
+	{Name: "admin", Prefix: "/v1/admin/", IP: authLimit,
+		Customer: ratelimit.Limit{Requests: 300, Per: time.Minute, Burst: 60},
+		Key:      ratelimit.Limit{Requests: 600, Per: time.Minute, Burst: 100}},
+	{Name: "default", Prefix: "/",
+		IP:       ratelimit.Limit{Requests: 120, Per: time.Minute, Burst: 40},
+		Customer: ratelimit.Limit{Requests: 300, Per: time.Minute, Burst: 60},
+		Key:      ratelimit.Limit{Requests: 1200, Per: time.Minute, Burst: 200}},
+}
+
+var authLimit = ratelimit.Limit{Requests: 10, Per: time.Minute, Burst: 5}
+
 // RegisterRouter --
-func RegisterRouter(router *mux.Router) *mux.Router {
+func RegisterRouter(router *mux.Router, midl *middleware.MuxMiddleware, sched *scheduler.Scheduler) *mux.Router {
 	// router.HandleFunc("/v1/info", HelloWorld).Methods("GET")
 
 	conn := database.RDB().DB()
 	timeout := time.Duration(utils.GetEnvInt("CONTEXT_TIMEOUT", 0)) * time.Second
+	retention := time.Duration(utils.GetEnvInt("PURGE_RETENTION_DAYS", 30)) * 24 * time.Hour
+	cartTTL := time.Duration(utils.GetEnvInt("CART_TTL_HOURS", 72)) * time.Hour
+	taxIncluded := utils.GetEnv("PRICES_INCLUDE_TAX", "false") == "true"
+	taxOrigin := utils.GetEnv("TAX_ORIGIN_COUNTRY", "ID")
+	transactor := database.NewTransactor(conn)
+
+	secret := utils.GetEnv("AUTH_SECRET", "")
+	if secret == "" {
+		// tokens will not survive a restart, fine for development only
+		logger.Info("AUTH_SECRET is not set, using a random secret")
+		secret = utils.RandString(32)
+	}
+
+	signer := auth.NewSigner(secret, time.Duration(utils.GetEnvInt("ACCESS_TOKEN_TTL_MINUTES", 15))*time.Minute)
+	midl.Signer = signer
+	rateStore := ratelimit.NewMemoryStore()
+	midl.Permissions = routePermissions
+	midl.Streams = streamRoutes
+	midl.RateLimits = ratePolicies
+	midl.RateStore = rateStore
+	midl.TrustProxy = utils.GetEnv("TRUST_PROXY", "false") == "true"
+	idempotencyRepo := idemRepo.NewPGIdempotencyRepository(conn)
+	idempotencyUsecase := idemUsecase.NewIdempotencyUsecase(idempotencyRepo, timeout)
+	idempotencyTTL := time.Duration(utils.GetEnvInt("IDEMPOTENCY_TTL_HOURS", 24)) * time.Hour
+	midl.Idempotency = idempotencyUsecase
+	// throttled requests are refused before the credentials are looked up
+	router.Use(midl.RateLimitMiddleware)
+	router.Use(midl.AuthMiddleware)
+	router.Use(midl.PermissionMiddleware)
+	router.Use(midl.IdempotencyMiddleware)
+
+	auditRepo := aRepo.NewPGAuditRepository(conn)
+	auditUsecase := aUsecase.NewAuditUsecase(auditRepo, timeout)
+	aHttp.NewAuditHandler(router, auditUsecase)
+
+	eventRepo := evRepo.NewPGEventRepository(conn)
+	eventUsecase := evUsecase.NewEventUsecase(eventRepo, timeout)
+
+	webhookTimeout := time.Duration(utils.GetEnvInt("WEBHOOK_TIMEOUT_SECONDS", 10)) * time.Second
+	webhookRepo := hookRepo.NewPGWebhookRepository(conn)
+	webhookUsecase := hookUsecase.NewWebhookUsecase(webhookRepo, webhook.NewHTTPSender(webhookTimeout), auditUsecase, timeout)
+	hookHttp.NewWebhookHandler(router, webhookUsecase)
+
+	apiKeyRepo := keyRepo.NewPGAPIKeyRepository(conn)
+	apiKeyUsecase := keyUsecase.NewAPIKeyUsecase(apiKeyRepo, auditUsecase, timeout)
+	keyHttp.NewAPIKeyHandler(router, apiKeyUsecase)
+	midl.Keys = apiKeyUsecase
 
 	catRepo := catRepo.NewPGProductCategoryRepository(conn)
-	catUscase := cateUsecase.NewPCUsecase(catRepo, timeout)
+	catUscase := cateUsecase.NewPCUsecase(catRepo, auditUsecase, timeout)
+
+	productPriceRepo := priceRepo.NewPGProductPriceRepository(conn)
+	productPriceUsecase := priceUsecase.NewProductPriceUsecase(productPriceRepo, transactor, eventUsecase, auditUsecase, timeout)
 
 	categoryRepo := cRepo.NewPGCategoryRepository(conn)
-	categoryUsecase := cUsecase.NewCategoryUsecase(categoryRepo, timeout)
+	categoryUsecase := cUsecase.NewCategoryUsecase(categoryRepo, auditUsecase, timeout)
 	cHttp.NewCategoryHandler(router, categoryUsecase)
 
 	productRepo := pRepo.NewPGProductRepository(conn)
-	productUsecase := pUsecase.NewProductUsecase(productRepo, timeout)
-	pHttp.NewProductHandler(router, productUsecase, catUscase, categoryUsecase)
+	productUsecase := pUsecase.NewProductUsecase(productRepo, transactor, eventUsecase, auditUsecase, timeout)
+	pHttp.NewProductHandler(router, productUsecase, catUscase, categoryUsecase, productPriceUsecase)
+
+	promotionRepo := promoRepo.NewPGPromotionRepository(conn)
+	promotionUsecase := promoUsecase.NewPromotionUsecase(promotionRepo, catRepo, auditUsecase, timeout)
+	promoHttp.NewPromotionHandler(router, promotionUsecase)
+
+	couponRepo := couponRepo.NewPGCouponRepository(conn)
+	couponUsecase := couponUsecase.NewCouponUsecase(couponRepo, auditUsecase, timeout)
+	couponHttp.NewCouponHandler(router, couponUsecase)
+
+	taxRepo := taxRepo.NewPGTaxRepository(conn)
+	taxUsecase := taxUsecase.NewTaxUsecase(taxRepo, auditUsecase, taxIncluded, taxOrigin, timeout)
+	taxHttp.NewTaxHandler(router, taxUsecase)
+
+	// streams have to end before the 15 seconds write timeout of the server,
+	// clients reconnect and resume from the last event they received
+	orderBroker := stream.NewBroker(utils.GetEnvInt("STREAM_HISTORY", 1000), 64)
+	streamLifetime := time.Duration(utils.GetEnvInt("STREAM_LIFETIME_SECONDS", 10)) * time.Second
+	streamSigner := auth.NewSigner(secret, time.Duration(utils.GetEnvInt("STREAM_TOKEN_TTL_SECONDS", 60))*time.Second)
 
 	orderRepo := oRepo.NewPGOrderRepository(conn)
-	orderUsecase := oUsecase.NewOrderUsecase(orderRepo, timeout)
-	oHttp.NewOrderHandler(router, orderUsecase, productUsecase)
+	orderUsecase := oUsecase.NewOrderUsecase(orderRepo, transactor, eventUsecase, orderBroker, auditUsecase, timeout)
+	oHttp.NewOrderHandler(router, orderUsecase, productUsecase, productPriceUsecase, promotionUsecase, couponUsecase, taxUsecase)
+	oHttp.NewOrderStreamHandler(router, orderBroker, streamSigner, streamLifetime)
+
+	invoiceRepo := invRepo.NewPGInvoiceRepository(conn)
+	invoiceUsecase := invUsecase.NewInvoiceUsecase(invoiceRepo, orderUsecase, productUsecase, auditUsecase, timeout)
+	invHttp.NewInvoiceHandler(router, invoiceUsecase)
+
+	paymentSecret := utils.GetEnv("PAYMENT_CALLBACK_SECRET", "")
+	if paymentSecret == "" {
+		// nobody can sign callbacks, payments are only driven through the API
+		logger.Info("PAYMENT_CALLBACK_SECRET is not set, using a random secret")
+		paymentSecret = utils.RandString(32)
+	}
+
+	paymentProvider := payment.NewFakeProvider(utils.GetEnv("PAYMENT_FAKE_MODE", payment.FakeApprove), paymentSecret)
+	paymentRepo := payRepo.NewPGPaymentRepository(conn)
+	paymentUsecase := payUsecase.NewPaymentUsecase(paymentRepo, orderUsecase, invoiceUsecase, paymentProvider, auditUsecase, timeout)
+	payHttp.NewPaymentHandler(router, paymentUsecase)
+
+	returnRepo := retRepo.NewPGReturnRepository(conn)
+	returnUsecase := retUsecase.NewReturnUsecase(returnRepo, orderUsecase, productUsecase, paymentUsecase, transactor, auditUsecase, timeout)
+	retHttp.NewReturnHandler(router, returnUsecase)
+
+	fulfilmentRepo := shipmentRepo.NewPGShipmentRepository(conn)
+	fulfilmentUsecase := shipmentUsecase.NewFulfilmentUsecase(fulfilmentRepo, orderUsecase, productUsecase, transactor, auditUsecase, timeout)
+	shipmentHttp.NewShipmentHandler(router, fulfilmentUsecase)
+
+	reportRepo := repRepo.NewPGReportRepository(conn)
+	reportUsecase := repUsecase.NewReportUsecase(reportRepo, timeout)
+	repHttp.NewReportHandler(router, reportUsecase)
+
+	relatedWindow := time.Duration(utils.GetEnvInt("RECOMMENDATION_WINDOW_DAYS", 180)) * 24 * time.Hour
+	relatedSupport := int64(utils.GetEnvInt("RECOMMENDATION_MIN_SUPPORT", 2))
+	recommendationRepo := recRepo.NewPGRecommendationRepository(conn)
+	recommendationUsecase := recUsecase.NewRecommendationUsecase(recommendationRepo, productUsecase, relatedWindow, relatedSupport, timeout)
+	recHttp.NewRecommendationHandler(router, recommendationUsecase)
+
+	customerRepo := customerRepo.NewPGCustomerRepository(conn)
+	customerUsecase := customerUsecase.NewCustomerUsecase(customerRepo, customer.NewLogNotifier(), signer, auditUsecase, timeout)
+	customerHttp.NewCustomerHandler(router, customerUsecase, orderUsecase)
+	midl.Revocations = customerUsecase
+
+	addressRepo := addrRepo.NewPGAddressRepository(conn)
+	addressUsecase := addrUsecase.NewAddressUsecase(addressRepo, auditUsecase, timeout)
+	addrHttp.NewAddressHandler(router, addressUsecase)
+
+	shippingRepo := shipRepo.NewPGShippingRepository(conn)
+	shippingUsecase := shipUsecase.NewShippingUsecase(shippingRepo, auditUsecase, timeout)
+	shipHttp.NewShippingHandler(router, shippingUsecase)
+
+	cartRepo := cartRepo.NewPGCartRepository(conn)
+	cartUsecase := cartUsecase.NewCartUsecase(cartRepo, productUsecase, productPriceUsecase, promotionUsecase, couponUsecase,
+		orderUsecase, addressUsecase, shippingUsecase, taxUsecase, transactor, cartTTL, timeout)
+	cartHttp.NewCartHandler(router, cartUsecase)
+
+	sched.Register("publish_product", time.Minute, func(ctx context.Context) error {
+		changed, err := productUsecase.ApplySchedule(utils.WithActor(ctx, "scheduler"))
+		logger.Debug(fmt.Sprintf("changed status of %d products", changed))
+		return err
+	})
+	sched.Register("purge_product", time.Hour, func(ctx context.Context) error {
+		purged, err := productUsecase.Purge(ctx, retention)
+		logger.Debug(fmt.Sprintf("purged %d products", purged))
+		return err
+	})
+	sched.Register("purge_cart", time.Hour, func(ctx context.Context) error {
+		purged, err := cartUsecase.PurgeExpired(ctx)
+		logger.Debug(fmt.Sprintf("purged %d expired carts", purged))
+		return err
+	})
+	sched.Register("purge_token", time.Hour, func(ctx context.Context) error {
+		purged, err := customerUsecase.PurgeExpiredTokens(ctx)
+		logger.Debug(fmt.Sprintf("purged %d expired tokens", purged))
+		return err
+	})
+	sched.Register("sweep_rate_limit", 10*time.Minute, func(ctx context.Context) error {
+		swept := rateStore.Sweep(time.Now(), time.Hour)
+		logger.Debug(fmt.Sprintf("swept %d idle rate limit buckets", swept))
+		return nil
+	})
+	sched.Register("purge_idempotency_key", time.Hour, func(ctx context.Context) error {
+		purged, err := idempotencyUsecase.Purge(ctx, idempotencyTTL)
+		logger.Debug(fmt.Sprintf("purged %d idempotency keys", purged))
+		return err
+	})
+	sched.Register("refresh_related_product", time.Hour, func(ctx context.Context) error {
+		pairs, err := recommendationUsecase.Refresh(ctx)
+		logger.Debug(fmt.Sprintf("found %d products bought together", pairs))
+		return err
+	})
+	sched.Register("dispatch_webhook", 10*time.Second, func(ctx context.Context) error {
+		delivered, err := webhookUsecase.Dispatch(ctx)
+		logger.Debug(fmt.Sprintf("delivered %d webhooks", delivered))
+		return err
+	})
+	sched.Register("purge_category", time.Hour, func(ctx context.Context) error {
+		purged, err := categoryUsecase.Purge(ctx, retention)
+		logger.Debug(fmt.Sprintf("purged %d categories", purged))
+		return err
+	})
 
 	return router
 }
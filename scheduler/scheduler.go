@@ -0,0 +1,93 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/soerjadi/exam/utils"
+)
+
+// Job is a unit of background work executed by the Scheduler
+type Job func(ctx context.Context) error
+
+type entry struct {
+	name     string
+	interval time.Duration
+	job      Job
+}
+
+// Scheduler run registered jobs periodically inside the running process
+type Scheduler struct {
+	mu      sync.Mutex
+	entries []entry
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+var logger = utils.LogBuilder(true)
+
+// New initialize an empty scheduler
+func New() *Scheduler {
+	return &Scheduler{}
+}
+
+// Register add job that will be executed every interval once the scheduler started.
+// Jobs registered after Start are not picked up.
+func (s *Scheduler) Register(name string, interval time.Duration, job Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries = append(s.entries, entry{
+		name:     name,
+		interval: interval,
+		job:      job,
+	})
+}
+
+// Start run every registered job in its own goroutine
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	for _, e := range s.entries {
+		s.wg.Add(1)
+		go s.run(ctx, e)
+	}
+}
+
+// Stop signal every job to stop and wait until the running ones are finished
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+
+	s.wg.Wait()
+}
+
+func (s *Scheduler) run(ctx context.Context, e entry) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			logger.Debug(fmt.Sprintf("running job %s", e.name))
+			if err := e.job(ctx); err != nil {
+				logger.Error(fmt.Sprintf("job %s: %s", e.name, err))
+			}
+		}
+	}
+}
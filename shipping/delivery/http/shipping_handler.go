@@ -0,0 +1,340 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/shipping"
+	"github.com/soerjadi/exam/utils"
+)
+
+type zoneData struct {
+	ID      int64                `json:"id"`
+	Name    string               `json:"name"`
+	Regions []*models.ZoneRegion `json:"regions"`
+}
+
+type methodData struct {
+	ID     int64                  `json:"id"`
+	ZoneID int64                  `json:"zone_id"`
+	Name   string                 `json:"name"`
+	Type   string                 `json:"type"`
+	Cost   float64                `json:"cost"`
+	Active bool                   `json:"active"`
+	Rates  []*models.ShippingRate `json:"rates"`
+}
+
+func (d zoneData) toModel() models.ShippingZone {
+	return models.ShippingZone{
+		ID:      d.ID,
+		Name:    d.Name,
+		Regions: d.Regions,
+	}
+}
+
+func (d methodData) toModel() models.ShippingMethod {
+	return models.ShippingMethod{
+		ID:     d.ID,
+		ZoneID: d.ZoneID,
+		Name:   d.Name,
+		Type:   d.Type,
+		Cost:   d.Cost,
+		Active: d.Active,
+		Rates:  d.Rates,
+	}
+}
+
+// ShippingHandler represent the http handler for shipping zones and methods
+type ShippingHandler struct {
+	ShippingUsecase shipping.Usecase
+}
+
+// NewShippingHandler initialize shipping resource endpoint
+func NewShippingHandler(router *mux.Router, usecase shipping.Usecase) *mux.Router {
+	handler := &ShippingHandler{
+		ShippingUsecase: usecase,
+	}
+
+	p := router.PathPrefix("/v1/admin/shipping").Subrouter()
+	p.HandleFunc("/zone/list", handler.ZoneList).Methods("GET")
+	p.HandleFunc("/zone/detail", handler.ZoneDetail).Methods("GET")
+	p.HandleFunc("/zone/add", handler.AddZone).Methods("POST")
+	p.HandleFunc("/zone/update", handler.UpdateZone).Methods("POST")
+	p.HandleFunc("/zone/delete", handler.DeleteZone).Methods("GET")
+	p.HandleFunc("/method/list", handler.MethodList).Methods("GET")
+	p.HandleFunc("/method/detail", handler.MethodDetail).Methods("GET")
+	p.HandleFunc("/method/add", handler.AddMethod).Methods("POST")
+	p.HandleFunc("/method/update", handler.UpdateMethod).Methods("POST")
+	p.HandleFunc("/method/delete", handler.DeleteMethod).Methods("GET")
+
+	return p
+}
+
+func decode(r *http.Request, v interface{}) error {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, v)
+}
+
+func queryID(r *http.Request, name string) (int64, error) {
+	return strconv.ParseInt(r.URL.Query().Get(name), 0, 64)
+}
+
+// ZoneList endpoint to list every shipping zone with its regions
+func (h *ShippingHandler) ZoneList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	zones, err := h.ShippingUsecase.GetZones(ctx)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entriesResult := &utils.EntriesResponse{
+		Data:  zones,
+		Found: int64(len(zones)),
+	}
+
+	utils.JSON(w, http.StatusOK, entriesResult)
+}
+
+// ZoneDetail endpoint to get a shipping zone from given ID
+func (h *ShippingHandler) ZoneDetail(w http.ResponseWriter, r *http.Request) {
+	id, err := queryID(r, "id")
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	zone, err := h.ShippingUsecase.GetZone(ctx, id)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, zone)
+}
+
+// AddZone endpoint to add a shipping zone from given body
+func (h *ShippingHandler) AddZone(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var data zoneData
+	if err := decode(r, &data); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	zone := data.toModel()
+	zone.ID = 0
+
+	err := h.ShippingUsecase.CreateZone(ctx, &zone)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, zone)
+}
+
+// UpdateZone endpoint to rename a shipping zone and replace its regions
+func (h *ShippingHandler) UpdateZone(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var data zoneData
+	if err := decode(r, &data); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	zone := data.toModel()
+	err := h.ShippingUsecase.UpdateZone(ctx, &zone)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, zone)
+}
+
+// DeleteZone endpoint to delete a shipping zone and its methods, orders keep
+// the shipping they were charged
+func (h *ShippingHandler) DeleteZone(w http.ResponseWriter, r *http.Request) {
+	id, err := queryID(r, "id")
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	err = h.ShippingUsecase.DeleteZone(ctx, id)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, "success")
+}
+
+// MethodList endpoint to list the shipping methods of a zone with their rate tables
+func (h *ShippingHandler) MethodList(w http.ResponseWriter, r *http.Request) {
+	zoneID, err := queryID(r, "zone_id")
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	methods, err := h.ShippingUsecase.GetMethods(ctx, zoneID)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entriesResult := &utils.EntriesResponse{
+		Data:  methods,
+		Found: int64(len(methods)),
+	}
+
+	utils.JSON(w, http.StatusOK, entriesResult)
+}
+
+// MethodDetail endpoint to get a shipping method from given ID
+func (h *ShippingHandler) MethodDetail(w http.ResponseWriter, r *http.Request) {
+	id, err := queryID(r, "id")
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	method, err := h.ShippingUsecase.GetMethod(ctx, id)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, method)
+}
+
+// AddMethod endpoint to add a shipping method from given body
+func (h *ShippingHandler) AddMethod(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var data methodData
+	if err := decode(r, &data); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	method := data.toModel()
+	method.ID = 0
+
+	err := h.ShippingUsecase.CreateMethod(ctx, &method)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, method)
+}
+
+// UpdateMethod endpoint to change a shipping method and replace its rate table
+func (h *ShippingHandler) UpdateMethod(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var data methodData
+	if err := decode(r, &data); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	method := data.toModel()
+	err := h.ShippingUsecase.UpdateMethod(ctx, &method)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, method)
+}
+
+// DeleteMethod endpoint to delete a shipping method
+func (h *ShippingHandler) DeleteMethod(w http.ResponseWriter, r *http.Request) {
+	id, err := queryID(r, "id")
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	err = h.ShippingUsecase.DeleteMethod(ctx, id)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, "success")
+}
@@ -0,0 +1,72 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/soerjadi/exam/models"
+	shippingHttp "github.com/soerjadi/exam/shipping/delivery/http"
+	"github.com/soerjadi/exam/shipping/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAddZone(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("CreateZone", mock.Anything, mock.MatchedBy(func(z *models.ShippingZone) bool {
+		return z.Name == "Java" && len(z.Regions) == 2 && z.Regions[1].Region == "Jawa Barat"
+	})).Return(nil)
+
+	body := `{"name":"Java","regions":[{"country":"ID","region":"DKI Jakarta"},{"country":"ID","region":"Jawa Barat"}]}`
+	req, err := http.NewRequest("POST", "/v1/admin/shipping/zone/add", strings.NewReader(body))
+	assert.NoError(t, err)
+
+	handler := shippingHttp.ShippingHandler{
+		ShippingUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.AddZone(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestAddMethodInvalid(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("CreateMethod", mock.Anything, mock.AnythingOfType("*models.ShippingMethod")).Return(models.ErrBadParamInput)
+
+	body := `{"zone_id":1,"name":"regular","type":"weight","rates":[]}`
+	req, err := http.NewRequest("POST", "/v1/admin/shipping/method/add", strings.NewReader(body))
+	assert.NoError(t, err)
+
+	handler := shippingHttp.ShippingHandler{
+		ShippingUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.AddMethod(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestMethodList(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("GetMethods", mock.Anything, int64(1)).
+		Return([]*models.ShippingMethod{&models.ShippingMethod{ID: 4, ZoneID: 1, Name: "same day"}}, nil)
+
+	req, err := http.NewRequest("GET", "/v1/admin/shipping/method/list?zone_id=1", nil)
+	assert.NoError(t, err)
+
+	handler := shippingHttp.ShippingHandler{
+		ShippingUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.MethodList(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
@@ -0,0 +1,45 @@
+package shipping
+
+import (
+	"math"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// BillableWeight return the weight charged for amount units of product, the
+// larger of the actual weight and the volumetric weight of its dimensions
+func BillableWeight(product *models.Product, amount int64) float64 {
+	volumetric := product.Length * product.Width * product.Height / models.VolumetricDivisor
+	return math.Max(product.Weight, volumetric) * float64(amount)
+}
+
+// Rate return the cost of shipping parcel with method. Rate tables charge
+// the line with the highest minimum the parcel reach, a parcel below every
+// line can not be shipped with the method.
+func Rate(method *models.ShippingMethod, parcel models.Parcel) (float64, error) {
+	var value float64
+
+	switch method.Type {
+	case models.ShippingFlat:
+		return method.Cost, nil
+	case models.ShippingWeight:
+		value = parcel.Weight
+	case models.ShippingPrice:
+		value = parcel.Subtotal
+	default:
+		return 0, models.ErrShippingUnavailable
+	}
+
+	var matched *models.ShippingRate
+	for _, rate := range method.Rates {
+		if rate.Min <= value && (matched == nil || rate.Min > matched.Min) {
+			matched = rate
+		}
+	}
+
+	if matched == nil {
+		return 0, models.ErrShippingUnavailable
+	}
+
+	return matched.Cost, nil
+}
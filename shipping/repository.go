@@ -0,0 +1,22 @@
+package shipping
+
+import (
+	"context"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// Repository represent the shipping zone and method repository contract
+type Repository interface {
+	GetZones(ctx context.Context) ([]*models.ShippingZone, error)
+	GetZone(ctx context.Context, id int64) (*models.ShippingZone, error)
+	FindZone(ctx context.Context, country string, region string) (*models.ShippingZone, error)
+	CreateZone(ctx context.Context, zone *models.ShippingZone) error
+	UpdateZone(ctx context.Context, zone *models.ShippingZone) error
+	DeleteZone(ctx context.Context, id int64) error
+	GetMethods(ctx context.Context, zoneID int64) ([]*models.ShippingMethod, error)
+	GetMethod(ctx context.Context, id int64) (*models.ShippingMethod, error)
+	CreateMethod(ctx context.Context, method *models.ShippingMethod) error
+	UpdateMethod(ctx context.Context, method *models.ShippingMethod) error
+	DeleteMethod(ctx context.Context, id int64) error
+}
@@ -0,0 +1,430 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/soerjadi/exam/database"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/shipping"
+	"github.com/soerjadi/exam/utils"
+)
+
+type pgShippingRepository struct {
+	Conn *sql.DB
+}
+
+var logger = utils.LogBuilder(true)
+
+// NewPGShippingRepository is bridge to create an object from shipping.Repository interface
+func NewPGShippingRepository(Conn *sql.DB) shipping.Repository {
+	return &pgShippingRepository{Conn}
+}
+
+func (p *pgShippingRepository) fetchZones(ctx context.Context, query string, args ...interface{}) ([]*models.ShippingZone, error) {
+	rows, err := p.Conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Error(err)
+		}
+	}()
+
+	result := make([]*models.ShippingZone, 0)
+	for rows.Next() {
+		t := new(models.ShippingZone)
+
+		err = rows.Scan(&t.ID, &t.Name, &t.Created)
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+
+		result = append(result, t)
+	}
+
+	return result, nil
+}
+
+func (p *pgShippingRepository) fetchMethods(ctx context.Context, query string, args ...interface{}) ([]*models.ShippingMethod, error) {
+	rows, err := p.Conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Error(err)
+		}
+	}()
+
+	result := make([]*models.ShippingMethod, 0)
+	for rows.Next() {
+		t := new(models.ShippingMethod)
+
+		err = rows.Scan(
+			&t.ID,
+			&t.ZoneID,
+			&t.Name,
+			&t.Type,
+			&t.Cost,
+			&t.Active,
+			&t.Created,
+			&t.Updated,
+		)
+
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+
+		result = append(result, t)
+	}
+
+	return result, nil
+}
+
+// regions fill the destinations covered by zones
+func (p *pgShippingRepository) regions(ctx context.Context, zones []*models.ShippingZone) error {
+	for _, zone := range zones {
+		rows, err := p.Conn.QueryContext(ctx, `SELECT zone_id, country, region FROM shipping_zone_region WHERE zone_id = ? ORDER BY country, region`, zone.ID)
+		if err != nil {
+			logger.Error(err)
+			return err
+		}
+
+		zone.Regions = make([]*models.ZoneRegion, 0)
+		for rows.Next() {
+			t := new(models.ZoneRegion)
+			if err = rows.Scan(&t.ZoneID, &t.Country, &t.Region); err != nil {
+				logger.Error(err)
+				_ = rows.Close()
+				return err
+			}
+
+			zone.Regions = append(zone.Regions, t)
+		}
+
+		if err = rows.Close(); err != nil {
+			logger.Error(err)
+		}
+	}
+
+	return nil
+}
+
+// rates fill the rate tables of methods, cheapest line first
+func (p *pgShippingRepository) rates(ctx context.Context, methods []*models.ShippingMethod) error {
+	for _, method := range methods {
+		rows, err := p.Conn.QueryContext(ctx, `SELECT id, method_id, min, cost FROM shipping_rates WHERE method_id = ? ORDER BY min`, method.ID)
+		if err != nil {
+			logger.Error(err)
+			return err
+		}
+
+		method.Rates = make([]*models.ShippingRate, 0)
+		for rows.Next() {
+			t := new(models.ShippingRate)
+			if err = rows.Scan(&t.ID, &t.MethodID, &t.Min, &t.Cost); err != nil {
+				logger.Error(err)
+				_ = rows.Close()
+				return err
+			}
+
+			method.Rates = append(method.Rates, t)
+		}
+
+		if err = rows.Close(); err != nil {
+			logger.Error(err)
+		}
+	}
+
+	return nil
+}
+
+func (p *pgShippingRepository) GetZones(ctx context.Context) ([]*models.ShippingZone, error) {
+	zones, err := p.fetchZones(ctx, `SELECT id, name, created FROM shipping_zones ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = p.regions(ctx, zones); err != nil {
+		return nil, err
+	}
+
+	return zones, nil
+}
+
+func (p *pgShippingRepository) GetZone(ctx context.Context, id int64) (*models.ShippingZone, error) {
+	zones, err := p.fetchZones(ctx, `SELECT id, name, created FROM shipping_zones WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(zones) == 0 {
+		return nil, models.ErrNotFound
+	}
+
+	if err = p.regions(ctx, zones); err != nil {
+		return nil, err
+	}
+
+	return zones[0], nil
+}
+
+// FindZone return the zone shipping to the region of country, a zone
+// listing the region win over one covering the whole country
+func (p *pgShippingRepository) FindZone(ctx context.Context, country string, region string) (*models.ShippingZone, error) {
+	query := `SELECT z.id, z.name, z.created FROM shipping_zones z JOIN shipping_zone_region r ON r.zone_id = z.id
+		WHERE r.country = ? AND (lower(r.region) = lower(?) OR r.region = '') ORDER BY r.region DESC, z.id LIMIT 1`
+
+	zones, err := p.fetchZones(ctx, query, country, region)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(zones) == 0 {
+		return nil, models.ErrNotFound
+	}
+
+	return zones[0], nil
+}
+
+// saveRegions replace the destinations covered by zone
+func saveRegions(ctx context.Context, tx *sql.Tx, zone *models.ShippingZone) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM shipping_zone_region WHERE zone_id = ?`, zone.ID); err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO shipping_zone_region(zone_id, country, region) VALUES(?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+
+	for _, region := range zone.Regions {
+		if _, err = stmt.ExecContext(ctx, zone.ID, region.Country, region.Region); err != nil {
+			logger.Error(err)
+			return err
+		}
+
+		region.ZoneID = zone.ID
+	}
+
+	return nil
+}
+
+func (p *pgShippingRepository) CreateZone(ctx context.Context, zone *models.ShippingZone) error {
+	return database.RunInTx(ctx, p.Conn, func(tx *sql.Tx) error {
+		stmt, err := tx.PrepareContext(ctx, `INSERT INTO shipping_zones(name) VALUES(?) returning id`)
+		if err != nil {
+			return err
+		}
+
+		result, err := stmt.ExecContext(ctx, zone.Name)
+		if err != nil {
+			return err
+		}
+
+		lastID, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		zone.ID = lastID
+		return saveRegions(ctx, tx, zone)
+	})
+}
+
+func (p *pgShippingRepository) UpdateZone(ctx context.Context, zone *models.ShippingZone) error {
+	return database.RunInTx(ctx, p.Conn, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `UPDATE shipping_zones SET name = ? WHERE id = ?`, zone.Name, zone.ID)
+		if err != nil {
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if affected != 1 {
+			return models.ErrNotFound
+		}
+
+		return saveRegions(ctx, tx, zone)
+	})
+}
+
+// DeleteZone remove the zone together with its methods
+func (p *pgShippingRepository) DeleteZone(ctx context.Context, id int64) error {
+	return database.RunInTx(ctx, p.Conn, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM shipping_rates WHERE method_id IN (SELECT id FROM shipping_methods WHERE zone_id = ?)`, id); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM shipping_methods WHERE zone_id = ?`, id); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM shipping_zone_region WHERE zone_id = ?`, id); err != nil {
+			return err
+		}
+
+		res, err := tx.ExecContext(ctx, `DELETE FROM shipping_zones WHERE id = ?`, id)
+		if err != nil {
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if affected != 1 {
+			return models.ErrNotFound
+		}
+
+		return nil
+	})
+}
+
+func (p *pgShippingRepository) GetMethods(ctx context.Context, zoneID int64) ([]*models.ShippingMethod, error) {
+	query := `SELECT id, zone_id, name, type, cost, active, created, updated FROM shipping_methods WHERE zone_id = ? ORDER BY id`
+
+	methods, err := p.fetchMethods(ctx, query, zoneID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = p.rates(ctx, methods); err != nil {
+		return nil, err
+	}
+
+	return methods, nil
+}
+
+func (p *pgShippingRepository) GetMethod(ctx context.Context, id int64) (*models.ShippingMethod, error) {
+	query := `SELECT id, zone_id, name, type, cost, active, created, updated FROM shipping_methods WHERE id = ?`
+
+	methods, err := p.fetchMethods(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(methods) == 0 {
+		return nil, models.ErrNotFound
+	}
+
+	if err = p.rates(ctx, methods); err != nil {
+		return nil, err
+	}
+
+	return methods[0], nil
+}
+
+// saveRates replace the rate table of method
+func saveRates(ctx context.Context, tx *sql.Tx, method *models.ShippingMethod) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM shipping_rates WHERE method_id = ?`, method.ID); err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO shipping_rates(method_id, min, cost) VALUES(?, ?, ?) returning id`)
+	if err != nil {
+		return err
+	}
+
+	for _, rate := range method.Rates {
+		result, err := stmt.ExecContext(ctx, method.ID, rate.Min, rate.Cost)
+		if err != nil {
+			logger.Error(err)
+			return err
+		}
+
+		rateID, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		rate.ID = rateID
+		rate.MethodID = method.ID
+	}
+
+	return nil
+}
+
+func (p *pgShippingRepository) CreateMethod(ctx context.Context, method *models.ShippingMethod) error {
+	return database.RunInTx(ctx, p.Conn, func(tx *sql.Tx) error {
+		query := `INSERT INTO shipping_methods(zone_id, name, type, cost, active) VALUES(?, ?, ?, ?, ?) returning id`
+
+		stmt, err := tx.PrepareContext(ctx, query)
+		if err != nil {
+			return err
+		}
+
+		result, err := stmt.ExecContext(ctx, method.ZoneID, method.Name, method.Type, method.Cost, method.Active)
+		if err != nil {
+			return err
+		}
+
+		lastID, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		method.ID = lastID
+		return saveRates(ctx, tx, method)
+	})
+}
+
+func (p *pgShippingRepository) UpdateMethod(ctx context.Context, method *models.ShippingMethod) error {
+	return database.RunInTx(ctx, p.Conn, func(tx *sql.Tx) error {
+		query := `UPDATE shipping_methods SET zone_id = ?, name = ?, type = ?, cost = ?, active = ?, updated = ? WHERE id = ?`
+
+		res, err := tx.ExecContext(ctx, query, method.ZoneID, method.Name, method.Type, method.Cost, method.Active, method.Updated, method.ID)
+		if err != nil {
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if affected != 1 {
+			return models.ErrNotFound
+		}
+
+		return saveRates(ctx, tx, method)
+	})
+}
+
+func (p *pgShippingRepository) DeleteMethod(ctx context.Context, id int64) error {
+	return database.RunInTx(ctx, p.Conn, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM shipping_rates WHERE method_id = ?`, id); err != nil {
+			return err
+		}
+
+		res, err := tx.ExecContext(ctx, `DELETE FROM shipping_methods WHERE id = ?`, id)
+		if err != nil {
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if affected != 1 {
+			return models.ErrNotFound
+		}
+
+		return nil
+	})
+}
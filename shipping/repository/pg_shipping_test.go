@@ -0,0 +1,157 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/shipping/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+var methodColumns = []string{"id", "zone_id", "name", "type", "cost", "active", "created", "updated"}
+
+func TestGetZone(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectQuery("SELECT id, name, created FROM shipping_zones WHERE id = \\?").WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "created"}).AddRow(1, "Java", time.Now()))
+	mock.ExpectQuery("SELECT zone_id, country, region FROM shipping_zone_region WHERE zone_id = \\?").WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"zone_id", "country", "region"}).
+			AddRow(1, "ID", "DKI Jakarta").
+			AddRow(1, "ID", "Jawa Barat"))
+
+	s := repository.NewPGShippingRepository(db)
+	zone, err := s.GetZone(context.TODO(), int64(1))
+
+	assert.NoError(t, err)
+	assert.Len(t, zone.Regions, 2)
+}
+
+func TestFindZone(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	query := "SELECT (.+) FROM shipping_zones z JOIN shipping_zone_region r (.+) ORDER BY r.region DESC"
+
+	t.Run("found", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs("ID", "DKI Jakarta").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "created"}).AddRow(1, "Java", time.Now()))
+
+		s := repository.NewPGShippingRepository(db)
+		zone, err := s.FindZone(context.TODO(), "ID", "DKI Jakarta")
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), zone.ID)
+	})
+
+	t.Run("not shipped", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs("SG", "").WillReturnRows(sqlmock.NewRows([]string{"id", "name", "created"}))
+
+		s := repository.NewPGShippingRepository(db)
+		_, err := s.FindZone(context.TODO(), "SG", "")
+
+		assert.Equal(t, models.ErrNotFound, err)
+	})
+}
+
+func TestCreateZone(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	zone := &models.ShippingZone{Name: "Java", Regions: []*models.ZoneRegion{{Country: "ID", Region: "DKI Jakarta"}}}
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare("INSERT INTO shipping_zones\\(name\\) VALUES\\(\\?\\) returning id").ExpectExec().WithArgs("Java").
+		WillReturnResult(sqlmock.NewResult(3, 1))
+	mock.ExpectExec("DELETE FROM shipping_zone_region WHERE zone_id = \\?").WithArgs(int64(3)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare("INSERT INTO shipping_zone_region\\(zone_id, country, region\\)").ExpectExec().
+		WithArgs(int64(3), "ID", "DKI Jakarta").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	s := repository.NewPGShippingRepository(db)
+	err = s.CreateZone(context.TODO(), zone)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), zone.Regions[0].ZoneID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetMethods(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectQuery("SELECT (.+) FROM shipping_methods WHERE zone_id = \\?").WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows(methodColumns).
+			AddRow(4, 1, "same day", models.ShippingFlat, 25000.0, true, time.Now(), nil).
+			AddRow(5, 1, "regular", models.ShippingWeight, 0.0, true, time.Now(), nil))
+	mock.ExpectQuery("SELECT id, method_id, min, cost FROM shipping_rates WHERE method_id = \\?").WithArgs(int64(4)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "method_id", "min", "cost"}))
+	mock.ExpectQuery("SELECT id, method_id, min, cost FROM shipping_rates WHERE method_id = \\?").WithArgs(int64(5)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "method_id", "min", "cost"}).
+			AddRow(1, 5, 0.0, 9000.0).
+			AddRow(2, 5, 5.0, 15000.0))
+
+	s := repository.NewPGShippingRepository(db)
+	methods, err := s.GetMethods(context.TODO(), int64(1))
+
+	assert.NoError(t, err)
+	assert.Len(t, methods, 2)
+	assert.Len(t, methods[0].Rates, 0)
+	assert.Len(t, methods[1].Rates, 2)
+}
+
+func TestCreateMethod(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	method := &models.ShippingMethod{ZoneID: 1, Name: "regular", Type: models.ShippingWeight, Active: true,
+		Rates: []*models.ShippingRate{{Min: 0, Cost: 9000}}}
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare("INSERT INTO shipping_methods\\(zone_id, name, type, cost, active\\)").ExpectExec().
+		WithArgs(int64(1), "regular", models.ShippingWeight, 0.0, true).WillReturnResult(sqlmock.NewResult(5, 1))
+	mock.ExpectExec("DELETE FROM shipping_rates WHERE method_id = \\?").WithArgs(int64(5)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare("INSERT INTO shipping_rates\\(method_id, min, cost\\)").ExpectExec().
+		WithArgs(int64(5), 0.0, 9000.0).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	s := repository.NewPGShippingRepository(db)
+	err = s.CreateMethod(context.TODO(), method)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), method.ID)
+	assert.Equal(t, int64(5), method.Rates[0].MethodID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteMethod(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM shipping_rates WHERE method_id = \\?").WithArgs(int64(5)).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("DELETE FROM shipping_methods WHERE id = \\?").WithArgs(int64(5)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	s := repository.NewPGShippingRepository(db)
+	err = s.DeleteMethod(context.TODO(), int64(5))
+
+	assert.Equal(t, models.ErrNotFound, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
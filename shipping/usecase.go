@@ -0,0 +1,23 @@
+package shipping
+
+import (
+	"context"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// Usecase represent the shipping usecase
+type Usecase interface {
+	GetZones(ctx context.Context) ([]*models.ShippingZone, error)
+	GetZone(ctx context.Context, id int64) (*models.ShippingZone, error)
+	CreateZone(ctx context.Context, zone *models.ShippingZone) error
+	UpdateZone(ctx context.Context, zone *models.ShippingZone) error
+	DeleteZone(ctx context.Context, id int64) error
+	GetMethods(ctx context.Context, zoneID int64) ([]*models.ShippingMethod, error)
+	GetMethod(ctx context.Context, id int64) (*models.ShippingMethod, error)
+	CreateMethod(ctx context.Context, method *models.ShippingMethod) error
+	UpdateMethod(ctx context.Context, method *models.ShippingMethod) error
+	DeleteMethod(ctx context.Context, id int64) error
+	Quote(ctx context.Context, destination *models.Address, parcel models.Parcel) ([]*models.ShippingQuote, error)
+	Cost(ctx context.Context, methodID int64, destination *models.Address, parcel models.Parcel) (*models.ShippingQuote, error)
+}
@@ -0,0 +1,311 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/soerjadi/exam/audit"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/shipping"
+	"github.com/soerjadi/exam/utils"
+	"gopkg.in/guregu/null.v3"
+)
+
+type shippingUsecase struct {
+	repo           shipping.Repository
+	audit          audit.Usecase
+	contextTimeout time.Duration
+}
+
+var logger = utils.LogBuilder(true)
+
+// NewShippingUsecase will create object that represent of shipping.Usecase interface
+func NewShippingUsecase(s shipping.Repository, a audit.Usecase, timeout time.Duration) shipping.Usecase {
+	return &shippingUsecase{
+		repo:           s,
+		audit:          a,
+		contextTimeout: timeout,
+	}
+}
+
+func (s *shippingUsecase) record(ctx context.Context, id int64, action string, before interface{}, after interface{}) {
+	if err := s.audit.Record(ctx, models.AuditShipping, id, action, before, after); err != nil {
+		logger.Error(err)
+	}
+}
+
+// validateZone reject zones covering nothing, country codes are upper cased
+func validateZone(zone *models.ShippingZone) error {
+	if zone.Name == "" || len(zone.Regions) == 0 {
+		return models.ErrBadParamInput
+	}
+
+	for _, region := range zone.Regions {
+		region.Country = strings.ToUpper(strings.TrimSpace(region.Country))
+		region.Region = strings.TrimSpace(region.Region)
+
+		if len(region.Country) != 2 {
+			return models.ErrBadParamInput
+		}
+	}
+
+	return nil
+}
+
+// validateMethod reject methods that could never be charged
+func validateMethod(method *models.ShippingMethod) error {
+	if method.Name == "" || method.ZoneID == 0 || method.Cost < 0 {
+		return models.ErrBadParamInput
+	}
+
+	switch method.Type {
+	case models.ShippingFlat:
+	case models.ShippingWeight, models.ShippingPrice:
+		if len(method.Rates) == 0 {
+			return models.ErrBadParamInput
+		}
+	default:
+		return models.ErrBadParamInput
+	}
+
+	for _, rate := range method.Rates {
+		if rate.Min < 0 || rate.Cost < 0 {
+			return models.ErrBadParamInput
+		}
+	}
+
+	return nil
+}
+
+func (s *shippingUsecase) GetZones(ctx context.Context) ([]*models.ShippingZone, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	return s.repo.GetZones(ctx)
+}
+
+func (s *shippingUsecase) GetZone(ctx context.Context, id int64) (*models.ShippingZone, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	return s.repo.GetZone(ctx, id)
+}
+
+func (s *shippingUsecase) CreateZone(ctx context.Context, zone *models.ShippingZone) error {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	if err := validateZone(zone); err != nil {
+		return err
+	}
+
+	if err := s.repo.CreateZone(ctx, zone); err != nil {
+		return err
+	}
+
+	s.record(ctx, zone.ID, models.AuditCreate, nil, zone)
+	return nil
+}
+
+func (s *shippingUsecase) UpdateZone(ctx context.Context, zone *models.ShippingZone) error {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	if err := validateZone(zone); err != nil {
+		return err
+	}
+
+	before, err := s.repo.GetZone(ctx, zone.ID)
+	if err != nil {
+		return err
+	}
+
+	zone.Created = before.Created
+	if err = s.repo.UpdateZone(ctx, zone); err != nil {
+		return err
+	}
+
+	s.record(ctx, zone.ID, models.AuditUpdate, before, zone)
+	return nil
+}
+
+func (s *shippingUsecase) DeleteZone(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	exists, err := s.repo.GetZone(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err = s.repo.DeleteZone(ctx, id); err != nil {
+		return err
+	}
+
+	s.record(ctx, id, models.AuditDelete, exists, nil)
+	return nil
+}
+
+func (s *shippingUsecase) GetMethods(ctx context.Context, zoneID int64) ([]*models.ShippingMethod, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	return s.repo.GetMethods(ctx, zoneID)
+}
+
+func (s *shippingUsecase) GetMethod(ctx context.Context, id int64) (*models.ShippingMethod, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	return s.repo.GetMethod(ctx, id)
+}
+
+func (s *shippingUsecase) CreateMethod(ctx context.Context, method *models.ShippingMethod) error {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	if err := validateMethod(method); err != nil {
+		return err
+	}
+
+	if _, err := s.repo.GetZone(ctx, method.ZoneID); err != nil {
+		return err
+	}
+
+	if err := s.repo.CreateMethod(ctx, method); err != nil {
+		return err
+	}
+
+	s.record(ctx, method.ID, models.AuditCreate, nil, method)
+	return nil
+}
+
+func (s *shippingUsecase) UpdateMethod(ctx context.Context, method *models.ShippingMethod) error {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	if err := validateMethod(method); err != nil {
+		return err
+	}
+
+	before, err := s.repo.GetMethod(ctx, method.ID)
+	if err != nil {
+		return err
+	}
+
+	if method.ZoneID != before.ZoneID {
+		if _, err = s.repo.GetZone(ctx, method.ZoneID); err != nil {
+			return err
+		}
+	}
+
+	method.Created = before.Created
+	method.Updated = null.NewTime(
+		time.Now(), true,
+	)
+
+	if err = s.repo.UpdateMethod(ctx, method); err != nil {
+		return err
+	}
+
+	s.record(ctx, method.ID, models.AuditUpdate, before, method)
+	return nil
+}
+
+func (s *shippingUsecase) DeleteMethod(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	exists, err := s.repo.GetMethod(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err = s.repo.DeleteMethod(ctx, id); err != nil {
+		return err
+	}
+
+	s.record(ctx, id, models.AuditDelete, exists, nil)
+	return nil
+}
+
+// zoneOf return the zone shipping to destination
+func (s *shippingUsecase) zoneOf(ctx context.Context, destination *models.Address) (*models.ShippingZone, error) {
+	zone, err := s.repo.FindZone(ctx, strings.ToUpper(destination.Country), destination.Region)
+	if err == models.ErrNotFound {
+		return nil, models.ErrShippingUnavailable
+	}
+
+	return zone, err
+}
+
+// Quote list the active methods able to ship parcel to destination with
+// their cost, cheapest first
+func (s *shippingUsecase) Quote(ctx context.Context, destination *models.Address, parcel models.Parcel) ([]*models.ShippingQuote, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	zone, err := s.zoneOf(ctx, destination)
+	if err != nil {
+		return nil, err
+	}
+
+	methods, err := s.repo.GetMethods(ctx, zone.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	quotes := make([]*models.ShippingQuote, 0, len(methods))
+	for _, method := range methods {
+		if !method.Active {
+			continue
+		}
+
+		cost, err := shipping.Rate(method, parcel)
+		if err == models.ErrShippingUnavailable {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		quotes = append(quotes, &models.ShippingQuote{MethodID: method.ID, Name: method.Name, Cost: cost})
+	}
+
+	sort.SliceStable(quotes, func(i, j int) bool {
+		return quotes[i].Cost < quotes[j].Cost
+	})
+
+	return quotes, nil
+}
+
+// Cost return the cost of shipping parcel to destination with the method,
+// the method has to be active and ship to the zone of destination
+func (s *shippingUsecase) Cost(ctx context.Context, methodID int64, destination *models.Address, parcel models.Parcel) (*models.ShippingQuote, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	method, err := s.repo.GetMethod(ctx, methodID)
+	if err != nil {
+		return nil, err
+	}
+
+	zone, err := s.zoneOf(ctx, destination)
+	if err != nil {
+		return nil, err
+	}
+
+	if !method.Active || method.ZoneID != zone.ID {
+		return nil, models.ErrShippingUnavailable
+	}
+
+	cost, err := shipping.Rate(method, parcel)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ShippingQuote{MethodID: method.ID, Name: method.Name, Cost: cost}, nil
+}
@@ -0,0 +1,136 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/soerjadi/exam/audit/audittest"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/shipping/mocks"
+	"github.com/soerjadi/exam/shipping/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	java     = &models.ShippingZone{ID: 1, Name: "Java"}
+	sameDay  = &models.ShippingMethod{ID: 4, ZoneID: 1, Name: "same day", Type: models.ShippingFlat, Cost: 25000, Active: true}
+	byWeight = &models.ShippingMethod{ID: 5, ZoneID: 1, Name: "regular", Type: models.ShippingWeight, Active: true,
+		Rates: []*models.ShippingRate{{Min: 0, Cost: 9000}, {Min: 5, Cost: 15000}, {Min: 20, Cost: 40000}}}
+	freeAbove = &models.ShippingMethod{ID: 6, ZoneID: 1, Name: "free above 500000", Type: models.ShippingPrice, Active: true,
+		Rates: []*models.ShippingRate{{Min: 500000, Cost: 0}}}
+	retired = &models.ShippingMethod{ID: 7, ZoneID: 1, Name: "retired", Type: models.ShippingFlat, Cost: 1000}
+)
+
+func TestCreateZone(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		zone := models.ShippingZone{Name: "Java", Regions: []*models.ZoneRegion{{Country: "id", Region: " DKI Jakarta "}}}
+
+		mockRepo.On("CreateZone", mock.Anything, &zone).Return(nil).Once()
+
+		mockAudit := audittest.NewUsecase()
+		s := usecase.NewShippingUsecase(mockRepo, mockAudit, time.Second*2)
+		err := s.CreateZone(context.TODO(), &zone)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "ID", zone.Regions[0].Country)
+		assert.Equal(t, "DKI Jakarta", zone.Regions[0].Region)
+		mockRepo.AssertExpectations(t)
+		audittest.AssertRecorded(t, mockAudit, models.AuditShipping, zone.ID, models.AuditCreate, nil, &zone)
+	})
+
+	t.Run("without region", func(t *testing.T) {
+		mockAudit := audittest.NewUsecase()
+		s := usecase.NewShippingUsecase(new(mocks.Repository), mockAudit, time.Second*2)
+		err := s.CreateZone(context.TODO(), &models.ShippingZone{Name: "nowhere"})
+
+		assert.Equal(t, models.ErrBadParamInput, err)
+		audittest.AssertNothingRecorded(t, mockAudit)
+	})
+}
+
+func TestCreateMethodInvalid(t *testing.T) {
+	s := usecase.NewShippingUsecase(new(mocks.Repository), audittest.NewUsecase(), time.Second*2)
+
+	invalid := []models.ShippingMethod{
+		{Name: "no rates", ZoneID: 1, Type: models.ShippingWeight},
+		{Name: "unknown", ZoneID: 1, Type: models.ShippingFlat + "_rate"},
+		{Name: "negative", ZoneID: 1, Type: models.ShippingPrice, Rates: []*models.ShippingRate{{Min: 0, Cost: -1}}},
+		{Name: "no zone", Type: models.ShippingFlat},
+	}
+
+	for _, method := range invalid {
+		err := s.CreateMethod(context.TODO(), &method)
+		assert.Equal(t, models.ErrBadParamInput, err, method.Name)
+	}
+}
+
+func TestQuote(t *testing.T) {
+	dest := &models.Address{Country: "id", Region: "DKI Jakarta"}
+
+	t.Run("cheapest first", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockRepo.On("FindZone", mock.Anything, "ID", "DKI Jakarta").Return(java, nil).Once()
+		mockRepo.On("GetMethods", mock.Anything, java.ID).
+			Return([]*models.ShippingMethod{sameDay, byWeight, freeAbove, retired}, nil).Once()
+
+		s := usecase.NewShippingUsecase(mockRepo, audittest.NewUsecase(), time.Second*2)
+		quotes, err := s.Quote(context.TODO(), dest, models.Parcel{Weight: 6.2, Subtotal: 120000})
+
+		assert.NoError(t, err)
+		assert.Len(t, quotes, 2)
+		assert.Equal(t, byWeight.ID, quotes[0].MethodID)
+		assert.Equal(t, 15000.0, quotes[0].Cost)
+		assert.Equal(t, sameDay.ID, quotes[1].MethodID)
+	})
+
+	t.Run("outside every zone", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockRepo.On("FindZone", mock.Anything, "SG", "").Return(nil, models.ErrNotFound).Once()
+
+		s := usecase.NewShippingUsecase(mockRepo, audittest.NewUsecase(), time.Second*2)
+		_, err := s.Quote(context.TODO(), &models.Address{Country: "SG"}, models.Parcel{Weight: 1})
+
+		assert.Equal(t, models.ErrShippingUnavailable, err)
+	})
+}
+
+func TestCost(t *testing.T) {
+	dest := &models.Address{Country: "ID", Region: "Bali"}
+
+	t.Run("price based", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockRepo.On("GetMethod", mock.Anything, freeAbove.ID).Return(freeAbove, nil).Once()
+		mockRepo.On("FindZone", mock.Anything, "ID", "Bali").Return(java, nil).Once()
+
+		s := usecase.NewShippingUsecase(mockRepo, audittest.NewUsecase(), time.Second*2)
+		quote, err := s.Cost(context.TODO(), freeAbove.ID, dest, models.Parcel{Weight: 2, Subtotal: 650000})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0.0, quote.Cost)
+	})
+
+	t.Run("method of another zone", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockRepo.On("GetMethod", mock.Anything, byWeight.ID).Return(byWeight, nil).Once()
+		mockRepo.On("FindZone", mock.Anything, "ID", "Bali").Return(&models.ShippingZone{ID: 2, Name: "Outer islands"}, nil).Once()
+
+		s := usecase.NewShippingUsecase(mockRepo, audittest.NewUsecase(), time.Second*2)
+		_, err := s.Cost(context.TODO(), byWeight.ID, dest, models.Parcel{Weight: 2})
+
+		assert.Equal(t, models.ErrShippingUnavailable, err)
+	})
+
+	t.Run("below every rate", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockRepo.On("GetMethod", mock.Anything, freeAbove.ID).Return(freeAbove, nil).Once()
+		mockRepo.On("FindZone", mock.Anything, "ID", "Bali").Return(java, nil).Once()
+
+		s := usecase.NewShippingUsecase(mockRepo, audittest.NewUsecase(), time.Second*2)
+		_, err := s.Cost(context.TODO(), freeAbove.ID, dest, models.Parcel{Weight: 2, Subtotal: 100000})
+
+		assert.Equal(t, models.ErrShippingUnavailable, err)
+	})
+}
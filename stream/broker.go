@@ -0,0 +1,130 @@
+package stream
+
+import (
+	"sync"
+	"time"
+)
+
+// Message is an event published to the subscribers of a Broker
+type Message struct {
+	ID      int64
+	Type    string
+	Payload interface{}
+}
+
+// Filter tell whether a subscriber want msg, a nil Filter want everything
+type Filter func(msg *Message) bool
+
+// Publisher publish events to whoever is listening, it never block
+type Publisher interface {
+	Publish(eventType string, payload interface{})
+}
+
+// Subscription receive the messages matching its filter on C. C is closed
+// once the subscription is closed, by Close or by the broker when the
+// subscriber fell too far behind.
+type Subscription struct {
+	C <-chan *Message
+
+	ch     chan *Message
+	filter Filter
+	broker *Broker
+}
+
+// Broker is an in-process pub/sub, every instance of the server has its own.
+// It keep the last messages so a subscriber reconnecting can resume from the
+// last one it received. IDs start at the time the broker was created in
+// milliseconds so they keep increasing across restarts.
+type Broker struct {
+	mu      sync.Mutex
+	seq     int64
+	history []*Message
+	size    int
+	buffer  int
+	subs    map[*Subscription]bool
+}
+
+// NewBroker initialize a broker keeping the last history messages, a
+// subscriber with more than buffer messages waiting is dropped
+func NewBroker(history int, buffer int) *Broker {
+	return &Broker{
+		seq:    time.Now().UnixNano() / int64(time.Millisecond),
+		size:   history,
+		buffer: buffer,
+		subs:   make(map[*Subscription]bool),
+	}
+}
+
+// Publish send an event to the subscribers whose filter match it
+func (b *Broker) Publish(eventType string, payload interface{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.seq++
+	msg := &Message{ID: b.seq, Type: eventType, Payload: payload}
+
+	b.history = append(b.history, msg)
+	if len(b.history) > b.size {
+		b.history = append([]*Message(nil), b.history[len(b.history)-b.size:]...)
+	}
+
+	for sub := range b.subs {
+		if sub.filter != nil && !sub.filter(msg) {
+			continue
+		}
+
+		select {
+		case sub.ch <- msg:
+		default:
+			// it resume from its last message when it reconnect
+			b.remove(sub)
+		}
+	}
+}
+
+// Subscribe register filter and return the kept messages matching it which
+// were published after lastID, the oldest first. Nothing is missed nor
+// repeated between them and the ones received on the subscription. When
+// lastID is older than every kept message all of them are returned.
+func (b *Broker) Subscribe(lastID int64, filter Filter) (*Subscription, []*Message) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	backlog := make([]*Message, 0)
+	if lastID > 0 {
+		for _, msg := range b.history {
+			if msg.ID > lastID && (filter == nil || filter(msg)) {
+				backlog = append(backlog, msg)
+			}
+		}
+	}
+
+	ch := make(chan *Message, b.buffer)
+	sub := &Subscription{
+		C:      ch,
+		ch:     ch,
+		filter: filter,
+		broker: b,
+	}
+	b.subs[sub] = true
+
+	return sub, backlog
+}
+
+// Close stop the subscription, it is safe to call more than once
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+
+	s.broker.remove(s)
+}
+
+// remove must be called with the lock held
+func (b *Broker) remove(sub *Subscription) {
+	if !b.subs[sub] {
+		return
+	}
+
+	delete(b.subs, sub)
+	close(sub.ch)
+}
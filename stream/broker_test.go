@@ -0,0 +1,140 @@
+package stream_test
+
+import (
+	"testing"
+
+	"github.com/soerjadi/exam/stream"
+	"github.com/stretchr/testify/assert"
+)
+
+// ids return the IDs of msgs
+func ids(msgs []*stream.Message) []int64 {
+	result := make([]int64, 0, len(msgs))
+	for _, msg := range msgs {
+		result = append(result, msg.ID)
+	}
+
+	return result
+}
+
+// receive take the messages waiting on sub
+func receive(sub *stream.Subscription) []*stream.Message {
+	msgs := make([]*stream.Message, 0)
+	for {
+		select {
+		case msg, open := <-sub.C:
+			if !open {
+				return msgs
+			}
+			msgs = append(msgs, msg)
+		default:
+			return msgs
+		}
+	}
+}
+
+func TestHistoryTrimmed(t *testing.T) {
+	broker := stream.NewBroker(3, 10)
+	sub, backlog := broker.Subscribe(0, nil)
+	defer sub.Close()
+
+	assert.Empty(t, backlog)
+
+	for i := 0; i < 5; i++ {
+		broker.Publish("order.created", i)
+	}
+
+	published := receive(sub)
+	assert.Len(t, published, 5)
+	for i := 1; i < len(published); i++ {
+		assert.True(t, published[i].ID > published[i-1].ID)
+	}
+
+	// older than every kept message, only the last 3 are left
+	resumed, backlog := broker.Subscribe(published[0].ID-1, nil)
+	defer resumed.Close()
+
+	assert.Equal(t, ids(published[2:]), ids(backlog))
+}
+
+func TestResumeFromLastID(t *testing.T) {
+	broker := stream.NewBroker(10, 10)
+	sub, _ := broker.Subscribe(0, nil)
+
+	broker.Publish("order.created", 1)
+	broker.Publish("order.created", 2)
+	broker.Publish("order.status_changed", 1)
+
+	published := receive(sub)
+	sub.Close()
+
+	// the client got the first message before disconnecting
+	resumed, backlog := broker.Subscribe(published[0].ID, nil)
+	defer resumed.Close()
+
+	broker.Publish("order.status_changed", 2)
+	live := receive(resumed)
+
+	assert.Equal(t, ids(published[1:]), ids(backlog))
+	assert.Len(t, live, 1)
+	assert.Equal(t, 2, live[0].Payload)
+	assert.True(t, live[0].ID > backlog[len(backlog)-1].ID)
+}
+
+func TestSubscribeFilter(t *testing.T) {
+	broker := stream.NewBroker(10, 10)
+	created := func(msg *stream.Message) bool {
+		return msg.Type == "order.created"
+	}
+
+	first, _ := broker.Subscribe(0, nil)
+	broker.Publish("order.created", 1)
+	broker.Publish("order.status_changed", 1)
+	start := receive(first)[0].ID - 1
+	first.Close()
+
+	sub, backlog := broker.Subscribe(start, created)
+	defer sub.Close()
+
+	broker.Publish("order.status_changed", 2)
+	broker.Publish("order.created", 2)
+
+	assert.Len(t, backlog, 1)
+	assert.Equal(t, 1, backlog[0].Payload)
+
+	live := receive(sub)
+	assert.Len(t, live, 1)
+	assert.Equal(t, "order.created", live[0].Type)
+	assert.Equal(t, 2, live[0].Payload)
+}
+
+func TestSlowSubscriberDropped(t *testing.T) {
+	broker := stream.NewBroker(10, 1)
+	slow, _ := broker.Subscribe(0, nil)
+	other, _ := broker.Subscribe(0, nil)
+
+	broker.Publish("order.created", 1)
+	assert.Len(t, receive(other), 1)
+
+	// slow did not take the first message, there is no room for the second
+	broker.Publish("order.created", 2)
+	assert.Len(t, receive(other), 1)
+
+	msg, open := <-slow.C
+	assert.True(t, open)
+	assert.Equal(t, 1, msg.Payload)
+
+	_, open = <-slow.C
+	assert.False(t, open)
+
+	// the others keep receiving, closing twice is safe
+	broker.Publish("order.created", 3)
+	assert.Len(t, receive(other), 1)
+
+	slow.Close()
+	other.Close()
+	other.Close()
+
+	_, open = <-other.C
+	assert.False(t, open)
+}
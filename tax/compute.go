@@ -0,0 +1,82 @@
+package tax
+
+import (
+	"math"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// round to the cent, tax is rounded per line
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// Compute fill the tax of every item of order from the rate of its product,
+// items without rate are not taxed. Discounts of the order are spread over
+// the items by their share of the subtotal so tax is charged on what is paid.
+// Tax is added to the total unless prices already include it.
+func Compute(order *models.Order, rates map[int64]*models.TaxRate, included bool) {
+	var subtotal float64
+	for _, item := range order.Items {
+		subtotal += item.Price * float64(item.Amount)
+	}
+
+	paid := 1.0
+	if subtotal > 0 {
+		paid = math.Max(subtotal-order.Discount, 0) / subtotal
+	}
+
+	order.Tax = 0
+	order.TaxIncluded = included
+
+	for _, item := range order.Items {
+		item.TaxName, item.TaxRate, item.Tax = "", 0, 0
+
+		rate, ok := rates[item.ProductID]
+		if !ok || rate == nil {
+			continue
+		}
+
+		base := item.Price * float64(item.Amount) * paid
+		if included {
+			item.Tax = round(base * rate.Rate / (100 + rate.Rate))
+		} else {
+			item.Tax = round(base * rate.Rate / 100)
+		}
+
+		item.TaxName = rate.Name
+		item.TaxRate = rate.Rate
+		order.Tax += item.Tax
+	}
+
+	order.Tax = round(order.Tax)
+	if !included {
+		order.Total += order.Tax
+	}
+
+	order.Taxes = Breakdown(order.Items)
+}
+
+// Breakdown sum the tax of items by rate, in the order rates first appear
+func Breakdown(items []*models.OrderItem) []*models.OrderTax {
+	result := make([]*models.OrderTax, 0)
+	index := make(map[models.OrderTax]*models.OrderTax)
+
+	for _, item := range items {
+		if item.Tax == 0 && item.TaxName == "" {
+			continue
+		}
+
+		key := models.OrderTax{Name: item.TaxName, Rate: item.TaxRate}
+		line, ok := index[key]
+		if !ok {
+			line = &models.OrderTax{Name: item.TaxName, Rate: item.TaxRate}
+			index[key] = line
+			result = append(result, line)
+		}
+
+		line.Amount = round(line.Amount + item.Tax)
+	}
+
+	return result
+}
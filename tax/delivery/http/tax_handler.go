@@ -0,0 +1,336 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/tax"
+	"github.com/soerjadi/exam/utils"
+)
+
+type classData struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type rateData struct {
+	ID      int64   `json:"id"`
+	ClassID int64   `json:"class_id"`
+	Country string  `json:"country"`
+	Region  string  `json:"region"`
+	Name    string  `json:"name"`
+	Rate    float64 `json:"rate"`
+}
+
+func (d classData) toModel() models.TaxClass {
+	return models.TaxClass{
+		ID:   d.ID,
+		Name: d.Name,
+	}
+}
+
+func (d rateData) toModel() models.TaxRate {
+	return models.TaxRate{
+		ID:      d.ID,
+		ClassID: d.ClassID,
+		Country: d.Country,
+		Region:  d.Region,
+		Name:    d.Name,
+		Rate:    d.Rate,
+	}
+}
+
+// TaxHandler represent the http handler for tax classes and rates
+type TaxHandler struct {
+	TaxUsecase tax.Usecase
+}
+
+// NewTaxHandler initialize tax resource endpoint
+func NewTaxHandler(router *mux.Router, usecase tax.Usecase) *mux.Router {
+	handler := &TaxHandler{
+		TaxUsecase: usecase,
+	}
+
+	p := router.PathPrefix("/v1/admin/tax").Subrouter()
+	p.HandleFunc("/class/list", handler.ClassList).Methods("GET")
+	p.HandleFunc("/class/detail", handler.ClassDetail).Methods("GET")
+	p.HandleFunc("/class/add", handler.AddClass).Methods("POST")
+	p.HandleFunc("/class/update", handler.UpdateClass).Methods("POST")
+	p.HandleFunc("/class/delete", handler.DeleteClass).Methods("GET")
+	p.HandleFunc("/rate/list", handler.RateList).Methods("GET")
+	p.HandleFunc("/rate/detail", handler.RateDetail).Methods("GET")
+	p.HandleFunc("/rate/add", handler.AddRate).Methods("POST")
+	p.HandleFunc("/rate/update", handler.UpdateRate).Methods("POST")
+	p.HandleFunc("/rate/delete", handler.DeleteRate).Methods("GET")
+
+	return p
+}
+
+func decode(r *http.Request, v interface{}) error {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, v)
+}
+
+func queryID(r *http.Request, name string) (int64, error) {
+	return strconv.ParseInt(r.URL.Query().Get(name), 0, 64)
+}
+
+// ClassList endpoint to list every tax class
+func (h *TaxHandler) ClassList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	classes, err := h.TaxUsecase.GetClasses(ctx)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entriesResult := &utils.EntriesResponse{
+		Data:  classes,
+		Found: int64(len(classes)),
+	}
+
+	utils.JSON(w, http.StatusOK, entriesResult)
+}
+
+// ClassDetail endpoint to get a tax class from given ID
+func (h *TaxHandler) ClassDetail(w http.ResponseWriter, r *http.Request) {
+	id, err := queryID(r, "id")
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	class, err := h.TaxUsecase.GetClass(ctx, id)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, class)
+}
+
+// AddClass endpoint to add a tax class from given body
+func (h *TaxHandler) AddClass(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var data classData
+	if err := decode(r, &data); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	class := data.toModel()
+	class.ID = 0
+
+	err := h.TaxUsecase.CreateClass(ctx, &class)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, class)
+}
+
+// UpdateClass endpoint to rename a tax class
+func (h *TaxHandler) UpdateClass(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var data classData
+	if err := decode(r, &data); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	class := data.toModel()
+	err := h.TaxUsecase.UpdateClass(ctx, &class)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, class)
+}
+
+// DeleteClass endpoint to delete a tax class and its rates, products of the
+// class are no longer taxed
+func (h *TaxHandler) DeleteClass(w http.ResponseWriter, r *http.Request) {
+	id, err := queryID(r, "id")
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	err = h.TaxUsecase.DeleteClass(ctx, id)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, "success")
+}
+
+// RateList endpoint to list the rates of a tax class
+func (h *TaxHandler) RateList(w http.ResponseWriter, r *http.Request) {
+	classID, err := queryID(r, "class_id")
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	rates, err := h.TaxUsecase.GetRates(ctx, classID)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entriesResult := &utils.EntriesResponse{
+		Data:  rates,
+		Found: int64(len(rates)),
+	}
+
+	utils.JSON(w, http.StatusOK, entriesResult)
+}
+
+// RateDetail endpoint to get a tax rate from given ID
+func (h *TaxHandler) RateDetail(w http.ResponseWriter, r *http.Request) {
+	id, err := queryID(r, "id")
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	rate, err := h.TaxUsecase.GetRate(ctx, id)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, rate)
+}
+
+// AddRate endpoint to add a tax rate from given body
+func (h *TaxHandler) AddRate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var data rateData
+	if err := decode(r, &data); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	rate := data.toModel()
+	rate.ID = 0
+
+	err := h.TaxUsecase.CreateRate(ctx, &rate)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, rate)
+}
+
+// UpdateRate endpoint to change a tax rate, orders keep the tax they were charged
+func (h *TaxHandler) UpdateRate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var data rateData
+	if err := decode(r, &data); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	rate := data.toModel()
+	err := h.TaxUsecase.UpdateRate(ctx, &rate)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, rate)
+}
+
+// DeleteRate endpoint to delete a tax rate
+func (h *TaxHandler) DeleteRate(w http.ResponseWriter, r *http.Request) {
+	id, err := queryID(r, "id")
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	err = h.TaxUsecase.DeleteRate(ctx, id)
+
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, "success")
+}
@@ -0,0 +1,71 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/soerjadi/exam/models"
+	taxHttp "github.com/soerjadi/exam/tax/delivery/http"
+	"github.com/soerjadi/exam/tax/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAddRate(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("CreateRate", mock.Anything, mock.MatchedBy(func(r *models.TaxRate) bool {
+		return r.ClassID == 1 && r.Country == "ID" && r.Region == "Bali" && r.Rate == 11
+	})).Return(nil)
+
+	body := `{"class_id":1,"country":"ID","region":"Bali","name":"VAT","rate":11}`
+	req, err := http.NewRequest("POST", "/v1/admin/tax/rate/add", strings.NewReader(body))
+	assert.NoError(t, err)
+
+	handler := taxHttp.TaxHandler{
+		TaxUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.AddRate(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestAddClassInvalid(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("CreateClass", mock.Anything, mock.AnythingOfType("*models.TaxClass")).Return(models.ErrBadParamInput)
+
+	req, err := http.NewRequest("POST", "/v1/admin/tax/class/add", strings.NewReader(`{"name":""}`))
+	assert.NoError(t, err)
+
+	handler := taxHttp.TaxHandler{
+		TaxUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.AddClass(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestRateList(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("GetRates", mock.Anything, int64(1)).
+		Return([]*models.TaxRate{&models.TaxRate{ID: 2, ClassID: 1, Country: "ID", Name: "VAT", Rate: 11}}, nil)
+
+	req, err := http.NewRequest("GET", "/v1/admin/tax/rate/list?class_id=1", nil)
+	assert.NoError(t, err)
+
+	handler := taxHttp.TaxHandler{
+		TaxUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.RateList(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
@@ -0,0 +1,22 @@
+package tax
+
+import (
+	"context"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// Repository represent the tax class and rate repository contract
+type Repository interface {
+	GetClasses(ctx context.Context) ([]*models.TaxClass, error)
+	GetClass(ctx context.Context, id int64) (*models.TaxClass, error)
+	CreateClass(ctx context.Context, class *models.TaxClass) error
+	UpdateClass(ctx context.Context, class *models.TaxClass) error
+	DeleteClass(ctx context.Context, id int64) error
+	GetRates(ctx context.Context, classID int64) ([]*models.TaxRate, error)
+	GetRate(ctx context.Context, id int64) (*models.TaxRate, error)
+	FindRate(ctx context.Context, classID int64, country string, region string) (*models.TaxRate, error)
+	CreateRate(ctx context.Context, rate *models.TaxRate) error
+	UpdateRate(ctx context.Context, rate *models.TaxRate) error
+	DeleteRate(ctx context.Context, id int64) error
+}
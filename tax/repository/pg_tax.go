@@ -0,0 +1,291 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/soerjadi/exam/database"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/tax"
+	"github.com/soerjadi/exam/utils"
+)
+
+type pgTaxRepository struct {
+	Conn *sql.DB
+}
+
+var logger = utils.LogBuilder(true)
+
+// NewPGTaxRepository is bridge to create an object from tax.Repository interface
+func NewPGTaxRepository(Conn *sql.DB) tax.Repository {
+	return &pgTaxRepository{Conn}
+}
+
+func (p *pgTaxRepository) fetchClasses(ctx context.Context, query string, args ...interface{}) ([]*models.TaxClass, error) {
+	rows, err := p.Conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Error(err)
+		}
+	}()
+
+	result := make([]*models.TaxClass, 0)
+	for rows.Next() {
+		t := new(models.TaxClass)
+
+		err = rows.Scan(&t.ID, &t.Name, &t.Created)
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+
+		result = append(result, t)
+	}
+
+	return result, nil
+}
+
+func (p *pgTaxRepository) fetchRates(ctx context.Context, query string, args ...interface{}) ([]*models.TaxRate, error) {
+	rows, err := p.Conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Error(err)
+		}
+	}()
+
+	result := make([]*models.TaxRate, 0)
+	for rows.Next() {
+		t := new(models.TaxRate)
+
+		err = rows.Scan(
+			&t.ID,
+			&t.ClassID,
+			&t.Country,
+			&t.Region,
+			&t.Name,
+			&t.Rate,
+			&t.Created,
+			&t.Updated,
+		)
+
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+
+		result = append(result, t)
+	}
+
+	return result, nil
+}
+
+func (p *pgTaxRepository) GetClasses(ctx context.Context) ([]*models.TaxClass, error) {
+	return p.fetchClasses(ctx, `SELECT id, name, created FROM tax_classes ORDER BY name`)
+}
+
+func (p *pgTaxRepository) GetClass(ctx context.Context, id int64) (*models.TaxClass, error) {
+	classes, err := p.fetchClasses(ctx, `SELECT id, name, created FROM tax_classes WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(classes) == 0 {
+		return nil, models.ErrNotFound
+	}
+
+	return classes[0], nil
+}
+
+func (p *pgTaxRepository) CreateClass(ctx context.Context, class *models.TaxClass) error {
+	stmt, err := p.Conn.PrepareContext(ctx, `INSERT INTO tax_classes(name) VALUES(?) returning id`)
+	if err != nil {
+		return err
+	}
+
+	result, err := stmt.ExecContext(ctx, class.Name)
+	if err != nil {
+		return err
+	}
+
+	lastID, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	class.ID = lastID
+	return nil
+}
+
+func (p *pgTaxRepository) UpdateClass(ctx context.Context, class *models.TaxClass) error {
+	stmt, err := p.Conn.PrepareContext(ctx, `UPDATE tax_classes SET name = ? WHERE id = ?`)
+	if err != nil {
+		return err
+	}
+
+	res, err := stmt.ExecContext(ctx, class.Name, class.ID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected != 1 {
+		return models.ErrNotFound
+	}
+
+	return nil
+}
+
+// DeleteClass remove the class and its rates, products of the class are no
+// longer taxed
+func (p *pgTaxRepository) DeleteClass(ctx context.Context, id int64) error {
+	return database.RunInTx(ctx, p.Conn, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `UPDATE products SET tax_class_id = NULL WHERE tax_class_id = ?`, id); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM tax_rates WHERE class_id = ?`, id); err != nil {
+			return err
+		}
+
+		res, err := tx.ExecContext(ctx, `DELETE FROM tax_classes WHERE id = ?`, id)
+		if err != nil {
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if affected != 1 {
+			return models.ErrNotFound
+		}
+
+		return nil
+	})
+}
+
+func (p *pgTaxRepository) GetRates(ctx context.Context, classID int64) ([]*models.TaxRate, error) {
+	query := `SELECT id, class_id, country, region, name, rate, created, updated FROM tax_rates WHERE class_id = ? ORDER BY country, region`
+
+	return p.fetchRates(ctx, query, classID)
+}
+
+func (p *pgTaxRepository) GetRate(ctx context.Context, id int64) (*models.TaxRate, error) {
+	query := `SELECT id, class_id, country, region, name, rate, created, updated FROM tax_rates WHERE id = ?`
+
+	rates, err := p.fetchRates(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rates) == 0 {
+		return nil, models.ErrNotFound
+	}
+
+	return rates[0], nil
+}
+
+// FindRate return the rate of the class in the region of country, a rate of
+// the region win over the one of the whole country
+func (p *pgTaxRepository) FindRate(ctx context.Context, classID int64, country string, region string) (*models.TaxRate, error) {
+	query := `SELECT id, class_id, country, region, name, rate, created, updated FROM tax_rates
+		WHERE class_id = ? AND country = ? AND (lower(region) = lower(?) OR region = '') ORDER BY region DESC LIMIT 1`
+
+	rates, err := p.fetchRates(ctx, query, classID, country, region)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rates) == 0 {
+		return nil, models.ErrNotFound
+	}
+
+	return rates[0], nil
+}
+
+func (p *pgTaxRepository) CreateRate(ctx context.Context, rate *models.TaxRate) error {
+	query := `INSERT INTO tax_rates(class_id, country, region, name, rate) VALUES(?, ?, ?, ?, ?) returning id`
+
+	stmt, err := p.Conn.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	result, err := stmt.ExecContext(ctx, rate.ClassID, rate.Country, rate.Region, rate.Name, rate.Rate)
+	if err != nil {
+		return err
+	}
+
+	lastID, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	rate.ID = lastID
+	return nil
+}
+
+func (p *pgTaxRepository) UpdateRate(ctx context.Context, rate *models.TaxRate) error {
+	query := `UPDATE tax_rates SET class_id = ?, country = ?, region = ?, name = ?, rate = ?, updated = ? WHERE id = ?`
+
+	stmt, err := p.Conn.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	res, err := stmt.ExecContext(ctx, rate.ClassID, rate.Country, rate.Region, rate.Name, rate.Rate, rate.Updated, rate.ID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected != 1 {
+		return models.ErrNotFound
+	}
+
+	return nil
+}
+
+func (p *pgTaxRepository) DeleteRate(ctx context.Context, id int64) error {
+	stmt, err := p.Conn.PrepareContext(ctx, `DELETE FROM tax_rates WHERE id = ?`)
+	if err != nil {
+		return err
+	}
+
+	res, err := stmt.ExecContext(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected != 1 {
+		return models.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/tax/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+var rateColumns = []string{"id", "class_id", "country", "region", "name", "rate", "created", "updated"}
+
+func TestGetClass(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectQuery("SELECT id, name, created FROM tax_classes WHERE id = \\?").WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "created"}).AddRow(1, "standard", time.Now()))
+
+	r := repository.NewPGTaxRepository(db)
+	class, err := r.GetClass(context.TODO(), int64(1))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "standard", class.Name)
+}
+
+func TestFindRate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	query := "SELECT (.+) FROM tax_rates WHERE class_id = \\? AND country = \\? (.+) ORDER BY region DESC LIMIT 1"
+
+	t.Run("found", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(int64(1), "ID", "DKI Jakarta").
+			WillReturnRows(sqlmock.NewRows(rateColumns).AddRow(2, 1, "ID", "", "VAT", 11, time.Now(), nil))
+
+		r := repository.NewPGTaxRepository(db)
+		rate, err := r.FindRate(context.TODO(), int64(1), "ID", "DKI Jakarta")
+
+		assert.NoError(t, err)
+		assert.Equal(t, float64(11), rate.Rate)
+	})
+
+	t.Run("untaxed", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(int64(1), "SG", "").WillReturnRows(sqlmock.NewRows(rateColumns))
+
+		r := repository.NewPGTaxRepository(db)
+		_, err := r.FindRate(context.TODO(), int64(1), "SG", "")
+
+		assert.Equal(t, models.ErrNotFound, err)
+	})
+}
+
+func TestCreateRate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rate := &models.TaxRate{ClassID: 1, Country: "ID", Name: "VAT", Rate: 11}
+
+	mock.ExpectPrepare("INSERT INTO tax_rates\\(class_id, country, region, name, rate\\)").ExpectExec().
+		WithArgs(int64(1), "ID", "", "VAT", float64(11)).WillReturnResult(sqlmock.NewResult(5, 1))
+
+	r := repository.NewPGTaxRepository(db)
+	err = r.CreateRate(context.TODO(), rate)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), rate.ID)
+}
+
+func TestDeleteClass(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE products SET tax_class_id = NULL WHERE tax_class_id = \\?").WithArgs(int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec("DELETE FROM tax_rates WHERE class_id = \\?").WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("DELETE FROM tax_classes WHERE id = \\?").WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	r := repository.NewPGTaxRepository(db)
+	err = r.DeleteClass(context.TODO(), int64(1))
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
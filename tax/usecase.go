@@ -0,0 +1,22 @@
+package tax
+
+import (
+	"context"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// Usecase represent the tax usecase
+type Usecase interface {
+	GetClasses(ctx context.Context) ([]*models.TaxClass, error)
+	GetClass(ctx context.Context, id int64) (*models.TaxClass, error)
+	CreateClass(ctx context.Context, class *models.TaxClass) error
+	UpdateClass(ctx context.Context, class *models.TaxClass) error
+	DeleteClass(ctx context.Context, id int64) error
+	GetRates(ctx context.Context, classID int64) ([]*models.TaxRate, error)
+	GetRate(ctx context.Context, id int64) (*models.TaxRate, error)
+	CreateRate(ctx context.Context, rate *models.TaxRate) error
+	UpdateRate(ctx context.Context, rate *models.TaxRate) error
+	DeleteRate(ctx context.Context, id int64) error
+	Apply(ctx context.Context, order *models.Order, classes map[int64]int64, destination *models.Address) error
+}
@@ -0,0 +1,258 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/soerjadi/exam/audit"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/tax"
+	"github.com/soerjadi/exam/utils"
+	"gopkg.in/guregu/null.v3"
+)
+
+type taxUsecase struct {
+	repo           tax.Repository
+	audit          audit.Usecase
+	included       bool
+	origin         string
+	contextTimeout time.Duration
+}
+
+var logger = utils.LogBuilder(true)
+
+// NewTaxUsecase will create object that represent of tax.Usecase interface.
+// included tell whether catalog prices already include tax, orders without
+// destination are taxed at the rates of the origin country.
+func NewTaxUsecase(t tax.Repository, a audit.Usecase, included bool, origin string, timeout time.Duration) tax.Usecase {
+	return &taxUsecase{
+		repo:           t,
+		audit:          a,
+		included:       included,
+		origin:         strings.ToUpper(origin),
+		contextTimeout: timeout,
+	}
+}
+
+func (t *taxUsecase) record(ctx context.Context, id int64, action string, before interface{}, after interface{}) {
+	if err := t.audit.Record(ctx, models.AuditTax, id, action, before, after); err != nil {
+		logger.Error(err)
+	}
+}
+
+// validateRate reject rates outside of 0 to 100 percent, country codes are
+// upper cased
+func validateRate(rate *models.TaxRate) error {
+	rate.Country = strings.ToUpper(strings.TrimSpace(rate.Country))
+	rate.Region = strings.TrimSpace(rate.Region)
+
+	if rate.ClassID == 0 || rate.Name == "" || len(rate.Country) != 2 {
+		return models.ErrBadParamInput
+	}
+
+	if rate.Rate < 0 || rate.Rate > 100 {
+		return models.ErrBadParamInput
+	}
+
+	return nil
+}
+
+func (t *taxUsecase) GetClasses(ctx context.Context) ([]*models.TaxClass, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.contextTimeout)
+	defer cancel()
+
+	return t.repo.GetClasses(ctx)
+}
+
+func (t *taxUsecase) GetClass(ctx context.Context, id int64) (*models.TaxClass, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.contextTimeout)
+	defer cancel()
+
+	return t.repo.GetClass(ctx, id)
+}
+
+func (t *taxUsecase) CreateClass(ctx context.Context, class *models.TaxClass) error {
+	ctx, cancel := context.WithTimeout(ctx, t.contextTimeout)
+	defer cancel()
+
+	if class.Name = strings.TrimSpace(class.Name); class.Name == "" {
+		return models.ErrBadParamInput
+	}
+
+	if err := t.repo.CreateClass(ctx, class); err != nil {
+		return err
+	}
+
+	t.record(ctx, class.ID, models.AuditCreate, nil, class)
+	return nil
+}
+
+func (t *taxUsecase) UpdateClass(ctx context.Context, class *models.TaxClass) error {
+	ctx, cancel := context.WithTimeout(ctx, t.contextTimeout)
+	defer cancel()
+
+	if class.Name = strings.TrimSpace(class.Name); class.Name == "" {
+		return models.ErrBadParamInput
+	}
+
+	before, err := t.repo.GetClass(ctx, class.ID)
+	if err != nil {
+		return err
+	}
+
+	class.Created = before.Created
+	if err = t.repo.UpdateClass(ctx, class); err != nil {
+		return err
+	}
+
+	t.record(ctx, class.ID, models.AuditUpdate, before, class)
+	return nil
+}
+
+func (t *taxUsecase) DeleteClass(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, t.contextTimeout)
+	defer cancel()
+
+	exists, err := t.repo.GetClass(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err = t.repo.DeleteClass(ctx, id); err != nil {
+		return err
+	}
+
+	t.record(ctx, id, models.AuditDelete, exists, nil)
+	return nil
+}
+
+func (t *taxUsecase) GetRates(ctx context.Context, classID int64) ([]*models.TaxRate, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.contextTimeout)
+	defer cancel()
+
+	return t.repo.GetRates(ctx, classID)
+}
+
+func (t *taxUsecase) GetRate(ctx context.Context, id int64) (*models.TaxRate, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.contextTimeout)
+	defer cancel()
+
+	return t.repo.GetRate(ctx, id)
+}
+
+func (t *taxUsecase) CreateRate(ctx context.Context, rate *models.TaxRate) error {
+	ctx, cancel := context.WithTimeout(ctx, t.contextTimeout)
+	defer cancel()
+
+	if err := validateRate(rate); err != nil {
+		return err
+	}
+
+	if _, err := t.repo.GetClass(ctx, rate.ClassID); err != nil {
+		return err
+	}
+
+	if err := t.repo.CreateRate(ctx, rate); err != nil {
+		return err
+	}
+
+	t.record(ctx, rate.ID, models.AuditCreate, nil, rate)
+	return nil
+}
+
+func (t *taxUsecase) UpdateRate(ctx context.Context, rate *models.TaxRate) error {
+	ctx, cancel := context.WithTimeout(ctx, t.contextTimeout)
+	defer cancel()
+
+	if err := validateRate(rate); err != nil {
+		return err
+	}
+
+	before, err := t.repo.GetRate(ctx, rate.ID)
+	if err != nil {
+		return err
+	}
+
+	if rate.ClassID != before.ClassID {
+		if _, err = t.repo.GetClass(ctx, rate.ClassID); err != nil {
+			return err
+		}
+	}
+
+	rate.Created = before.Created
+	rate.Updated = null.NewTime(
+		time.Now(), true,
+	)
+
+	if err = t.repo.UpdateRate(ctx, rate); err != nil {
+		return err
+	}
+
+	t.record(ctx, rate.ID, models.AuditUpdate, before, rate)
+	return nil
+}
+
+func (t *taxUsecase) DeleteRate(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, t.contextTimeout)
+	defer cancel()
+
+	exists, err := t.repo.GetRate(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err = t.repo.DeleteRate(ctx, id); err != nil {
+		return err
+	}
+
+	t.record(ctx, id, models.AuditDelete, exists, nil)
+	return nil
+}
+
+// Apply tax order shipped to destination, classes map the products of the
+// order to their tax class. Products without class or without a rate in the
+// destination are not taxed. Orders placed without items get a single item
+// of their product so the tax is kept per line.
+func (t *taxUsecase) Apply(ctx context.Context, order *models.Order, classes map[int64]int64, destination *models.Address) error {
+	ctx, cancel := context.WithTimeout(ctx, t.contextTimeout)
+	defer cancel()
+
+	if len(order.Items) == 0 {
+		order.Items = []*models.OrderItem{
+			&models.OrderItem{ProductID: order.ProductID, Amount: order.Amount, Price: order.Price},
+		}
+	}
+
+	country, region := t.origin, ""
+	if destination != nil {
+		country, region = strings.ToUpper(destination.Country), destination.Region
+	}
+
+	found := make(map[int64]*models.TaxRate)
+	rates := make(map[int64]*models.TaxRate)
+	for _, item := range order.Items {
+		classID := classes[item.ProductID]
+		if classID == 0 {
+			continue
+		}
+
+		rate, ok := found[classID]
+		if !ok {
+			var err error
+			rate, err = t.repo.FindRate(ctx, classID, country, region)
+			if err != nil && err != models.ErrNotFound {
+				return err
+			}
+
+			found[classID] = rate
+		}
+
+		if rate != nil {
+			rates[item.ProductID] = rate
+		}
+	}
+
+	tax.Compute(order, rates, t.included)
+	return nil
+}
@@ -0,0 +1,126 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/soerjadi/exam/audit/audittest"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/tax/mocks"
+	"github.com/soerjadi/exam/tax/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	vat     = &models.TaxRate{ID: 2, ClassID: 1, Country: "ID", Name: "VAT", Rate: 10}
+	reduced = &models.TaxRate{ID: 3, ClassID: 2, Country: "ID", Name: "reduced VAT", Rate: 5}
+)
+
+func newOrder() *models.Order {
+	return &models.Order{
+		Discount: 100,
+		Total:    900,
+		Items: []*models.OrderItem{
+			{ProductID: 1, Amount: 2, Price: 300},
+			{ProductID: 2, Amount: 1, Price: 200},
+			{ProductID: 3, Amount: 1, Price: 200},
+		},
+	}
+}
+
+func TestCreateRate(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		rate := models.TaxRate{ClassID: 1, Country: " id", Name: "VAT", Rate: 11}
+
+		mockRepo.On("GetClass", mock.Anything, int64(1)).Return(&models.TaxClass{ID: 1, Name: "standard"}, nil).Once()
+		mockRepo.On("CreateRate", mock.Anything, &rate).Return(nil).Once()
+
+		mockAudit := audittest.NewUsecase()
+		u := usecase.NewTaxUsecase(mockRepo, mockAudit, false, "ID", time.Second*2)
+		err := u.CreateRate(context.TODO(), &rate)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "ID", rate.Country)
+		mockRepo.AssertExpectations(t)
+		audittest.AssertRecorded(t, mockAudit, models.AuditTax, rate.ID, models.AuditCreate, nil, &rate)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		mockAudit := audittest.NewUsecase()
+		u := usecase.NewTaxUsecase(new(mocks.Repository), mockAudit, false, "ID", time.Second*2)
+
+		invalid := []models.TaxRate{
+			{ClassID: 1, Country: "IDN", Name: "VAT", Rate: 11},
+			{ClassID: 1, Country: "ID", Name: "VAT", Rate: 101},
+			{ClassID: 1, Country: "ID", Rate: 11},
+			{Country: "ID", Name: "VAT", Rate: 11},
+		}
+
+		for _, rate := range invalid {
+			err := u.CreateRate(context.TODO(), &rate)
+			assert.Equal(t, models.ErrBadParamInput, err)
+		}
+		audittest.AssertNothingRecorded(t, mockAudit)
+	})
+}
+
+func TestApply(t *testing.T) {
+	classes := map[int64]int64{1: 1, 2: 2}
+	destination := &models.Address{Country: "id", Region: "DKI Jakarta"}
+
+	t.Run("exclusive", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockRepo.On("FindRate", mock.Anything, int64(1), "ID", "DKI Jakarta").Return(vat, nil).Once()
+		mockRepo.On("FindRate", mock.Anything, int64(2), "ID", "DKI Jakarta").Return(reduced, nil).Once()
+
+		order := newOrder()
+		u := usecase.NewTaxUsecase(mockRepo, audittest.NewUsecase(), false, "ID", time.Second*2)
+		err := u.Apply(context.TODO(), order, classes, destination)
+
+		assert.NoError(t, err)
+		// the discount is a tenth of the subtotal, every line pays 90%
+		assert.Equal(t, float64(54), order.Items[0].Tax)
+		assert.Equal(t, float64(9), order.Items[1].Tax)
+		assert.Equal(t, float64(0), order.Items[2].Tax)
+		assert.Equal(t, float64(63), order.Tax)
+		assert.Equal(t, float64(963), order.Total)
+		assert.False(t, order.TaxIncluded)
+		assert.Len(t, order.Taxes, 2)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("inclusive", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockRepo.On("FindRate", mock.Anything, int64(1), "ID", "DKI Jakarta").Return(vat, nil).Once()
+		mockRepo.On("FindRate", mock.Anything, int64(2), "ID", "DKI Jakarta").Return(nil, models.ErrNotFound).Once()
+
+		order := newOrder()
+		u := usecase.NewTaxUsecase(mockRepo, audittest.NewUsecase(), true, "ID", time.Second*2)
+		err := u.Apply(context.TODO(), order, classes, destination)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 49.09, order.Items[0].Tax)
+		assert.Equal(t, 49.09, order.Tax)
+		assert.Equal(t, float64(900), order.Total)
+		assert.True(t, order.TaxIncluded)
+		assert.Len(t, order.Taxes, 1)
+	})
+
+	t.Run("direct order at origin", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockRepo.On("FindRate", mock.Anything, int64(1), "ID", "").Return(vat, nil).Once()
+
+		order := &models.Order{ProductID: 1, Amount: 3, Price: 100, Total: 300}
+		u := usecase.NewTaxUsecase(mockRepo, audittest.NewUsecase(), false, "id", time.Second*2)
+		err := u.Apply(context.TODO(), order, classes, nil)
+
+		assert.NoError(t, err)
+		assert.Len(t, order.Items, 1)
+		assert.Equal(t, float64(30), order.Items[0].Tax)
+		assert.Equal(t, float64(330), order.Total)
+		mockRepo.AssertExpectations(t)
+	})
+}
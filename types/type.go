@@ -1,11 +1,45 @@
 package types
 
-import "github.com/soerjadi/exam/models"
+import (
+	"github.com/soerjadi/exam/models"
+	"gopkg.in/guregu/null.v3"
+)
 
 // Product represent product model with product category
 type Product struct {
-	ID       int64              `json:"id"`
-	Name     string             `json:"name"`
-	SKU      string             `json:"sku"`
-	Category []*models.Category `json:"category"`
+	ID          int64              `json:"id"`
+	Name        string             `json:"name"`
+	SKU         string             `json:"sku"`
+	Status      int                `json:"status"`
+	PublishAt   null.Time          `json:"publish_at"`
+	UnpublishAt null.Time          `json:"unpublish_at"`
+	Category    []*models.Category `json:"category"`
+	Version     int64              `json:"version"`
+	Weight      float64            `json:"weight"`
+	Length      float64            `json:"length"`
+	Width       float64            `json:"width"`
+	Height      float64            `json:"height"`
+	TaxClassID  null.Int           `json:"tax_class_id"`
+	Stock       int64              `json:"stock"`
+}
+
+// OrderPriceAudit compare the prices charged on the lines of an order with
+// the list prices that were in effect when the order was placed, it match
+// when every line does
+type OrderPriceAudit struct {
+	OrderID int64             `json:"order_id"`
+	Lines   []*LinePriceAudit `json:"lines"`
+	Match   bool              `json:"match"`
+}
+
+// LinePriceAudit compare the price charged on a line of an order with the
+// list price of its product, ListPrice is null when there was none
+type LinePriceAudit struct {
+	OrderItemID int64                `json:"order_item_id"`
+	ProductID   int64                `json:"product_id"`
+	Amount      int64                `json:"amount"`
+	Charged     float64              `json:"charged"`
+	ListPrice   *models.ProductPrice `json:"list_price"`
+	Difference  float64              `json:"difference"`
+	Match       bool                 `json:"match"`
 }
@@ -0,0 +1,41 @@
+package utils
+
+import "context"
+
+type contextKey string
+
+const (
+	requestIDKey contextKey = "request_id"
+	actorKey     contextKey = "actor"
+)
+
+// AnonymousActor is used as actor when the request carry no identity
+const AnonymousActor = "anonymous"
+
+// WithRequestID return copy of ctx carrying the request ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID return request ID stored in ctx, empty when there is none
+func RequestID(ctx context.Context) string {
+	if requestID, ok := ctx.Value(requestIDKey).(string); ok {
+		return requestID
+	}
+
+	return ""
+}
+
+// WithActor return copy of ctx carrying who is doing the request
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
+// Actor return actor stored in ctx, AnonymousActor when there is none
+func Actor(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey).(string); ok && actor != "" {
+		return actor
+	}
+
+	return AnonymousActor
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ETag build the entity tag of a resource from its version
+func ETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// ContentETag build the entity tag of a representation holding more than the
+// resource itself, it change whenever body change. The version is kept in
+// front so the tag can still be sent back in If-Match.
+func ContentETag(version int64, body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + strconv.FormatInt(version, 10) + "-" + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// IfMatchVersion return the version the client expect from If-Match header.
+// ok is false when the header is missing or hold a wildcard.
+func IfMatchVersion(r *http.Request) (version int64, ok bool, err error) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return 0, false, nil
+	}
+
+	version, err = parseETag(value)
+	if err != nil {
+		return 0, false, err
+	}
+
+	return version, true, nil
+}
+
+// NotModified report whether If-None-Match header already match given etag
+func NotModified(r *http.Request, etag string) bool {
+	for _, value := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		value = strings.TrimSpace(value)
+		if value == "*" || strings.TrimPrefix(value, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseETag(value string) (int64, error) {
+	value = strings.Trim(strings.TrimPrefix(value, "W/"), `"`)
+	if i := strings.Index(value, "-"); i > 0 {
+		value = value[:i]
+	}
+
+	return strconv.ParseInt(value, 10, 64)
+}
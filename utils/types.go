@@ -8,6 +8,10 @@ type IDQuery struct {
 // ID type for id
 type ID int64
 
+// AccessTokenData is returned on login, the refresh token is exchanged for a
+// new pair once the access token expire
 type AccessTokenData struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int64  `json:"expires_in,omitempty"`
 }
@@ -0,0 +1,286 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/utils"
+	"github.com/soerjadi/exam/webhook"
+)
+
+type webhookData struct {
+	ID      int64    `json:"id"`
+	URL     string   `json:"url"`
+	Events  []string `json:"events"`
+	Active  bool     `json:"active"`
+	Version int64    `json:"version"`
+}
+
+// WebhookHandler represent the http handler for webhooks
+type WebhookHandler struct {
+	WebhookUsecase webhook.Usecase
+}
+
+// NewWebhookHandler initialize webhook resource endpoint
+func NewWebhookHandler(router *mux.Router, usecase webhook.Usecase) *mux.Router {
+	handler := &WebhookHandler{
+		WebhookUsecase: usecase,
+	}
+
+	p := router.PathPrefix("/v1/admin/webhook").Subrouter()
+	p.HandleFunc("/add", handler.AddWebhook).Methods("POST")
+	p.HandleFunc("/list", handler.GetList).Methods("GET")
+	p.HandleFunc("/detail", handler.GetByID).Methods("GET")
+	p.HandleFunc("/update", handler.UpdateWebhook).Methods("POST")
+	p.HandleFunc("/delete", handler.DeleteWebhook).Methods("GET")
+	p.HandleFunc("/{id:[0-9]+}/deliveries", handler.Deliveries).Methods("GET")
+	p.HandleFunc("/redeliver", handler.Redeliver).Methods("POST")
+
+	return p
+}
+
+func decode(r *http.Request, v interface{}) error {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, v)
+}
+
+// page read offset and limit from the query string, both are optional
+func page(r *http.Request) (int64, int64, error) {
+	params := r.URL.Query()
+
+	var offset, limit int64
+	var err error
+	if value := params.Get("limit"); value != "" {
+		if limit, err = strconv.ParseInt(value, 0, 64); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if value := params.Get("offset"); value != "" {
+		if offset, err = strconv.ParseInt(value, 0, 64); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if limit == 0 {
+		limit = 10
+	}
+
+	return offset, limit, nil
+}
+
+// errorStatus map errors of the webhook usecase to http status
+func errorStatus(err error) int32 {
+	switch err {
+	case models.ErrNotFound:
+		return http.StatusNotFound
+	case models.ErrConflict:
+		return http.StatusConflict
+	}
+
+	return http.StatusBadRequest
+}
+
+// AddWebhook register an endpoint, the response is the only time its signing
+// secret is shown
+func (h *WebhookHandler) AddWebhook(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var data webhookData
+	if err := decode(r, &data); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	hook := models.Webhook{
+		URL:    data.URL,
+		Events: data.Events,
+	}
+
+	err := h.WebhookUsecase.Create(ctx, &hook)
+
+	if err != nil {
+		utils.Error(w, errorStatus(err), err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, hook)
+}
+
+// GetList list the registered webhooks
+func (h *WebhookHandler) GetList(w http.ResponseWriter, r *http.Request) {
+	offset, limit, err := page(r)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	hooks, found, err := h.WebhookUsecase.GetList(ctx, offset, limit)
+
+	if err != nil {
+		utils.Error(w, errorStatus(err), err.Error())
+		return
+	}
+
+	entriesResult := &utils.EntriesResponse{
+		Data:  hooks,
+		Found: found,
+	}
+
+	utils.JSON(w, http.StatusOK, entriesResult)
+}
+
+// GetByID get detail webhook from given ID
+func (h *WebhookHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 0, 64)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	hook, err := h.WebhookUsecase.GetByID(ctx, id)
+
+	if err != nil {
+		utils.Error(w, errorStatus(err), err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, hook)
+}
+
+// UpdateWebhook replace the url, events and active flag of a webhook
+func (h *WebhookHandler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var data webhookData
+	if err := decode(r, &data); err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	hook := models.Webhook{
+		ID:      data.ID,
+		URL:     data.URL,
+		Events:  data.Events,
+		Active:  data.Active,
+		Version: data.Version,
+	}
+
+	err := h.WebhookUsecase.Update(ctx, &hook)
+
+	if err != nil {
+		utils.Error(w, errorStatus(err), err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, hook)
+}
+
+// DeleteWebhook remove a webhook and its deliveries
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 0, 64)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	err = h.WebhookUsecase.Delete(ctx, id)
+
+	if err != nil {
+		utils.Error(w, errorStatus(err), err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, "success")
+}
+
+// Deliveries list the deliveries of a webhook, the latest first
+func (h *WebhookHandler) Deliveries(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 0, 64)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	offset, limit, err := page(r)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	deliveries, found, err := h.WebhookUsecase.GetDeliveries(ctx, id, offset, limit)
+
+	if err != nil {
+		utils.Error(w, errorStatus(err), err.Error())
+		return
+	}
+
+	entriesResult := &utils.EntriesResponse{
+		Data:  deliveries,
+		Found: found,
+	}
+
+	utils.JSON(w, http.StatusOK, entriesResult)
+}
+
+// Redeliver queue a delivery to be sent again
+func (h *WebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 0, 64)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	delivery, err := h.WebhookUsecase.Redeliver(ctx, id)
+
+	if err != nil {
+		utils.Error(w, errorStatus(err), err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, delivery)
+}
@@ -0,0 +1,98 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/soerjadi/exam/models"
+	hookHttp "github.com/soerjadi/exam/webhook/delivery/http"
+	"github.com/soerjadi/exam/webhook/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAddWebhook(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("Create", mock.Anything, mock.MatchedBy(func(h *models.Webhook) bool {
+		return h.URL == "https://erp.example.com/hook" && len(h.Events) == 1
+	})).Run(func(args mock.Arguments) {
+		hook := args.Get(1).(*models.Webhook)
+		hook.ID = 1
+		hook.Secret = "whsec_abc"
+	}).Return(nil).Once()
+
+	body := `{"url":"https://erp.example.com/hook","events":["order.created"]}`
+	req, err := http.NewRequest("POST", "/v1/admin/webhook/add", strings.NewReader(body))
+	assert.NoError(t, err)
+
+	handler := hookHttp.WebhookHandler{
+		WebhookUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.AddWebhook(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"secret":"whsec_abc"`)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestAddWebhookInvalid(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("Create", mock.Anything, mock.AnythingOfType("*models.Webhook")).Return(models.ErrBadParamInput).Once()
+
+	req, err := http.NewRequest("POST", "/v1/admin/webhook/add", strings.NewReader(`{"url":"erp","events":[]}`))
+	assert.NoError(t, err)
+
+	handler := hookHttp.WebhookHandler{
+		WebhookUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.AddWebhook(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestDeliveries(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("GetDeliveries", mock.Anything, int64(1), int64(0), int64(10)).Return([]*models.WebhookDelivery{
+		&models.WebhookDelivery{ID: 5, WebhookID: 1, EventID: 9, Status: models.DeliveryFailed, Attempts: 8},
+	}, int64(1), nil).Once()
+
+	req, err := http.NewRequest("GET", "/v1/admin/webhook/1/deliveries", nil)
+	assert.NoError(t, err)
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+
+	handler := hookHttp.WebhookHandler{
+		WebhookUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.Deliveries(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"status":"failed"`)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestRedeliverNotFound(t *testing.T) {
+	mockUsecase := new(mocks.Usecase)
+	mockUsecase.On("Redeliver", mock.Anything, int64(5)).Return(nil, models.ErrNotFound).Once()
+
+	req, err := http.NewRequest("POST", "/v1/admin/webhook/redeliver?id=5", nil)
+	assert.NoError(t, err)
+
+	handler := hookHttp.WebhookHandler{
+		WebhookUsecase: mockUsecase,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.Redeliver(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
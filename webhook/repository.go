@@ -0,0 +1,27 @@
+package webhook
+
+import (
+	"context"
+	"time"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// Repository represent the webhook repository contract, it also read the
+// outbox the events are dispatched from
+type Repository interface {
+	GetList(ctx context.Context, offset int64, limit int64) ([]*models.Webhook, int64, error)
+	GetByID(ctx context.Context, id int64) (*models.Webhook, error)
+	GetActive(ctx context.Context) ([]*models.Webhook, error)
+	Create(ctx context.Context, hook *models.Webhook) error
+	Update(ctx context.Context, hook *models.Webhook) error
+	Delete(ctx context.Context, id int64) error
+	GetPendingEvents(ctx context.Context, limit int64) ([]*models.Event, error)
+	GetEvent(ctx context.Context, id int64) (*models.Event, error)
+	Fanout(ctx context.Context, event *models.Event, webhookIDs []int64) error
+	GetDue(ctx context.Context, now time.Time, limit int64) ([]*models.WebhookDelivery, error)
+	Lease(ctx context.Context, delivery *models.WebhookDelivery, until time.Time) error
+	GetDelivery(ctx context.Context, id int64) (*models.WebhookDelivery, error)
+	GetDeliveries(ctx context.Context, webhookID int64, offset int64, limit int64) ([]*models.WebhookDelivery, int64, error)
+	UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+}
@@ -0,0 +1,409 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/soerjadi/exam/database"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/utils"
+	"github.com/soerjadi/exam/webhook"
+	"gopkg.in/guregu/null.v3"
+)
+
+type pgWebhookRepository struct {
+	Conn *sql.DB
+}
+
+var logger = utils.LogBuilder(true)
+
+// NewPGWebhookRepository is bridge to create an object from webhook.Repository interface
+func NewPGWebhookRepository(Conn *sql.DB) webhook.Repository {
+	return &pgWebhookRepository{Conn}
+}
+
+func (p *pgWebhookRepository) fetch(ctx context.Context, query string, args ...interface{}) ([]*models.Webhook, error) {
+	rows, err := p.Conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Error(err)
+		}
+	}()
+
+	result := make([]*models.Webhook, 0)
+	for rows.Next() {
+		h := new(models.Webhook)
+		var events []byte
+
+		err = rows.Scan(&h.ID, &h.URL, &h.Secret, &events, &h.Active, &h.Created, &h.Updated, &h.Version)
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+
+		if err = json.Unmarshal(events, &h.Events); err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+
+		result = append(result, h)
+	}
+
+	return result, nil
+}
+
+func (p *pgWebhookRepository) fetchEvents(ctx context.Context, query string, args ...interface{}) ([]*models.Event, error) {
+	rows, err := p.Conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Error(err)
+		}
+	}()
+
+	result := make([]*models.Event, 0)
+	for rows.Next() {
+		e := new(models.Event)
+		var payload []byte
+
+		err = rows.Scan(&e.ID, &e.Type, &e.AggregateID, &payload, &e.Created, &e.Dispatched)
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+
+		e.Payload = json.RawMessage(payload)
+		result = append(result, e)
+	}
+
+	return result, nil
+}
+
+func (p *pgWebhookRepository) fetchDeliveries(ctx context.Context, query string, args ...interface{}) ([]*models.WebhookDelivery, error) {
+	rows, err := p.Conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Error(err)
+		}
+	}()
+
+	result := make([]*models.WebhookDelivery, 0)
+	for rows.Next() {
+		d := new(models.WebhookDelivery)
+
+		err = rows.Scan(&d.ID, &d.WebhookID, &d.EventID, &d.EventType, &d.Status, &d.Attempts, &d.NextAttempt,
+			&d.ResponseCode, &d.Error, &d.Created, &d.Delivered)
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+
+		result = append(result, d)
+	}
+
+	return result, nil
+}
+
+func (p *pgWebhookRepository) GetList(ctx context.Context, offset int64, limit int64) ([]*models.Webhook, int64, error) {
+	query := `SELECT id, url, secret, events, active, created, updated, version FROM webhooks ORDER BY id LIMIT ? OFFSET ?`
+
+	hooks, err := p.fetch(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var found int64
+	if err = p.Conn.QueryRowContext(ctx, `SELECT count(id) FROM webhooks`).Scan(&found); err != nil {
+		logger.Error(err)
+		return nil, 0, err
+	}
+
+	return hooks, found, nil
+}
+
+func (p *pgWebhookRepository) GetByID(ctx context.Context, id int64) (*models.Webhook, error) {
+	query := `SELECT id, url, secret, events, active, created, updated, version FROM webhooks WHERE id = ?`
+
+	hooks, err := p.fetch(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(hooks) == 0 {
+		return nil, models.ErrNotFound
+	}
+
+	return hooks[0], nil
+}
+
+// GetActive list the webhooks events are dispatched to
+func (p *pgWebhookRepository) GetActive(ctx context.Context) ([]*models.Webhook, error) {
+	query := `SELECT id, url, secret, events, active, created, updated, version FROM webhooks WHERE active = true ORDER BY id`
+
+	return p.fetch(ctx, query)
+}
+
+func (p *pgWebhookRepository) Create(ctx context.Context, hook *models.Webhook) error {
+	events, err := json.Marshal(hook.Events)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO webhooks(url, secret, events, active) VALUES(?, ?, ?, ?) returning id`
+	stmt, err := p.Conn.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	result, err := stmt.ExecContext(ctx, hook.URL, hook.Secret, events, hook.Active)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	lastID, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	hook.ID = lastID
+	hook.Version = 1
+	return nil
+}
+
+// Update change the endpoint and subscriptions of a webhook, its secret is
+// kept
+func (p *pgWebhookRepository) Update(ctx context.Context, hook *models.Webhook) error {
+	events, err := json.Marshal(hook.Events)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE webhooks SET url = ?, events = ?, active = ?, updated = ?, version = version + 1 WHERE id = ? AND version = ?`
+	stmt, err := p.Conn.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	res, err := stmt.ExecContext(ctx, hook.URL, events, hook.Active, hook.Updated, hook.ID, hook.Version)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected != 1 {
+		return models.ErrConflict
+	}
+
+	hook.Version++
+	return nil
+}
+
+// Delete remove a webhook together with its deliveries
+func (p *pgWebhookRepository) Delete(ctx context.Context, id int64) error {
+	return database.RunInTx(ctx, p.Conn, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM webhook_deliveries WHERE webhook_id = ?`, id); err != nil {
+			return err
+		}
+
+		res, err := tx.ExecContext(ctx, `DELETE FROM webhooks WHERE id = ?`, id)
+		if err != nil {
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if affected != 1 {
+			return models.ErrNotFound
+		}
+
+		return nil
+	})
+}
+
+// GetPendingEvents list the events of the outbox not dispatched yet, the
+// oldest first
+func (p *pgWebhookRepository) GetPendingEvents(ctx context.Context, limit int64) ([]*models.Event, error) {
+	query := `SELECT id, type, aggregate_id, payload, created, dispatched FROM outbox_events WHERE dispatched IS NULL ORDER BY id LIMIT ?`
+
+	return p.fetchEvents(ctx, query, limit)
+}
+
+func (p *pgWebhookRepository) GetEvent(ctx context.Context, id int64) (*models.Event, error) {
+	query := `SELECT id, type, aggregate_id, payload, created, dispatched FROM outbox_events WHERE id = ?`
+
+	events, err := p.fetchEvents(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(events) == 0 {
+		return nil, models.ErrNotFound
+	}
+
+	return events[0], nil
+}
+
+// Fanout mark event dispatched and queue a delivery of it to every webhook of
+// webhookIDs, in one transaction so an event is never queued twice. It return
+// models.ErrConflict when another dispatcher got the event first.
+func (p *pgWebhookRepository) Fanout(ctx context.Context, event *models.Event, webhookIDs []int64) error {
+	now := time.Now()
+
+	return database.RunInTx(ctx, p.Conn, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `UPDATE outbox_events SET dispatched = ? WHERE id = ? AND dispatched IS NULL`, now, event.ID)
+		if err != nil {
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if affected != 1 {
+			return models.ErrConflict
+		}
+
+		if len(webhookIDs) > 0 {
+			stmt, err := tx.PrepareContext(ctx, `INSERT INTO webhook_deliveries(webhook_id, event_id, event_type, status, next_attempt)
+				VALUES(?, ?, ?, ?, ?) ON CONFLICT (webhook_id, event_id) DO NOTHING`)
+			if err != nil {
+				return err
+			}
+
+			for _, id := range webhookIDs {
+				if _, err = stmt.ExecContext(ctx, id, event.ID, event.Type, models.DeliveryPending, now); err != nil {
+					logger.Error(err)
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetDue list the pending deliveries whose next attempt is due at now
+func (p *pgWebhookRepository) GetDue(ctx context.Context, now time.Time, limit int64) ([]*models.WebhookDelivery, error) {
+	query := `SELECT id, webhook_id, event_id, event_type, status, attempts, next_attempt, response_code, error, created, delivered
+		FROM webhook_deliveries WHERE status = ? AND next_attempt <= ? ORDER BY next_attempt LIMIT ?`
+
+	return p.fetchDeliveries(ctx, query, models.DeliveryPending, now, limit)
+}
+
+// Lease push the next attempt of a due delivery to until, so other
+// dispatchers leave it alone while it is sent. It return models.ErrConflict
+// when the delivery was leased or changed since it was read.
+func (p *pgWebhookRepository) Lease(ctx context.Context, delivery *models.WebhookDelivery, until time.Time) error {
+	query := `UPDATE webhook_deliveries SET next_attempt = ? WHERE id = ? AND status = ? AND next_attempt = ?`
+
+	res, err := p.Conn.ExecContext(ctx, query, until, delivery.ID, models.DeliveryPending, delivery.NextAttempt)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected != 1 {
+		return models.ErrConflict
+	}
+
+	delivery.NextAttempt = null.TimeFrom(until)
+	return nil
+}
+
+func (p *pgWebhookRepository) GetDelivery(ctx context.Context, id int64) (*models.WebhookDelivery, error) {
+	query := `SELECT id, webhook_id, event_id, event_type, status, attempts, next_attempt, response_code, error, created, delivered
+		FROM webhook_deliveries WHERE id = ?`
+
+	deliveries, err := p.fetchDeliveries(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(deliveries) == 0 {
+		return nil, models.ErrNotFound
+	}
+
+	return deliveries[0], nil
+}
+
+// GetDeliveries list the deliveries of a webhook, the latest first
+func (p *pgWebhookRepository) GetDeliveries(ctx context.Context, webhookID int64, offset int64, limit int64) ([]*models.WebhookDelivery, int64, error) {
+	query := `SELECT id, webhook_id, event_id, event_type, status, attempts, next_attempt, response_code, error, created, delivered
+		FROM webhook_deliveries WHERE webhook_id = ? ORDER BY id DESC LIMIT ? OFFSET ?`
+
+	deliveries, err := p.fetchDeliveries(ctx, query, webhookID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var found int64
+	err = p.Conn.QueryRowContext(ctx, `SELECT count(id) FROM webhook_deliveries WHERE webhook_id = ?`, webhookID).Scan(&found)
+	if err != nil {
+		logger.Error(err)
+		return nil, 0, err
+	}
+
+	return deliveries, found, nil
+}
+
+// UpdateDelivery store the outcome of an attempt
+func (p *pgWebhookRepository) UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	query := `UPDATE webhook_deliveries SET status = ?, attempts = ?, next_attempt = ?, response_code = ?, error = ?, delivered = ? WHERE id = ?`
+
+	stmt, err := p.Conn.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	res, err := stmt.ExecContext(ctx, delivery.Status, delivery.Attempts, delivery.NextAttempt, delivery.ResponseCode,
+		delivery.Error, delivery.Delivered, delivery.ID)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected != 1 {
+		return models.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,196 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/webhook/repository"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v3"
+)
+
+var deliveryColumns = []string{"id", "webhook_id", "event_id", "event_type", "status", "attempts", "next_attempt",
+	"response_code", "error", "created", "delivered"}
+
+func TestGetByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "url", "secret", "events", "active", "created", "updated", "version"}).
+		AddRow(1, "https://erp.example.com/hook", "whsec_abc", []byte(`["order.created"]`), true, time.Now(), nil, 2)
+
+	mock.ExpectQuery("SELECT id, url, secret, events, active, created, updated, version FROM webhooks WHERE id = \\?").
+		WithArgs(int64(1)).WillReturnRows(rows)
+
+	r := repository.NewPGWebhookRepository(db)
+	hook, err := r.GetByID(context.TODO(), int64(1))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{models.EventOrderCreated}, hook.Events)
+	assert.Equal(t, int64(2), hook.Version)
+}
+
+func TestCreate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	hook := &models.Webhook{
+		URL:    "https://erp.example.com/hook",
+		Secret: "whsec_abc",
+		Events: []string{"*"},
+		Active: true,
+	}
+
+	prep := mock.ExpectPrepare("INSERT INTO webhooks\\(url, secret, events, active\\) VALUES\\(\\?, \\?, \\?, \\?\\) returning id")
+	prep.ExpectExec().WithArgs(hook.URL, hook.Secret, []byte(`["*"]`), true).WillReturnResult(sqlmock.NewResult(3, 1))
+
+	r := repository.NewPGWebhookRepository(db)
+	err = r.Create(context.TODO(), hook)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), hook.ID)
+	assert.Equal(t, int64(1), hook.Version)
+}
+
+func TestUpdateConflict(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	prep := mock.ExpectPrepare("UPDATE webhooks SET url = \\?, events = \\?, active = \\?, updated = \\?, version = version \\+ 1 WHERE id = \\? AND version = \\?")
+	prep.ExpectExec().WillReturnResult(sqlmock.NewResult(0, 0))
+
+	r := repository.NewPGWebhookRepository(db)
+	err = r.Update(context.TODO(), &models.Webhook{ID: 1, URL: "https://erp.example.com/hook", Events: []string{"*"}, Version: 1})
+
+	assert.Equal(t, models.ErrConflict, err)
+}
+
+func TestFanout(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	event := &models.Event{ID: 9, Type: models.EventOrderCreated}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE outbox_events SET dispatched = \\? WHERE id = \\? AND dispatched IS NULL").
+		WithArgs(sqlmock.AnyArg(), int64(9)).WillReturnResult(sqlmock.NewResult(0, 1))
+	prep := mock.ExpectPrepare("INSERT INTO webhook_deliveries\\(webhook_id, event_id, event_type, status, next_attempt\\)")
+	prep.ExpectExec().WithArgs(int64(1), int64(9), event.Type, models.DeliveryPending, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+	prep.ExpectExec().WithArgs(int64(2), int64(9), event.Type, models.DeliveryPending, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectCommit()
+
+	r := repository.NewPGWebhookRepository(db)
+	err = r.Fanout(context.TODO(), event, []int64{1, 2})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFanoutWithoutSubscriber(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE outbox_events SET dispatched = \\? WHERE id = \\? AND dispatched IS NULL").
+		WithArgs(sqlmock.AnyArg(), int64(9)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	r := repository.NewPGWebhookRepository(db)
+	err = r.Fanout(context.TODO(), &models.Event{ID: 9, Type: models.EventProductCreated}, []int64{})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFanoutAlreadyDispatched(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE outbox_events SET dispatched = \\? WHERE id = \\? AND dispatched IS NULL").
+		WithArgs(sqlmock.AnyArg(), int64(9)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	r := repository.NewPGWebhookRepository(db)
+	err = r.Fanout(context.TODO(), &models.Event{ID: 9, Type: models.EventOrderCreated}, []int64{1})
+
+	assert.Equal(t, models.ErrConflict, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDue(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Now()
+	rows := sqlmock.NewRows(deliveryColumns).
+		AddRow(5, 1, 9, models.EventOrderCreated, models.DeliveryPending, 2, now, 500, "endpoint answered 500", now, nil)
+
+	mock.ExpectQuery("SELECT (.+) FROM webhook_deliveries WHERE status = \\? AND next_attempt <= \\? ORDER BY next_attempt LIMIT \\?").
+		WithArgs(models.DeliveryPending, now, int64(100)).WillReturnRows(rows)
+
+	r := repository.NewPGWebhookRepository(db)
+	due, err := r.GetDue(context.TODO(), now, int64(100))
+
+	assert.NoError(t, err)
+	assert.Len(t, due, 1)
+	assert.Equal(t, 2, due[0].Attempts)
+	assert.Equal(t, int64(500), due[0].ResponseCode.Int64)
+}
+
+func TestLease(t *testing.T) {
+	now := time.Now()
+	until := now.Add(5 * time.Minute)
+	query := "UPDATE webhook_deliveries SET next_attempt = \\? WHERE id = \\? AND status = \\? AND next_attempt = \\?"
+
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+
+		delivery := &models.WebhookDelivery{ID: 5, Status: models.DeliveryPending, NextAttempt: null.TimeFrom(now)}
+		mock.ExpectExec(query).WithArgs(until, int64(5), models.DeliveryPending, null.TimeFrom(now)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		r := repository.NewPGWebhookRepository(db)
+		err = r.Lease(context.TODO(), delivery, until)
+
+		assert.NoError(t, err)
+		assert.Equal(t, until, delivery.NextAttempt.Time)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("taken", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+
+		delivery := &models.WebhookDelivery{ID: 5, Status: models.DeliveryPending, NextAttempt: null.TimeFrom(now)}
+		mock.ExpectExec(query).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		r := repository.NewPGWebhookRepository(db)
+		err = r.Lease(context.TODO(), delivery, until)
+
+		assert.Equal(t, models.ErrConflict, err)
+		assert.Equal(t, now, delivery.NextAttempt.Time)
+	})
+}
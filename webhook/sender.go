@@ -0,0 +1,45 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// Sender post a delivery to an endpoint and return the status it answered
+type Sender interface {
+	Send(ctx context.Context, url string, header http.Header, body []byte) (int, error)
+}
+
+type httpSender struct {
+	client *http.Client
+}
+
+// NewHTTPSender return a Sender giving up on endpoints slower than timeout
+func NewHTTPSender(timeout time.Duration) Sender {
+	return &httpSender{client: &http.Client{Timeout: timeout}}
+}
+
+func (s *httpSender) Send(ctx context.Context, url string, header http.Header, body []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req = req.WithContext(ctx)
+	req.Header = header
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	// drain the answer so the connection is reused, it is not kept
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(res.Body, 64<<10))
+
+	return res.StatusCode, nil
+}
@@ -0,0 +1,50 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// Headers sent with every delivery
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// MaxAttempts is how many times a delivery is tried before it is failed
+const MaxAttempts = 8
+
+const (
+	firstRetry = 30 * time.Second
+	lastRetry  = 6 * time.Hour
+)
+
+// Sign give the signature header of body sent at timestamp, an HMAC-SHA256
+// of "timestamp.body" keyed by secret and hex encoded. Receivers recompute it
+// and reject old timestamps to stop replays.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(fmt.Sprintf("%d.", timestamp)))
+	mac.Write(body)
+
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, hex.EncodeToString(mac.Sum(nil)))
+}
+
+// Backoff is how long to wait after the attempt-th failed attempt, it
+// double every time from 30 seconds up to 6 hours
+func Backoff(attempt int) time.Duration {
+	wait := firstRetry
+	for i := 1; i < attempt && wait < lastRetry; i++ {
+		wait *= 2
+	}
+
+	if wait > lastRetry {
+		wait = lastRetry
+	}
+
+	return wait
+}
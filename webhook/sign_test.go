@@ -0,0 +1,38 @@
+package webhook_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/soerjadi/exam/webhook"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSign(t *testing.T) {
+	signature := webhook.Sign("whsec_abc", 1574841600, []byte(`{"id":3}`))
+
+	assert.Equal(t, "t=1574841600,v1=31cd486225f69640b2e550fe8e239955ed4a1d652109deaee9abf723d8c0e7c9", signature)
+
+	// the timestamp is signed too
+	assert.NotEqual(t, signature[len("t=1574841600,"):],
+		webhook.Sign("whsec_abc", 1574841601, []byte(`{"id":3}`))[len("t=1574841601,"):])
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		wait    time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{8, 64 * time.Minute},
+		{10, 256 * time.Minute},
+		{11, 6 * time.Hour},
+		{50, 6 * time.Hour},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.wait, webhook.Backoff(test.attempt), "attempt %d", test.attempt)
+	}
+}
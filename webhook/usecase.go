@@ -0,0 +1,19 @@
+package webhook
+
+import (
+	"context"
+
+	"github.com/soerjadi/exam/models"
+)
+
+// Usecase represent the webhook usecase
+type Usecase interface {
+	GetList(ctx context.Context, offset int64, limit int64) ([]*models.Webhook, int64, error)
+	GetByID(ctx context.Context, id int64) (*models.Webhook, error)
+	Create(ctx context.Context, hook *models.Webhook) error
+	Update(ctx context.Context, hook *models.Webhook) error
+	Delete(ctx context.Context, id int64) error
+	GetDeliveries(ctx context.Context, webhookID int64, offset int64, limit int64) ([]*models.WebhookDelivery, int64, error)
+	Redeliver(ctx context.Context, deliveryID int64) (*models.WebhookDelivery, error)
+	Dispatch(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,378 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/soerjadi/exam/audit"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/utils"
+	"github.com/soerjadi/exam/webhook"
+	"gopkg.in/guregu/null.v3"
+)
+
+// AllEvents subscribe a webhook to every event type
+const AllEvents = "*"
+
+// dispatchBatch is how many events and deliveries a Dispatch handle at most
+const dispatchBatch = 100
+
+// deliveryLease is how long a delivery being sent is hidden from other
+// dispatchers, it must outlast the timeout of the Sender
+const deliveryLease = 5 * time.Minute
+
+const secretPrefix = "whsec_"
+
+type webhookUsecase struct {
+	repo    webhook.Repository
+	sender  webhook.Sender
+	audit   audit.Usecase
+	timeout time.Duration
+}
+
+var logger = utils.LogBuilder(true)
+
+// NewWebhookUsecase will create object that represent of webhook.Usecase interface
+func NewWebhookUsecase(r webhook.Repository, s webhook.Sender, a audit.Usecase, timeout time.Duration) webhook.Usecase {
+	return &webhookUsecase{
+		repo:    r,
+		sender:  s,
+		audit:   a,
+		timeout: timeout,
+	}
+}
+
+// record write the change to the audit log without the secret of the webhook
+func (u *webhookUsecase) record(ctx context.Context, id int64, action string, before *models.Webhook, after *models.Webhook) {
+	if err := u.audit.Record(ctx, models.AuditWebhook, id, action, redact(before), redact(after)); err != nil {
+		logger.Error(err)
+	}
+}
+
+// redact return a copy of hook without its secret
+func redact(hook *models.Webhook) *models.Webhook {
+	if hook == nil {
+		return nil
+	}
+
+	safe := *hook
+	safe.Secret = ""
+	return &safe
+}
+
+func validate(hook *models.Webhook) error {
+	endpoint, err := url.Parse(hook.URL)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return models.ErrBadParamInput
+	}
+
+	if len(hook.Events) == 0 {
+		return models.ErrBadParamInput
+	}
+
+	for _, eventType := range hook.Events {
+		if !known(eventType) {
+			return models.ErrBadParamInput
+		}
+	}
+
+	return nil
+}
+
+func known(eventType string) bool {
+	if eventType == AllEvents {
+		return true
+	}
+
+	for _, t := range models.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+func subscribed(hook *models.Webhook, eventType string) bool {
+	for _, t := range hook.Events {
+		if t == AllEvents || t == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (u *webhookUsecase) GetList(ctx context.Context, offset int64, limit int64) ([]*models.Webhook, int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	hooks, found, err := u.repo.GetList(ctx, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for i, hook := range hooks {
+		hooks[i] = redact(hook)
+	}
+
+	return hooks, found, nil
+}
+
+func (u *webhookUsecase) GetByID(ctx context.Context, id int64) (*models.Webhook, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	hook, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return redact(hook), nil
+}
+
+// Create register an active webhook and give it a new secret, the only time
+// the secret is returned
+func (u *webhookUsecase) Create(ctx context.Context, hook *models.Webhook) error {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	if err := validate(hook); err != nil {
+		return err
+	}
+
+	hook.Secret = secretPrefix + utils.RandString(32)
+	hook.Active = true
+
+	if err := u.repo.Create(ctx, hook); err != nil {
+		return err
+	}
+
+	u.record(ctx, hook.ID, models.AuditCreate, nil, hook)
+	return nil
+}
+
+// Update change the url, events and whether a webhook is active
+func (u *webhookUsecase) Update(ctx context.Context, hook *models.Webhook) error {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	if err := validate(hook); err != nil {
+		return err
+	}
+
+	before, err := u.repo.GetByID(ctx, hook.ID)
+	if err != nil {
+		return err
+	}
+
+	if hook.Version == 0 {
+		hook.Version = before.Version
+	}
+
+	if hook.Version != before.Version {
+		return models.ErrConflict
+	}
+
+	after := *before
+	after.URL = hook.URL
+	after.Events = hook.Events
+	after.Active = hook.Active
+	after.Updated = null.NewTime(time.Now(), true)
+
+	if err = u.repo.Update(ctx, &after); err != nil {
+		return err
+	}
+
+	*hook = *redact(&after)
+	u.record(ctx, hook.ID, models.AuditUpdate, before, hook)
+	return nil
+}
+
+func (u *webhookUsecase) Delete(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	before, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err = u.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	u.record(ctx, id, models.AuditDelete, before, nil)
+	return nil
+}
+
+// GetDeliveries list the deliveries of a webhook with the outcome of their
+// last attempt
+func (u *webhookUsecase) GetDeliveries(ctx context.Context, webhookID int64, offset int64, limit int64) ([]*models.WebhookDelivery, int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	if _, err := u.repo.GetByID(ctx, webhookID); err != nil {
+		return nil, 0, err
+	}
+
+	return u.repo.GetDeliveries(ctx, webhookID, offset, limit)
+}
+
+// Redeliver queue a delivery again for the next Dispatch with a full set of
+// attempts, delivered ones included
+func (u *webhookUsecase) Redeliver(ctx context.Context, deliveryID int64) (*models.WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	delivery, err := u.repo.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery.Status = models.DeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttempt = null.TimeFrom(time.Now())
+
+	if err = u.repo.UpdateDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+// Dispatch queue a delivery of the new events of the outbox to every active
+// webhook subscribed to them, then attempt the deliveries which are due. It
+// return how many were delivered. Sending is bounded by the timeout of the
+// Sender rather than the one of the usecase.
+func (u *webhookUsecase) Dispatch(ctx context.Context) (int64, error) {
+	hooks, err := u.repo.GetActive(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	events, err := u.repo.GetPendingEvents(ctx, dispatchBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, event := range events {
+		ids := make([]int64, 0)
+		for _, hook := range hooks {
+			if subscribed(hook, event.Type) {
+				ids = append(ids, hook.ID)
+			}
+		}
+
+		err = u.repo.Fanout(ctx, event, ids)
+		if err == models.ErrConflict {
+			// fanned out by another dispatcher
+			continue
+		}
+
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	due, err := u.repo.GetDue(ctx, time.Now(), dispatchBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	active := make(map[int64]*models.Webhook, len(hooks))
+	for _, hook := range hooks {
+		active[hook.ID] = hook
+	}
+
+	var delivered int64
+	for _, delivery := range due {
+		err = u.repo.Lease(ctx, delivery, time.Now().Add(deliveryLease))
+		if err == models.ErrConflict {
+			// taken by another dispatcher
+			continue
+		}
+
+		if err != nil {
+			logger.Error(err)
+			continue
+		}
+
+		if err = u.deliver(ctx, active[delivery.WebhookID], delivery); err != nil {
+			logger.Error(err)
+			continue
+		}
+
+		if delivery.Status == models.DeliveryDelivered {
+			delivered++
+		}
+	}
+
+	return delivered, nil
+}
+
+// deliver attempt to post the event of delivery to hook and store the
+// outcome, failures are retried with a growing backoff until MaxAttempts.
+// Deliveries to a webhook deactivated since they were queued are failed. The
+// delivery must be leased, a dispatcher dying while sending leave it to be
+// tried again once the lease is over.
+func (u *webhookUsecase) deliver(ctx context.Context, hook *models.Webhook, delivery *models.WebhookDelivery) error {
+	now := time.Now()
+	delivery.Attempts++
+
+	if hook == nil {
+		delivery.Status = models.DeliveryFailed
+		delivery.NextAttempt = null.Time{}
+		delivery.Error = "webhook is not active"
+		return u.repo.UpdateDelivery(ctx, delivery)
+	}
+
+	event, err := u.repo.GetEvent(ctx, delivery.EventID)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set(webhook.EventHeader, event.Type)
+	header.Set(webhook.DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	header.Set(webhook.SignatureHeader, webhook.Sign(hook.Secret, now.Unix(), body))
+
+	code, err := u.sender.Send(ctx, hook.URL, header, body)
+
+	delivery.ResponseCode = null.Int{}
+	if err == nil {
+		delivery.ResponseCode = null.IntFrom(int64(code))
+	}
+
+	switch {
+	case err == nil && code >= 200 && code < 300:
+		delivery.Status = models.DeliveryDelivered
+		delivery.NextAttempt = null.Time{}
+		delivery.Delivered = null.TimeFrom(now)
+		delivery.Error = ""
+	case delivery.Attempts >= webhook.MaxAttempts:
+		delivery.Status = models.DeliveryFailed
+		delivery.NextAttempt = null.Time{}
+	default:
+		delivery.NextAttempt = null.TimeFrom(now.Add(webhook.Backoff(delivery.Attempts)))
+	}
+
+	if err != nil {
+		delivery.Error = err.Error()
+	} else if delivery.Status != models.DeliveryDelivered {
+		delivery.Error = fmt.Sprintf("endpoint answered %d", code)
+	}
+
+	return u.repo.UpdateDelivery(ctx, delivery)
+}
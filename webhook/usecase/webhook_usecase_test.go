@@ -0,0 +1,189 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/soerjadi/exam/audit/audittest"
+	"github.com/soerjadi/exam/models"
+	"github.com/soerjadi/exam/webhook"
+	"github.com/soerjadi/exam/webhook/mocks"
+	"github.com/soerjadi/exam/webhook/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreate(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Webhook")).Return(nil).Once()
+
+		hook := &models.Webhook{URL: "https://erp.example.com/hook", Events: []string{models.EventOrderCreated}}
+
+		mockAudit := audittest.NewUsecase()
+		u := usecase.NewWebhookUsecase(mockRepo, new(mocks.Sender), mockAudit, time.Second*2)
+		err := u.Create(context.TODO(), hook)
+
+		assert.NoError(t, err)
+		assert.True(t, hook.Active)
+		assert.True(t, strings.HasPrefix(hook.Secret, "whsec_"))
+		mockRepo.AssertExpectations(t)
+
+		// the secret is kept out of the audit log
+		redacted := *hook
+		redacted.Secret = ""
+		audittest.AssertRecorded(t, mockAudit, models.AuditWebhook, hook.ID, models.AuditCreate, (*models.Webhook)(nil), &redacted)
+	})
+
+	t.Run("invalid url", func(t *testing.T) {
+		mockAudit := audittest.NewUsecase()
+		u := usecase.NewWebhookUsecase(new(mocks.Repository), new(mocks.Sender), mockAudit, time.Second*2)
+		err := u.Create(context.TODO(), &models.Webhook{URL: "ftp://erp.example.com", Events: []string{"*"}})
+
+		assert.Equal(t, models.ErrBadParamInput, err)
+		audittest.AssertNothingRecorded(t, mockAudit)
+	})
+
+	t.Run("unknown event", func(t *testing.T) {
+		u := usecase.NewWebhookUsecase(new(mocks.Repository), new(mocks.Sender), audittest.NewUsecase(), time.Second*2)
+		err := u.Create(context.TODO(), &models.Webhook{URL: "https://erp.example.com/hook", Events: []string{"order.deleted"}})
+
+		assert.Equal(t, models.ErrBadParamInput, err)
+	})
+}
+
+func TestGetByIDRedactSecret(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockRepo.On("GetByID", mock.Anything, int64(1)).Return(&models.Webhook{ID: 1, Secret: "whsec_abc"}, nil).Once()
+
+	u := usecase.NewWebhookUsecase(mockRepo, new(mocks.Sender), audittest.NewUsecase(), time.Second*2)
+	hook, err := u.GetByID(context.TODO(), int64(1))
+
+	assert.NoError(t, err)
+	assert.Empty(t, hook.Secret)
+}
+
+func TestDispatch(t *testing.T) {
+	hook := &models.Webhook{ID: 1, URL: "https://erp.example.com/hook", Secret: "whsec_abc", Events: []string{models.EventOrderCreated}, Active: true}
+	event := &models.Event{ID: 9, Type: models.EventOrderCreated, AggregateID: 3, Payload: []byte(`{"id":3}`)}
+	other := &models.Event{ID: 10, Type: models.EventProductCreated, AggregateID: 4, Payload: []byte(`{"id":4}`)}
+
+	newRepo := func(delivery *models.WebhookDelivery) *mocks.Repository {
+		mockRepo := new(mocks.Repository)
+		mockRepo.On("GetActive", mock.Anything).Return([]*models.Webhook{hook}, nil).Once()
+		mockRepo.On("GetPendingEvents", mock.Anything, int64(100)).Return([]*models.Event{event, other}, nil).Once()
+		mockRepo.On("Fanout", mock.Anything, event, []int64{1}).Return(nil).Once()
+		mockRepo.On("Fanout", mock.Anything, other, []int64{}).Return(nil).Once()
+		mockRepo.On("GetDue", mock.Anything, mock.AnythingOfType("time.Time"), int64(100)).
+			Return([]*models.WebhookDelivery{delivery}, nil).Once()
+		mockRepo.On("Lease", mock.Anything, delivery, mock.AnythingOfType("time.Time")).Return(nil).Once()
+		mockRepo.On("GetEvent", mock.Anything, int64(9)).Return(event, nil).Once()
+		mockRepo.On("UpdateDelivery", mock.Anything, delivery).Return(nil).Once()
+
+		return mockRepo
+	}
+
+	t.Run("delivered", func(t *testing.T) {
+		delivery := &models.WebhookDelivery{ID: 5, WebhookID: 1, EventID: 9, Status: models.DeliveryPending}
+		mockRepo := newRepo(delivery)
+
+		mockSender := new(mocks.Sender)
+		mockSender.On("Send", mock.Anything, hook.URL, mock.MatchedBy(func(h http.Header) bool {
+			return h.Get(webhook.EventHeader) == models.EventOrderCreated && h.Get(webhook.DeliveryHeader) == "5" &&
+				strings.HasPrefix(h.Get(webhook.SignatureHeader), "t=")
+		}), mock.Anything).Return(http.StatusNoContent, nil).Once()
+
+		u := usecase.NewWebhookUsecase(mockRepo, mockSender, audittest.NewUsecase(), time.Second*2)
+		delivered, err := u.Dispatch(context.TODO())
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), delivered)
+		assert.Equal(t, models.DeliveryDelivered, delivery.Status)
+		assert.True(t, delivery.Delivered.Valid)
+		assert.Equal(t, int64(http.StatusNoContent), delivery.ResponseCode.Int64)
+		mockRepo.AssertExpectations(t)
+		mockSender.AssertExpectations(t)
+	})
+
+	t.Run("retry with backoff", func(t *testing.T) {
+		delivery := &models.WebhookDelivery{ID: 5, WebhookID: 1, EventID: 9, Status: models.DeliveryPending, Attempts: 2}
+		mockRepo := newRepo(delivery)
+
+		mockSender := new(mocks.Sender)
+		mockSender.On("Send", mock.Anything, hook.URL, mock.Anything, mock.Anything).Return(http.StatusInternalServerError, nil).Once()
+
+		u := usecase.NewWebhookUsecase(mockRepo, mockSender, audittest.NewUsecase(), time.Second*2)
+		delivered, err := u.Dispatch(context.TODO())
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), delivered)
+		assert.Equal(t, models.DeliveryPending, delivery.Status)
+		assert.Equal(t, 3, delivery.Attempts)
+		assert.True(t, delivery.NextAttempt.Time.After(time.Now().Add(webhook.Backoff(3)-time.Minute)))
+		assert.Equal(t, "endpoint answered 500", delivery.Error)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("fail after max attempts", func(t *testing.T) {
+		delivery := &models.WebhookDelivery{ID: 5, WebhookID: 1, EventID: 9, Status: models.DeliveryPending, Attempts: webhook.MaxAttempts - 1}
+		mockRepo := newRepo(delivery)
+
+		mockSender := new(mocks.Sender)
+		mockSender.On("Send", mock.Anything, hook.URL, mock.Anything, mock.Anything).Return(0, errors.New("connection refused")).Once()
+
+		u := usecase.NewWebhookUsecase(mockRepo, mockSender, audittest.NewUsecase(), time.Second*2)
+		_, err := u.Dispatch(context.TODO())
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.DeliveryFailed, delivery.Status)
+		assert.False(t, delivery.NextAttempt.Valid)
+		assert.False(t, delivery.ResponseCode.Valid)
+		assert.Equal(t, "connection refused", delivery.Error)
+		mockRepo.AssertExpectations(t)
+	})
+	t.Run("taken by another dispatcher", func(t *testing.T) {
+		delivery := &models.WebhookDelivery{ID: 5, WebhookID: 1, EventID: 9, Status: models.DeliveryPending}
+
+		mockRepo := new(mocks.Repository)
+		mockRepo.On("GetActive", mock.Anything).Return([]*models.Webhook{hook}, nil).Once()
+		mockRepo.On("GetPendingEvents", mock.Anything, int64(100)).Return([]*models.Event{event, other}, nil).Once()
+		mockRepo.On("Fanout", mock.Anything, event, []int64{1}).Return(models.ErrConflict).Once()
+		mockRepo.On("Fanout", mock.Anything, other, []int64{}).Return(nil).Once()
+		mockRepo.On("GetDue", mock.Anything, mock.AnythingOfType("time.Time"), int64(100)).
+			Return([]*models.WebhookDelivery{delivery}, nil).Once()
+		mockRepo.On("Lease", mock.Anything, delivery, mock.AnythingOfType("time.Time")).Return(models.ErrConflict).Once()
+
+		mockSender := new(mocks.Sender)
+
+		u := usecase.NewWebhookUsecase(mockRepo, mockSender, audittest.NewUsecase(), time.Second*2)
+		delivered, err := u.Dispatch(context.TODO())
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), delivered)
+		assert.Equal(t, 0, delivery.Attempts)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "UpdateDelivery", mock.Anything, mock.Anything)
+		mockSender.AssertNotCalled(t, "Send", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestRedeliver(t *testing.T) {
+	delivery := &models.WebhookDelivery{ID: 5, WebhookID: 1, EventID: 9, Status: models.DeliveryFailed, Attempts: webhook.MaxAttempts}
+
+	mockRepo := new(mocks.Repository)
+	mockRepo.On("GetDelivery", mock.Anything, int64(5)).Return(delivery, nil).Once()
+	mockRepo.On("UpdateDelivery", mock.Anything, delivery).Return(nil).Once()
+
+	u := usecase.NewWebhookUsecase(mockRepo, new(mocks.Sender), audittest.NewUsecase(), time.Second*2)
+	result, err := u.Redeliver(context.TODO(), int64(5))
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.DeliveryPending, result.Status)
+	assert.Equal(t, 0, result.Attempts)
+	assert.True(t, result.NextAttempt.Valid)
+	mockRepo.AssertExpectations(t)
+}